@@ -0,0 +1,487 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/repositories"
+	"github.com/over55/workery-server/internal/utils"
+)
+
+const (
+	etlCompletedStatus = "completed"
+	etlSkippedStatus   = "skipped"
+	etlFailedStatus    = "failed"
+)
+
+var (
+	etlDryRun     bool
+	etlResume     bool
+	etlBatchSize  int
+	etlReportPath string
+)
+
+// Function adds the flags which are shared by all our ETL commands.
+func addETLFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&etlDryRun, "dry-run", false, "Run the import inside a transaction which gets rolled back at the end.")
+	cmd.Flags().BoolVar(&etlResume, "resume", false, "Continue from the checkpoints saved by a previous run.")
+	cmd.Flags().IntVar(&etlBatchSize, "batch-size", 500, "The number of rows saved per transaction.")
+	cmd.Flags().StringVar(&etlReportPath, "report", "", "The file path to save the JSON report of the run.")
+}
+
+// etlEnv holds the database connections and the tenant which every step of
+// the ETL run shares.
+type etlEnv struct {
+	DB          *sql.DB
+	OldDB       *sql.DB // The tenant schema of the old database.
+	OldPublicDB *sql.DB // The `public` schema of the old database.
+	SchemaName  string
+	TenantId    uint64
+
+	// The timezone of the tenant which is used for the old values which
+	// do not include an offset.
+	Location *time.Location
+}
+
+// etlStep describes how to import a single table from the old workery.
+type etlStep struct {
+	Name string
+
+	// Set to true if the step runs before the tenant exists in our database,
+	// ex: the `tenant` and `user` steps.
+	IsGlobal bool
+
+	// Set to true if the step does something outside of our database which
+	// cannot be rolled back, these steps are skipped during a dry run.
+	HasSideEffects bool
+
+	// Function returns every row from the old database which this step will
+	// import.
+	ListRows func(ctx context.Context, e *etlEnv) ([]*etlRow, error)
+}
+
+// etlRow is a single record from the old database. The `Run` function must
+// save the record using repositories attached to the transaction. If the
+// function panics, which our older ETL code does on errors, the panic is
+// treated the same as returning an error.
+type etlRow struct {
+	OldId uint64
+
+	// The old record of the row, the times of the record which do not
+	// include an offset are converted to UTC before the row runs.
+	Record interface{}
+
+	Run func(ctx context.Context, tx *sql.Tx) error
+}
+
+type etlRowError struct {
+	OldId uint64 `json:"old_id"`
+	Error string `json:"error"`
+}
+
+type etlStepReport struct {
+	Name         string         `json:"name"`
+	Status       string         `json:"status"`
+	Error        string         `json:"error,omitempty"`
+	TotalRows    int            `json:"total_rows"`
+	SkippedRows  int            `json:"skipped_rows"`
+	ImportedRows int            `json:"imported_rows"`
+	FailedRows   int            `json:"failed_rows"`
+	Errors       []*etlRowError `json:"errors,omitempty"`
+}
+
+type etlReport struct {
+	SchemaName   string           `json:"schema_name"`
+	DryRun       bool             `json:"dry_run"`
+	StartedTime  time.Time        `json:"started_time"`
+	FinishedTime time.Time        `json:"finished_time"`
+	Steps        []*etlStepReport `json:"steps"`
+}
+
+// Function connects to our new database and to the `public` and tenant
+// schemas of the old database.
+func connectETLEnv(schemaName string) *etlEnv {
+	// Load up our NEW database.
+	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Load up our OLD database.
+	//
+	// DEVELOPERS NOTE:
+	// We read the old database in UTC because our `TIMESTAMP` columns drop
+	// the offset of the values we insert, if the old database returned the
+	// values in its local timezone we would save the local time as UTC.
+	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
+	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
+	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
+	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
+	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
+	oldPublicDb, err := utils.ConnectDBWithTimezone(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, "public", "UTC")
+	if err != nil {
+		log.Fatal(err)
+	}
+	oldDb, err := utils.ConnectDBWithTimezone(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, schemaName, "UTC")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return &etlEnv{
+		DB:          db,
+		OldDB:       oldDb,
+		OldPublicDB: oldPublicDb,
+		SchemaName:  schemaName,
+	}
+}
+
+// Function closes all the database connections.
+func (e *etlEnv) Close() {
+	e.DB.Close()
+	e.OldDB.Close()
+	e.OldPublicDB.Close()
+}
+
+// Function connects to our databases and runs the steps in order. The
+// `tenantId` is optional and if zero the tenant is looked up by the schema
+// name once it exists.
+func runETL(schemaName string, tenantId uint64, steps []*etlStep) {
+	ctx := context.Background()
+
+	env := connectETLEnv(schemaName)
+	defer env.Close()
+	env.TenantId = tenantId
+
+	report := &etlReport{
+		SchemaName:  schemaName,
+		DryRun:      etlDryRun,
+		StartedTime: time.Now(),
+	}
+
+	// DEVELOPERS NOTE:
+	// For a dry run we use a single transaction for every step so the later
+	// steps can find the records the earlier steps created, and then we roll
+	// everything back at the very end.
+	var dryRunTx *sql.Tx
+	if etlDryRun {
+		var err error
+		dryRunTx, err = env.DB.BeginTx(ctx, nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer dryRunTx.Rollback()
+	}
+
+	for _, step := range steps {
+		log.Println("ETL | Step:", step.Name)
+		sr, err := runETLStep(ctx, env, step, dryRunTx)
+		report.Steps = append(report.Steps, sr)
+		if err != nil {
+			sr.Status = etlFailedStatus
+			sr.Error = err.Error()
+			saveETLReport(report)
+			log.Fatal("ETL | Step: ", step.Name, " | Aborted with error: ", err)
+		}
+	}
+	saveETLReport(report)
+}
+
+func runETLStep(ctx context.Context, env *etlEnv, step *etlStep, dryRunTx *sql.Tx) (*etlStepReport, error) {
+	sr := &etlStepReport{
+		Name: step.Name,
+	}
+
+	if etlDryRun && step.HasSideEffects {
+		sr.Status = etlSkippedStatus
+		return sr, nil
+	}
+
+	tr := repositories.NewTenantRepo(env.DB)
+	cr := repositories.NewETLCheckpointRepo(env.DB)
+	if dryRunTx != nil {
+		tr = tr.WithTx(dryRunTx)
+		cr = cr.WithTx(dryRunTx)
+	}
+
+	// Lookup the tenant if the step requires it.
+	if !step.IsGlobal && env.Location == nil {
+		var tenant *models.Tenant
+		var err error
+		if env.TenantId == 0 {
+			tenant, err = tr.GetBySchemaName(ctx, env.SchemaName)
+		} else {
+			tenant, err = tr.GetById(ctx, env.TenantId)
+		}
+		if err != nil {
+			return sr, err
+		}
+		if tenant == nil {
+			return sr, fmt.Errorf("tenant `%s` does not exist, please run the `tenant` step first", env.SchemaName)
+		}
+		env.TenantId = tenant.Id
+		env.Location, err = utils.GetTimezoneLocation(tenant.Timezone)
+		if err != nil {
+			return sr, err
+		}
+	}
+
+	// DEVELOPERS NOTE:
+	// The tenants which were imported before we converted the old times to
+	// UTC must be corrected first, otherwise `etl_fix_timestamps` would shift
+	// the rows we import now as well.
+	if !step.IsGlobal {
+		fcp, err := cr.GetBySchemaNameAndStep(ctx, env.SchemaName, etlFixTimestampsStep)
+		if err != nil {
+			return sr, err
+		}
+		if fcp != nil && !fcp.IsCompleted {
+			return sr, fmt.Errorf("the timestamps of `%s` were imported in the local time, please run `etl_fix_timestamps` first", env.SchemaName)
+		}
+	}
+
+	// Lookup where we left off last time.
+	cp, err := cr.GetBySchemaNameAndStep(ctx, env.SchemaName, step.Name)
+	if err != nil {
+		return sr, err
+	}
+	if cp != nil && !etlResume {
+		return sr, fmt.Errorf("step already ran for `%s`, use `--resume` to continue from the checkpoint", env.SchemaName)
+	}
+	if cp == nil {
+		cp = &models.ETLCheckpoint{
+			SchemaName:  env.SchemaName,
+			Step:        step.Name,
+			CreatedTime: time.Now(),
+		}
+	}
+	if cp.IsCompleted && len(cp.FailedOldIds) == 0 {
+		sr.Status = etlSkippedStatus
+		return sr, nil
+	}
+
+	rows, err := listETLRows(ctx, env, step)
+	if err != nil {
+		return sr, err
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].OldId < rows[j].OldId
+	})
+	sr.TotalRows = len(rows)
+
+	// Skip the rows which were already processed in a previous run, except
+	// for the rows which failed as those are retried.
+	failed := make(map[uint64]bool)
+	for _, oldId := range cp.FailedOldIds {
+		failed[oldId] = true
+	}
+	var pending []*etlRow
+	for _, row := range rows {
+		if row.OldId > cp.LastOldId || failed[row.OldId] {
+			pending = append(pending, row)
+		}
+	}
+	sr.SkippedRows = len(rows) - len(pending)
+
+	batchSize := etlBatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	for start := 0; start < len(pending) || start == 0; start += batchSize {
+		end := start + batchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+
+		tx := dryRunTx
+		if tx == nil {
+			tx, err = env.DB.BeginTx(ctx, nil)
+			if err != nil {
+				return sr, err
+			}
+		}
+
+		for _, row := range pending[start:end] {
+			env.localizeOldTimes(row.Record)
+			if err := runETLRow(ctx, tx, row); err != nil {
+				if err == errETLSavepoint {
+					rollbackETLTx(tx, dryRunTx)
+					return sr, err
+				}
+				sr.FailedRows++
+				sr.Errors = append(sr.Errors, &etlRowError{OldId: row.OldId, Error: err.Error()})
+				failed[row.OldId] = true
+			} else {
+				sr.ImportedRows++
+				cp.ImportedRows++
+				delete(failed, row.OldId)
+			}
+			if row.OldId > cp.LastOldId {
+				cp.LastOldId = row.OldId
+			}
+		}
+		cp.FailedOldIds = sortedETLOldIds(failed)
+		cp.FailedRows = uint64(len(cp.FailedOldIds))
+
+		// Save our checkpoint in the same transaction as the batch so they
+		// are either both saved or both discarded.
+		cp.IsCompleted = end == len(pending)
+		cp.LastModifiedTime = time.Now()
+		if err := cr.WithTx(tx).InsertOrUpdateBySchemaNameAndStep(ctx, cp); err != nil {
+			rollbackETLTx(tx, dryRunTx)
+			return sr, err
+		}
+		if tx != dryRunTx {
+			if err := tx.Commit(); err != nil {
+				return sr, err
+			}
+		}
+		log.Println("ETL | Step:", step.Name, "| Processed:", end, "of", len(pending))
+	}
+
+	sr.Status = etlCompletedStatus
+	return sr, nil
+}
+
+// Function returns the old ids of the set in ascending order.
+func sortedETLOldIds(set map[uint64]bool) []uint64 {
+	arr := make([]uint64, 0, len(set))
+	for oldId := range set {
+		arr = append(arr, oldId)
+	}
+	sort.Slice(arr, func(i, j int) bool {
+		return arr[i] < arr[j]
+	})
+	return arr
+}
+
+// Function returns the rows of the step, our older list functions panic on
+// scan errors so we convert those into an error as well.
+func listETLRows(ctx context.Context, env *etlEnv, step *etlStep) (rows []*etlRow, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return step.ListRows(ctx, env)
+}
+
+// Function converts the times of the old record which the old database
+// returned without an offset, those are the `timestamp` and `date` columns,
+// from the wall clock of the tenant into UTC. The `timestamptz` columns are
+// already returned in UTC as we connect to the old database in UTC.
+func (e *etlEnv) localizeOldTimes(record interface{}) {
+	v := reflect.ValueOf(record)
+	if e.Location == nil || v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	v = v.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if !f.CanSet() {
+			continue
+		}
+		switch t := f.Interface().(type) {
+		case time.Time:
+			f.Set(reflect.ValueOf(e.localizeOldTime(t)))
+		case null.Time:
+			if t.Valid {
+				f.Set(reflect.ValueOf(null.TimeFrom(e.localizeOldTime(t.Time))))
+			}
+		}
+	}
+}
+
+// Function returns the old time in UTC, please note the times which are
+// already in UTC are returned as is so converting twice is safe.
+func (e *etlEnv) localizeOldTime(t time.Time) time.Time {
+	if t.IsZero() || t.Location() == time.UTC {
+		return t
+	}
+	res, err := utils.ConvertPGAdminTimeStringToTime(t.Format("2006-01-02 15:04:05.999999999"), e.Location)
+	if err != nil {
+		log.Println("localizeOldTime | ConvertPGAdminTimeStringToTime | err:", err)
+		return t
+	}
+	return res.UTC()
+}
+
+var errETLSavepoint = fmt.Errorf("failed to manage the row savepoint")
+
+// Function runs the row inside a savepoint so a failed row can be undone
+// without losing the other rows in the batch.
+func runETLRow(ctx context.Context, tx *sql.Tx, row *etlRow) (err error) {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT etl_row"); err != nil {
+		log.Println("runETLRow | SAVEPOINT | err:", err)
+		return errETLSavepoint
+	}
+
+	err = func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%v", r)
+			}
+		}()
+		return row.Run(ctx, tx)
+	}()
+
+	if err != nil {
+		if _, rerr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT etl_row"); rerr != nil {
+			log.Println("runETLRow | ROLLBACK TO SAVEPOINT | err:", rerr)
+			return errETLSavepoint
+		}
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT etl_row"); err != nil {
+		log.Println("runETLRow | RELEASE SAVEPOINT | err:", err)
+		return errETLSavepoint
+	}
+	return nil
+}
+
+func rollbackETLTx(tx *sql.Tx, dryRunTx *sql.Tx) {
+	if tx != dryRunTx {
+		tx.Rollback()
+	}
+}
+
+// Function prints the human readable summary of the run and, if requested,
+// saves the full report with every row error as JSON.
+func saveETLReport(report *etlReport) {
+	report.FinishedTime = time.Now()
+
+	fmt.Println("ETL report for", report.SchemaName, "| Dry run:", report.DryRun)
+	for _, sr := range report.Steps {
+		fmt.Printf("%-40s %-10s total=%d skipped=%d imported=%d failed=%d\n", sr.Name, sr.Status, sr.TotalRows, sr.SkippedRows, sr.ImportedRows, sr.FailedRows)
+		if sr.Error != "" {
+			fmt.Println("    error:", sr.Error)
+		}
+		for _, re := range sr.Errors {
+			fmt.Println("    old id", re.OldId, "->", re.Error)
+		}
+	}
+
+	if etlReportPath == "" {
+		return
+	}
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Println("saveETLReport | json.MarshalIndent | err:", err)
+		return
+	}
+	if err := ioutil.WriteFile(etlReportPath, b, 0644); err != nil {
+		log.Println("saveETLReport | ioutil.WriteFile | err:", err)
+	}
+}
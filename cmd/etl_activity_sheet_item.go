@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,7 +13,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -24,6 +22,7 @@ var (
 func init() {
 	activitySheetItemETLCmd.Flags().StringVarP(&activitySheetItemETLSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	activitySheetItemETLCmd.MarkFlagRequired("schema_name")
+	addETLFlags(activitySheetItemETLCmd)
 	rootCmd.AddCommand(activitySheetItemETLCmd)
 }
 
@@ -37,46 +36,36 @@ var activitySheetItemETLCmd = &cobra.Command{
 }
 
 func doRunImportActivitySheetItem() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, activitySheetItemETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	ur := repositories.NewUserRepo(db)
-	asir := repositories.NewActivitySheetItemRepo(db)
-	owor := repositories.NewOngoingWorkOrderRepo(db)
-	wor := repositories.NewWorkOrderRepo(db)
-	ar := repositories.NewAssociateRepo(db)
+	runETL(activitySheetItemETLSchemaName, 0, []*etlStep{activitySheetItemETLStep})
+}
 
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, activitySheetItemETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant == nil {
-		log.Fatal("Tenant does not exist!")
-	}
+var activitySheetItemETLStep = &etlStep{
+	Name: "activity_sheet_item",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		ur := repositories.NewUserRepo(e.DB)
+		asir := repositories.NewActivitySheetItemRepo(e.DB)
+		owor := repositories.NewOngoingWorkOrderRepo(e.DB)
+		wor := repositories.NewWorkOrderRepo(e.DB)
+		ar := repositories.NewAssociateRepo(e.DB)
 
-	runActivitySheetItemETL(ctx, tenant.Id, ur, asir, owor, wor, ar, oldDb)
+		arr, err := ListAllActivitySheetItems(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oldRecord := range arr {
+			oldRecord := oldRecord
+			rows = append(rows, &etlRow{
+				OldId:  oldRecord.Id,
+				Record: oldRecord,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertActivitySheetItemETL(ctx, e.TenantId, ur.WithTx(tx), asir.WithTx(tx), owor.WithTx(tx), wor.WithTx(tx), ar.WithTx(tx), oldRecord)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldUActivitySheetItem struct {
@@ -136,25 +125,6 @@ func ListAllActivitySheetItems(db *sql.DB) ([]*OldUActivitySheetItem, error) {
 	return arr, err
 }
 
-func runActivitySheetItemETL(
-	ctx context.Context,
-	tenantId uint64,
-	ur *repositories.UserRepo,
-	asir *repositories.ActivitySheetItemRepo,
-	owor *repositories.OngoingWorkOrderRepo,
-	wor *repositories.WorkOrderRepo,
-	ar *repositories.AssociateRepo,
-	oldDb *sql.DB,
-) {
-	activitySheetItems, err := ListAllActivitySheetItems(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, oldRecord := range activitySheetItems {
-		insertActivitySheetItemETL(ctx, tenantId, ur, asir, owor, wor, ar, oldRecord)
-	}
-}
-
 func insertActivitySheetItemETL(
 	ctx context.Context,
 	tid uint64,
@@ -275,7 +245,7 @@ func insertActivitySheetItemETL(
 
 	err = asir.Insert(ctx, m)
 	if err != nil {
-		log.Fatal("Aborted on ID#", oldRecord.Id, "via err:", err)
+		log.Panic("Aborted on ID#", oldRecord.Id, "via err:", err)
 	} else {
 		fmt.Println("Imported ID#", oldRecord.Id)
 	}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	allETLSchemaName string
+)
+
+func init() {
+	allETLCmd.Flags().StringVarP(&allETLSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
+	allETLCmd.MarkFlagRequired("schema_name")
+	addETLFlags(allETLCmd)
+	rootCmd.AddCommand(allETLCmd)
+}
+
+var allETLCmd = &cobra.Command{
+	Use:   "etl_all",
+	Short: "Import every table for the tenant from old workery",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		doRunImportAll()
+	},
+}
+
+// DEVELOPERS NOTE:
+// The steps are ordered so every record is imported after the records it
+// references, do not change the order unless you know what you are doing.
+var allETLSteps = []*etlStep{
+	tenantETLStep,
+	userETLStep,
+	userGroupETLStep,
+	howHearAboutUsItemETLStep,
+	skillSetETLStep,
+	insuranceRequirementETLStep,
+	skillSetInsuranceRequirementETLStep,
+	vehicleTypeETLStep,
+	tagETLStep,
+	commentETLStep,
+	workOrderServiceFeeETLStep,
+	customerETLStep,
+	customerTagETLStep,
+	customerCommentETLStep,
+	associateETLStep,
+	associateSkillSetETLStep,
+	associateTagETLStep,
+	associateVehicleTypeETLStep,
+	associateInsuranceRequirementETLStep,
+	associateCommentETLStep,
+	associateAwayLogETLStep,
+	partnerETLStep,
+	partnerCommentETLStep,
+	staffETLStep,
+	staffTagETLStep,
+	staffCommentETLStep,
+	bulletinBoardItemETLStep,
+	ongoingWorkOrderETLStep,
+	workOrderETLStep,
+	workOrderSkillSetETLStep,
+	workOrderTagETLStep,
+	workOrderCommentETLStep,
+	workOrderInvoiceETLStep,
+	workOrderDepositETLStep,
+	taskItemETLStep,
+	activitySheetItemETLStep,
+	privateFileDownloadToTMPDIRETLStep,
+	privateFileUploadToTMPDIRETLStep,
+}
+
+func doRunImportAll() {
+	runETL(allETLSchemaName, 0, allETLSteps)
+}
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -16,7 +15,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -26,6 +24,7 @@ var (
 func init() {
 	associateETLCmd.Flags().StringVarP(&associateETLSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	associateETLCmd.MarkFlagRequired("schema_name")
+	addETLFlags(associateETLCmd)
 	rootCmd.AddCommand(associateETLCmd)
 }
 
@@ -39,45 +38,36 @@ var associateETLCmd = &cobra.Command{
 }
 
 func doRunImportAssociate() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, associateETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
+	runETL(associateETLSchemaName, 0, []*etlStep{associateETLStep})
+}
 
-	// Load up our repositories.
-	tenantRepo := repositories.NewTenantRepo(db)
-	userRepo := repositories.NewUserRepo(db)
-	associateRepo := repositories.NewAssociateRepo(db)
-	serviceFeeRepo := repositories.NewWorkOrderServiceFeeRepo(db)
-	r := repositories.NewHowHearAboutUsItemRepo(db)
+var associateETLStep = &etlStep{
+	Name: "associate",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		userRepo := repositories.NewUserRepo(e.DB)
+		associateRepo := repositories.NewAssociateRepo(e.DB)
+		serviceFeeRepo := repositories.NewWorkOrderServiceFeeRepo(e.DB)
+		r := repositories.NewHowHearAboutUsItemRepo(e.DB)
 
-	// Lookup the tenant.
-	tenant, err := tenantRepo.GetBySchemaName(ctx, associateETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant == nil {
-		log.Fatal("Tenant does not exist!")
-	}
-
-	runAssociateETL(ctx, tenant.Id, userRepo, associateRepo, serviceFeeRepo, r, oldDb)
+		associates, err := ListAllAssociates(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oldAssociate := range associates {
+			oldAssociate := oldAssociate
+			rows = append(rows, &etlRow{
+				OldId:  oldAssociate.Id,
+				Record: oldAssociate,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					serviceFeeId := getAssociateServiceFeeIdETL(ctx, e.TenantId, serviceFeeRepo.WithTx(tx), oldAssociate)
+					insertAssociateETL(ctx, e.TenantId, serviceFeeId, userRepo.WithTx(tx), associateRepo.WithTx(tx), r.WithTx(tx), oldAssociate)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldUAssociate struct {
@@ -190,71 +180,62 @@ func ListAllAssociates(db *sql.DB) ([]*OldUAssociate, error) {
 			&m.OrganizationTypeOf, &m.AvatarImageId, &m.ServiceFeeId,
 		)
 		if err != nil {
-			log.Fatal("(AA)", err)
+			log.Panic("(AA)", err)
 		}
 		arr = append(arr, m)
 	}
 	err = rows.Err()
 	if err != nil {
-		log.Fatal("(BB)", err)
+		log.Panic("(BB)", err)
 	}
 	return arr, err
 }
 
-func runAssociateETL(
+func getAssociateServiceFeeIdETL(
 	ctx context.Context,
 	tenantId uint64,
-	userRepo *repositories.UserRepo,
-	associateRepo *repositories.AssociateRepo,
 	serviceFeeRepo *repositories.WorkOrderServiceFeeRepo,
-	r *repositories.HowHearAboutUsItemRepo,
-	oldDb *sql.DB,
-) {
-	associates, err := ListAllAssociates(oldDb)
+	oldAssociate *OldUAssociate,
+) uint64 {
+	var serviceFeeId uint64
+
+	oldServiceFeeId, err := oldAssociate.ServiceFeeId.Value()
+
 	if err != nil {
-		log.Fatal("runAssociateETL | ListAllAssociates | err:", err)
+		log.Panic("getAssociateServiceFeeIdETL | oldAssociate.ServiceFeeId | err", err)
 	}
-	for _, oldAssociate := range associates {
-		var serviceFeeId uint64
 
-		oldServiceFeeId, err := oldAssociate.ServiceFeeId.Value()
+	// DEVELOPERS NOTE:
+	// THIS IS TECH DEBT! BUST SINCE WE IMPORT THE DATA, THIS ETL WILL
+	// BE DELETED SO WE ACCEPT THIS TECH DEBT.
+	// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
+	if oldServiceFeeId == nil {
+		log.Println("getAssociateServiceFeeIdETL | oldServiceFeeId == nil | oldAssociate.ServiceFeeId", oldAssociate.Id, oldAssociate.ServiceFeeId)
+		if tenantId == 2 {
+			serviceFeeId = 3
+		} else if tenantId == 4 {
+			serviceFeeId = 11
+		} else {
 
+		}
+	} else {
+		serviceFeeId, err = serviceFeeRepo.GetIdByOldId(ctx, tenantId, serviceFeeId)
 		if err != nil {
-			log.Panic("runAssociateETL | oldAssociate.ServiceFeeId | err", err)
+			log.Panic("getAssociateServiceFeeIdETL | serviceFeeRepo.GetIdByOldId | err", err)
 		}
-
-		// DEVELOPERS NOTE:
-		// THIS IS TECH DEBT! BUST SINCE WE IMPORT THE DATA, THIS ETL WILL
-		// BE DELETED SO WE ACCEPT THIS TECH DEBT.
-		// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
-		if oldServiceFeeId == nil {
-			log.Println("runAssociateETL | oldServiceFeeId == nil | oldAssociate.ServiceFeeId", oldAssociate.Id, oldAssociate.ServiceFeeId)
-			if tenantId == 2 {
-				serviceFeeId = 3
-			} else if tenantId == 4 {
-				serviceFeeId = 11
-			} else {
-
-			}
+	}
+	if serviceFeeId == 0 {
+		if tenantId == 2 {
+			serviceFeeId = 3
+		} else if tenantId == 4 {
+			serviceFeeId = 11
 		} else {
-			serviceFeeId, err = serviceFeeRepo.GetIdByOldId(ctx, tenantId, serviceFeeId)
-			if err != nil {
-				log.Panic("runAssociateETL | serviceFeeRepo.GetIdByOldId | err", err)
-			}
+			log.Println("getAssociateServiceFeeIdETL | serviceFeeId", serviceFeeId)
 		}
-		if serviceFeeId == 0 {
-			if tenantId == 2 {
-				serviceFeeId = 3
-			} else if tenantId == 4 {
-				serviceFeeId = 11
-			} else {
-				log.Println("runAssociateETL | serviceFeeId", serviceFeeId)
-			}
-		}
-		// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
-
-		insertAssociateETL(ctx, tenantId, serviceFeeId, userRepo, associateRepo, r, oldAssociate)
 	}
+	// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
+
+	return serviceFeeId
 }
 
 func insertAssociateETL(
@@ -302,10 +283,10 @@ func insertAssociateETL(
 		// log.Println(oldAssociate.Id)
 		user, err := userRepo.GetByOldId(ctx, userId)
 		if err != nil {
-			log.Fatal("(A)", err)
+			log.Panic("(A)", err)
 		}
 		if user == nil {
-			log.Fatal("(B) User is null")
+			log.Panic("(B) User is null")
 		}
 		userId = user.Id
 
@@ -451,7 +432,7 @@ func insertAssociateETL(
 	howHearText := ""
 	howHear, err := r.GetById(ctx, howHearId)
 	if err != nil {
-		log.Fatal(err)
+		log.Panic(err)
 		return
 	}
 	if howHearId == 1 {
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,7 +13,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -27,6 +25,7 @@ func init() {
 	awlETLCmd.MarkFlagRequired("schema_name")
 	awlETLCmd.Flags().IntVarP(&awlETLTenantId, "tenant_id", "t", 0, "Tenant Id that this data belongs to")
 	awlETLCmd.MarkFlagRequired("tenant_id")
+	addETLFlags(awlETLCmd)
 	rootCmd.AddCommand(awlETLCmd)
 }
 
@@ -40,53 +39,35 @@ var awlETLCmd = &cobra.Command{
 }
 
 func doRunImportAssociateAwayLog() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal("utils.ConnectDB", err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, awlETLSchemaName)
-	if err != nil {
-		log.Fatal("utils.ConnectDB", err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	asr := repositories.NewAssociateAwayLogRepo(db)
-	ar := repositories.NewAssociateRepo(db)
-	vtr := repositories.NewAssociateAwayLogRepo(db)
-	ur := repositories.NewUserRepo(db)
-
-	runAssociateAwayLogETL(ctx, uint64(awlETLTenantId), asr, ar, vtr, ur, oldDb)
+	runETL(awlETLSchemaName, uint64(awlETLTenantId), []*etlStep{associateAwayLogETLStep})
 }
 
-func runAssociateAwayLogETL(
-	ctx context.Context,
-	tenantId uint64,
-	asr *repositories.AssociateAwayLogRepo,
-	ar *repositories.AssociateRepo,
-	vtr *repositories.AssociateAwayLogRepo,
-	ur *repositories.UserRepo,
-	oldDb *sql.DB,
-) {
-	ass, err := ListAllAssociateAwayLogs(oldDb)
-	if err != nil {
-		log.Fatal("ListAllAssociateAwayLogs", err)
-	}
-	for _, oss := range ass {
-		insertAssociateAwayLogETL(ctx, tenantId, asr, ar, vtr, ur, oss)
-	}
+var associateAwayLogETLStep = &etlStep{
+	Name: "associate_away_log",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		asr := repositories.NewAssociateAwayLogRepo(e.DB)
+		ar := repositories.NewAssociateRepo(e.DB)
+		vtr := repositories.NewAssociateAwayLogRepo(e.DB)
+		ur := repositories.NewUserRepo(e.DB)
+
+		arr, err := ListAllAssociateAwayLogs(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oss := range arr {
+			oss := oss
+			rows = append(rows, &etlRow{
+				OldId:  oss.Id,
+				Record: oss,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertAssociateAwayLogETL(ctx, e.TenantId, asr.WithTx(tx), ar.WithTx(tx), vtr.WithTx(tx), ur.WithTx(tx), oss)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldAssociateAwayLog struct {
@@ -143,13 +124,13 @@ func ListAllAssociateAwayLogs(db *sql.DB) ([]*OldAssociateAwayLog, error) {
 			&m.LastModifiedById,
 		)
 		if err != nil {
-			log.Fatal("rows.Scan", err)
+			log.Panic("rows.Scan", err)
 		}
 		arr = append(arr, m)
 	}
 	err = rows.Err()
 	if err != nil {
-		log.Fatal("rows.Err", err)
+		log.Panic("rows.Err", err)
 	}
 	return arr, err
 }
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +12,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -26,6 +24,7 @@ func init() {
 	asETLCmd.MarkFlagRequired("schema_name")
 	asETLCmd.Flags().IntVarP(&asETLTenantId, "tenant_id", "t", 0, "Tenant Id that this data belongs to")
 	asETLCmd.MarkFlagRequired("tenant_id")
+	addETLFlags(asETLCmd)
 	rootCmd.AddCommand(asETLCmd)
 }
 
@@ -39,51 +38,34 @@ var asETLCmd = &cobra.Command{
 }
 
 func doRunImportAssociateComment() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal("utils.ConnectDB", err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, asETLSchemaName)
-	if err != nil {
-		log.Fatal("utils.ConnectDB", err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	asr := repositories.NewAssociateCommentRepo(db)
-	ar := repositories.NewAssociateRepo(db)
-	vtr := repositories.NewCommentRepo(db)
-
-	runAssociateCommentETL(ctx, uint64(asETLTenantId), asr, ar, vtr, oldDb)
+	runETL(asETLSchemaName, uint64(asETLTenantId), []*etlStep{associateCommentETLStep})
 }
 
-func runAssociateCommentETL(
-	ctx context.Context,
-	tenantId uint64,
-	asr *repositories.AssociateCommentRepo,
-	ar *repositories.AssociateRepo,
-	vtr *repositories.CommentRepo,
-	oldDb *sql.DB,
-) {
-	ass, err := ListAllAssociateComments(oldDb)
-	if err != nil {
-		log.Fatal("ListAllAssociateComments", err)
-	}
-	for _, oss := range ass {
-		insertAssociateCommentETL(ctx, tenantId, asr, ar, vtr, oss)
-	}
+var associateCommentETLStep = &etlStep{
+	Name: "associate_comment",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		asr := repositories.NewAssociateCommentRepo(e.DB)
+		ar := repositories.NewAssociateRepo(e.DB)
+		vtr := repositories.NewCommentRepo(e.DB)
+
+		arr, err := ListAllAssociateComments(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oss := range arr {
+			oss := oss
+			rows = append(rows, &etlRow{
+				OldId:  oss.Id,
+				Record: oss,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertAssociateCommentETL(ctx, e.TenantId, asr.WithTx(tx), ar.WithTx(tx), vtr.WithTx(tx), oss)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldAssociateComment struct {
@@ -120,13 +102,13 @@ func ListAllAssociateComments(db *sql.DB) ([]*OldAssociateComment, error) {
 			&m.CommentId,
 		)
 		if err != nil {
-			log.Fatal("rows.Scan", err)
+			log.Panic("rows.Scan", err)
 		}
 		arr = append(arr, m)
 	}
 	err = rows.Err()
 	if err != nil {
-		log.Fatal("rows.Err", err)
+		log.Panic("rows.Err", err)
 	}
 	return arr, err
 }
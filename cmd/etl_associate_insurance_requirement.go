@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +12,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -26,6 +24,7 @@ func init() {
 	airETLCmd.MarkFlagRequired("schema_name")
 	airETLCmd.Flags().IntVarP(&airETLTenantId, "tenant_id", "t", 0, "Tenant Id that this data belongs to")
 	airETLCmd.MarkFlagRequired("tenant_id")
+	addETLFlags(airETLCmd)
 	rootCmd.AddCommand(airETLCmd)
 }
 
@@ -39,51 +38,34 @@ var airETLCmd = &cobra.Command{
 }
 
 func doRunImportAssociateInsuranceRequirement() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal("utils.ConnectDB", err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, airETLSchemaName)
-	if err != nil {
-		log.Fatal("utils.ConnectDB", err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	asr := repositories.NewAssociateInsuranceRequirementRepo(db)
-	ar := repositories.NewAssociateRepo(db)
-	vtr := repositories.NewInsuranceRequirementRepo(db)
-
-	runAssociateInsuranceRequirementETL(ctx, uint64(airETLTenantId), asr, ar, vtr, oldDb)
+	runETL(airETLSchemaName, uint64(airETLTenantId), []*etlStep{associateInsuranceRequirementETLStep})
 }
 
-func runAssociateInsuranceRequirementETL(
-	ctx context.Context,
-	tenantId uint64,
-	asr *repositories.AssociateInsuranceRequirementRepo,
-	ar *repositories.AssociateRepo,
-	vtr *repositories.InsuranceRequirementRepo,
-	oldDb *sql.DB,
-) {
-	ass, err := ListAllAssociateInsuranceRequirements(oldDb)
-	if err != nil {
-		log.Fatal("ListAllAssociateInsuranceRequirements", err)
-	}
-	for _, oss := range ass {
-		insertAssociateInsuranceRequirementETL(ctx, tenantId, asr, ar, vtr, oss)
-	}
+var associateInsuranceRequirementETLStep = &etlStep{
+	Name: "associate_insurance_requirement",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		asr := repositories.NewAssociateInsuranceRequirementRepo(e.DB)
+		ar := repositories.NewAssociateRepo(e.DB)
+		vtr := repositories.NewInsuranceRequirementRepo(e.DB)
+
+		arr, err := ListAllAssociateInsuranceRequirements(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oss := range arr {
+			oss := oss
+			rows = append(rows, &etlRow{
+				OldId:  oss.Id,
+				Record: oss,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertAssociateInsuranceRequirementETL(ctx, e.TenantId, asr.WithTx(tx), ar.WithTx(tx), vtr.WithTx(tx), oss)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldAssociateInsuranceRequirement struct {
@@ -120,13 +102,13 @@ func ListAllAssociateInsuranceRequirements(db *sql.DB) ([]*OldAssociateInsurance
 			&m.InsuranceRequirementId,
 		)
 		if err != nil {
-			log.Fatal("rows.Scan", err)
+			log.Panic("rows.Scan", err)
 		}
 		arr = append(arr, m)
 	}
 	err = rows.Err()
 	if err != nil {
-		log.Fatal("rows.Err", err)
+		log.Panic("rows.Err", err)
 	}
 	return arr, err
 }
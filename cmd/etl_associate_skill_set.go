@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +12,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -26,6 +24,7 @@ func init() {
 	assETLCmd.MarkFlagRequired("schema_name")
 	assETLCmd.Flags().IntVarP(&assETLTenantId, "tenant_id", "t", 0, "Tenant Id that this data belongs to")
 	assETLCmd.MarkFlagRequired("tenant_id")
+	addETLFlags(assETLCmd)
 	rootCmd.AddCommand(assETLCmd)
 }
 
@@ -39,51 +38,34 @@ var assETLCmd = &cobra.Command{
 }
 
 func doRunImportAssociateSkillSet() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, assETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	assr := repositories.NewAssociateSkillSetRepo(db)
-	ar := repositories.NewAssociateRepo(db)
-	ssr := repositories.NewSkillSetRepo(db)
-
-	runAssociateSkillSetETL(ctx, uint64(assETLTenantId), assr, ar, ssr, oldDb)
+	runETL(assETLSchemaName, uint64(assETLTenantId), []*etlStep{associateSkillSetETLStep})
 }
 
-func runAssociateSkillSetETL(
-	ctx context.Context,
-	tenantId uint64,
-	assr *repositories.AssociateSkillSetRepo,
-	ar *repositories.AssociateRepo,
-	ssr *repositories.SkillSetRepo,
-	oldDb *sql.DB,
-) {
-	asss, err := ListAllAssociateSkillSets(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, oss := range asss {
-		insertAssociateSkillSetETL(ctx, tenantId, assr, ar, ssr, oss)
-	}
+var associateSkillSetETLStep = &etlStep{
+	Name: "associate_skill_set",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		assr := repositories.NewAssociateSkillSetRepo(e.DB)
+		ar := repositories.NewAssociateRepo(e.DB)
+		ssr := repositories.NewSkillSetRepo(e.DB)
+
+		arr, err := ListAllAssociateSkillSets(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oss := range arr {
+			oss := oss
+			rows = append(rows, &etlRow{
+				OldId:  oss.Id,
+				Record: oss,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertAssociateSkillSetETL(ctx, e.TenantId, assr.WithTx(tx), ar.WithTx(tx), ssr.WithTx(tx), oss)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldAssociateSkillSet struct {
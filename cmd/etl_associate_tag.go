@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +12,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -26,6 +24,7 @@ func init() {
 	aatETLCmd.MarkFlagRequired("schema_name")
 	aatETLCmd.Flags().IntVarP(&aatETLTenantId, "tenant_id", "t", 0, "Tenant Id that this data belongs to")
 	aatETLCmd.MarkFlagRequired("tenant_id")
+	addETLFlags(aatETLCmd)
 	rootCmd.AddCommand(aatETLCmd)
 }
 
@@ -39,51 +38,34 @@ var aatETLCmd = &cobra.Command{
 }
 
 func doRunImportAssociateTag() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, aatETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	aatr := repositories.NewAssociateTagRepo(db)
-	ar := repositories.NewAssociateRepo(db)
-	vtr := repositories.NewTagRepo(db)
-
-	runAssociateTagETL(ctx, uint64(aatETLTenantId), aatr, ar, vtr, oldDb)
+	runETL(aatETLSchemaName, uint64(aatETLTenantId), []*etlStep{associateTagETLStep})
 }
 
-func runAssociateTagETL(
-	ctx context.Context,
-	tenantId uint64,
-	aatr *repositories.AssociateTagRepo,
-	ar *repositories.AssociateRepo,
-	vtr *repositories.TagRepo,
-	oldDb *sql.DB,
-) {
-	aats, err := ListAllAssociateTags(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, oss := range aats {
-		insertAssociateTagETL(ctx, tenantId, aatr, ar, vtr, oss)
-	}
+var associateTagETLStep = &etlStep{
+	Name: "associate_tag",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		aatr := repositories.NewAssociateTagRepo(e.DB)
+		ar := repositories.NewAssociateRepo(e.DB)
+		vtr := repositories.NewTagRepo(e.DB)
+
+		arr, err := ListAllAssociateTags(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oss := range arr {
+			oss := oss
+			rows = append(rows, &etlRow{
+				OldId:  oss.Id,
+				Record: oss,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertAssociateTagETL(ctx, e.TenantId, aatr.WithTx(tx), ar.WithTx(tx), vtr.WithTx(tx), oss)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldAssociateTag struct {
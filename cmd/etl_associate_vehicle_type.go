@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +12,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -26,6 +24,7 @@ func init() {
 	avtETLCmd.MarkFlagRequired("schema_name")
 	avtETLCmd.Flags().IntVarP(&avtETLTenantId, "tenant_id", "t", 0, "Tenant Id that this data belongs to")
 	avtETLCmd.MarkFlagRequired("tenant_id")
+	addETLFlags(avtETLCmd)
 	rootCmd.AddCommand(avtETLCmd)
 }
 
@@ -39,51 +38,34 @@ var avtETLCmd = &cobra.Command{
 }
 
 func doRunImportAssociateVehicleType() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, avtETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	avtr := repositories.NewAssociateVehicleTypeRepo(db)
-	ar := repositories.NewAssociateRepo(db)
-	vtr := repositories.NewVehicleTypeRepo(db)
-
-	runAssociateVehicleTypeETL(ctx, uint64(avtETLTenantId), avtr, ar, vtr, oldDb)
+	runETL(avtETLSchemaName, uint64(avtETLTenantId), []*etlStep{associateVehicleTypeETLStep})
 }
 
-func runAssociateVehicleTypeETL(
-	ctx context.Context,
-	tenantId uint64,
-	avtr *repositories.AssociateVehicleTypeRepo,
-	ar *repositories.AssociateRepo,
-	vtr *repositories.VehicleTypeRepo,
-	oldDb *sql.DB,
-) {
-	avts, err := ListAllAssociateVehicleTypes(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, oss := range avts {
-		insertAssociateVehicleTypeETL(ctx, tenantId, avtr, ar, vtr, oss)
-	}
+var associateVehicleTypeETLStep = &etlStep{
+	Name: "associate_vehicle_type",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		avtr := repositories.NewAssociateVehicleTypeRepo(e.DB)
+		ar := repositories.NewAssociateRepo(e.DB)
+		vtr := repositories.NewVehicleTypeRepo(e.DB)
+
+		arr, err := ListAllAssociateVehicleTypes(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oss := range arr {
+			oss := oss
+			rows = append(rows, &etlRow{
+				OldId:  oss.Id,
+				Record: oss,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertAssociateVehicleTypeETL(ctx, e.TenantId, avtr.WithTx(tx), ar.WithTx(tx), vtr.WithTx(tx), oss)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldAssociateVehicleType struct {
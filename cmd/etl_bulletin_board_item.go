@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,7 +13,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -24,6 +22,7 @@ var (
 func init() {
 	bulletinBoardItemETLCmd.Flags().StringVarP(&bulletinBoardItemETLSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	bulletinBoardItemETLCmd.MarkFlagRequired("schema_name")
+	addETLFlags(bulletinBoardItemETLCmd)
 	rootCmd.AddCommand(bulletinBoardItemETLCmd)
 }
 
@@ -37,43 +36,33 @@ var bulletinBoardItemETLCmd = &cobra.Command{
 }
 
 func doRunImportBulletinBoardItem() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, bulletinBoardItemETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	irr := repositories.NewBulletinBoardItemRepo(db)
-	ur := repositories.NewUserRepo(db)
+	runETL(bulletinBoardItemETLSchemaName, 0, []*etlStep{bulletinBoardItemETLStep})
+}
 
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, bulletinBoardItemETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant == nil {
-		log.Fatal("Tenant does not exist!")
-	}
+var bulletinBoardItemETLStep = &etlStep{
+	Name: "bulletin_board_item",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		irr := repositories.NewBulletinBoardItemRepo(e.DB)
+		ur := repositories.NewUserRepo(e.DB)
 
-	runBulletinBoardItemETL(ctx, tenant.Id, irr, ur, oldDb)
+		arr, err := ListAllBulletinBoardItems(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oir := range arr {
+			oir := oir
+			rows = append(rows, &etlRow{
+				OldId:  oir.Id,
+				Record: oir,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertBulletinBoardItemETL(ctx, e.TenantId, irr.WithTx(tx), ur.WithTx(tx), oir)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldBulletinBoardItem struct {
@@ -133,16 +122,6 @@ func ListAllBulletinBoardItems(db *sql.DB) ([]*OldBulletinBoardItem, error) {
 	return arr, err
 }
 
-func runBulletinBoardItemETL(ctx context.Context, tenantId uint64, irr *repositories.BulletinBoardItemRepo, ur *repositories.UserRepo, oldDb *sql.DB) {
-	bulletin_board_item, err := ListAllBulletinBoardItems(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, oir := range bulletin_board_item {
-		insertBulletinBoardItemETL(ctx, tenantId, irr, ur, oir)
-	}
-}
-
 func insertBulletinBoardItemETL(ctx context.Context, tid uint64, irr *repositories.BulletinBoardItemRepo, ur *repositories.UserRepo, oir *OldBulletinBoardItem) {
 	//
 	// Set the `state`.
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,7 +13,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -24,6 +22,7 @@ var (
 func init() {
 	commentETLCmd.Flags().StringVarP(&commentETLSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	commentETLCmd.MarkFlagRequired("schema_name")
+	addETLFlags(commentETLCmd)
 	rootCmd.AddCommand(commentETLCmd)
 }
 
@@ -37,43 +36,33 @@ var commentETLCmd = &cobra.Command{
 }
 
 func doRunImportComment() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, commentETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	irr := repositories.NewCommentRepo(db)
-	ur := repositories.NewUserRepo(db)
+	runETL(commentETLSchemaName, 0, []*etlStep{commentETLStep})
+}
 
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, commentETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant == nil {
-		log.Fatal("Tenant does not exist!")
-	}
+var commentETLStep = &etlStep{
+	Name: "comment",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		irr := repositories.NewCommentRepo(e.DB)
+		ur := repositories.NewUserRepo(e.DB)
 
-	runCommentETL(ctx, tenant.Id, irr, ur, oldDb)
+		arr, err := ListAllComments(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oir := range arr {
+			oir := oir
+			rows = append(rows, &etlRow{
+				OldId:  oir.Id,
+				Record: oir,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertCommentETL(ctx, e.TenantId, irr.WithTx(tx), ur.WithTx(tx), oir)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldComment struct {
@@ -133,16 +122,6 @@ func ListAllComments(db *sql.DB) ([]*OldComment, error) {
 	return arr, err
 }
 
-func runCommentETL(ctx context.Context, tenantId uint64, irr *repositories.CommentRepo, ur *repositories.UserRepo, oldDb *sql.DB) {
-	comments, err := ListAllComments(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, oir := range comments {
-		insertCommentETL(ctx, tenantId, irr, ur, oir)
-	}
-}
-
 func insertCommentETL(ctx context.Context, tid uint64, irr *repositories.CommentRepo, ur *repositories.UserRepo, oir *OldComment) {
 	//
 	// Set the `state`.
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -16,7 +15,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -26,6 +24,7 @@ var (
 func init() {
 	customerETLCmd.Flags().StringVarP(&customerETLSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	customerETLCmd.MarkFlagRequired("schema_name")
+	addETLFlags(customerETLCmd)
 	rootCmd.AddCommand(customerETLCmd)
 }
 
@@ -39,44 +38,34 @@ var customerETLCmd = &cobra.Command{
 }
 
 func doRunImportCustomer() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, customerETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
+	runETL(customerETLSchemaName, 0, []*etlStep{customerETLStep})
+}
 
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	ur := repositories.NewUserRepo(db)
-	om := repositories.NewCustomerRepo(db)
-	r := repositories.NewHowHearAboutUsItemRepo(db)
+var customerETLStep = &etlStep{
+	Name: "customer",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		ur := repositories.NewUserRepo(e.DB)
+		omr := repositories.NewCustomerRepo(e.DB)
+		r := repositories.NewHowHearAboutUsItemRepo(e.DB)
 
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, customerETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant == nil {
-		log.Fatal("Tenant does not exist!")
-	}
-
-	runCustomerETL(ctx, tenant.Id, ur, om, r, oldDb)
+		arr, err := ListAllCustomers(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, om := range arr {
+			om := om
+			rows = append(rows, &etlRow{
+				OldId:  om.Id,
+				Record: om,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertCustomerETL(ctx, e.TenantId, ur.WithTx(tx), omr.WithTx(tx), r.WithTx(tx), om)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldUCustomer struct {
@@ -198,34 +187,17 @@ func ListAllCustomers(db *sql.DB) ([]*OldUCustomer, error) {
 			&m.OrganizationTypeOf, &m.AvatarImageId,
 		)
 		if err != nil {
-			log.Fatal("(AA)", err)
+			log.Panic("(AA)", err)
 		}
 		arr = append(arr, m)
 	}
 	err = rows.Err()
 	if err != nil {
-		log.Fatal("(BB)", err)
+		log.Panic("(BB)", err)
 	}
 	return arr, err
 }
 
-func runCustomerETL(
-	ctx context.Context,
-	tenantId uint64,
-	ur *repositories.UserRepo,
-	omr *repositories.CustomerRepo,
-	r *repositories.HowHearAboutUsItemRepo,
-	oldDb *sql.DB,
-) {
-	customers, err := ListAllCustomers(oldDb)
-	if err != nil {
-		log.Fatal("(0000)", err)
-	}
-	for _, om := range customers {
-		insertCustomerETL(ctx, tenantId, ur, omr, r, om)
-	}
-}
-
 func insertCustomerETL(
 	ctx context.Context,
 	tid uint64,
@@ -269,10 +241,10 @@ func insertCustomerETL(
 		// log.Println(om.Id)
 		user, err := ur.GetByOldId(ctx, userId)
 		if err != nil {
-			log.Fatal("(A)", err)
+			log.Panic("(A)", err)
 		}
 		if user == nil {
-			log.Fatal("(B) User is null")
+			log.Panic("(B) User is null")
 		}
 		userId = user.Id
 
@@ -418,7 +390,7 @@ func insertCustomerETL(
 	howHearText := ""
 	howHear, err := r.GetById(ctx, howHearId)
 	if err != nil {
-		log.Fatal(err)
+		log.Panic(err)
 		return
 	}
 	if howHearId == 1 {
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +12,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -23,6 +21,7 @@ var (
 func init() {
 	customerCommentETLCmd.Flags().StringVarP(&customerCommentETLSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	customerCommentETLCmd.MarkFlagRequired("schema_name")
+	addETLFlags(customerCommentETLCmd)
 	rootCmd.AddCommand(customerCommentETLCmd)
 }
 
@@ -36,44 +35,44 @@ var customerCommentETLCmd = &cobra.Command{
 }
 
 func doRunImportCustomerComment() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, customerCommentETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
+	runETL(customerCommentETLSchemaName, 0, []*etlStep{customerCommentETLStep})
+}
 
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	irr := repositories.NewCustomerCommentRepo(db)
-	om := repositories.NewCustomerRepo(db)
-	commentr := repositories.NewCommentRepo(db)
+var customerCommentETLStep = &etlStep{
+	Name: "customer_comment",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		irr := repositories.NewCustomerCommentRepo(e.DB)
+		om := repositories.NewCustomerRepo(e.DB)
+		tr := repositories.NewCommentRepo(e.DB)
 
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, customerCommentETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant == nil {
-		log.Fatal("Tenant does not exist!")
-	}
-
-	runCustomerCommentETL(ctx, tenant.Id, irr, om, commentr, oldDb)
+		arr, err := ListAllCustomerComments(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oir := range arr {
+			oir := oir
+			rows = append(rows, &etlRow{
+				OldId:  oir.Id,
+				Record: oir,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					customerId, err := om.WithTx(tx).GetIdByOldId(ctx, e.TenantId, oir.CustomerId)
+					if err != nil {
+						return err
+					}
+
+					commentId, err := tr.WithTx(tx).GetIdByOldId(ctx, e.TenantId, oir.CommentId)
+					if err != nil {
+						return err
+					}
+
+					insertCustomerCommentETL(ctx, e.TenantId, oir.Id, customerId, commentId, irr.WithTx(tx))
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldUCustomerComment struct {
@@ -123,27 +122,6 @@ func ListAllCustomerComments(db *sql.DB) ([]*OldUCustomerComment, error) {
 	return arr, err
 }
 
-func runCustomerCommentETL(ctx context.Context, tenantId uint64, irr *repositories.CustomerCommentRepo, om *repositories.CustomerRepo, tr *repositories.CommentRepo, oldDb *sql.DB) {
-	customerComments, err := ListAllCustomerComments(oldDb)
-	if err != nil {
-		log.Fatal("runCustomerCommentETL | ListAllCustomerComments | err", err)
-	}
-	for _, oir := range customerComments {
-
-		customerId, err := om.GetIdByOldId(ctx, tenantId, oir.CustomerId)
-		if err != nil {
-			log.Panic("runCustomerCommentETL | om.GetIdByOldId | err", err)
-		}
-
-		commentId, err := tr.GetIdByOldId(ctx, tenantId, oir.CommentId)
-		if err != nil {
-			log.Panic("runCustomerCommentETL | tr.GetIdByOldId | err", err)
-		}
-
-		insertCustomerCommentETL(ctx, tenantId, oir.Id, customerId, commentId, irr)
-	}
-}
-
 func insertCustomerCommentETL(ctx context.Context, tenantId uint64, oldId uint64, customerId uint64, commentId uint64, irr *repositories.CustomerCommentRepo) {
 	fmt.Println("Pre-Imported Customer Comment ID#", oldId)
 	m := &models.CustomerComment{
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +12,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -23,6 +21,7 @@ var (
 func init() {
 	customerTagETLCmd.Flags().StringVarP(&customerTagETLSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	customerTagETLCmd.MarkFlagRequired("schema_name")
+	addETLFlags(customerTagETLCmd)
 	rootCmd.AddCommand(customerTagETLCmd)
 }
 
@@ -36,44 +35,44 @@ var customerTagETLCmd = &cobra.Command{
 }
 
 func doRunImportCustomerTag() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, customerTagETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
+	runETL(customerTagETLSchemaName, 0, []*etlStep{customerTagETLStep})
+}
 
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	irr := repositories.NewCustomerTagRepo(db)
-	om := repositories.NewCustomerRepo(db)
-	tagr := repositories.NewTagRepo(db)
+var customerTagETLStep = &etlStep{
+	Name: "customer_tag",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		irr := repositories.NewCustomerTagRepo(e.DB)
+		om := repositories.NewCustomerRepo(e.DB)
+		tr := repositories.NewTagRepo(e.DB)
 
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, customerTagETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant == nil {
-		log.Fatal("Tenant does not exist!")
-	}
-
-	runCustomerTagETL(ctx, tenant.Id, irr, om, tagr, oldDb)
+		arr, err := ListAllCustomerTags(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oir := range arr {
+			oir := oir
+			rows = append(rows, &etlRow{
+				OldId:  oir.Id,
+				Record: oir,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					customerId, err := om.WithTx(tx).GetIdByOldId(ctx, e.TenantId, oir.CustomerId)
+					if err != nil {
+						return err
+					}
+
+					tagId, err := tr.WithTx(tx).GetIdByOldId(ctx, e.TenantId, oir.TagId)
+					if err != nil {
+						return err
+					}
+
+					insertCustomerTagETL(ctx, e.TenantId, oir.Id, customerId, tagId, irr.WithTx(tx))
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldUCustomerTag struct {
@@ -121,27 +120,6 @@ func ListAllCustomerTags(db *sql.DB) ([]*OldUCustomerTag, error) {
 	return arr, err
 }
 
-func runCustomerTagETL(ctx context.Context, tenantId uint64, irr *repositories.CustomerTagRepo, om *repositories.CustomerRepo, tr *repositories.TagRepo, oldDb *sql.DB) {
-	customerTags, err := ListAllCustomerTags(oldDb)
-	if err != nil {
-		log.Fatal("runCustomerTagETL | ListAllCustomerTags | err", err)
-	}
-	for _, oir := range customerTags {
-
-		customerId, err := om.GetIdByOldId(ctx, tenantId, oir.CustomerId)
-		if err != nil {
-			log.Panic("runCustomerTagETL | om.GetIdByOldId | err", err)
-		}
-
-		tagId, err := tr.GetIdByOldId(ctx, tenantId, oir.TagId)
-		if err != nil {
-			log.Panic("runCustomerTagETL | tr.GetIdByOldId | err", err)
-		}
-
-		insertCustomerTagETL(ctx, tenantId, oir.Id, customerId, tagId, irr)
-	}
-}
-
 func insertCustomerTagETL(ctx context.Context, tenantId uint64, oldId uint64, customerId uint64, tagId uint64, irr *repositories.CustomerTagRepo) {
 	fmt.Println("Pre-Imported Customer Tag ID#", oldId)
 	m := &models.CustomerTag{
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/over55/workery-server/internal/repositories"
+	"github.com/over55/workery-server/internal/utils"
+)
+
+const etlFixTimestampsStep = "fix_timestamps"
+
+var (
+	etlFixTimestampsSchemaName   string
+	etlFixTimestampsFromTimezone string
+	etlFixTimestampsDryRun       bool
+)
+
+func init() {
+	etlFixTimestampsCmd.Flags().StringVarP(&etlFixTimestampsSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
+	etlFixTimestampsCmd.MarkFlagRequired("schema_name")
+	etlFixTimestampsCmd.Flags().StringVar(&etlFixTimestampsFromTimezone, "from_timezone", "", "The timezone the imported values were saved in, defaults to the timezone of the tenant.")
+	etlFixTimestampsCmd.Flags().BoolVar(&etlFixTimestampsDryRun, "dry-run", false, "Count the rows which would be corrected without saving.")
+	rootCmd.AddCommand(etlFixTimestampsCmd)
+}
+
+var etlFixTimestampsCmd = &cobra.Command{
+	Use:   "etl_fix_timestamps",
+	Short: "Correct the timestamps of the already imported tenant data to UTC",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		doRunETLFixTimestamps()
+	},
+}
+
+// etlTimestampColumns are the tables with timestamps which the older ETL
+// saved in the local time of the tenant instead of UTC. Please note the ETL
+// converts the old times to UTC now so only the tenants imported before that
+// change need to be corrected, those are marked by the `0018` migration.
+var etlTimestampColumns = []struct {
+	Table   string
+	Columns []string
+}{
+	{"users", []string{"created_time", "modified_time"}},
+	{"comments", []string{"created_time", "last_modified_time"}},
+	{"work_order_service_fees", []string{"created_time", "last_modified_time"}},
+	{"bulletin_board_items", []string{"created_time", "last_modified_time"}},
+	{"customers", []string{"created_time", "last_modified_time"}},
+	{"customer_tags", []string{"created_time"}},
+	{"customer_comments", []string{"created_time"}},
+	{"associates", []string{"created_time", "last_modified_time"}},
+	{"associate_away_logs", []string{"created_time", "last_modified_time"}},
+	{"ongoing_work_orders", []string{"created_time", "last_modified_time"}},
+	{"work_orders", []string{"created_time", "last_modified_time"}},
+	{"activity_sheet_items", []string{"created_time"}},
+	{"work_order_comments", []string{"created_time"}},
+	{"work_order_invoices", []string{"created_time", "last_modified_time"}},
+	{"work_order_deposits", []string{"created_time", "last_modified_time"}},
+	{"task_items", []string{"created_time", "last_modified_time"}},
+	{"staff", []string{"created_time", "last_modified_time"}},
+	{"partners", []string{"created_time", "last_modified_time"}},
+	{"private_files", []string{"created_time", "last_modified_time"}},
+}
+
+func doRunETLFixTimestamps() {
+	ctx := context.Background()
+
+	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	tr := repositories.NewTenantRepo(db)
+	cr := repositories.NewETLCheckpointRepo(db)
+
+	// Lookup the tenant.
+	tenant, err := tr.GetBySchemaName(ctx, etlFixTimestampsSchemaName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if tenant == nil {
+		log.Fatal("Tenant does not exist!")
+	}
+
+	timezone := etlFixTimestampsFromTimezone
+	if timezone == "" {
+		timezone = tenant.Timezone
+	}
+	if timezone == "" {
+		timezone = utils.DefaultTimezone
+	}
+	if _, err := utils.GetTimezoneLocation(timezone); err != nil {
+		log.Fatal(err)
+	}
+
+	// DEVELOPERS NOTE:
+	// Running the correction twice would shift the values twice so we keep
+	// a checkpoint to prevent that from happening. The checkpoint is only
+	// added for the tenants imported before the ETL converted the old times
+	// and the ETL refuses to import into those tenants until they are
+	// corrected, so every imported row we find here needs the correction.
+	cp, err := cr.GetBySchemaNameAndStep(ctx, etlFixTimestampsSchemaName, etlFixTimestampsStep)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if cp == nil {
+		log.Fatal("The timestamps of this tenant were imported in UTC, nothing to correct")
+	}
+	if cp.IsCompleted {
+		log.Fatal("The timestamps of this tenant were already corrected on ", cp.LastModifiedTime)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	var total int64
+	for _, tc := range etlTimestampColumns {
+		var sets []string
+		for _, c := range tc.Columns {
+			sets = append(sets, c+" = ("+c+" AT TIME ZONE $2) AT TIME ZONE 'UTC'")
+		}
+		query := "UPDATE " + tc.Table + " SET " + strings.Join(sets, ", ") + " WHERE tenant_id = $1 AND old_id <> 0"
+		res, err := tx.ExecContext(ctx, query, tenant.Id, timezone)
+		if err != nil {
+			log.Fatal(tc.Table, ": ", err)
+		}
+		count, err := res.RowsAffected()
+		if err != nil {
+			log.Fatal(tc.Table, ": ", err)
+		}
+		total += count
+		log.Println("ETL | Fix timestamps | Table:", tc.Table, "| Rows:", count)
+	}
+
+	if etlFixTimestampsDryRun {
+		log.Println("ETL | Fix timestamps | Dry run, rolling back", total, "rows from", timezone, "to UTC")
+		return
+	}
+
+	cp.ImportedRows = uint64(total)
+	cp.IsCompleted = true
+	cp.LastModifiedTime = time.Now()
+	if err := cr.WithTx(tx).InsertOrUpdateBySchemaNameAndStep(ctx, cp); err != nil {
+		log.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Fatal(err)
+	}
+	log.Println("ETL | Fix timestamps | Corrected", total, "rows from", timezone, "to UTC")
+}
@@ -6,7 +6,6 @@ import (
 	// "encoding/csv"
 	"database/sql"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,7 +13,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -23,10 +21,10 @@ var (
 )
 
 func init() {
-	howHearAboutUsItemETLCmd.Flags().StringVarP(&etlhhauiTenantSchema, "schema_name", "s", "public", "Schema name of the old workery")
+	howHearAboutUsItemETLCmd.Flags().StringVarP(&etlhhauiTenantSchema, "schema_name", "s", "", "The schema name of the tenant in the postgres.")
 	howHearAboutUsItemETLCmd.MarkFlagRequired("schema_name")
-	howHearAboutUsItemETLCmd.Flags().IntVarP(&etlhhauiTenantId, "tenant_id", "t", 0, "Tenant Id that this data belongs to")
-	howHearAboutUsItemETLCmd.MarkFlagRequired("tenant_id")
+	howHearAboutUsItemETLCmd.Flags().IntVarP(&etlhhauiTenantId, "tenant_id", "t", 0, "Tenant Id that this data belongs to, if not set then it is looked up by the schema name")
+	addETLFlags(howHearAboutUsItemETLCmd)
 	rootCmd.AddCommand(howHearAboutUsItemETLCmd)
 }
 
@@ -35,45 +33,37 @@ var howHearAboutUsItemETLCmd = &cobra.Command{
 	Short: "Import the how_hear_about_us_item data from old workery",
 	Long:  ``,
 	Run: func(cmd *cobra.Command, args []string) {
-		doRunImportHowHearAboutUsItem(uint64(etlhhauiTenantId))
+		doRunImportHowHearAboutUsItem()
 	},
 }
 
-func doRunImportHowHearAboutUsItem(tid uint64) {
-	// Load up our database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our repositories.
-	r := repositories.NewHowHearAboutUsItemRepo(db)
-
-	// Load up our old database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, etlhhauiTenantSchema)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Begin the operation.
-	runHowHearAboutUsItemETL(tid, r, oldDb)
+func doRunImportHowHearAboutUsItem() {
+	runETL(etlhhauiTenantSchema, uint64(etlhhauiTenantId), []*etlStep{howHearAboutUsItemETLStep})
 }
 
-func runHowHearAboutUsItemETL(tid uint64, r *repositories.HowHearAboutUsItemRepo, oldDb *sql.DB) {
-	items, err := ListAllHowHearAboutUsItems(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, v := range items {
-		runHowHearAboutUsItemInsert(tid, v, r)
-	}
+var howHearAboutUsItemETLStep = &etlStep{
+	Name: "how_hear_about_us_item",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		r := repositories.NewHowHearAboutUsItemRepo(e.DB)
+
+		arr, err := ListAllHowHearAboutUsItems(e.OldPublicDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, v := range arr {
+			v := v
+			rows = append(rows, &etlRow{
+				OldId:  v.Id,
+				Record: v,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					runHowHearAboutUsItemInsert(e.TenantId, v, r.WithTx(tx))
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldHowHearAboutUsItem struct {
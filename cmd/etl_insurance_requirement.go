@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +12,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -23,6 +21,7 @@ var (
 func init() {
 	insuranceRequirementETLCmd.Flags().StringVarP(&insuranceRequirementETLSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	insuranceRequirementETLCmd.MarkFlagRequired("schema_name")
+	addETLFlags(insuranceRequirementETLCmd)
 	rootCmd.AddCommand(insuranceRequirementETLCmd)
 }
 
@@ -36,39 +35,32 @@ var insuranceRequirementETLCmd = &cobra.Command{
 }
 
 func doRunImportInsuranceRequirement() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, insuranceRequirementETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
+	runETL(insuranceRequirementETLSchemaName, 0, []*etlStep{insuranceRequirementETLStep})
+}
 
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	irr := repositories.NewInsuranceRequirementRepo(db)
+var insuranceRequirementETLStep = &etlStep{
+	Name: "insurance_requirement",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		irr := repositories.NewInsuranceRequirementRepo(e.DB)
 
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, insuranceRequirementETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	runInsuranceRequirementETL(ctx, tenant.Id, irr, oldDb)
+		arr, err := ListAllInsuranceRequirements(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oir := range arr {
+			oir := oir
+			rows = append(rows, &etlRow{
+				OldId:  oir.Id,
+				Record: oir,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertInsuranceRequirementETL(ctx, e.TenantId, irr.WithTx(tx), oir)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldUInsuranceRequirement struct {
@@ -118,16 +110,6 @@ func ListAllInsuranceRequirements(db *sql.DB) ([]*OldUInsuranceRequirement, erro
 	return arr, err
 }
 
-func runInsuranceRequirementETL(ctx context.Context, tenantId uint64, irr *repositories.InsuranceRequirementRepo, oldDb *sql.DB) {
-	insuranceRequirements, err := ListAllInsuranceRequirements(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, oir := range insuranceRequirements {
-		insertInsuranceRequirementETL(ctx, tenantId, irr, oir)
-	}
-}
-
 func insertInsuranceRequirementETL(ctx context.Context, tid uint64, irr *repositories.InsuranceRequirementRepo, oir *OldUInsuranceRequirement) {
 	var state int8 = 1
 	if oir.IsArchived == true {
@@ -141,6 +123,8 @@ func insertInsuranceRequirementETL(ctx context.Context, tid uint64, irr *reposit
 		Text:        oir.Text,
 		Description: oir.Description,
 		State:       state,
+
+		AssociateDateTypeOf: models.GuessInsuranceRequirementAssociateDateTypeOf(oir.Text),
 	}
 	err := irr.Insert(ctx, m)
 	if err != nil {
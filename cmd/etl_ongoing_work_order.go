@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,7 +13,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -27,6 +25,7 @@ func init() {
 	owoETLCmd.MarkFlagRequired("schema_name")
 	owoETLCmd.Flags().IntVarP(&owoETLTenantId, "tenant_id", "t", 0, "Tenant Id that this data belongs to")
 	owoETLCmd.MarkFlagRequired("tenant_id")
+	addETLFlags(owoETLCmd)
 	rootCmd.AddCommand(owoETLCmd)
 }
 
@@ -40,53 +39,35 @@ var owoETLCmd = &cobra.Command{
 }
 
 func doRunImportOngoingWorkOrder() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal("utils.ConnectDB", err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, owoETLSchemaName)
-	if err != nil {
-		log.Fatal("utils.ConnectDB", err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	asr := repositories.NewOngoingWorkOrderRepo(db)
-	ar := repositories.NewAssociateRepo(db)
-	cr := repositories.NewCustomerRepo(db)
-	ur := repositories.NewUserRepo(db)
-
-	runOngoingWorkOrderETL(ctx, uint64(owoETLTenantId), asr, ar, cr, ur, oldDb)
+	runETL(owoETLSchemaName, uint64(owoETLTenantId), []*etlStep{ongoingWorkOrderETLStep})
 }
 
-func runOngoingWorkOrderETL(
-	ctx context.Context,
-	tenantId uint64,
-	asr *repositories.OngoingWorkOrderRepo,
-	ar *repositories.AssociateRepo,
-	cr *repositories.CustomerRepo,
-	ur *repositories.UserRepo,
-	oldDb *sql.DB,
-) {
-	ass, err := ListAllOngoingWorkOrders(oldDb)
-	if err != nil {
-		log.Fatal("ListAllOngoingWorkOrders", err)
-	}
-	for _, oss := range ass {
-		insertOngoingWorkOrderETL(ctx, tenantId, asr, ar, cr, ur, oss)
-	}
+var ongoingWorkOrderETLStep = &etlStep{
+	Name: "ongoing_work_order",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		asr := repositories.NewOngoingWorkOrderRepo(e.DB)
+		ar := repositories.NewAssociateRepo(e.DB)
+		cr := repositories.NewCustomerRepo(e.DB)
+		ur := repositories.NewUserRepo(e.DB)
+
+		arr, err := ListAllOngoingWorkOrders(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oss := range arr {
+			oss := oss
+			rows = append(rows, &etlRow{
+				OldId:  oss.Id,
+				Record: oss,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertOngoingWorkOrderETL(ctx, e.TenantId, asr.WithTx(tx), ar.WithTx(tx), cr.WithTx(tx), ur.WithTx(tx), oss)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldOngoingWorkOrder struct {
@@ -137,13 +118,13 @@ func ListAllOngoingWorkOrders(db *sql.DB) ([]*OldOngoingWorkOrder, error) {
 			&m.LastModifiedFrom,
 		)
 		if err != nil {
-			log.Fatal("ListAllOngoingWorkOrders | rows.Scan", err)
+			log.Panic("ListAllOngoingWorkOrders | rows.Scan", err)
 		}
 		arr = append(arr, m)
 	}
 	err = rows.Err()
 	if err != nil {
-		log.Fatal("ListAllOngoingWorkOrders | rows.Err", err)
+		log.Panic("ListAllOngoingWorkOrders | rows.Err", err)
 	}
 	return arr, err
 }
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -16,7 +15,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -26,6 +24,7 @@ var (
 func init() {
 	partnerETLCmd.Flags().StringVarP(&partnerETLSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	partnerETLCmd.MarkFlagRequired("schema_name")
+	addETLFlags(partnerETLCmd)
 	rootCmd.AddCommand(partnerETLCmd)
 }
 
@@ -39,45 +38,34 @@ var partnerETLCmd = &cobra.Command{
 }
 
 func doRunImportPartner() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, partnerETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
+	runETL(partnerETLSchemaName, 0, []*etlStep{partnerETLStep})
+}
 
-	// Load up our repositories.
-	tenantRepo := repositories.NewTenantRepo(db)
-	userRepo := repositories.NewUserRepo(db)
-	partnerRepo := repositories.NewPartnerRepo(db)
-	serviceFeeRepo := repositories.NewWorkOrderServiceFeeRepo(db)
-	r := repositories.NewHowHearAboutUsItemRepo(db)
+var partnerETLStep = &etlStep{
+	Name: "partner",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		userRepo := repositories.NewUserRepo(e.DB)
+		partnerRepo := repositories.NewPartnerRepo(e.DB)
+		r := repositories.NewHowHearAboutUsItemRepo(e.DB)
 
-	// Lookup the tenant.
-	tenant, err := tenantRepo.GetBySchemaName(ctx, partnerETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant == nil {
-		log.Fatal("Tenant does not exist!")
-	}
-
-	runPartnerETL(ctx, tenant.Id, userRepo, partnerRepo, serviceFeeRepo, r, oldDb)
+		arr, err := ListAllPartners(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oldPartner := range arr {
+			oldPartner := oldPartner
+			rows = append(rows, &etlRow{
+				OldId:  oldPartner.Id,
+				Record: oldPartner,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertPartnerETL(ctx, e.TenantId, userRepo.WithTx(tx), partnerRepo.WithTx(tx), r.WithTx(tx), oldPartner)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldUPartner struct {
@@ -188,35 +176,17 @@ func ListAllPartners(db *sql.DB) ([]*OldUPartner, error) {
 			&m.OrganizationTypeOf, &m.AvatarImageId,
 		)
 		if err != nil {
-			log.Fatal("(AA)", err)
+			log.Panic("(AA)", err)
 		}
 		arr = append(arr, m)
 	}
 	err = rows.Err()
 	if err != nil {
-		log.Fatal("(BB)", err)
+		log.Panic("(BB)", err)
 	}
 	return arr, err
 }
 
-func runPartnerETL(
-	ctx context.Context,
-	tenantId uint64,
-	userRepo *repositories.UserRepo,
-	partnerRepo *repositories.PartnerRepo,
-	serviceFeeRepo *repositories.WorkOrderServiceFeeRepo,
-	r *repositories.HowHearAboutUsItemRepo,
-	oldDb *sql.DB,
-) {
-	partners, err := ListAllPartners(oldDb)
-	if err != nil {
-		log.Fatal("runPartnerETL | ListAllPartners | err:", err)
-	}
-	for _, oldPartner := range partners {
-		insertPartnerETL(ctx, tenantId, userRepo, partnerRepo, r, oldPartner)
-	}
-}
-
 func insertPartnerETL(
 	ctx context.Context,
 	tenantId uint64,
@@ -261,10 +231,10 @@ func insertPartnerETL(
 		// log.Println(oldPartner.Id)
 		user, err := userRepo.GetByOldId(ctx, userId)
 		if err != nil {
-			log.Fatal("(A)", err)
+			log.Panic("(A)", err)
 		}
 		if user == nil {
-			log.Fatal("(B) User is null")
+			log.Panic("(B) User is null")
 		}
 		userId = user.Id
 
@@ -410,7 +380,7 @@ func insertPartnerETL(
 	howHearText := ""
 	howHear, err := r.GetById(ctx, howHearId)
 	if err != nil {
-		log.Fatal(err)
+		log.Panic(err)
 		return
 	}
 	if howHearId == 1 {
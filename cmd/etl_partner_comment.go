@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +12,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -26,6 +24,7 @@ func init() {
 	psETLCmd.MarkFlagRequired("schema_name")
 	psETLCmd.Flags().IntVarP(&psETLTenantId, "tenant_id", "t", 0, "Tenant Id that this data belongs to")
 	psETLCmd.MarkFlagRequired("tenant_id")
+	addETLFlags(psETLCmd)
 	rootCmd.AddCommand(psETLCmd)
 }
 
@@ -39,51 +38,34 @@ var psETLCmd = &cobra.Command{
 }
 
 func doRunImportPartnerComment() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal("utils.ConnectDB", err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, psETLSchemaName)
-	if err != nil {
-		log.Fatal("utils.ConnectDB", err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	asr := repositories.NewPartnerCommentRepo(db)
-	ar := repositories.NewPartnerRepo(db)
-	vtr := repositories.NewCommentRepo(db)
-
-	runPartnerCommentETL(ctx, uint64(psETLTenantId), asr, ar, vtr, oldDb)
+	runETL(psETLSchemaName, uint64(psETLTenantId), []*etlStep{partnerCommentETLStep})
 }
 
-func runPartnerCommentETL(
-	ctx context.Context,
-	tenantId uint64,
-	asr *repositories.PartnerCommentRepo,
-	ar *repositories.PartnerRepo,
-	vtr *repositories.CommentRepo,
-	oldDb *sql.DB,
-) {
-	ass, err := ListAllPartnerComments(oldDb)
-	if err != nil {
-		log.Fatal("ListAllPartnerComments", err)
-	}
-	for _, oss := range ass {
-		insertPartnerCommentETL(ctx, tenantId, asr, ar, vtr, oss)
-	}
+var partnerCommentETLStep = &etlStep{
+	Name: "partner_comment",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		asr := repositories.NewPartnerCommentRepo(e.DB)
+		ar := repositories.NewPartnerRepo(e.DB)
+		vtr := repositories.NewCommentRepo(e.DB)
+
+		arr, err := ListAllPartnerComments(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oss := range arr {
+			oss := oss
+			rows = append(rows, &etlRow{
+				OldId:  oss.Id,
+				Record: oss,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertPartnerCommentETL(ctx, e.TenantId, asr.WithTx(tx), ar.WithTx(tx), vtr.WithTx(tx), oss)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldPartnerComment struct {
@@ -120,13 +102,13 @@ func ListAllPartnerComments(db *sql.DB) ([]*OldPartnerComment, error) {
 			&m.CommentId,
 		)
 		if err != nil {
-			log.Fatal("rows.Scan", err)
+			log.Panic("rows.Scan", err)
 		}
 		arr = append(arr, m)
 	}
 	err = rows.Err()
 	if err != nil {
-		log.Fatal("rows.Err", err)
+		log.Panic("rows.Err", err)
 	}
 	return arr, err
 }
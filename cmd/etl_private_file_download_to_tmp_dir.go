@@ -30,6 +30,7 @@ var (
 func init() {
 	privateFileDownloadToTMPDIRETLCmd.Flags().StringVarP(&privateFileDownloadToTMPDIRETLSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	privateFileDownloadToTMPDIRETLCmd.MarkFlagRequired("schema_name")
+	addETLFlags(privateFileDownloadToTMPDIRETLCmd)
 	rootCmd.AddCommand(privateFileDownloadToTMPDIRETLCmd)
 }
 
@@ -75,51 +76,50 @@ func listAllS3Objects(s3Client *s3.S3, bucketName string) *s3.ListObjectsOutput
 }
 
 func doRunDownloadPrivateFileToTmpDir() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, privateFileDownloadToTMPDIRETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	pfr := repositories.NewPrivateFileRepo(db)
-	ur := repositories.NewUserRepo(db)
-	ar := repositories.NewAssociateRepo(db)
-	cr := repositories.NewCustomerRepo(db)
-	pr := repositories.NewPartnerRepo(db)
-	sr := repositories.NewStaffRepo(db)
-	wor := repositories.NewWorkOrderRepo(db)
-
-	// Load up our S3 instances
-	oldS3Client, oldBucketName := getOldS3ClientInstance()
-
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, privateFileDownloadToTMPDIRETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant == nil {
-		log.Fatal("Tenant does not exist!")
-	}
+	runETL(privateFileDownloadToTMPDIRETLSchemaName, 0, []*etlStep{privateFileDownloadToTMPDIRETLStep})
+}
 
-	runPrivateFileETL(ctx, tenant.Id, pfr, oldDb, oldS3Client, oldBucketName, ur, ar, cr, pr, sr, wor)
+var privateFileDownloadToTMPDIRETLStep = &etlStep{
+	Name:           "private_file_download_to_tmp_dir",
+	HasSideEffects: true,
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		pfr := repositories.NewPrivateFileRepo(e.DB)
+		ur := repositories.NewUserRepo(e.DB)
+		ar := repositories.NewAssociateRepo(e.DB)
+		cr := repositories.NewCustomerRepo(e.DB)
+		pr := repositories.NewPartnerRepo(e.DB)
+		sr := repositories.NewStaffRepo(e.DB)
+		wor := repositories.NewWorkOrderRepo(e.DB)
+
+		// Load up our S3 instances
+		oldS3Client, oldBucketName := getOldS3ClientInstance()
+
+		// Fetch all the database records from the old database at once.
+		uploads, err := ListAllOldPrivateFiles(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+
+		// Fetch all the upload files we have in the old AWS S3 instance.
+		s3Objects := listAllS3Objects(oldS3Client, oldBucketName)
+
+		// Iterate through all the old database records and iterate over the
+		// upload AWS S3 files to match the key, then process the file.
+		var rows []*etlRow
+		for _, upload := range uploads {
+			upload := upload
+			s3key := utils.FindMatchingObjectKeyInS3Bucket(s3Objects, upload.DataFile)
+			rows = append(rows, &etlRow{
+				OldId:  upload.Id,
+				Record: upload,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertPrivateFileETL(ctx, e.TenantId, pfr.WithTx(tx), upload, oldS3Client, oldBucketName, s3key, ur.WithTx(tx), ar.WithTx(tx), cr.WithTx(tx), pr.WithTx(tx), sr.WithTx(tx), wor.WithTx(tx))
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldPrivateFile struct {
@@ -202,37 +202,6 @@ func ListAllOldPrivateFiles(db *sql.DB) ([]*OldPrivateFile, error) {
 	return arr, err
 }
 
-func runPrivateFileETL(
-	ctx context.Context,
-	tid uint64,
-	pfr *repositories.PrivateFileRepo,
-	oldDb *sql.DB,
-	oldS3Client *s3.S3,
-	oldBucketName string,
-	ur *repositories.UserRepo,
-	ar *repositories.AssociateRepo,
-	cr *repositories.CustomerRepo,
-	pr *repositories.PartnerRepo,
-	sr *repositories.StaffRepo,
-	wor *repositories.WorkOrderRepo,
-) {
-	// Fetch all the database records from the old database at once.
-	uploads, err := ListAllOldPrivateFiles(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// Fetch all the upload files we have in the old AWS S3 instance.
-	s3Objects := listAllS3Objects(oldS3Client, oldBucketName)
-
-	// Iterate through all the old database records and iterate over the
-	// upload AWS S3 files to match the key, then process the file.
-	for _, upload := range uploads {
-		s3key := utils.FindMatchingObjectKeyInS3Bucket(s3Objects, upload.DataFile)
-		insertPrivateFileETL(ctx, tid, pfr, upload, oldS3Client, oldBucketName, s3key, ur, ar, cr, pr, sr, wor)
-	}
-}
-
 func insertPrivateFileETL(
 	ctx context.Context,
 	tid uint64,
@@ -399,5 +368,5 @@ func insertPrivateFileETL(
 		log.Panic(err)
 	}
 	fmt.Println("Imported ID#", opf.Id)
-	// log.Fatal("HALT BY PROGRAMMER") For debugging purposes only.
+	// log.Panic("HALT BY PROGRAMMER") For debugging purposes only.
 }
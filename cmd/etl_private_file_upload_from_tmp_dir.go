@@ -32,6 +32,7 @@ var (
 func init() {
 	privateFileUploadToTMPDIRETLCmd.Flags().StringVarP(&privateFileUploadToTMPDIRETLSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	privateFileUploadToTMPDIRETLCmd.MarkFlagRequired("schema_name")
+	addETLFlags(privateFileUploadToTMPDIRETLCmd)
 	rootCmd.AddCommand(privateFileUploadToTMPDIRETLCmd)
 }
 
@@ -109,39 +110,35 @@ func ListAllPrivateFilesByTenantId(db *sql.DB, tenantId uint64) ([]*models.Priva
 }
 
 func doRunUploadPrivateFileFromTmpDir() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	pfr := repositories.NewPrivateFileRepo(db)
+	runETL(privateFileUploadToTMPDIRETLSchemaName, 0, []*etlStep{privateFileUploadToTMPDIRETLStep})
+}
 
-	// Load up our S3 instances
-	s3Client, bucketName := getS3ClientInstance()
+var privateFileUploadToTMPDIRETLStep = &etlStep{
+	Name:           "private_file_upload_from_tmp_dir",
+	HasSideEffects: true,
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		pfr := repositories.NewPrivateFileRepo(e.DB)
 
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, privateFileUploadToTMPDIRETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant == nil {
-		log.Fatal("Tenant does not exist!")
-	}
+		// Load up our S3 instances
+		s3Client, bucketName := getS3ClientInstance()
 
-	privateFiles, err := ListAllPrivateFilesByTenantId(db, tenant.Id)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, privateFile := range privateFiles {
-		uploadPrivateFileToS3(pfr, s3Client, bucketName, privateFile)
-	}
+		privateFiles, err := ListAllPrivateFilesByTenantId(e.DB, e.TenantId)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, privateFile := range privateFiles {
+			privateFile := privateFile
+			rows = append(rows, &etlRow{
+				OldId: privateFile.Id,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					uploadPrivateFileToS3(pfr.WithTx(tx), s3Client, bucketName, privateFile)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 func uploadPrivateFileToS3(pfr *repositories.PrivateFileRepo, s3 *s3.S3, bucketName string, privateFile *models.PrivateFile) {
@@ -160,7 +157,7 @@ func uploadPrivateFileToS3(pfr *repositories.PrivateFileRepo, s3 *s3.S3, bucketN
 	// generate a new key.
 	doesExist, err := pfr.CheckIfExistsByS3Key(context.Background(), newS3Key)
 	if err != nil {
-		log.Fatal("pfr.CheckIfExistsByS3Key:", err)
+		log.Panic("pfr.CheckIfExistsByS3Key:", err)
 	}
 	if doesExist {
 		log.Println("Duplicate found! Appending UUID to file for private file ID:", privateFile.Id)
@@ -170,7 +167,7 @@ func uploadPrivateFileToS3(pfr *repositories.PrivateFileRepo, s3 *s3.S3, bucketN
 	// Open the file and read the content.
 	f, err := os.Open(privateFile.S3Key)
 	if err != nil {
-		log.Fatal("os.Open:", err)
+		log.Panic("os.Open:", err)
 	}
 	defer f.Close()
 
@@ -182,7 +179,7 @@ func uploadPrivateFileToS3(pfr *repositories.PrivateFileRepo, s3 *s3.S3, bucketN
 	// Upload the content to S3.
 	err = utils.UploadBinToS3(s3, bucketName, newS3Key, contents, "private")
 	if err != nil {
-		log.Fatal("UploadBinToS3", err)
+		log.Panic("UploadBinToS3", err)
 	}
 
 	// Update the private file in the database.
@@ -190,10 +187,10 @@ func uploadPrivateFileToS3(pfr *repositories.PrivateFileRepo, s3 *s3.S3, bucketN
 	privateFile.S3Key = newS3Key
 	err = pfr.UpdateById(context.Background(), privateFile)
 	if err != nil {
-		log.Fatal("pfr.UpdateById:", err)
+		log.Panic("pfr.UpdateById:", err)
 	}
 
 	log.Println("Imported ID#", privateFile.Id)
 
-	// log.Fatal("PROGRAMMER HALT") // For debugging purposes only.
+	// log.Panic("PROGRAMMER HALT") // For debugging purposes only.
 }
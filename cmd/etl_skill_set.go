@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +12,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -26,6 +24,7 @@ func init() {
 	skillSetETLCmd.MarkFlagRequired("schema_name")
 	skillSetETLCmd.Flags().IntVarP(&skillSetETLTenantId, "tenant_id", "t", 0, "Tenant Id that this data belongs to")
 	skillSetETLCmd.MarkFlagRequired("tenant_id")
+	addETLFlags(skillSetETLCmd)
 	rootCmd.AddCommand(skillSetETLCmd)
 }
 
@@ -39,42 +38,32 @@ var skillSetETLCmd = &cobra.Command{
 }
 
 func doRunImportSkillSet() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, skillSetETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	ssr := repositories.NewSkillSetRepo(db)
-
-	runSkillSetETL(ctx, uint64(skillSetETLTenantId), ssr, oldDb)
+	runETL(skillSetETLSchemaName, uint64(skillSetETLTenantId), []*etlStep{skillSetETLStep})
 }
 
-func runSkillSetETL(ctx context.Context, tenantId uint64, ssr *repositories.SkillSetRepo, oldDb *sql.DB) {
-	skillSets, err := ListAllSkillSets(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, oss := range skillSets {
-		insertSkillSetETL(ctx, tenantId, ssr, oss)
-	}
+var skillSetETLStep = &etlStep{
+	Name: "skill_set",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		ssr := repositories.NewSkillSetRepo(e.DB)
+
+		arr, err := ListAllSkillSets(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oss := range arr {
+			oss := oss
+			rows = append(rows, &etlRow{
+				OldId:  oss.Id,
+				Record: oss,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertSkillSetETL(ctx, e.TenantId, ssr.WithTx(tx), oss)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldSkillSet struct {
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +12,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -26,6 +24,7 @@ func init() {
 	ssirETLCmd.MarkFlagRequired("schema_name")
 	ssirETLCmd.Flags().IntVarP(&ssirETLTenantId, "tenant_id", "t", 0, "Tenant Id that this data belongs to")
 	ssirETLCmd.MarkFlagRequired("tenant_id")
+	addETLFlags(ssirETLCmd)
 	rootCmd.AddCommand(ssirETLCmd)
 }
 
@@ -39,42 +38,32 @@ var ssirETLCmd = &cobra.Command{
 }
 
 func doRunImportSkillSetInsuranceRequirement() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, ssirETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	ssir := repositories.NewSkillSetInsuranceRequirementRepo(db)
-
-	runSkillSetInsuranceRequirementETL(ctx, uint64(ssirETLTenantId), ssir, oldDb)
+	runETL(ssirETLSchemaName, uint64(ssirETLTenantId), []*etlStep{skillSetInsuranceRequirementETLStep})
 }
 
-func runSkillSetInsuranceRequirementETL(ctx context.Context, tenantId uint64, ssir *repositories.SkillSetInsuranceRequirementRepo, oldDb *sql.DB) {
-	ssirs, err := ListAllSkillSetInsuranceRequirements(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, oss := range ssirs {
-		insertSkillSetInsuranceRequirementETL(ctx, tenantId, ssir, oss)
-	}
+var skillSetInsuranceRequirementETLStep = &etlStep{
+	Name: "skill_set_insurance_requirement",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		ssir := repositories.NewSkillSetInsuranceRequirementRepo(e.DB)
+
+		arr, err := ListAllSkillSetInsuranceRequirements(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oss := range arr {
+			oss := oss
+			rows = append(rows, &etlRow{
+				OldId:  oss.Id,
+				Record: oss,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertSkillSetInsuranceRequirementETL(ctx, e.TenantId, ssir.WithTx(tx), oss)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldSkillSetInsuranceRequirement struct {
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -16,7 +15,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -26,6 +24,7 @@ var (
 func init() {
 	staffETLCmd.Flags().StringVarP(&staffETLSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	staffETLCmd.MarkFlagRequired("schema_name")
+	addETLFlags(staffETLCmd)
 	rootCmd.AddCommand(staffETLCmd)
 }
 
@@ -39,44 +38,34 @@ var staffETLCmd = &cobra.Command{
 }
 
 func doRunImportStaff() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, staffETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
+	runETL(staffETLSchemaName, 0, []*etlStep{staffETLStep})
+}
 
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	ur := repositories.NewUserRepo(db)
-	sr := repositories.NewStaffRepo(db)
-	r := repositories.NewHowHearAboutUsItemRepo(db)
+var staffETLStep = &etlStep{
+	Name: "staff",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		ur := repositories.NewUserRepo(e.DB)
+		sr := repositories.NewStaffRepo(e.DB)
+		r := repositories.NewHowHearAboutUsItemRepo(e.DB)
 
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, staffETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant == nil {
-		log.Fatal("Tenant does not exist!")
-	}
-
-	runStaffETL(ctx, tenant.Id, ur, sr, r, oldDb)
+		arr, err := ListAllStaffs(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, om := range arr {
+			om := om
+			rows = append(rows, &etlRow{
+				OldId:  om.Id,
+				Record: om,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertStaffETL(ctx, e.TenantId, ur.WithTx(tx), sr.WithTx(tx), r.WithTx(tx), om)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldUStaff struct {
@@ -194,34 +183,17 @@ func ListAllStaffs(db *sql.DB) ([]*OldUStaff, error) {
 			&m.EmergencyContactRelationship, &m.EmergencyContactTelephone, &m.PoliceCheck,
 		)
 		if err != nil {
-			log.Fatal("(AA)", err)
+			log.Panic("(AA)", err)
 		}
 		arr = append(arr, m)
 	}
 	err = rows.Err()
 	if err != nil {
-		log.Fatal("(BB)", err)
+		log.Panic("(BB)", err)
 	}
 	return arr, err
 }
 
-func runStaffETL(
-	ctx context.Context,
-	tenantId uint64,
-	ur *repositories.UserRepo,
-	sr *repositories.StaffRepo,
-	r *repositories.HowHearAboutUsItemRepo,
-	oldDb *sql.DB,
-) {
-	staffs, err := ListAllStaffs(oldDb)
-	if err != nil {
-		log.Fatal("(0000)", err)
-	}
-	for _, om := range staffs {
-		insertStaffETL(ctx, tenantId, ur, sr, r, om)
-	}
-}
-
 func insertStaffETL(
 	ctx context.Context,
 	tid uint64,
@@ -267,10 +239,10 @@ func insertStaffETL(
 		// log.Println(om.Id)
 		user, err := ur.GetByOldId(ctx, userId)
 		if err != nil {
-			log.Fatal("(A)", err)
+			log.Panic("(A)", err)
 		}
 		if user == nil {
-			log.Fatal("(B) User is null")
+			log.Panic("(B) User is null")
 		}
 		userId = user.Id
 
@@ -416,7 +388,7 @@ func insertStaffETL(
 	howHearText := ""
 	howHear, err := r.GetById(ctx, howHearId)
 	if err != nil {
-		log.Fatal(err)
+		log.Panic(err)
 		return
 	}
 
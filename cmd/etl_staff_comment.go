@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +12,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -23,6 +21,7 @@ var (
 func init() {
 	staffCommentETLCmd.Flags().StringVarP(&staffCommentETLSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	staffCommentETLCmd.MarkFlagRequired("schema_name")
+	addETLFlags(staffCommentETLCmd)
 	rootCmd.AddCommand(staffCommentETLCmd)
 }
 
@@ -36,44 +35,44 @@ var staffCommentETLCmd = &cobra.Command{
 }
 
 func doRunImportStaffComment() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, staffCommentETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
+	runETL(staffCommentETLSchemaName, 0, []*etlStep{staffCommentETLStep})
+}
 
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	irr := repositories.NewStaffCommentRepo(db)
-	om := repositories.NewStaffRepo(db)
-	commentr := repositories.NewCommentRepo(db)
+var staffCommentETLStep = &etlStep{
+	Name: "staff_comment",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		irr := repositories.NewStaffCommentRepo(e.DB)
+		om := repositories.NewStaffRepo(e.DB)
+		tr := repositories.NewCommentRepo(e.DB)
 
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, staffCommentETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant == nil {
-		log.Fatal("Tenant does not exist!")
-	}
-
-	runStaffCommentETL(ctx, tenant.Id, irr, om, commentr, oldDb)
+		arr, err := ListAllStaffComments(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oir := range arr {
+			oir := oir
+			rows = append(rows, &etlRow{
+				OldId:  oir.Id,
+				Record: oir,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					staffId, err := om.WithTx(tx).GetIdByOldId(ctx, e.TenantId, oir.StaffId)
+					if err != nil {
+						return err
+					}
+
+					commentId, err := tr.WithTx(tx).GetIdByOldId(ctx, e.TenantId, oir.CommentId)
+					if err != nil {
+						return err
+					}
+
+					insertStaffCommentETL(ctx, e.TenantId, oir.Id, staffId, commentId, irr.WithTx(tx))
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldUStaffComment struct {
@@ -123,34 +122,6 @@ func ListAllStaffComments(db *sql.DB) ([]*OldUStaffComment, error) {
 	return arr, err
 }
 
-func runStaffCommentETL(
-	ctx context.Context,
-	tenantId uint64,
-	irr *repositories.StaffCommentRepo,
-	om *repositories.StaffRepo,
-	tr *repositories.CommentRepo,
-	oldDb *sql.DB,
-) {
-	staffComments, err := ListAllStaffComments(oldDb)
-	if err != nil {
-		log.Fatal("runStaffCommentETL | ListAllStaffComments | err", err)
-	}
-	for _, oir := range staffComments {
-
-		staffId, err := om.GetIdByOldId(ctx, tenantId, oir.StaffId)
-		if err != nil {
-			log.Panic("runStaffCommentETL | om.GetIdByOldId | err", err)
-		}
-
-		commentId, err := tr.GetIdByOldId(ctx, tenantId, oir.CommentId)
-		if err != nil {
-			log.Panic("runStaffCommentETL | tr.GetIdByOldId | err", err)
-		}
-
-		insertStaffCommentETL(ctx, tenantId, oir.Id, staffId, commentId, irr)
-	}
-}
-
 func insertStaffCommentETL(ctx context.Context, tenantId uint64, oldId uint64, staffId uint64, commentId uint64, irr *repositories.StaffCommentRepo) {
 	fmt.Println("Pre-Imported Staff Comment ID#", oldId)
 	m := &models.StaffComment{
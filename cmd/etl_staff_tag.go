@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +12,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -23,6 +21,7 @@ var (
 func init() {
 	staffTagETLCmd.Flags().StringVarP(&staffTagETLSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	staffTagETLCmd.MarkFlagRequired("schema_name")
+	addETLFlags(staffTagETLCmd)
 	rootCmd.AddCommand(staffTagETLCmd)
 }
 
@@ -36,44 +35,44 @@ var staffTagETLCmd = &cobra.Command{
 }
 
 func doRunImportStaffTag() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, staffTagETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
+	runETL(staffTagETLSchemaName, 0, []*etlStep{staffTagETLStep})
+}
 
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	irr := repositories.NewStaffTagRepo(db)
-	om := repositories.NewStaffRepo(db)
-	tagr := repositories.NewTagRepo(db)
+var staffTagETLStep = &etlStep{
+	Name: "staff_tag",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		irr := repositories.NewStaffTagRepo(e.DB)
+		om := repositories.NewStaffRepo(e.DB)
+		tr := repositories.NewTagRepo(e.DB)
 
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, staffTagETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant == nil {
-		log.Fatal("Tenant does not exist!")
-	}
-
-	runStaffTagETL(ctx, tenant.Id, irr, om, tagr, oldDb)
+		arr, err := ListAllStaffTags(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oir := range arr {
+			oir := oir
+			rows = append(rows, &etlRow{
+				OldId:  oir.Id,
+				Record: oir,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					staffId, err := om.WithTx(tx).GetIdByOldId(ctx, e.TenantId, oir.StaffId)
+					if err != nil {
+						return err
+					}
+
+					tagId, err := tr.WithTx(tx).GetIdByOldId(ctx, e.TenantId, oir.TagId)
+					if err != nil {
+						return err
+					}
+
+					insertStaffTagETL(ctx, e.TenantId, oir.Id, staffId, tagId, irr.WithTx(tx))
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldUStaffTag struct {
@@ -121,27 +120,6 @@ func ListAllStaffTags(db *sql.DB) ([]*OldUStaffTag, error) {
 	return arr, err
 }
 
-func runStaffTagETL(ctx context.Context, tenantId uint64, irr *repositories.StaffTagRepo, om *repositories.StaffRepo, tr *repositories.TagRepo, oldDb *sql.DB) {
-	staffTags, err := ListAllStaffTags(oldDb)
-	if err != nil {
-		log.Fatal("runStaffTagETL | ListAllStaffTags | err", err)
-	}
-	for _, oir := range staffTags {
-
-		staffId, err := om.GetIdByOldId(ctx, tenantId, oir.StaffId)
-		if err != nil {
-			log.Panic("runStaffTagETL | om.GetIdByOldId | err", err)
-		}
-
-		tagId, err := tr.GetIdByOldId(ctx, tenantId, oir.TagId)
-		if err != nil {
-			log.Panic("runStaffTagETL | tr.GetIdByOldId | err", err)
-		}
-
-		insertStaffTagETL(ctx, tenantId, oir.Id, staffId, tagId, irr)
-	}
-}
-
 func insertStaffTagETL(ctx context.Context, tenantId uint64, oldId uint64, staffId uint64, tagId uint64, irr *repositories.StaffTagRepo) {
 	fmt.Println("Pre-Imported Staff Tag ID#", oldId)
 	m := &models.StaffTag{
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +12,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -23,6 +21,7 @@ var (
 func init() {
 	tagETLCmd.Flags().StringVarP(&tagETLSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	tagETLCmd.MarkFlagRequired("schema_name")
+	addETLFlags(tagETLCmd)
 	rootCmd.AddCommand(tagETLCmd)
 }
 
@@ -36,42 +35,32 @@ var tagETLCmd = &cobra.Command{
 }
 
 func doRunImportTag() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, tagETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	irr := repositories.NewTagRepo(db)
+	runETL(tagETLSchemaName, 0, []*etlStep{tagETLStep})
+}
 
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, tagETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant == nil {
-		log.Fatal("Tenant does not exist!")
-	}
+var tagETLStep = &etlStep{
+	Name: "tag",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		irr := repositories.NewTagRepo(e.DB)
 
-	runTagETL(ctx, tenant.Id, irr, oldDb)
+		arr, err := ListAllTags(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oir := range arr {
+			oir := oir
+			rows = append(rows, &etlRow{
+				OldId:  oir.Id,
+				Record: oir,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertTagETL(ctx, e.TenantId, irr.WithTx(tx), oir)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldUTag struct {
@@ -121,16 +110,6 @@ func ListAllTags(db *sql.DB) ([]*OldUTag, error) {
 	return arr, err
 }
 
-func runTagETL(ctx context.Context, tenantId uint64, irr *repositories.TagRepo, oldDb *sql.DB) {
-	tags, err := ListAllTags(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, oir := range tags {
-		insertTagETL(ctx, tenantId, irr, oir)
-	}
-}
-
 func insertTagETL(ctx context.Context, tid uint64, irr *repositories.TagRepo, oir *OldUTag) {
 	var state int8 = 1
 	if oir.IsArchived == true {
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,7 +13,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -24,6 +22,7 @@ var (
 func init() {
 	taskItemETLCmd.Flags().StringVarP(&taskItemETLSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	taskItemETLCmd.MarkFlagRequired("schema_name")
+	addETLFlags(taskItemETLCmd)
 	rootCmd.AddCommand(taskItemETLCmd)
 }
 
@@ -37,57 +36,47 @@ var taskItemETLCmd = &cobra.Command{
 }
 
 func doRunImportTaskItem() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, taskItemETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	tir := repositories.NewTaskItemRepo(db)
-	wor := repositories.NewWorkOrderRepo(db)
-	owor := repositories.NewOngoingWorkOrderRepo(db)
-	ur := repositories.NewUserRepo(db)
-	ar := repositories.NewAssociateRepo(db)
-	cr := repositories.NewCustomerRepo(db)
-
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, taskItemETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant == nil {
-		log.Fatal("Tenant does not exist!")
-	}
+	runETL(taskItemETLSchemaName, 0, []*etlStep{taskItemETLStep})
+}
 
-	runTaskItemETL(ctx, tenant.Id, tir, wor, owor, ur, ar, cr, oldDb)
+var taskItemETLStep = &etlStep{
+	Name: "task_item",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		tir := repositories.NewTaskItemRepo(e.DB)
+		wor := repositories.NewWorkOrderRepo(e.DB)
+		owor := repositories.NewOngoingWorkOrderRepo(e.DB)
+		ur := repositories.NewUserRepo(e.DB)
+		ar := repositories.NewAssociateRepo(e.DB)
+		cr := repositories.NewCustomerRepo(e.DB)
+
+		arr, err := ListAllTaskItems(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oti := range arr {
+			oti := oti
+			rows = append(rows, &etlRow{
+				OldId:  oti.Id,
+				Record: oti,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertTaskItemETL(ctx, e.TenantId, tir.WithTx(tx), wor.WithTx(tx), owor.WithTx(tx), ur.WithTx(tx), ar.WithTx(tx), cr.WithTx(tx), oti)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldUTaskItem struct {
 	Id                       uint64      `json:"id"`
-	TypeOf                   string      `json:"type_of"`
+	TypeOf                   int8        `json:"type_of"`
 	Title                    string      `json:"title"`
 	Description              string      `json:"description"`
 	DueDate                  time.Time   `json:"due_date"`
 	IsClosed                 bool        `json:"is_closed"`
-	WasPostponed             string      `json:"was_postponed"`
+	WasPostponed             bool        `json:"was_postponed"`
 	ClosingReason            int8        `json:"closing_reason"`
 	ClosingReasonOther       string      `json:"closing_reason_other"`
 	CreatedAt                time.Time   `json:"created_at"`
@@ -166,26 +155,6 @@ func ListAllTaskItems(db *sql.DB) ([]*OldUTaskItem, error) {
 	return arr, err
 }
 
-func runTaskItemETL(
-	ctx context.Context,
-	tenantId uint64,
-	tir *repositories.TaskItemRepo,
-	wor *repositories.WorkOrderRepo,
-	owor *repositories.OngoingWorkOrderRepo,
-	ur *repositories.UserRepo,
-	ar *repositories.AssociateRepo,
-	cr *repositories.CustomerRepo,
-	oldDb *sql.DB,
-) {
-	oldTaskItems, err := ListAllTaskItems(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, oti := range oldTaskItems {
-		insertTaskItemETL(ctx, tenantId, tir, wor, owor, ur, ar, cr, oti)
-	}
-}
-
 func insertTaskItemETL(
 	ctx context.Context,
 	tid uint64,
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +16,7 @@ import (
 )
 
 func init() {
+	addETLFlags(tenantETLCmd)
 	rootCmd.AddCommand(tenantETLCmd)
 }
 
@@ -30,30 +30,33 @@ var tenantETLCmd = &cobra.Command{
 }
 
 func doRunImportTenant() {
-	// Load up our new database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our repositories.
-	r := repositories.NewTenantRepo(db)
+	runETL("public", 0, []*etlStep{tenantETLStep})
+}
 
-	// Load up our old database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
+var tenantETLStep = &etlStep{
+	Name:     "tenant",
+	IsGlobal: true,
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		r := repositories.NewTenantRepo(e.DB)
 
-	// Begin the operation.
-	runTenantETL(r, oldDb)
+		arr, err := ListAllTenants(e.OldPublicDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, v := range arr {
+			v := v
+			rows = append(rows, &etlRow{
+				OldId:  v.Id,
+				Record: v,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					runTenantInsert(v, r.WithTx(tx))
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldTenant struct {
@@ -164,17 +167,12 @@ func ListAllTenants(db *sql.DB) ([]*OldTenant, error) {
 	return arr, err
 }
 
-func runTenantETL(r *repositories.TenantRepo, oldDb *sql.DB) {
-	tenants, err := ListAllTenants(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, v := range tenants {
-		runTenantInsert(v, r)
+func runTenantInsert(ot *OldTenant, r *repositories.TenantRepo) {
+	timezone := ot.TimezoneName
+	if timezone == "" {
+		timezone = utils.DefaultTimezone
 	}
-}
 
-func runTenantInsert(ot *OldTenant, r *repositories.TenantRepo) {
 	m := &models.Tenant{
 		OldId:              ot.Id,
 		Uuid:               uuid.NewString(),
@@ -183,7 +181,7 @@ func runTenantInsert(ot *OldTenant, r *repositories.TenantRepo) {
 		Name:               ot.Name,
 		Url:                ot.Url.String,
 		State:              1,
-		Timezone:           "America/Toronto",
+		Timezone:           timezone,
 		CreatedTime:        ot.Created,
 		ModifiedTime:       ot.LastModified,
 		AddressCountry:     ot.AddressCountry,
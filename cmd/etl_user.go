@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"strings"
 	"time"
 
@@ -14,10 +13,10 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 func init() {
+	addETLFlags(userETLCmd)
 	rootCmd.AddCommand(userETLCmd)
 }
 
@@ -31,31 +30,34 @@ var userETLCmd = &cobra.Command{
 }
 
 func doRunImportUser() {
-	// Load up our new database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	ur := repositories.NewUserRepo(db)
-
-	// Load up our old database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
+	runETL("public", 0, []*etlStep{userETLStep})
+}
+
+var userETLStep = &etlStep{
+	Name:     "user",
+	IsGlobal: true,
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		tr := repositories.NewTenantRepo(e.DB)
+		ur := repositories.NewUserRepo(e.DB)
 
-	// Begin the operation.
-	runUserETL(tr, ur, oldDb)
+		arr, err := ListAllUsers(e.OldPublicDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, v := range arr {
+			v := v
+			rows = append(rows, &etlRow{
+				OldId:  v.Id,
+				Record: v,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					runUserInsert(v, tr.WithTx(tx), ur.WithTx(tx))
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldUser struct {
@@ -124,16 +126,6 @@ func ListAllUsers(db *sql.DB) ([]*OldUser, error) {
 	return arr, err
 }
 
-func runUserETL(tr *repositories.TenantRepo, ur *repositories.UserRepo, oldDb *sql.DB) {
-	users, err := ListAllUsers(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, v := range users {
-		runUserInsert(v, tr, ur)
-	}
-}
-
 func runUserInsert(ou *OldUser, tr *repositories.TenantRepo, ur *repositories.UserRepo) {
 	var state int8 = 0
 	if ou.IsActive == true {
@@ -150,7 +142,7 @@ func runUserInsert(ou *OldUser, tr *repositories.TenantRepo, ur *repositories.Us
 	ctx := context.Background()
 	tenant, err := tr.GetByOldId(ctx, uint64(tenantId.Int64))
 	if err != nil {
-		log.Fatal(err)
+		log.Panic(err)
 	}
 
 	lexicalName := ou.LastName + ", " + ou.FirstName
@@ -4,8 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -13,10 +11,10 @@ import (
 
 	// "github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 func init() {
+	addETLFlags(userGroupETLCmd)
 	rootCmd.AddCommand(userGroupETLCmd)
 }
 
@@ -30,30 +28,33 @@ var userGroupETLCmd = &cobra.Command{
 }
 
 func doRunImportUserGroup() {
-	// Load up our new database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our repositories.
-	r := repositories.NewUserRepo(db)
+	runETL("public", 0, []*etlStep{userGroupETLStep})
+}
 
-	// Load up our old database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
+var userGroupETLStep = &etlStep{
+	Name:     "user_group",
+	IsGlobal: true,
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		r := repositories.NewUserRepo(e.DB)
 
-	// Begin the operation.
-	runUserGroupETL(r, oldDb)
+		arr, err := ListAllUserGroups(e.OldPublicDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, v := range arr {
+			v := v
+			rows = append(rows, &etlRow{
+				OldId:  v.Id,
+				Record: v,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					runUserGroupInsert(v, r.WithTx(tx))
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldUserGroup struct {
@@ -102,16 +103,6 @@ func ListAllUserGroups(db *sql.DB) ([]*OldUserGroup, error) {
 	return arr, err
 }
 
-func runUserGroupETL(r *repositories.UserRepo, oldDb *sql.DB) {
-	userGroups, err := ListAllUserGroups(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, v := range userGroups {
-		runUserGroupInsert(v, r)
-	}
-}
-
 func runUserGroupInsert(ot *OldUserGroup, r *repositories.UserRepo) {
 	ctx := context.Background()
 	user, err := r.GetByOldId(ctx, ot.UserId)
@@ -126,6 +117,6 @@ func runUserGroupInsert(ot *OldUserGroup, r *repositories.UserRepo) {
 		}
 		fmt.Println("Processed UserId #", user.Id)
 	} else {
-		fmt.Println("Skipped UserId #", user.Id)
+		fmt.Println("Skipped UserId #", ot.UserId)
 	}
 }
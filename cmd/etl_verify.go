@@ -0,0 +1,405 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/over55/workery-server/internal/repositories"
+)
+
+const (
+	etlVerifyPassStatus = "pass"
+	etlVerifyFailStatus = "fail"
+
+	// The largest difference between two amounts which we consider equal,
+	// the old database stores amounts as decimals and we store floats.
+	etlVerifyAmountTolerance = 0.005
+)
+
+var (
+	etlVerifySchemaName string
+	etlVerifySampleSize int
+	etlVerifyReportPath string
+)
+
+func init() {
+	etlVerifyCmd.Flags().StringVarP(&etlVerifySchemaName, "schema_name", "s", "", "The schema name in the postgres.")
+	etlVerifyCmd.MarkFlagRequired("schema_name")
+	etlVerifyCmd.Flags().IntVar(&etlVerifySampleSize, "sample-size", 10, "The number of random rows per table to compare field by field.")
+	etlVerifyCmd.Flags().StringVar(&etlVerifyReportPath, "report", "", "The file path to save the JSON report of the verification.")
+	rootCmd.AddCommand(etlVerifyCmd)
+}
+
+var etlVerifyCmd = &cobra.Command{
+	Use:   "etl_verify",
+	Short: "Verify the imported tenant data against the old workery",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		doRunETLVerify()
+	},
+}
+
+// etlVerifyTable describes a table which we compare by row count and by
+// the sum of its amount columns.
+type etlVerifyTable struct {
+	Name     string
+	OldTable string
+	NewTable string
+	IsPublic bool // Set to true if the old table is in the `public` schema.
+	Sums     []*etlVerifySum
+}
+
+type etlVerifySum struct {
+	OldColumn string
+	NewColumn string
+}
+
+// etlVerifySpotCheck describes how to compare random rows field by field.
+// The `OldQuery` must select the old id followed by the fields and take the
+// sample size as `$1`. The `NewQuery` must select the same fields in the
+// same order and take the tenant id as `$1` and the old id as `$2`.
+type etlVerifySpotCheck struct {
+	Name     string
+	OldQuery string
+	NewQuery string
+	Fields   []string
+}
+
+var etlVerifyTables = []*etlVerifyTable{
+	{Name: "how_hear_about_us_item", OldTable: "workery_how_hear_about_us_items", NewTable: "how_hear_about_us_items", IsPublic: true},
+	{Name: "skill_set", OldTable: "workery_skill_sets", NewTable: "skill_sets"},
+	{Name: "insurance_requirement", OldTable: "workery_insurance_requirements", NewTable: "insurance_requirements"},
+	{Name: "skill_set_insurance_requirement", OldTable: "workery_skill_sets_insurance_requirements", NewTable: "skill_set_insurance_requirements"},
+	{Name: "vehicle_type", OldTable: "workery_vehicle_types", NewTable: "vehicle_types"},
+	{Name: "tag", OldTable: "workery_tags", NewTable: "tags"},
+	{Name: "comment", OldTable: "workery_comments", NewTable: "comments"},
+	{Name: "work_order_service_fee", OldTable: "workery_work_order_service_fees", NewTable: "work_order_service_fees"},
+	{Name: "customer", OldTable: "workery_customers", NewTable: "customers"},
+	{Name: "customer_tag", OldTable: "workery_customers_tags", NewTable: "customer_tags"},
+	{Name: "customer_comment", OldTable: "workery_customer_comments", NewTable: "customer_comments"},
+	{Name: "associate", OldTable: "workery_associates", NewTable: "associates"},
+	{Name: "associate_skill_set", OldTable: "workery_associates_skill_sets", NewTable: "associate_skill_sets"},
+	{Name: "associate_vehicle_type", OldTable: "workery_associates_vehicle_types", NewTable: "associate_vehicle_types"},
+	{Name: "associate_insurance_requirement", OldTable: "workery_associates_insurance_requirements", NewTable: "associate_insurance_requirements"},
+	{Name: "associate_comment", OldTable: "workery_associate_comments", NewTable: "associate_comments"},
+	{Name: "associate_away_log", OldTable: "workery_away_logs", NewTable: "associate_away_logs"},
+	{Name: "partner", OldTable: "workery_partners", NewTable: "partners"},
+	{Name: "partner_comment", OldTable: "workery_partner_comments", NewTable: "partner_comments"},
+	{Name: "staff", OldTable: "workery_staff", NewTable: "staff"},
+	{Name: "staff_tag", OldTable: "workery_staff_tags", NewTable: "staff_tags"},
+	{Name: "staff_comment", OldTable: "workery_staff_comments", NewTable: "staff_comments"},
+	{Name: "bulletin_board_item", OldTable: "workery_bulletin_board_items", NewTable: "bulletin_board_items"},
+	{Name: "ongoing_work_order", OldTable: "workery_ongoing_work_orders", NewTable: "ongoing_work_orders"},
+	{Name: "work_order", OldTable: "workery_work_orders", NewTable: "work_orders", Sums: []*etlVerifySum{
+		{OldColumn: "invoice_total_amount", NewColumn: "invoice_total_amount"},
+		{OldColumn: "invoice_service_fee_amount", NewColumn: "invoice_service_fee_amount"},
+		{OldColumn: "invoice_balance_owing_amount", NewColumn: "invoice_balance_owing_amount"},
+	}},
+	{Name: "work_order_skill_set", OldTable: "workery_work_orders_skill_sets", NewTable: "work_order_skill_sets"},
+	{Name: "work_order_tag", OldTable: "workery_work_orders_tags", NewTable: "work_order_tags"},
+	{Name: "work_order_comment", OldTable: "workery_work_order_comments", NewTable: "work_order_comments"},
+	{Name: "work_order_invoice", OldTable: "workery_work_order_invoices", NewTable: "work_order_invoices", Sums: []*etlVerifySum{
+		{OldColumn: "total", NewColumn: "total"},
+		{OldColumn: "amount_due", NewColumn: "amount_due"},
+	}},
+	{Name: "work_order_deposit", OldTable: "workery_work_order_deposits", NewTable: "work_order_deposits", Sums: []*etlVerifySum{
+		{OldColumn: "amount", NewColumn: "amount"},
+	}},
+	{Name: "task_item", OldTable: "workery_task_items", NewTable: "task_items"},
+	{Name: "activity_sheet_item", OldTable: "workery_activity_sheet_items", NewTable: "activity_sheet_items"},
+	{Name: "private_file", OldTable: "workery_private_file_uploads", NewTable: "private_files"},
+}
+
+var etlVerifySpotChecks = []*etlVerifySpotCheck{
+	{
+		Name:     "customer",
+		OldQuery: "SELECT id, given_name, last_name, email, telephone, postal_code FROM workery_customers ORDER BY random() LIMIT $1",
+		NewQuery: "SELECT given_name, last_name, email, telephone, postal_code FROM customers WHERE tenant_id = $1 AND old_id = $2",
+		Fields:   []string{"given_name", "last_name", "email", "telephone", "postal_code"},
+	},
+	{
+		Name:     "associate",
+		OldQuery: "SELECT id, given_name, last_name, email, telephone, postal_code FROM workery_associates ORDER BY random() LIMIT $1",
+		NewQuery: "SELECT given_name, last_name, email, telephone, postal_code FROM associates WHERE tenant_id = $1 AND old_id = $2",
+		Fields:   []string{"given_name", "last_name", "email", "telephone", "postal_code"},
+	},
+	{
+		Name:     "staff",
+		OldQuery: "SELECT id, given_name, last_name, email, telephone FROM workery_staff ORDER BY random() LIMIT $1",
+		NewQuery: "SELECT given_name, last_name, email, telephone FROM staff WHERE tenant_id = $1 AND old_id = $2",
+		Fields:   []string{"given_name", "last_name", "email", "telephone"},
+	},
+	{
+		Name:     "work_order",
+		OldQuery: "SELECT id, description, hours, invoice_total_amount, invoice_service_fee_amount, invoice_balance_owing_amount FROM workery_work_orders ORDER BY random() LIMIT $1",
+		NewQuery: "SELECT description, hours, invoice_total_amount, invoice_service_fee_amount, invoice_balance_owing_amount FROM work_orders WHERE tenant_id = $1 AND old_id = $2",
+		Fields:   []string{"description", "hours", "invoice_total_amount", "invoice_service_fee_amount", "invoice_balance_owing_amount"},
+	},
+	{
+		// DEVELOPERS NOTE:
+		// The old invoices are keyed by the work order so we match them
+		// through the work order which was imported from the same old id.
+		Name:     "work_order_invoice",
+		OldQuery: "SELECT order_id, invoice_id, total, amount_due, payment_amount FROM workery_work_order_invoices ORDER BY random() LIMIT $1",
+		NewQuery: "SELECT i.invoice_id, i.total, i.amount_due, i.payment_amount FROM work_order_invoices AS i INNER JOIN work_orders AS w ON w.id = i.order_id WHERE i.tenant_id = $1 AND w.old_id = $2",
+		Fields:   []string{"invoice_id", "total", "amount_due", "payment_amount"},
+	},
+	{
+		Name:     "work_order_deposit",
+		OldQuery: "SELECT id, amount, deposit_method, paid_to, paid_for FROM workery_work_order_deposits ORDER BY random() LIMIT $1",
+		NewQuery: "SELECT amount, deposit_method, paid_to, paid_for FROM work_order_deposits WHERE tenant_id = $1 AND old_id = $2",
+		Fields:   []string{"amount", "deposit_method", "paid_to", "paid_for"},
+	},
+}
+
+type etlVerifyCheckReport struct {
+	Kind     string `json:"kind"` // Either `count`, `sum` or `row`.
+	Table    string `json:"table"`
+	Column   string `json:"column,omitempty"`
+	OldId    uint64 `json:"old_id,omitempty"`
+	OldValue string `json:"old_value"`
+	NewValue string `json:"new_value"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+type etlVerifyReport struct {
+	SchemaName   string                  `json:"schema_name"`
+	TenantId     uint64                  `json:"tenant_id"`
+	Status       string                  `json:"status"`
+	PassedChecks int                     `json:"passed_checks"`
+	FailedChecks int                     `json:"failed_checks"`
+	StartedTime  time.Time               `json:"started_time"`
+	FinishedTime time.Time               `json:"finished_time"`
+	Checks       []*etlVerifyCheckReport `json:"checks"`
+}
+
+func (r *etlVerifyReport) add(c *etlVerifyCheckReport) {
+	if c.Error != "" {
+		c.Status = etlVerifyFailStatus
+	}
+	if c.Status == etlVerifyPassStatus {
+		r.PassedChecks++
+	} else {
+		r.FailedChecks++
+	}
+	r.Checks = append(r.Checks, c)
+}
+
+func doRunETLVerify() {
+	ctx := context.Background()
+
+	env := connectETLEnv(etlVerifySchemaName)
+	defer env.Close()
+
+	// Lookup the tenant.
+	tr := repositories.NewTenantRepo(env.DB)
+	tenant, err := tr.GetBySchemaName(ctx, etlVerifySchemaName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if tenant == nil {
+		log.Fatal("Tenant does not exist!")
+	}
+	env.TenantId = tenant.Id
+
+	report := &etlVerifyReport{
+		SchemaName:  env.SchemaName,
+		TenantId:    env.TenantId,
+		StartedTime: time.Now(),
+	}
+	for _, t := range etlVerifyTables {
+		verifyETLTable(ctx, env, t, report)
+	}
+	for _, sc := range etlVerifySpotChecks {
+		verifyETLSpotCheck(ctx, env, sc, report)
+	}
+	report.FinishedTime = time.Now()
+	report.Status = etlVerifyPassStatus
+	if report.FailedChecks > 0 {
+		report.Status = etlVerifyFailStatus
+	}
+
+	saveETLVerifyReport(report)
+	if report.Status != etlVerifyPassStatus {
+		log.Fatal("ETL verification failed with ", report.FailedChecks, " failed checks")
+	}
+}
+
+// Function compares the row count and the amount sums of the table.
+func verifyETLTable(ctx context.Context, env *etlEnv, t *etlVerifyTable, report *etlVerifyReport) {
+	oldDb := env.OldDB
+	if t.IsPublic {
+		oldDb = env.OldPublicDB
+	}
+
+	c := &etlVerifyCheckReport{Kind: "count", Table: t.Name}
+	var oldCount, newCount int64
+	err := queryETLVerifyValue(ctx, oldDb, "SELECT COUNT(*) FROM "+t.OldTable, &oldCount)
+	if err == nil {
+		err = queryETLVerifyValue(ctx, env.DB, "SELECT COUNT(*) FROM "+t.NewTable+" WHERE tenant_id = $1", &newCount, env.TenantId)
+	}
+	c.OldValue = strconv.FormatInt(oldCount, 10)
+	c.NewValue = strconv.FormatInt(newCount, 10)
+	c.Status = etlVerifyFailStatus
+	if err != nil {
+		c.Error = err.Error()
+	} else if oldCount == newCount {
+		c.Status = etlVerifyPassStatus
+	}
+	report.add(c)
+
+	for _, s := range t.Sums {
+		c := &etlVerifyCheckReport{Kind: "sum", Table: t.Name, Column: s.NewColumn}
+		var oldSum, newSum sql.NullFloat64
+		err := queryETLVerifyValue(ctx, oldDb, "SELECT SUM("+s.OldColumn+") FROM "+t.OldTable, &oldSum)
+		if err == nil {
+			err = queryETLVerifyValue(ctx, env.DB, "SELECT SUM("+s.NewColumn+") FROM "+t.NewTable+" WHERE tenant_id = $1", &newSum, env.TenantId)
+		}
+		c.OldValue = strconv.FormatFloat(oldSum.Float64, 'f', 2, 64)
+		c.NewValue = strconv.FormatFloat(newSum.Float64, 'f', 2, 64)
+		c.Status = etlVerifyFailStatus
+		if err != nil {
+			c.Error = err.Error()
+		} else if math.Abs(oldSum.Float64-newSum.Float64) < etlVerifyAmountTolerance {
+			c.Status = etlVerifyPassStatus
+		}
+		report.add(c)
+	}
+}
+
+func queryETLVerifyValue(ctx context.Context, db *sql.DB, query string, dest interface{}, args ...interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return db.QueryRowContext(ctx, query, args...).Scan(dest)
+}
+
+// Function picks random rows from the old database and compares every field
+// with the row imported from the same old id.
+func verifyETLSpotCheck(ctx context.Context, env *etlEnv, sc *etlVerifySpotCheck, report *etlVerifyReport) {
+	oldRows, err := queryETLVerifyRows(ctx, env.OldDB, len(sc.Fields)+1, sc.OldQuery, etlVerifySampleSize)
+	if err != nil {
+		report.add(&etlVerifyCheckReport{Kind: "row", Table: sc.Name, Error: err.Error()})
+		return
+	}
+
+	for _, oldRow := range oldRows {
+		oldId, err := strconv.ParseUint(oldRow[0].String, 10, 64)
+		if err != nil {
+			report.add(&etlVerifyCheckReport{Kind: "row", Table: sc.Name, Error: err.Error()})
+			continue
+		}
+
+		newRows, err := queryETLVerifyRows(ctx, env.DB, len(sc.Fields), sc.NewQuery, env.TenantId, oldId)
+		if err != nil {
+			report.add(&etlVerifyCheckReport{Kind: "row", Table: sc.Name, OldId: oldId, Error: err.Error()})
+			continue
+		}
+		if len(newRows) == 0 {
+			report.add(&etlVerifyCheckReport{Kind: "row", Table: sc.Name, OldId: oldId, Error: "row was not imported"})
+			continue
+		}
+
+		for i, field := range sc.Fields {
+			c := &etlVerifyCheckReport{
+				Kind:     "row",
+				Table:    sc.Name,
+				Column:   field,
+				OldId:    oldId,
+				OldValue: oldRow[i+1].String,
+				NewValue: newRows[0][i].String,
+				Status:   etlVerifyFailStatus,
+			}
+			if isETLVerifyValueEqual(c.OldValue, c.NewValue) {
+				c.Status = etlVerifyPassStatus
+			}
+			report.add(c)
+		}
+	}
+}
+
+// Function returns all the rows of the query with every column converted to
+// text. A `NULL` column is returned as an empty string.
+func queryETLVerifyRows(ctx context.Context, db *sql.DB, columns int, query string, args ...interface{}) ([][]sql.NullString, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr [][]sql.NullString
+	for rows.Next() {
+		row := make([]sql.NullString, columns)
+		dest := make([]interface{}, columns)
+		for i := range row {
+			dest[i] = &row[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		arr = append(arr, row)
+	}
+	return arr, rows.Err()
+}
+
+// Function compares the values as numbers if both are numbers, otherwise as
+// text.
+func isETLVerifyValueEqual(oldValue string, newValue string) bool {
+	if oldValue == newValue {
+		return true
+	}
+	oldFloat, oldErr := strconv.ParseFloat(oldValue, 64)
+	newFloat, newErr := strconv.ParseFloat(newValue, 64)
+	if oldErr == nil && newErr == nil {
+		return math.Abs(oldFloat-newFloat) < etlVerifyAmountTolerance
+	}
+	return false
+}
+
+// Function prints the human readable report and, if requested, saves the
+// full report as JSON.
+func saveETLVerifyReport(report *etlVerifyReport) {
+	fmt.Println("ETL verification for", report.SchemaName, "| Tenant ID:", report.TenantId, "| Status:", report.Status)
+	fmt.Println("Passed checks:", report.PassedChecks, "| Failed checks:", report.FailedChecks)
+	for _, c := range report.Checks {
+		if c.Kind == "row" && c.Status == etlVerifyPassStatus {
+			continue // Only print the failed rows to keep the output short.
+		}
+		name := c.Table
+		if c.Column != "" {
+			name = name + "." + c.Column
+		}
+		if c.OldId != 0 {
+			name = fmt.Sprintf("%s (old id %d)", name, c.OldId)
+		}
+		fmt.Printf("%-4s %-6s %-60s old=%s new=%s\n", c.Status, c.Kind, name, c.OldValue, c.NewValue)
+		if c.Error != "" {
+			fmt.Println("    error:", c.Error)
+		}
+	}
+
+	if etlVerifyReportPath == "" {
+		return
+	}
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Println("saveETLVerifyReport | json.MarshalIndent | err:", err)
+		return
+	}
+	if err := ioutil.WriteFile(etlVerifyReportPath, b, 0644); err != nil {
+		log.Println("saveETLVerifyReport | ioutil.WriteFile | err:", err)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"strings"
 	"time"
 
@@ -15,7 +14,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -28,6 +26,7 @@ func init() {
 	woETLCmd.MarkFlagRequired("schema_name")
 	woETLCmd.Flags().IntVarP(&woETLTenantId, "tenant_id", "t", 0, "Tenant Id that this data belongs to")
 	woETLCmd.MarkFlagRequired("tenant_id")
+	addETLFlags(woETLCmd)
 	rootCmd.AddCommand(woETLCmd)
 }
 
@@ -41,57 +40,37 @@ var woETLCmd = &cobra.Command{
 }
 
 func doRunImportWorkOrder() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal("utils.ConnectDB", err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, woETLSchemaName)
-	if err != nil {
-		log.Fatal("utils.ConnectDB", err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	asr := repositories.NewWorkOrderRepo(db)
-	ar := repositories.NewAssociateRepo(db)
-	cr := repositories.NewCustomerRepo(db)
-	isfr := repositories.NewWorkOrderServiceFeeRepo(db)
-	owor := repositories.NewOngoingWorkOrderRepo(db)
-	ur := repositories.NewUserRepo(db)
-
-	runWorkOrderETL(ctx, uint64(woETLTenantId), asr, ar, cr, isfr, owor, ur, oldDb)
+	runETL(woETLSchemaName, uint64(woETLTenantId), []*etlStep{workOrderETLStep})
 }
 
-func runWorkOrderETL(
-	ctx context.Context,
-	tenantId uint64,
-	asr *repositories.WorkOrderRepo,
-	ar *repositories.AssociateRepo,
-	cr *repositories.CustomerRepo,
-	isfr *repositories.WorkOrderServiceFeeRepo,
-	owor *repositories.OngoingWorkOrderRepo,
-	ur *repositories.UserRepo,
-	oldDb *sql.DB,
-) {
-	ass, err := ListAllWorkOrders(oldDb)
-	if err != nil {
-		log.Fatal("ListAllWorkOrders", err)
-	}
-	for _, oss := range ass {
-		insertWorkOrderETL(ctx, tenantId, asr, ar, cr, isfr, owor, ur, oss)
-	}
+var workOrderETLStep = &etlStep{
+	Name: "work_order",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		asr := repositories.NewWorkOrderRepo(e.DB)
+		ar := repositories.NewAssociateRepo(e.DB)
+		cr := repositories.NewCustomerRepo(e.DB)
+		isfr := repositories.NewWorkOrderServiceFeeRepo(e.DB)
+		owor := repositories.NewOngoingWorkOrderRepo(e.DB)
+		ur := repositories.NewUserRepo(e.DB)
+
+		arr, err := ListAllWorkOrders(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oss := range arr {
+			oss := oss
+			rows = append(rows, &etlRow{
+				OldId:  oss.Id,
+				Record: oss,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertWorkOrderETL(ctx, e.TenantId, asr.WithTx(tx), ar.WithTx(tx), cr.WithTx(tx), isfr.WithTx(tx), owor.WithTx(tx), ur.WithTx(tx), oss)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldWorkOrder struct {
@@ -220,13 +199,13 @@ func ListAllWorkOrders(db *sql.DB) ([]*OldWorkOrder, error) {
 			&m.InvoiceAmountDueCurrency, &m.InvoiceAmountDue, &m.InvoiceSubTotalAmountCurrency, &m.InvoiceSubTotalAmount, &m.ClosingReasonComment,
 		)
 		if err != nil {
-			log.Fatal("ListAllWorkOrders | rows.Scan", err)
+			log.Panic("ListAllWorkOrders | rows.Scan", err)
 		}
 		arr = append(arr, m)
 	}
 	err = rows.Err()
 	if err != nil {
-		log.Fatal("ListAllWorkOrders | rows.Err", err)
+		log.Panic("ListAllWorkOrders | rows.Err", err)
 	}
 	return arr, err
 }
@@ -475,7 +454,7 @@ func insertWorkOrderETL(
 		ClosingReason:                     oss.ClosingReason,
 		ClosingReasonOther:                oss.ClosingReasonOther,
 		State:                             state,
-		Currency:                          "CAD",
+		Currency:                          models.DefaultCurrency,
 		WasJobSatisfactory:                oss.WasJobSatisfactory,
 		WasJobFinishedOnTimeAndOnBudget:   oss.WasJobFinishedOnTimeAndOnBudget,
 		WasAssociatePunctual:              oss.WasAssociatePunctual,
@@ -483,12 +462,12 @@ func insertWorkOrderETL(
 		WouldCustomerReferOurOrganization: oss.WouldCustomerReferOurOrganization,
 		Score:                             oss.Score,
 		InvoiceDate:                       oss.InvoiceDate,
-		InvoiceQuoteAmount:                oss.InvoiceQuoteAmount,
-		InvoiceLabourAmount:               oss.InvoiceLabourAmount,
-		InvoiceMaterialAmount:             oss.InvoiceMaterialAmount,
-		InvoiceTaxAmount:                  oss.InvoiceTaxAmount,
-		InvoiceTotalAmount:                oss.InvoiceTotalAmount,
-		InvoiceServiceFeeAmount:           oss.InvoiceServiceFeeAmount,
+		InvoiceQuoteAmount:                models.NewMoneyFromFloat(oss.InvoiceQuoteAmount),
+		InvoiceLabourAmount:               models.NewMoneyFromFloat(oss.InvoiceLabourAmount),
+		InvoiceMaterialAmount:             models.NewMoneyFromFloat(oss.InvoiceMaterialAmount),
+		InvoiceTaxAmount:                  models.NewMoneyFromFloat(oss.InvoiceTaxAmount),
+		InvoiceTotalAmount:                models.NewMoneyFromFloat(oss.InvoiceTotalAmount),
+		InvoiceServiceFeeAmount:           models.NewMoneyFromFloat(oss.InvoiceServiceFeeAmount),
 		InvoiceServiceFeePaymentDate:      oss.InvoiceServiceFeePaymentDate,
 		CreatedTime:                       oss.Created,
 		CreatedById:                       createdById,
@@ -503,22 +482,22 @@ func insertWorkOrderETL(
 		OngoingWorkOrderId:                ongoingWorkOrderId,
 		WasSurveyConducted:                oss.WasSurveyConducted,
 		WasThereFinancialsInputted:        oss.WasThereFinancialsInputted,
-		InvoiceActualServiceFeeAmountPaid: oss.InvoiceActualServiceFeeAmountPaid,
-		InvoiceBalanceOwingAmount:         oss.InvoiceBalanceOwingAmount,
-		InvoiceQuotedLabourAmount:         oss.InvoiceQuotedLabourAmount,
-		InvoiceQuotedMaterialAmount:       oss.InvoiceQuotedMaterialAmount,
-		InvoiceTotalQuoteAmount:           oss.InvoiceTotalQuoteAmount,
+		InvoiceActualServiceFeeAmountPaid: models.NewMoneyFromFloat(oss.InvoiceActualServiceFeeAmountPaid),
+		InvoiceBalanceOwingAmount:         models.NewMoneyFromFloat(oss.InvoiceBalanceOwingAmount),
+		InvoiceQuotedLabourAmount:         models.NewMoneyFromFloat(oss.InvoiceQuotedLabourAmount),
+		InvoiceQuotedMaterialAmount:       models.NewMoneyFromFloat(oss.InvoiceQuotedMaterialAmount),
+		InvoiceTotalQuoteAmount:           models.NewMoneyFromFloat(oss.InvoiceTotalQuoteAmount),
 		Visits:                            oss.Visits,
 		InvoiceIds:                        oss.InvoiceIds,
 		NoSurveyConductedReason:           oss.NoSurveyConductedReason,
 		NoSurveyConductedReasonOther:      oss.NoSurveyConductedReasonOther,
 		ClonedFromId:                      clonedFromId,
-		InvoiceDepositAmount:              oss.InvoiceDepositAmount,
-		InvoiceOtherCostsAmount:           oss.InvoiceOtherCostsAmount,
-		InvoiceQuotedOtherCostsAmount:     oss.InvoiceQuotedOtherCostsAmount,
+		InvoiceDepositAmount:              models.NewMoneyFromFloat(oss.InvoiceDepositAmount),
+		InvoiceOtherCostsAmount:           models.NewMoneyFromFloat(oss.InvoiceOtherCostsAmount),
+		InvoiceQuotedOtherCostsAmount:     models.NewMoneyFromFloat(oss.InvoiceQuotedOtherCostsAmount),
 		InvoicePaidTo:                     oss.InvoicePaidTo,
-		InvoiceAmountDue:                  oss.InvoiceAmountDue,
-		InvoiceSubTotalAmount:             oss.InvoiceSubTotalAmount,
+		InvoiceAmountDue:                  models.NewMoneyFromFloat(oss.InvoiceAmountDue),
+		InvoiceSubTotalAmount:             models.NewMoneyFromFloat(oss.InvoiceSubTotalAmount),
 		ClosingReasonComment:              oss.ClosingReasonComment,
 	}
 
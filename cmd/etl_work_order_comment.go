@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +12,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -23,6 +21,7 @@ var (
 func init() {
 	workOrderCommentCmd.Flags().StringVarP(&workOrderCommentSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	workOrderCommentCmd.MarkFlagRequired("schema_name")
+	addETLFlags(workOrderCommentCmd)
 	rootCmd.AddCommand(workOrderCommentCmd)
 }
 
@@ -36,59 +35,34 @@ var workOrderCommentCmd = &cobra.Command{
 }
 
 func doRunImportWorkOrderComment() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, workOrderCommentSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	wotp := repositories.NewWorkOrderCommentRepo(db)
-	ar := repositories.NewWorkOrderRepo(db)
-	vtr := repositories.NewCommentRepo(db)
-
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, workOrderCommentSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant != nil {
-		runWorkOrderCommentETL(ctx, uint64(tenant.Id), wotp, ar, vtr, oldDb)
-	}
+	runETL(workOrderCommentSchemaName, 0, []*etlStep{workOrderCommentETLStep})
 }
 
-func runWorkOrderCommentETL(
-	ctx context.Context,
-	tenantId uint64,
-	wotp *repositories.WorkOrderCommentRepo,
-	ar *repositories.WorkOrderRepo,
-	vtr *repositories.CommentRepo,
-	oldDb *sql.DB,
-) {
-	aats, err := ListAllWorkOrderComments(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, oss := range aats {
-		insertWorkOrderCommentETL(ctx, tenantId, wotp, ar, vtr, oss)
-	}
+var workOrderCommentETLStep = &etlStep{
+	Name: "work_order_comment",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		wotp := repositories.NewWorkOrderCommentRepo(e.DB)
+		ar := repositories.NewWorkOrderRepo(e.DB)
+		vtr := repositories.NewCommentRepo(e.DB)
+
+		arr, err := ListAllWorkOrderComments(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oss := range arr {
+			oss := oss
+			rows = append(rows, &etlRow{
+				OldId:  oss.Id,
+				Record: oss,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertWorkOrderCommentETL(ctx, e.TenantId, wotp.WithTx(tx), ar.WithTx(tx), vtr.WithTx(tx), oss)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldWorkOrderComment struct {
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,7 +13,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -24,6 +22,7 @@ var (
 func init() {
 	workOrderDepositETLCmd.Flags().StringVarP(&workOrderDepositETLSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	workOrderDepositETLCmd.MarkFlagRequired("schema_name")
+	addETLFlags(workOrderDepositETLCmd)
 	rootCmd.AddCommand(workOrderDepositETLCmd)
 }
 
@@ -37,44 +36,34 @@ var workOrderDepositETLCmd = &cobra.Command{
 }
 
 func doRunImportWorkOrderDeposit() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, workOrderDepositETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	or := repositories.NewWorkOrderRepo(db)
-	irr := repositories.NewWorkOrderDepositRepo(db)
-	ur := repositories.NewUserRepo(db)
+	runETL(workOrderDepositETLSchemaName, 0, []*etlStep{workOrderDepositETLStep})
+}
 
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, workOrderDepositETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant == nil {
-		log.Fatal("Tenant does not exist!")
-	}
+var workOrderDepositETLStep = &etlStep{
+	Name: "work_order_deposit",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		or := repositories.NewWorkOrderRepo(e.DB)
+		irr := repositories.NewWorkOrderDepositRepo(e.DB)
+		ur := repositories.NewUserRepo(e.DB)
 
-	runWorkOrderDepositETL(ctx, tenant.Id, or, irr, ur, oldDb)
+		arr, err := ListAllWorkOrderDeposits(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oir := range arr {
+			oir := oir
+			rows = append(rows, &etlRow{
+				OldId:  oir.Id,
+				Record: oir,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertWorkOrderDepositETL(ctx, e.TenantId, or.WithTx(tx), irr.WithTx(tx), ur.WithTx(tx), oir)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldUWorkOrderDeposit struct {
@@ -158,23 +147,6 @@ func ListAllWorkOrderDeposits(db *sql.DB) ([]*OldUWorkOrderDeposit, error) {
 	return arr, err
 }
 
-func runWorkOrderDepositETL(
-	ctx context.Context,
-	tenantId uint64,
-	or *repositories.WorkOrderRepo,
-	irr *repositories.WorkOrderDepositRepo,
-	ur *repositories.UserRepo,
-	oldDb *sql.DB,
-) {
-	workOrderDeposits, err := ListAllWorkOrderDeposits(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, oir := range workOrderDeposits {
-		insertWorkOrderDepositETL(ctx, tenantId, or, irr, ur, oir)
-	}
-}
-
 func insertWorkOrderDepositETL(
 	ctx context.Context,
 	tid uint64,
@@ -266,8 +238,8 @@ func insertWorkOrderDepositETL(
 		PaidAt:             oir.PaidAt,
 		DepositMethod:      oir.DepositMethod,
 		PaidTo:             oir.PaidTo,
-		Currency:           oir.AmountCurrency,
-		Amount:             oir.Amount,
+		Currency:           models.CurrencyOrDefault(oir.AmountCurrency),
+		Amount:             models.NewMoneyFromFloat(oir.Amount),
 		PaidFor:            oir.PaidFor,
 		CreatedTime:        oir.CreatedAt,
 		LastModifiedTime:   oir.LastModifiedAt,
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,7 +13,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -24,6 +22,7 @@ var (
 func init() {
 	workOrderInvoiceCmd.Flags().StringVarP(&workOrderInvoiceSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	workOrderInvoiceCmd.MarkFlagRequired("schema_name")
+	addETLFlags(workOrderInvoiceCmd)
 	rootCmd.AddCommand(workOrderInvoiceCmd)
 }
 
@@ -37,60 +36,35 @@ var workOrderInvoiceCmd = &cobra.Command{
 }
 
 func doRunImportWorkOrderInvoice() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, workOrderInvoiceSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	wotp := repositories.NewWorkOrderInvoiceRepo(db)
-	ar := repositories.NewWorkOrderRepo(db)
-	ur := repositories.NewUserRepo(db)
-
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, workOrderInvoiceSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant != nil {
-		runWorkOrderInvoiceETL(ctx, uint64(tenant.Id), wotp, ar, ur, oldDb)
-	}
+	runETL(workOrderInvoiceSchemaName, 0, []*etlStep{workOrderInvoiceETLStep})
 }
 
-func runWorkOrderInvoiceETL(
-	ctx context.Context,
-	tenantId uint64,
-	wotp *repositories.WorkOrderInvoiceRepo,
-	ar *repositories.WorkOrderRepo,
-	ur *repositories.UserRepo,
-	// vtr *repositories.InvoiceRepo,
-	oldDb *sql.DB,
-) {
-	aats, err := ListAllWorkOrderInvoices(oldDb)
-	if err != nil {
-		log.Fatal("ListAllWorkOrderInvoices|err:", err)
-	}
-	for _, oss := range aats {
-		insertWorkOrderInvoiceETL(ctx, tenantId, wotp, ar, ur, oss)
-	}
+var workOrderInvoiceETLStep = &etlStep{
+	Name: "work_order_invoice",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		wotp := repositories.NewWorkOrderInvoiceRepo(e.DB)
+		ar := repositories.NewWorkOrderRepo(e.DB)
+		ur := repositories.NewUserRepo(e.DB)
+		lir := repositories.NewWorkOrderInvoiceLineItemRepo(e.DB)
+
+		arr, err := ListAllWorkOrderInvoices(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oss := range arr {
+			oss := oss
+			rows = append(rows, &etlRow{
+				OldId:  oss.OrderId,
+				Record: oss,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertWorkOrderInvoiceETL(ctx, e.TenantId, wotp.WithTx(tx), ar.WithTx(tx), ur.WithTx(tx), lir.WithTx(tx), oss)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldWorkOrderInvoice struct {
@@ -213,7 +187,7 @@ type OldWorkOrderInvoice struct {
 	DepositCurrency          string      `json:"deposit_currency"`
 	PaymentAmountCurrency    string      `json:"payment_amount_currency"`
 	PaymentAmount            float64     `json:"payment_amount"`
-	PaymentDate              time.Time   `json:"payment_date"`
+	PaymentDate              null.Time   `json:"payment_date"`
 	IsCash                   bool        `json:"is_cash"`
 	IsCheque                 bool        `json:"is_cheque"`
 	IsDebit                  bool        `json:"is_debit"`
@@ -232,7 +206,7 @@ type OldWorkOrderInvoice struct {
 	LastModifiedFrom         string      `json:"last_modified_from"`
 	LastModifiedFromIsPublic bool        `json:"last_modified_from_is_public"`
 	ClientAddress            string      `json:"client_address"`
-	RevisionVersion          int8        `json:"revision_version"`
+	RevisionVersion          int16       `json:"revision_version"`
 	Deposit                  float64     `json:"deposit"`
 	AmountDue                float64     `json:"amount_due"`
 	SubTotal                 float64     `json:"sub_total"`
@@ -312,7 +286,7 @@ func ListAllWorkOrderInvoices(db *sql.DB) ([]*OldWorkOrderInvoice, error) {
 			&m.Deposit, &m.AmountDue, &m.SubTotal, &m.SubTotalCurrency,
 		)
 		if err != nil {
-			log.Fatalln("rows.Scan|err:", err)
+			log.Panicln("rows.Scan|err:", err)
 		}
 		arr = append(arr, m)
 	}
@@ -329,6 +303,7 @@ func insertWorkOrderInvoiceETL(
 	wotp *repositories.WorkOrderInvoiceRepo,
 	wor *repositories.WorkOrderRepo,
 	ur *repositories.UserRepo,
+	lir *repositories.WorkOrderInvoiceLineItemRepo,
 	oss *OldWorkOrderInvoice,
 ) {
 	//
@@ -367,98 +342,98 @@ func insertWorkOrderInvoiceETL(
 	//
 
 	m := &models.WorkOrderInvoice{
-		Uuid:                     uuid.NewString(),             // 1
-		TenantId:                 tid,                          // 2
-		OldId:                    orderId,                      // 3
-		InvoiceId:                oss.InvoiceId,                // 4
-		OrderId:                  orderId,                      // 5
-		InvoiceDate:              oss.InvoiceDate,              // 6
-		AssociateName:            oss.AssociateName,            // 7
-		AssociateTelephone:       oss.AssociateTelephone,       // 8
-		ClientName:               oss.ClientName,               // 9
-		ClientTelephone:          oss.ClientTelephone,          // 10
-		ClientEmail:              oss.ClientEmail,              // 11
-		Line01Qty:                oss.Line01Qty,                // 12
-		Line01Desc:               oss.Line01Desc,               // 13
-		Line01Price:              oss.Line01Price,              // 14
-		Line01Amount:             oss.Line01Amount,             // 15
-		Line02Qty:                oss.Line02Qty,                // 16
-		Line02Desc:               oss.Line02Desc,               // 17
-		Line02Price:              oss.Line02Price,              // 18
-		Line02Amount:             oss.Line02Amount,             // 19
-		Line03Qty:                oss.Line03Qty,                // 20
-		Line03Desc:               oss.Line03Desc,               // 21
-		Line03Price:              oss.Line03Price,              // 22
-		Line03Amount:             oss.Line03Amount,             // 23
-		Line04Qty:                oss.Line04Qty,                // 24
-		Line04Desc:               oss.Line04Desc,               // 25
-		Line04Price:              oss.Line04Price,              // 26
-		Line04Amount:             oss.Line04Amount,             // 27
-		Line05Qty:                oss.Line05Qty,                // 28
-		Line05Desc:               oss.Line05Desc,               // 29
-		Line05Price:              oss.Line05Price,              // 30
-		Line05Amount:             oss.Line05Amount,             // 31
-		Line06Qty:                oss.Line06Qty,                // 32
-		Line06Desc:               oss.Line06Desc,               // 33
-		Line06Price:              oss.Line06Price,              // 34
-		Line06Amount:             oss.Line06Amount,             // 35
-		Line07Qty:                oss.Line07Qty,                // 36
-		Line07Desc:               oss.Line07Desc,               // 37
-		Line07Price:              oss.Line07Price,              // 38
-		Line07Amount:             oss.Line07Amount,             // 39
-		Line08Qty:                oss.Line08Qty,                // 40
-		Line08Desc:               oss.Line08Desc,               // 41
-		Line08Price:              oss.Line08Price,              // 42
-		Line08Amount:             oss.Line08Amount,             // 43
-		Line09Qty:                oss.Line09Qty,                // 44
-		Line09Desc:               oss.Line09Desc,               // 45
-		Line09Price:              oss.Line09Price,              // 46
-		Line09Amount:             oss.Line09Amount,             // 47
-		Line10Qty:                oss.Line10Qty,                // 48
-		Line10Desc:               oss.Line10Desc,               // 49
-		Line10Price:              oss.Line10Price,              // 50
-		Line10Amount:             oss.Line10Amount,             // 51
-		Line11Qty:                oss.Line11Qty,                // 52
-		Line11Desc:               oss.Line11Desc,               // 53
-		Line11Price:              oss.Line11Price,              // 54
-		Line11Amount:             oss.Line11Amount,             // 55
-		Line12Qty:                oss.Line12Qty,                // 56
-		Line12Desc:               oss.Line12Desc,               // 57
-		Line12Price:              oss.Line12Price,              // 58
-		Line12Amount:             oss.Line12Amount,             // 59
-		Line13Qty:                oss.Line13Qty,                // 60
-		Line13Desc:               oss.Line13Desc,               // 61
-		Line13Price:              oss.Line13Price,              // 62
-		Line13Amount:             oss.Line13Amount,             // 63
-		Line14Qty:                oss.Line14Qty,                // 64
-		Line14Desc:               oss.Line14Desc,               // 65
-		Line14Price:              oss.Line14Price,              // 66
-		Line14Amount:             oss.Line14Amount,             // 67
-		Line15Qty:                oss.Line15Qty,                // 68
-		Line15Desc:               oss.Line15Desc,               // 69
-		Line15Price:              oss.Line15Price,              // 70
-		Line15Amount:             oss.Line15Amount,             // 71
-		InvoiceQuoteDays:         oss.InvoiceQuoteDays,         // 72
-		InvoiceAssociateTax:      oss.InvoiceAssociateTax,      // 73
-		InvoiceQuoteDate:         oss.InvoiceQuoteDate,         // 74
-		InvoiceCustomersApproval: oss.InvoiceCustomersApproval, // 75
-		Line01Notes:              oss.Line01Notes,              // 76
-		Line02Notes:              oss.Line02Notes,              // 77
-		TotalLabour:              oss.TotalLabour,              // 78
-		TotalMaterials:           oss.TotalMaterials,           // 79
-		OtherCosts:               oss.OtherCosts,               // 80
-		Tax:                      oss.Tax,                      // 81
-		Total:                    oss.Total,                    // 82
-		PaymentAmount:            oss.PaymentAmount,            // 83
-		PaymentDate:              oss.PaymentDate,              // 84
-		IsCash:                   oss.IsCash,                   // 85
-		IsCheque:                 oss.IsCheque,                 // 86
-		IsDebit:                  oss.IsDebit,                  // 87
-		IsCredit:                 oss.IsCredit,                 // 88
-		IsOther:                  oss.IsOther,                  // 89
-		ClientSignature:          oss.ClientSignature,          // 90
-		AssociateSignDate:        oss.AssociateSignDate,        // 91
-		AssociateSignature:       oss.AssociateSignature,       // 92
+		Uuid:                     uuid.NewString(),                             // 1
+		TenantId:                 tid,                                          // 2
+		OldId:                    orderId,                                      // 3
+		InvoiceId:                oss.InvoiceId,                                // 4
+		OrderId:                  orderId,                                      // 5
+		InvoiceDate:              oss.InvoiceDate,                              // 6
+		AssociateName:            oss.AssociateName,                            // 7
+		AssociateTelephone:       oss.AssociateTelephone,                       // 8
+		ClientName:               oss.ClientName,                               // 9
+		ClientTelephone:          oss.ClientTelephone,                          // 10
+		ClientEmail:              oss.ClientEmail,                              // 11
+		Line01Qty:                oss.Line01Qty,                                // 12
+		Line01Desc:               oss.Line01Desc,                               // 13
+		Line01Price:              oss.Line01Price,                              // 14
+		Line01Amount:             oss.Line01Amount,                             // 15
+		Line02Qty:                oss.Line02Qty,                                // 16
+		Line02Desc:               oss.Line02Desc,                               // 17
+		Line02Price:              oss.Line02Price,                              // 18
+		Line02Amount:             oss.Line02Amount,                             // 19
+		Line03Qty:                oss.Line03Qty,                                // 20
+		Line03Desc:               oss.Line03Desc,                               // 21
+		Line03Price:              oss.Line03Price,                              // 22
+		Line03Amount:             oss.Line03Amount,                             // 23
+		Line04Qty:                oss.Line04Qty,                                // 24
+		Line04Desc:               oss.Line04Desc,                               // 25
+		Line04Price:              oss.Line04Price,                              // 26
+		Line04Amount:             oss.Line04Amount,                             // 27
+		Line05Qty:                oss.Line05Qty,                                // 28
+		Line05Desc:               oss.Line05Desc,                               // 29
+		Line05Price:              oss.Line05Price,                              // 30
+		Line05Amount:             oss.Line05Amount,                             // 31
+		Line06Qty:                oss.Line06Qty,                                // 32
+		Line06Desc:               oss.Line06Desc,                               // 33
+		Line06Price:              oss.Line06Price,                              // 34
+		Line06Amount:             oss.Line06Amount,                             // 35
+		Line07Qty:                oss.Line07Qty,                                // 36
+		Line07Desc:               oss.Line07Desc,                               // 37
+		Line07Price:              oss.Line07Price,                              // 38
+		Line07Amount:             oss.Line07Amount,                             // 39
+		Line08Qty:                oss.Line08Qty,                                // 40
+		Line08Desc:               oss.Line08Desc,                               // 41
+		Line08Price:              oss.Line08Price,                              // 42
+		Line08Amount:             oss.Line08Amount,                             // 43
+		Line09Qty:                oss.Line09Qty,                                // 44
+		Line09Desc:               oss.Line09Desc,                               // 45
+		Line09Price:              oss.Line09Price,                              // 46
+		Line09Amount:             oss.Line09Amount,                             // 47
+		Line10Qty:                oss.Line10Qty,                                // 48
+		Line10Desc:               oss.Line10Desc,                               // 49
+		Line10Price:              oss.Line10Price,                              // 50
+		Line10Amount:             oss.Line10Amount,                             // 51
+		Line11Qty:                oss.Line11Qty,                                // 52
+		Line11Desc:               oss.Line11Desc,                               // 53
+		Line11Price:              oss.Line11Price,                              // 54
+		Line11Amount:             oss.Line11Amount,                             // 55
+		Line12Qty:                oss.Line12Qty,                                // 56
+		Line12Desc:               oss.Line12Desc,                               // 57
+		Line12Price:              oss.Line12Price,                              // 58
+		Line12Amount:             oss.Line12Amount,                             // 59
+		Line13Qty:                oss.Line13Qty,                                // 60
+		Line13Desc:               oss.Line13Desc,                               // 61
+		Line13Price:              oss.Line13Price,                              // 62
+		Line13Amount:             oss.Line13Amount,                             // 63
+		Line14Qty:                oss.Line14Qty,                                // 64
+		Line14Desc:               oss.Line14Desc,                               // 65
+		Line14Price:              oss.Line14Price,                              // 66
+		Line14Amount:             oss.Line14Amount,                             // 67
+		Line15Qty:                oss.Line15Qty,                                // 68
+		Line15Desc:               oss.Line15Desc,                               // 69
+		Line15Price:              oss.Line15Price,                              // 70
+		Line15Amount:             oss.Line15Amount,                             // 71
+		InvoiceQuoteDays:         oss.InvoiceQuoteDays,                         // 72
+		InvoiceAssociateTax:      oss.InvoiceAssociateTax,                      // 73
+		InvoiceQuoteDate:         oss.InvoiceQuoteDate,                         // 74
+		InvoiceCustomersApproval: oss.InvoiceCustomersApproval,                 // 75
+		Line01Notes:              oss.Line01Notes,                              // 76
+		Line02Notes:              oss.Line02Notes,                              // 77
+		TotalLabour:              models.NewMoneyFromFloat(oss.TotalLabour),    // 78
+		TotalMaterials:           models.NewMoneyFromFloat(oss.TotalMaterials), // 79
+		OtherCosts:               models.NewMoneyFromFloat(oss.OtherCosts),     // 80
+		Tax:                      models.NewMoneyFromFloat(oss.Tax),            // 81
+		Total:                    models.NewMoneyFromFloat(oss.Total),          // 82
+		PaymentAmount:            models.NewMoneyFromFloat(oss.PaymentAmount),  // 83
+		PaymentDate:              oss.PaymentDate,                              // 84
+		IsCash:                   oss.IsCash,                                   // 85
+		IsCheque:                 oss.IsCheque,                                 // 86
+		IsDebit:                  oss.IsDebit,                                  // 87
+		IsCredit:                 oss.IsCredit,                                 // 88
+		IsOther:                  oss.IsOther,                                  // 89
+		ClientSignature:          oss.ClientSignature,                          // 90
+		AssociateSignDate:        oss.AssociateSignDate,                        // 91
+		AssociateSignature:       oss.AssociateSignature,                       // 92
 		// WorkOrderId            uint64 `json:"work_order_id"`
 		CreatedTime:      oss.CreatedAt,      // 93
 		LastModifiedTime: oss.LastModifiedAt, // 94
@@ -468,21 +443,103 @@ func insertWorkOrderInvoiceETL(
 		// CreatedFromIsPublic bool `json:"created_from_is_public"`
 		// LastModifiedFrom string `json:"last_modified_from"`
 		// LastModifiedFromIsPublic bool `json:"last_modified_from_is_public"`
-		ClientAddress:   oss.ClientAddress,   // 97
-		RevisionVersion: oss.RevisionVersion, // 98
-		Deposit:         oss.Deposit,         // 99
-		AmountDue:       oss.AmountDue,       // 100
-		SubTotal:        oss.SubTotal,        // 101
+		ClientAddress:   oss.ClientAddress,                       // 97
+		RevisionVersion: oss.RevisionVersion,                     // 98
+		Deposit:         models.NewMoneyFromFloat(oss.Deposit),   // 99
+		AmountDue:       models.NewMoneyFromFloat(oss.AmountDue), // 100
+		SubTotal:        models.NewMoneyFromFloat(oss.SubTotal),  // 101
 		// State:                 oss.State,           // 102 (TODO)
 		CreatedByName:      null.StringFrom(user.Name), // 103
 		LastModifiedByName: null.StringFrom(user.Name), // 104
 	}
 
+	// The old invoices always had a payment date so only keep it if the
+	// invoice was actually paid.
+	if oss.PaymentAmount == 0 {
+		m.PaymentDate = null.Time{}
+	}
+
 	fmt.Println("OrderId:", orderId)
 
-	err = wotp.Insert(ctx, m)
+	// The invoice may already exist if an earlier run imported it, in that
+	// case we keep it and only import its line items again.
+	inv, err := wotp.GetByOrderId(ctx, orderId)
 	if err != nil {
-		log.Panic("wotp.Insert | err", err)
+		log.Panic("wotp.GetByOrderId | err", err)
+	}
+	if inv == nil {
+		err = wotp.Insert(ctx, m)
+		if err != nil {
+			log.Panic("wotp.Insert | err", err)
+		}
+		inv, err = wotp.GetByOrderId(ctx, orderId)
+		if err != nil {
+			log.Panic("wotp.GetByOrderId | err", err)
+		}
+	}
+
+	//
+	// Insert the line items.
+	//
+
+	for _, li := range getWorkOrderInvoiceLineItemsETL(m) {
+		li.Uuid = uuid.NewString()
+		li.TenantId = tid
+		li.InvoiceId = inv.Id
+		li.CreatedTime = m.CreatedTime
+		li.CreatedById = null.IntFrom(int64(userId))
+		li.LastModifiedTime = m.LastModifiedTime
+		li.LastModifiedById = null.IntFrom(int64(userId))
+		if err := lir.InsertOrUpdateByOld(ctx, li); err != nil {
+			log.Panic("lir.InsertOrUpdateByOld | err", err)
+		}
 	}
 	fmt.Println("Imported ID#", orderId)
 }
+
+// Function will explode the fifteen `Line01` to `Line15` column groups of the
+// invoice into line items, skipping the empty lines. Please note the old
+// invoices did not track what the line was for so we import them as labour.
+func getWorkOrderInvoiceLineItemsETL(m *models.WorkOrderInvoice) []*models.WorkOrderInvoiceLineItem {
+	lines := []struct {
+		Qty    null.Int
+		Desc   null.String
+		Price  null.Float
+		Amount null.Float
+		Notes  null.String
+	}{
+		{null.IntFrom(int64(m.Line01Qty)), null.StringFrom(m.Line01Desc), null.FloatFrom(m.Line01Price), null.FloatFrom(m.Line01Amount), m.Line01Notes},
+		{m.Line02Qty, m.Line02Desc, m.Line02Price, m.Line02Amount, m.Line02Notes},
+		{m.Line03Qty, m.Line03Desc, m.Line03Price, m.Line03Amount, null.String{}},
+		{m.Line04Qty, m.Line04Desc, m.Line04Price, m.Line04Amount, null.String{}},
+		{m.Line05Qty, m.Line05Desc, m.Line05Price, m.Line05Amount, null.String{}},
+		{m.Line06Qty, m.Line06Desc, m.Line06Price, m.Line06Amount, null.String{}},
+		{m.Line07Qty, m.Line07Desc, m.Line07Price, m.Line07Amount, null.String{}},
+		{m.Line08Qty, m.Line08Desc, m.Line08Price, m.Line08Amount, null.String{}},
+		{m.Line09Qty, m.Line09Desc, m.Line09Price, m.Line09Amount, null.String{}},
+		{m.Line10Qty, m.Line10Desc, m.Line10Price, m.Line10Amount, null.String{}},
+		{m.Line11Qty, m.Line11Desc, m.Line11Price, m.Line11Amount, null.String{}},
+		{m.Line12Qty, m.Line12Desc, m.Line12Price, m.Line12Amount, null.String{}},
+		{m.Line13Qty, m.Line13Desc, m.Line13Price, m.Line13Amount, null.String{}},
+		{m.Line14Qty, m.Line14Desc, m.Line14Price, m.Line14Amount, null.String{}},
+		{m.Line15Qty, m.Line15Desc, m.Line15Price, m.Line15Amount, null.String{}},
+	}
+
+	var arr []*models.WorkOrderInvoiceLineItem
+	for i, l := range lines {
+		if l.Desc.ValueOrZero() == "" && l.Amount.ValueOrZero() == 0 {
+			continue
+		}
+		arr = append(arr, &models.WorkOrderInvoiceLineItem{
+			OldId:       uint64(i + 1),
+			SortNumber:  int16(i + 1),
+			TypeOf:      models.WorkOrderInvoiceLineItemLabourTypeOf,
+			Quantity:    float64(l.Qty.ValueOrZero()),
+			Description: l.Desc.ValueOrZero(),
+			UnitPrice:   models.NewMoneyFromFloat(l.Price.ValueOrZero()),
+			Amount:      models.NewMoneyFromFloat(l.Amount.ValueOrZero()),
+			Notes:       l.Notes.ValueOrZero(),
+		})
+	}
+	return arr
+}
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,7 +13,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -24,6 +22,7 @@ var (
 func init() {
 	workOrderServiceFeeETLCmd.Flags().StringVarP(&workOrderServiceFeeETLSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	workOrderServiceFeeETLCmd.MarkFlagRequired("schema_name")
+	addETLFlags(workOrderServiceFeeETLCmd)
 	rootCmd.AddCommand(workOrderServiceFeeETLCmd)
 }
 
@@ -37,43 +36,33 @@ var workOrderServiceFeeETLCmd = &cobra.Command{
 }
 
 func doRunImportWorkOrderServiceFee() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, workOrderServiceFeeETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	irr := repositories.NewWorkOrderServiceFeeRepo(db)
-	ur := repositories.NewUserRepo(db)
+	runETL(workOrderServiceFeeETLSchemaName, 0, []*etlStep{workOrderServiceFeeETLStep})
+}
 
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, workOrderServiceFeeETLSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant == nil {
-		log.Fatal("Tenant does not exist!")
-	}
+var workOrderServiceFeeETLStep = &etlStep{
+	Name: "work_order_service_fee",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		irr := repositories.NewWorkOrderServiceFeeRepo(e.DB)
+		ur := repositories.NewUserRepo(e.DB)
 
-	runWorkOrderServiceFeeETL(ctx, tenant.Id, irr, ur, oldDb)
+		arr, err := ListAllWorkOrderServiceFees(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oir := range arr {
+			oir := oir
+			rows = append(rows, &etlRow{
+				OldId:  oir.Id,
+				Record: oir,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertWorkOrderServiceFeeETL(ctx, e.TenantId, irr.WithTx(tx), ur.WithTx(tx), oir)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldUWorkOrderServiceFee struct {
@@ -133,16 +122,6 @@ func ListAllWorkOrderServiceFees(db *sql.DB) ([]*OldUWorkOrderServiceFee, error)
 	return arr, err
 }
 
-func runWorkOrderServiceFeeETL(ctx context.Context, tenantId uint64, irr *repositories.WorkOrderServiceFeeRepo, ur *repositories.UserRepo, oldDb *sql.DB) {
-	workOrderServiceFees, err := ListAllWorkOrderServiceFees(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, oir := range workOrderServiceFees {
-		insertWorkOrderServiceFeeETL(ctx, tenantId, irr, ur, oir)
-	}
-}
-
 func insertWorkOrderServiceFeeETL(ctx context.Context, tid uint64, irr *repositories.WorkOrderServiceFeeRepo, ur *repositories.UserRepo, oir *OldUWorkOrderServiceFee) {
 	//
 	// Set the `state`.
@@ -220,7 +199,7 @@ func insertWorkOrderServiceFeeETL(ctx context.Context, tid uint64, irr *reposito
 		Uuid:               uuid.NewString(),
 		Title:              oir.Title,
 		Description:        oir.Description,
-		Percentage:         oir.Percentage,
+		Percentage:         models.NewPercentFromFloat(oir.Percentage),
 		CreatedTime:        oir.CreatedAt,
 		CreatedById:        createdById,
 		CreatedByName:      createdByName,
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +12,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -23,6 +21,7 @@ var (
 func init() {
 	workOrderSkillSetCmd.Flags().StringVarP(&workOrderSkillSetSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	workOrderSkillSetCmd.MarkFlagRequired("schema_name")
+	addETLFlags(workOrderSkillSetCmd)
 	rootCmd.AddCommand(workOrderSkillSetCmd)
 }
 
@@ -36,59 +35,34 @@ var workOrderSkillSetCmd = &cobra.Command{
 }
 
 func doRunImportWorkOrderSkillSet() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, workOrderSkillSetSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	wossr := repositories.NewWorkOrderSkillSetRepo(db)
-	ar := repositories.NewWorkOrderRepo(db)
-	vtr := repositories.NewSkillSetRepo(db)
-
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, workOrderSkillSetSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant != nil {
-		runWorkOrderSkillSetETL(ctx, uint64(tenant.Id), wossr, ar, vtr, oldDb)
-	}
+	runETL(workOrderSkillSetSchemaName, 0, []*etlStep{workOrderSkillSetETLStep})
 }
 
-func runWorkOrderSkillSetETL(
-	ctx context.Context,
-	tenantId uint64,
-	wossr *repositories.WorkOrderSkillSetRepo,
-	wor *repositories.WorkOrderRepo,
-	vtr *repositories.SkillSetRepo,
-	oldDb *sql.DB,
-) {
-	aats, err := ListAllWorkOrderSkillSets(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, oss := range aats {
-		insertWorkOrderSkillSetETL(ctx, tenantId, wossr, wor, vtr, oss)
-	}
+var workOrderSkillSetETLStep = &etlStep{
+	Name: "work_order_skill_set",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		wossr := repositories.NewWorkOrderSkillSetRepo(e.DB)
+		wor := repositories.NewWorkOrderRepo(e.DB)
+		vtr := repositories.NewSkillSetRepo(e.DB)
+
+		arr, err := ListAllWorkOrderSkillSets(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oss := range arr {
+			oss := oss
+			rows = append(rows, &etlRow{
+				OldId:  oss.Id,
+				Record: oss,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertWorkOrderSkillSetETL(ctx, e.TenantId, wossr.WithTx(tx), wor.WithTx(tx), vtr.WithTx(tx), oss)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldWorkOrderSkillSet struct {
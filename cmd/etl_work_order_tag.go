@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +12,6 @@ import (
 
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/repositories"
-	"github.com/over55/workery-server/internal/utils"
 )
 
 var (
@@ -23,6 +21,7 @@ var (
 func init() {
 	workOrderTagCmd.Flags().StringVarP(&workOrderTagSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
 	workOrderTagCmd.MarkFlagRequired("schema_name")
+	addETLFlags(workOrderTagCmd)
 	rootCmd.AddCommand(workOrderTagCmd)
 }
 
@@ -36,59 +35,34 @@ var workOrderTagCmd = &cobra.Command{
 }
 
 func doRunImportWorkOrderTag() {
-	// Load up our NEW database.
-	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	// Load up our OLD database.
-	oldDBHost := os.Getenv("WORKERY_OLD_DB_HOST")
-	oldDBPort := os.Getenv("WORKERY_OLD_DB_PORT")
-	oldDBUser := os.Getenv("WORKERY_OLD_DB_USER")
-	oldDBPassword := os.Getenv("WORKERY_OLD_DB_PASSWORD")
-	oldDBName := os.Getenv("WORKERY_OLD_DB_NAME")
-	oldDb, err := utils.ConnectDB(oldDBHost, oldDBPort, oldDBUser, oldDBPassword, oldDBName, workOrderTagSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer oldDb.Close()
-
-	// Load up our background context.
-	ctx := context.Background()
-
-	// Load up our repositories.
-	tr := repositories.NewTenantRepo(db)
-	wotp := repositories.NewWorkOrderTagRepo(db)
-	ar := repositories.NewWorkOrderRepo(db)
-	vtr := repositories.NewTagRepo(db)
-
-	// Lookup the tenant.
-	tenant, err := tr.GetBySchemaName(ctx, workOrderTagSchemaName)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if tenant != nil {
-		runWorkOrderTagETL(ctx, uint64(tenant.Id), wotp, ar, vtr, oldDb)
-	}
+	runETL(workOrderTagSchemaName, 0, []*etlStep{workOrderTagETLStep})
 }
 
-func runWorkOrderTagETL(
-	ctx context.Context,
-	tenantId uint64,
-	wotp *repositories.WorkOrderTagRepo,
-	ar *repositories.WorkOrderRepo,
-	vtr *repositories.TagRepo,
-	oldDb *sql.DB,
-) {
-	aats, err := ListAllWorkOrderTags(oldDb)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, oss := range aats {
-		insertWorkOrderTagETL(ctx, tenantId, wotp, ar, vtr, oss)
-	}
+var workOrderTagETLStep = &etlStep{
+	Name: "work_order_tag",
+	ListRows: func(ctx context.Context, e *etlEnv) ([]*etlRow, error) {
+		wotp := repositories.NewWorkOrderTagRepo(e.DB)
+		ar := repositories.NewWorkOrderRepo(e.DB)
+		vtr := repositories.NewTagRepo(e.DB)
+
+		arr, err := ListAllWorkOrderTags(e.OldDB)
+		if err != nil {
+			return nil, err
+		}
+		var rows []*etlRow
+		for _, oss := range arr {
+			oss := oss
+			rows = append(rows, &etlRow{
+				OldId:  oss.Id,
+				Record: oss,
+				Run: func(ctx context.Context, tx *sql.Tx) error {
+					insertWorkOrderTagETL(ctx, e.TenantId, wotp.WithTx(tx), ar.WithTx(tx), vtr.WithTx(tx), oss)
+					return nil
+				},
+			})
+		}
+		return rows, nil
+	},
 }
 
 type OldWorkOrderTag struct {
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/over55/workery-server/internal/importers"
+	"github.com/over55/workery-server/internal/repositories"
+	"github.com/over55/workery-server/internal/utils"
+)
+
+var (
+	associateImportSchemaName   string
+	associateImportFilePath     string
+	associateImportDryRun       bool
+	associateImportBatchSize    int
+	associateImportMapping      string
+	associateImportHowHearId    uint64
+	associateImportServiceFeeId uint64
+	associateImportOutput       string
+)
+
+func init() {
+	associateImportCmd.Flags().StringVarP(&associateImportSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
+	associateImportCmd.MarkFlagRequired("schema_name")
+	associateImportCmd.Flags().StringVarP(&associateImportFilePath, "file", "f", "", "The path to the CSV file to import.")
+	associateImportCmd.MarkFlagRequired("file")
+	associateImportCmd.Flags().BoolVar(&associateImportDryRun, "dry_run", false, "Validate the CSV file without saving anything.")
+	associateImportCmd.Flags().IntVar(&associateImportBatchSize, "batch_size", importers.DefaultBatchSize, "The number of rows saved per transaction.")
+	associateImportCmd.Flags().StringVarP(&associateImportMapping, "map", "m", "", "Map the CSV headers to our fields, ex: \"First Name=given_name,Phone=telephone\".")
+	associateImportCmd.Flags().Uint64Var(&associateImportHowHearId, "how_hear_id", 0, "The `how hear about us` item to use if the row does not specify one.")
+	associateImportCmd.Flags().Uint64Var(&associateImportServiceFeeId, "service_fee_id", 0, "The service fee to use if the row does not specify one.")
+	associateImportCmd.Flags().StringVarP(&associateImportOutput, "output", "o", "text", "The report format, either `text` or `json`.")
+	rootCmd.AddCommand(associateImportCmd)
+}
+
+var associateImportCmd = &cobra.Command{
+	Use:   "import_associate",
+	Short: "Import the associates from a CSV file",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		doRunImportAssociateCSV()
+	},
+}
+
+func doRunImportAssociateCSV() {
+	// Load up our database.
+	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	// Load up our background context.
+	ctx := context.Background()
+
+	// Lookup the tenant.
+	tr := repositories.NewTenantRepo(db)
+	tenant, err := tr.GetBySchemaName(ctx, associateImportSchemaName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if tenant == nil {
+		log.Fatal("Tenant does not exist!")
+	}
+
+	mapping, err := importers.ParseMapping(associateImportMapping)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	f, err := os.Open(associateImportFilePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	imp := importers.NewImporter(db)
+	report, err := imp.ImportAssociates(ctx, f, &importers.Options{
+		TenantId:            tenant.Id,
+		CreatedByName:       "Import",
+		DryRun:              associateImportDryRun,
+		BatchSize:           associateImportBatchSize,
+		Mapping:             mapping,
+		DefaultHowHearId:    associateImportHowHearId,
+		DefaultServiceFeeId: associateImportServiceFeeId,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	printImportReport(report, associateImportOutput)
+}
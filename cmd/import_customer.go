@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/over55/workery-server/internal/importers"
+	"github.com/over55/workery-server/internal/repositories"
+	"github.com/over55/workery-server/internal/utils"
+)
+
+var (
+	customerImportSchemaName string
+	customerImportFilePath   string
+	customerImportDryRun     bool
+	customerImportBatchSize  int
+	customerImportMapping    string
+	customerImportHowHearId  uint64
+	customerImportOutput     string
+)
+
+func init() {
+	customerImportCmd.Flags().StringVarP(&customerImportSchemaName, "schema_name", "s", "", "The schema name in the postgres.")
+	customerImportCmd.MarkFlagRequired("schema_name")
+	customerImportCmd.Flags().StringVarP(&customerImportFilePath, "file", "f", "", "The path to the CSV file to import.")
+	customerImportCmd.MarkFlagRequired("file")
+	customerImportCmd.Flags().BoolVar(&customerImportDryRun, "dry_run", false, "Validate the CSV file without saving anything.")
+	customerImportCmd.Flags().IntVar(&customerImportBatchSize, "batch_size", importers.DefaultBatchSize, "The number of rows saved per transaction.")
+	customerImportCmd.Flags().StringVarP(&customerImportMapping, "map", "m", "", "Map the CSV headers to our fields, ex: \"First Name=given_name,Phone=telephone\".")
+	customerImportCmd.Flags().Uint64Var(&customerImportHowHearId, "how_hear_id", 0, "The `how hear about us` item to use if the row does not specify one.")
+	customerImportCmd.Flags().StringVarP(&customerImportOutput, "output", "o", "text", "The report format, either `text` or `json`.")
+	rootCmd.AddCommand(customerImportCmd)
+}
+
+var customerImportCmd = &cobra.Command{
+	Use:   "import_customer",
+	Short: "Import the customers from a CSV file",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		doRunImportCustomerCSV()
+	},
+}
+
+func doRunImportCustomerCSV() {
+	// Load up our database.
+	db, err := utils.ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, databaseName, "public")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	// Load up our background context.
+	ctx := context.Background()
+
+	// Lookup the tenant.
+	tr := repositories.NewTenantRepo(db)
+	tenant, err := tr.GetBySchemaName(ctx, customerImportSchemaName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if tenant == nil {
+		log.Fatal("Tenant does not exist!")
+	}
+
+	mapping, err := importers.ParseMapping(customerImportMapping)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	f, err := os.Open(customerImportFilePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	imp := importers.NewImporter(db)
+	report, err := imp.ImportCustomers(ctx, f, &importers.Options{
+		TenantId:         tenant.Id,
+		CreatedByName:    "Import",
+		DryRun:           customerImportDryRun,
+		BatchSize:        customerImportBatchSize,
+		Mapping:          mapping,
+		DefaultHowHearId: customerImportHowHearId,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	printImportReport(report, customerImportOutput)
+}
+
+// Function prints the import report to the console in either a `json` or
+// human readable `text` format.
+func printImportReport(report *importers.Report, output string) {
+	if output == "json" {
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(b))
+		return
+	}
+
+	for _, row := range report.Rows {
+		fields := make([]string, 0, len(row.Errors))
+		for field := range row.Errors {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			fmt.Printf("Row %d | %s | %s: %s\n", row.Row, row.Status, field, row.Errors[field])
+		}
+	}
+	fmt.Println("Dry run:", report.DryRun)
+	fmt.Println("Total rows:", report.TotalRows)
+	fmt.Println("Valid rows:", report.ValidRows)
+	fmt.Println("Invalid rows:", report.InvalidRows)
+	fmt.Println("Duplicate rows:", report.DuplicateRows)
+	fmt.Println("Imported rows:", report.ImportedRows)
+	fmt.Println("Failed rows:", report.FailedRows)
+}
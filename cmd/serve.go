@@ -14,12 +14,20 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/over55/workery-server/internal/controllers"
+	"github.com/over55/workery-server/internal/importers"
 	repo "github.com/over55/workery-server/internal/repositories"
+	"github.com/over55/workery-server/internal/scheduler"
 	"github.com/over55/workery-server/internal/session"
 	"github.com/over55/workery-server/internal/utils"
 )
 
+var (
+	serveWithScheduler bool
+)
+
 func init() {
+	serveCmd.Flags().BoolVar(&serveWithScheduler, "scheduler", os.Getenv("WORKERY_RUN_SCHEDULER") == "true", "Run the task scheduler inside the server")
+	serveCmd.Flags().DurationVar(&schedulerInterval, "interval", scheduler.DefaultInterval, "How often the scheduled jobs run")
 	rootCmd.AddCommand(serveCmd)
 }
 
@@ -54,8 +62,11 @@ func runServeCmd() {
 	// Load up our repositories.
 	asir := repo.NewActivitySheetItemRepo(db)
 	aalr := repo.NewAssociateAwayLogRepo(db)
+	acalr := repo.NewAssociateCalendarRepo(db)
 	acr := repo.NewAssociateCommentRepo(db)
+	acomr := repo.NewAssociateComplianceRepo(db)
 	airr := repo.NewAssociateInsuranceRequirementRepo(db)
+	alr := repo.NewAssociateLedgerRepo(db)
 	assr := repo.NewAssociateSkillSetRepo(db)
 	atr := repo.NewAssociateTagRepo(db)
 	avtr := repo.NewAssociateVehicleTypeRepo(db)
@@ -97,13 +108,21 @@ func runServeCmd() {
 	tagr := repo.NewTagRepo(db)
 	tir := repo.NewTaskItemRepo(db)
 	tr := repo.NewTenantRepo(db)
+	titr := repo.NewTenantInvoiceTemplateRepo(db)
+	tamr := repo.NewTenantAccountMappingRepo(db)
+	ttrr := repo.NewTenantTaxRuleRepo(db)
+	ttsr := repo.NewTenantTaskSettingRepo(db)
+	woirr := repo.NewWorkOrderInvoiceRevisionRepo(db)
+	aer := repo.NewAccountingExportRepo(db)
 	ur := repo.NewUserRepo(db)
 	vtr := repo.NewVehicleTypeRepo(db)
 	wocr := repo.NewWorkOrderCommentRepo(db)
 	wodr := repo.NewWorkOrderDepositRepo(db)
 	woir := repo.NewWorkOrderInvoiceRepo(db)
+	woilir := repo.NewWorkOrderInvoiceLineItemRepo(db)
 	wosfr := repo.NewWorkOrderServiceFeeRepo(db)
 	wossr := repo.NewWorkOrderSkillSetRepo(db)
+	wosur := repo.NewWorkOrderSurveyRepo(db)
 	wotr := repo.NewWorkOrderTagRepo(db)
 	wor := repo.NewWorkOrderRepo(db)
 	laalr := repo.NewLiteAssociateAwayLogRepo(db)
@@ -112,14 +131,21 @@ func runServeCmd() {
 	// account with our ID
 	sm := session.New()
 
+	// Load up our S3 instance which holds the private files of the tenants.
+	s3Client, bucketName := getS3ClientInstance()
+
 	// Instead of using a `New` sort of function, we will populate our structure
 	// so we can use it.
 	c := &controllers.Controller{
 		SecretSigningKeyBin:               []byte(applicationSigningKey),
+		AccountingExportRepo:              aer,
 		ActivitySheetItemRepo:             asir,
 		AssociateAwayLogRepo:              aalr,
+		AssociateCalendarRepo:             acalr,
 		AssociateCommentRepo:              acr,
+		AssociateComplianceRepo:           acomr,
 		AssociateInsuranceRequirementRepo: airr,
+		AssociateLedgerRepo:               alr,
 		AssociateSkillSetRepo:             assr,
 		AssociateTagRepo:                  atr,
 		AssociateVehicleTypeRepo:          avtr,
@@ -162,16 +188,26 @@ func runServeCmd() {
 		TagRepo:                          tagr,
 		TaskItemRepo:                     tir,
 		TenantRepo:                       tr,
+		TenantInvoiceTemplateRepo:        titr,
+		TenantAccountMappingRepo:         tamr,
+		TenantTaxRuleRepo:                ttrr,
+		TenantTaskSettingRepo:            ttsr,
+		WorkOrderInvoiceRevisionRepo:     woirr,
 		UserRepo:                         ur,
 		VehicleTypeRepo:                  vtr,
 		WorkOrderCommentRepo:             wocr,
 		WorkOrderDepositRepo:             wodr,
 		WorkOrderInvoiceRepo:             woir,
+		WorkOrderInvoiceLineItemRepo:     woilir,
 		WorkOrderServiceFeeRepo:          wosfr,
 		WorkOrderSkillSetRepo:            wossr,
+		WorkOrderSurveyRepo:              wosur,
 		WorkOrderTagRepo:                 wotr,
 		WorkOrderRepo:                    wor,
 		SessionManager:                   sm,
+		S3Client:                         s3Client,
+		S3BucketName:                     bucketName,
+		Importer:                         importers.NewImporter(db),
 	}
 
 	mux := http.NewServeMux()
@@ -192,11 +228,25 @@ func runServeCmd() {
 
 	go runMainRuntimeLoop(srv)
 
+	// The scheduler stops once the server receives the shutdown signal.
+	schedulerCtx, cancelScheduler := context.WithCancel(context.Background())
+	schedulerStopped := make(chan struct{})
+	if serveWithScheduler {
+		go func() {
+			scheduler.New(db, schedulerInterval).Run(schedulerCtx)
+			close(schedulerStopped)
+		}()
+	} else {
+		close(schedulerStopped)
+	}
+
 	log.Print("Server Started")
 
 	// Run the main loop blocking code.
 	<-done
 
+	cancelScheduler()
+	<-schedulerStopped
 	stopMainRuntimeLoop(srv)
 }
 
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/over55/workery-server/internal/scheduler"
+	"github.com/over55/workery-server/internal/utils"
+)
+
+var (
+	schedulerInterval time.Duration
+)
+
+func init() {
+	workerCmd.Flags().DurationVar(&schedulerInterval, "interval", scheduler.DefaultInterval, "How often the scheduled jobs run")
+	rootCmd.AddCommand(workerCmd)
+}
+
+var workerCmd = &cobra.Command{
+	Use:   "worker",
+	Short: "Run the task scheduler without serving the JSON API",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		runWorkerCmd()
+	},
+}
+
+func runWorkerCmd() {
+	// Load up our database.
+	db, err := utils.ConnectDB(
+		databaseHost,
+		databasePort,
+		databaseUser,
+		databasePassword,
+		databaseName,
+		"public",
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		scheduler.New(db, schedulerInterval).Run(ctx)
+		close(stopped)
+	}()
+
+	log.Print("Worker Started")
+
+	done := make(chan os.Signal, 1)
+	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	<-done
+
+	// Wait for the running jobs to finish so the advisory lock is released.
+	cancel()
+	<-stopped
+	log.Print("Worker Exited")
+}
@@ -0,0 +1,232 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/reports"
+	"github.com/over55/workery-server/internal/utils"
+)
+
+// Function exports the invoices, deposits and service fee payments between
+// the `from` and `to` dates as journal entries for the accounting software
+// of the tenant. The `format` parameter is either `iif` (QuickBooks) or `csv`
+// and the exported records are marked so they are never exported again,
+// unless `dry_run` is set in which case nothing gets marked. The file of the
+// export can be downloaded again with `accountingExportDownloadEndpoint`.
+func (h *Controller) accountingExportCreateEndpoint(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	user := ctx.Value("user").(*models.User)
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+
+	// Permission handling - Only management can export the books.
+	if roleId != 1 && roleId != 2 {
+		http.Error(w, "Forbidden - You are not management", http.StatusForbidden)
+		return
+	}
+
+	tenant, err := h.TenantRepo.GetById(ctx, tenantId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	loc, err := utils.GetTimezoneLocation(tenant.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	// Extract our parameters from the URL, the dates are in the timezone of
+	// the tenant and the `to` date is inclusive.
+	e := make(map[string]string)
+	format := r.FormValue("format")
+	if format == "" {
+		format = models.AccountingCSVFormat
+	}
+	if format != models.AccountingCSVFormat && format != models.AccountingIIFFormat {
+		e["format"] = "invalid value"
+	}
+	from := parseDateFormValue(r, "from", loc, false, e)
+	to := parseDateFormValue(r, "to", loc, true, e)
+	if _, ok := e["from"]; !ok && !from.Valid {
+		e["from"] = "missing value"
+	}
+	if _, ok := e["to"]; !ok && !to.Valid {
+		e["to"] = "missing value"
+	}
+	if from.Valid && to.Valid && !from.Time.Before(to.Time) {
+		e["to"] = "must be on or after from"
+	}
+	dryRun, _ := strconv.ParseBool(r.FormValue("dry_run"))
+	if len(e) != 0 {
+		b, _ := json.Marshal(e)
+		http.Error(w, string(b), http.StatusBadRequest)
+		return
+	}
+
+	mapping, err := h.TenantAccountMappingRepo.GetByTenantId(ctx, tenantId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if mapping == nil {
+		mapping = models.NewDefaultTenantAccountMapping(tenantId)
+	}
+
+	d := &reports.AccountingJournal{
+		Mapping:  mapping,
+		Location: loc,
+	}
+	if d.Invoices, err = h.AccountingExportRepo.ListUnexportedInvoices(ctx, tenantId, from.Time, to.Time); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if d.Deposits, err = h.AccountingExportRepo.ListUnexportedDeposits(ctx, tenantId, from.Time, to.Time); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if d.ServiceFeePayments, err = h.AccountingExportRepo.ListUnexportedServiceFeePayments(ctx, tenantId, from.Time, to.Time); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	arr := reports.BuildAccountingJournalEntries(d)
+
+	buf := new(bytes.Buffer)
+	if err := reports.WriteAccountingJournal(buf, format, arr, loc); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !dryRun && len(arr) > 0 {
+		m := &models.AccountingExport{
+			Uuid:          uuid.NewString(),
+			TenantId:      tenantId,
+			Format:        format,
+			FromDate:      from.Time,
+			ToDate:        to.Time,
+			EntryCount:    uint64(len(arr)),
+			CreatedTime:   time.Now(),
+			CreatedById:   null.IntFrom(int64(user.Id)),
+			CreatedByName: null.StringFrom(user.Name),
+			CreatedFromIP: null.NewString(ipAddress, ipAddress != ""),
+		}
+		if err := h.AccountingExportRepo.InsertWithEntries(ctx, m, arr); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	writeAccountingJournalFile(w, buf, format, from.Time, to.Time, loc)
+}
+
+func (h *Controller) accountingExportsListEndpoint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+
+	// Permission handling - Only management can export the books.
+	if roleId != 1 && roleId != 2 {
+		http.Error(w, "Forbidden - You are not management", http.StatusForbidden)
+		return
+	}
+
+	// Extract our parameters from the URL.
+	offsetParam, _ := strconv.ParseUint(r.FormValue("offset"), 10, 64)
+	limitParam, _ := strconv.ParseUint(r.FormValue("limit"), 10, 64)
+	if limitParam == 0 || limitParam > 500 {
+		limitParam = 100
+	}
+
+	arr, err := h.AccountingExportRepo.ListByTenantId(ctx, tenantId, offsetParam, limitParam)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	count, err := h.AccountingExportRepo.CountByTenantId(ctx, tenantId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := idos.NewAccountingExportListResponseIDO(arr, count)
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function writes the file of the export again from the journal entries
+// which were kept when the records were exported.
+func (h *Controller) accountingExportDownloadEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+
+	// Permission handling - Only management can export the books.
+	if roleId != 1 && roleId != 2 {
+		http.Error(w, "Forbidden - You are not management", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m, err := h.AccountingExportRepo.GetById(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if m == nil || m.TenantId != tenantId {
+		http.Error(w, "Accounting export does not exist", http.StatusNotFound)
+		return
+	}
+	arr, err := h.AccountingExportRepo.ListEntriesByExportId(ctx, m.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if uint64(len(arr)) != m.EntryCount {
+		http.Error(w, "Accounting export was made before the exported entries were kept", http.StatusGone)
+		return
+	}
+
+	tenant, err := h.TenantRepo.GetById(ctx, tenantId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	loc, err := utils.GetTimezoneLocation(tenant.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	buf := new(bytes.Buffer)
+	if err := reports.WriteAccountingJournal(buf, m.Format, arr, loc); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeAccountingJournalFile(w, buf, m.Format, m.FromDate, m.ToDate, loc)
+}
+
+// Function writes the journal as an attachment named after the exported
+// dates, the `to` date is exclusive.
+func writeAccountingJournalFile(w http.ResponseWriter, buf *bytes.Buffer, format string, from time.Time, to time.Time, loc *time.Location) {
+	contentType, ext := reports.AccountingJournalContentType(format)
+	filename := "journal-" + from.In(loc).Format("20060102") + "-" + to.In(loc).AddDate(0, 0, -1).Format("20060102") + ext
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	buf.WriteTo(w)
+}
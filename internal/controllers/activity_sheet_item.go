@@ -0,0 +1,272 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/validators"
+)
+
+// The fields the activity sheet can be sorted by, the sort field is written
+// into the query so only these values are allowed.
+var activitySheetItemSortFields = map[string]bool{
+	"created_time":           true,
+	"associate_lexical_name": true,
+	"order_id":               true,
+	"state":                  true,
+}
+
+// Function returns the responses of the associates to being offered a job,
+// the optional `associate_id`, `order_id`, `ongoing_order_id` and `state`
+// parameters filter the results.
+func (h *Controller) activitySheetItemsListEndpoint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+
+	// Permission handling - Only staff can view the activity sheet.
+	if roleId != 1 && roleId != 2 && roleId != 3 {
+		http.Error(w, "Forbidden - You are not staff", http.StatusForbidden)
+		return
+	}
+
+	// Extract our parameters from the URL.
+	offsetParamString := r.FormValue("offset")
+	offsetParam, _ := strconv.ParseUint(offsetParamString, 10, 64)
+	limitParamString := r.FormValue("limit")
+	limitParam, _ := strconv.ParseUint(limitParamString, 10, 64)
+	if limitParam == 0 || limitParam > 500 {
+		limitParam = 100
+	}
+	sortOrderString := strings.ToUpper(r.FormValue("sort_order"))
+	if sortOrderString != "ASC" {
+		sortOrderString = "DESC"
+	}
+	sortFieldString := r.FormValue("sort_field")
+	if !activitySheetItemSortFields[sortFieldString] {
+		sortFieldString = "created_time"
+	}
+	associateId, _ := strconv.ParseInt(r.FormValue("associate_id"), 10, 64)
+	orderId, _ := strconv.ParseInt(r.FormValue("order_id"), 10, 64)
+	ongoingOrderId, _ := strconv.ParseInt(r.FormValue("ongoing_order_id"), 10, 64)
+	var states []int8
+	if state, err := strconv.ParseInt(r.FormValue("state"), 10, 8); err == nil {
+		states = append(states, int8(state))
+	}
+
+	f := models.ActivitySheetItemFilter{
+		TenantId:       tenantId,
+		States:         states,
+		SortField:      sortFieldString,
+		SortOrder:      sortOrderString,
+		AssociateId:    null.NewInt(associateId, associateId != 0),
+		OrderId:        null.NewInt(orderId, orderId != 0),
+		OngoingOrderId: null.NewInt(ongoingOrderId, ongoingOrderId != 0),
+		Offset:         offsetParam,
+		Limit:          limitParam,
+	}
+
+	arrCh := make(chan []*models.ActivitySheetItem)
+	countCh := make(chan uint64)
+
+	go func() {
+		arr, err := h.ActivitySheetItemRepo.ListByFilter(ctx, &f)
+		if err != nil {
+			log.Println("WARNING: activitySheetItemsListEndpoint|ListByFilter|err:", err.Error())
+			arrCh <- nil
+			return
+		}
+		arrCh <- arr[:]
+	}()
+
+	go func() {
+		count, err := h.ActivitySheetItemRepo.CountByFilter(ctx, &f)
+		if err != nil {
+			log.Println("WARNING: activitySheetItemsListEndpoint|CountByFilter|err:", err.Error())
+			countCh <- 0
+			return
+		}
+		countCh <- count
+	}()
+
+	arr, count := <-arrCh, <-countCh
+
+	res := idos.NewActivitySheetItemListResponseIDO(arr, count)
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) activitySheetItemCreateEndpoint(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+
+	// Permission handling - Only staff can add to the activity sheet.
+	if roleId != 1 && roleId != 2 && roleId != 3 {
+		http.Error(w, "Forbidden - You are not staff", http.StatusForbidden)
+		return
+	}
+
+	var postData *idos.ActivitySheetItemIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateActivitySheetItemSaveFromRequest(postData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	associate, err := h.AssociateRepo.GetById(ctx, postData.AssociateId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if associate == nil || associate.TenantId != tenantId {
+		http.Error(w, `{"associate_id":"associate does not exist"}`, http.StatusBadRequest)
+		return
+	}
+
+	var ongoingOrderId null.Int
+	if postData.OrderId.Valid {
+		order, err := h.WorkOrderRepo.GetById(ctx, uint64(postData.OrderId.Int64))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if order == nil || order.TenantId != tenantId {
+			http.Error(w, `{"order_id":"work order does not exist"}`, http.StatusBadRequest)
+			return
+		}
+		ongoingOrderId = order.OngoingWorkOrderId
+	}
+
+	m := h.newActivitySheetItem(r, associate, postData.OrderId, ongoingOrderId, postData.State, strings.TrimSpace(postData.Comment))
+	if err := h.ActivitySheetItemRepo.Insert(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) activitySheetItemGetEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+
+	// Permission handling - Only staff can view the activity sheet.
+	if roleId != 1 && roleId != 2 && roleId != 3 {
+		http.Error(w, "Forbidden - You are not staff", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m, err := h.ActivitySheetItemRepo.GetById(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if m == nil || m.TenantId != tenantId {
+		http.Error(w, "Activity sheet item does not exist", http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function returns the share of the job offers the associate accepted over
+// the last `days` days, by default the last year.
+func (h *Controller) associateAcceptanceRateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	ctx := r.Context()
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	days := 365
+	if s := r.FormValue("days"); s != "" {
+		var err error
+		days, err = strconv.Atoi(s)
+		if err != nil || days < 1 || days > 3650 {
+			http.Error(w, `{"days":"must be between 1 and 3650"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	m, err := h.ActivitySheetItemRepo.GetAcceptanceRateByAssociateId(ctx, a.Id, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) newActivitySheetItem(r *http.Request, associate *models.Associate, orderId null.Int, ongoingOrderId null.Int, state int8, comment string) *models.ActivitySheetItem {
+	ctx := r.Context()
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+
+	return &models.ActivitySheetItem{
+		Uuid:                 uuid.NewString(),
+		TenantId:             associate.TenantId,
+		Comment:              comment,
+		CreatedTime:          time.Now(),
+		CreatedById:          null.IntFrom(int64(user.Id)),
+		CreatedByName:        null.StringFrom(user.Name),
+		CreatedFromIP:        null.StringFrom(ipAddress),
+		AssociateId:          associate.Id,
+		AssociateName:        associate.Name,
+		AssociateLexicalName: associate.LexicalName,
+		OrderId:              orderId,
+		State:                state,
+		OngoingOrderId:       ongoingOrderId,
+	}
+}
+
+// Function returns the latest offer of the work order which is still waiting
+// on the response of the associate, or which the associate accepted through
+// the portal, or nil if there is none. Responding to the offer updates it
+// instead of adding another item to the activity sheet.
+func (h *Controller) getOpenActivitySheetOffer(ctx context.Context, order *models.WorkOrder, associateId uint64) (*models.ActivitySheetItem, error) {
+	f := &models.ActivitySheetItemFilter{
+		TenantId:    order.TenantId,
+		States:      []int8{models.ActivitySheetItemPendingState, models.ActivitySheetItemAcceptedState},
+		SortField:   "created_time",
+		SortOrder:   "DESC",
+		AssociateId: null.IntFrom(int64(associateId)),
+		OrderId:     null.IntFrom(int64(order.Id)),
+		Limit:       1,
+	}
+	arr, err := h.ActivitySheetItemRepo.ListByFilter(ctx, f)
+	if err != nil || len(arr) == 0 {
+		return nil, err
+	}
+	return arr[0], nil
+}
@@ -0,0 +1,521 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/utils"
+	"github.com/over55/workery-server/internal/validators"
+)
+
+func (h *Controller) associateCreateEndpoint(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+
+	// Permission handling - Only staff can create associates.
+	if roleId != 1 && roleId != 2 && roleId != 3 {
+		http.Error(w, "Forbidden - You are not staff", http.StatusForbidden)
+		return
+	}
+
+	// Get the user `POST` data from the HTTP request.
+	var postData *idos.AssociateIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateAssociateSaveFromRequest(postData, true)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+	email := strings.ToLower(strings.TrimSpace(postData.Email))
+	doesExist, err := h.UserRepo.CheckIfExistsByEmail(ctx, email)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if doesExist {
+		http.Error(w, `{"email":"email is already in use"}`, http.StatusBadRequest)
+		return
+	}
+
+	uuidStr := uuid.NewString()
+	m := &models.Associate{
+		Uuid:     uuidStr,
+		TenantId: tenantId,
+		State:    models.AssociateActiveState,
+	}
+	applyAssociateContact(m, &postData.AssociateContactIDO)
+	applyAssociateAddress(m, &postData.AssociateAddressIDO)
+	if ok := h.applyAssociateMetrics(w, r, m, &postData.AssociateMetricsIDO); !ok {
+		return
+	}
+	if !m.JoinDate.Valid {
+		m.JoinDate = null.TimeFrom(time.Now())
+	}
+	setAssociateCreatedBy(ctx, m)
+
+	// The associate logs in with the email of the user account, if no
+	// password was provided then the password needs to be set through the
+	// account before the associate can log in.
+	user := &models.User{
+		Uuid:         uuidStr,
+		TenantId:     tenantId,
+		Email:        m.Email,
+		FirstName:    m.GivenName,
+		LastName:     m.LastName,
+		Name:         m.Name,
+		LexicalName:  m.LexicalName,
+		State:        1, // Active
+		RoleId:       4, // Associate
+		Timezone:     h.getTenantLocation(ctx, tenantId).String(),
+		CreatedTime:  m.CreatedTime,
+		ModifiedTime: m.CreatedTime,
+		JoinedTime:   m.CreatedTime,
+		PrExpiryTime: m.CreatedTime,
+	}
+	if postData.Password != "" {
+		passwordHash, err := utils.HashPassword(postData.Password)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		user.PasswordAlgorithm = utils.HashPasswordAlgorithm()
+		user.PasswordHash = passwordHash
+	}
+	if err := h.AssociateRepo.InsertWithUser(ctx, user, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) associateGetEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	ctx := r.Context()
+
+	m, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	if err := h.attachAssociateReferences(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) associateUpdateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	m, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	// Get the user `PUT` data from the HTTP request.
+	var putData *idos.AssociateIDO
+	if err := json.NewDecoder(r.Body).Decode(&putData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateAssociateSaveFromRequest(putData, false)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	applyAssociateContact(m, &putData.AssociateContactIDO)
+	applyAssociateAddress(m, &putData.AssociateAddressIDO)
+	if ok := h.applyAssociateMetrics(w, r, m, &putData.AssociateMetricsIDO); !ok {
+		return
+	}
+	h.saveAssociateWithUser(w, r, m, nil)
+}
+
+func (h *Controller) associateContactUpdateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	m, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	var putData *idos.AssociateContactIDO
+	if err := json.NewDecoder(r.Body).Decode(&putData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateAssociateContactFromRequest(putData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	applyAssociateContact(m, putData)
+	h.saveAssociateWithUser(w, r, m, nil)
+}
+
+func (h *Controller) associateAddressUpdateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+
+	m, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	var putData *idos.AssociateAddressIDO
+	if err := json.NewDecoder(r.Body).Decode(&putData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateAssociateAddressFromRequest(putData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	applyAssociateAddress(m, putData)
+	setAssociateLastModifiedBy(ctx, m)
+	h.saveAssociate(w, r, m)
+}
+
+func (h *Controller) associateMetricsUpdateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+
+	m, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	var putData *idos.AssociateMetricsIDO
+	if err := json.NewDecoder(r.Body).Decode(&putData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateAssociateMetricsFromRequest(putData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	if ok := h.applyAssociateMetrics(w, r, m, putData); !ok {
+		return
+	}
+	setAssociateLastModifiedBy(ctx, m)
+	h.saveAssociate(w, r, m)
+}
+
+// Function sets the password of the user account the associate logs in with.
+func (h *Controller) associateAccountUpdateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+
+	m, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	var putData *idos.AssociateAccountIDO
+	if err := json.NewDecoder(r.Body).Decode(&putData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateAssociateAccountFromRequest(putData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.UserRepo.GetById(ctx, m.UserId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if user == nil { // Defensive code
+		http.Error(w, "Associate user account does not exist", http.StatusInternalServerError)
+		return
+	}
+	passwordHash, err := utils.HashPassword(putData.Password)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	user.PasswordAlgorithm = utils.HashPasswordAlgorithm()
+	user.PasswordHash = passwordHash
+	user.ModifiedTime = time.Now()
+	if err := h.UserRepo.UpdateById(ctx, user); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Function returns the associate along with the skill sets, tags, vehicle
+// types and insurance requirements of the associate.
+func (h *Controller) attachAssociateReferences(ctx context.Context, m *models.Associate) error {
+	skillSets, err := h.AssociateSkillSetRepo.ListByAssociateId(ctx, m.Id)
+	if err != nil {
+		return err
+	}
+	tags, err := h.AssociateTagRepo.ListByAssociateId(ctx, m.Id)
+	if err != nil {
+		return err
+	}
+	vehicleTypes, err := h.AssociateVehicleTypeRepo.ListByAssociateId(ctx, m.Id)
+	if err != nil {
+		return err
+	}
+	insuranceRequirements, err := h.AssociateInsuranceRequirementRepo.ListByAssociateId(ctx, m.Id)
+	if err != nil {
+		return err
+	}
+	m.SkillSets = skillSets
+	m.Tags = tags
+	m.VehicleTypes = vehicleTypes
+	m.InsuranceRequirements = insuranceRequirements
+	return nil
+}
+
+// Function saves the associate, along with its user account so the login
+// and the name of the account stay in sync with the contact of the
+// associate. The `user` is looked up if it was not provided.
+func (h *Controller) saveAssociateWithUser(w http.ResponseWriter, r *http.Request, m *models.Associate, user *models.User) {
+	ctx := r.Context()
+
+	if user == nil {
+		var err error
+		user, err = h.UserRepo.GetById(ctx, m.UserId)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if user == nil { // Defensive code
+			http.Error(w, "Associate user account does not exist", http.StatusInternalServerError)
+			return
+		}
+	}
+	if user.Email != m.Email {
+		doesExist, err := h.UserRepo.CheckIfExistsByEmail(ctx, m.Email)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if doesExist {
+			http.Error(w, `{"email":"email is already in use"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	setAssociateLastModifiedBy(ctx, m)
+	user.Email = m.Email
+	user.FirstName = m.GivenName
+	user.LastName = m.LastName
+	user.Name = m.Name
+	user.LexicalName = m.LexicalName
+	user.ModifiedTime = m.LastModifiedTime
+	if err := h.AssociateRepo.UpdateWithUserById(ctx, user, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) saveAssociate(w http.ResponseWriter, r *http.Request, m *models.Associate) {
+	if err := h.AssociateRepo.UpdateById(r.Context(), m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func applyAssociateContact(m *models.Associate, d *idos.AssociateContactIDO) {
+	m.TypeOf = d.TypeOf
+	m.OrganizationName = ""
+	m.OrganizationTypeOf = 0
+	if d.TypeOf == models.AssociateCommercialTypeOf {
+		m.OrganizationName = strings.TrimSpace(d.OrganizationName)
+		m.OrganizationTypeOf = d.OrganizationTypeOf
+	}
+	m.GivenName = strings.TrimSpace(d.GivenName)
+	m.MiddleName = strings.TrimSpace(d.MiddleName)
+	m.LastName = strings.TrimSpace(d.LastName)
+	m.Email = strings.ToLower(strings.TrimSpace(d.Email))
+	m.IsOkToEmail = d.IsOkToEmail
+	m.Telephone = strings.TrimSpace(d.Telephone)
+	m.TelephoneTypeOf = d.TelephoneTypeOf
+	m.TelephoneExtension = strings.TrimSpace(d.TelephoneExtension)
+	m.OtherTelephone = strings.TrimSpace(d.OtherTelephone)
+	m.OtherTelephoneTypeOf = d.OtherTelephoneTypeOf
+	m.OtherTelephoneExtension = strings.TrimSpace(d.OtherTelephoneExtension)
+	m.IsOkToText = d.IsOkToText
+	compileAssociateValues(m)
+}
+
+func applyAssociateAddress(m *models.Associate, d *idos.AssociateAddressIDO) {
+	m.AddressCountry = strings.TrimSpace(d.AddressCountry)
+	m.AddressRegion = strings.TrimSpace(d.AddressRegion)
+	m.AddressLocality = strings.TrimSpace(d.AddressLocality)
+	m.PostOfficeBoxNumber = strings.TrimSpace(d.PostOfficeBoxNumber)
+	m.PostalCode = strings.TrimSpace(d.PostalCode)
+	m.StreetAddress = strings.TrimSpace(d.StreetAddress)
+	m.StreetAddressExtra = strings.TrimSpace(d.StreetAddressExtra)
+	compileAssociateValues(m)
+}
+
+// Function applies the metrics to the associate after checking the how hear
+// item and the service fee belong to the tenant, otherwise the error is
+// written to the response and `false` is returned.
+func (h *Controller) applyAssociateMetrics(w http.ResponseWriter, r *http.Request, m *models.Associate, d *idos.AssociateMetricsIDO) bool {
+	ctx := r.Context()
+
+	howHear, err := h.HowHearAboutUsItemRepo.GetById(ctx, d.HowHearId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if howHear == nil || howHear.TenantId != m.TenantId {
+		http.Error(w, `{"how_hear_id":"how hear item does not exist"}`, http.StatusBadRequest)
+		return false
+	}
+	serviceFee, err := h.WorkOrderServiceFeeRepo.GetById(ctx, d.ServiceFeeId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if serviceFee == nil || serviceFee.TenantId != m.TenantId {
+		http.Error(w, `{"service_fee_id":"service fee does not exist"}`, http.StatusBadRequest)
+		return false
+	}
+
+	now := time.Now()
+	m.Birthdate = d.Birthdate
+	if d.JoinDate.Valid {
+		m.JoinDate = d.JoinDate
+	}
+	m.Gender = strings.TrimSpace(d.Gender)
+	m.Nationality = strings.TrimSpace(d.Nationality)
+	m.TaxId = strings.TrimSpace(d.TaxId)
+	m.HowHearId = howHear.Id
+	m.HowHearText = howHear.Text
+	m.HowHearOther = strings.TrimSpace(d.HowHearOther)
+	m.Business = strings.TrimSpace(d.Business)
+	m.HourlySalaryDesired = d.HourlySalaryDesired
+	m.LimitSpecial = strings.TrimSpace(d.LimitSpecial)
+	m.WsibNumber = strings.TrimSpace(d.WsibNumber)
+	m.DriversLicenseClass = strings.TrimSpace(d.DriversLicenseClass)
+	m.ServiceFeeId = serviceFee.Id
+	m.EmergencyContactName = strings.TrimSpace(d.EmergencyContactName)
+	m.EmergencyContactRelationship = strings.TrimSpace(d.EmergencyContactRelationship)
+	m.EmergencyContactTelephone = strings.TrimSpace(d.EmergencyContactTelephone)
+	m.EmergencyContactAlternativeTelephone = strings.TrimSpace(d.EmergencyContactAlternativeTelephone)
+
+	// DEVELOPERS NOTE:
+	// The dates are required by the database, the dates which were not
+	// provided keep their saved value. Same as the importer they default to
+	// today for a new associate which reports them as lapsed in the
+	// compliance report until they are set.
+	dates := []struct {
+		value *time.Time
+		input null.Time
+	}{
+		{&m.DuesDate, d.DuesDate},
+		{&m.CommercialInsuranceExpiryDate, d.CommercialInsuranceExpiryDate},
+		{&m.AutoInsuranceExpiryDate, d.AutoInsuranceExpiryDate},
+		{&m.WsibInsuranceDate, d.WsibInsuranceDate},
+		{&m.PoliceCheck, d.PoliceCheck},
+	}
+	for _, date := range dates {
+		if date.input.Valid {
+			*date.value = date.input.Time
+		} else if m.Id == 0 {
+			*date.value = now
+		}
+	}
+	return true
+}
+
+// Function compiles the name, the address and the search text of the
+// associate from the values which were provided.
+func compileAssociateValues(m *models.Associate) {
+	if m.MiddleName != "" {
+		m.Name = m.GivenName + " " + m.MiddleName + " " + m.LastName
+		m.LexicalName = m.LastName + ", " + m.MiddleName + ", " + m.GivenName
+	} else {
+		m.Name = m.GivenName + " " + m.LastName
+		m.LexicalName = m.LastName + ", " + m.GivenName
+	}
+
+	address := ""
+	if m.StreetAddress != "" {
+		address += m.StreetAddress
+	}
+	if m.StreetAddressExtra != "" {
+		address += " " + m.StreetAddressExtra
+	}
+	if m.StreetAddress != "" {
+		address += ", "
+	}
+	address += m.AddressLocality
+	address += ", " + m.AddressRegion
+	address += ", " + m.AddressCountry
+	m.FullAddressWithoutPostalCode = address
+	m.FullAddressWithPostalCode = "-"
+	m.FullAddressUrl = "https://www.google.com/maps/place/" + address
+	if m.PostalCode != "" {
+		m.FullAddressWithPostalCode = address + ", " + m.PostalCode
+		m.FullAddressUrl = "https://www.google.com/maps/place/" + m.FullAddressWithPostalCode
+	}
+
+	indexedText := []string{m.Name, m.OrganizationName, m.Email, m.Telephone, m.OtherTelephone, m.FullAddressWithPostalCode}
+	runes := []rune(strings.TrimSpace(strings.Join(indexedText, " ")))
+	if len(runes) > 511 {
+		runes = runes[:511]
+	}
+	m.IndexedText = string(runes)
+}
+
+func setAssociateCreatedBy(ctx context.Context, m *models.Associate) {
+	setAssociateLastModifiedBy(ctx, m)
+	m.CreatedTime = m.LastModifiedTime
+	m.CreatedById = m.LastModifiedById
+	m.CreatedByName = m.LastModifiedByName
+	m.CreatedFromIP = m.LastModifiedFromIP
+}
+
+func setAssociateLastModifiedBy(ctx context.Context, m *models.Associate) {
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+	m.LastModifiedTime = time.Now()
+	m.LastModifiedById = null.IntFrom(int64(user.Id))
+	m.LastModifiedByName = null.StringFrom(user.Name)
+	m.LastModifiedFromIP = ipAddress
+}
@@ -1,18 +1,19 @@
 package controllers
 
 import (
-	// "encoding/json"
 	"encoding/json"
 	"log"
 	"net/http"
-	// "time"
 	"strconv"
+	"strings"
+	"time"
 
-	// "github.com/google/uuid"
+	"github.com/google/uuid"
 	null "gopkg.in/guregu/null.v4"
 
 	"github.com/over55/workery-server/internal/idos"
 	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/validators"
 )
 
 func (h *Controller) associateAwayLogsListEndpoint(w http.ResponseWriter, r *http.Request) {
@@ -88,3 +89,267 @@ func (h *Controller) associateAwayLogsListEndpoint(w http.ResponseWriter, r *htt
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
+
+func (h *Controller) associateAwayLogCreateEndpoint(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+
+	// Permission handling - Only staff can manage the away logs.
+	if roleId != 1 && roleId != 2 && roleId != 3 {
+		http.Error(w, "Forbidden - You are not staff", http.StatusForbidden)
+		return
+	}
+
+	// Get the user `POST` data from the HTTP request.
+	var postData *idos.AssociateAwayLogIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateAssociateAwayLogSaveFromRequest(postData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	m := &models.AssociateAwayLog{
+		Uuid:               uuid.NewString(),
+		TenantId:           tenantId,
+		State:              models.AssociateAwayLogActiveState,
+		CreatedTime:        now,
+		CreatedById:        null.IntFrom(int64(user.Id)),
+		CreatedByName:      null.StringFrom(user.Name),
+		CreatedFromIP:      ipAddress,
+		LastModifiedTime:   now,
+		LastModifiedById:   null.IntFrom(int64(user.Id)),
+		LastModifiedByName: null.StringFrom(user.Name),
+		LastModifiedFromIP: ipAddress,
+	}
+	if ok := h.applyAssociateAwayLogIDO(w, r, m, postData); !ok {
+		return
+	}
+	if ok := h.saveAssociateAwayLog(w, r, m); !ok {
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	h.writeAssociateAwayLog(w, r, m)
+}
+
+func (h *Controller) associateAwayLogGetEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	m, ok := h.getAssociateAwayLogForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	h.writeAssociateAwayLog(w, r, m)
+}
+
+func (h *Controller) associateAwayLogUpdateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+
+	m, ok := h.getAssociateAwayLogForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	if m.State != models.AssociateAwayLogActiveState {
+		http.Error(w, "Away log has already ended", http.StatusBadRequest)
+		return
+	}
+
+	// Get the user `PUT` data from the HTTP request.
+	var putData *idos.AssociateAwayLogIDO
+	if err := json.NewDecoder(r.Body).Decode(&putData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateAssociateAwayLogSaveFromRequest(putData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	if ok := h.applyAssociateAwayLogIDO(w, r, m, putData); !ok {
+		return
+	}
+	m.LastModifiedTime = time.Now()
+	m.LastModifiedById = null.IntFrom(int64(user.Id))
+	m.LastModifiedByName = null.StringFrom(user.Name)
+	m.LastModifiedFromIP = ipAddress
+	if ok := h.saveAssociateAwayLog(w, r, m); !ok {
+		return
+	}
+	h.writeAssociateAwayLog(w, r, m)
+}
+
+// Function ends the away log right away, the associate is available for new
+// work orders again.
+func (h *Controller) associateAwayLogEndEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+
+	m, ok := h.getAssociateAwayLogForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	if m.State != models.AssociateAwayLogActiveState {
+		http.Error(w, "Away log has already ended", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	m.State = models.AssociateAwayLogInactiveState
+	m.UntilFurtherNotice = false
+	m.UntilDate = null.TimeFrom(now)
+	m.LastModifiedTime = now
+	m.LastModifiedById = null.IntFrom(int64(user.Id))
+	m.LastModifiedByName = null.StringFrom(user.Name)
+	m.LastModifiedFromIP = ipAddress
+	if err := h.AssociateAwayLogRepo.UpdateById(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function copies the validated request data into the away log, the associate
+// must belong to the tenant. If anything is wrong the error is written to the
+// response and `false` is returned.
+func (h *Controller) applyAssociateAwayLogIDO(w http.ResponseWriter, r *http.Request, m *models.AssociateAwayLog, data *idos.AssociateAwayLogIDO) bool {
+	ctx := r.Context()
+
+	associate, err := h.AssociateRepo.GetById(ctx, data.AssociateId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if associate == nil || associate.TenantId != m.TenantId {
+		http.Error(w, `{"associate_id":"associate does not exist"}`, http.StatusBadRequest)
+		return false
+	}
+
+	untilDate := data.UntilDate
+	if data.UntilFurtherNotice {
+		untilDate = null.Time{}
+	}
+	m.AssociateId = associate.Id
+	m.AssociateName = associate.Name
+	m.AssociateLexicalName = associate.LexicalName
+	m.Reason = data.Reason
+	m.ReasonOther = null.String{}
+	if data.Reason == models.AssociateAwayLogOtherReason {
+		m.ReasonOther = null.StringFrom(strings.TrimSpace(data.ReasonOther.ValueOrZero()))
+	}
+	m.UntilFurtherNotice = data.UntilFurtherNotice
+	m.UntilDate = untilDate
+	m.StartDate = data.StartDate
+	return true
+}
+
+// Function saves the away log unless the associate has another active away
+// log for the same period. If anything is wrong the error is written to the
+// response and `false` is returned.
+func (h *Controller) saveAssociateAwayLog(w http.ResponseWriter, r *http.Request, m *models.AssociateAwayLog) bool {
+	err := h.AssociateAwayLogRepo.SaveIfNotOverlapping(r.Context(), m)
+	if err == models.ErrAssociateAwayLogOverlaps {
+		http.Error(w, `{"start_date":"overlaps with another away log of the associate"}`, http.StatusBadRequest)
+		return false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// Function writes the away log along with the open work orders and pending
+// tasks of the associate which need to be reassigned.
+func (h *Controller) writeAssociateAwayLog(w http.ResponseWriter, r *http.Request, m *models.AssociateAwayLog) {
+	ctx := r.Context()
+
+	var workOrders []*models.LiteWorkOrder
+	var taskItems []*models.LiteTaskItem
+	if m.State == models.AssociateAwayLogActiveState {
+		var err error
+		workOrders, err = h.LiteWorkOrderRepo.ListByFilter(ctx, &models.LiteWorkOrderFilter{
+			TenantId:    m.TenantId,
+			AssociateId: null.IntFrom(int64(m.AssociateId)),
+			States: []int8{
+				models.WorkOrderPendingState,
+				models.WorkOrderOngoingState,
+				models.WorkOrderInProgressState,
+			},
+			SortField: "start_date",
+			SortOrder: "ASC",
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		taskItems, err = h.LiteTaskItemRepo.ListByFilter(ctx, &models.LiteTaskItemFilter{
+			TenantId:    m.TenantId,
+			AssociateId: null.IntFrom(int64(m.AssociateId)),
+			States:      []int8{models.TaskActiveState},
+			IsClosed:    null.BoolFrom(false),
+			SortField:   "due_date",
+			SortOrder:   "ASC",
+			Limit:       500,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	res := idos.NewAssociateAwayLogResponseIDO(m, workOrders, taskItems)
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function returns the away log of the tenant if the user is staff, otherwise
+// the error is written to the response and `false` is returned.
+func (h *Controller) getAssociateAwayLogForStaff(w http.ResponseWriter, r *http.Request, idStr string) (*models.AssociateAwayLog, bool) {
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+
+	// Permission handling - Only staff can manage the away logs.
+	if roleId != 1 && roleId != 2 && roleId != 3 {
+		http.Error(w, "Forbidden - You are not staff", http.StatusForbidden)
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	m, err := h.AssociateAwayLogRepo.GetById(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if m == nil || m.TenantId != tenantId {
+		http.Error(w, "Away log does not exist", http.StatusNotFound)
+		return nil, false
+	}
+	return m, true
+}
@@ -0,0 +1,250 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/utils"
+)
+
+const (
+	// The longest period the calendar of an associate can be listed for.
+	associateCalendarMaxDays = 366
+
+	// The period of the calendar feed around the current day.
+	associateCalendarFeedPastDays   = 30
+	associateCalendarFeedFutureDays = 180
+)
+
+func (h *Controller) associateCalendarEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	ctx := r.Context()
+
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	// Extract the period from the URL, the dates are in the timezone of the
+	// tenant and the `to` date is inclusive. By default the next 30 days are
+	// returned.
+	loc := h.getTenantLocation(ctx, a.TenantId)
+	e := make(map[string]string)
+	from := parseDateFormValue(r, "from", loc, false, e)
+	to := parseDateFormValue(r, "to", loc, true, e)
+	if !from.Valid {
+		from = null.TimeFrom(startOfDay(time.Now(), loc))
+	}
+	if !to.Valid {
+		to = null.TimeFrom(from.Time.AddDate(0, 0, 30))
+	}
+	if len(e) == 0 {
+		if !to.Time.After(from.Time) {
+			e["to"] = "must not be before the from date"
+		} else if to.Time.After(from.Time.AddDate(0, 0, associateCalendarMaxDays)) {
+			e["to"] = "period is longer than 366 days"
+		}
+	}
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, string(b), http.StatusBadRequest)
+		return
+	}
+
+	c, err := h.getAssociateCalendar(ctx, a.Id, loc, from.Time, to.Time)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(&c); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function creates the token of the calendar feed of the associate, the
+// previous token of the associate stops working.
+func (h *Controller) associateCalendarTokenCreateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	ctx := r.Context()
+	user := ctx.Value("user").(*models.User)
+
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	token := hex.EncodeToString(b)
+
+	m := &models.AssociateCalendarToken{
+		TenantId:      a.TenantId,
+		AssociateId:   a.Id,
+		TokenHash:     hashAssociateCalendarToken(token),
+		CreatedTime:   time.Now(),
+		CreatedById:   null.IntFrom(int64(user.Id)),
+		CreatedByName: null.StringFrom(user.Name),
+	}
+	if err := h.AssociateCalendarRepo.InsertOrUpdateTokenByAssociateId(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := idos.NewAssociateCalendarTokenResponseIDO(m, token)
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) associateCalendarTokenDeleteEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	ctx := r.Context()
+
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	if err := h.AssociateCalendarRepo.DeleteTokenByAssociateId(ctx, a.Id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Function returns the calendar of the associate as an iCalendar feed, the
+// feed is not protected by the login but by the token in the URL so calendar
+// applications can subscribe to it.
+func (h *Controller) associateCalendarFeedEndpoint(w http.ResponseWriter, r *http.Request, fileName string) {
+	ctx := r.Context()
+
+	token := strings.TrimSuffix(fileName, ".ics")
+	m, err := h.AssociateCalendarRepo.GetTokenByTokenHash(ctx, hashAssociateCalendarToken(token))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if m == nil {
+		http.Error(w, "Calendar does not exist", http.StatusNotFound)
+		return
+	}
+	a, err := h.AssociateRepo.GetById(ctx, m.AssociateId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if a == nil || a.TenantId != m.TenantId { // Defensive code
+		http.Error(w, "Calendar does not exist", http.StatusNotFound)
+		return
+	}
+
+	now := time.Now()
+	loc := h.getTenantLocation(ctx, a.TenantId)
+	today := startOfDay(now, loc)
+	from := today.AddDate(0, 0, -associateCalendarFeedPastDays)
+	to := today.AddDate(0, 0, associateCalendarFeedFutureDays)
+	c, err := h.getAssociateCalendar(ctx, a.Id, loc, from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Every event is shown as an all day event, the away periods until
+	// further notice are shown until the end of the feed.
+	cal := utils.NewICalendar("Workery - " + a.Name)
+	for _, e := range c.Events {
+		start := startOfDay(e.StartDate, loc)
+		end := start.AddDate(0, 0, 1)
+		if e.TypeOf == models.AssociateCalendarAwayEventTypeOf {
+			end = to
+			if e.EndDate.Valid {
+				end = startOfDay(e.EndDate.Time, loc).AddDate(0, 0, 1)
+			}
+		}
+		cal.AddEvent(&utils.ICalendarEvent{
+			Uid:         e.Uid + "@workery",
+			Summary:     e.Title,
+			Description: e.Description,
+			Start:       start,
+			End:         end,
+			AllDay:      true,
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", "inline; filename=\"calendar.ics\"")
+	w.Write(cal.Bytes(now))
+}
+
+// Function returns the calendar of the associate between `from` (inclusive)
+// and `to` (exclusive) along with the visits of the ongoing work orders which
+// were not created as jobs yet.
+func (h *Controller) getAssociateCalendar(ctx context.Context, associateId uint64, loc *time.Location, from time.Time, to time.Time) (*models.AssociateCalendar, error) {
+	events, err := h.AssociateCalendarRepo.ListEventsByAssociateId(ctx, associateId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	c := &models.AssociateCalendar{
+		AssociateId: associateId,
+		From:        from,
+		To:          to,
+		Events:      events,
+	}
+
+	ids, err := h.OngoingWorkOrderRepo.ListIdsByAssociateId(ctx, associateId)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		m, err := h.OngoingWorkOrderRepo.GetById(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			c.AddOngoingWorkOrder(m, loc)
+		}
+	}
+
+	c.Sort()
+	if c.Events == nil { // Always return an array and not `null`.
+		c.Events = []*models.AssociateCalendarEvent{}
+	}
+	return c, nil
+}
+
+// Function returns the timezone of the tenant or UTC if it cannot be loaded.
+func (h *Controller) getTenantLocation(ctx context.Context, tenantId uint64) *time.Location {
+	tenant, err := h.TenantRepo.GetById(ctx, tenantId)
+	if err != nil || tenant == nil {
+		return time.UTC
+	}
+	loc, err := utils.GetTimezoneLocation(tenant.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+func hashAssociateCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
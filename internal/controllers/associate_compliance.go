@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/validators"
+)
+
+// Function returns the compliance of the active associates of the tenant,
+// the optional `status` parameter only returns the associates with at least
+// that status, ex: `status=2` returns the expiring and lapsed associates.
+func (h *Controller) associatesComplianceListEndpoint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+
+	// Permission handling - Only staff can view the compliance of associates.
+	if roleId != 1 && roleId != 2 && roleId != 3 {
+		http.Error(w, "Forbidden - You are not staff", http.StatusForbidden)
+		return
+	}
+
+	days, ok := parseComplianceDays(w, r)
+	if !ok {
+		return
+	}
+	status, _ := strconv.ParseInt(r.FormValue("status"), 10, 8)
+
+	arr, err := h.AssociateComplianceRepo.ListByTenantId(ctx, tenantId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	now := time.Now()
+	var res []*models.AssociateCompliance
+	for _, m := range arr {
+		m.ComputeStatus(now, days)
+		if m.Status >= int8(status) {
+			res = append(res, m)
+		}
+	}
+
+	ido := idos.NewAssociateComplianceListResponseIDO(days, res)
+	if err := json.NewEncoder(w).Encode(&ido); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) associateComplianceEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	ctx := r.Context()
+
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	days, ok := parseComplianceDays(w, r)
+	if !ok {
+		return
+	}
+
+	m, err := h.AssociateComplianceRepo.GetByAssociateId(ctx, a.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	m.AssociateName = a.Name
+	m.AssociateLexicalName = a.LexicalName
+	m.ComputeStatus(time.Now(), days)
+
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) insuranceRequirementUpdateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	role_id := uint64(ctx.Value("user_role_id").(int8))
+
+	// Permission handling - If use is not administrator then error.
+	if role_id != 1 {
+		http.Error(w, "Forbidden - You are not an administrator", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m, err := h.InsuranceRequirementRepo.GetById(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if m == nil || m.TenantId != tenantId {
+		http.Error(w, "Insurance requirement does not exist", http.StatusNotFound)
+		return
+	}
+
+	// Get the user `PUT` data from the HTTP request.
+	var putData *idos.InsuranceRequirementIDO
+	if err := json.NewDecoder(r.Body).Decode(&putData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateInsuranceRequirementSaveFromRequest(putData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	m.Text = strings.TrimSpace(putData.Text)
+	m.Description = putData.Description
+	m.State = putData.State
+	m.AssociateDateTypeOf = putData.AssociateDateTypeOf
+	if err := h.InsuranceRequirementRepo.UpdateById(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function returns the number of days before the expiry date a requirement is
+// reported as expiring, otherwise the error is written to the response and
+// `false` is returned.
+func parseComplianceDays(w http.ResponseWriter, r *http.Request) (int, bool) {
+	s := r.FormValue("days")
+	if s == "" {
+		return models.AssociateComplianceDefaultExpiringDays, true
+	}
+	days, err := strconv.Atoi(s)
+	if err != nil || days < 0 || days > 365 {
+		http.Error(w, `{"days":"must be between 0 and 365"}`, http.StatusBadRequest)
+		return 0, false
+	}
+	return days, true
+}
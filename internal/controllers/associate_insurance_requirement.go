@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+)
+
+func (h *Controller) associateInsuranceRequirementsListEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	ctx := r.Context()
+
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	arr, err := h.AssociateInsuranceRequirementRepo.ListByAssociateId(ctx, a.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if arr == nil { // Always return an array and not `null`.
+		arr = []*models.AssociateInsuranceRequirement{}
+	}
+
+	if err := json.NewEncoder(w).Encode(&arr); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) associateInsuranceRequirementCreateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	var postData *idos.AssociateInsuranceRequirementIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ref, err := h.InsuranceRequirementRepo.GetById(ctx, postData.InsuranceRequirementId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if ref == nil || ref.TenantId != a.TenantId {
+		http.Error(w, `{"insurance_requirement_id":"insurance requirement does not exist"}`, http.StatusBadRequest)
+		return
+	}
+	existing, err := h.AssociateInsuranceRequirementRepo.GetByAssociateIdAndInsuranceRequirementId(ctx, a.Id, ref.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if existing != nil {
+		http.Error(w, `{"insurance_requirement_id":"insurance requirement is already assigned to the associate"}`, http.StatusBadRequest)
+		return
+	}
+
+	m := &models.AssociateInsuranceRequirement{
+		Uuid:                   uuid.NewString(),
+		TenantId:               a.TenantId,
+		AssociateId:            a.Id,
+		InsuranceRequirementId: ref.Id,
+	}
+	if err := h.AssociateInsuranceRequirementRepo.Insert(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	m, err = h.AssociateInsuranceRequirementRepo.GetByAssociateIdAndInsuranceRequirementId(ctx, a.Id, ref.Id) // Lookup the ID.
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	m.Text = ref.Text
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) associateInsuranceRequirementDeleteEndpoint(w http.ResponseWriter, r *http.Request, idStr string, insuranceRequirementIdStr string) {
+	ctx := r.Context()
+
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	insuranceRequirementId, err := strconv.ParseUint(insuranceRequirementIdStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m, err := h.AssociateInsuranceRequirementRepo.GetByAssociateIdAndInsuranceRequirementId(ctx, a.Id, insuranceRequirementId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if m == nil {
+		http.Error(w, "Associate insurance requirement does not exist", http.StatusNotFound)
+		return
+	}
+	if err := h.AssociateInsuranceRequirementRepo.DeleteById(ctx, m.Id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/utils"
+	"github.com/over55/workery-server/internal/validators"
+)
+
+// Function returns what every associate of the tenant owes us in service
+// fees.
+func (h *Controller) associateBalancesListEndpoint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+
+	// Permission handling - Only staff can view the receivables.
+	if roleId != 1 && roleId != 2 && roleId != 3 {
+		http.Error(w, "Forbidden - You are not staff", http.StatusForbidden)
+		return
+	}
+
+	arr, err := h.AssociateLedgerRepo.ListBalancesByTenantId(ctx, tenantId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := idos.NewAssociateBalanceListResponseIDO(arr)
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function returns the service fee statement of the associate. The optional
+// `from` and `to` parameters are inclusive dates, ex: `2021-01-31`, in the
+// timezone of the tenant.
+func (h *Controller) associateStatementEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	h.writeAssociateStatement(w, r, a)
+}
+
+func (h *Controller) writeAssociateStatement(w http.ResponseWriter, r *http.Request, a *models.Associate) {
+	ctx := r.Context()
+	tenant, err := h.TenantRepo.GetById(ctx, a.TenantId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	loc, err := utils.GetTimezoneLocation(tenant.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	// The ledger is stored in UTC and the `to` date is inclusive.
+	e := make(map[string]string)
+	from := parseDateFormValue(r, "from", loc, false, e)
+	to := parseDateFormValue(r, "to", loc, true, e)
+	if len(e) != 0 {
+		b, _ := json.Marshal(e)
+		http.Error(w, string(b), http.StatusBadRequest)
+		return
+	}
+
+	var openingBalance models.Money
+	if from.Valid {
+		openingBalance, err = h.AssociateLedgerRepo.GetBalanceByAssociateId(ctx, a.Id, from.Time)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	arr, err := h.AssociateLedgerRepo.ListByAssociateId(ctx, a.Id, from.Time, to.Time)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := idos.NewAssociateStatementResponseIDO(a, from, to, openingBalance, arr)
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function records the payment of the service fees of several work orders
+// of the associate at once, every work order is settled in full.
+func (h *Controller) associateServiceFeePaymentCreateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	// Get the user `POST` data from the HTTP request.
+	var postData *idos.AssociateServiceFeePaymentIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	arr := make([]*models.WorkOrder, len(postData.OrderIds))
+	for i, orderId := range postData.OrderIds {
+		m, err := h.WorkOrderRepo.GetById(ctx, orderId)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		arr[i] = m
+	}
+	isValid, errStr := validators.ValidateAssociateServiceFeePaymentFromRequest(postData, a, arr)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	res := &idos.AssociateServiceFeePaymentResponseIDO{
+		AssociateId: a.Id,
+		OrderIds:    postData.OrderIds,
+	}
+	now := time.Now()
+	for _, m := range arr {
+		res.AmountPaid += m.InvoiceServiceFeeAmount - m.InvoiceActualServiceFeeAmountPaid
+		m.InvoiceActualServiceFeeAmountPaid = m.InvoiceServiceFeeAmount
+		m.InvoiceServiceFeePaymentDate = postData.PaidAt
+		m.LastModifiedTime = now
+		m.LastModifiedById = null.IntFrom(int64(user.Id))
+		m.LastModifiedByName = null.StringFrom(user.Name)
+		m.LastModifiedFromIP = null.NewString(ipAddress, ipAddress != "")
+	}
+	if err := h.WorkOrderRepo.UpdateServiceFeePayments(ctx, arr); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function will lookup the associate in the URL and return false after
+// writing the error response if the user is not staff of the tenant or the
+// associate does not exist.
+func (h *Controller) getAssociateForStaff(w http.ResponseWriter, r *http.Request, idStr string) (*models.Associate, bool) {
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+
+	// Permission handling - Only staff can view the finances of associates.
+	if roleId != 1 && roleId != 2 && roleId != 3 {
+		http.Error(w, "Forbidden - You are not staff", http.StatusForbidden)
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	a, err := h.AssociateRepo.GetById(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if a == nil || a.TenantId != tenantId {
+		http.Error(w, "Associate does not exist", http.StatusNotFound)
+		return nil, false
+	}
+	return a, true
+}
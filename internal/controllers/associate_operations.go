@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/validators"
+)
+
+// Function upgrades the residential associate to a commercial associate of
+// the organization.
+func (h *Controller) associateUpgradeEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	m, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	if m.TypeOf == models.AssociateCommercialTypeOf {
+		http.Error(w, `{"type_of":"associate is already commercial"}`, http.StatusBadRequest)
+		return
+	}
+
+	var postData *idos.AssociateUpgradeIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateAssociateUpgradeFromRequest(postData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	m.TypeOf = models.AssociateCommercialTypeOf
+	m.OrganizationName = strings.TrimSpace(postData.OrganizationName)
+	m.OrganizationTypeOf = postData.OrganizationTypeOf
+	compileAssociateValues(m)
+	setAssociateLastModifiedBy(r.Context(), m)
+	h.saveAssociate(w, r, m)
+}
+
+// Function downgrades the commercial associate to a residential associate,
+// the organization of the associate is removed.
+func (h *Controller) associateDowngradeEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	m, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	if m.TypeOf != models.AssociateCommercialTypeOf {
+		http.Error(w, `{"type_of":"associate is not commercial"}`, http.StatusBadRequest)
+		return
+	}
+
+	m.TypeOf = models.AssociateResidentialTypeOf
+	m.OrganizationName = ""
+	m.OrganizationTypeOf = 0
+	compileAssociateValues(m)
+	setAssociateLastModifiedBy(r.Context(), m)
+	h.saveAssociate(w, r, m)
+}
+
+// Function archives the associate with the reason, the associate no longer
+// gets listed and the user account of the associate gets deactivated.
+func (h *Controller) associateArchiveEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+
+	m, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	if m.State != models.AssociateActiveState {
+		http.Error(w, `{"state":"associate is already archived"}`, http.StatusBadRequest)
+		return
+	}
+
+	var postData *idos.AssociateArchiveIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateAssociateArchiveFromRequest(postData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.UserRepo.GetById(ctx, m.UserId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if user == nil { // Defensive code
+		http.Error(w, "Associate user account does not exist", http.StatusInternalServerError)
+		return
+	}
+
+	m.State = models.AssociateInactiveState
+	m.DeactivationReason = postData.DeactivationReason
+	m.DeactivationReasonOther = strings.TrimSpace(postData.DeactivationReasonOther)
+	user.State = 0 // Inactive
+	h.saveAssociateWithUser(w, r, m, user)
+}
@@ -0,0 +1,457 @@
+package controllers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/utils"
+	"github.com/over55/workery-server/internal/validators"
+)
+
+const maxAssociatePhotoSize = 10 << 20 // 10MB
+
+// The content types of the photos an associate can upload for a job.
+var associatePhotoContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// The fields the work orders of the associate can be sorted by, the sort
+// field is written into the query so only these values are allowed.
+var associateWorkOrderSortFields = map[string]bool{
+	"assignment_date": true,
+	"start_date":      true,
+	"state":           true,
+	"id":              true,
+}
+
+// Function returns the work orders assigned to the logged in associate, the
+// optional `state` parameter filters the results.
+func (h *Controller) myWorkOrdersListEndpoint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	a, ok := h.getAssociateForUser(w, r)
+	if !ok {
+		return
+	}
+
+	// Extract our parameters from the URL.
+	offsetParamString := r.FormValue("offset")
+	offsetParam, _ := strconv.ParseUint(offsetParamString, 10, 64)
+	limitParamString := r.FormValue("limit")
+	limitParam, _ := strconv.ParseUint(limitParamString, 10, 64)
+	if limitParam == 0 || limitParam > 500 {
+		limitParam = 25
+	}
+	sortOrderString := strings.ToUpper(r.FormValue("sort_order"))
+	if sortOrderString != "ASC" {
+		sortOrderString = "DESC"
+	}
+	sortFieldString := r.FormValue("sort_field")
+	if !associateWorkOrderSortFields[sortFieldString] {
+		sortFieldString = "assignment_date"
+	}
+	var states []int8
+	if state, err := strconv.ParseInt(r.FormValue("state"), 10, 8); err == nil {
+		states = append(states, int8(state))
+	}
+
+	// The associate of the filter must always be set so the associate only
+	// ever sees their own work orders.
+	f := models.LiteWorkOrderFilter{
+		TenantId:    a.TenantId,
+		AssociateId: null.IntFrom(int64(a.Id)),
+		SortField:   sortFieldString,
+		SortOrder:   sortOrderString,
+		States:      states,
+		Offset:      offsetParam,
+		Limit:       limitParam,
+	}
+
+	arrCh := make(chan []*models.LiteWorkOrder)
+	countCh := make(chan uint64)
+
+	go func() {
+		arr, err := h.LiteWorkOrderRepo.ListByFilter(ctx, &f)
+		if err != nil {
+			log.Println("WARNING: myWorkOrdersListEndpoint|ListByFilter|err:", err.Error())
+			arrCh <- nil
+			return
+		}
+		arrCh <- arr[:]
+	}()
+
+	go func() {
+		count, err := h.LiteWorkOrderRepo.CountByFilter(ctx, &f)
+		if err != nil {
+			log.Println("WARNING: myWorkOrdersListEndpoint|CountByFilter|err:", err.Error())
+			countCh <- 0
+			return
+		}
+		countCh <- count
+	}()
+
+	arr, count := <-arrCh, <-countCh
+
+	res := idos.NewLiteWorkOrderListResponseIDO(arr, count)
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) myWorkOrderGetEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	a, ok := h.getAssociateForUser(w, r)
+	if !ok {
+		return
+	}
+	order, ok := h.getWorkOrderForAssociate(w, r, a, idStr)
+	if !ok {
+		return
+	}
+	h.writeAssociateWorkOrder(w, r, order)
+}
+
+// Function saves the hours and the number of visits the associate worked on
+// the job, the job itself gets closed by staff through the order completion
+// task.
+func (h *Controller) myWorkOrderCompletionEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	a, ok := h.getAssociateForUser(w, r)
+	if !ok {
+		return
+	}
+	order, ok := h.getWorkOrderForAssociate(w, r, a, idStr)
+	if !ok {
+		return
+	}
+	if order.State != models.WorkOrderInProgressState && order.State != models.WorkOrderOngoingState {
+		http.Error(w, "Work order is not in progress", http.StatusConflict)
+		return
+	}
+
+	var putData *idos.AssociateWorkOrderCompletionIDO
+	if err := json.NewDecoder(r.Body).Decode(&putData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateAssociateWorkOrderCompletionFromRequest(putData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+	if putData.CompletionDate.Valid && putData.CompletionDate.Time.Before(order.StartDate) {
+		http.Error(w, `{"completion_date":"must not be before the start date"}`, http.StatusBadRequest)
+		return
+	}
+
+	order.Hours = putData.Hours
+	order.Visits = putData.Visits
+	order.CompletionDate = putData.CompletionDate
+	if !order.CompletionDate.Valid {
+		order.CompletionDate = null.TimeFrom(time.Now())
+	}
+	h.markWorkOrderModified(r, order)
+	if err := h.WorkOrderRepo.UpdateAssociateCompletionById(ctx, order); err != nil {
+		if err == models.ErrWorkOrderNotInProgress {
+			http.Error(w, "Work order is not in progress", http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The work order is read again since only the completion was saved.
+	order, err := h.WorkOrderRepo.GetById(ctx, order.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if order == nil {
+		http.Error(w, "Work order does not exist", http.StatusNotFound)
+		return
+	}
+	h.writeAssociateWorkOrder(w, r, order)
+}
+
+// Function uploads the `file` photo of the job, the photo is kept as a
+// private file of the work order.
+func (h *Controller) myWorkOrderPhotoCreateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+	a, ok := h.getAssociateForUser(w, r)
+	if !ok {
+		return
+	}
+	order, ok := h.getWorkOrderForAssociate(w, r, a, idStr)
+	if !ok {
+		return
+	}
+	if h.S3Client == nil {
+		http.Error(w, "File storage is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAssociatePhotoSize)
+	if err := r.ParseMultipartForm(maxAssociatePhotoSize); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "missing photo `file`", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	bin, err := ioutil.ReadAll(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !associatePhotoContentTypes[http.DetectContentType(bin)] {
+		http.Error(w, `{"file":"must be a jpeg, png, gif or webp image"}`, http.StatusBadRequest)
+		return
+	}
+
+	// Every photo is kept so the key is made unique with the UUID.
+	fileUuid := uuid.NewString()
+	filename := filepath.Base(header.Filename)
+	s3Key := "tenant/" + strconv.FormatUint(order.TenantId, 10) + "/private/uploads/" + fileUuid + "-" + filename
+	if err := utils.UploadBinToS3(h.S3Client, h.S3BucketName, s3Key, string(bin), "private"); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	m := &models.PrivateFile{
+		Uuid:               fileUuid,
+		TenantId:           order.TenantId,
+		S3Key:              s3Key,
+		Title:              filename,
+		Description:        "Job photo for work order #" + strconv.FormatUint(order.Id, 10),
+		CreatedTime:        now,
+		CreatedFromIP:      null.StringFrom(ipAddress),
+		CreatedById:        null.IntFrom(int64(user.Id)),
+		CreatedByName:      null.StringFrom(user.Name),
+		LastModifiedTime:   now,
+		LastModifiedById:   null.IntFrom(int64(user.Id)),
+		LastModifiedByName: null.StringFrom(user.Name),
+		LastModifiedFromIP: null.StringFrom(ipAddress),
+		AssociateId:        null.IntFrom(int64(a.Id)),
+		WorkOrderId:        null.IntFrom(int64(order.Id)),
+		State:              1,
+	}
+	if err := h.PrivateFileRepo.Insert(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function returns the jobs offered to the logged in associate which are
+// still waiting on their response.
+func (h *Controller) myOffersListEndpoint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	a, ok := h.getAssociateForUser(w, r)
+	if !ok {
+		return
+	}
+
+	f := &models.ActivitySheetItemFilter{
+		TenantId:    a.TenantId,
+		States:      []int8{models.ActivitySheetItemPendingState},
+		SortField:   "created_time",
+		SortOrder:   "DESC",
+		AssociateId: null.IntFrom(int64(a.Id)),
+		Limit:       500,
+	}
+	arr, err := h.ActivitySheetItemRepo.ListByFilter(ctx, f)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := idos.NewActivitySheetItemListResponseIDO(arr, uint64(len(arr)))
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) myOfferAcceptEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	h.respondToMyOffer(w, r, idStr, models.ActivitySheetItemAcceptedState)
+}
+
+func (h *Controller) myOfferDeclineEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	h.respondToMyOffer(w, r, idStr, models.ActivitySheetItemDeclinedState)
+}
+
+// Function saves the response of the logged in associate to the job offered
+// to them, staff still assigns the job through the assign associate task.
+func (h *Controller) respondToMyOffer(w http.ResponseWriter, r *http.Request, idStr string, state int8) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	a, ok := h.getAssociateForUser(w, r)
+	if !ok {
+		return
+	}
+
+	var postData *idos.AssociateOfferResponseIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateAssociateOfferResponseFromRequest(postData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m, err := h.ActivitySheetItemRepo.GetById(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if m == nil || m.TenantId != a.TenantId || m.AssociateId != a.Id {
+		http.Error(w, "Offer does not exist", http.StatusNotFound)
+		return
+	}
+	if m.State != models.ActivitySheetItemPendingState {
+		http.Error(w, "Offer was already answered", http.StatusConflict)
+		return
+	}
+
+	// The job could have been assigned to someone else or closed since it
+	// was offered.
+	if m.OrderId.Valid {
+		order, err := h.WorkOrderRepo.GetById(ctx, uint64(m.OrderId.Int64))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if order == nil || order.AssociateId.Valid || order.State == models.WorkOrderCancelledState || order.State == models.WorkOrderArchivedState {
+			http.Error(w, "Job is no longer available", http.StatusConflict)
+			return
+		}
+	}
+
+	m.State = state
+	if comment := strings.TrimSpace(postData.Comment); comment != "" {
+		m.Comment = comment
+	}
+	if err := h.ActivitySheetItemRepo.UpdateById(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function returns the service fee statement of the logged in associate. The
+// optional `from` and `to` parameters work like the staff statement.
+func (h *Controller) myStatementEndpoint(w http.ResponseWriter, r *http.Request) {
+	a, ok := h.getAssociateForUser(w, r)
+	if !ok {
+		return
+	}
+	h.writeAssociateStatement(w, r, a)
+}
+
+// Function will lookup the associate of the logged in user and return false
+// after writing the error response if the user is not an associate.
+func (h *Controller) getAssociateForUser(w http.ResponseWriter, r *http.Request) (*models.Associate, bool) {
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+	userId := uint64(ctx.Value("user_id").(uint64))
+
+	// Permission handling - Only associates have a portal.
+	if roleId != 4 {
+		http.Error(w, "Forbidden - You are not an associate", http.StatusForbidden)
+		return nil, false
+	}
+
+	id, err := h.AssociateRepo.GetIdByUserId(ctx, userId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if id == 0 {
+		http.Error(w, "Forbidden - You are not an associate", http.StatusForbidden)
+		return nil, false
+	}
+	a, err := h.AssociateRepo.GetById(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if a == nil || a.TenantId != tenantId { // Defensive code
+		http.Error(w, "Forbidden - You are not an associate", http.StatusForbidden)
+		return nil, false
+	}
+	return a, true
+}
+
+// Function will lookup the work order in the URL and return false after
+// writing the error response if the work order is not assigned to the
+// associate, the work orders of other associates are reported as missing.
+func (h *Controller) getWorkOrderForAssociate(w http.ResponseWriter, r *http.Request, a *models.Associate, idStr string) (*models.WorkOrder, bool) {
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	order, err := h.WorkOrderRepo.GetById(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if order == nil || order.TenantId != a.TenantId || !order.AssociateId.Valid || order.AssociateId.Int64 != int64(a.Id) {
+		http.Error(w, "Work order does not exist", http.StatusNotFound)
+		return nil, false
+	}
+	return order, true
+}
+
+func (h *Controller) writeAssociateWorkOrder(w http.ResponseWriter, r *http.Request, order *models.WorkOrder) {
+	c, err := h.CustomerRepo.GetById(r.Context(), order.CustomerId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if c != nil && c.TenantId != order.TenantId { // Defensive code
+		c = nil
+	}
+
+	res := idos.NewAssociateWorkOrderIDO(order, c)
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+)
+
+func (h *Controller) associateSkillSetsListEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	ctx := r.Context()
+
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	arr, err := h.AssociateSkillSetRepo.ListByAssociateId(ctx, a.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if arr == nil { // Always return an array and not `null`.
+		arr = []*models.AssociateSkillSet{}
+	}
+
+	if err := json.NewEncoder(w).Encode(&arr); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) associateSkillSetCreateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	var postData *idos.AssociateSkillSetIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ref, err := h.SkillSetRepo.GetById(ctx, postData.SkillSetId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if ref == nil || ref.TenantId != a.TenantId {
+		http.Error(w, `{"skill_set_id":"skill set does not exist"}`, http.StatusBadRequest)
+		return
+	}
+	existing, err := h.AssociateSkillSetRepo.GetByAssociateIdAndSkillSetId(ctx, a.Id, ref.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if existing != nil {
+		http.Error(w, `{"skill_set_id":"skill set is already assigned to the associate"}`, http.StatusBadRequest)
+		return
+	}
+
+	m := &models.AssociateSkillSet{
+		Uuid:        uuid.NewString(),
+		TenantId:    a.TenantId,
+		AssociateId: a.Id,
+		SkillSetId:  ref.Id,
+	}
+	if err := h.AssociateSkillSetRepo.Insert(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	m, err = h.AssociateSkillSetRepo.GetByAssociateIdAndSkillSetId(ctx, a.Id, ref.Id) // Lookup the ID.
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	m.Category = ref.Category
+	m.SubCategory = ref.SubCategory
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) associateSkillSetDeleteEndpoint(w http.ResponseWriter, r *http.Request, idStr string, skillSetIdStr string) {
+	ctx := r.Context()
+
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	skillSetId, err := strconv.ParseUint(skillSetIdStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m, err := h.AssociateSkillSetRepo.GetByAssociateIdAndSkillSetId(ctx, a.Id, skillSetId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if m == nil {
+		http.Error(w, "Associate skill set does not exist", http.StatusNotFound)
+		return
+	}
+	if err := h.AssociateSkillSetRepo.DeleteById(ctx, m.Id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+)
+
+func (h *Controller) associateTagsListEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	ctx := r.Context()
+
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	arr, err := h.AssociateTagRepo.ListByAssociateId(ctx, a.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if arr == nil { // Always return an array and not `null`.
+		arr = []*models.AssociateTag{}
+	}
+
+	if err := json.NewEncoder(w).Encode(&arr); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) associateTagCreateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	var postData *idos.AssociateTagIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ref, err := h.TagRepo.GetById(ctx, postData.TagId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if ref == nil || ref.TenantId != a.TenantId {
+		http.Error(w, `{"tag_id":"tag does not exist"}`, http.StatusBadRequest)
+		return
+	}
+	existing, err := h.AssociateTagRepo.GetByAssociateIdAndTagId(ctx, a.Id, ref.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if existing != nil {
+		http.Error(w, `{"tag_id":"tag is already assigned to the associate"}`, http.StatusBadRequest)
+		return
+	}
+
+	m := &models.AssociateTag{
+		Uuid:        uuid.NewString(),
+		TenantId:    a.TenantId,
+		AssociateId: a.Id,
+		TagId:       ref.Id,
+	}
+	if err := h.AssociateTagRepo.Insert(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	m, err = h.AssociateTagRepo.GetByAssociateIdAndTagId(ctx, a.Id, ref.Id) // Lookup the ID.
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	m.Text = ref.Text
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) associateTagDeleteEndpoint(w http.ResponseWriter, r *http.Request, idStr string, tagIdStr string) {
+	ctx := r.Context()
+
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	tagId, err := strconv.ParseUint(tagIdStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m, err := h.AssociateTagRepo.GetByAssociateIdAndTagId(ctx, a.Id, tagId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if m == nil {
+		http.Error(w, "Associate tag does not exist", http.StatusNotFound)
+		return
+	}
+	if err := h.AssociateTagRepo.DeleteById(ctx, m.Id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
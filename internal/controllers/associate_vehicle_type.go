@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+)
+
+func (h *Controller) associateVehicleTypesListEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	ctx := r.Context()
+
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	arr, err := h.AssociateVehicleTypeRepo.ListByAssociateId(ctx, a.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if arr == nil { // Always return an array and not `null`.
+		arr = []*models.AssociateVehicleType{}
+	}
+
+	if err := json.NewEncoder(w).Encode(&arr); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) associateVehicleTypeCreateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	var postData *idos.AssociateVehicleTypeIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ref, err := h.VehicleTypeRepo.GetById(ctx, postData.VehicleTypeId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if ref == nil || ref.TenantId != a.TenantId {
+		http.Error(w, `{"vehicle_type_id":"vehicle type does not exist"}`, http.StatusBadRequest)
+		return
+	}
+	existing, err := h.AssociateVehicleTypeRepo.GetByAssociateIdAndVehicleTypeId(ctx, a.Id, ref.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if existing != nil {
+		http.Error(w, `{"vehicle_type_id":"vehicle type is already assigned to the associate"}`, http.StatusBadRequest)
+		return
+	}
+
+	m := &models.AssociateVehicleType{
+		Uuid:          uuid.NewString(),
+		TenantId:      a.TenantId,
+		AssociateId:   a.Id,
+		VehicleTypeId: ref.Id,
+	}
+	if err := h.AssociateVehicleTypeRepo.Insert(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	m, err = h.AssociateVehicleTypeRepo.GetByAssociateIdAndVehicleTypeId(ctx, a.Id, ref.Id) // Lookup the ID.
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	m.Text = ref.Text
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) associateVehicleTypeDeleteEndpoint(w http.ResponseWriter, r *http.Request, idStr string, vehicleTypeIdStr string) {
+	ctx := r.Context()
+
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	vehicleTypeId, err := strconv.ParseUint(vehicleTypeIdStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m, err := h.AssociateVehicleTypeRepo.GetByAssociateIdAndVehicleTypeId(ctx, a.Id, vehicleTypeId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if m == nil {
+		http.Error(w, "Associate vehicle type does not exist", http.StatusNotFound)
+		return
+	}
+	if err := h.AssociateVehicleTypeRepo.DeleteById(ctx, m.Id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
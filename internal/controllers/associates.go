@@ -62,6 +62,19 @@ func (h *Controller) associatesListEndpoint(w http.ResponseWriter, r *http.Reque
 	// log.Println("SortOrder", f.SortOrder)
 	// log.Println("SortField", f.SortField)
 
+	// If the user requested an export then stream every row matching our
+	// filter into a spreadsheet instead of returning a single page.
+	if r.FormValue("export") != "" {
+		f.Offset = 0
+		f.Limit = 0
+		h.exportSpreadsheet(w, r, "associates", idos.LiteAssociateExportColumns, func(write func(row map[string]string) error) error {
+			return h.LiteAssociateRepo.StreamByFilter(ctx, &f, func(m *models.LiteAssociate) error {
+				return write(idos.NewLiteAssociateExportRow(m))
+			})
+		})
+		return
+	}
+
 	arrCh := make(chan []*models.LiteAssociate)
 	countCh := make(chan uint64)
 
@@ -3,17 +3,24 @@ package controllers
 import (
 	"net/http"
 
+	"github.com/aws/aws-sdk-go/service/s3"
+
 	// "github.com/over55/workery-server/internal/repositories"
+	"github.com/over55/workery-server/internal/importers"
 	"github.com/over55/workery-server/internal/models"
 	"github.com/over55/workery-server/internal/session"
 )
 
 type Controller struct {
 	SecretSigningKeyBin               []byte
+	AccountingExportRepo              models.AccountingExportRepository
 	ActivitySheetItemRepo             models.ActivitySheetItemRepository
 	AssociateAwayLogRepo              models.AssociateAwayLogRepository
+	AssociateCalendarRepo             models.AssociateCalendarRepository
 	AssociateCommentRepo              models.AssociateCommentRepository
+	AssociateComplianceRepo           models.AssociateComplianceRepository
 	AssociateInsuranceRequirementRepo models.AssociateInsuranceRequirementRepository
+	AssociateLedgerRepo               models.AssociateLedgerRepository
 	AssociateSkillSetRepo             models.AssociateSkillSetRepository
 	AssociateTagRepo                  models.AssociateTagRepository
 	AssociateVehicleTypeRepo          models.AssociateVehicleTypeRepository
@@ -56,16 +63,26 @@ type Controller struct {
 	TagRepo                           models.TagRepository
 	TaskItemRepo                      models.TaskItemRepository
 	TenantRepo                        models.TenantRepository
+	TenantInvoiceTemplateRepo         models.TenantInvoiceTemplateRepository
+	TenantAccountMappingRepo          models.TenantAccountMappingRepository
+	TenantTaxRuleRepo                 models.TenantTaxRuleRepository
+	TenantTaskSettingRepo             models.TenantTaskSettingRepository
+	WorkOrderInvoiceRevisionRepo      models.WorkOrderInvoiceRevisionRepository
 	UserRepo                          models.UserRepository
 	VehicleTypeRepo                   models.VehicleTypeRepository
 	WorkOrderCommentRepo              models.WorkOrderCommentRepository
 	WorkOrderDepositRepo              models.WorkOrderDepositRepository
 	WorkOrderInvoiceRepo              models.WorkOrderInvoiceRepository
+	WorkOrderInvoiceLineItemRepo      models.WorkOrderInvoiceLineItemRepository
 	WorkOrderServiceFeeRepo           models.WorkOrderServiceFeeRepository
 	WorkOrderSkillSetRepo             models.WorkOrderSkillSetRepository
+	WorkOrderSurveyRepo               models.WorkOrderSurveyRepository
 	WorkOrderTagRepo                  models.WorkOrderTagRepository
 	WorkOrderRepo                     models.WorkOrderRepository
 	SessionManager                    *session.SessionManager
+	S3Client                          *s3.S3
+	S3BucketName                      string
+	Importer                          *importers.Importer
 }
 
 func (h *Controller) HandleRequests(w http.ResponseWriter, r *http.Request) {
@@ -76,6 +93,24 @@ func (h *Controller) HandleRequests(w http.ResponseWriter, r *http.Request) {
 	p := ctx.Value("url_split").([]string)
 	n := len(p)
 
+	// Every endpoint outside of the associate portal and the endpoints which
+	// are shared by all users is built for staff and exposes the records of
+	// the whole tenant so the other users are limited to the following.
+	sharedPath := map[string]bool{
+		"my":            true,
+		"login":         true,
+		"profile":       true,
+		"dashboard":     true,
+		"navigation":    true,
+		"calendar-feed": true,
+	}
+	if roleId, ok := ctx.Value("user_role_id").(int8); ok && roleId != 1 && roleId != 2 && roleId != 3 {
+		if n < 2 || p[0] != "v1" || !sharedPath[p[1]] {
+			http.Error(w, "Forbidden - You are not staff", http.StatusForbidden)
+			return
+		}
+	}
+
 	switch {
 	// --- TENANTS ---
 	case n == 2 && p[0] == "v1" && p[1] == "tenants" && r.Method == http.MethodGet:
@@ -88,6 +123,26 @@ func (h *Controller) HandleRequests(w http.ResponseWriter, r *http.Request) {
 		h.tenantGetEndpoint(w, r, p[2])
 	case n == 3 && p[0] == "v1" && p[1] == "franchise" && r.Method == http.MethodPut:
 		h.tenantUpdateEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "franchise" && p[3] == "invoice-template" && r.Method == http.MethodGet:
+		h.tenantInvoiceTemplateGetEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "franchise" && p[3] == "invoice-template" && r.Method == http.MethodPut:
+		h.tenantInvoiceTemplateUpdateEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "franchise" && p[3] == "task-settings" && r.Method == http.MethodGet:
+		h.tenantTaskSettingGetEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "franchise" && p[3] == "task-settings" && r.Method == http.MethodPut:
+		h.tenantTaskSettingUpdateEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "franchise" && p[3] == "account-mappings" && r.Method == http.MethodGet:
+		h.tenantAccountMappingGetEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "franchise" && p[3] == "account-mappings" && r.Method == http.MethodPut:
+		h.tenantAccountMappingUpdateEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "franchise" && p[3] == "tax-rules" && r.Method == http.MethodGet:
+		h.tenantTaxRulesListEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "franchise" && p[3] == "tax-rules" && r.Method == http.MethodPost:
+		h.tenantTaxRuleCreateEndpoint(w, r, p[2])
+	case n == 5 && p[0] == "v1" && p[1] == "franchise" && p[3] == "tax-rule" && r.Method == http.MethodPut:
+		h.tenantTaxRuleUpdateEndpoint(w, r, p[2], p[4])
+	case n == 5 && p[0] == "v1" && p[1] == "franchise" && p[3] == "tax-rule" && r.Method == http.MethodDelete:
+		h.tenantTaxRuleDeleteEndpoint(w, r, p[2], p[4])
 	// case n == 3 && p[0] == "v1" && p[1] == "tenant" && r.Method == http.MethodDelete:
 	// 	h.deleteTenantById(w, r, p[2])
 
@@ -106,22 +161,174 @@ func (h *Controller) HandleRequests(w http.ResponseWriter, r *http.Request) {
 		h.customersListEndpoint(w, r)
 	case n == 3 && p[0] == "v1" && p[1] == "customer" && r.Method == http.MethodGet:
 		h.customerGetEndpoint(w, r, p[2])
+	case n == 3 && p[0] == "v1" && p[1] == "customers" && p[2] == "import" && r.Method == http.MethodPost:
+		h.customersImportEndpoint(w, r)
 
 	// --- WORK ORDERS ---
 	case n == 2 && p[0] == "v1" && p[1] == "orders" && r.Method == http.MethodGet:
 		h.workOrdersListEndpoint(w, r)
+	case n == 4 && p[0] == "v1" && p[1] == "order" && p[3] == "invoice.pdf" && r.Method == http.MethodGet:
+		h.workOrderInvoicePDFEndpoint(w, r, p[2])
+	case n == 5 && p[0] == "v1" && p[1] == "order" && p[3] == "invoice" && p[4] == "lines" && r.Method == http.MethodGet:
+		h.workOrderInvoiceLineItemsListEndpoint(w, r, p[2])
+	case n == 5 && p[0] == "v1" && p[1] == "order" && p[3] == "invoice" && p[4] == "lines" && r.Method == http.MethodPost:
+		h.workOrderInvoiceLineItemCreateEndpoint(w, r, p[2])
+	case n == 6 && p[0] == "v1" && p[1] == "order" && p[3] == "invoice" && p[4] == "lines" && p[5] == "reorder" && r.Method == http.MethodPut:
+		h.workOrderInvoiceLineItemsReorderEndpoint(w, r, p[2])
+	case n == 6 && p[0] == "v1" && p[1] == "order" && p[3] == "invoice" && p[4] == "line" && r.Method == http.MethodPut:
+		h.workOrderInvoiceLineItemUpdateEndpoint(w, r, p[2], p[5])
+	case n == 6 && p[0] == "v1" && p[1] == "order" && p[3] == "invoice" && p[4] == "line" && r.Method == http.MethodDelete:
+		h.workOrderInvoiceLineItemDeleteEndpoint(w, r, p[2], p[5])
+	case n == 5 && p[0] == "v1" && p[1] == "order" && p[3] == "invoice" && p[4] == "revisions" && r.Method == http.MethodGet:
+		h.workOrderInvoiceRevisionsListEndpoint(w, r, p[2])
+	case n == 6 && p[0] == "v1" && p[1] == "order" && p[3] == "invoice" && p[4] == "revisions" && p[5] == "diff" && r.Method == http.MethodGet:
+		h.workOrderInvoiceRevisionsDiffEndpoint(w, r, p[2])
+	case n == 5 && p[0] == "v1" && p[1] == "order" && p[3] == "invoice" && p[4] == "reopen" && r.Method == http.MethodPost:
+		h.workOrderInvoiceReopenEndpoint(w, r, p[2])
+	case n == 5 && p[0] == "v1" && p[1] == "order" && p[3] == "invoice" && p[4] == "lock" && r.Method == http.MethodPost:
+		h.workOrderInvoiceLockEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "order" && p[3] == "surveys" && r.Method == http.MethodGet:
+		h.workOrderSurveysListEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "order" && p[3] == "survey" && r.Method == http.MethodPost:
+		h.workOrderSurveyCreateEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "order" && p[3] == "deposits" && r.Method == http.MethodGet:
+		h.workOrderDepositsListEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "order" && p[3] == "deposits" && r.Method == http.MethodPost:
+		h.workOrderDepositCreateEndpoint(w, r, p[2])
+	case n == 5 && p[0] == "v1" && p[1] == "order" && p[3] == "deposit" && r.Method == http.MethodDelete:
+		h.workOrderDepositDeleteEndpoint(w, r, p[2], p[4])
 
 	// --- ASSOCIATES ---
 	case n == 2 && p[0] == "v1" && p[1] == "associates" && r.Method == http.MethodGet:
 		h.associatesListEndpoint(w, r)
+	case n == 2 && p[0] == "v1" && p[1] == "associates" && r.Method == http.MethodPost:
+		h.associateCreateEndpoint(w, r)
+	case n == 3 && p[0] == "v1" && p[1] == "associate" && r.Method == http.MethodGet:
+		h.associateGetEndpoint(w, r, p[2])
+	case n == 3 && p[0] == "v1" && p[1] == "associate" && r.Method == http.MethodPut:
+		h.associateUpdateEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "contact" && r.Method == http.MethodPut:
+		h.associateContactUpdateEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "address" && r.Method == http.MethodPut:
+		h.associateAddressUpdateEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "metrics" && r.Method == http.MethodPut:
+		h.associateMetricsUpdateEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "account" && r.Method == http.MethodPut:
+		h.associateAccountUpdateEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "upgrade" && r.Method == http.MethodPost:
+		h.associateUpgradeEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "downgrade" && r.Method == http.MethodPost:
+		h.associateDowngradeEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "archive" && r.Method == http.MethodPost:
+		h.associateArchiveEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "skill-sets" && r.Method == http.MethodGet:
+		h.associateSkillSetsListEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "skill-sets" && r.Method == http.MethodPost:
+		h.associateSkillSetCreateEndpoint(w, r, p[2])
+	case n == 5 && p[0] == "v1" && p[1] == "associate" && p[3] == "skill-set" && r.Method == http.MethodDelete:
+		h.associateSkillSetDeleteEndpoint(w, r, p[2], p[4])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "tags" && r.Method == http.MethodGet:
+		h.associateTagsListEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "tags" && r.Method == http.MethodPost:
+		h.associateTagCreateEndpoint(w, r, p[2])
+	case n == 5 && p[0] == "v1" && p[1] == "associate" && p[3] == "tag" && r.Method == http.MethodDelete:
+		h.associateTagDeleteEndpoint(w, r, p[2], p[4])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "vehicle-types" && r.Method == http.MethodGet:
+		h.associateVehicleTypesListEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "vehicle-types" && r.Method == http.MethodPost:
+		h.associateVehicleTypeCreateEndpoint(w, r, p[2])
+	case n == 5 && p[0] == "v1" && p[1] == "associate" && p[3] == "vehicle-type" && r.Method == http.MethodDelete:
+		h.associateVehicleTypeDeleteEndpoint(w, r, p[2], p[4])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "insurance-requirements" && r.Method == http.MethodGet:
+		h.associateInsuranceRequirementsListEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "insurance-requirements" && r.Method == http.MethodPost:
+		h.associateInsuranceRequirementCreateEndpoint(w, r, p[2])
+	case n == 5 && p[0] == "v1" && p[1] == "associate" && p[3] == "insurance-requirement" && r.Method == http.MethodDelete:
+		h.associateInsuranceRequirementDeleteEndpoint(w, r, p[2], p[4])
+	case n == 3 && p[0] == "v1" && p[1] == "associates" && p[2] == "import" && r.Method == http.MethodPost:
+		h.associatesImportEndpoint(w, r)
+	case n == 3 && p[0] == "v1" && p[1] == "associates" && p[2] == "balances" && r.Method == http.MethodGet:
+		h.associateBalancesListEndpoint(w, r)
+	case n == 3 && p[0] == "v1" && p[1] == "associates" && p[2] == "satisfaction" && r.Method == http.MethodGet:
+		h.associatesSatisfactionListEndpoint(w, r)
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "calendar" && r.Method == http.MethodGet:
+		h.associateCalendarEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "calendar-token" && r.Method == http.MethodPost:
+		h.associateCalendarTokenCreateEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "calendar-token" && r.Method == http.MethodDelete:
+		h.associateCalendarTokenDeleteEndpoint(w, r, p[2])
+	case n == 3 && p[0] == "v1" && p[1] == "calendar-feed" && r.Method == http.MethodGet:
+		h.associateCalendarFeedEndpoint(w, r, p[2])
+	case n == 3 && p[0] == "v1" && p[1] == "associates" && p[2] == "compliance" && r.Method == http.MethodGet:
+		h.associatesComplianceListEndpoint(w, r)
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "compliance" && r.Method == http.MethodGet:
+		h.associateComplianceEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "satisfaction" && r.Method == http.MethodGet:
+		h.associateSatisfactionEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "statement" && r.Method == http.MethodGet:
+		h.associateStatementEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "service-fee-payments" && r.Method == http.MethodPost:
+		h.associateServiceFeePaymentCreateEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "associate" && p[3] == "acceptance-rate" && r.Method == http.MethodGet:
+		h.associateAcceptanceRateEndpoint(w, r, p[2])
+
+	// --- ASSOCIATE PORTAL ---
+	case n == 3 && p[0] == "v1" && p[1] == "my" && p[2] == "orders" && r.Method == http.MethodGet:
+		h.myWorkOrdersListEndpoint(w, r)
+	case n == 4 && p[0] == "v1" && p[1] == "my" && p[2] == "order" && r.Method == http.MethodGet:
+		h.myWorkOrderGetEndpoint(w, r, p[3])
+	case n == 5 && p[0] == "v1" && p[1] == "my" && p[2] == "order" && p[4] == "completion" && r.Method == http.MethodPut:
+		h.myWorkOrderCompletionEndpoint(w, r, p[3])
+	case n == 5 && p[0] == "v1" && p[1] == "my" && p[2] == "order" && p[4] == "photos" && r.Method == http.MethodPost:
+		h.myWorkOrderPhotoCreateEndpoint(w, r, p[3])
+	case n == 3 && p[0] == "v1" && p[1] == "my" && p[2] == "offers" && r.Method == http.MethodGet:
+		h.myOffersListEndpoint(w, r)
+	case n == 5 && p[0] == "v1" && p[1] == "my" && p[2] == "offer" && p[4] == "accept" && r.Method == http.MethodPost:
+		h.myOfferAcceptEndpoint(w, r, p[3])
+	case n == 5 && p[0] == "v1" && p[1] == "my" && p[2] == "offer" && p[4] == "decline" && r.Method == http.MethodPost:
+		h.myOfferDeclineEndpoint(w, r, p[3])
+	case n == 3 && p[0] == "v1" && p[1] == "my" && p[2] == "statement" && r.Method == http.MethodGet:
+		h.myStatementEndpoint(w, r)
 
 	// --- TASKS ---
 	case n == 2 && p[0] == "v1" && p[1] == "tasks" && r.Method == http.MethodGet:
 		h.taskItemsListEndpoint(w, r)
+	case n == 3 && p[0] == "v1" && p[1] == "task" && r.Method == http.MethodGet:
+		h.taskItemGetEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "task" && p[3] == "assign-associate" && r.Method == http.MethodPost:
+		h.taskItemAssignAssociateEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "task" && p[3] == "decline-associate" && r.Method == http.MethodPost:
+		h.taskItemDeclineAssociateEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "task" && p[3] == "follow-up" && r.Method == http.MethodPost:
+		h.taskItemFollowUpEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "task" && p[3] == "follow-up-pending" && r.Method == http.MethodPost:
+		h.taskItemFollowUpPendingEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "task" && p[3] == "close" && r.Method == http.MethodPost:
+		h.taskItemCloseEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "task" && p[3] == "order-completion" && r.Method == http.MethodPost:
+		h.taskItemOrderCompletionEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "task" && p[3] == "survey" && r.Method == http.MethodPost:
+		h.taskItemSurveyEndpoint(w, r, p[2])
+
+	// --- ACTIVITY SHEETS ---
+	case n == 2 && p[0] == "v1" && p[1] == "activity-sheets" && r.Method == http.MethodGet:
+		h.activitySheetItemsListEndpoint(w, r)
+	case n == 2 && p[0] == "v1" && p[1] == "activity-sheets" && r.Method == http.MethodPost:
+		h.activitySheetItemCreateEndpoint(w, r)
+	case n == 3 && p[0] == "v1" && p[1] == "activity-sheet" && r.Method == http.MethodGet:
+		h.activitySheetItemGetEndpoint(w, r, p[2])
 
 	// --- ONGOING WORK ORDERS ---
 	case n == 2 && p[0] == "v1" && p[1] == "ongoing-orders" && r.Method == http.MethodGet:
 		h.ongoingWorkOrdersListEndpoint(w, r)
+	case n == 2 && p[0] == "v1" && p[1] == "ongoing-orders" && r.Method == http.MethodPost:
+		h.ongoingWorkOrderCreateEndpoint(w, r)
+	case n == 3 && p[0] == "v1" && p[1] == "ongoing-order" && r.Method == http.MethodGet:
+		h.ongoingWorkOrderGetEndpoint(w, r, p[2])
+	case n == 3 && p[0] == "v1" && p[1] == "ongoing-order" && r.Method == http.MethodPut:
+		h.ongoingWorkOrderUpdateEndpoint(w, r, p[2])
+	case n == 3 && p[0] == "v1" && p[1] == "ongoing-order" && r.Method == http.MethodDelete:
+		h.ongoingWorkOrderDeleteEndpoint(w, r, p[2])
 
 		// --- PARTNERS ---
 	case n == 2 && p[0] == "v1" && p[1] == "partners" && r.Method == http.MethodGet:
@@ -134,6 +341,12 @@ func (h *Controller) HandleRequests(w http.ResponseWriter, r *http.Request) {
 		// --- FINANCIALS ---
 	case n == 2 && p[0] == "v1" && p[1] == "financials" && r.Method == http.MethodGet:
 		h.financialsListEndpoint(w, r)
+	case n == 2 && p[0] == "v1" && p[1] == "accounting-exports" && r.Method == http.MethodPost:
+		h.accountingExportCreateEndpoint(w, r)
+	case n == 2 && p[0] == "v1" && p[1] == "accounting-exports" && r.Method == http.MethodGet:
+		h.accountingExportsListEndpoint(w, r)
+	case n == 4 && p[0] == "v1" && p[1] == "accounting-export" && p[3] == "download" && r.Method == http.MethodGet:
+		h.accountingExportDownloadEndpoint(w, r, p[2])
 
 		// --- BULLETIN BOARD ITEMS ---
 	case n == 2 && p[0] == "v1" && p[1] == "bulletin-board-items" && r.Method == http.MethodGet:
@@ -150,14 +363,34 @@ func (h *Controller) HandleRequests(w http.ResponseWriter, r *http.Request) {
 	// --- ASSOCIATE AWAY LOGS ---
 	case n == 2 && p[0] == "v1" && p[1] == "associate-away-logs" && r.Method == http.MethodGet:
 		h.associateAwayLogsListEndpoint(w, r)
+	case n == 2 && p[0] == "v1" && p[1] == "associate-away-logs" && r.Method == http.MethodPost:
+		h.associateAwayLogCreateEndpoint(w, r)
+	case n == 3 && p[0] == "v1" && p[1] == "associate-away-log" && r.Method == http.MethodGet:
+		h.associateAwayLogGetEndpoint(w, r, p[2])
+	case n == 3 && p[0] == "v1" && p[1] == "associate-away-log" && r.Method == http.MethodPut:
+		h.associateAwayLogUpdateEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "associate-away-log" && p[3] == "end" && r.Method == http.MethodPost:
+		h.associateAwayLogEndEndpoint(w, r, p[2])
 
 	// --- INSURANCE REQUIREMENTS ---
 	case n == 2 && p[0] == "v1" && p[1] == "insurance-requirements" && r.Method == http.MethodGet:
 		h.insuranceRequirementsListEndpoint(w, r)
+	case n == 3 && p[0] == "v1" && p[1] == "insurance-requirement" && r.Method == http.MethodPut:
+		h.insuranceRequirementUpdateEndpoint(w, r, p[2])
 
 	// --- WORK ORDER SERVICE FEES ---
 	case n == 2 && p[0] == "v1" && p[1] == "order-service-fees" && r.Method == http.MethodGet:
 		h.workOrderServiceFeesListEndpoint(w, r)
+	case n == 2 && p[0] == "v1" && p[1] == "order-service-fees" && r.Method == http.MethodPost:
+		h.workOrderServiceFeeCreateEndpoint(w, r)
+	case n == 3 && p[0] == "v1" && p[1] == "order-service-fee" && r.Method == http.MethodGet:
+		h.workOrderServiceFeeGetEndpoint(w, r, p[2])
+	case n == 3 && p[0] == "v1" && p[1] == "order-service-fee" && r.Method == http.MethodPut:
+		h.workOrderServiceFeeUpdateEndpoint(w, r, p[2])
+	case n == 3 && p[0] == "v1" && p[1] == "order-service-fee" && r.Method == http.MethodDelete:
+		h.workOrderServiceFeeArchiveEndpoint(w, r, p[2])
+	case n == 4 && p[0] == "v1" && p[1] == "order-service-fee" && p[3] == "preview" && r.Method == http.MethodPost:
+		h.workOrderServiceFeePreviewChangeEndpoint(w, r, p[2])
 
 	// --- DEACTIVATED CUSTOMER ---
 	case n == 2 && p[0] == "v1" && p[1] == "deactivated-customers" && r.Method == http.MethodGet:
@@ -57,6 +57,19 @@ func (h *Controller) customersListEndpoint(w http.ResponseWriter, r *http.Reques
 	// log.Println("SortOrder", f.SortOrder)
 	// log.Println("SortField", f.SortField)
 
+	// If the user requested an export then stream every row matching our
+	// filter into a spreadsheet instead of returning a single page.
+	if r.FormValue("export") != "" {
+		f.Offset = 0
+		f.Limit = 0
+		h.exportSpreadsheet(w, r, "customers", idos.LiteCustomerExportColumns, func(write func(row map[string]string) error) error {
+			return h.LiteCustomerRepo.StreamByFilter(ctx, &f, func(m *models.LiteCustomer) error {
+				return write(idos.NewLiteCustomerExportRow(m))
+			})
+		})
+		return
+	}
+
 	arrCh := make(chan []*models.LiteCustomer)
 	countCh := make(chan uint64)
 
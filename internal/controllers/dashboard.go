@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
-	// "time"
+	"time"
 
 	// "github.com/google/uuid"
 	null "gopkg.in/guregu/null.v4"
@@ -210,11 +210,34 @@ func (h *Controller) dashboardEndpoint(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
+	//
+	// Find "compliance_alerts".
+	//
+
+	caCh := make(chan []*models.AssociateCompliance)
+	go func() {
+		arr, err := h.AssociateComplianceRepo.ListByTenantId(ctx, tenantId)
+		if err != nil {
+			log.Println("WARNING | dashboardEndpoint | AssociateComplianceRepo.ListByTenantId|err:", err)
+			caCh <- []*models.AssociateCompliance{}
+			return
+		}
+		now := time.Now()
+		res := []*models.AssociateCompliance{}
+		for _, m := range arr {
+			m.ComputeStatus(now, models.AssociateComplianceDefaultExpiringDays)
+			if m.Status != models.AssociateComplianceCompliantStatus {
+				res = append(res, m)
+			}
+		}
+		caCh <- res
+	}()
+
 	//
 	// Block this function until all the `goroutines` finish before proceeding further.
 	//
 
-	cc, jc, mc, tc, bbi, lmbu, lmbt, al, woc := <-ccCh, <-jcCh, <-mcCh, <-tasksCountCh, <-bbiCh, <-lmbuCh, <-lmbtCh, <-alCh, <-wocCh
+	cc, jc, mc, tc, bbi, lmbu, lmbt, al, woc, ca := <-ccCh, <-jcCh, <-mcCh, <-tasksCountCh, <-bbiCh, <-lmbuCh, <-lmbtCh, <-alCh, <-wocCh, <-caCh
 
 	//
 	// Generate our response
@@ -230,6 +253,7 @@ func (h *Controller) dashboardEndpoint(w http.ResponseWriter, r *http.Request) {
 		LastModifiedJobsByTeam: lmbt,
 		AwayLog:                al,
 		PastFewDayComments:     woc,
+		ComplianceAlerts:       ca,
 	}
 	if err := json.NewEncoder(w).Encode(&ido); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/utils"
+)
+
+// Function will stream every row provided by the `stream` function into a
+// spreadsheet file in the HTTP response. The format is taken from the
+// `export` URL parameter and the optional comma-seperated `columns` URL
+// parameter selects which columns to include.
+func (h *Controller) exportSpreadsheet(
+	w http.ResponseWriter,
+	r *http.Request,
+	filenamePrefix string,
+	available []*idos.ExportColumnIDO,
+	stream func(write func(row map[string]string) error) error,
+) {
+	format := r.FormValue("export")
+	if format != utils.SpreadsheetCSVFormat && format != utils.SpreadsheetXLSXFormat {
+		http.Error(w, "Unsupported export format, please pick either `csv` or `xlsx`", http.StatusBadRequest)
+		return
+	}
+
+	columns, err := idos.SelectExportColumns(available, r.FormValue("columns"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", filenamePrefix, time.Now().Format("2006-01-02"), format)
+	w.Header().Set("Content-Type", utils.SpreadsheetContentType(format))
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	sw, err := utils.NewSpreadsheetWriter(w, format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// DEVELOPERS NOTE:
+	// Once we start writing rows the HTTP status was already sent so any
+	// errors from this point forward can only be logged.
+
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.Title
+	}
+	if err := sw.WriteRow(header); err != nil {
+		log.Println("WARNING: exportSpreadsheet|WriteRow|err:", err.Error())
+		return
+	}
+
+	cells := make([]string, len(columns))
+	err = stream(func(row map[string]string) error {
+		for i, c := range columns {
+			cells[i] = row[c.Key]
+		}
+		return sw.WriteRow(cells)
+	})
+	if err != nil {
+		log.Println("WARNING: exportSpreadsheet|stream|err:", err.Error())
+	}
+
+	if err := sw.Close(); err != nil {
+		log.Println("WARNING: exportSpreadsheet|Close|err:", err.Error())
+	}
+}
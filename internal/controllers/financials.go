@@ -5,14 +5,15 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
-	// "time"
 	"strconv"
+	"time"
 
 	// "github.com/google/uuid"
 	null "gopkg.in/guregu/null.v4"
 
 	"github.com/over55/workery-server/internal/idos"
 	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/utils"
 )
 
 func (h *Controller) financialsListEndpoint(w http.ResponseWriter, r *http.Request) {
@@ -50,6 +51,68 @@ func (h *Controller) financialsListEndpoint(w http.ResponseWriter, r *http.Reque
 		States:    []int8{7, 8}, //TECHDEBT
 	}
 
+	// Extract our optional financial filters from the URL, the dates are in
+	// the timezone of the tenant and the `to` dates are inclusive.
+	tenant, err := h.TenantRepo.GetById(ctx, tenantId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	loc, err := utils.GetTimezoneLocation(tenant.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	e := make(map[string]string)
+	if s := r.FormValue("associate_id"); s != "" {
+		if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+			f.AssociateId = null.IntFrom(v)
+		} else {
+			e["associate_id"] = "invalid value"
+		}
+	}
+	if s := r.FormValue("invoice_paid_to"); s != "" {
+		if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+			f.InvoicePaidTo = null.IntFrom(v)
+		} else {
+			e["invoice_paid_to"] = "invalid value"
+		}
+	}
+	if s := r.FormValue("service_fee_payment_date_is_null"); s != "" {
+		if v, err := strconv.ParseBool(s); err == nil {
+			f.ServiceFeePaymentDateIsNull = null.BoolFrom(v)
+		} else {
+			e["service_fee_payment_date_is_null"] = "invalid value"
+		}
+	}
+	if s := r.FormValue("has_balance_owing"); s != "" {
+		if v, err := strconv.ParseBool(s); err == nil {
+			f.HasBalanceOwing = v
+		} else {
+			e["has_balance_owing"] = "invalid value"
+		}
+	}
+	f.InvoiceDateGTE = parseDateFormValue(r, "invoice_date_from", loc, false, e)
+	f.InvoiceDateLT = parseDateFormValue(r, "invoice_date_to", loc, true, e)
+	f.ServiceFeePaymentDateGTE = parseDateFormValue(r, "service_fee_payment_date_from", loc, false, e)
+	f.ServiceFeePaymentDateLT = parseDateFormValue(r, "service_fee_payment_date_to", loc, true, e)
+
+	// The `overdue_days` parameter is a shortcut for the service fees which
+	// are still outstanding that many days after the job was invoiced.
+	if s := r.FormValue("overdue_days"); s != "" {
+		if v, err := strconv.ParseUint(s, 10, 16); err == nil {
+			f.InvoiceDateLT = null.TimeFrom(time.Now().AddDate(0, 0, -int(v)))
+			f.ServiceFeePaymentDateIsNull = null.BoolFrom(true)
+			f.HasBalanceOwing = true
+		} else {
+			e["overdue_days"] = "invalid value"
+		}
+	}
+	if len(e) != 0 {
+		b, _ := json.Marshal(e)
+		http.Error(w, string(b), http.StatusBadRequest)
+		return
+	}
+
 	// // For debugging purposes only.
 	// log.Println("TenantId", f.TenantId)
 	// log.Println("Search", f.Search)
@@ -58,8 +121,22 @@ func (h *Controller) financialsListEndpoint(w http.ResponseWriter, r *http.Reque
 	// log.Println("SortOrder", f.SortOrder)
 	// log.Println("SortField", f.SortField)
 
+	// If the user requested an export then stream every row matching our
+	// filter into a spreadsheet instead of returning a single page.
+	if r.FormValue("export") != "" {
+		f.Offset = 0
+		f.Limit = 0
+		h.exportSpreadsheet(w, r, "financials", idos.LiteFinancialExportColumns, func(write func(row map[string]string) error) error {
+			return h.LiteFinancialRepo.StreamByFilter(ctx, &f, func(m *models.LiteFinancial) error {
+				return write(idos.NewLiteFinancialExportRow(m))
+			})
+		})
+		return
+	}
+
 	arrCh := make(chan []*models.LiteFinancial)
 	countCh := make(chan uint64)
+	totalsCh := make(chan *models.LiteFinancialTotals)
 
 	go func() {
 		arr, err := h.LiteFinancialRepo.ListByFilter(ctx, &f)
@@ -81,11 +158,41 @@ func (h *Controller) financialsListEndpoint(w http.ResponseWriter, r *http.Reque
 		countCh <- count
 	}()
 
-	arr, count := <-arrCh, <-countCh
+	go func() {
+		totals, err := h.LiteFinancialRepo.SumByFilter(ctx, &f)
+		if err != nil {
+			log.Println("WARNING: financialsListEndpoint|SumByFilter|err:", err.Error())
+			totalsCh <- &models.LiteFinancialTotals{}
+			return
+		}
+		totalsCh <- totals
+	}()
+
+	arr, count, totals := <-arrCh, <-countCh, <-totalsCh
 
-	res := idos.NewLiteFinancialListResponseIDO(arr, count)
+	res := idos.NewLiteFinancialListResponseIDO(arr, count, totals)
 
 	if err := json.NewEncoder(w).Encode(&res); err != nil { // [2]
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
+
+// Function parses the `YYYY-MM-DD` date of the URL parameter in the location,
+// if `endOfDay` is set then the start of the next day is returned so the date
+// can be used as an inclusive upper bound. Invalid dates are added to the
+// error map.
+func parseDateFormValue(r *http.Request, key string, loc *time.Location, endOfDay bool, e map[string]string) null.Time {
+	s := r.FormValue(key)
+	if s == "" {
+		return null.Time{}
+	}
+	t, err := time.ParseInLocation("2006-01-02", s, loc)
+	if err != nil {
+		e[key] = "invalid date"
+		return null.Time{}
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return null.TimeFrom(t.UTC())
+}
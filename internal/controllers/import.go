@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/over55/workery-server/internal/importers"
+	"github.com/over55/workery-server/internal/models"
+)
+
+const maxImportFileSize = 32 << 20 // 32MB
+
+func (h *Controller) customersImportEndpoint(w http.ResponseWriter, r *http.Request) {
+	h.importEndpoint(w, r, h.Importer.ImportCustomers)
+}
+
+func (h *Controller) associatesImportEndpoint(w http.ResponseWriter, r *http.Request) {
+	h.importEndpoint(w, r, h.Importer.ImportAssociates)
+}
+
+// Function will import the uploaded `file` CSV using the `importFn` and
+// return the per-row report. The following optional form values are
+// supported: `dry_run`, `batch_size`, `map`, `how_hear_id` and
+// `service_fee_id`.
+func (h *Controller) importEndpoint(w http.ResponseWriter, r *http.Request, importFn func(ctx context.Context, r io.Reader, opt *importers.Options) (*importers.Report, error)) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+
+	// Permission handling - Only executives and management can import.
+	if roleId != 1 && roleId != 2 {
+		http.Error(w, "Forbidden - You are not management", http.StatusForbidden)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileSize)
+	if err := r.ParseMultipartForm(maxImportFileSize); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "missing csv `file`", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	mapping, err := importers.ParseMapping(r.FormValue("map"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	dryRun, _ := strconv.ParseBool(r.FormValue("dry_run"))
+	batchSize, _ := strconv.Atoi(r.FormValue("batch_size"))
+	howHearId, _ := strconv.ParseUint(r.FormValue("how_hear_id"), 10, 64)
+	serviceFeeId, _ := strconv.ParseUint(r.FormValue("service_fee_id"), 10, 64)
+
+	report, err := importFn(ctx, file, &importers.Options{
+		TenantId:            tenantId,
+		CreatedById:         user.Id,
+		CreatedByName:       user.Name,
+		CreatedFromIP:       ipAddress,
+		DryRun:              dryRun,
+		BatchSize:           batchSize,
+		Mapping:             mapping,
+		DefaultHowHearId:    howHearId,
+		DefaultServiceFeeId: serviceFeeId,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&report); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
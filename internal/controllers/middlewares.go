@@ -79,6 +79,7 @@ func (h *Controller) JWTProcessorMiddleware(fn http.HandlerFunc) http.HandlerFun
 				"register":      true,
 				"login":         true,
 				"refresh-token": true,
+				"calendar-feed": true,
 			}
 
 			// DEVELOPERS NOTE:
@@ -189,6 +190,7 @@ func (h *Controller) ProtectedURLsMiddleware(fn http.HandlerFunc) http.HandlerFu
 			"register":      true,
 			"login":         true,
 			"refresh-token": true,
+			"calendar-feed": true,
 		}
 
 		// DEVELOPERS NOTE:
@@ -0,0 +1,247 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/utils"
+	"github.com/over55/workery-server/internal/validators"
+)
+
+func (h *Controller) ongoingWorkOrderCreateEndpoint(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+
+	// Permission handling - Only staff can create ongoing work orders.
+	if roleId != 1 && roleId != 2 && roleId != 3 {
+		http.Error(w, "Forbidden - You are not staff", http.StatusForbidden)
+		return
+	}
+
+	// Get the user `POST` data from the HTTP request.
+	var postData *idos.OngoingWorkOrderIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateOngoingWorkOrderSaveFromRequest(postData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	m := &models.OngoingWorkOrder{
+		Uuid:               uuid.NewString(),
+		TenantId:           tenantId,
+		State:              models.OngoingWorkOrderActiveState,
+		CreatedTime:        now,
+		CreatedById:        null.IntFrom(int64(user.Id)),
+		CreatedByName:      null.StringFrom(user.Name),
+		CreatedFromIP:      null.StringFrom(ipAddress),
+		LastModifiedTime:   now,
+		LastModifiedById:   null.IntFrom(int64(user.Id)),
+		LastModifiedByName: null.StringFrom(user.Name),
+		LastModifiedFromIP: null.StringFrom(ipAddress),
+	}
+	if ok := h.applyOngoingWorkOrderIDO(w, r, m, postData); !ok {
+		return
+	}
+	if err := h.OngoingWorkOrderRepo.Insert(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) ongoingWorkOrderGetEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	m, ok := h.getOngoingWorkOrderForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) ongoingWorkOrderUpdateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+
+	m, ok := h.getOngoingWorkOrderForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	// Get the user `PUT` data from the HTTP request.
+	var putData *idos.OngoingWorkOrderIDO
+	if err := json.NewDecoder(r.Body).Decode(&putData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateOngoingWorkOrderSaveFromRequest(putData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	// DEVELOPERS NOTE:
+	// The jobs which were already created are kept as they are, the changes
+	// only apply to the jobs created after `generated_until`.
+	if ok := h.applyOngoingWorkOrderIDO(w, r, m, putData); !ok {
+		return
+	}
+	m.LastModifiedTime = time.Now()
+	m.LastModifiedById = null.IntFrom(int64(user.Id))
+	m.LastModifiedByName = null.StringFrom(user.Name)
+	m.LastModifiedFromIP = null.StringFrom(ipAddress)
+	if err := h.OngoingWorkOrderRepo.UpdateById(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function deactivates the ongoing work order so no more jobs get created
+// for it, the jobs which were already created are kept.
+func (h *Controller) ongoingWorkOrderDeleteEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+
+	m, ok := h.getOngoingWorkOrderForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+	m.State = models.OngoingWorkOrderInactiveState
+	m.LastModifiedTime = time.Now()
+	m.LastModifiedById = null.IntFrom(int64(user.Id))
+	m.LastModifiedByName = null.StringFrom(user.Name)
+	m.LastModifiedFromIP = null.StringFrom(ipAddress)
+	if err := h.OngoingWorkOrderRepo.UpdateById(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Function copies the validated request data into the ongoing work order, the
+// customer and associate must belong to the tenant. If anything is wrong the
+// error is written to the response and `false` is returned.
+func (h *Controller) applyOngoingWorkOrderIDO(w http.ResponseWriter, r *http.Request, m *models.OngoingWorkOrder, data *idos.OngoingWorkOrderIDO) bool {
+	ctx := r.Context()
+
+	customer, err := h.CustomerRepo.GetById(ctx, data.CustomerId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if customer == nil || customer.TenantId != m.TenantId {
+		http.Error(w, `{"customer_id":"customer does not exist"}`, http.StatusBadRequest)
+		return false
+	}
+	m.CustomerId = customer.Id
+	m.CustomerName = null.StringFrom(customer.Name)
+	m.CustomerLexicalName = null.StringFrom(customer.LexicalName)
+
+	m.AssociateId = null.Int{}
+	m.AssociateName = null.String{}
+	m.AssociateLexicalName = null.String{}
+	if data.AssociateId.Valid {
+		associate, err := h.AssociateRepo.GetById(ctx, uint64(data.AssociateId.Int64))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return false
+		}
+		if associate == nil || associate.TenantId != m.TenantId {
+			http.Error(w, `{"associate_id":"associate does not exist"}`, http.StatusBadRequest)
+			return false
+		}
+		m.AssociateId = null.IntFrom(int64(associate.Id))
+		m.AssociateName = null.StringFrom(associate.Name)
+		m.AssociateLexicalName = null.StringFrom(associate.LexicalName)
+	}
+
+	// The recurrence dates are in the timezone of the tenant.
+	tenant, err := h.TenantRepo.GetById(ctx, m.TenantId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	loc, err := utils.GetTimezoneLocation(tenant.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	startDate, _ := time.ParseInLocation("2006-01-02", data.RecurrenceStartDate, loc)
+	m.RecurrenceStartDate = null.TimeFrom(startDate.UTC())
+	m.RecurrenceEndDate = null.Time{}
+	if data.RecurrenceEndDate != "" {
+		endDate, _ := time.ParseInLocation("2006-01-02", data.RecurrenceEndDate, loc)
+		m.RecurrenceEndDate = null.TimeFrom(endDate.UTC())
+	}
+
+	rule, _ := models.ParseRecurrenceRule(data.RecurrenceRule) // Validated already.
+	m.RecurrenceRule = rule.String()
+	m.Description = strings.TrimSpace(data.Description)
+	m.TypeOf = data.TypeOf
+	m.IsHomeSupportService = data.IsHomeSupportService
+	return true
+}
+
+// Function returns the ongoing work order of the tenant if the user is staff,
+// otherwise the error is written to the response and `false` is returned.
+func (h *Controller) getOngoingWorkOrderForStaff(w http.ResponseWriter, r *http.Request, idStr string) (*models.OngoingWorkOrder, bool) {
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+
+	// Permission handling - Only staff can manage ongoing work orders.
+	if roleId != 1 && roleId != 2 && roleId != 3 {
+		http.Error(w, "Forbidden - You are not staff", http.StatusForbidden)
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	m, err := h.OngoingWorkOrderRepo.GetById(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if m == nil || m.TenantId != tenantId {
+		http.Error(w, "Ongoing work order does not exist", http.StatusNotFound)
+		return nil, false
+	}
+	return m, true
+}
@@ -0,0 +1,555 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/validators"
+)
+
+func (h *Controller) taskItemGetEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+
+	// Permission handling - Only staff can view tasks.
+	if roleId != 1 && roleId != 2 && roleId != 3 {
+		http.Error(w, "Forbidden - You are not staff", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m, err := h.TaskItemRepo.GetById(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if m == nil || m.TenantId != tenantId {
+		http.Error(w, "Task does not exist", http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) taskItemAssignAssociateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+
+	var postData *idos.TaskItemAssignAssociateIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateTaskItemAssignAssociateFromRequest(postData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	task, order, ok := h.getOpenTaskItemForStaff(w, r, idStr, models.TaskAssignAssociateTypeOf)
+	if !ok {
+		return
+	}
+
+	associate, err := h.AssociateRepo.GetById(ctx, postData.AssociateId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if associate == nil || associate.TenantId != tenantId {
+		http.Error(w, `{"associate_id":"associate does not exist"}`, http.StatusBadRequest)
+		return
+	}
+	if associate.State != models.AssociateActiveState {
+		http.Error(w, `{"associate_id":"associate is not active"}`, http.StatusBadRequest)
+		return
+	}
+
+	// The associate cannot be assigned if any insurance required by the skill
+	// sets of the job has lapsed.
+	now := time.Now()
+	lapsed, err := h.AssociateComplianceRepo.ListLapsedForWorkOrder(ctx, associate.Id, order.Id, now)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(lapsed) != 0 {
+		texts := make([]string, len(lapsed))
+		for i, item := range lapsed {
+			texts[i] = item.InsuranceRequirementText
+		}
+		b, err := json.Marshal(map[string]string{
+			"associate_id": "associate has lapsed insurance required by the job: " + strings.Join(texts, ", "),
+		})
+		if err != nil { // Defensive code
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, string(b), http.StatusBadRequest)
+		return
+	}
+
+	order.AssociateId = null.IntFrom(int64(associate.Id))
+	order.AssociateName = null.StringFrom(associate.Name)
+	order.AssociateLexicalName = null.StringFrom(associate.LexicalName)
+	order.AssignmentDate = null.TimeFrom(now)
+	if !order.InvoiceServiceFeeId.Valid && associate.ServiceFeeId != 0 {
+		order.InvoiceServiceFeeId = null.IntFrom(int64(associate.ServiceFeeId))
+	}
+	if order.IsOngoing {
+		order.State = models.WorkOrderOngoingState
+	} else {
+		order.State = models.WorkOrderInProgressState
+	}
+
+	// The next step is to confirm the associate and the customer agreed to
+	// meet with each other.
+	next := h.newTaskItemForWorkOrder(r, order, models.TaskFollowUpDidAssociateAndCustomerAgreedToMeetTypeOf, now.AddDate(0, 0, 1))
+
+	// Keep the history of the associate accepting the job so the acceptance
+	// rate of the associate stays accurate.
+	item, err := h.getOpenActivitySheetOffer(ctx, order, associate.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if item == nil {
+		item = h.newActivitySheetItem(r, associate, null.IntFrom(int64(order.Id)), order.OngoingWorkOrderId, models.ActivitySheetItemAcceptedState, "")
+	}
+	item.State = models.ActivitySheetItemAcceptedState
+	h.markTaskItemClosed(r, task, 0, "")
+	h.markWorkOrderModified(r, order)
+	if err := h.TaskItemRepo.CloseAndAssignAssociate(ctx, task, order, next, item); err != nil {
+		writeTaskItemCloseError(w, err)
+		return
+	}
+	writeTaskItemOperationResponse(w, task, order, next)
+}
+
+// Function records the associate declining the job offered through the
+// assign associate task, the task stays open so another associate can be
+// assigned.
+func (h *Controller) taskItemDeclineAssociateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+
+	var postData *idos.TaskItemDeclineAssociateIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateTaskItemDeclineAssociateFromRequest(postData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	_, order, ok := h.getOpenTaskItemForStaff(w, r, idStr, models.TaskAssignAssociateTypeOf)
+	if !ok {
+		return
+	}
+
+	associate, err := h.AssociateRepo.GetById(ctx, postData.AssociateId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if associate == nil || associate.TenantId != tenantId {
+		http.Error(w, `{"associate_id":"associate does not exist"}`, http.StatusBadRequest)
+		return
+	}
+
+	// Record the response on the offer made to the associate, if there is
+	// one, otherwise the job was offered without being recorded.
+	m, err := h.getOpenActivitySheetOffer(ctx, order, associate.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if m != nil {
+		m.State = models.ActivitySheetItemDeclinedState
+		m.Comment = strings.TrimSpace(postData.Comment)
+		err = h.ActivitySheetItemRepo.UpdateById(ctx, m)
+	} else {
+		m = h.newActivitySheetItem(r, associate, null.IntFrom(int64(order.Id)), order.OngoingWorkOrderId, models.ActivitySheetItemDeclinedState, strings.TrimSpace(postData.Comment))
+		err = h.ActivitySheetItemRepo.Insert(ctx, m)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) taskItemFollowUpEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	var postData *idos.TaskItemFollowUpIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateTaskItemFollowUpFromRequest(postData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	task, order, ok := h.getOpenTaskItemForStaff(w, r, idStr, models.TaskFollowUpDidAssociateAndCustomerAgreedToMeetTypeOf)
+	if !ok {
+		return
+	}
+
+	var next *models.TaskItem
+	change := int8(models.TaskWorkOrderNoChange)
+	if postData.HasAgreedToMeet {
+		// Check up with the customer once the associate has met them.
+		next = h.newTaskItemForWorkOrder(r, order, models.TaskFollowUpIsJobCompleteTypeOf, postData.MeetingDate.Time)
+	} else {
+		// The associate is taken off the work order and another associate
+		// needs to get assigned.
+		order.AssociateId = null.Int{}
+		order.AssociateName = null.String{}
+		order.AssociateLexicalName = null.String{}
+		order.AssignmentDate = null.Time{}
+		order.State = models.WorkOrderNewState
+		next = h.newTaskItemForWorkOrder(r, order, models.TaskAssignAssociateTypeOf, time.Now())
+		change = models.TaskWorkOrderAssociateChange
+	}
+	h.closeTaskItemAndCreateNext(w, r, task, 0, "", order, next, change)
+}
+
+func (h *Controller) taskItemFollowUpPendingEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	var postData *idos.TaskItemFollowUpPendingIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateTaskItemFollowUpPendingFromRequest(postData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	task, order, ok := h.getOpenTaskItemForStaff(w, r, idStr, models.TaskFollowUpIsJobCompleteTypeOf, models.TaskFollowUpDidAssociateAndCustomerAgreedToMeetTypeOf)
+	if !ok {
+		return
+	}
+
+	// The follow up gets postponed by replacing the task with the same task
+	// due at a later date.
+	task.WasPostponed = true
+	next := h.newTaskItemForWorkOrder(r, order, task.TypeOf, postData.DueDate.Time)
+	h.closeTaskItemAndCreateNext(w, r, task, 0, "", order, next, models.TaskWorkOrderNoChange)
+}
+
+func (h *Controller) taskItemCloseEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	var postData *idos.TaskItemCloseIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateTaskItemCloseFromRequest(postData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	task, order, ok := h.getOpenTaskItemForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	// Closing the task cancels the work order so there is no next task.
+	order.State = models.WorkOrderCancelledState
+	order.ClosingReason = postData.ClosingReason
+	order.ClosingReasonOther = null.NewString(postData.ClosingReasonOther, postData.ClosingReasonOther != "")
+	order.ClosingReasonComment = postData.ClosingReasonComment
+	h.closeTaskItemAndCreateNext(w, r, task, postData.ClosingReason, postData.ClosingReasonOther, order, nil, models.TaskWorkOrderClosingChange)
+}
+
+func (h *Controller) taskItemOrderCompletionEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+
+	var postData *idos.TaskItemOrderCompletionIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateTaskItemOrderCompletionFromRequest(postData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	task, order, ok := h.getOpenTaskItemForStaff(w, r, idStr, models.TaskFollowUpIsJobCompleteTypeOf)
+	if !ok {
+		return
+	}
+
+	if !postData.WasCompleted {
+		order.State = models.WorkOrderCancelledState
+		order.ClosingReason = postData.ClosingReason
+		order.ClosingReasonOther = null.NewString(postData.ClosingReasonOther, postData.ClosingReasonOther != "")
+		h.closeTaskItemAndCreateNext(w, r, task, postData.ClosingReason, postData.ClosingReasonOther, order, nil, models.TaskWorkOrderClosingChange)
+		return
+	}
+
+	fee, err := h.WorkOrderServiceFeeRepo.GetById(ctx, postData.InvoiceServiceFeeId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if fee == nil || fee.TenantId != tenantId {
+		http.Error(w, `{"invoice_service_fee_id":"service fee does not exist"}`, http.StatusBadRequest)
+		return
+	}
+	percentage, err := h.WorkOrderServiceFeeRepo.GetPercentageAtById(ctx, fee.Id, postData.InvoiceDate.Time)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The tax is computed from the tax rules of the tenant which are
+	// effective for the type of the work order on the invoice date.
+	rules, err := h.TenantTaxRuleRepo.ListEffectiveByTenantId(ctx, tenantId, order.TypeOf, postData.InvoiceDate.Time)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	order.CompletionDate = postData.CompletionDate
+	order.InvoiceDate = postData.InvoiceDate
+	order.InvoiceIds = null.StringFrom(strings.TrimSpace(postData.InvoiceIds))
+	order.InvoiceQuoteAmount = postData.InvoiceQuoteAmount
+	order.InvoiceLabourAmount = postData.InvoiceLabourAmount
+	order.InvoiceMaterialAmount = postData.InvoiceMaterialAmount
+	order.InvoiceOtherCostsAmount = postData.InvoiceOtherCostsAmount
+	order.InvoiceSubTotalAmount = postData.InvoiceLabourAmount + postData.InvoiceMaterialAmount + postData.InvoiceOtherCostsAmount
+	order.InvoiceTaxAmount, order.InvoiceTaxRules = models.ApplyTaxRules(order.InvoiceSubTotalAmount, rules)
+	order.InvoiceTotalAmount = order.InvoiceSubTotalAmount + order.InvoiceTaxAmount
+	order.InvoicePaidTo = null.IntFrom(int64(postData.InvoicePaidTo))
+	order.InvoiceServiceFeeId = null.IntFrom(int64(fee.Id))
+	order.InvoiceServiceFeeAmount = models.ComputeServiceFee(postData.InvoiceLabourAmount, percentage)
+	order.Visits = postData.Visits
+	order.WasThereFinancialsInputted = true
+	order.State = models.WorkOrderCompletedButUnpaidState
+
+	// Once the job is done the customer gets surveyed.
+	next := h.newTaskItemForWorkOrder(r, order, models.TaskFollowUpCustomerSurveyTypeOf, time.Now())
+	h.closeTaskItemAndCreateNext(w, r, task, 0, "", order, next, models.TaskWorkOrderCompletionChange)
+}
+
+func (h *Controller) taskItemSurveyEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	var postData *idos.WorkOrderSurveyIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateWorkOrderSurveySaveFromRequest(postData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	task, order, ok := h.getOpenTaskItemForStaff(w, r, idStr, models.TaskFollowUpCustomerSurveyTypeOf)
+	if !ok {
+		return
+	}
+
+	// The survey is the last step of the work order.
+	if _, ok := h.createWorkOrderSurvey(w, r, order, postData, task); !ok {
+		return
+	}
+	res := &idos.TaskItemOperationResponseIDO{
+		Task:  task,
+		Order: order,
+	}
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// getOpenTaskItemForStaff returns the open task and its work order, if
+// `typeOfs` is provided the task must be one of those types.
+func (h *Controller) getOpenTaskItemForStaff(w http.ResponseWriter, r *http.Request, idStr string, typeOfs ...int8) (*models.TaskItem, *models.WorkOrder, bool) {
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+
+	// Permission handling - Only staff can process tasks.
+	if roleId != 1 && roleId != 2 && roleId != 3 {
+		http.Error(w, "Forbidden - You are not staff", http.StatusForbidden)
+		return nil, nil, false
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, nil, false
+	}
+	task, err := h.TaskItemRepo.GetById(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	if task == nil || task.TenantId != tenantId {
+		http.Error(w, "Task does not exist", http.StatusNotFound)
+		return nil, nil, false
+	}
+	if task.IsClosed {
+		http.Error(w, "Task is already closed", http.StatusConflict)
+		return nil, nil, false
+	}
+	if len(typeOfs) > 0 {
+		isAllowed := false
+		for _, typeOf := range typeOfs {
+			if task.TypeOf == typeOf {
+				isAllowed = true
+			}
+		}
+		if !isAllowed {
+			http.Error(w, "Task does not support this operation", http.StatusConflict)
+			return nil, nil, false
+		}
+	}
+
+	order, err := h.WorkOrderRepo.GetById(ctx, task.OrderId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	if order == nil || order.TenantId != tenantId {
+		http.Error(w, "Work order does not exist", http.StatusNotFound)
+		return nil, nil, false
+	}
+	return task, order, true
+}
+
+func (h *Controller) newTaskItemForWorkOrder(r *http.Request, order *models.WorkOrder, typeOf int8, dueDate time.Time) *models.TaskItem {
+	ctx := r.Context()
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+	now := time.Now()
+
+	return &models.TaskItem{
+		Uuid:                 uuid.NewString(),
+		TenantId:             order.TenantId,
+		TypeOf:               typeOf,
+		Title:                models.TaskItemTypeOfTitles[typeOf],
+		Description:          order.Description,
+		DueDate:              dueDate,
+		OrderId:              order.Id,
+		OngoingOrderId:       order.OngoingWorkOrderId,
+		CreatedTime:          now,
+		CreatedFromIP:        null.StringFrom(ipAddress),
+		CreatedById:          null.IntFrom(int64(user.Id)),
+		CreatedByName:        null.StringFrom(user.Name),
+		LastModifiedTime:     now,
+		LastModifiedFromIP:   null.StringFrom(ipAddress),
+		LastModifiedById:     null.IntFrom(int64(user.Id)),
+		LastModifiedByName:   null.StringFrom(user.Name),
+		State:                models.TaskActiveState,
+		CustomerId:           null.IntFrom(int64(order.CustomerId)),
+		CustomerName:         null.StringFrom(order.CustomerName),
+		CustomerLexicalName:  null.StringFrom(order.CustomerLexicalName),
+		AssociateId:          order.AssociateId,
+		AssociateName:        order.AssociateName,
+		AssociateLexicalName: order.AssociateLexicalName,
+		OrderTypeOf:          order.TypeOf,
+	}
+}
+
+// closeTaskItemAndCreateNext closes the task, saves the `change` part of the
+// work order and creates the next task, if there is one, in a single
+// transaction and then writes the result.
+func (h *Controller) closeTaskItemAndCreateNext(w http.ResponseWriter, r *http.Request, task *models.TaskItem, reason int8, reasonOther string, order *models.WorkOrder, next *models.TaskItem, change int8) {
+	ctx := r.Context()
+	h.markTaskItemClosed(r, task, reason, reasonOther)
+	h.markWorkOrderModified(r, order)
+
+	if err := h.TaskItemRepo.CloseAndCreateNext(ctx, task, order, next, change); err != nil {
+		writeTaskItemCloseError(w, err)
+		return
+	}
+	writeTaskItemOperationResponse(w, task, order, next)
+}
+
+// writeTaskItemCloseError writes the error of closing the task, the task
+// could have been closed by someone else since it was read.
+func writeTaskItemCloseError(w http.ResponseWriter, err error) {
+	if err == models.ErrTaskItemClosed {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+func writeTaskItemOperationResponse(w http.ResponseWriter, task *models.TaskItem, order *models.WorkOrder, next *models.TaskItem) {
+	res := &idos.TaskItemOperationResponseIDO{
+		Task:     task,
+		Order:    order,
+		NextTask: next,
+	}
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) markTaskItemClosed(r *http.Request, task *models.TaskItem, reason int8, reasonOther string) {
+	ctx := r.Context()
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+
+	task.IsClosed = true
+	task.ClosingReason = reason
+	task.ClosingReasonOther = reasonOther
+	task.LastModifiedTime = time.Now()
+	task.LastModifiedFromIP = null.StringFrom(ipAddress)
+	task.LastModifiedById = null.IntFrom(int64(user.Id))
+	task.LastModifiedByName = null.StringFrom(user.Name)
+}
+
+func (h *Controller) markWorkOrderModified(r *http.Request, order *models.WorkOrder) {
+	ctx := r.Context()
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+
+	order.LastModifiedTime = time.Now()
+	order.LastModifiedFromIP = null.StringFrom(ipAddress)
+	order.LastModifiedById = null.IntFrom(int64(user.Id))
+	order.LastModifiedByName = null.StringFrom(user.Name)
+}
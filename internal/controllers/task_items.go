@@ -38,6 +38,11 @@ func (h *Controller) taskItemsListEndpoint(w http.ResponseWriter, r *http.Reques
 		sortFieldString = "due_date"
 	}
 
+	isOverdueString := r.FormValue("is_overdue")
+	isOverdue, err := strconv.ParseBool(isOverdueString)
+	escalatedToIdString := r.FormValue("escalated_to_id")
+	escalatedToId, _ := strconv.ParseInt(escalatedToIdString, 10, 64)
+
 	// DEVELOPERS NOTE:
 	// - Write code to handle filtering by states.
 	var states []int8 = []int8{1} // TECHDEBT
@@ -45,14 +50,16 @@ func (h *Controller) taskItemsListEndpoint(w http.ResponseWriter, r *http.Reques
 	// Start by defining our base listing filter and then append depending on
 	// different cases.
 	f := models.LiteTaskItemFilter{
-		TenantId:  tenantId,
-		SortField: sortFieldString,
-		SortOrder: sortOrderString,
-		Search:    null.NewString(searchString, searchString != ""),
-		States:    states,
-		Offset:    offsetParam,
-		Limit:     limitParam,
-		IsClosed:  null.BoolFrom(false), // TECHDEBT
+		TenantId:      tenantId,
+		SortField:     sortFieldString,
+		SortOrder:     sortOrderString,
+		Search:        null.NewString(searchString, searchString != ""),
+		States:        states,
+		Offset:        offsetParam,
+		Limit:         limitParam,
+		IsClosed:      null.BoolFrom(false), // TECHDEBT
+		IsOverdue:     null.NewBool(isOverdue, err == nil),
+		EscalatedToId: null.NewInt(escalatedToId, escalatedToId != 0),
 	}
 
 	// // For debugging purposes only.
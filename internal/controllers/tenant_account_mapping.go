@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/validators"
+)
+
+func (h *Controller) tenantAccountMappingGetEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	role_id := uint64(ctx.Value("user_role_id").(int8))
+
+	// Permission handling - If use is not administrator then error.
+	if role_id != 1 {
+		http.Error(w, "Forbidden - You are not an administrator", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m, err := h.TenantAccountMappingRepo.GetByTenantId(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if m == nil { // Tenants without a mapping get the defaults.
+		m = models.NewDefaultTenantAccountMapping(id)
+	}
+
+	ido := idos.NewTenantAccountMappingIDO(m)
+	if err := json.NewEncoder(w).Encode(&ido); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) tenantAccountMappingUpdateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	role_id := uint64(ctx.Value("user_role_id").(int8))
+
+	// Permission handling - If use is not administrator then error.
+	if role_id != 1 {
+		http.Error(w, "Forbidden - You are not an administrator", http.StatusForbidden)
+		return
+	}
+
+	// Lookup the tenant based on the `ID` or error.
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	doesExist, err := h.TenantRepo.CheckIfExistsById(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if doesExist == false {
+		http.Error(w, "Tenant does not exist", http.StatusNotFound)
+		return
+	}
+
+	// Get the user `PUT` data from the HTTP request.
+	var putData *idos.TenantAccountMappingIDO
+	if err := json.NewDecoder(r.Body).Decode(&putData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateTenantAccountMappingSaveFromRequest(putData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	m := &models.TenantAccountMapping{
+		TenantId:           id,
+		AccountsReceivable: strings.TrimSpace(putData.AccountsReceivable),
+		LabourIncome:       strings.TrimSpace(putData.LabourIncome),
+		MaterialsIncome:    strings.TrimSpace(putData.MaterialsIncome),
+		OtherIncome:        strings.TrimSpace(putData.OtherIncome),
+		SalesTaxPayable:    strings.TrimSpace(putData.SalesTaxPayable),
+		UndepositedFunds:   strings.TrimSpace(putData.UndepositedFunds),
+		AssociateClearing:  strings.TrimSpace(putData.AssociateClearing),
+		ServiceFeeIncome:   strings.TrimSpace(putData.ServiceFeeIncome),
+		CreatedTime:        now,
+		LastModifiedTime:   now,
+	}
+	if err := h.TenantAccountMappingRepo.InsertOrUpdateByTenantId(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return our result
+	ido := idos.NewTenantAccountMappingIDO(m)
+	if err := json.NewEncoder(w).Encode(&ido); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/validators"
+)
+
+func (h *Controller) tenantInvoiceTemplateGetEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	role_id := uint64(ctx.Value("user_role_id").(int8))
+
+	// Permission handling - If use is not administrator then error.
+	if role_id != 1 {
+		http.Error(w, "Forbidden - You are not an administrator", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m, err := h.TenantInvoiceTemplateRepo.GetByTenantId(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if m == nil { // Tenants without a template get the defaults.
+		m = &models.TenantInvoiceTemplate{TenantId: id}
+	}
+
+	ido := idos.NewTenantInvoiceTemplateIDO(m)
+	if err := json.NewEncoder(w).Encode(&ido); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) tenantInvoiceTemplateUpdateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	role_id := uint64(ctx.Value("user_role_id").(int8))
+
+	// Permission handling - If use is not administrator then error.
+	if role_id != 1 {
+		http.Error(w, "Forbidden - You are not an administrator", http.StatusForbidden)
+		return
+	}
+
+	// Lookup the tenant based on the `ID` or error.
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	doesExist, err := h.TenantRepo.CheckIfExistsById(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if doesExist == false {
+		http.Error(w, "Tenant does not exist", http.StatusNotFound)
+		return
+	}
+
+	// Get the user `PUT` data from the HTTP request.
+	var putData *idos.TenantInvoiceTemplateIDO
+	if err := json.NewDecoder(r.Body).Decode(&putData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateTenantInvoiceTemplateSaveFromRequest(putData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	m := &models.TenantInvoiceTemplate{
+		TenantId:              id,
+		LogoS3Key:             putData.LogoS3Key,
+		TaxRegistrationNumber: putData.TaxRegistrationNumber,
+		FooterText:            putData.FooterText,
+		CreatedTime:           now,
+		LastModifiedTime:      now,
+	}
+	if err := h.TenantInvoiceTemplateRepo.InsertOrUpdateByTenantId(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return our result
+	ido := idos.NewTenantInvoiceTemplateIDO(m)
+	if err := json.NewEncoder(w).Encode(&ido); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/validators"
+)
+
+func (h *Controller) tenantTaskSettingGetEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	role_id := uint64(ctx.Value("user_role_id").(int8))
+
+	// Permission handling - If use is not administrator then error.
+	if role_id != 1 {
+		http.Error(w, "Forbidden - You are not an administrator", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m, err := h.TenantTaskSettingRepo.GetByTenantId(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if m == nil { // Tenants without settings get the defaults.
+		m = &models.TenantTaskSetting{
+			TenantId:          id,
+			FollowUpAfterDays: models.TenantTaskSettingDefaultFollowUpAfterDays,
+			EscalateAfterDays: models.TenantTaskSettingDefaultEscalateAfterDays,
+		}
+	}
+
+	ido := idos.NewTenantTaskSettingIDO(m)
+	if err := json.NewEncoder(w).Encode(&ido); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) tenantTaskSettingUpdateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	role_id := uint64(ctx.Value("user_role_id").(int8))
+
+	// Permission handling - If use is not administrator then error.
+	if role_id != 1 {
+		http.Error(w, "Forbidden - You are not an administrator", http.StatusForbidden)
+		return
+	}
+
+	// Lookup the tenant based on the `ID` or error.
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	doesExist, err := h.TenantRepo.CheckIfExistsById(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if doesExist == false {
+		http.Error(w, "Tenant does not exist", http.StatusNotFound)
+		return
+	}
+
+	// Get the user `PUT` data from the HTTP request.
+	var putData *idos.TenantTaskSettingIDO
+	if err := json.NewDecoder(r.Body).Decode(&putData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateTenantTaskSettingSaveFromRequest(putData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	// The overdue tasks can only be escalated to a manager of the tenant.
+	if putData.EscalateToId.Valid {
+		u, err := h.UserRepo.GetById(ctx, uint64(putData.EscalateToId.Int64))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if u == nil || u.TenantId != id || (u.RoleId != 1 && u.RoleId != 2) {
+			http.Error(w, `{"escalate_to_id":"user is not a manager of the tenant"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	now := time.Now()
+	m := &models.TenantTaskSetting{
+		TenantId:          id,
+		FollowUpAfterDays: putData.FollowUpAfterDays,
+		EscalateAfterDays: putData.EscalateAfterDays,
+		EscalateToId:      putData.EscalateToId,
+		CreatedTime:       now,
+		LastModifiedTime:  now,
+	}
+	if err := h.TenantTaskSettingRepo.InsertOrUpdateByTenantId(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return our result
+	ido := idos.NewTenantTaskSettingIDO(m)
+	if err := json.NewEncoder(w).Encode(&ido); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
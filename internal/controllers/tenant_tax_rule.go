@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/validators"
+)
+
+func (h *Controller) tenantTaxRulesListEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	role_id := uint64(ctx.Value("user_role_id").(int8))
+
+	// Permission handling - If use is not administrator then error.
+	if role_id != 1 {
+		http.Error(w, "Forbidden - You are not an administrator", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	arr, err := h.TenantTaxRuleRepo.ListByTenantId(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := idos.NewTenantTaxRuleListResponseIDO(id, arr)
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) tenantTaxRuleCreateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	role_id := uint64(ctx.Value("user_role_id").(int8))
+	user_id := uint64(ctx.Value("user_id").(uint64))
+
+	// Permission handling - If use is not administrator then error.
+	if role_id != 1 {
+		http.Error(w, "Forbidden - You are not an administrator", http.StatusForbidden)
+		return
+	}
+
+	// Lookup the tenant based on the `ID` or error.
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	doesExist, err := h.TenantRepo.CheckIfExistsById(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if doesExist == false {
+		http.Error(w, "Tenant does not exist", http.StatusNotFound)
+		return
+	}
+
+	// Get the user `POST` data from the HTTP request.
+	var postData *idos.TenantTaxRuleIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	arr, err := h.TenantTaxRuleRepo.ListByTenantId(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	isValid, errStr := validators.ValidateTenantTaxRuleSaveFromRequest(postData, 0, arr)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	m := &models.TenantTaxRule{
+		Uuid:             uuid.NewString(),
+		TenantId:         id,
+		Name:             strings.TrimSpace(postData.Name),
+		Rate:             postData.Rate,
+		TypeOf:           postData.TypeOf,
+		EffectiveFrom:    postData.EffectiveFrom.Time,
+		EffectiveTo:      postData.EffectiveTo,
+		CreatedTime:      now,
+		CreatedById:      null.IntFrom(int64(user_id)),
+		LastModifiedTime: now,
+		LastModifiedById: null.IntFrom(int64(user_id)),
+	}
+	if err := h.TenantTaxRuleRepo.Insert(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) tenantTaxRuleUpdateEndpoint(w http.ResponseWriter, r *http.Request, idStr string, ruleIdStr string) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	user_id := uint64(ctx.Value("user_id").(uint64))
+
+	m, ok := h.getTenantTaxRuleForAdministrator(w, r, idStr, ruleIdStr)
+	if !ok {
+		return
+	}
+
+	// Get the user `PUT` data from the HTTP request.
+	var putData *idos.TenantTaxRuleIDO
+	if err := json.NewDecoder(r.Body).Decode(&putData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	arr, err := h.TenantTaxRuleRepo.ListByTenantId(ctx, m.TenantId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	isValid, errStr := validators.ValidateTenantTaxRuleSaveFromRequest(putData, m.Id, arr)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	// DEVELOPERS NOTE:
+	// Invoices keep a snapshot of the rules they were computed with so
+	// changing a rule only affects the invoices which get recomputed later.
+	m.Name = strings.TrimSpace(putData.Name)
+	m.Rate = putData.Rate
+	m.TypeOf = putData.TypeOf
+	m.EffectiveFrom = putData.EffectiveFrom.Time
+	m.EffectiveTo = putData.EffectiveTo
+	m.LastModifiedTime = time.Now()
+	m.LastModifiedById = null.IntFrom(int64(user_id))
+	if err := h.TenantTaxRuleRepo.UpdateById(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) tenantTaxRuleDeleteEndpoint(w http.ResponseWriter, r *http.Request, idStr string, ruleIdStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	m, ok := h.getTenantTaxRuleForAdministrator(w, r, idStr, ruleIdStr)
+	if !ok {
+		return
+	}
+	if err := h.TenantTaxRuleRepo.DeleteById(ctx, m.Id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Function returns the tax rule of the tenant if the user is an administrator,
+// otherwise the error is written to the response and `false` is returned.
+func (h *Controller) getTenantTaxRuleForAdministrator(w http.ResponseWriter, r *http.Request, idStr string, ruleIdStr string) (*models.TenantTaxRule, bool) {
+	ctx := r.Context()
+	role_id := uint64(ctx.Value("user_role_id").(int8))
+
+	// Permission handling - If use is not administrator then error.
+	if role_id != 1 {
+		http.Error(w, "Forbidden - You are not an administrator", http.StatusForbidden)
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	ruleId, err := strconv.ParseUint(ruleIdStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	m, err := h.TenantTaxRuleRepo.GetById(ctx, ruleId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if m == nil || m.TenantId != id {
+		http.Error(w, "Tax rule does not exist", http.StatusNotFound)
+		return nil, false
+	}
+	return m, true
+}
@@ -57,6 +57,19 @@ func (h *Controller) workOrdersListEndpoint(w http.ResponseWriter, r *http.Reque
 	// log.Println("SortOrder", f.SortOrder)
 	// log.Println("SortField", f.SortField)
 
+	// If the user requested an export then stream every row matching our
+	// filter into a spreadsheet instead of returning a single page.
+	if r.FormValue("export") != "" {
+		f.Offset = 0
+		f.Limit = 0
+		h.exportSpreadsheet(w, r, "work-orders", idos.LiteWorkOrderExportColumns, func(write func(row map[string]string) error) error {
+			return h.LiteWorkOrderRepo.StreamByFilter(ctx, &f, func(m *models.LiteWorkOrder) error {
+				return write(idos.NewLiteWorkOrderExportRow(m))
+			})
+		})
+		return
+	}
+
 	arrCh := make(chan []*models.LiteWorkOrder)
 	countCh := make(chan uint64)
 
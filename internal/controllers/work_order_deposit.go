@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/validators"
+)
+
+func (h *Controller) workOrderDepositsListEndpoint(w http.ResponseWriter, r *http.Request, orderIdStr string) {
+	defer r.Body.Close()
+
+	order, ok := h.getWorkOrderForStaff(w, r, orderIdStr)
+	if !ok {
+		return
+	}
+	h.writeWorkOrderDeposits(w, r, order.Id)
+}
+
+func (h *Controller) workOrderDepositCreateEndpoint(w http.ResponseWriter, r *http.Request, orderIdStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+	order, ok := h.getWorkOrderForStaff(w, r, orderIdStr)
+	if !ok {
+		return
+	}
+
+	// Get the user `POST` data from the HTTP request.
+	var postData *idos.WorkOrderDepositIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateWorkOrderDepositCreateFromRequest(postData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	m := &models.WorkOrderDeposit{
+		Uuid:               uuid.NewString(),
+		TenantId:           order.TenantId,
+		OrderId:            order.Id,
+		PaidAt:             postData.PaidAt,
+		DepositMethod:      postData.DepositMethod,
+		PaidTo:             null.IntFrom(int64(postData.PaidTo)),
+		Currency:           models.CurrencyOrDefault(order.Currency),
+		Amount:             postData.Amount,
+		PaidFor:            postData.PaidFor,
+		CreatedTime:        now,
+		CreatedById:        null.IntFrom(int64(user.Id)),
+		CreatedByName:      null.StringFrom(user.Name),
+		CreatedFromIP:      null.NewString(ipAddress, ipAddress != ""),
+		LastModifiedTime:   now,
+		LastModifiedById:   null.IntFrom(int64(user.Id)),
+		LastModifiedByName: null.StringFrom(user.Name),
+		LastModifiedFromIP: null.NewString(ipAddress, ipAddress != ""),
+		State:              models.WorkOrderDepositActiveState,
+	}
+	if err := h.WorkOrderDepositRepo.InsertAndUpdateOrderBalance(ctx, m, newWorkOrderInvoiceRevision(ctx)); err != nil {
+		writeWorkOrderInvoiceEditError(w, err)
+		return
+	}
+
+	h.writeWorkOrderDeposits(w, r, order.Id)
+}
+
+// Function will reverse the deposit instead of deleting it so the history of
+// payments on the work order is kept intact.
+func (h *Controller) workOrderDepositDeleteEndpoint(w http.ResponseWriter, r *http.Request, orderIdStr string, depositIdStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+	order, ok := h.getWorkOrderForStaff(w, r, orderIdStr)
+	if !ok {
+		return
+	}
+
+	depositId, err := strconv.ParseUint(depositIdStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	d, err := h.WorkOrderDepositRepo.GetById(ctx, depositId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if d == nil || d.OrderId != order.Id {
+		http.Error(w, "Deposit does not exist", http.StatusNotFound)
+		return
+	}
+	if d.ReversalOfId.Valid || d.State != models.WorkOrderDepositActiveState {
+		http.Error(w, "{\"id\":\"deposit cannot be reversed\"}", http.StatusBadRequest)
+		return
+	}
+	reversal, err := h.WorkOrderDepositRepo.GetByReversalOfId(ctx, d.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if reversal != nil {
+		http.Error(w, "{\"id\":\"deposit was already reversed\"}", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	m := &models.WorkOrderDeposit{
+		Uuid:               uuid.NewString(),
+		TenantId:           d.TenantId,
+		OrderId:            d.OrderId,
+		PaidAt:             null.TimeFrom(now),
+		DepositMethod:      d.DepositMethod,
+		PaidTo:             d.PaidTo,
+		Currency:           d.Currency,
+		Amount:             -d.Amount,
+		PaidFor:            d.PaidFor,
+		CreatedTime:        now,
+		CreatedById:        null.IntFrom(int64(user.Id)),
+		CreatedByName:      null.StringFrom(user.Name),
+		CreatedFromIP:      null.NewString(ipAddress, ipAddress != ""),
+		LastModifiedTime:   now,
+		LastModifiedById:   null.IntFrom(int64(user.Id)),
+		LastModifiedByName: null.StringFrom(user.Name),
+		LastModifiedFromIP: null.NewString(ipAddress, ipAddress != ""),
+		State:              models.WorkOrderDepositActiveState,
+		ReversalOfId:       null.IntFrom(int64(d.Id)),
+	}
+	if err := h.WorkOrderDepositRepo.InsertAndUpdateOrderBalance(ctx, m, newWorkOrderInvoiceRevision(ctx)); err != nil {
+		writeWorkOrderInvoiceEditError(w, err)
+		return
+	}
+
+	h.writeWorkOrderDeposits(w, r, order.Id)
+}
+
+// Function will lookup the work order in the URL and return false after
+// writing the error response if the user is not staff of the tenant or the
+// work order does not exist.
+func (h *Controller) getWorkOrderForStaff(w http.ResponseWriter, r *http.Request, orderIdStr string) (*models.WorkOrder, bool) {
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+
+	// Permission handling - Only staff can modify work orders.
+	if roleId != 1 && roleId != 2 && roleId != 3 {
+		http.Error(w, "Forbidden - You are not staff", http.StatusForbidden)
+		return nil, false
+	}
+
+	orderId, err := strconv.ParseUint(orderIdStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	order, err := h.WorkOrderRepo.GetById(ctx, orderId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if order == nil || order.TenantId != tenantId {
+		http.Error(w, "Work order does not exist", http.StatusNotFound)
+		return nil, false
+	}
+	return order, true
+}
+
+// Function returns the deposits of the work order along with its freshly
+// computed balances.
+func (h *Controller) writeWorkOrderDeposits(w http.ResponseWriter, r *http.Request, orderId uint64) {
+	ctx := r.Context()
+
+	order, err := h.WorkOrderRepo.GetById(ctx, orderId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	arr, err := h.WorkOrderDepositRepo.ListByOrderId(ctx, orderId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := idos.NewWorkOrderDepositListResponseIDO(order, arr)
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,261 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/validators"
+)
+
+func (h *Controller) workOrderInvoiceLineItemsListEndpoint(w http.ResponseWriter, r *http.Request, orderIdStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	inv, ok := h.getWorkOrderInvoiceForStaff(w, r, orderIdStr)
+	if !ok {
+		return
+	}
+
+	arr, err := h.WorkOrderInvoiceLineItemRepo.ListByInvoiceId(ctx, inv.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := idos.NewWorkOrderInvoiceLineItemListResponseIDO(inv, arr)
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) workOrderInvoiceLineItemCreateEndpoint(w http.ResponseWriter, r *http.Request, orderIdStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	userId := uint64(ctx.Value("user_id").(uint64))
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+	inv, ok := h.getEditableWorkOrderInvoiceForStaff(w, r, orderIdStr)
+	if !ok {
+		return
+	}
+
+	// Get the user `POST` data from the HTTP request.
+	var postData *idos.WorkOrderInvoiceLineItemIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateWorkOrderInvoiceLineItemSaveFromRequest(postData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	// New lines are always appended to the end of the invoice.
+	now := time.Now()
+	m := &models.WorkOrderInvoiceLineItem{
+		Uuid:               uuid.NewString(),
+		TenantId:           inv.TenantId,
+		InvoiceId:          inv.Id,
+		TypeOf:             postData.TypeOf,
+		Quantity:           postData.Quantity,
+		Description:        postData.Description,
+		UnitPrice:          postData.UnitPrice,
+		Amount:             postData.UnitPrice.MulQuantity(postData.Quantity),
+		Notes:              postData.Notes,
+		CreatedTime:        now,
+		CreatedById:        null.IntFrom(int64(userId)),
+		CreatedFromIP:      null.NewString(ipAddress, ipAddress != ""),
+		LastModifiedTime:   now,
+		LastModifiedById:   null.IntFrom(int64(userId)),
+		LastModifiedFromIP: null.NewString(ipAddress, ipAddress != ""),
+	}
+	setWorkOrderInvoiceLastModified(ctx, inv)
+	arr, err := h.WorkOrderInvoiceLineItemRepo.InsertAndRecomputeInvoice(ctx, m, inv, newWorkOrderInvoiceRevision(ctx))
+	if err != nil {
+		writeWorkOrderInvoiceEditError(w, err)
+		return
+	}
+
+	writeWorkOrderInvoiceLineItems(w, inv, arr)
+}
+
+func (h *Controller) workOrderInvoiceLineItemUpdateEndpoint(w http.ResponseWriter, r *http.Request, orderIdStr string, lineIdStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	userId := uint64(ctx.Value("user_id").(uint64))
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+	inv, ok := h.getEditableWorkOrderInvoiceForStaff(w, r, orderIdStr)
+	if !ok {
+		return
+	}
+	m, ok := h.getWorkOrderInvoiceLineItem(w, r, inv, lineIdStr)
+	if !ok {
+		return
+	}
+
+	// Get the user `PUT` data from the HTTP request.
+	var putData *idos.WorkOrderInvoiceLineItemIDO
+	if err := json.NewDecoder(r.Body).Decode(&putData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateWorkOrderInvoiceLineItemSaveFromRequest(putData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	m.TypeOf = putData.TypeOf
+	m.Quantity = putData.Quantity
+	m.Description = putData.Description
+	m.UnitPrice = putData.UnitPrice
+	m.Amount = putData.UnitPrice.MulQuantity(putData.Quantity)
+	m.Notes = putData.Notes
+	m.LastModifiedTime = time.Now()
+	m.LastModifiedById = null.IntFrom(int64(userId))
+	m.LastModifiedFromIP = null.NewString(ipAddress, ipAddress != "")
+	setWorkOrderInvoiceLastModified(ctx, inv)
+	arr, err := h.WorkOrderInvoiceLineItemRepo.UpdateByIdAndRecomputeInvoice(ctx, m, inv, newWorkOrderInvoiceRevision(ctx))
+	if err != nil {
+		writeWorkOrderInvoiceEditError(w, err)
+		return
+	}
+
+	writeWorkOrderInvoiceLineItems(w, inv, arr)
+}
+
+func (h *Controller) workOrderInvoiceLineItemsReorderEndpoint(w http.ResponseWriter, r *http.Request, orderIdStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	inv, ok := h.getEditableWorkOrderInvoiceForStaff(w, r, orderIdStr)
+	if !ok {
+		return
+	}
+
+	// Get the user `PUT` data from the HTTP request.
+	var putData *idos.WorkOrderInvoiceLineItemReorderIDO
+	if err := json.NewDecoder(r.Body).Decode(&putData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	arr, err := h.WorkOrderInvoiceLineItemRepo.ListByInvoiceId(ctx, inv.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	isValid, errStr := validators.ValidateWorkOrderInvoiceLineItemReorderFromRequest(putData, arr)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	setWorkOrderInvoiceLastModified(ctx, inv)
+	arr, err = h.WorkOrderInvoiceLineItemRepo.UpdateSortNumbersAndRecomputeInvoice(ctx, putData.Ids, inv, newWorkOrderInvoiceRevision(ctx))
+	if err != nil {
+		writeWorkOrderInvoiceEditError(w, err)
+		return
+	}
+
+	writeWorkOrderInvoiceLineItems(w, inv, arr)
+}
+
+func (h *Controller) workOrderInvoiceLineItemDeleteEndpoint(w http.ResponseWriter, r *http.Request, orderIdStr string, lineIdStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	inv, ok := h.getEditableWorkOrderInvoiceForStaff(w, r, orderIdStr)
+	if !ok {
+		return
+	}
+	m, ok := h.getWorkOrderInvoiceLineItem(w, r, inv, lineIdStr)
+	if !ok {
+		return
+	}
+
+	setWorkOrderInvoiceLastModified(ctx, inv)
+	arr, err := h.WorkOrderInvoiceLineItemRepo.DeleteByIdAndRecomputeInvoice(ctx, m.Id, inv, newWorkOrderInvoiceRevision(ctx))
+	if err != nil {
+		writeWorkOrderInvoiceEditError(w, err)
+		return
+	}
+
+	writeWorkOrderInvoiceLineItems(w, inv, arr)
+}
+
+// Function will lookup the invoice of the work order in the URL and return
+// false after writing the error response if the user is not staff of the
+// tenant or the invoice does not exist.
+func (h *Controller) getWorkOrderInvoiceForStaff(w http.ResponseWriter, r *http.Request, orderIdStr string) (*models.WorkOrderInvoice, bool) {
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+
+	// Permission handling - Only staff can modify invoices.
+	if roleId != 1 && roleId != 2 && roleId != 3 {
+		http.Error(w, "Forbidden - You are not staff", http.StatusForbidden)
+		return nil, false
+	}
+
+	orderId, err := strconv.ParseUint(orderIdStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	inv, err := h.WorkOrderInvoiceRepo.GetByOrderId(ctx, orderId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if inv == nil || inv.TenantId != tenantId {
+		http.Error(w, "Invoice does not exist", http.StatusNotFound)
+		return nil, false
+	}
+	return inv, true
+}
+
+func (h *Controller) getWorkOrderInvoiceLineItem(w http.ResponseWriter, r *http.Request, inv *models.WorkOrderInvoice, lineIdStr string) (*models.WorkOrderInvoiceLineItem, bool) {
+	lineId, err := strconv.ParseUint(lineIdStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	m, err := h.WorkOrderInvoiceLineItemRepo.GetById(r.Context(), lineId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if m == nil || m.InvoiceId != inv.Id {
+		http.Error(w, "Line item does not exist", http.StatusNotFound)
+		return nil, false
+	}
+	return m, true
+}
+
+// Function sets the user who changed the invoice, the totals of the invoice
+// are recomputed by the repository along with the change.
+func setWorkOrderInvoiceLastModified(ctx context.Context, inv *models.WorkOrderInvoice) {
+	user := ctx.Value("user").(*models.User)
+	inv.LastModifiedTime = time.Now()
+	inv.LastModifiedById = user.Id
+	inv.LastModifiedByName = null.StringFrom(user.Name)
+}
+
+// Function returns the lines of the invoice along with the new totals.
+func writeWorkOrderInvoiceLineItems(w http.ResponseWriter, inv *models.WorkOrderInvoice, arr []*models.WorkOrderInvoiceLineItem) {
+	res := idos.NewWorkOrderInvoiceLineItemListResponseIDO(inv, arr)
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/reports"
+	"github.com/over55/workery-server/internal/utils"
+)
+
+// Function will render the invoice of the work order as a PDF. If the `store`
+// query parameter is set to `true` then the generated file is also saved as
+// a private file attached to the work order.
+func (h *Controller) workOrderInvoicePDFEndpoint(w http.ResponseWriter, r *http.Request, orderIdStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	inv, ok := h.getWorkOrderInvoiceForStaff(w, r, orderIdStr)
+	if !ok {
+		return
+	}
+	store := r.FormValue("store") == "true"
+	if store && h.S3Client == nil {
+		http.Error(w, "File storage is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	order, err := h.WorkOrderRepo.GetById(ctx, inv.OrderId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var currency string
+	if order != nil {
+		currency = order.Currency
+	}
+	tenant, err := h.TenantRepo.GetById(ctx, inv.TenantId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	tpl, err := h.TenantInvoiceTemplateRepo.GetByTenantId(ctx, inv.TenantId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	lines, err := h.WorkOrderInvoiceLineItemRepo.ListByInvoiceId(ctx, inv.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	loc, err := utils.GetTimezoneLocation(tenant.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	d := &reports.WorkOrderInvoicePDF{
+		Tenant:    tenant,
+		Template:  tpl,
+		Logo:      h.getTenantInvoiceLogo(tpl),
+		Invoice:   inv,
+		LineItems: lines,
+		Location:  loc,
+		Currency:  models.CurrencyOrDefault(currency),
+	}
+	buf := new(bytes.Buffer)
+	if err := reports.RenderWorkOrderInvoicePDF(buf, d); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	filename := "invoice-" + strconv.FormatUint(inv.OrderId, 10) + ".pdf"
+	if store {
+		if err := h.storeWorkOrderInvoicePDF(ctx, inv, filename, buf.Bytes()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", "inline; filename=\""+filename+"\"")
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	buf.WriteTo(w)
+}
+
+// Function returns the decoded logo of the template or nil if the tenant
+// does not have one or it could not be loaded, a missing logo should never
+// prevent the invoice from being generated.
+func (h *Controller) getTenantInvoiceLogo(tpl *models.TenantInvoiceTemplate) image.Image {
+	if tpl == nil || tpl.LogoS3Key == "" || h.S3Client == nil {
+		return nil
+	}
+	bin, err := utils.GetS3ObjBin(h.S3Client, h.S3BucketName, tpl.LogoS3Key)
+	if err != nil {
+		log.Println("WARNING: getTenantInvoiceLogo|GetS3ObjBin|err:", err.Error())
+		return nil
+	}
+	defer bin.Close()
+	img, _, err := image.Decode(bin)
+	if err != nil {
+		log.Println("WARNING: getTenantInvoiceLogo|Decode|err:", err.Error())
+		return nil
+	}
+	return img
+}
+
+func (h *Controller) storeWorkOrderInvoicePDF(ctx context.Context, inv *models.WorkOrderInvoice, filename string, bin []byte) error {
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+	fileUuid := uuid.NewString()
+
+	// Every generated invoice is kept so the key is made unique with the UUID.
+	s3Key := "tenant/" + strconv.FormatUint(inv.TenantId, 10) + "/private/uploads/" + fileUuid + "-" + filename
+	if err := utils.UploadBinToS3(h.S3Client, h.S3BucketName, s3Key, string(bin), "private"); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	m := &models.PrivateFile{
+		Uuid:               fileUuid,
+		TenantId:           inv.TenantId,
+		S3Key:              s3Key,
+		Title:              filename,
+		Description:        "Generated invoice for work order #" + strconv.FormatUint(inv.OrderId, 10),
+		CreatedTime:        now,
+		CreatedFromIP:      null.StringFrom(ipAddress),
+		CreatedById:        null.IntFrom(int64(user.Id)),
+		CreatedByName:      null.StringFrom(user.Name),
+		LastModifiedTime:   now,
+		LastModifiedById:   null.IntFrom(int64(user.Id)),
+		LastModifiedByName: null.StringFrom(user.Name),
+		LastModifiedFromIP: null.StringFrom(ipAddress),
+		WorkOrderId:        null.IntFrom(int64(inv.OrderId)),
+		State:              1,
+	}
+	return h.PrivateFileRepo.Insert(ctx, m)
+}
@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+)
+
+func (h *Controller) workOrderInvoiceRevisionsListEndpoint(w http.ResponseWriter, r *http.Request, orderIdStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	inv, ok := h.getWorkOrderInvoiceForStaff(w, r, orderIdStr)
+	if !ok {
+		return
+	}
+
+	current, err := h.getWorkOrderInvoiceSnapshot(ctx, inv)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	arr, err := h.WorkOrderInvoiceRevisionRepo.ListByInvoiceId(ctx, inv.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := idos.NewWorkOrderInvoiceRevisionListResponseIDO(inv, current, arr)
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) workOrderInvoiceRevisionsDiffEndpoint(w http.ResponseWriter, r *http.Request, orderIdStr string) {
+	defer r.Body.Close()
+
+	inv, ok := h.getWorkOrderInvoiceForStaff(w, r, orderIdStr)
+	if !ok {
+		return
+	}
+
+	// The `to` revision defaults to the current version of the invoice.
+	fromVersion, err := strconv.ParseInt(r.FormValue("from"), 10, 16)
+	if err != nil {
+		http.Error(w, `{"from":"invalid value"}`, http.StatusBadRequest)
+		return
+	}
+	toVersion := int64(inv.RevisionVersion)
+	if s := r.FormValue("to"); s != "" {
+		toVersion, err = strconv.ParseInt(s, 10, 16)
+		if err != nil {
+			http.Error(w, `{"to":"invalid value"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	from, ok := h.getWorkOrderInvoiceRevisionSnapshot(w, r, inv, int16(fromVersion))
+	if !ok {
+		return
+	}
+	to, ok := h.getWorkOrderInvoiceRevisionSnapshot(w, r, inv, int16(toVersion))
+	if !ok {
+		return
+	}
+
+	res, err := models.DiffWorkOrderInvoiceSnapshots(int16(fromVersion), from, int16(toVersion), to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) workOrderInvoiceReopenEndpoint(w http.ResponseWriter, r *http.Request, orderIdStr string) {
+	h.updateWorkOrderInvoiceLock(w, r, orderIdStr, true)
+}
+
+func (h *Controller) workOrderInvoiceLockEndpoint(w http.ResponseWriter, r *http.Request, orderIdStr string) {
+	h.updateWorkOrderInvoiceLock(w, r, orderIdStr, false)
+}
+
+// Function reopens the paid invoice so it can be changed again or locks the
+// reopened invoice, only managers are allowed to do this.
+func (h *Controller) updateWorkOrderInvoiceLock(w http.ResponseWriter, r *http.Request, orderIdStr string, reopen bool) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	roleId := ctx.Value("user_role_id").(int8)
+	user := ctx.Value("user").(*models.User)
+
+	// Permission handling - Only management can reopen invoices.
+	if roleId != 1 && roleId != 2 {
+		http.Error(w, "Forbidden - You are not a manager", http.StatusForbidden)
+		return
+	}
+
+	inv, ok := h.getWorkOrderInvoiceForStaff(w, r, orderIdStr)
+	if !ok {
+		return
+	}
+	if !inv.PaymentDate.Valid {
+		http.Error(w, `{"payment_date":"invoice was not paid"}`, http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	if reopen {
+		inv.ReopenedTime = null.TimeFrom(now)
+		inv.ReopenedById = null.IntFrom(int64(user.Id))
+	} else {
+		inv.ReopenedTime = null.Time{}
+		inv.ReopenedById = null.Int{}
+	}
+	inv.LastModifiedTime = now
+	inv.LastModifiedById = user.Id
+	inv.LastModifiedByName = null.StringFrom(user.Name)
+	if err := h.WorkOrderInvoiceRepo.UpdateReopenedById(ctx, inv); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.workOrderInvoiceRevisionsListEndpoint(w, r, orderIdStr)
+}
+
+// Function is the same as `getWorkOrderInvoiceForStaff` but also writes the
+// error response if the invoice is locked since it was paid.
+func (h *Controller) getEditableWorkOrderInvoiceForStaff(w http.ResponseWriter, r *http.Request, orderIdStr string) (*models.WorkOrderInvoice, bool) {
+	inv, ok := h.getWorkOrderInvoiceForStaff(w, r, orderIdStr)
+	if !ok {
+		return nil, false
+	}
+	if inv.IsLocked() {
+		http.Error(w, "Invoice is locked since it was paid, a manager must reopen it first", http.StatusConflict)
+		return nil, false
+	}
+	return inv, true
+}
+
+// Function writes the error of changing the invoice, the invoice could have
+// been locked since it was read.
+func writeWorkOrderInvoiceEditError(w http.ResponseWriter, err error) {
+	if err == models.ErrWorkOrderInvoiceLocked {
+		http.Error(w, "Invoice is locked since it was paid, a manager must reopen it first", http.StatusConflict)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// Function returns the revision which keeps the current version of the
+// invoice before it gets changed, the repository fills in the invoice and the
+// snapshot in the same transaction as the change.
+func newWorkOrderInvoiceRevision(ctx context.Context) *models.WorkOrderInvoiceRevision {
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+
+	return &models.WorkOrderInvoiceRevision{
+		CreatedTime:   time.Now(),
+		CreatedById:   null.IntFrom(int64(user.Id)),
+		CreatedByName: null.StringFrom(user.Name),
+		CreatedFromIP: null.NewString(ipAddress, ipAddress != ""),
+	}
+}
+
+func (h *Controller) getWorkOrderInvoiceSnapshot(ctx context.Context, inv *models.WorkOrderInvoice) (*models.WorkOrderInvoiceSnapshot, error) {
+	arr, err := h.WorkOrderInvoiceLineItemRepo.ListByInvoiceId(ctx, inv.Id)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewWorkOrderInvoiceSnapshot(inv, arr), nil
+}
+
+// Function returns the snapshot of the revision of the invoice, the current
+// revision version returns the invoice as it is right now.
+func (h *Controller) getWorkOrderInvoiceRevisionSnapshot(w http.ResponseWriter, r *http.Request, inv *models.WorkOrderInvoice, version int16) (*models.WorkOrderInvoiceSnapshot, bool) {
+	ctx := r.Context()
+	if version == inv.RevisionVersion {
+		snapshot, err := h.getWorkOrderInvoiceSnapshot(ctx, inv)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return nil, false
+		}
+		return snapshot, true
+	}
+
+	m, err := h.WorkOrderInvoiceRevisionRepo.GetByInvoiceIdAndRevisionVersion(ctx, inv.Id, version)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if m == nil {
+		http.Error(w, "Revision "+strconv.Itoa(int(version))+" does not exist", http.StatusNotFound)
+		return nil, false
+	}
+	return m.Snapshot, true
+}
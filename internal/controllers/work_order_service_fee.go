@@ -1,18 +1,21 @@
 package controllers
 
 import (
-	// "encoding/json"
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
-	// "time"
 	"strconv"
+	"strings"
+	"time"
 
-	// "github.com/google/uuid"
+	"github.com/google/uuid"
 	null "gopkg.in/guregu/null.v4"
 
 	"github.com/over55/workery-server/internal/idos"
 	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/utils"
+	"github.com/over55/workery-server/internal/validators"
 )
 
 func (h *Controller) workOrderServiceFeesListEndpoint(w http.ResponseWriter, r *http.Request) {
@@ -88,3 +91,289 @@ func (h *Controller) workOrderServiceFeesListEndpoint(w http.ResponseWriter, r *
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
+
+func (h *Controller) workOrderServiceFeeCreateEndpoint(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	role_id := uint64(ctx.Value("user_role_id").(int8))
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+
+	// Permission handling - If use is not administrator then error.
+	if role_id != 1 {
+		http.Error(w, "Forbidden - You are not an administrator", http.StatusForbidden)
+		return
+	}
+
+	// Get the user `POST` data from the HTTP request.
+	var postData *idos.WorkOrderServiceFeeIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateWorkOrderServiceFeeSaveFromRequest(postData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	effectiveFrom, ok := h.parseWorkOrderServiceFeeEffectiveFrom(w, r, postData.EffectiveFrom, now)
+	if !ok {
+		return
+	}
+
+	m := &models.WorkOrderServiceFee{
+		Uuid:               uuid.NewString(),
+		TenantId:           tenantId,
+		Title:              strings.TrimSpace(postData.Title),
+		Description:        postData.Description,
+		CreatedTime:        now,
+		CreatedById:        null.IntFrom(int64(user.Id)),
+		CreatedByName:      null.StringFrom(user.Name),
+		CreatedFromIP:      ipAddress,
+		LastModifiedTime:   now,
+		LastModifiedById:   null.IntFrom(int64(user.Id)),
+		LastModifiedByName: null.StringFrom(user.Name),
+		LastModifiedFromIP: ipAddress,
+		State:              models.WorkOrderServiceFeeActiveState,
+	}
+	if !effectiveFrom.After(now) {
+		m.Percentage = postData.Percentage
+	}
+	rate := &models.WorkOrderServiceFeeRate{
+		TenantId:      tenantId,
+		Percentage:    postData.Percentage,
+		EffectiveFrom: effectiveFrom,
+		CreatedTime:   now,
+		CreatedById:   null.IntFrom(int64(user.Id)),
+		CreatedByName: null.StringFrom(user.Name),
+		CreatedFromIP: ipAddress,
+	}
+	if err := h.WorkOrderServiceFeeRepo.InsertWithRate(ctx, m, rate); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := idos.NewWorkOrderServiceFeeDetailResponseIDO(m, []*models.WorkOrderServiceFeeRate{rate})
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) workOrderServiceFeeGetEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	m, ok := h.getWorkOrderServiceFeeForAdministrator(w, r, idStr)
+	if !ok {
+		return
+	}
+	h.writeWorkOrderServiceFee(ctx, w, m)
+}
+
+func (h *Controller) workOrderServiceFeeUpdateEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+
+	m, ok := h.getWorkOrderServiceFeeForAdministrator(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	// Get the user `PUT` data from the HTTP request.
+	var putData *idos.WorkOrderServiceFeeIDO
+	if err := json.NewDecoder(r.Body).Decode(&putData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateWorkOrderServiceFeeSaveFromRequest(putData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	effectiveFrom, ok := h.parseWorkOrderServiceFeeEffectiveFrom(w, r, putData.EffectiveFrom, now)
+	if !ok {
+		return
+	}
+
+	// DEVELOPERS NOTE:
+	// A new percentage never replaces the old one, instead it is added to the
+	// history starting at its effective date so work orders which were
+	// invoiced before then keep the percentage they were charged.
+	var rate *models.WorkOrderServiceFeeRate
+	if putData.Percentage != m.Percentage || putData.EffectiveFrom != "" {
+		rate = &models.WorkOrderServiceFeeRate{
+			TenantId:      m.TenantId,
+			Percentage:    putData.Percentage,
+			EffectiveFrom: effectiveFrom,
+			CreatedTime:   now,
+			CreatedById:   null.IntFrom(int64(user.Id)),
+			CreatedByName: null.StringFrom(user.Name),
+			CreatedFromIP: ipAddress,
+		}
+		if !effectiveFrom.After(now) {
+			m.Percentage = putData.Percentage
+		}
+	}
+	m.Title = strings.TrimSpace(putData.Title)
+	m.Description = putData.Description
+	m.LastModifiedTime = now
+	m.LastModifiedById = null.IntFrom(int64(user.Id))
+	m.LastModifiedByName = null.StringFrom(user.Name)
+	m.LastModifiedFromIP = ipAddress
+	if err := h.WorkOrderServiceFeeRepo.UpdateWithRate(ctx, m, rate); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeWorkOrderServiceFee(ctx, w, m)
+}
+
+func (h *Controller) workOrderServiceFeeArchiveEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	// Extract the session details from our "Session" middleware.
+	ctx := r.Context()
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+
+	m, ok := h.getWorkOrderServiceFeeForAdministrator(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	// Archived service fees are kept since historical work orders still
+	// reference them.
+	m.State = models.WorkOrderServiceFeeInactiveState
+	m.LastModifiedTime = time.Now()
+	m.LastModifiedById = null.IntFrom(int64(user.Id))
+	m.LastModifiedByName = null.StringFrom(user.Name)
+	m.LastModifiedFromIP = ipAddress
+	if err := h.WorkOrderServiceFeeRepo.UpdateById(ctx, m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeWorkOrderServiceFee(ctx, w, m)
+}
+
+func (h *Controller) workOrderServiceFeePreviewChangeEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	m, ok := h.getWorkOrderServiceFeeForAdministrator(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	// Get the user `POST` data from the HTTP request.
+	var postData *idos.WorkOrderServiceFeeChangePreviewIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateWorkOrderServiceFeeChangePreviewFromRequest(postData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+	effectiveFrom, ok := h.parseWorkOrderServiceFeeEffectiveFrom(w, r, postData.EffectiveFrom, time.Now())
+	if !ok {
+		return
+	}
+
+	res, err := h.WorkOrderServiceFeeRepo.PreviewChangeById(ctx, m.Id, postData.Percentage, effectiveFrom)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function returns the service fee of the tenant if the user is an
+// administrator, otherwise the error is written to the response and `false`
+// is returned.
+func (h *Controller) getWorkOrderServiceFeeForAdministrator(w http.ResponseWriter, r *http.Request, idStr string) (*models.WorkOrderServiceFee, bool) {
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	role_id := uint64(ctx.Value("user_role_id").(int8))
+
+	// Permission handling - If use is not administrator then error.
+	if role_id != 1 {
+		http.Error(w, "Forbidden - You are not an administrator", http.StatusForbidden)
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	m, err := h.WorkOrderServiceFeeRepo.GetById(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if m == nil || m.TenantId != tenantId {
+		http.Error(w, "Service fee does not exist", http.StatusNotFound)
+		return nil, false
+	}
+	return m, true
+}
+
+// Function parses the `YYYY-MM-DD` effective date in the timezone of the
+// tenant. Percentages cannot take effect in the past since that would change
+// the fee of work orders which were already invoiced, so an empty value or
+// today's date take effect right away.
+func (h *Controller) parseWorkOrderServiceFeeEffectiveFrom(w http.ResponseWriter, r *http.Request, s string, now time.Time) (time.Time, bool) {
+	if s == "" {
+		return now.UTC(), true
+	}
+
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	tenant, err := h.TenantRepo.GetById(ctx, tenantId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return time.Time{}, false
+	}
+	loc, err := utils.GetTimezoneLocation(tenant.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", s, loc)
+	if err != nil {
+		http.Error(w, `{"effective_from":"invalid date"}`, http.StatusBadRequest)
+		return time.Time{}, false
+	}
+	if t.AddDate(0, 0, 1).Before(now) {
+		http.Error(w, `{"effective_from":"cannot be in the past"}`, http.StatusBadRequest)
+		return time.Time{}, false
+	}
+	if t.Before(now) {
+		t = now
+	}
+	return t.UTC(), true
+}
+
+func (h *Controller) writeWorkOrderServiceFee(ctx context.Context, w http.ResponseWriter, m *models.WorkOrderServiceFee) {
+	rates, err := h.WorkOrderServiceFeeRepo.ListRatesById(ctx, m.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	res := idos.NewWorkOrderServiceFeeDetailResponseIDO(m, rates)
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
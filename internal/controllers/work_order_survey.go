@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/utils"
+	"github.com/over55/workery-server/internal/validators"
+)
+
+func (h *Controller) workOrderSurveysListEndpoint(w http.ResponseWriter, r *http.Request, orderIdStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	order, ok := h.getWorkOrderForStaff(w, r, orderIdStr)
+	if !ok {
+		return
+	}
+
+	arr, err := h.WorkOrderSurveyRepo.ListByOrderId(ctx, order.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := idos.NewWorkOrderSurveyListResponseIDO(order, arr)
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function records the customer satisfaction survey of a completed work
+// order, if the work order is waiting on its survey task then the task gets
+// closed as well.
+func (h *Controller) workOrderSurveyCreateEndpoint(w http.ResponseWriter, r *http.Request, orderIdStr string) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	order, ok := h.getWorkOrderForStaff(w, r, orderIdStr)
+	if !ok {
+		return
+	}
+	if order.State != models.WorkOrderCompletedButUnpaidState && order.State != models.WorkOrderCompletedAndPaidState {
+		http.Error(w, "Work order is not completed", http.StatusConflict)
+		return
+	}
+
+	var postData *idos.WorkOrderSurveyIDO
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isValid, errStr := validators.ValidateWorkOrderSurveySaveFromRequest(postData)
+	if isValid == false {
+		http.Error(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	var task *models.TaskItem
+	if order.LatestPendingTaskId.Valid {
+		t, err := h.TaskItemRepo.GetById(ctx, uint64(order.LatestPendingTaskId.Int64))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if t != nil && !t.IsClosed && t.TypeOf == models.TaskFollowUpCustomerSurveyTypeOf {
+			task = t
+		}
+	}
+
+	m, ok := h.createWorkOrderSurvey(w, r, order, postData, task)
+	if !ok {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function returns the satisfaction summary of every associate of the
+// tenant. The optional `from` and `to` parameters are inclusive dates, ex:
+// `2021-01-31`, in the timezone of the tenant.
+func (h *Controller) associatesSatisfactionListEndpoint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantId := uint64(ctx.Value("user_tenant_id").(uint64))
+	roleId := ctx.Value("user_role_id").(int8)
+
+	// Permission handling - Only staff can view the satisfaction report.
+	if roleId != 1 && roleId != 2 && roleId != 3 {
+		http.Error(w, "Forbidden - You are not staff", http.StatusForbidden)
+		return
+	}
+
+	from, to, ok := h.parseSatisfactionDates(w, r, tenantId)
+	if !ok {
+		return
+	}
+	arr, err := h.WorkOrderSurveyRepo.ListSatisfactionByTenantId(ctx, tenantId, from.Time, to.Time)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := idos.NewAssociateSatisfactionListResponseIDO(from, to, arr)
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function returns the score of the associate along with the surveys which
+// were submitted for the associate, the same `from` and `to` parameters as
+// the list are supported.
+func (h *Controller) associateSatisfactionEndpoint(w http.ResponseWriter, r *http.Request, idStr string) {
+	ctx := r.Context()
+	a, ok := h.getAssociateForStaff(w, r, idStr)
+	if !ok {
+		return
+	}
+
+	from, to, ok := h.parseSatisfactionDates(w, r, a.TenantId)
+	if !ok {
+		return
+	}
+	arr, err := h.WorkOrderSurveyRepo.ListByAssociateId(ctx, a.Id, from.Time, to.Time)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := idos.NewAssociateSatisfactionResponseIDO(a, from, to, arr)
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Controller) parseSatisfactionDates(w http.ResponseWriter, r *http.Request, tenantId uint64) (null.Time, null.Time, bool) {
+	tenant, err := h.TenantRepo.GetById(r.Context(), tenantId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return null.Time{}, null.Time{}, false
+	}
+	loc := time.UTC
+	if tenant != nil {
+		if l, err := utils.GetTimezoneLocation(tenant.Timezone); err == nil {
+			loc = l
+		}
+	}
+
+	// The surveys are stored in UTC and the `to` date is inclusive.
+	e := make(map[string]string)
+	from := parseDateFormValue(r, "from", loc, false, e)
+	to := parseDateFormValue(r, "to", loc, true, e)
+	if len(e) != 0 {
+		b, _ := json.Marshal(e)
+		http.Error(w, string(b), http.StatusBadRequest)
+		return null.Time{}, null.Time{}, false
+	}
+	return from, to, true
+}
+
+// Function saves the survey on the work order, keeps it in the history of
+// the work order and recomputes the score of the associate. The optional
+// task is the open survey task which gets closed with the survey.
+func (h *Controller) createWorkOrderSurvey(w http.ResponseWriter, r *http.Request, order *models.WorkOrder, postData *idos.WorkOrderSurveyIDO, task *models.TaskItem) (*models.WorkOrderSurvey, bool) {
+	ctx := r.Context()
+	user := ctx.Value("user").(*models.User)
+	ipAddress, _ := ctx.Value("IPAddress").(string)
+	now := time.Now()
+
+	m := &models.WorkOrderSurvey{
+		Uuid:               uuid.NewString(),
+		TenantId:           order.TenantId,
+		OrderId:            order.Id,
+		AssociateId:        order.AssociateId,
+		WasSurveyConducted: postData.WasSurveyConducted,
+		CreatedTime:        now,
+		CreatedById:        null.IntFrom(int64(user.Id)),
+		CreatedByName:      null.StringFrom(user.Name),
+		CreatedFromIP:      null.StringFrom(ipAddress),
+	}
+	if postData.WasSurveyConducted {
+		m.WasJobSatisfactory = postData.WasJobSatisfactory
+		m.WasJobFinishedOnTimeAndOnBudget = postData.WasJobFinishedOnTimeAndOnBudget
+		m.WasAssociatePunctual = postData.WasAssociatePunctual
+		m.WasAssociateProfessional = postData.WasAssociateProfessional
+		m.WouldCustomerReferOurOrganization = postData.WouldCustomerReferOurOrganization
+	} else {
+		m.NoSurveyConductedReason = postData.NoSurveyConductedReason
+		m.NoSurveyConductedReasonOther = null.NewString(postData.NoSurveyConductedReasonOther, postData.NoSurveyConductedReasonOther != "")
+	}
+	m.Score = m.ComputeScore()
+	m.ApplyTo(order)
+
+	if task != nil {
+		h.markTaskItemClosed(r, task, 0, "")
+	}
+	h.markWorkOrderModified(r, order)
+
+	if err := h.WorkOrderSurveyRepo.Create(ctx, m, order, task); err != nil {
+		writeTaskItemCloseError(w, err)
+		return nil, false
+	}
+	return m, true
+}
@@ -0,0 +1,20 @@
+package idos
+
+import (
+	"github.com/over55/workery-server/internal/models"
+)
+
+type AccountingExportListResponseIDO struct {
+	Count   uint64                     `json:"count"`
+	Results []*models.AccountingExport `json:"results"`
+}
+
+func NewAccountingExportListResponseIDO(arr []*models.AccountingExport, count uint64) *AccountingExportListResponseIDO {
+	if arr == nil { // Always return an array and not `null`.
+		arr = []*models.AccountingExport{}
+	}
+	return &AccountingExportListResponseIDO{
+		Count:   count,
+		Results: arr,
+	}
+}
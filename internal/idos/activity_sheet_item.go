@@ -0,0 +1,37 @@
+package idos
+
+import (
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+type ActivitySheetItemListResponseIDO struct {
+	NextId  uint64                      `json:"next_id,omitempty"`
+	Count   uint64                      `json:"count"`
+	Results []*models.ActivitySheetItem `json:"results"`
+}
+
+func NewActivitySheetItemListResponseIDO(arr []*models.ActivitySheetItem, count uint64) *ActivitySheetItemListResponseIDO {
+	// Calculate next id.
+	var nextId uint64
+	if len(arr) > 0 {
+		lastRecord := arr[len(arr)-1]
+		nextId = lastRecord.Id
+	}
+
+	res := &ActivitySheetItemListResponseIDO{ // Return through HTTP.
+		Count:   count,
+		Results: arr,
+		NextId:  nextId,
+	}
+
+	return res
+}
+
+type ActivitySheetItemIDO struct {
+	AssociateId uint64   `json:"associate_id"`
+	OrderId     null.Int `json:"order_id"`
+	State       int8     `json:"state"`
+	Comment     string   `json:"comment"`
+}
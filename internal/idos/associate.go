@@ -38,3 +38,101 @@ func NewLiteAssociateListResponseIDO(arr []*models.LiteAssociate, count uint64)
 
 	return res
 }
+
+// AssociateContactIDO is the contact details of the associate, the email is
+// also the login of the user account of the associate.
+type AssociateContactIDO struct {
+	TypeOf                  int8   `json:"type_of"`
+	OrganizationName        string `json:"organization_name"`
+	OrganizationTypeOf      int8   `json:"organization_type_of"`
+	GivenName               string `json:"given_name"`
+	MiddleName              string `json:"middle_name"`
+	LastName                string `json:"last_name"`
+	Email                   string `json:"email"`
+	IsOkToEmail             bool   `json:"is_ok_to_email"`
+	Telephone               string `json:"telephone"`
+	TelephoneTypeOf         int8   `json:"telephone_type_of"`
+	TelephoneExtension      string `json:"telephone_extension"`
+	OtherTelephone          string `json:"other_telephone"`
+	OtherTelephoneTypeOf    int8   `json:"other_telephone_type_of"`
+	OtherTelephoneExtension string `json:"other_telephone_extension"`
+	IsOkToText              bool   `json:"is_ok_to_text"`
+}
+
+type AssociateAddressIDO struct {
+	AddressCountry      string `json:"address_country"`
+	AddressRegion       string `json:"address_region"`
+	AddressLocality     string `json:"address_locality"`
+	PostOfficeBoxNumber string `json:"post_office_box_number"`
+	PostalCode          string `json:"postal_code"`
+	StreetAddress       string `json:"street_address"`
+	StreetAddressExtra  string `json:"street_address_extra"`
+}
+
+// AssociateMetricsIDO is the details of the associate which are used for
+// matching them with jobs and tracking their compliance.
+type AssociateMetricsIDO struct {
+	Birthdate                            null.Time `json:"birthdate"`
+	JoinDate                             null.Time `json:"join_date"`
+	Gender                               string    `json:"gender"`
+	Nationality                          string    `json:"nationality"`
+	TaxId                                string    `json:"tax_id"`
+	HowHearId                            uint64    `json:"how_hear_id"`
+	HowHearOther                         string    `json:"how_hear_other"`
+	Business                             string    `json:"business"`
+	HourlySalaryDesired                  int8      `json:"hourly_salary_desired"`
+	LimitSpecial                         string    `json:"limit_special"`
+	DuesDate                             null.Time `json:"dues_date"`
+	CommercialInsuranceExpiryDate        null.Time `json:"commercial_insurance_expiry_date"`
+	AutoInsuranceExpiryDate              null.Time `json:"auto_insurance_expiry_date"`
+	WsibNumber                           string    `json:"wsib_number"`
+	WsibInsuranceDate                    null.Time `json:"wsib_insurance_date"`
+	PoliceCheck                          null.Time `json:"police_check"`
+	DriversLicenseClass                  string    `json:"drivers_license_class"`
+	ServiceFeeId                         uint64    `json:"service_fee_id"`
+	EmergencyContactName                 string    `json:"emergency_contact_name"`
+	EmergencyContactRelationship         string    `json:"emergency_contact_relationship"`
+	EmergencyContactTelephone            string    `json:"emergency_contact_telephone"`
+	EmergencyContactAlternativeTelephone string    `json:"emergency_contact_alternative_telephone"`
+}
+
+// AssociateIDO is used to create and update the associate, the password is
+// only used when creating the associate.
+type AssociateIDO struct {
+	AssociateContactIDO
+	AssociateAddressIDO
+	AssociateMetricsIDO
+	Password         string `json:"password"`
+	PasswordRepeated string `json:"password_repeated"`
+}
+
+type AssociateAccountIDO struct {
+	Password         string `json:"password"`
+	PasswordRepeated string `json:"password_repeated"`
+}
+
+type AssociateUpgradeIDO struct {
+	OrganizationName   string `json:"organization_name"`
+	OrganizationTypeOf int8   `json:"organization_type_of"`
+}
+
+type AssociateArchiveIDO struct {
+	DeactivationReason      int8   `json:"deactivation_reason"`
+	DeactivationReasonOther string `json:"deactivation_reason_other"`
+}
+
+type AssociateSkillSetIDO struct {
+	SkillSetId uint64 `json:"skill_set_id"`
+}
+
+type AssociateTagIDO struct {
+	TagId uint64 `json:"tag_id"`
+}
+
+type AssociateVehicleTypeIDO struct {
+	VehicleTypeId uint64 `json:"vehicle_type_id"`
+}
+
+type AssociateInsuranceRequirementIDO struct {
+	InsuranceRequirementId uint64 `json:"insurance_requirement_id"`
+}
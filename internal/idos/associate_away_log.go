@@ -38,3 +38,37 @@ func NewLiteAssociateAwayLogListResponseIDO(arr []*models.LiteAssociateAwayLog,
 
 	return res
 }
+
+// AssociateAwayLogIDO is the data to create or update an away log, the
+// `until_date` is ignored when the associate is away until further notice.
+type AssociateAwayLogIDO struct {
+	AssociateId        uint64      `json:"associate_id"`
+	Reason             int8        `json:"reason"`
+	ReasonOther        null.String `json:"reason_other"`
+	UntilFurtherNotice bool        `json:"until_further_notice"`
+	UntilDate          null.Time   `json:"until_date"`
+	StartDate          null.Time   `json:"start_date"`
+}
+
+// AssociateAwayLogResponseIDO returns the away log along with the open work
+// orders and pending tasks of the associate which need to be reassigned
+// while the associate is away.
+type AssociateAwayLogResponseIDO struct {
+	AwayLog    *models.AssociateAwayLog `json:"away_log"`
+	WorkOrders []*models.LiteWorkOrder  `json:"work_orders"`
+	TaskItems  []*models.LiteTaskItem   `json:"task_items"`
+}
+
+func NewAssociateAwayLogResponseIDO(m *models.AssociateAwayLog, workOrders []*models.LiteWorkOrder, taskItems []*models.LiteTaskItem) *AssociateAwayLogResponseIDO {
+	if workOrders == nil { // Always return an array and not `null`.
+		workOrders = []*models.LiteWorkOrder{}
+	}
+	if taskItems == nil {
+		taskItems = []*models.LiteTaskItem{}
+	}
+	return &AssociateAwayLogResponseIDO{
+		AwayLog:    m,
+		WorkOrders: workOrders,
+		TaskItems:  taskItems,
+	}
+}
@@ -0,0 +1,25 @@
+package idos
+
+import (
+	"time"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+// AssociateCalendarTokenResponseIDO returns the token of the calendar feed,
+// the token is only returned once when it is created.
+type AssociateCalendarTokenResponseIDO struct {
+	AssociateId uint64    `json:"associate_id"`
+	Token       string    `json:"token"`
+	FeedUrl     string    `json:"feed_url"`
+	CreatedTime time.Time `json:"created_time"`
+}
+
+func NewAssociateCalendarTokenResponseIDO(m *models.AssociateCalendarToken, token string) *AssociateCalendarTokenResponseIDO {
+	return &AssociateCalendarTokenResponseIDO{
+		AssociateId: m.AssociateId,
+		Token:       token,
+		FeedUrl:     "/api/v1/calendar-feed/" + token + ".ics",
+		CreatedTime: m.CreatedTime,
+	}
+}
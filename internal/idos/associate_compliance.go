@@ -0,0 +1,29 @@
+package idos
+
+import (
+	"github.com/over55/workery-server/internal/models"
+)
+
+type AssociateComplianceListResponseIDO struct {
+	ExpiringDays int                           `json:"expiring_days"`
+	Count        uint64                        `json:"count"`
+	Results      []*models.AssociateCompliance `json:"results"`
+}
+
+func NewAssociateComplianceListResponseIDO(expiringDays int, arr []*models.AssociateCompliance) *AssociateComplianceListResponseIDO {
+	if arr == nil { // Always return an array and not `null`.
+		arr = []*models.AssociateCompliance{}
+	}
+	return &AssociateComplianceListResponseIDO{
+		ExpiringDays: expiringDays,
+		Count:        uint64(len(arr)),
+		Results:      arr,
+	}
+}
+
+type InsuranceRequirementIDO struct {
+	Text                string `json:"text"`
+	Description         string `json:"description"`
+	State               int8   `json:"state"`
+	AssociateDateTypeOf int8   `json:"associate_date_type_of"`
+}
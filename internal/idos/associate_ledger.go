@@ -0,0 +1,77 @@
+package idos
+
+import (
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+// The statement covers the entries from `From` (inclusive) until `To`
+// (exclusive), either side of the range is null if it was left open.
+type AssociateStatementResponseIDO struct {
+	AssociateId      uint64                         `json:"associate_id"`
+	AssociateName    string                         `json:"associate_name"`
+	From             null.Time                      `json:"from"`
+	To               null.Time                      `json:"to"`
+	OpeningBalance   models.Money                   `json:"opening_balance"`
+	FeesDue          models.Money                   `json:"fees_due"`
+	PaymentsReceived models.Money                   `json:"payments_received"`
+	ClosingBalance   models.Money                   `json:"closing_balance"`
+	Results          []*models.AssociateLedgerEntry `json:"results"`
+}
+
+// Function returns the statement with the running balance of every entry
+// computed from the opening balance.
+func NewAssociateStatementResponseIDO(a *models.Associate, from null.Time, to null.Time, openingBalance models.Money, arr []*models.AssociateLedgerEntry) *AssociateStatementResponseIDO {
+	if arr == nil {
+		arr = []*models.AssociateLedgerEntry{}
+	}
+	res := &AssociateStatementResponseIDO{
+		AssociateId:    a.Id,
+		AssociateName:  a.Name,
+		From:           from,
+		To:             to,
+		OpeningBalance: openingBalance,
+		ClosingBalance: openingBalance,
+		Results:        arr,
+	}
+	for _, e := range arr {
+		if e.TypeOf == models.AssociateLedgerEntryServiceFeeTypeOf {
+			res.FeesDue += e.Amount
+		} else {
+			res.PaymentsReceived -= e.Amount
+		}
+		res.ClosingBalance += e.Amount
+		e.Balance = res.ClosingBalance
+	}
+	return res
+}
+
+type AssociateBalanceListResponseIDO struct {
+	Total   models.Money               `json:"total"`
+	Results []*models.AssociateBalance `json:"results"`
+}
+
+func NewAssociateBalanceListResponseIDO(arr []*models.AssociateBalance) *AssociateBalanceListResponseIDO {
+	if arr == nil {
+		arr = []*models.AssociateBalance{}
+	}
+	res := &AssociateBalanceListResponseIDO{
+		Results: arr,
+	}
+	for _, b := range arr {
+		res.Total += b.Balance
+	}
+	return res
+}
+
+type AssociateServiceFeePaymentIDO struct {
+	OrderIds []uint64  `json:"order_ids"`
+	PaidAt   null.Time `json:"paid_at"`
+}
+
+type AssociateServiceFeePaymentResponseIDO struct {
+	AssociateId uint64       `json:"associate_id"`
+	OrderIds    []uint64     `json:"order_ids"`
+	AmountPaid  models.Money `json:"amount_paid"`
+}
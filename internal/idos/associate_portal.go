@@ -0,0 +1,86 @@
+package idos
+
+import (
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+// AssociateWorkOrderIDO is the work order as seen by the associate assigned
+// to it, only the contact details of the customer are included.
+type AssociateWorkOrderIDO struct {
+	Id                                uint64                       `json:"id"`
+	TypeOf                            int8                         `json:"type_of"`
+	State                             int8                         `json:"state"`
+	Description                       string                       `json:"description"`
+	IsOngoing                         bool                         `json:"is_ongoing"`
+	IsHomeSupportService              bool                         `json:"is_home_support_service"`
+	AssignmentDate                    null.Time                    `json:"assignment_date"`
+	StartDate                         time.Time                    `json:"start_date"`
+	CompletionDate                    null.Time                    `json:"completion_date"`
+	Hours                             float64                      `json:"hours"`
+	Visits                            int8                         `json:"visits"`
+	Currency                          string                       `json:"currency"`
+	InvoiceServiceFeeAmount           models.Money                 `json:"invoice_service_fee_amount"`
+	InvoiceActualServiceFeeAmountPaid models.Money                 `json:"invoice_actual_service_fee_amount_paid"`
+	Customer                          *AssociateCustomerContactIDO `json:"customer"`
+}
+
+type AssociateCustomerContactIDO struct {
+	Id                        uint64 `json:"id"`
+	Name                      string `json:"name"`
+	OrganizationName          string `json:"organization_name"`
+	Email                     string `json:"email"`
+	Telephone                 string `json:"telephone"`
+	TelephoneExtension        string `json:"telephone_extension"`
+	OtherTelephone            string `json:"other_telephone"`
+	OtherTelephoneExtension   string `json:"other_telephone_extension"`
+	FullAddressWithPostalCode string `json:"full_address_with_postal_code"`
+	FullAddressUrl            string `json:"full_address_url"`
+}
+
+func NewAssociateWorkOrderIDO(m *models.WorkOrder, c *models.Customer) *AssociateWorkOrderIDO {
+	res := &AssociateWorkOrderIDO{
+		Id:                                m.Id,
+		TypeOf:                            m.TypeOf,
+		State:                             m.State,
+		Description:                       m.Description,
+		IsOngoing:                         m.IsOngoing,
+		IsHomeSupportService:              m.IsHomeSupportService,
+		AssignmentDate:                    m.AssignmentDate,
+		StartDate:                         m.StartDate,
+		CompletionDate:                    m.CompletionDate,
+		Hours:                             m.Hours,
+		Visits:                            m.Visits,
+		Currency:                          models.CurrencyOrDefault(m.Currency),
+		InvoiceServiceFeeAmount:           m.InvoiceServiceFeeAmount,
+		InvoiceActualServiceFeeAmountPaid: m.InvoiceActualServiceFeeAmountPaid,
+	}
+	if c != nil {
+		res.Customer = &AssociateCustomerContactIDO{
+			Id:                        c.Id,
+			Name:                      c.Name,
+			OrganizationName:          c.OrganizationName,
+			Email:                     c.Email,
+			Telephone:                 c.Telephone,
+			TelephoneExtension:        c.TelephoneExtension,
+			OtherTelephone:            c.OtherTelephone,
+			OtherTelephoneExtension:   c.OtherTelephoneExtension,
+			FullAddressWithPostalCode: c.FullAddressWithPostalCode,
+			FullAddressUrl:            c.FullAddressUrl,
+		}
+	}
+	return res
+}
+
+type AssociateOfferResponseIDO struct {
+	Comment string `json:"comment"`
+}
+
+type AssociateWorkOrderCompletionIDO struct {
+	CompletionDate null.Time `json:"completion_date"`
+	Hours          float64   `json:"hours"`
+	Visits         int8      `json:"visits"`
+}
@@ -7,15 +7,16 @@ import (
 )
 
 type DashboardIDO struct {
-	CustomerCount          uint64                      `json:"customer_count"`
-	JobCount               uint64                      `json:"job_count"`
-	MemberCount            uint64                      `json:"member_count"`
-	TasksCount             uint64                      `json:"tasks_count"`
-	BulletinBoardItems     []*models.BulletinBoardItem `json:"bulletin_board_items"`
-	LastModifiedJobsByUser []*models.LiteWorkOrder     `json:"last_modified_jobs_by_user"`
-	AwayLog                []*models.AssociateAwayLog  `json:"away_log"`
-	LastModifiedJobsByTeam []*models.LiteWorkOrder     `json:"last_modified_jobs_by_team"`
-	PastFewDayComments     []*models.WorkOrderComment  `json:"past_few_day_comments"`
+	CustomerCount          uint64                        `json:"customer_count"`
+	JobCount               uint64                        `json:"job_count"`
+	MemberCount            uint64                        `json:"member_count"`
+	TasksCount             uint64                        `json:"tasks_count"`
+	BulletinBoardItems     []*models.BulletinBoardItem   `json:"bulletin_board_items"`
+	LastModifiedJobsByUser []*models.LiteWorkOrder       `json:"last_modified_jobs_by_user"`
+	AwayLog                []*models.AssociateAwayLog    `json:"away_log"`
+	LastModifiedJobsByTeam []*models.LiteWorkOrder       `json:"last_modified_jobs_by_team"`
+	PastFewDayComments     []*models.WorkOrderComment    `json:"past_few_day_comments"`
+	ComplianceAlerts       []*models.AssociateCompliance `json:"compliance_alerts"`
 }
 
 type NavigationIDO struct {
@@ -0,0 +1,185 @@
+package idos
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+// Structure used to describe a single column the user can select when
+// exporting a list as a spreadsheet.
+type ExportColumnIDO struct {
+	Key   string `json:"key"`
+	Title string `json:"title"`
+}
+
+// Function will return the columns the user selected via a comma-seperated
+// list of keys, or all the available columns if nothing was selected.
+func SelectExportColumns(available []*ExportColumnIDO, keysStr string) ([]*ExportColumnIDO, error) {
+	if strings.TrimSpace(keysStr) == "" {
+		return available, nil
+	}
+
+	lookup := make(map[string]*ExportColumnIDO, len(available))
+	for _, c := range available {
+		lookup[c.Key] = c
+	}
+
+	var selected []*ExportColumnIDO
+	for _, key := range strings.Split(keysStr, ",") {
+		key = strings.TrimSpace(key)
+		c, ok := lookup[key]
+		if !ok {
+			return nil, errors.New("unknown export column: " + key)
+		}
+		selected = append(selected, c)
+	}
+	return selected, nil
+}
+
+//
+// Customers
+//
+
+var LiteCustomerExportColumns = []*ExportColumnIDO{
+	{Key: "id", Title: "ID"},
+	{Key: "state", Title: "State"},
+	{Key: "type_of", Title: "Type"},
+	{Key: "given_name", Title: "Given Name"},
+	{Key: "last_name", Title: "Last Name"},
+	{Key: "telephone", Title: "Telephone"},
+	{Key: "email", Title: "Email"},
+	{Key: "join_date", Title: "Join Date"},
+}
+
+func NewLiteCustomerExportRow(m *models.LiteCustomer) map[string]string {
+	return map[string]string{
+		"id":         strconv.FormatUint(m.Id, 10),
+		"state":      strconv.Itoa(int(m.State)),
+		"type_of":    strconv.Itoa(int(m.TypeOf)),
+		"given_name": m.GivenName,
+		"last_name":  m.LastName,
+		"telephone":  m.Telephone,
+		"email":      m.Email,
+		"join_date":  exportNullTime(m.JoinDate),
+	}
+}
+
+//
+// Associates
+//
+
+var LiteAssociateExportColumns = []*ExportColumnIDO{
+	{Key: "id", Title: "ID"},
+	{Key: "state", Title: "State"},
+	{Key: "given_name", Title: "Given Name"},
+	{Key: "last_name", Title: "Last Name"},
+	{Key: "telephone", Title: "Telephone"},
+	{Key: "telephone_extension", Title: "Telephone Extension"},
+	{Key: "email", Title: "Email"},
+	{Key: "join_date", Title: "Join Date"},
+}
+
+func NewLiteAssociateExportRow(m *models.LiteAssociate) map[string]string {
+	return map[string]string{
+		"id":                  strconv.FormatUint(m.Id, 10),
+		"state":               strconv.Itoa(int(m.State)),
+		"given_name":          m.GivenName,
+		"last_name":           m.LastName,
+		"telephone":           m.Telephone,
+		"telephone_extension": m.TelephoneExtension,
+		"email":               m.Email,
+		"join_date":           exportNullTime(m.JoinDate),
+	}
+}
+
+//
+// Work Orders
+//
+
+var LiteWorkOrderExportColumns = []*ExportColumnIDO{
+	{Key: "id", Title: "ID"},
+	{Key: "state", Title: "State"},
+	{Key: "type_of", Title: "Type"},
+	{Key: "customer_id", Title: "Customer ID"},
+	{Key: "customer_name", Title: "Customer Name"},
+	{Key: "associate_id", Title: "Associate ID"},
+	{Key: "associate_name", Title: "Associate Name"},
+	{Key: "assignment_date", Title: "Assignment Date"},
+	{Key: "start_date", Title: "Start Date"},
+	{Key: "is_ongoing", Title: "Is Ongoing"},
+}
+
+func NewLiteWorkOrderExportRow(m *models.LiteWorkOrder) map[string]string {
+	return map[string]string{
+		"id":              strconv.FormatUint(m.Id, 10),
+		"state":           strconv.Itoa(int(m.State)),
+		"type_of":         strconv.Itoa(int(m.TypeOf)),
+		"customer_id":     strconv.FormatUint(m.CustomerId, 10),
+		"customer_name":   m.CustomerName,
+		"associate_id":    exportNullInt(m.AssociateId),
+		"associate_name":  m.AssociateName.ValueOrZero(),
+		"assignment_date": exportNullTime(m.AssignmentDate),
+		"start_date":      m.StartDate.Format(time.RFC3339),
+		"is_ongoing":      strconv.FormatBool(m.IsOngoing),
+	}
+}
+
+//
+// Financials
+//
+
+var LiteFinancialExportColumns = []*ExportColumnIDO{
+	{Key: "id", Title: "ID"},
+	{Key: "state", Title: "State"},
+	{Key: "type_of", Title: "Type"},
+	{Key: "customer_id", Title: "Customer ID"},
+	{Key: "customer_name", Title: "Customer Name"},
+	{Key: "associate_id", Title: "Associate ID"},
+	{Key: "associate_name", Title: "Associate Name"},
+	{Key: "invoice_service_fee_payment_date", Title: "Service Fee Payment Date"},
+	{Key: "invoice_date", Title: "Invoice Date"},
+	{Key: "invoice_paid_to", Title: "Paid To"},
+	{Key: "currency", Title: "Currency"},
+	{Key: "invoice_service_fee_amount", Title: "Service Fee"},
+	{Key: "invoice_actual_service_fee_amount_paid", Title: "Service Fee Paid"},
+	{Key: "invoice_balance_owing_amount", Title: "Balance Owing"},
+}
+
+func NewLiteFinancialExportRow(m *models.LiteFinancial) map[string]string {
+	return map[string]string{
+		"id":                                     strconv.FormatUint(m.Id, 10),
+		"state":                                  strconv.Itoa(int(m.State)),
+		"type_of":                                strconv.Itoa(int(m.TypeOf)),
+		"customer_id":                            strconv.FormatUint(m.CustomerId, 10),
+		"customer_name":                          m.CustomerName,
+		"associate_id":                           exportNullInt(m.AssociateId),
+		"associate_name":                         m.AssociateName.ValueOrZero(),
+		"invoice_service_fee_payment_date":       exportNullTime(m.InvoiceServiceFeePaymentDate),
+		"invoice_date":                           exportNullTime(m.InvoiceDate),
+		"invoice_paid_to":                        exportNullInt(m.InvoicePaidTo),
+		"currency":                               m.Currency,
+		"invoice_service_fee_amount":             m.InvoiceServiceFeeAmount.String(),
+		"invoice_actual_service_fee_amount_paid": m.InvoiceActualServiceFeeAmountPaid.String(),
+		"invoice_balance_owing_amount":           m.InvoiceBalanceOwingAmount.String(),
+	}
+}
+
+func exportNullTime(t null.Time) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.Format(time.RFC3339)
+}
+
+func exportNullInt(i null.Int) string {
+	if !i.Valid {
+		return ""
+	}
+	return strconv.FormatInt(i.Int64, 10)
+}
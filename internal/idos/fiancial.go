@@ -17,12 +17,13 @@ type LiteFinancialFilterIDO struct {
 }
 
 type LiteFinancialListResponseIDO struct {
-	NextId  uint64                  `json:"next_id,omitempty"`
-	Count   uint64                  `json:"count"`
-	Results []*models.LiteFinancial `json:"results"`
+	NextId  uint64                      `json:"next_id,omitempty"`
+	Count   uint64                      `json:"count"`
+	Totals  *models.LiteFinancialTotals `json:"totals"`
+	Results []*models.LiteFinancial     `json:"results"`
 }
 
-func NewLiteFinancialListResponseIDO(arr []*models.LiteFinancial, count uint64) *LiteFinancialListResponseIDO {
+func NewLiteFinancialListResponseIDO(arr []*models.LiteFinancial, count uint64, totals *models.LiteFinancialTotals) *LiteFinancialListResponseIDO {
 	// Calculate next id.
 	var nextId uint64
 	if len(arr) > 0 {
@@ -32,6 +33,7 @@ func NewLiteFinancialListResponseIDO(arr []*models.LiteFinancial, count uint64)
 
 	res := &LiteFinancialListResponseIDO{ // Return through HTTP.
 		Count:   count,
+		Totals:  totals,
 		Results: arr,
 		NextId:  nextId,
 	}
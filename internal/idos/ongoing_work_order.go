@@ -38,3 +38,17 @@ func NewLiteOngoingWorkOrderListResponseIDO(arr []*models.LiteOngoingWorkOrder,
 
 	return res
 }
+
+// OngoingWorkOrderIDO is the data to create or update an ongoing work order,
+// the recurrence dates are formatted as `YYYY-MM-DD` in the timezone of the
+// tenant and the end date is optional.
+type OngoingWorkOrderIDO struct {
+	CustomerId           uint64   `json:"customer_id"`
+	AssociateId          null.Int `json:"associate_id"`
+	Description          string   `json:"description"`
+	TypeOf               int8     `json:"type_of"`
+	IsHomeSupportService bool     `json:"is_home_support_service"`
+	RecurrenceRule       string   `json:"recurrence_rule"`
+	RecurrenceStartDate  string   `json:"recurrence_start_date"`
+	RecurrenceEndDate    string   `json:"recurrence_end_date"`
+}
@@ -38,3 +38,53 @@ func NewLiteTaskItemListResponseIDO(arr []*models.LiteTaskItem, count uint64) *L
 
 	return res
 }
+
+type TaskItemAssignAssociateIDO struct {
+	AssociateId uint64 `json:"associate_id"`
+}
+
+// TaskItemDeclineAssociateIDO is the associate who declined the job offered
+// through the assign associate task.
+type TaskItemDeclineAssociateIDO struct {
+	AssociateId uint64 `json:"associate_id"`
+	Comment     string `json:"comment"`
+}
+
+type TaskItemFollowUpIDO struct {
+	HasAgreedToMeet bool      `json:"has_agreed_to_meet"`
+	MeetingDate     null.Time `json:"meeting_date"`
+}
+
+type TaskItemFollowUpPendingIDO struct {
+	DueDate null.Time `json:"due_date"`
+}
+
+type TaskItemCloseIDO struct {
+	ClosingReason        int8   `json:"closing_reason"`
+	ClosingReasonOther   string `json:"closing_reason_other"`
+	ClosingReasonComment string `json:"closing_reason_comment"`
+}
+
+type TaskItemOrderCompletionIDO struct {
+	WasCompleted            bool         `json:"was_completed"`
+	ClosingReason           int8         `json:"closing_reason"`
+	ClosingReasonOther      string       `json:"closing_reason_other"`
+	CompletionDate          null.Time    `json:"completion_date"`
+	InvoiceDate             null.Time    `json:"invoice_date"`
+	InvoiceIds              string       `json:"invoice_ids"`
+	InvoiceQuoteAmount      models.Money `json:"invoice_quote_amount"`
+	InvoiceLabourAmount     models.Money `json:"invoice_labour_amount"`
+	InvoiceMaterialAmount   models.Money `json:"invoice_material_amount"`
+	InvoiceOtherCostsAmount models.Money `json:"invoice_other_costs_amount"`
+	InvoicePaidTo           int8         `json:"invoice_paid_to"`
+	InvoiceServiceFeeId     uint64       `json:"invoice_service_fee_id"`
+	Visits                  int8         `json:"visits"`
+}
+
+// TaskItemOperationResponseIDO is the closed task along with the work order
+// and the next task of the work order, if there is one.
+type TaskItemOperationResponseIDO struct {
+	Task     *models.TaskItem  `json:"task"`
+	Order    *models.WorkOrder `json:"order"`
+	NextTask *models.TaskItem  `json:"next_task"`
+}
@@ -0,0 +1,31 @@
+package idos
+
+import (
+	"github.com/over55/workery-server/internal/models"
+)
+
+type TenantAccountMappingIDO struct {
+	TenantId           uint64 `json:"tenant_id"`
+	AccountsReceivable string `json:"accounts_receivable"`
+	LabourIncome       string `json:"labour_income"`
+	MaterialsIncome    string `json:"materials_income"`
+	OtherIncome        string `json:"other_income"`
+	SalesTaxPayable    string `json:"sales_tax_payable"`
+	UndepositedFunds   string `json:"undeposited_funds"`
+	AssociateClearing  string `json:"associate_clearing"`
+	ServiceFeeIncome   string `json:"service_fee_income"`
+}
+
+func NewTenantAccountMappingIDO(m *models.TenantAccountMapping) *TenantAccountMappingIDO {
+	return &TenantAccountMappingIDO{
+		TenantId:           m.TenantId,
+		AccountsReceivable: m.AccountsReceivable,
+		LabourIncome:       m.LabourIncome,
+		MaterialsIncome:    m.MaterialsIncome,
+		OtherIncome:        m.OtherIncome,
+		SalesTaxPayable:    m.SalesTaxPayable,
+		UndepositedFunds:   m.UndepositedFunds,
+		AssociateClearing:  m.AssociateClearing,
+		ServiceFeeIncome:   m.ServiceFeeIncome,
+	}
+}
@@ -0,0 +1,21 @@
+package idos
+
+import (
+	"github.com/over55/workery-server/internal/models"
+)
+
+type TenantInvoiceTemplateIDO struct {
+	TenantId              uint64 `json:"tenant_id"`
+	LogoS3Key             string `json:"logo_s3_key"`
+	TaxRegistrationNumber string `json:"tax_registration_number"`
+	FooterText            string `json:"footer_text"`
+}
+
+func NewTenantInvoiceTemplateIDO(m *models.TenantInvoiceTemplate) *TenantInvoiceTemplateIDO {
+	return &TenantInvoiceTemplateIDO{
+		TenantId:              m.TenantId,
+		LogoS3Key:             m.LogoS3Key,
+		TaxRegistrationNumber: m.TaxRegistrationNumber,
+		FooterText:            m.FooterText,
+	}
+}
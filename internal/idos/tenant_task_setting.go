@@ -0,0 +1,23 @@
+package idos
+
+import (
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+type TenantTaskSettingIDO struct {
+	TenantId          uint64   `json:"tenant_id"`
+	FollowUpAfterDays int16    `json:"follow_up_after_days"`
+	EscalateAfterDays int16    `json:"escalate_after_days"`
+	EscalateToId      null.Int `json:"escalate_to_id"`
+}
+
+func NewTenantTaskSettingIDO(m *models.TenantTaskSetting) *TenantTaskSettingIDO {
+	return &TenantTaskSettingIDO{
+		TenantId:          m.TenantId,
+		FollowUpAfterDays: m.FollowUpAfterDays,
+		EscalateAfterDays: m.EscalateAfterDays,
+		EscalateToId:      m.EscalateToId,
+	}
+}
@@ -0,0 +1,30 @@
+package idos
+
+import (
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+type TenantTaxRuleIDO struct {
+	Name          string         `json:"name"`
+	Rate          models.Percent `json:"rate"`
+	TypeOf        int8           `json:"type_of"`
+	EffectiveFrom null.Time      `json:"effective_from"`
+	EffectiveTo   null.Time      `json:"effective_to"`
+}
+
+type TenantTaxRuleListResponseIDO struct {
+	TenantId uint64                  `json:"tenant_id"`
+	Results  []*models.TenantTaxRule `json:"results"`
+}
+
+func NewTenantTaxRuleListResponseIDO(tenantId uint64, arr []*models.TenantTaxRule) *TenantTaxRuleListResponseIDO {
+	if arr == nil { // Always return an array and not `null`.
+		arr = []*models.TenantTaxRule{}
+	}
+	return &TenantTaxRuleListResponseIDO{
+		TenantId: tenantId,
+		Results:  arr,
+	}
+}
@@ -0,0 +1,38 @@
+package idos
+
+import (
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+type WorkOrderDepositIDO struct {
+	PaidAt        null.Time    `json:"paid_at"`
+	DepositMethod int8         `json:"deposit_method"`
+	PaidTo        int8         `json:"paid_to"`
+	PaidFor       int8         `json:"paid_for"`
+	Amount        models.Money `json:"amount"`
+}
+
+type WorkOrderDepositListResponseIDO struct {
+	OrderId                   uint64                     `json:"order_id"`
+	Currency                  string                     `json:"currency"`
+	InvoiceDepositAmount      models.Money               `json:"invoice_deposit_amount"`
+	InvoiceAmountDue          models.Money               `json:"invoice_amount_due"`
+	InvoiceBalanceOwingAmount models.Money               `json:"invoice_balance_owing_amount"`
+	Results                   []*models.WorkOrderDeposit `json:"results"`
+}
+
+func NewWorkOrderDepositListResponseIDO(order *models.WorkOrder, arr []*models.WorkOrderDeposit) *WorkOrderDepositListResponseIDO {
+	if arr == nil {
+		arr = []*models.WorkOrderDeposit{}
+	}
+	return &WorkOrderDepositListResponseIDO{
+		OrderId:                   order.Id,
+		Currency:                  models.CurrencyOrDefault(order.Currency),
+		InvoiceDepositAmount:      order.InvoiceDepositAmount,
+		InvoiceAmountDue:          order.InvoiceAmountDue,
+		InvoiceBalanceOwingAmount: order.InvoiceBalanceOwingAmount,
+		Results:                   arr,
+	}
+}
@@ -0,0 +1,50 @@
+package idos
+
+import (
+	"github.com/over55/workery-server/internal/models"
+)
+
+type WorkOrderInvoiceLineItemIDO struct {
+	TypeOf      int8         `json:"type_of"`
+	Quantity    float64      `json:"quantity"`
+	Description string       `json:"description"`
+	UnitPrice   models.Money `json:"unit_price"`
+	Notes       string       `json:"notes"`
+}
+
+type WorkOrderInvoiceLineItemReorderIDO struct {
+	Ids []uint64 `json:"ids"`
+}
+
+type WorkOrderInvoiceLineItemListResponseIDO struct {
+	InvoiceId      uint64                             `json:"invoice_id"`
+	OrderId        uint64                             `json:"order_id"`
+	TotalLabour    models.Money                       `json:"total_labour"`
+	TotalMaterials models.Money                       `json:"total_materials"`
+	OtherCosts     models.Money                       `json:"other_costs"`
+	SubTotal       models.Money                       `json:"sub_total"`
+	Tax            models.Money                       `json:"tax"`
+	Total          models.Money                       `json:"total"`
+	Deposit        models.Money                       `json:"deposit"`
+	AmountDue      models.Money                       `json:"amount_due"`
+	Results        []*models.WorkOrderInvoiceLineItem `json:"results"`
+}
+
+func NewWorkOrderInvoiceLineItemListResponseIDO(inv *models.WorkOrderInvoice, arr []*models.WorkOrderInvoiceLineItem) *WorkOrderInvoiceLineItemListResponseIDO {
+	if arr == nil {
+		arr = []*models.WorkOrderInvoiceLineItem{}
+	}
+	return &WorkOrderInvoiceLineItemListResponseIDO{
+		InvoiceId:      inv.Id,
+		OrderId:        inv.OrderId,
+		TotalLabour:    inv.TotalLabour,
+		TotalMaterials: inv.TotalMaterials,
+		OtherCosts:     inv.OtherCosts,
+		SubTotal:       inv.SubTotal,
+		Tax:            inv.Tax,
+		Total:          inv.Total,
+		Deposit:        inv.Deposit,
+		AmountDue:      inv.AmountDue,
+		Results:        arr,
+	}
+}
@@ -0,0 +1,26 @@
+package idos
+
+import (
+	"github.com/over55/workery-server/internal/models"
+)
+
+type WorkOrderInvoiceRevisionListResponseIDO struct {
+	InvoiceId              uint64                             `json:"invoice_id"`
+	CurrentRevisionVersion int16                              `json:"current_revision_version"`
+	IsLocked               bool                               `json:"is_locked"`
+	Current                *models.WorkOrderInvoiceSnapshot   `json:"current"`
+	Results                []*models.WorkOrderInvoiceRevision `json:"results"`
+}
+
+func NewWorkOrderInvoiceRevisionListResponseIDO(inv *models.WorkOrderInvoice, current *models.WorkOrderInvoiceSnapshot, arr []*models.WorkOrderInvoiceRevision) *WorkOrderInvoiceRevisionListResponseIDO {
+	if arr == nil { // Always return an array and not `null`.
+		arr = []*models.WorkOrderInvoiceRevision{}
+	}
+	return &WorkOrderInvoiceRevisionListResponseIDO{
+		InvoiceId:              inv.Id,
+		CurrentRevisionVersion: inv.RevisionVersion,
+		IsLocked:               inv.IsLocked(),
+		Current:                current,
+		Results:                arr,
+	}
+}
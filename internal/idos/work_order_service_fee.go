@@ -38,3 +38,33 @@ func NewLiteWorkOrderServiceFeeListResponseIDO(arr []*models.LiteWorkOrderServic
 
 	return res
 }
+
+type WorkOrderServiceFeeIDO struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Percentage  models.Percent `json:"percentage"`
+
+	// The `YYYY-MM-DD` date in the timezone of the tenant when the percentage
+	// takes effect, an empty value means right away.
+	EffectiveFrom string `json:"effective_from"`
+}
+
+type WorkOrderServiceFeeChangePreviewIDO struct {
+	Percentage    models.Percent `json:"percentage"`
+	EffectiveFrom string         `json:"effective_from"`
+}
+
+type WorkOrderServiceFeeDetailResponseIDO struct {
+	*models.WorkOrderServiceFee
+	Rates []*models.WorkOrderServiceFeeRate `json:"rates"`
+}
+
+func NewWorkOrderServiceFeeDetailResponseIDO(m *models.WorkOrderServiceFee, rates []*models.WorkOrderServiceFeeRate) *WorkOrderServiceFeeDetailResponseIDO {
+	if rates == nil { // Always return an array and not `null`.
+		rates = []*models.WorkOrderServiceFeeRate{}
+	}
+	return &WorkOrderServiceFeeDetailResponseIDO{
+		WorkOrderServiceFee: m,
+		Rates:               rates,
+	}
+}
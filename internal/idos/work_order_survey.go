@@ -0,0 +1,78 @@
+package idos
+
+import (
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+type WorkOrderSurveyIDO struct {
+	WasSurveyConducted                bool     `json:"was_survey_conducted"`
+	NoSurveyConductedReason           null.Int `json:"no_survey_conducted_reason"`
+	NoSurveyConductedReasonOther      string   `json:"no_survey_conducted_reason_other"`
+	WasJobSatisfactory                bool     `json:"was_job_satisfactory"`
+	WasJobFinishedOnTimeAndOnBudget   bool     `json:"was_job_finished_on_time_and_on_budget"`
+	WasAssociatePunctual              bool     `json:"was_associate_punctual"`
+	WasAssociateProfessional          bool     `json:"was_associate_professional"`
+	WouldCustomerReferOurOrganization bool     `json:"would_customer_refer_our_organization"`
+}
+
+type WorkOrderSurveyListResponseIDO struct {
+	OrderId uint64                    `json:"order_id"`
+	Score   int8                      `json:"score"`
+	Results []*models.WorkOrderSurvey `json:"results"`
+}
+
+func NewWorkOrderSurveyListResponseIDO(order *models.WorkOrder, arr []*models.WorkOrderSurvey) *WorkOrderSurveyListResponseIDO {
+	if arr == nil {
+		arr = []*models.WorkOrderSurvey{}
+	}
+	return &WorkOrderSurveyListResponseIDO{
+		OrderId: order.Id,
+		Score:   order.Score,
+		Results: arr,
+	}
+}
+
+type AssociateSatisfactionListResponseIDO struct {
+	From    null.Time                       `json:"from"`
+	To      null.Time                       `json:"to"`
+	Results []*models.AssociateSatisfaction `json:"results"`
+}
+
+func NewAssociateSatisfactionListResponseIDO(from null.Time, to null.Time, arr []*models.AssociateSatisfaction) *AssociateSatisfactionListResponseIDO {
+	if arr == nil {
+		arr = []*models.AssociateSatisfaction{}
+	}
+	return &AssociateSatisfactionListResponseIDO{
+		From:    from,
+		To:      to,
+		Results: arr,
+	}
+}
+
+// AssociateSatisfactionResponseIDO is the current score of the associate
+// along with every survey submitted within the dates, the `AssociateScore`
+// of the surveys is the history of the score.
+type AssociateSatisfactionResponseIDO struct {
+	AssociateId   uint64                    `json:"associate_id"`
+	AssociateName string                    `json:"associate_name"`
+	Score         float64                   `json:"score"`
+	From          null.Time                 `json:"from"`
+	To            null.Time                 `json:"to"`
+	Results       []*models.WorkOrderSurvey `json:"results"`
+}
+
+func NewAssociateSatisfactionResponseIDO(a *models.Associate, from null.Time, to null.Time, arr []*models.WorkOrderSurvey) *AssociateSatisfactionResponseIDO {
+	if arr == nil {
+		arr = []*models.WorkOrderSurvey{}
+	}
+	return &AssociateSatisfactionResponseIDO{
+		AssociateId:   a.Id,
+		AssociateName: a.Name,
+		Score:         a.Score,
+		From:          from,
+		To:            to,
+		Results:       arr,
+	}
+}
@@ -0,0 +1,104 @@
+package importers
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+// The CSV columns, after mapping, which are supported for associates.
+var AssociateImportFields = append([]string{
+	"business", "wsib_number", "drivers_license_class", "service_fee_id",
+	"dues_date", "commercial_insurance_expiry_date", "auto_insurance_expiry_date",
+	"wsib_insurance_date", "police_check",
+}, CustomerImportFields...)
+
+// Function will import the associates from the CSV file and return the
+// per-row report of the import.
+func (imp *Importer) ImportAssociates(ctx context.Context, r io.Reader, opt *Options) (*Report, error) {
+	return imp.run(ctx, r, opt, fieldSet(AssociateImportFields), true, imp.AssociateRepo.CheckIfExistsByEmailOrTelephone, func(ctx context.Context, tx *sql.Tx, p *personRow) error {
+		return imp.insertAssociate(ctx, tx, p, opt)
+	})
+}
+
+func (imp *Importer) insertAssociate(ctx context.Context, tx *sql.Tx, p *personRow, opt *Options) error {
+	howHear, err := imp.HowHearRepo.GetById(ctx, p.HowHearId)
+	if err != nil {
+		return err
+	}
+
+	uuidStr := uuid.NewString()
+	name, lexicalName := compileNames(p)
+	userId, err := imp.insertUser(ctx, tx, p, opt, 4, "associate", uuidStr, name, lexicalName) // 4 = Associate
+	if err != nil {
+		return err
+	}
+
+	typeOf := p.TypeOf
+	if typeOf == 0 {
+		typeOf = models.AssociateResidentialTypeOf
+		if p.OrganizationName != "" {
+			typeOf = models.AssociateCommercialTypeOf
+		}
+	}
+
+	now := time.Now()
+	fullAddressWithoutPostalCode, fullAddressWithPostalCode, fullAddressUrl := compileAddress(p)
+	m := &models.Associate{
+		Uuid:                          uuidStr,
+		TenantId:                      opt.TenantId,
+		UserId:                        userId,
+		TypeOf:                        typeOf,
+		OrganizationName:              p.OrganizationName,
+		Business:                      p.Business,
+		IsOkToEmail:                   p.IsOkToEmail,
+		IsOkToText:                    p.IsOkToText,
+		DuesDate:                      timeOrDefault(p.DuesDate, now),
+		CommercialInsuranceExpiryDate: timeOrDefault(p.CommercialInsuranceExpiryDate, now),
+		AutoInsuranceExpiryDate:       timeOrDefault(p.AutoInsuranceExpiryDate, now),
+		WsibNumber:                    p.WsibNumber,
+		WsibInsuranceDate:             timeOrDefault(p.WsibInsuranceDate, now),
+		PoliceCheck:                   timeOrDefault(p.PoliceCheck, now),
+		DriversLicenseClass:           p.DriversLicenseClass,
+		HowHearId:                     p.HowHearId,
+		HowHearText:                   howHear.Text,
+		State:                         models.AssociateActiveState,
+		CreatedTime:                   now,
+		CreatedById:                   null.NewInt(int64(opt.CreatedById), opt.CreatedById != 0),
+		CreatedByName:                 null.NewString(opt.CreatedByName, opt.CreatedByName != ""),
+		CreatedFromIP:                 opt.CreatedFromIP,
+		LastModifiedTime:              now,
+		LastModifiedById:              null.NewInt(int64(opt.CreatedById), opt.CreatedById != 0),
+		LastModifiedByName:            null.NewString(opt.CreatedByName, opt.CreatedByName != ""),
+		LastModifiedFromIP:            opt.CreatedFromIP,
+		ServiceFeeId:                  p.ServiceFeeId,
+		AddressCountry:                p.AddressCountry,
+		AddressRegion:                 p.AddressRegion,
+		AddressLocality:               p.AddressLocality,
+		PostalCode:                    p.PostalCode,
+		StreetAddress:                 p.StreetAddress,
+		StreetAddressExtra:            p.StreetAddressExtra,
+		FullAddressWithoutPostalCode:  fullAddressWithoutPostalCode,
+		FullAddressWithPostalCode:     fullAddressWithPostalCode,
+		FullAddressUrl:                fullAddressUrl,
+		GivenName:                     p.GivenName,
+		MiddleName:                    p.MiddleName,
+		LastName:                      p.LastName,
+		Name:                          name,
+		LexicalName:                   lexicalName,
+		Birthdate:                     null.NewTime(p.Birthdate, !p.Birthdate.IsZero()),
+		JoinDate:                      null.TimeFrom(timeOrDefault(p.JoinDate, now)),
+		TaxId:                         p.TaxId,
+		Email:                         p.Email,
+		Telephone:                     p.Telephone,
+		TelephoneExtension:            p.TelephoneExtension,
+		OtherTelephone:                p.OtherTelephone,
+	}
+	return imp.AssociateRepo.WithTx(tx).Insert(ctx, m)
+}
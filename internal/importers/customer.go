@@ -0,0 +1,107 @@
+package importers
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+// The CSV columns, after mapping, which are supported for customers.
+var CustomerImportFields = []string{
+	"given_name", "middle_name", "last_name", "email", "telephone",
+	"telephone_extension", "other_telephone", "organization_name",
+	"street_address", "street_address_extra", "address_locality",
+	"address_region", "address_country", "postal_code", "is_ok_to_email",
+	"is_ok_to_text", "how_hear_id", "join_date", "birthdate", "tax_id",
+	"type_of",
+}
+
+// Function will import the customers from the CSV file and return the
+// per-row report of the import.
+func (imp *Importer) ImportCustomers(ctx context.Context, r io.Reader, opt *Options) (*Report, error) {
+	return imp.run(ctx, r, opt, fieldSet(CustomerImportFields), false, imp.CustomerRepo.CheckIfExistsByEmailOrTelephone, func(ctx context.Context, tx *sql.Tx, p *personRow) error {
+		return imp.insertCustomer(ctx, tx, p, opt)
+	})
+}
+
+func (imp *Importer) insertCustomer(ctx context.Context, tx *sql.Tx, p *personRow, opt *Options) error {
+	howHear, err := imp.HowHearRepo.GetById(ctx, p.HowHearId)
+	if err != nil {
+		return err
+	}
+
+	uuidStr := uuid.NewString()
+	name, lexicalName := compileNames(p)
+	userId, err := imp.insertUser(ctx, tx, p, opt, 5, "customer", uuidStr, name, lexicalName) // 5 = Customer
+	if err != nil {
+		return err
+	}
+
+	typeOf := p.TypeOf
+	if typeOf == 0 {
+		typeOf = models.CustomerResidentialTypeOf
+		if p.OrganizationName != "" {
+			typeOf = models.CustomerCommercialTypeOf
+		}
+	}
+
+	now := time.Now()
+	fullAddressWithoutPostalCode, fullAddressWithPostalCode, fullAddressUrl := compileAddress(p)
+	m := &models.Customer{
+		Uuid:                         uuidStr,
+		TenantId:                     opt.TenantId,
+		UserId:                       userId,
+		TypeOf:                       typeOf,
+		IsOkToEmail:                  p.IsOkToEmail,
+		IsOkToText:                   p.IsOkToText,
+		IsBusiness:                   typeOf == models.CustomerCommercialTypeOf,
+		HowHearId:                    p.HowHearId,
+		HowHearText:                  howHear.Text,
+		State:                        models.CustomerActiveState,
+		CreatedTime:                  now,
+		CreatedById:                  null.NewInt(int64(opt.CreatedById), opt.CreatedById != 0),
+		CreatedByName:                null.NewString(opt.CreatedByName, opt.CreatedByName != ""),
+		CreatedFromIP:                opt.CreatedFromIP,
+		LastModifiedTime:             now,
+		LastModifiedById:             null.NewInt(int64(opt.CreatedById), opt.CreatedById != 0),
+		LastModifiedByName:           null.NewString(opt.CreatedByName, opt.CreatedByName != ""),
+		LastModifiedFromIP:           opt.CreatedFromIP,
+		OrganizationName:             p.OrganizationName,
+		AddressCountry:               p.AddressCountry,
+		AddressRegion:                p.AddressRegion,
+		AddressLocality:              p.AddressLocality,
+		PostalCode:                   p.PostalCode,
+		StreetAddress:                p.StreetAddress,
+		StreetAddressExtra:           p.StreetAddressExtra,
+		FullAddressWithoutPostalCode: fullAddressWithoutPostalCode,
+		FullAddressWithPostalCode:    fullAddressWithPostalCode,
+		FullAddressUrl:               fullAddressUrl,
+		GivenName:                    p.GivenName,
+		MiddleName:                   p.MiddleName,
+		LastName:                     p.LastName,
+		Name:                         name,
+		LexicalName:                  lexicalName,
+		Birthdate:                    null.NewTime(p.Birthdate, !p.Birthdate.IsZero()),
+		JoinDate:                     null.TimeFrom(timeOrDefault(p.JoinDate, now)),
+		TaxId:                        p.TaxId,
+		Email:                        p.Email,
+		Telephone:                    p.Telephone,
+		TelephoneExtension:           p.TelephoneExtension,
+		OtherTelephone:               p.OtherTelephone,
+	}
+	return imp.CustomerRepo.WithTx(tx).Insert(ctx, m)
+}
+
+func fieldSet(fields []string) map[string]bool {
+	m := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		m[f] = true
+	}
+	return m
+}
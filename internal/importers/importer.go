@@ -0,0 +1,617 @@
+package importers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"io"
+	"log"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/repositories"
+)
+
+const (
+	DefaultBatchSize = 100
+
+	RowValidStatus     = "valid"
+	RowImportedStatus  = "imported"
+	RowInvalidStatus   = "invalid"
+	RowDuplicateStatus = "duplicate"
+	RowFailedStatus    = "failed"
+)
+
+// Options used to control how a CSV file gets imported.
+type Options struct {
+	// The tenant all the imported records will belong to.
+	TenantId uint64
+
+	// The user account which is performing the import, it will be saved as
+	// the `created by` and `last modified by` of every imported record.
+	CreatedById   uint64
+	CreatedByName string
+	CreatedFromIP string
+
+	// If true then the CSV file is only validated and checked for duplicates
+	// and nothing gets saved to the database.
+	DryRun bool
+
+	// The number of rows saved per database transaction.
+	BatchSize int
+
+	// Maps the CSV header name to our field name, for example:
+	// "First Name" -> "given_name". Headers which are not in the mapping are
+	// matched to our field names directly.
+	Mapping map[string]string
+
+	// The `how hear about us` item and the `service fee` to use if the row
+	// does not specify them.
+	DefaultHowHearId    uint64
+	DefaultServiceFeeId uint64
+}
+
+// Structure used to report the result of a single CSV row.
+type RowResult struct {
+	Row    int               `json:"row"`
+	Status string            `json:"status"`
+	Email  string            `json:"email,omitempty"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Structure used to report the result of the entire CSV file. Only the rows
+// which did not import cleanly are included in the `Rows` field.
+type Report struct {
+	DryRun        bool         `json:"dry_run"`
+	TotalRows     int          `json:"total_rows"`
+	ValidRows     int          `json:"valid_rows"`
+	InvalidRows   int          `json:"invalid_rows"`
+	DuplicateRows int          `json:"duplicate_rows"`
+	ImportedRows  int          `json:"imported_rows"`
+	FailedRows    int          `json:"failed_rows"`
+	Rows          []*RowResult `json:"rows"`
+}
+
+// Importer is used to bulk import customers and associates from CSV files.
+type Importer struct {
+	db             *sql.DB
+	UserRepo       *repositories.UserRepo
+	CustomerRepo   *repositories.CustomerRepo
+	AssociateRepo  *repositories.AssociateRepo
+	HowHearRepo    *repositories.HowHearAboutUsItemRepo
+	ServiceFeeRepo *repositories.WorkOrderServiceFeeRepo
+}
+
+func NewImporter(db *sql.DB) *Importer {
+	return &Importer{
+		db:             db,
+		UserRepo:       repositories.NewUserRepo(db),
+		CustomerRepo:   repositories.NewCustomerRepo(db),
+		AssociateRepo:  repositories.NewAssociateRepo(db),
+		HowHearRepo:    repositories.NewHowHearAboutUsItemRepo(db),
+		ServiceFeeRepo: repositories.NewWorkOrderServiceFeeRepo(db),
+	}
+}
+
+// Function will parse the mapping in the format of
+// "CSV Header=field_name,Other Header=other_field" used by our command line
+// and API.
+func ParseMapping(s string) (map[string]string, error) {
+	m := make(map[string]string)
+	if strings.TrimSpace(s) == "" {
+		return m, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" || strings.TrimSpace(kv[1]) == "" {
+			return nil, errors.New("invalid column mapping: " + pair)
+		}
+		m[normalizeHeader(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return m, nil
+}
+
+// personRow is the parsed and validated contents of a single CSV row. The
+// fields are shared between customers and associates.
+type personRow struct {
+	Row                int
+	GivenName          string
+	MiddleName         string
+	LastName           string
+	Email              string
+	Telephone          string
+	TelephoneExtension string
+	OtherTelephone     string
+	OrganizationName   string
+	StreetAddress      string
+	StreetAddressExtra string
+	AddressLocality    string
+	AddressRegion      string
+	AddressCountry     string
+	PostalCode         string
+	IsOkToEmail        bool
+	IsOkToText         bool
+	HowHearId          uint64
+	JoinDate           time.Time
+	Birthdate          time.Time
+	TaxId              string
+	TypeOf             int8
+
+	// Associate only.
+	Business                      string
+	WsibNumber                    string
+	DriversLicenseClass           string
+	ServiceFeeId                  uint64
+	DuesDate                      time.Time
+	CommercialInsuranceExpiryDate time.Time
+	AutoInsuranceExpiryDate       time.Time
+	WsibInsuranceDate             time.Time
+	PoliceCheck                   time.Time
+}
+
+// Function returns the telephone number with everything but the digits
+// removed so we can compare numbers regardless of their formatting.
+func normalizeTelephone(s string) string {
+	return nonDigitRegexp.ReplaceAllString(s, "")
+}
+
+var nonDigitRegexp = regexp.MustCompile("[^0-9]")
+
+func normalizeHeader(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.ReplaceAll(s, " ", "_")
+	return s
+}
+
+// Function reads the CSV file and returns every row as a map of our field
+// names to the cell values.
+func readCSV(r io.Reader, mapping map[string]string, allowed map[string]bool) ([]map[string]string, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("csv file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, len(header))
+	for i, h := range header {
+		h = normalizeHeader(strings.TrimPrefix(h, "\ufeff")) // Excel likes to add a BOM.
+		if mapped, ok := mapping[h]; ok {
+			h = mapped
+		}
+		if h != "" && !allowed[h] {
+			return nil, errors.New("unknown csv column: " + header[i])
+		}
+		fields[i] = h
+	}
+
+	var rows []map[string]string
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]string, len(fields))
+		for i, value := range record {
+			if i < len(fields) && fields[i] != "" {
+				row[fields[i]] = strings.TrimSpace(value)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// Function converts the CSV values into our row structure and returns any
+// validation errors keyed by the field name.
+func (imp *Importer) parseRow(ctx context.Context, n int, v map[string]string, opt *Options, isAssociate bool) (*personRow, map[string]string) {
+	e := make(map[string]string)
+
+	p := &personRow{
+		Row:                 n,
+		GivenName:           v["given_name"],
+		MiddleName:          v["middle_name"],
+		LastName:            v["last_name"],
+		Email:               strings.ToLower(v["email"]),
+		Telephone:           v["telephone"],
+		TelephoneExtension:  v["telephone_extension"],
+		OtherTelephone:      v["other_telephone"],
+		OrganizationName:    v["organization_name"],
+		StreetAddress:       v["street_address"],
+		StreetAddressExtra:  v["street_address_extra"],
+		AddressLocality:     v["address_locality"],
+		AddressRegion:       v["address_region"],
+		AddressCountry:      v["address_country"],
+		PostalCode:          v["postal_code"],
+		TaxId:               v["tax_id"],
+		Business:            v["business"],
+		WsibNumber:          v["wsib_number"],
+		DriversLicenseClass: v["drivers_license_class"],
+		HowHearId:           opt.DefaultHowHearId,
+		ServiceFeeId:        opt.DefaultServiceFeeId,
+	}
+
+	checkRequired(e, "given_name", p.GivenName, 63)
+	checkRequired(e, "last_name", p.LastName, 63)
+	checkLength(e, "middle_name", p.MiddleName, 63)
+	checkLength(e, "email", p.Email, 255)
+	checkLength(e, "telephone", p.Telephone, 127)
+	checkLength(e, "telephone_extension", p.TelephoneExtension, 31)
+	checkLength(e, "other_telephone", p.OtherTelephone, 127)
+	checkLength(e, "organization_name", p.OrganizationName, 255)
+	checkLength(e, "street_address", p.StreetAddress, 127)
+	checkLength(e, "street_address_extra", p.StreetAddressExtra, 127)
+	checkLength(e, "address_locality", p.AddressLocality, 127)
+	checkLength(e, "address_region", p.AddressRegion, 127)
+	checkLength(e, "address_country", p.AddressCountry, 127)
+	checkLength(e, "postal_code", p.PostalCode, 127)
+	checkLength(e, "tax_id", p.TaxId, 127)
+
+	if p.Email == "" && p.Telephone == "" {
+		e["email"] = "email or telephone is required"
+	}
+	if p.Email != "" {
+		if _, err := mail.ParseAddress(p.Email); err != nil {
+			e["email"] = "invalid email address"
+		}
+	}
+	if p.Telephone != "" && len(normalizeTelephone(p.Telephone)) < 7 {
+		e["telephone"] = "invalid telephone number"
+	}
+
+	p.IsOkToEmail = parseBool(e, "is_ok_to_email", v["is_ok_to_email"])
+	p.IsOkToText = parseBool(e, "is_ok_to_text", v["is_ok_to_text"])
+	p.JoinDate = parseDate(e, "join_date", v["join_date"])
+	p.Birthdate = parseDate(e, "birthdate", v["birthdate"])
+
+	if s := v["type_of"]; s != "" {
+		typeOf, err := strconv.ParseInt(s, 10, 8)
+		if err != nil || typeOf < 1 || typeOf > 3 {
+			e["type_of"] = "must be 1 (unassigned), 2 (residential) or 3 (commercial)"
+		}
+		p.TypeOf = int8(typeOf)
+	}
+
+	if s := v["how_hear_id"]; s != "" {
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			e["how_hear_id"] = "must be a number"
+		}
+		p.HowHearId = id
+	}
+	if p.HowHearId == 0 {
+		e["how_hear_id"] = "missing value"
+	} else if _, ok := e["how_hear_id"]; !ok {
+		howHear, err := imp.HowHearRepo.GetById(ctx, p.HowHearId)
+		if err != nil {
+			log.Println("WARNING: parseRow|HowHearRepo.GetById|err:", err.Error())
+			e["how_hear_id"] = "lookup failed"
+		} else if howHear == nil || howHear.TenantId != opt.TenantId {
+			e["how_hear_id"] = "does not exist"
+		}
+	}
+
+	if isAssociate {
+		checkLength(e, "business", p.Business, 63)
+		checkLength(e, "wsib_number", p.WsibNumber, 127)
+		checkLength(e, "drivers_license_class", p.DriversLicenseClass, 31)
+
+		p.DuesDate = parseDate(e, "dues_date", v["dues_date"])
+		p.CommercialInsuranceExpiryDate = parseDate(e, "commercial_insurance_expiry_date", v["commercial_insurance_expiry_date"])
+		p.AutoInsuranceExpiryDate = parseDate(e, "auto_insurance_expiry_date", v["auto_insurance_expiry_date"])
+		p.WsibInsuranceDate = parseDate(e, "wsib_insurance_date", v["wsib_insurance_date"])
+		p.PoliceCheck = parseDate(e, "police_check", v["police_check"])
+
+		if s := v["service_fee_id"]; s != "" {
+			id, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				e["service_fee_id"] = "must be a number"
+			}
+			p.ServiceFeeId = id
+		}
+		if p.ServiceFeeId == 0 {
+			e["service_fee_id"] = "missing value"
+		} else if _, ok := e["service_fee_id"]; !ok {
+			serviceFee, err := imp.ServiceFeeRepo.GetById(ctx, p.ServiceFeeId)
+			if err != nil {
+				log.Println("WARNING: parseRow|ServiceFeeRepo.GetById|err:", err.Error())
+				e["service_fee_id"] = "lookup failed"
+			} else if serviceFee == nil || serviceFee.TenantId != opt.TenantId {
+				e["service_fee_id"] = "does not exist"
+			}
+		}
+	}
+
+	if len(e) != 0 {
+		return nil, e
+	}
+	return p, nil
+}
+
+func checkRequired(e map[string]string, field string, value string, max int) {
+	if value == "" {
+		e[field] = "missing value"
+		return
+	}
+	checkLength(e, field, value, max)
+}
+
+func checkLength(e map[string]string, field string, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		e[field] = "character count over " + strconv.Itoa(max)
+	}
+}
+
+func parseBool(e map[string]string, field string, value string) bool {
+	switch strings.ToLower(value) {
+	case "", "0", "false", "no", "n":
+		return false
+	case "1", "true", "yes", "y":
+		return true
+	default:
+		e[field] = "must be true or false"
+		return false
+	}
+}
+
+// Function parses the date in `YYYY-MM-DD` format and returns the zero time
+// if the value was empty.
+func parseDate(e map[string]string, field string, value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		e[field] = "must be in YYYY-MM-DD format"
+	}
+	return t
+}
+
+// Function returns the error message if the row is a duplicate of either a
+// previous row in the same CSV file or an existing record in the database.
+func (imp *Importer) checkDuplicate(
+	ctx context.Context,
+	p *personRow,
+	opt *Options,
+	seenEmails map[string]int,
+	seenTelephones map[string]int,
+	existsFn func(ctx context.Context, tid uint64, email string, telephone string) (bool, error),
+) (string, error) {
+	telephone := normalizeTelephone(p.Telephone)
+
+	if p.Email != "" {
+		if row, ok := seenEmails[p.Email]; ok {
+			return "email is used by row " + strconv.Itoa(row), nil
+		}
+	}
+	if telephone != "" {
+		if row, ok := seenTelephones[telephone]; ok {
+			return "telephone is used by row " + strconv.Itoa(row), nil
+		}
+	}
+
+	exists, err := existsFn(ctx, opt.TenantId, p.Email, telephone)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "email or telephone already exists", nil
+	}
+
+	// DEVELOPERS NOTE:
+	// Emails are unique across every user account so we must also make sure
+	// no staff member or other tenant is already using it.
+	if p.Email != "" {
+		exists, err = imp.UserRepo.CheckIfExistsByEmail(ctx, p.Email)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return "email is already used by a user account", nil
+		}
+		seenEmails[p.Email] = p.Row
+	}
+	if telephone != "" {
+		seenTelephones[telephone] = p.Row
+	}
+	return "", nil
+}
+
+// Function will validate every row of the CSV file and, unless we are doing
+// a dry run, save the valid rows in batches where each batch is committed in
+// its own transaction. If any row in a batch fails to save then the entire
+// batch is rolled back and every row in the batch is reported as failed.
+func (imp *Importer) run(
+	ctx context.Context,
+	r io.Reader,
+	opt *Options,
+	allowed map[string]bool,
+	isAssociate bool,
+	existsFn func(ctx context.Context, tid uint64, email string, telephone string) (bool, error),
+	insertFn func(ctx context.Context, tx *sql.Tx, p *personRow) error,
+) (*Report, error) {
+	if opt.BatchSize <= 0 {
+		opt.BatchSize = DefaultBatchSize
+	}
+
+	records, err := readCSV(r, opt.Mapping, allowed)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		DryRun:    opt.DryRun,
+		TotalRows: len(records),
+		Rows:      []*RowResult{},
+	}
+
+	seenEmails := make(map[string]int)
+	seenTelephones := make(map[string]int)
+
+	var valid []*personRow
+	for i, v := range records {
+		n := i + 2 // Row #1 is the header and spreadsheets start counting at one.
+
+		p, e := imp.parseRow(ctx, n, v, opt, isAssociate)
+		if e != nil {
+			report.InvalidRows++
+			report.Rows = append(report.Rows, &RowResult{Row: n, Status: RowInvalidStatus, Email: v["email"], Errors: e})
+			continue
+		}
+
+		reason, err := imp.checkDuplicate(ctx, p, opt, seenEmails, seenTelephones, existsFn)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			report.DuplicateRows++
+			report.Rows = append(report.Rows, &RowResult{Row: n, Status: RowDuplicateStatus, Email: p.Email, Errors: map[string]string{"email": reason}})
+			continue
+		}
+
+		report.ValidRows++
+		valid = append(valid, p)
+	}
+
+	if opt.DryRun {
+		return report, nil
+	}
+
+	for start := 0; start < len(valid); start += opt.BatchSize {
+		end := start + opt.BatchSize
+		if end > len(valid) {
+			end = len(valid)
+		}
+		batch := valid[start:end]
+
+		if failedRow, err := imp.insertBatch(ctx, batch, insertFn); err != nil {
+			log.Println("WARNING: importer|insertBatch|err:", err.Error())
+			report.FailedRows += len(batch)
+			for _, p := range batch {
+				msg := "batch was rolled back"
+				if p.Row == failedRow {
+					msg = err.Error()
+				}
+				report.Rows = append(report.Rows, &RowResult{Row: p.Row, Status: RowFailedStatus, Email: p.Email, Errors: map[string]string{"row": msg}})
+			}
+			continue
+		}
+		report.ImportedRows += len(batch)
+	}
+	return report, nil
+}
+
+// Function saves every row in a single transaction and returns the row
+// number which caused the error, if any.
+func (imp *Importer) insertBatch(ctx context.Context, batch []*personRow, insertFn func(ctx context.Context, tx *sql.Tx, p *personRow) error) (int, error) {
+	tx, err := imp.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range batch {
+		if err := insertFn(ctx, tx, p); err != nil {
+			tx.Rollback()
+			return p.Row, err
+		}
+	}
+	return 0, tx.Commit()
+}
+
+// Function creates the user account which the imported customer or
+// associate will belong to and returns the ID of the new account.
+func (imp *Importer) insertUser(ctx context.Context, tx *sql.Tx, p *personRow, opt *Options, roleId int8, emailPrefix string, uuidStr string, name string, lexicalName string) (uint64, error) {
+	ur := imp.UserRepo.WithTx(tx)
+
+	// DEVELOPERS NOTE:
+	// Every user account requires an email so we generate a placeholder
+	// when the row only has a telephone, same as what our ETL does.
+	email := p.Email
+	if email == "" {
+		email = emailPrefix + "+" + uuidStr + "@workery.ca"
+	}
+
+	now := time.Now()
+	um := &models.User{
+		Uuid:              uuidStr,
+		TenantId:          opt.TenantId,
+		FirstName:         p.GivenName,
+		LastName:          p.LastName,
+		Name:              name,
+		LexicalName:       lexicalName,
+		Email:             email,
+		State:             1, // Active
+		RoleId:            roleId,
+		Timezone:          "America/Toronto",
+		CreatedTime:       now,
+		ModifiedTime:      now,
+		JoinedTime:        now,
+		WasEmailActivated: false,
+		PrExpiryTime:      now,
+	}
+	if err := ur.Insert(ctx, um); err != nil {
+		return 0, err
+	}
+	user, err := ur.GetByEmail(ctx, email)
+	if err != nil {
+		return 0, err
+	}
+	if user == nil {
+		return 0, errors.New("user account was not created")
+	}
+	return user.Id, nil
+}
+
+// Function returns the `name` and `lexical name` of the person.
+func compileNames(p *personRow) (string, string) {
+	if p.MiddleName != "" {
+		return p.GivenName + " " + p.MiddleName + " " + p.LastName, p.LastName + ", " + p.MiddleName + ", " + p.GivenName
+	}
+	return p.GivenName + " " + p.LastName, p.LastName + ", " + p.GivenName
+}
+
+// Function returns the `full address without postal code`, `full address with
+// postal code` and `full address url` of the person.
+func compileAddress(p *personRow) (string, string, string) {
+	address := ""
+	if p.StreetAddress != "" {
+		address += p.StreetAddress
+	}
+	if p.StreetAddressExtra != "" {
+		address += " " + p.StreetAddressExtra
+	}
+	if p.StreetAddress != "" {
+		address += ", "
+	}
+	address += p.AddressLocality
+	address += ", " + p.AddressRegion
+	address += ", " + p.AddressCountry
+
+	withPostalCode := "-"
+	url := "https://www.google.com/maps/place/" + address
+	if p.PostalCode != "" {
+		withPostalCode = address + ", " + p.PostalCode
+		url = "https://www.google.com/maps/place/" + withPostalCode
+	}
+	return address, withPostalCode, url
+}
+
+// Function returns the time or, if not set, the default time.
+func timeOrDefault(t time.Time, def time.Time) time.Time {
+	if t.IsZero() {
+		return def
+	}
+	return t
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+)
+
+// SourceTypeOf
+//---------------------
+// 1 = Work order invoice
+// 2 = Work order deposit
+// 3 = Service fee payment of a work order
+
+const (
+	AccountingInvoiceSourceTypeOf           = 1
+	AccountingDepositSourceTypeOf           = 2
+	AccountingServiceFeePaymentSourceTypeOf = 3
+
+	AccountingIIFFormat = "iif"
+	AccountingCSVFormat = "csv"
+)
+
+type AccountingExport struct {
+	Id            uint64      `json:"id"`
+	Uuid          string      `json:"uuid"`
+	TenantId      uint64      `json:"tenant_id"`
+	Format        string      `json:"format"`
+	FromDate      time.Time   `json:"from_date"`
+	ToDate        time.Time   `json:"to_date"`
+	EntryCount    uint64      `json:"entry_count"`
+	CreatedTime   time.Time   `json:"created_time"`
+	CreatedById   null.Int    `json:"created_by_id"`
+	CreatedByName null.String `json:"created_by_name"`
+	CreatedFromIP null.String `json:"created_from_ip"`
+}
+
+// AccountingJournalEntry is a balanced double-entry transaction built from
+// one of our financial records. The `SourceVersion` is the revision version
+// of invoices and the payment version of service fee payments, a record is
+// exported again as an adjustment once its version changes.
+type AccountingJournalEntry struct {
+	SourceTypeOf  int8                     `json:"source_type_of"`
+	SourceId      uint64                   `json:"source_id"`
+	SourceVersion int64                    `json:"source_version"`
+	Date          time.Time                `json:"date"`
+	Reference     string                   `json:"reference"`
+	Name          string                   `json:"name"`
+	Memo          string                   `json:"memo"`
+	Currency      string                   `json:"currency"`
+	Lines         []*AccountingJournalLine `json:"lines"`
+}
+
+// AccountingJournalLine is a single line of the journal entry, a positive
+// amount is a debit and a negative amount is a credit.
+type AccountingJournalLine struct {
+	Account string `json:"account"`
+	Amount  Money  `json:"amount"`
+}
+
+// AccountingJournalEntries are the entries which were already exported for
+// a record, they are read from the database as a JSON array.
+type AccountingJournalEntries []*AccountingJournalEntry
+
+// Scan implements the `sql.Scanner` interface.
+func (a *AccountingJournalEntries) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*a = AccountingJournalEntries{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	default:
+		return fmt.Errorf("cannot scan %T into accounting journal entries", value)
+	}
+}
+
+// The invoice totals whose current revision has not been exported yet, the
+// `Exported` entries are the earlier revisions which were exported.
+type AccountingInvoice struct {
+	Id               uint64                   `json:"id"`
+	OrderId          uint64                   `json:"order_id"`
+	InvoiceId        string                   `json:"invoice_id"`
+	InvoiceDate      time.Time                `json:"invoice_date"`
+	ClientName       string                   `json:"client_name"`
+	Currency         string                   `json:"currency"`
+	TotalLabour      Money                    `json:"total_labour"`
+	TotalMaterials   Money                    `json:"total_materials"`
+	OtherCosts       Money                    `json:"other_costs"`
+	Tax              Money                    `json:"tax"`
+	RevisionVersion  int16                    `json:"revision_version"`
+	LastModifiedTime time.Time                `json:"last_modified_time"`
+	Exported         AccountingJournalEntries `json:"exported"`
+}
+
+// The service fee payment of a work order whose current version has not
+// been exported yet, the `Exported` entries are the earlier versions which
+// were exported.
+type AccountingServiceFeePayment struct {
+	OrderId          uint64                   `json:"order_id"`
+	AssociateName    string                   `json:"associate_name"`
+	PaidAt           time.Time                `json:"paid_at"`
+	Currency         string                   `json:"currency"`
+	Amount           Money                    `json:"amount"`
+	Version          int64                    `json:"version"`
+	LastModifiedTime time.Time                `json:"last_modified_time"`
+	Exported         AccountingJournalEntries `json:"exported"`
+}
+
+type AccountingExportRepository interface {
+	ListUnexportedInvoices(ctx context.Context, tenantId uint64, from time.Time, to time.Time) ([]*AccountingInvoice, error)
+	ListUnexportedDeposits(ctx context.Context, tenantId uint64, from time.Time, to time.Time) ([]*WorkOrderDeposit, error)
+	ListUnexportedServiceFeePayments(ctx context.Context, tenantId uint64, from time.Time, to time.Time) ([]*AccountingServiceFeePayment, error)
+	InsertWithEntries(ctx context.Context, m *AccountingExport, arr []*AccountingJournalEntry) error
+	GetById(ctx context.Context, id uint64) (*AccountingExport, error)
+	ListByTenantId(ctx context.Context, tenantId uint64, offset uint64, limit uint64) ([]*AccountingExport, error)
+	CountByTenantId(ctx context.Context, tenantId uint64) (uint64, error)
+	ListEntriesByExportId(ctx context.Context, exportId uint64) ([]*AccountingJournalEntry, error)
+}
@@ -7,11 +7,34 @@ import (
 	null "gopkg.in/guregu/null.v4"
 )
 
-// State
+const (
+	ActivitySheetItemDeclinedState = 1
+	ActivitySheetItemPendingState  = 2
+	ActivitySheetItemAcceptedState = 3
+)
+
+//---------------------
+// state
 //---------------------
-// 1 = Active
-// 0 = Inactive
+// 1 = Declined
+// 2 = Pending
+// 3 = Accepted
+
+// Structure used to encapsulate the various filters we want to apply when we
+// perform our `listing` functionality for the `ActivitySheetItem` model.
+type ActivitySheetItemFilter struct {
+	TenantId       uint64   `json:"tenant_id"`
+	States         []int8   `json:"states"`
+	SortOrder      string   `json:"sort_order"`
+	SortField      string   `json:"sort_field"`
+	AssociateId    null.Int `json:"associate_id"`
+	OrderId        null.Int `json:"order_id"`
+	OngoingOrderId null.Int `json:"ongoing_order_id"`
+	Offset         uint64   `json:"offset"`
+	Limit          uint64   `json:"limit"`
+}
 
+// ActivitySheetItem is the response of the associate to being offered a job.
 type ActivitySheetItem struct {
 	Id                   uint64      `json:"id"`
 	Uuid                 string      `json:"uuid"`
@@ -37,4 +60,27 @@ type ActivitySheetItemRepository interface {
 	GetIdByOldId(ctx context.Context, tid uint64, oid uint64) (uint64, error)
 	CheckIfExistsById(ctx context.Context, id uint64) (bool, error)
 	InsertOrUpdateById(ctx context.Context, u *ActivitySheetItem) error
+	ListByFilter(ctx context.Context, filter *ActivitySheetItemFilter) ([]*ActivitySheetItem, error)
+	CountByFilter(ctx context.Context, filter *ActivitySheetItemFilter) (uint64, error)
+
+	// Function returns the acceptance rate of the associate from the
+	// responses created since `since`.
+	GetAcceptanceRateByAssociateId(ctx context.Context, associateId uint64, since time.Time) (*AssociateAcceptanceRate, error)
+}
+
+// AssociateAcceptanceRate is the share of the job offers which the associate
+// accepted, the pending responses are not counted.
+type AssociateAcceptanceRate struct {
+	AssociateId   uint64  `json:"associate_id"`
+	AcceptedCount uint64  `json:"accepted_count"`
+	DeclinedCount uint64  `json:"declined_count"`
+	PendingCount  uint64  `json:"pending_count"`
+	Rate          float64 `json:"rate"`
+}
+
+func (m *AssociateAcceptanceRate) ComputeRate() {
+	m.Rate = 0
+	if total := m.AcceptedCount + m.DeclinedCount; total > 0 {
+		m.Rate = float64(m.AcceptedCount) / float64(total)
+	}
 }
@@ -0,0 +1,26 @@
+package models
+
+import "testing"
+
+func TestAssociateAcceptanceRateComputeRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		accepted uint64
+		declined uint64
+		pending  uint64
+		want     float64
+	}{
+		{"no responses", 0, 0, 0, 0},
+		{"only pending", 0, 0, 4, 0},
+		{"all accepted", 3, 0, 0, 1},
+		{"all declined", 0, 2, 0, 0},
+		{"pending not counted", 3, 1, 5, 0.75},
+	}
+	for _, tt := range tests {
+		m := &AssociateAcceptanceRate{AcceptedCount: tt.accepted, DeclinedCount: tt.declined, PendingCount: tt.pending}
+		m.ComputeRate()
+		if m.Rate != tt.want {
+			t.Errorf("%s: rate = %v, want %v", tt.name, m.Rate, tt.want)
+		}
+	}
+}
@@ -8,11 +8,17 @@ import (
 )
 
 const (
-	AssociateActiveState       = 1
-	AssociateInactiveState     = 0
-	AssociateUnassignedTypeOf  = 1
-	AssociateResidentialTypeOf = 2
-	AssociateCommercialTypeOf  = 3
+	AssociateActiveState                     = 1
+	AssociateInactiveState                   = 0
+	AssociateNotSpecifiedDeactivationReason  = 0
+	AssociateOtherDeactivationReason         = 1
+	AssociateBlacklistedDeactivationReason   = 2
+	AssociateMovedDeactivationReason         = 3
+	AssociateDeceasedDeactivationReason      = 4
+	AssociateDoNotConstactDeactivationReason = 5
+	AssociateUnassignedTypeOf                = 1
+	AssociateResidentialTypeOf               = 2
+	AssociateCommercialTypeOf                = 3
 )
 
 // State
@@ -20,6 +26,23 @@ const (
 // 1 = Active
 // 0 = Inactive
 
+//---------------------
+// type_of
+//---------------------
+// 1 = Unknown Associate
+// 2 = Residential Associate
+// 3 = Commercial Associate
+
+//---------------------
+// deactivation_reason
+//---------------------
+// 0 = Not Specified
+// 1 = Other
+// 2 = Blacklisted
+// 3 = Moved
+// 4 = Deceased
+// 5 = Do not contact
+
 type Associate struct {
 	// -- associate.py
 	Id                            uint64      `json:"id"`
@@ -103,12 +126,24 @@ type Associate struct {
 	OtherTelephoneExtension string `json:"other_telephone_extension"`
 	OtherTelephoneTypeOf    int8   `json:"other_telephone_type_of"`
 
+	// -- associate.py
+	EmergencyContactName                 string `json:"emergency_contact_name"`
+	EmergencyContactRelationship         string `json:"emergency_contact_relationship"`
+	EmergencyContactTelephone            string `json:"emergency_contact_telephone"`
+	EmergencyContactAlternativeTelephone string `json:"emergency_contact_alternative_telephone"`
+
 	// Name     string  `json:"name"`
 	// Url     string  `json:"url"`
 	// OrganizationId sql.NullInt64  `json:"organization_id"`
 	// OwnerId sql.NullInt64  `json:"owner_id"`
 	// IsBlacklisted bool `json:"is_blacklisted"`
 	// AvatarImageId sql.NullInt64  `json:"avatar_image_id"`
+
+	// -- Reference --
+	SkillSets             []*AssociateSkillSet             `json:"skill_sets,omitempty"`
+	Tags                  []*AssociateTag                  `json:"tags,omitempty"`
+	VehicleTypes          []*AssociateVehicleType          `json:"vehicle_types,omitempty"`
+	InsuranceRequirements []*AssociateInsuranceRequirement `json:"insurance_requirements,omitempty"`
 }
 
 type AssociateRepository interface {
@@ -116,6 +151,16 @@ type AssociateRepository interface {
 	UpdateById(ctx context.Context, u *Associate) error
 	GetById(ctx context.Context, id uint64) (*Associate, error)
 	GetIdByOldId(ctx context.Context, tid uint64, oid uint64) (uint64, error)
+	GetIdByUserId(ctx context.Context, userId uint64) (uint64, error)
 	CheckIfExistsById(ctx context.Context, id uint64) (bool, error)
+	CheckIfExistsByEmailOrTelephone(ctx context.Context, tid uint64, email string, telephone string) (bool, error)
 	InsertOrUpdateById(ctx context.Context, u *Associate) error
+
+	// Function creates the user account of the associate and then the
+	// associate, either both get created or none.
+	InsertWithUser(ctx context.Context, user *User, u *Associate) error
+
+	// Function saves the associate and its user account, either both get
+	// saved or none.
+	UpdateWithUserById(ctx context.Context, user *User, u *Associate) error
 }
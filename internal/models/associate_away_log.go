@@ -2,16 +2,43 @@ package models
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	null "gopkg.in/guregu/null.v4"
 )
 
 const (
-	AssociateAwayLogActiveState   = 1
-	AssociateAwayLogInactiveState = 0
+	AssociateAwayLogActiveState                      = 1
+	AssociateAwayLogInactiveState                    = 0
+	AssociateAwayLogOtherReason                      = 1
+	AssociateAwayLogGoingOnVacationReason            = 2
+	AssociateAwayLogPersonalReason                   = 3
+	AssociateAwayLogCommercialInsuranceExpiredReason = 4
+	AssociateAwayLogPoliceCheckExpiredReason         = 5
 )
 
+// ErrAssociateAwayLogOverlaps is returned when saving an away log whose
+// period overlaps with another active away log of the associate.
+var ErrAssociateAwayLogOverlaps = errors.New("overlaps with another away log of the associate")
+
+//---------------------
+// reason
+//---------------------
+// 1 = Other
+// 2 = Going on vacation
+// 3 = Personal reasons
+// 4 = Commercial insurance expired
+// 5 = Police check expired
+
+var AssociateAwayLogReasonTitles = map[int8]string{
+	AssociateAwayLogOtherReason:                      "Other",
+	AssociateAwayLogGoingOnVacationReason:            "Going on vacation",
+	AssociateAwayLogPersonalReason:                   "Personal reasons",
+	AssociateAwayLogCommercialInsuranceExpiredReason: "Commercial insurance expired",
+	AssociateAwayLogPoliceCheckExpiredReason:         "Police check expired",
+}
+
 type AssociateAwayLog struct {
 	Id                   uint64      `json:"id"`
 	Uuid                 string      `json:"uuid"`
@@ -54,4 +81,20 @@ type AssociateAwayLogRepository interface {
 	InsertOrUpdateById(ctx context.Context, u *AssociateAwayLog) error
 	ListByFilter(ctx context.Context, filter *AssociateAwayLogFilter) ([]*AssociateAwayLog, error)
 	CountByFilter(ctx context.Context, filter *AssociateAwayLogFilter) (uint64, error)
+	CheckIfAwayByAssociateId(ctx context.Context, associateId uint64, at time.Time) (bool, error)
+
+	// Function returns true if another active away log of the associate
+	// overlaps with the period, a null `startDate` means the period started
+	// right away and a null `untilDate` means until further notice.
+	CheckIfOverlapsByAssociateId(ctx context.Context, associateId uint64, excludeId uint64, startDate null.Time, untilDate null.Time) (bool, error)
+
+	// Function inserts the away log, or updates it if it was already saved,
+	// unless it overlaps with another active away log of the associate in
+	// which case `ErrAssociateAwayLogOverlaps` is returned. The check and the
+	// save happen within a single transaction which holds the associate.
+	SaveIfNotOverlapping(ctx context.Context, u *AssociateAwayLog) error
+
+	// Function deactivates the active away logs whose `until_date` has
+	// passed and returns how many were deactivated.
+	ExpireByUntilDate(ctx context.Context, now time.Time) (int64, error)
 }
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+)
+
+const (
+	AssociateCalendarWorkOrderEventTypeOf    = 1
+	AssociateCalendarOngoingVisitEventTypeOf = 2
+	AssociateCalendarAwayEventTypeOf         = 3
+)
+
+//---------------------
+// type_of
+//---------------------
+// 1 = Work order assigned to the associate
+// 2 = Visit of an ongoing work order
+// 3 = Away period of the associate
+
+// AssociateCalendarEvent is a single entry of the availability of an
+// associate. The `EndDate` of an away period is null when the associate is
+// away until further notice, every other event lasts the whole day of its
+// `StartDate`.
+type AssociateCalendarEvent struct {
+	Uid            string    `json:"uid"`
+	TypeOf         int8      `json:"type_of"`
+	Title          string    `json:"title"`
+	Description    string    `json:"description"`
+	StartDate      time.Time `json:"start_date"`
+	EndDate        null.Time `json:"end_date"`
+	State          int8      `json:"state"`
+	OrderId        null.Int  `json:"order_id"`
+	OngoingOrderId null.Int  `json:"ongoing_order_id"`
+	AwayLogId      null.Int  `json:"away_log_id"`
+	AssignmentDate null.Time `json:"assignment_date"`
+
+	// The visit of the ongoing work order has not been created as a job yet,
+	// see `OngoingWorkOrderGenerateAheadDays`.
+	IsProjected bool `json:"is_projected"`
+}
+
+// AssociateCalendar combines the work orders, the visits of the ongoing work
+// orders and the away periods of an associate between `From` (inclusive) and
+// `To` (exclusive).
+type AssociateCalendar struct {
+	AssociateId uint64                    `json:"associate_id"`
+	From        time.Time                 `json:"from"`
+	To          time.Time                 `json:"to"`
+	Events      []*AssociateCalendarEvent `json:"events"`
+}
+
+// Function returns true if an away period of the calendar covers the time.
+func (c *AssociateCalendar) IsAwayAt(t time.Time) bool {
+	for _, e := range c.Events {
+		if e.TypeOf != AssociateCalendarAwayEventTypeOf || t.Before(e.StartDate) {
+			continue
+		}
+		if !e.EndDate.Valid || !t.After(e.EndDate.Time) {
+			return true
+		}
+	}
+	return false
+}
+
+// Function adds the visits of the ongoing work order which fall within the
+// calendar but whose jobs were not created yet. The visits are computed like
+// the scheduler does so they are skipped while the associate is away.
+func (c *AssociateCalendar) AddOngoingWorkOrder(m *OngoingWorkOrder, loc *time.Location) {
+	rule, err := ParseRecurrenceRule(m.RecurrenceRule)
+	if err != nil || !m.RecurrenceStartDate.Valid {
+		return
+	}
+
+	after := c.From.Add(-time.Nanosecond)
+	if m.GeneratedUntil.Valid && m.GeneratedUntil.Time.After(after) {
+		after = m.GeneratedUntil.Time
+	}
+	until := c.To.Add(-time.Nanosecond)
+	if m.RecurrenceEndDate.Valid && m.RecurrenceEndDate.Time.Before(until) {
+		until = m.RecurrenceEndDate.Time
+	}
+
+	for _, t := range rule.Occurrences(m.RecurrenceStartDate.Time.In(loc), after, until) {
+		if c.IsAwayAt(t) {
+			continue
+		}
+		c.Events = append(c.Events, &AssociateCalendarEvent{
+			Uid:            "ongoing-work-order-" + strconv.FormatUint(m.Id, 10) + "-" + t.UTC().Format("20060102"),
+			TypeOf:         AssociateCalendarOngoingVisitEventTypeOf,
+			Title:          "Ongoing job #" + strconv.FormatUint(m.Id, 10) + ": " + m.CustomerName.ValueOrZero(),
+			Description:    m.Description,
+			StartDate:      t,
+			State:          WorkOrderOngoingState,
+			OngoingOrderId: null.IntFrom(int64(m.Id)),
+			IsProjected:    true,
+		})
+	}
+}
+
+// Function sorts the events by their start date.
+func (c *AssociateCalendar) Sort() {
+	sort.SliceStable(c.Events, func(i, j int) bool {
+		return c.Events[i].StartDate.Before(c.Events[j].StartDate)
+	})
+}
+
+// AssociateCalendarToken grants access to the calendar feed of the associate
+// without logging in, only the SHA-256 hash of the token is saved.
+type AssociateCalendarToken struct {
+	Id            uint64      `json:"id"`
+	TenantId      uint64      `json:"tenant_id"`
+	AssociateId   uint64      `json:"associate_id"`
+	TokenHash     string      `json:"-"`
+	CreatedTime   time.Time   `json:"created_time"`
+	CreatedById   null.Int    `json:"created_by_id"`
+	CreatedByName null.String `json:"created_by_name"`
+}
+
+type AssociateCalendarRepository interface {
+	// Function returns the work orders and the active away periods of the
+	// associate which fall within `from` (inclusive) and `to` (exclusive).
+	ListEventsByAssociateId(ctx context.Context, associateId uint64, from time.Time, to time.Time) ([]*AssociateCalendarEvent, error)
+
+	GetTokenByTokenHash(ctx context.Context, tokenHash string) (*AssociateCalendarToken, error)
+
+	// Function saves the token of the associate, the previous token of the
+	// associate is revoked.
+	InsertOrUpdateTokenByAssociateId(ctx context.Context, m *AssociateCalendarToken) error
+
+	DeleteTokenByAssociateId(ctx context.Context, associateId uint64) error
+}
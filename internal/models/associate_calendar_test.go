@@ -0,0 +1,111 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+)
+
+func TestAssociateCalendarIsAwayAt(t *testing.T) {
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC)
+	}
+	c := &AssociateCalendar{
+		Events: []*AssociateCalendarEvent{
+			{TypeOf: AssociateCalendarAwayEventTypeOf, StartDate: day(time.January, 10), EndDate: null.TimeFrom(day(time.January, 15))},
+			{TypeOf: AssociateCalendarWorkOrderEventTypeOf, StartDate: day(time.January, 20)},
+			{TypeOf: AssociateCalendarAwayEventTypeOf, StartDate: day(time.February, 1)},
+		},
+	}
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want bool
+	}{
+		{"before the away period", day(time.January, 9), false},
+		{"start of the away period", day(time.January, 10), true},
+		{"end of the away period", day(time.January, 15), true},
+		{"after the away period", day(time.January, 15).Add(time.Nanosecond), false},
+		{"work order", day(time.January, 20), false},
+		{"until further notice", day(time.June, 1), true},
+	}
+	for _, tt := range tests {
+		if got := c.IsAwayAt(tt.t); got != tt.want {
+			t.Errorf("%s: IsAwayAt() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAssociateCalendarAddOngoingWorkOrder(t *testing.T) {
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2026, month, d, 9, 0, 0, 0, time.UTC)
+	}
+	away := &AssociateCalendarEvent{
+		TypeOf:    AssociateCalendarAwayEventTypeOf,
+		StartDate: day(time.January, 11),
+		EndDate:   null.TimeFrom(day(time.January, 13)),
+	}
+
+	tests := []struct {
+		name    string
+		order   *OngoingWorkOrder
+		wantIds []string
+	}{
+		{
+			"weekly skipping the away period",
+			&OngoingWorkOrder{Id: 7, RecurrenceRule: "FREQ=WEEKLY", RecurrenceStartDate: null.TimeFrom(day(time.January, 5))},
+			[]string{"ongoing-work-order-7-20260105", "ongoing-work-order-7-20260119", "ongoing-work-order-7-20260126"},
+		},
+		{
+			"jobs already generated",
+			&OngoingWorkOrder{Id: 7, RecurrenceRule: "FREQ=WEEKLY", RecurrenceStartDate: null.TimeFrom(day(time.January, 5)), GeneratedUntil: null.TimeFrom(day(time.January, 19))},
+			[]string{"ongoing-work-order-7-20260126"},
+		},
+		{
+			"ended",
+			&OngoingWorkOrder{Id: 7, RecurrenceRule: "FREQ=WEEKLY", RecurrenceStartDate: null.TimeFrom(day(time.January, 5)), RecurrenceEndDate: null.TimeFrom(day(time.January, 20))},
+			[]string{"ongoing-work-order-7-20260105", "ongoing-work-order-7-20260119"},
+		},
+		{
+			"invalid rule",
+			&OngoingWorkOrder{Id: 7, RecurrenceRule: "FREQ=DAILY", RecurrenceStartDate: null.TimeFrom(day(time.January, 5))},
+			nil,
+		},
+		{
+			"no start date",
+			&OngoingWorkOrder{Id: 7, RecurrenceRule: "FREQ=WEEKLY"},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		c := &AssociateCalendar{
+			From:   time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+			To:     time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC),
+			Events: []*AssociateCalendarEvent{away},
+		}
+		c.AddOngoingWorkOrder(tt.order, time.UTC)
+
+		var ids []string
+		for _, e := range c.Events {
+			if e.TypeOf != AssociateCalendarOngoingVisitEventTypeOf {
+				continue
+			}
+			if !e.IsProjected || e.OngoingOrderId.ValueOrZero() != int64(tt.order.Id) {
+				t.Errorf("%s: visit %+v", tt.name, e)
+			}
+			ids = append(ids, e.Uid)
+		}
+		if len(ids) != len(tt.wantIds) {
+			t.Errorf("%s: visits = %v, want %v", tt.name, ids, tt.wantIds)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != tt.wantIds[i] {
+				t.Errorf("%s: visits = %v, want %v", tt.name, ids, tt.wantIds)
+				break
+			}
+		}
+	}
+}
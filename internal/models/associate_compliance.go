@@ -0,0 +1,83 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	AssociateComplianceCompliantStatus = 1
+	AssociateComplianceExpiringStatus  = 2
+	AssociateComplianceLapsedStatus    = 3
+
+	// The number of days before the expiry date a requirement is reported
+	// as expiring if no other number of days was requested.
+	AssociateComplianceDefaultExpiringDays = 30
+)
+
+//---------------------
+// status
+//---------------------
+// 1 = Compliant
+// 2 = Expiring within the number of days
+// 3 = Lapsed
+
+// AssociateComplianceItem is the status of a single insurance requirement of
+// the associate, the `ExpiryDate` is the date of the associate set by the
+// `AssociateDateTypeOf` of the requirement.
+type AssociateComplianceItem struct {
+	InsuranceRequirementId   uint64    `json:"insurance_requirement_id"`
+	InsuranceRequirementText string    `json:"insurance_requirement_text"`
+	AssociateDateTypeOf      int8      `json:"associate_date_type_of"`
+	ExpiryDate               time.Time `json:"expiry_date"`
+	Status                   int8      `json:"status"`
+	DaysRemaining            int       `json:"days_remaining"`
+}
+
+// Function computes the status of the requirement on `now`, the requirement
+// is expiring if it lapses within `expiringDays` days.
+func (m *AssociateComplianceItem) ComputeStatus(now time.Time, expiringDays int) {
+	m.DaysRemaining = int(m.ExpiryDate.Sub(now).Hours() / 24)
+	switch {
+	case !m.ExpiryDate.After(now):
+		m.Status = AssociateComplianceLapsedStatus
+	case m.ExpiryDate.Before(now.AddDate(0, 0, expiringDays)):
+		m.Status = AssociateComplianceExpiringStatus
+	default:
+		m.Status = AssociateComplianceCompliantStatus
+	}
+}
+
+// AssociateCompliance is the status of every tracked insurance requirement of
+// the associate, the `Status` is the worst status of the requirements.
+type AssociateCompliance struct {
+	AssociateId          uint64                     `json:"associate_id"`
+	AssociateName        string                     `json:"associate_name"`
+	AssociateLexicalName string                     `json:"associate_lexical_name"`
+	Status               int8                       `json:"status"`
+	Items                []*AssociateComplianceItem `json:"items"`
+}
+
+func (m *AssociateCompliance) ComputeStatus(now time.Time, expiringDays int) {
+	m.Status = AssociateComplianceCompliantStatus
+	for _, item := range m.Items {
+		item.ComputeStatus(now, expiringDays)
+		if item.Status > m.Status {
+			m.Status = item.Status
+		}
+	}
+}
+
+type AssociateComplianceRepository interface {
+	// Function returns the tracked insurance requirements of the active
+	// associates of the tenant, the status is not computed.
+	ListByTenantId(ctx context.Context, tenantId uint64) ([]*AssociateCompliance, error)
+
+	// Function returns the tracked insurance requirements of the associate,
+	// the status is not computed.
+	GetByAssociateId(ctx context.Context, associateId uint64) (*AssociateCompliance, error)
+
+	// Function returns the tracked insurance requirements needed by the skill
+	// sets of the work order which have lapsed for the associate on `now`.
+	ListLapsedForWorkOrder(ctx context.Context, associateId uint64, orderId uint64, now time.Time) ([]*AssociateComplianceItem, error)
+}
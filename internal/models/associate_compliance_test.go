@@ -0,0 +1,59 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAssociateComplianceItemComputeStatus(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name              string
+		expiryDate        time.Time
+		wantStatus        int8
+		wantDaysRemaining int
+	}{
+		{"lapsed", now.AddDate(0, 0, -3), AssociateComplianceLapsedStatus, -3},
+		{"lapses now", now, AssociateComplianceLapsedStatus, 0},
+		{"lapses today", now.Add(time.Hour), AssociateComplianceExpiringStatus, 0},
+		{"expiring", now.AddDate(0, 0, 10), AssociateComplianceExpiringStatus, 10},
+		{"end of the expiring days", now.AddDate(0, 0, 30), AssociateComplianceCompliantStatus, 30},
+		{"compliant", now.AddDate(1, 0, 0), AssociateComplianceCompliantStatus, 365},
+	}
+	for _, tt := range tests {
+		m := &AssociateComplianceItem{ExpiryDate: tt.expiryDate}
+		m.ComputeStatus(now, AssociateComplianceDefaultExpiringDays)
+		if m.Status != tt.wantStatus || m.DaysRemaining != tt.wantDaysRemaining {
+			t.Errorf("%s: status, days remaining = %d, %d, want %d, %d", tt.name, m.Status, m.DaysRemaining, tt.wantStatus, tt.wantDaysRemaining)
+		}
+	}
+}
+
+func TestAssociateComplianceComputeStatus(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	compliant := now.AddDate(1, 0, 0)
+	expiring := now.AddDate(0, 0, 10)
+	lapsed := now.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name        string
+		expiryDates []time.Time
+		want        int8
+	}{
+		{"no requirements", nil, AssociateComplianceCompliantStatus},
+		{"compliant", []time.Time{compliant, compliant}, AssociateComplianceCompliantStatus},
+		{"expiring", []time.Time{compliant, expiring}, AssociateComplianceExpiringStatus},
+		{"lapsed", []time.Time{lapsed, expiring, compliant}, AssociateComplianceLapsedStatus},
+	}
+	for _, tt := range tests {
+		m := &AssociateCompliance{}
+		for _, d := range tt.expiryDates {
+			m.Items = append(m.Items, &AssociateComplianceItem{ExpiryDate: d})
+		}
+		m.ComputeStatus(now, AssociateComplianceDefaultExpiringDays)
+		if m.Status != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, m.Status, tt.want)
+		}
+	}
+}
@@ -16,6 +16,7 @@ type AssociateInsuranceRequirement struct {
 	AssociateId            uint64 `json:"associate_id"`
 	InsuranceRequirementId uint64 `json:"insurance_requirement_id"`
 	OldId                  uint64 `json:"old_id"`
+	Text                   string `json:"text,omitempty"` // Referenced value from 'insurance_requirements'.
 }
 
 type AssociateInsuranceRequirementRepository interface {
@@ -25,4 +26,10 @@ type AssociateInsuranceRequirementRepository interface {
 	GetIdByOldId(ctx context.Context, tid uint64, oid uint64) (uint64, error)
 	CheckIfExistsById(ctx context.Context, id uint64) (bool, error)
 	InsertOrUpdateById(ctx context.Context, u *AssociateInsuranceRequirement) error
+
+	// Function returns the insurance requirements of the associate along
+	// with their referenced values.
+	ListByAssociateId(ctx context.Context, associateId uint64) ([]*AssociateInsuranceRequirement, error)
+	GetByAssociateIdAndInsuranceRequirementId(ctx context.Context, associateId uint64, insuranceRequirementId uint64) (*AssociateInsuranceRequirement, error)
+	DeleteById(ctx context.Context, id uint64) error
 }
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// TypeOf
+//---------------------
+// 1 = Service fee billed for the work order
+// 2 = Service fee payment received from the associate
+// 3 = Deposit paid to the organization on the work order
+
+const (
+	AssociateLedgerEntryServiceFeeTypeOf = 1
+	AssociateLedgerEntryPaymentTypeOf    = 2
+	AssociateLedgerEntryDepositTypeOf    = 3
+)
+
+// AssociateLedgerEntry is a single row of the `associate_service_fee_ledger`
+// view, a positive amount increases what the associate owes us.
+type AssociateLedgerEntry struct {
+	TenantId    uint64    `json:"tenant_id"`
+	AssociateId uint64    `json:"associate_id"`
+	OrderId     uint64    `json:"order_id"`
+	TypeOf      int8      `json:"type_of"`
+	EntryDate   time.Time `json:"entry_date"`
+	Currency    string    `json:"currency"`
+	Amount      Money     `json:"amount"`
+	Balance     Money     `json:"balance"` // Compiled value
+}
+
+type AssociateBalance struct {
+	AssociateId          uint64 `json:"associate_id"`
+	AssociateName        string `json:"associate_name"`
+	AssociateLexicalName string `json:"associate_lexical_name"`
+	Balance              Money  `json:"balance"`
+}
+
+type AssociateLedgerRepository interface {
+	ListByAssociateId(ctx context.Context, associateId uint64, from time.Time, to time.Time) ([]*AssociateLedgerEntry, error)
+	GetBalanceByAssociateId(ctx context.Context, associateId uint64, before time.Time) (Money, error)
+	ListBalancesByTenantId(ctx context.Context, tenantId uint64) ([]*AssociateBalance, error)
+}
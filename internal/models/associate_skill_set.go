@@ -16,6 +16,8 @@ type AssociateSkillSet struct {
 	AssociateId uint64 `json:"associate_id"`
 	SkillSetId  uint64 `json:"skill_set_id"`
 	OldId       uint64 `json:"old_id"`
+	Category    string `json:"category,omitempty"`     // Referenced value from 'skill_sets'.
+	SubCategory string `json:"sub_category,omitempty"` // Referenced value from 'skill_sets'.
 }
 
 type AssociateSkillSetRepository interface {
@@ -25,4 +27,10 @@ type AssociateSkillSetRepository interface {
 	GetByOld(ctx context.Context, tenantId uint64, oldId uint64) (*AssociateSkillSet, error)
 	CheckIfExistsById(ctx context.Context, id uint64) (bool, error)
 	InsertOrUpdateById(ctx context.Context, u *AssociateSkillSet) error
+
+	// Function returns the skill sets of the associate along with their
+	// referenced values.
+	ListByAssociateId(ctx context.Context, associateId uint64) ([]*AssociateSkillSet, error)
+	GetByAssociateIdAndSkillSetId(ctx context.Context, associateId uint64, skillSetId uint64) (*AssociateSkillSet, error)
+	DeleteById(ctx context.Context, id uint64) error
 }
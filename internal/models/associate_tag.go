@@ -16,6 +16,7 @@ type AssociateTag struct {
 	AssociateId uint64 `json:"associate_id"`
 	TagId       uint64 `json:"tag_id"`
 	OldId       uint64 `json:"old_id"`
+	Text        string `json:"text,omitempty"` // Referenced value from 'tags'.
 }
 
 type AssociateTagRepository interface {
@@ -25,4 +26,10 @@ type AssociateTagRepository interface {
 	GetByOld(ctx context.Context, tenantId uint64, oldId uint64) (*AssociateTag, error)
 	CheckIfExistsById(ctx context.Context, id uint64) (bool, error)
 	InsertOrUpdateById(ctx context.Context, u *AssociateTag) error
+
+	// Function returns the tags of the associate along with their
+	// referenced values.
+	ListByAssociateId(ctx context.Context, associateId uint64) ([]*AssociateTag, error)
+	GetByAssociateIdAndTagId(ctx context.Context, associateId uint64, tagId uint64) (*AssociateTag, error)
+	DeleteById(ctx context.Context, id uint64) error
 }
@@ -16,6 +16,7 @@ type AssociateVehicleType struct {
 	AssociateId   uint64 `json:"associate_id"`
 	VehicleTypeId uint64 `json:"vehicle_type_id"`
 	OldId         uint64 `json:"old_id"`
+	Text          string `json:"text,omitempty"` // Referenced value from 'vehicle_types'.
 }
 
 type AssociateVehicleTypeRepository interface {
@@ -25,4 +26,10 @@ type AssociateVehicleTypeRepository interface {
 	GetByOld(ctx context.Context, tenantId uint64, oldId uint64) (*AssociateVehicleType, error)
 	CheckIfExistsById(ctx context.Context, id uint64) (bool, error)
 	InsertOrUpdateById(ctx context.Context, u *AssociateVehicleType) error
+
+	// Function returns the vehicle types of the associate along with their
+	// referenced values.
+	ListByAssociateId(ctx context.Context, associateId uint64) ([]*AssociateVehicleType, error)
+	GetByAssociateIdAndVehicleTypeId(ctx context.Context, associateId uint64, vehicleTypeId uint64) (*AssociateVehicleType, error)
+	DeleteById(ctx context.Context, id uint64) error
 }
@@ -134,5 +134,6 @@ type CustomerRepository interface {
 	GetById(ctx context.Context, id uint64) (*Customer, error)
 	GetIdByOldId(ctx context.Context, tid uint64, oid uint64) (uint64, error)
 	CheckIfExistsById(ctx context.Context, id uint64) (bool, error)
+	CheckIfExistsByEmailOrTelephone(ctx context.Context, tid uint64, email string, telephone string) (bool, error)
 	InsertOrUpdateById(ctx context.Context, u *Customer) error
 }
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// ETLCheckpoint keeps track of how far an ETL step got for a particular
+// tenant schema so the import can be resumed without duplicating rows.
+type ETLCheckpoint struct {
+	Id               uint64    `json:"id"`
+	SchemaName       string    `json:"schema_name"`
+	Step             string    `json:"step"`
+	LastOldId        uint64    `json:"last_old_id"`
+	ImportedRows     uint64    `json:"imported_rows"`
+	FailedRows       uint64    `json:"failed_rows"`
+	FailedOldIds     []uint64  `json:"failed_old_ids"` // Retried when resuming.
+	IsCompleted      bool      `json:"is_completed"`
+	CreatedTime      time.Time `json:"created_time"`
+	LastModifiedTime time.Time `json:"last_modified_time"`
+}
+
+type ETLCheckpointRepository interface {
+	GetBySchemaNameAndStep(ctx context.Context, schemaName string, step string) (*ETLCheckpoint, error)
+	InsertOrUpdateBySchemaNameAndStep(ctx context.Context, m *ETLCheckpoint) error
+}
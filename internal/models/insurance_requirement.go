@@ -2,6 +2,18 @@ package models
 
 import (
 	"context"
+	"strings"
+)
+
+const (
+	InsuranceRequirementActiveState                            = 1
+	InsuranceRequirementInactiveState                          = 0
+	InsuranceRequirementNotTrackedAssociateDateTypeOf          = 0
+	InsuranceRequirementCommercialInsuranceAssociateDateTypeOf = 1
+	InsuranceRequirementAutoInsuranceAssociateDateTypeOf       = 2
+	InsuranceRequirementWsibInsuranceAssociateDateTypeOf       = 3
+	InsuranceRequirementPoliceCheckAssociateDateTypeOf         = 4
+	InsuranceRequirementDuesAssociateDateTypeOf                = 5
 )
 
 // State
@@ -9,6 +21,16 @@ import (
 // 1 = Active
 // 0 = Inactive
 
+//---------------------
+// associate_date_type_of
+//---------------------
+// 0 = Not tracked
+// 1 = Associate.CommercialInsuranceExpiryDate
+// 2 = Associate.AutoInsuranceExpiryDate
+// 3 = Associate.WsibInsuranceDate
+// 4 = Associate.PoliceCheck
+// 5 = Associate.DuesDate
+
 type InsuranceRequirement struct {
 	Id          uint64 `json:"id"`
 	Uuid        string `json:"uuid"`
@@ -17,6 +39,28 @@ type InsuranceRequirement struct {
 	Description string `json:"description"`
 	State       int8   `json:"state"`
 	OldId       uint64 `json:"old_id"`
+
+	// The date of the associate which the requirement expires on, the
+	// compliance of the associates is only tracked if it is set.
+	AssociateDateTypeOf int8 `json:"associate_date_type_of"`
+}
+
+// Function returns the date of the associate which the requirement expires
+// on based on the text of the requirement, this is used for the requirements
+// imported from the old system. Keep in sync with the `0016` migration.
+func GuessInsuranceRequirementAssociateDateTypeOf(text string) int8 {
+	text = strings.ToLower(text)
+	switch {
+	case strings.Contains(text, "commercial"):
+		return InsuranceRequirementCommercialInsuranceAssociateDateTypeOf
+	case strings.Contains(text, "auto"):
+		return InsuranceRequirementAutoInsuranceAssociateDateTypeOf
+	case strings.Contains(text, "wsib"):
+		return InsuranceRequirementWsibInsuranceAssociateDateTypeOf
+	case strings.Contains(text, "police"):
+		return InsuranceRequirementPoliceCheckAssociateDateTypeOf
+	}
+	return InsuranceRequirementNotTrackedAssociateDateTypeOf
 }
 
 type InsuranceRequirementRepository interface {
@@ -36,4 +36,5 @@ type LiteAssociate struct {
 type LiteAssociateRepository interface {
 	ListByFilter(ctx context.Context, filter *LiteAssociateFilter) ([]*LiteAssociate, error)
 	CountByFilter(ctx context.Context, filter *LiteAssociateFilter) (uint64, error)
+	StreamByFilter(ctx context.Context, filter *LiteAssociateFilter, fn func(m *LiteAssociate) error) error
 }
@@ -33,4 +33,5 @@ type LiteCustomer struct {
 type LiteCustomerRepository interface {
 	ListByFilter(ctx context.Context, filter *LiteCustomerFilter) ([]*LiteCustomer, error)
 	CountByFilter(ctx context.Context, filter *LiteCustomerFilter) (uint64, error)
+	StreamByFilter(ctx context.Context, filter *LiteCustomerFilter, fn func(m *LiteCustomer) error) error
 }
@@ -16,26 +16,53 @@ type LiteFinancialFilter struct {
 	AssociateLexicalName null.String `json:"associate_lexical_name"`
 	CustomerName         null.String `json:"customer_name"`
 	CustomerLexicalName  null.String `json:"customer_lexical_name"`
-	SortOrder            string      `json:"sort_order"`
-	SortField            string      `json:"sort_field"`
-	Search               null.String `json:"search"`
-	Offset               uint64      `json:"offset"`
-	Limit                uint64      `json:"limit"`
+	AssociateId          null.Int    `json:"associate_id"`
+	InvoicePaidTo        null.Int    `json:"invoice_paid_to"`
+	InvoiceDateGTE       null.Time   `json:"invoice_date_gte"`
+	InvoiceDateLT        null.Time   `json:"invoice_date_lt"`
+	HasBalanceOwing      bool        `json:"has_balance_owing"`
+
+	// Filter on the service fee payment date, either by whether the service
+	// fee was paid or by the range of the payment date.
+	ServiceFeePaymentDateIsNull null.Bool `json:"service_fee_payment_date_is_null"`
+	ServiceFeePaymentDateGTE    null.Time `json:"service_fee_payment_date_gte"`
+	ServiceFeePaymentDateLT     null.Time `json:"service_fee_payment_date_lt"`
+
+	SortOrder string      `json:"sort_order"`
+	SortField string      `json:"sort_field"`
+	Search    null.String `json:"search"`
+	Offset    uint64      `json:"offset"`
+	Limit     uint64      `json:"limit"`
 }
 
 type LiteFinancial struct {
-	Id                           uint64      `json:"id"`
-	TenantId                     uint64      `json:"tenant_id"`
-	CustomerId                   uint64      `json:"customer_id"`
-	CustomerName                 string      `json:"customer_name"`
-	AssociateId                  null.Int    `json:"associate_id"`
-	AssociateName                null.String `json:"associate_name"`
-	InvoiceServiceFeePaymentDate null.Time   `json:"invoice_service_fee_payment_date"`
-	TypeOf                       int8        `json:"type_of"`
-	State                        int8        `json:"state"`
+	Id                                uint64      `json:"id"`
+	TenantId                          uint64      `json:"tenant_id"`
+	CustomerId                        uint64      `json:"customer_id"`
+	CustomerName                      string      `json:"customer_name"`
+	AssociateId                       null.Int    `json:"associate_id"`
+	AssociateName                     null.String `json:"associate_name"`
+	InvoiceServiceFeePaymentDate      null.Time   `json:"invoice_service_fee_payment_date"`
+	TypeOf                            int8        `json:"type_of"`
+	State                             int8        `json:"state"`
+	Currency                          string      `json:"currency"`
+	InvoiceDate                       null.Time   `json:"invoice_date"`
+	InvoicePaidTo                     null.Int    `json:"invoice_paid_to"`
+	InvoiceServiceFeeAmount           Money       `json:"invoice_service_fee_amount"`
+	InvoiceActualServiceFeeAmountPaid Money       `json:"invoice_actual_service_fee_amount_paid"`
+	InvoiceBalanceOwingAmount         Money       `json:"invoice_balance_owing_amount"`
+}
+
+// The service fee totals of every work order matching the filter.
+type LiteFinancialTotals struct {
+	ServiceFeeAmount     Money `json:"service_fee_amount"`
+	ServiceFeeAmountPaid Money `json:"service_fee_amount_paid"`
+	BalanceOwingAmount   Money `json:"balance_owing_amount"`
 }
 
 type LiteFinancialRepository interface {
 	ListByFilter(ctx context.Context, filter *LiteFinancialFilter) ([]*LiteFinancial, error)
 	CountByFilter(ctx context.Context, filter *LiteFinancialFilter) (uint64, error)
+	SumByFilter(ctx context.Context, filter *LiteFinancialFilter) (*LiteFinancialTotals, error)
+	StreamByFilter(ctx context.Context, filter *LiteFinancialFilter, fn func(m *LiteFinancial) error) error
 }
@@ -10,14 +10,17 @@ import (
 // Structure used to encapsulate the various filters we want to apply when we
 // perform our `listing` functionality for the `LiteTaskItem` model.
 type LiteTaskItemFilter struct {
-	TenantId  uint64      `json:"tenant_id"`
-	States    []int8      `json:"states"`
-	SortOrder string      `json:"sort_order"`
-	SortField string      `json:"sort_field"`
-	IsClosed  null.Bool   `json:"is_closed"`
-	Search    null.String `json:"search"`
-	Offset    uint64      `json:"offset"`
-	Limit     uint64      `json:"limit"`
+	TenantId      uint64      `json:"tenant_id"`
+	States        []int8      `json:"states"`
+	SortOrder     string      `json:"sort_order"`
+	SortField     string      `json:"sort_field"`
+	IsClosed      null.Bool   `json:"is_closed"`
+	IsOverdue     null.Bool   `json:"is_overdue"`
+	EscalatedToId null.Int    `json:"escalated_to_id"`
+	AssociateId   null.Int    `json:"associate_id"`
+	Search        null.String `json:"search"`
+	Offset        uint64      `json:"offset"`
+	Limit         uint64      `json:"limit"`
 }
 
 type LiteTaskItem struct {
@@ -33,6 +36,8 @@ type LiteTaskItem struct {
 	AssociateName        null.String `json:"associate_name,omitempty"`
 	AssociateLexicalName null.String `json:"associate_lexical_name,omitempty"`
 	OrderTypeOf          int8        `json:"order_type_of"`
+	IsOverdue            bool        `json:"is_overdue"`
+	EscalatedToId        null.Int    `json:"escalated_to_id"`
 }
 
 type LiteTaskItemRepository interface {
@@ -13,6 +13,7 @@ type LiteWorkOrderFilter struct {
 	TenantId             uint64      `json:"tenant_id"`
 	States               []int8      `json:"states"`
 	LastModifiedById     null.Int    `json:"last_modified_by_id"`
+	AssociateId          null.Int    `json:"associate_id"`
 	AssociateName        null.String `json:"associate_name"`
 	AssociateLexicalName null.String `json:"associate_lexical_name"`
 	CustomerName         null.String `json:"customer_name"`
@@ -93,4 +94,5 @@ type LiteWorkOrder struct {
 type LiteWorkOrderRepository interface {
 	ListByFilter(ctx context.Context, filter *LiteWorkOrderFilter) ([]*LiteWorkOrder, error)
 	CountByFilter(ctx context.Context, filter *LiteWorkOrderFilter) (uint64, error)
+	StreamByFilter(ctx context.Context, filter *LiteWorkOrderFilter, fn func(m *LiteWorkOrder) error) error
 }
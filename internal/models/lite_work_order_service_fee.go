@@ -22,7 +22,7 @@ type LiteWorkOrderServiceFee struct {
 	Id          uint64  `json:"id"`
 	TenantId    uint64  `json:"tenant_id"`
 	Title       string  `json:"title"`
-	Percentage  float64 `json:"percentage"`
+	Percentage  Percent `json:"percentage"`
 	Description string  `json:"description"`
 	State       int8    `json:"state"`
 }
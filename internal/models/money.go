@@ -0,0 +1,259 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// The currency of our tenants if the record does not specify one.
+const DefaultCurrency = "CAD"
+
+// Function returns the currency code of the record in upper case or our
+// default currency if the record does not specify one. Every amount is in
+// the currency of its record so this is the only place which decides the
+// currency of an amount.
+func CurrencyOrDefault(currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return DefaultCurrency
+	}
+	return currency
+}
+
+// Money is an exact amount in cents, it is stored in the database as a
+// `NUMERIC(12,2)` and serialized to JSON as a number with two decimals so it
+// stays compatible with the `float64` amounts we used to return. Money does
+// not carry its currency, the currency comes from the `Currency` field of
+// the record (or the work order the record belongs to) and must always be
+// resolved with `CurrencyOrDefault`.
+//
+// All rounding of amounts happens in this file: amounts are always rounded
+// to the nearest cent with halves rounded away from zero.
+type Money int64
+
+// Percent is an exact percentage in ten-thousandths of a percent, ex: 12.5%
+// is stored as `125000`. It is stored in the database as a `NUMERIC(9,4)`.
+type Percent int64
+
+const percentScale = 10000
+
+// Function returns the money for the whole cents.
+func NewMoneyFromCents(cents int64) Money {
+	return Money(cents)
+}
+
+// Function returns the money for the float rounded to the nearest cent, this
+// should only be used when importing amounts which were stored as floats.
+func NewMoneyFromFloat(f float64) Money {
+	return Money(math.Round(f * 100))
+}
+
+// Function parses a decimal string, ex: "123.45", into money rounding to the
+// nearest cent.
+func ParseMoney(s string) (Money, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return 0, fmt.Errorf("invalid amount: %q", s)
+	}
+	return Money(roundRat(r.Mul(r, big.NewRat(100, 1)))), nil
+}
+
+func (m Money) Cents() int64 {
+	return int64(m)
+}
+
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+func (m Money) String() string {
+	sign := ""
+	cents := int64(m)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// Function returns the amount formatted for display, ex: "$1,234.50 CAD".
+func (m Money) Format(currency string) string {
+	currency = CurrencyOrDefault(currency)
+	s := m.String()
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign = "-"
+		s = s[1:]
+	}
+	whole, fraction := s[:len(s)-3], s[len(s)-3:]
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + "," + whole[i:]
+	}
+	return sign + "$" + whole + fraction + " " + currency
+}
+
+// Function returns the percentage of the amount, this is how our tax and
+// service fees are computed.
+func (m Money) MulPercent(p Percent) Money {
+	r := new(big.Rat).SetFrac64(int64(m)*int64(p), 100*percentScale)
+	return Money(roundRat(r))
+}
+
+// Function returns the amount multiplied by the quantity, ex: the quantity of
+// an invoice line times its unit price.
+func (m Money) MulQuantity(q float64) Money {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(q, 'f', -1, 64))
+	if !ok {
+		return 0
+	}
+	return Money(roundRat(r.Mul(r, new(big.Rat).SetInt64(int64(m)))))
+}
+
+// Scan implements the `sql.Scanner` interface.
+func (m *Money) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*m = 0
+	case []byte:
+		return m.Scan(string(v))
+	case string:
+		parsed, err := ParseMoney(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case float64:
+		*m = NewMoneyFromFloat(v)
+	case int64:
+		*m = Money(v * 100)
+	default:
+		return fmt.Errorf("cannot scan %T into money", value)
+	}
+	return nil
+}
+
+// Value implements the `driver.Valuer` interface.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts both a number and a string, the value is parsed
+// without going through a `float64` so it stays exact.
+func (m *Money) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		*m = 0
+		return nil
+	}
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Function parses a decimal string, ex: "13" or "12.5", into a percentage.
+func ParsePercent(s string) (Percent, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return 0, fmt.Errorf("invalid percentage: %q", s)
+	}
+	return Percent(roundRat(r.Mul(r, big.NewRat(percentScale, 1)))), nil
+}
+
+// Function returns the percentage for the float, this should only be used
+// when importing percentages which were stored as floats.
+func NewPercentFromFloat(f float64) Percent {
+	return Percent(math.Round(f * percentScale))
+}
+
+func (p Percent) Float64() float64 {
+	return float64(p) / percentScale
+}
+
+func (p Percent) String() string {
+	return new(big.Rat).SetFrac64(int64(p), percentScale).FloatString(4)
+}
+
+// Scan implements the `sql.Scanner` interface.
+func (p *Percent) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*p = 0
+	case []byte:
+		return p.Scan(string(v))
+	case string:
+		parsed, err := ParsePercent(v)
+		if err != nil {
+			return err
+		}
+		*p = parsed
+	case float64:
+		*p = NewPercentFromFloat(v)
+	case int64:
+		*p = Percent(v * percentScale)
+	default:
+		return fmt.Errorf("cannot scan %T into percent", value)
+	}
+	return nil
+}
+
+// Value implements the `driver.Valuer` interface.
+func (p Percent) Value() (driver.Value, error) {
+	return p.String(), nil
+}
+
+func (p Percent) MarshalJSON() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Percent) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		*p = 0
+		return nil
+	}
+	parsed, err := ParsePercent(s)
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+// Function rounds the rational to the nearest integer with halves rounded
+// away from zero.
+func roundRat(r *big.Rat) int64 {
+	num := new(big.Int).Set(r.Num())
+	den := r.Denom()
+	neg := num.Sign() < 0
+	num.Abs(num)
+
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Lsh(rem, 1).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if neg {
+		q.Neg(q)
+	}
+	return q.Int64()
+}
+
+// Function returns the tax owing on the amount.
+func ComputeTax(amount Money, rate Percent) Money {
+	return amount.MulPercent(rate)
+}
+
+// Function returns the service fee owing on the amount.
+func ComputeServiceFee(amount Money, rate Percent) Money {
+	return amount.MulPercent(rate)
+}
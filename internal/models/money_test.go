@@ -0,0 +1,123 @@
+package models
+
+import "testing"
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Money
+		wantErr bool
+	}{
+		{"123.45", 12345, false},
+		{"10", 1000, false},
+		{" 1.2 ", 120, false},
+		{"0.005", 1, false},
+		{"-0.005", -1, false},
+		{"0.004", 0, false},
+		{"abc", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMoney(%q) error = %v, want error %v", tt.s, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMoney(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyFormat(t *testing.T) {
+	tests := []struct {
+		m        Money
+		currency string
+		want     string
+	}{
+		{123456, "CAD", "$1,234.56 CAD"},
+		{100000000, "", "$1,000,000.00 CAD"},
+		{-5, " usd ", "-$0.05 USD"},
+		{-123456, "CAD", "-$1,234.56 CAD"},
+		{99900, "CAD", "$999.00 CAD"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.Format(tt.currency); got != tt.want {
+			t.Errorf("Money(%d).Format(%q) = %q, want %q", tt.m, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyMulPercent(t *testing.T) {
+	tests := []struct {
+		m    Money
+		p    Percent
+		want Money
+	}{
+		{1000, NewPercentFromFloat(13), 130},
+		{999, NewPercentFromFloat(13), 130},
+		{12345, NewPercentFromFloat(12.5), 1543},
+		{1, NewPercentFromFloat(50), 1},
+		{-1, NewPercentFromFloat(50), -1},
+		{1000, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.m.MulPercent(tt.p); got != tt.want {
+			t.Errorf("Money(%d).MulPercent(%s) = %d, want %d", tt.m, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyMulQuantity(t *testing.T) {
+	tests := []struct {
+		m    Money
+		q    float64
+		want Money
+	}{
+		{1999, 3, 5997},
+		{1999, 1.5, 2999},
+		{333, 0.1, 33},
+		{100, 0, 0},
+		{-1999, 1.5, -2999},
+	}
+	for _, tt := range tests {
+		if got := tt.m.MulQuantity(tt.q); got != tt.want {
+			t.Errorf("Money(%d).MulQuantity(%v) = %d, want %d", tt.m, tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestComputeTax(t *testing.T) {
+	tests := []struct {
+		amount Money
+		rate   Percent
+		want   Money
+	}{
+		{10000, NewPercentFromFloat(13), 1300},
+		{5, NewPercentFromFloat(13), 1},
+		{3, NewPercentFromFloat(13), 0},
+		{10000, 0, 0},
+		{0, NewPercentFromFloat(13), 0},
+	}
+	for _, tt := range tests {
+		if got := ComputeTax(tt.amount, tt.rate); got != tt.want {
+			t.Errorf("ComputeTax(%d, %s) = %d, want %d", tt.amount, tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestCurrencyOrDefault(t *testing.T) {
+	tests := []struct {
+		currency string
+		want     string
+	}{
+		{"", DefaultCurrency},
+		{"   ", DefaultCurrency},
+		{"usd", "USD"},
+		{" cad ", "CAD"},
+	}
+	for _, tt := range tests {
+		if got := CurrencyOrDefault(tt.currency); got != tt.want {
+			t.Errorf("CurrencyOrDefault(%q) = %q, want %q", tt.currency, got, tt.want)
+		}
+	}
+}
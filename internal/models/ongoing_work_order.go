@@ -7,6 +7,15 @@ import (
 	null "gopkg.in/guregu/null.v4"
 )
 
+const (
+	OngoingWorkOrderActiveState   = 1
+	OngoingWorkOrderInactiveState = 0
+
+	// The number of days ahead of time the jobs of the ongoing work orders
+	// get created.
+	OngoingWorkOrderGenerateAheadDays = 30
+)
+
 // State
 //---------------------
 // 1 = Active
@@ -32,6 +41,20 @@ type OngoingWorkOrder struct {
 	LastModifiedByName   null.String `json:"last_modified_by_name"`
 	LastModifiedFromIP   null.String `json:"last_modified_from_ip"`
 	OldId                uint64      `json:"old_id"`
+	Description          string      `json:"description"`
+	TypeOf               int8        `json:"type_of"`
+	IsHomeSupportService bool        `json:"is_home_support_service"`
+	RecurrenceRule       string      `json:"recurrence_rule"`
+	RecurrenceStartDate  null.Time   `json:"recurrence_start_date"`
+	RecurrenceEndDate    null.Time   `json:"recurrence_end_date"`
+	GeneratedUntil       null.Time   `json:"generated_until"`
+}
+
+// OngoingWorkOrderOccurrence is the job created for a single occurrence of
+// the ongoing work order along with its first task.
+type OngoingWorkOrderOccurrence struct {
+	Order *WorkOrder `json:"order"`
+	Task  *TaskItem  `json:"task"`
 }
 
 type OngoingWorkOrderRepository interface {
@@ -41,4 +64,17 @@ type OngoingWorkOrderRepository interface {
 	GetIdByOldId(ctx context.Context, tid uint64, oid uint64) (uint64, error)
 	CheckIfExistsById(ctx context.Context, id uint64) (bool, error)
 	InsertOrUpdateById(ctx context.Context, u *OngoingWorkOrder) error
+
+	// Function returns the active ongoing work orders with a recurrence rule
+	// whose jobs have not been created up to `until`.
+	ListIdsDueForGeneration(ctx context.Context, until time.Time, limit uint64) ([]uint64, error)
+
+	// Function returns the active ongoing work orders with a recurrence rule
+	// which are assigned to the associate.
+	ListIdsByAssociateId(ctx context.Context, associateId uint64) ([]uint64, error)
+
+	// Function creates the jobs and their first task and saves the date the
+	// jobs of the ongoing work order were created up to, all within a single
+	// transaction.
+	InsertOccurrences(ctx context.Context, u *OngoingWorkOrder, arr []*OngoingWorkOrderOccurrence, generatedUntil time.Time) error
 }
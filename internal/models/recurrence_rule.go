@@ -0,0 +1,125 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	RecurrenceWeeklyFrequency  = "WEEKLY"
+	RecurrenceMonthlyFrequency = "MONTHLY"
+)
+
+// RecurrenceRule is the subset of the iCalendar RRULE which is supported for
+// the ongoing work orders, for example:
+//
+//	FREQ=WEEKLY                 Every week on the weekday of the start date.
+//	FREQ=WEEKLY;INTERVAL=2      Every other week on the weekday of the start date.
+//	FREQ=MONTHLY;BYMONTHDAY=15  Every month on the 15th.
+//
+// Unlike the RRULE a month which is too short for `BYMONTHDAY` falls on its
+// last day instead of being skipped.
+type RecurrenceRule struct {
+	Frequency  string
+	Interval   int
+	ByMonthDay int
+}
+
+// Function parses the rule or returns an error describing the first part of
+// the rule which is not supported.
+func ParseRecurrenceRule(s string) (*RecurrenceRule, error) {
+	r := &RecurrenceRule{Interval: 1}
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(s), "RRULE:"), ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.New("invalid rule part " + part)
+		}
+		key, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		switch key {
+		case "FREQ":
+			if value != RecurrenceWeeklyFrequency && value != RecurrenceMonthlyFrequency {
+				return nil, errors.New("frequency must be WEEKLY or MONTHLY")
+			}
+			r.Frequency = value
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 2 {
+				return nil, errors.New("interval must be 1 or 2")
+			}
+			r.Interval = n
+		case "BYMONTHDAY":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 31 {
+				return nil, errors.New("month day must be between 1 and 31")
+			}
+			r.ByMonthDay = n
+		default:
+			return nil, errors.New("unsupported rule part " + key)
+		}
+	}
+
+	switch r.Frequency {
+	case RecurrenceWeeklyFrequency:
+		if r.ByMonthDay != 0 {
+			return nil, errors.New("month day is only supported by the MONTHLY frequency")
+		}
+	case RecurrenceMonthlyFrequency:
+		if r.ByMonthDay == 0 {
+			return nil, errors.New("month day is required by the MONTHLY frequency")
+		}
+		if r.Interval != 1 {
+			return nil, errors.New("interval is only supported by the WEEKLY frequency")
+		}
+	default:
+		return nil, errors.New("frequency is required")
+	}
+	return r, nil
+}
+
+// Function returns the rule in its canonical form.
+func (r *RecurrenceRule) String() string {
+	s := "FREQ=" + r.Frequency
+	if r.Interval > 1 {
+		s += ";INTERVAL=" + strconv.Itoa(r.Interval)
+	}
+	if r.ByMonthDay > 0 {
+		s += ";BYMONTHDAY=" + strconv.Itoa(r.ByMonthDay)
+	}
+	return s
+}
+
+// Function returns the occurrences of the rule which fall after `after` and
+// up to and including `until`. The occurrences keep the time of day and the
+// location of `start` which is the first possible occurrence.
+func (r *RecurrenceRule) Occurrences(start time.Time, after time.Time, until time.Time) []time.Time {
+	var arr []time.Time
+	for i := 0; ; i++ {
+		var t time.Time
+		if r.Frequency == RecurrenceWeeklyFrequency {
+			t = start.AddDate(0, 0, 7*r.Interval*i)
+		} else {
+			t = monthDayOf(start, i, r.ByMonthDay)
+			if t.Before(start) {
+				continue
+			}
+		}
+		if t.After(until) {
+			return arr
+		}
+		if t.After(after) {
+			arr = append(arr, t)
+		}
+	}
+}
+
+// Function returns the day of the month `months` after the month of `start`,
+// the day falls on the last day of the month if the month is too short.
+func monthDayOf(start time.Time, months int, day int) time.Time {
+	first := time.Date(start.Year(), start.Month(), 1, start.Hour(), start.Minute(), start.Second(), 0, start.Location()).AddDate(0, months, 0)
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
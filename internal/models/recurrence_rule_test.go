@@ -0,0 +1,97 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    string
+		wantErr bool
+	}{
+		{"FREQ=WEEKLY", "FREQ=WEEKLY", false},
+		{"RRULE:FREQ=WEEKLY;INTERVAL=2", "FREQ=WEEKLY;INTERVAL=2", false},
+		{"FREQ=WEEKLY;INTERVAL=1", "FREQ=WEEKLY", false},
+		{"freq=monthly;bymonthday=15", "FREQ=MONTHLY;BYMONTHDAY=15", false},
+		{"", "", true},
+		{"FREQ", "", true},
+		{"FREQ=DAILY", "", true},
+		{"INTERVAL=2", "", true},
+		{"FREQ=WEEKLY;INTERVAL=3", "", true},
+		{"FREQ=WEEKLY;BYMONTHDAY=1", "", true},
+		{"FREQ=WEEKLY;COUNT=3", "", true},
+		{"FREQ=MONTHLY", "", true},
+		{"FREQ=MONTHLY;BYMONTHDAY=32", "", true},
+		{"FREQ=MONTHLY;BYMONTHDAY=1;INTERVAL=2", "", true},
+	}
+	for _, tt := range tests {
+		r, err := ParseRecurrenceRule(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRecurrenceRule(%q) error = %v, want error %v", tt.s, err, tt.wantErr)
+			continue
+		}
+		if err == nil && r.String() != tt.want {
+			t.Errorf("ParseRecurrenceRule(%q) = %q, want %q", tt.s, r.String(), tt.want)
+		}
+	}
+}
+
+func TestRecurrenceRuleOccurrences(t *testing.T) {
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2026, month, d, 9, 0, 0, 0, time.UTC)
+	}
+	start := day(time.January, 5) // A Monday.
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		after time.Time
+		until time.Time
+		want  []time.Time
+	}{
+		{
+			"weekly including until", "FREQ=WEEKLY", start, start.Add(-time.Nanosecond), day(time.January, 26),
+			[]time.Time{day(time.January, 5), day(time.January, 12), day(time.January, 19), day(time.January, 26)},
+		},
+		{
+			"weekly after", "FREQ=WEEKLY", start, day(time.January, 12), day(time.January, 26),
+			[]time.Time{day(time.January, 19), day(time.January, 26)},
+		},
+		{
+			"biweekly", "FREQ=WEEKLY;INTERVAL=2", start, start.Add(-time.Nanosecond), day(time.February, 1),
+			[]time.Time{day(time.January, 5), day(time.January, 19)},
+		},
+		{
+			"monthly skips the day before the start", "FREQ=MONTHLY;BYMONTHDAY=1", start, start.Add(-time.Nanosecond), day(time.March, 31),
+			[]time.Time{day(time.February, 1), day(time.March, 1)},
+		},
+		{
+			"monthly on the last day of short months", "FREQ=MONTHLY;BYMONTHDAY=31", start, start.Add(-time.Nanosecond), day(time.April, 1),
+			[]time.Time{day(time.January, 31), day(time.February, 28), day(time.March, 31)},
+		},
+		{
+			"nothing before until", "FREQ=WEEKLY", start, start.Add(-time.Nanosecond), day(time.January, 4),
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		r, err := ParseRecurrenceRule(tt.rule)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got := r.Occurrences(tt.start, tt.after, tt.until)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: Occurrences() = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if !got[i].Equal(tt.want[i]) {
+				t.Errorf("%s: Occurrences() = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	null "gopkg.in/guregu/null.v4"
@@ -17,16 +18,55 @@ const (
 // 1 = Active
 // 0 = Inactive
 
+// The part of the work order which the task operation changes, only the
+// columns of that part get saved when the task is closed.
+const (
+	TaskWorkOrderNoChange         = 0
+	TaskWorkOrderAssociateChange  = 1
+	TaskWorkOrderClosingChange    = 2
+	TaskWorkOrderCompletionChange = 3
+)
+
+// ErrTaskItemClosed is returned when closing a task which someone else has
+// already closed.
+var ErrTaskItemClosed = errors.New("task is already closed")
+
+// TypeOf
+//---------------------
+// 1 = Assign an associate to the work order.
+// 2 = Follow up with the customer if the job is complete.
+// 3 = Follow up with the customer survey.
+// 4 = Follow up if the associate and customer agreed to meet.
+// 5 = Update the ongoing work order.
+
+const (
+	TaskAssignAssociateTypeOf                             = 1
+	TaskFollowUpIsJobCompleteTypeOf                       = 2
+	TaskFollowUpCustomerSurveyTypeOf                      = 3
+	TaskFollowUpDidAssociateAndCustomerAgreedToMeetTypeOf = 4
+	TaskUpdateOngoingJobTypeOf                            = 5
+)
+
+// TaskItemTypeOfTitles is the title given to the tasks created by the task
+// workflow for each type of task.
+var TaskItemTypeOfTitles = map[int8]string{
+	TaskAssignAssociateTypeOf:                             "Assign associate",
+	TaskFollowUpIsJobCompleteTypeOf:                       "Follow up: is job complete?",
+	TaskFollowUpCustomerSurveyTypeOf:                      "Follow up: customer survey",
+	TaskFollowUpDidAssociateAndCustomerAgreedToMeetTypeOf: "Follow up: did associate and customer agree to meet?",
+	TaskUpdateOngoingJobTypeOf:                            "Update ongoing job",
+}
+
 type TaskItem struct {
 	Id                   uint64      `json:"id"`                               // 01
 	Uuid                 string      `json:"uuid"`                             // 02
 	TenantId             uint64      `json:"tenant_id"`                        // 03
-	TypeOf               string      `json:"type_of"`                          // 04
+	TypeOf               int8        `json:"type_of"`                          // 04
 	Title                string      `json:"title"`                            // 05
 	Description          string      `json:"description"`                      // 06
 	DueDate              time.Time   `json:"due_date"`                         // 07
 	IsClosed             bool        `json:"is_closed"`                        // 08
-	WasPostponed         string      `json:"was_postponed"`                    // 09
+	WasPostponed         bool        `json:"was_postponed"`                    // 09
 	ClosingReason        int8        `json:"closing_reason"`                   // 10
 	ClosingReasonOther   string      `json:"closing_reason_other"`             // 11
 	OrderId              uint64      `json:"order_id"`                         // 12
@@ -48,6 +88,9 @@ type TaskItem struct {
 	AssociateName        null.String `json:"associate_name,omitempty"`         // 26
 	AssociateLexicalName null.String `json:"associate_lexical_name,omitempty"` // 27
 	OrderTypeOf          int8        `json:"order_type_of"`                    // 28
+	IsOverdue            bool        `json:"is_overdue"`                       // 31
+	EscalatedTime        null.Time   `json:"escalated_time"`                   // 32
+	EscalatedToId        null.Int    `json:"escalated_to_id"`                  // 33
 }
 
 type TaskItemRepository interface {
@@ -57,4 +100,30 @@ type TaskItemRepository interface {
 	GetIdByOldId(ctx context.Context, tid uint64, oid uint64) (uint64, error)
 	CheckIfExistsById(ctx context.Context, id uint64) (bool, error)
 	InsertOrUpdateById(ctx context.Context, u *TaskItem) error
+
+	// Function closes the task, creates the next task of the work order, if
+	// there is one, and saves the `change` part of the work order pointing at
+	// the next task all within a single transaction. The work order is read
+	// again afterwards and `ErrTaskItemClosed` is returned if the task was
+	// closed in the meantime.
+	CloseAndCreateNext(ctx context.Context, u *TaskItem, order *WorkOrder, next *TaskItem, change int8) error
+
+	// Function closes the assign associate task like `CloseAndCreateNext` and
+	// records the associate accepting the job in the activity sheet, the
+	// item is updated if it was already saved as the offer of the job, within
+	// the same transaction.
+	CloseAndAssignAssociate(ctx context.Context, u *TaskItem, order *WorkOrder, next *TaskItem, item *ActivitySheetItem) error
+
+	// Function creates the task and saves it as the latest pending task of
+	// its work order within a single transaction.
+	InsertForWorkOrder(ctx context.Context, u *TaskItem) error
+
+	// Function flags every open task which is past its due date as overdue
+	// and returns the number of tasks which got flagged.
+	UpdateOverdue(ctx context.Context, now time.Time) (int64, error)
+
+	// Function escalates every open task which is overdue by more than the
+	// `escalate after days` of its tenant and returns the number of tasks
+	// which got escalated.
+	EscalateOverdue(ctx context.Context, now time.Time) (int64, error)
 }
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// TenantAccountMapping holds the names of the accounts in the accounting
+// software of the tenant which our journal entries are exported to.
+type TenantAccountMapping struct {
+	Id                 uint64    `json:"id"`
+	TenantId           uint64    `json:"tenant_id"`
+	AccountsReceivable string    `json:"accounts_receivable"`
+	LabourIncome       string    `json:"labour_income"`
+	MaterialsIncome    string    `json:"materials_income"`
+	OtherIncome        string    `json:"other_income"`
+	SalesTaxPayable    string    `json:"sales_tax_payable"`
+	UndepositedFunds   string    `json:"undeposited_funds"`
+	AssociateClearing  string    `json:"associate_clearing"`
+	ServiceFeeIncome   string    `json:"service_fee_income"`
+	CreatedTime        time.Time `json:"created_time"`
+	LastModifiedTime   time.Time `json:"last_modified_time"`
+}
+
+// Function returns the account mapping used by tenants who have not
+// configured their own, the names match the database defaults.
+func NewDefaultTenantAccountMapping(tenantId uint64) *TenantAccountMapping {
+	return &TenantAccountMapping{
+		TenantId:           tenantId,
+		AccountsReceivable: "Accounts Receivable",
+		LabourIncome:       "Labour Income",
+		MaterialsIncome:    "Materials Income",
+		OtherIncome:        "Other Income",
+		SalesTaxPayable:    "Sales Tax Payable",
+		UndepositedFunds:   "Undeposited Funds",
+		AssociateClearing:  "Associate Clearing",
+		ServiceFeeIncome:   "Service Fee Income",
+	}
+}
+
+type TenantAccountMappingRepository interface {
+	GetByTenantId(ctx context.Context, tenantId uint64) (*TenantAccountMapping, error)
+	InsertOrUpdateByTenantId(ctx context.Context, m *TenantAccountMapping) error
+}
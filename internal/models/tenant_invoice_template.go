@@ -0,0 +1,23 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// TenantInvoiceTemplate holds the per-tenant details which are printed on
+// the invoices in addition to the name and address of the `Tenant`.
+type TenantInvoiceTemplate struct {
+	Id                    uint64    `json:"id"`
+	TenantId              uint64    `json:"tenant_id"`
+	LogoS3Key             string    `json:"logo_s3_key"`
+	TaxRegistrationNumber string    `json:"tax_registration_number"`
+	FooterText            string    `json:"footer_text"`
+	CreatedTime           time.Time `json:"created_time"`
+	LastModifiedTime      time.Time `json:"last_modified_time"`
+}
+
+type TenantInvoiceTemplateRepository interface {
+	GetByTenantId(ctx context.Context, tenantId uint64) (*TenantInvoiceTemplate, error)
+	InsertOrUpdateByTenantId(ctx context.Context, m *TenantInvoiceTemplate) error
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+)
+
+const (
+	TenantTaskSettingDefaultFollowUpAfterDays = 7
+	TenantTaskSettingDefaultEscalateAfterDays = 3
+)
+
+// TenantTaskSetting controls how the scheduler creates and escalates the
+// tasks of the tenant. If `EscalateToId` is not set then the overdue tasks
+// get escalated to the first active manager of the tenant.
+type TenantTaskSetting struct {
+	Id                uint64    `json:"id"`
+	TenantId          uint64    `json:"tenant_id"`
+	FollowUpAfterDays int16     `json:"follow_up_after_days"`
+	EscalateAfterDays int16     `json:"escalate_after_days"`
+	EscalateToId      null.Int  `json:"escalate_to_id"`
+	CreatedTime       time.Time `json:"created_time"`
+	LastModifiedTime  time.Time `json:"last_modified_time"`
+}
+
+type TenantTaskSettingRepository interface {
+	GetByTenantId(ctx context.Context, tenantId uint64) (*TenantTaskSetting, error)
+	InsertOrUpdateByTenantId(ctx context.Context, m *TenantTaskSetting) error
+}
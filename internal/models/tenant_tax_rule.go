@@ -0,0 +1,121 @@
+package models
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+)
+
+// TypeOf
+//---------------------
+// 0 = Applies to every type of work order
+// 1 = Residential | WorkOrderResidentialTypeOf
+// 2 = Commercial | WorkOrderCommercialTypeOf
+// 3 = Unassigned | WorkOrderUnassignedTypeOf
+
+const TenantTaxRuleAnyTypeOf = 0
+
+// TenantTaxRule is a tax, ex: HST, GST or PST, which the tenant charges on
+// the invoices of their work orders. Several rules can apply to the same
+// invoice, ex: GST and PST.
+type TenantTaxRule struct {
+	Id               uint64    `json:"id"`
+	Uuid             string    `json:"uuid"`
+	TenantId         uint64    `json:"tenant_id"`
+	Name             string    `json:"name"`
+	Rate             Percent   `json:"rate"`
+	TypeOf           int8      `json:"type_of"`
+	EffectiveFrom    time.Time `json:"effective_from"`
+	EffectiveTo      null.Time `json:"effective_to"`
+	CreatedTime      time.Time `json:"created_time"`
+	CreatedById      null.Int  `json:"created_by_id"`
+	LastModifiedTime time.Time `json:"last_modified_time"`
+	LastModifiedById null.Int  `json:"last_modified_by_id"`
+}
+
+// Function returns true if both rules charge the same tax, going by their
+// name, on the same type of work order on at least one common day, the tax
+// would otherwise be charged twice on the invoice. The `EffectiveTo` date is
+// exclusive and a null date means the rule never ends.
+func (m *TenantTaxRule) Overlaps(o *TenantTaxRule) bool {
+	if !strings.EqualFold(strings.TrimSpace(m.Name), strings.TrimSpace(o.Name)) {
+		return false
+	}
+	if m.TypeOf != TenantTaxRuleAnyTypeOf && o.TypeOf != TenantTaxRuleAnyTypeOf && m.TypeOf != o.TypeOf {
+		return false
+	}
+	return (!o.EffectiveTo.Valid || m.EffectiveFrom.Before(o.EffectiveTo.Time)) &&
+		(!m.EffectiveTo.Valid || o.EffectiveFrom.Before(m.EffectiveTo.Time))
+}
+
+type TenantTaxRuleRepository interface {
+	Insert(ctx context.Context, m *TenantTaxRule) error
+	UpdateById(ctx context.Context, m *TenantTaxRule) error
+	GetById(ctx context.Context, id uint64) (*TenantTaxRule, error)
+	DeleteById(ctx context.Context, id uint64) error
+	ListByTenantId(ctx context.Context, tenantId uint64) ([]*TenantTaxRule, error)
+	ListEffectiveByTenantId(ctx context.Context, tenantId uint64, typeOf int8, at time.Time) ([]*TenantTaxRule, error)
+}
+
+// AppliedTaxRule is the snapshot of the tax rule which is stored with the
+// invoice so later changes to the rule do not change issued invoices.
+type AppliedTaxRule struct {
+	RuleId uint64  `json:"rule_id"`
+	Name   string  `json:"name"`
+	Rate   Percent `json:"rate"`
+	Amount Money   `json:"amount"`
+}
+
+// AppliedTaxRules is stored in the database as a JSON array.
+type AppliedTaxRules []*AppliedTaxRule
+
+// Function returns the tax of every rule on the amount along with the total
+// tax, the tax of every rule is rounded on its own since that is how it gets
+// printed on the invoice.
+func ApplyTaxRules(amount Money, rules []*TenantTaxRule) (Money, AppliedTaxRules) {
+	var total Money
+	applied := AppliedTaxRules{}
+	for _, rule := range rules {
+		tax := ComputeTax(amount, rule.Rate)
+		total += tax
+		applied = append(applied, &AppliedTaxRule{
+			RuleId: rule.Id,
+			Name:   rule.Name,
+			Rate:   rule.Rate,
+			Amount: tax,
+		})
+	}
+	return total, applied
+}
+
+// Scan implements the `sql.Scanner` interface.
+func (a *AppliedTaxRules) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*a = AppliedTaxRules{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	default:
+		return fmt.Errorf("cannot scan %T into applied tax rules", value)
+	}
+}
+
+// Value implements the `driver.Valuer` interface.
+func (a AppliedTaxRules) Value() (driver.Value, error) {
+	if a == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
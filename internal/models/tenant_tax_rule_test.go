@@ -0,0 +1,71 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+)
+
+func TestApplyTaxRules(t *testing.T) {
+	gst := &TenantTaxRule{Id: 1, Name: "GST", Rate: NewPercentFromFloat(5)}
+	pst := &TenantTaxRule{Id: 2, Name: "PST", Rate: NewPercentFromFloat(8)}
+
+	tests := []struct {
+		name    string
+		amount  Money
+		rules   []*TenantTaxRule
+		want    Money
+		applied []Money
+	}{
+		{"no rules", 10000, nil, 0, []Money{}},
+		{"single rule", 10000, []*TenantTaxRule{gst}, 500, []Money{500}},
+		{"rules rounded on their own", 1001, []*TenantTaxRule{gst, pst}, 130, []Money{50, 80}},
+	}
+	for _, tt := range tests {
+		got, applied := ApplyTaxRules(tt.amount, tt.rules)
+		if got != tt.want {
+			t.Errorf("%s: tax = %d, want %d", tt.name, got, tt.want)
+		}
+		if len(applied) != len(tt.applied) {
+			t.Errorf("%s: %d applied rules, want %d", tt.name, len(applied), len(tt.applied))
+			continue
+		}
+		for i, a := range applied {
+			if a.Amount != tt.applied[i] || a.RuleId != tt.rules[i].Id {
+				t.Errorf("%s: applied rule %d = %+v, want rule %d with %d", tt.name, i, a, tt.rules[i].Id, tt.applied[i])
+			}
+		}
+	}
+}
+
+func TestTenantTaxRuleOverlaps(t *testing.T) {
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	jul := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	dec := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	hst := &TenantTaxRule{Name: "HST", TypeOf: WorkOrderResidentialTypeOf, EffectiveFrom: jan, EffectiveTo: null.TimeFrom(jul)}
+
+	tests := []struct {
+		name string
+		rule *TenantTaxRule
+		want bool
+	}{
+		{"same period", &TenantTaxRule{Name: "HST", TypeOf: WorkOrderResidentialTypeOf, EffectiveFrom: jan, EffectiveTo: null.TimeFrom(jul)}, true},
+		{"name differs in case", &TenantTaxRule{Name: " hst ", TypeOf: WorkOrderResidentialTypeOf, EffectiveFrom: jan}, true},
+		{"other tax", &TenantTaxRule{Name: "PST", TypeOf: WorkOrderResidentialTypeOf, EffectiveFrom: jan}, false},
+		{"other type of work order", &TenantTaxRule{Name: "HST", TypeOf: WorkOrderCommercialTypeOf, EffectiveFrom: jan}, false},
+		{"any type of work order", &TenantTaxRule{Name: "HST", TypeOf: TenantTaxRuleAnyTypeOf, EffectiveFrom: jan}, true},
+		{"starts when the other ends", &TenantTaxRule{Name: "HST", TypeOf: WorkOrderResidentialTypeOf, EffectiveFrom: jul}, false},
+		{"starts before the other ends", &TenantTaxRule{Name: "HST", TypeOf: WorkOrderResidentialTypeOf, EffectiveFrom: jul.Add(-time.Hour), EffectiveTo: null.TimeFrom(dec)}, true},
+		{"ends when the other starts", &TenantTaxRule{Name: "HST", TypeOf: WorkOrderResidentialTypeOf, EffectiveFrom: jan.AddDate(-1, 0, 0), EffectiveTo: null.TimeFrom(jan)}, false},
+		{"never ends", &TenantTaxRule{Name: "HST", TypeOf: WorkOrderResidentialTypeOf, EffectiveFrom: jan.AddDate(-1, 0, 0)}, true},
+	}
+	for _, tt := range tests {
+		if got := hst.Overlaps(tt.rule); got != tt.want {
+			t.Errorf("%s: Overlaps() = %v, want %v", tt.name, got, tt.want)
+		}
+		if got := tt.rule.Overlaps(hst); got != tt.want {
+			t.Errorf("%s: reversed Overlaps() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -12,6 +12,47 @@ import (
 // 1 = Active
 // 0 = Inactive
 
+//---------------------
+// deposit_method
+//---------------------
+// 1 = Debit
+// 2 = Credit
+// 3 = Cheque
+// 4 = Cash
+// 5 = Other
+
+//---------------------
+// paid_to
+//---------------------
+// 1 = Paid to associate
+// 2 = Paid to organization
+
+//---------------------
+// paid_for
+//---------------------
+// 1 = Labour
+// 2 = Materials
+// 3 = Other Costs
+
+const (
+	WorkOrderDepositInactiveState      = 0
+	WorkOrderDepositActiveState        = 1
+	WorkOrderDepositDebitMethod        = 1
+	WorkOrderDepositCreditMethod       = 2
+	WorkOrderDepositChequeMethod       = 3
+	WorkOrderDepositCashMethod         = 4
+	WorkOrderDepositOtherMethod        = 5
+	WorkOrderDepositPaidToAssociate    = 1
+	WorkOrderDepositPaidToOrganization = 2
+	WorkOrderDepositPaidForLabour      = 1
+	WorkOrderDepositPaidForMaterials   = 2
+	WorkOrderDepositPaidForOtherCosts  = 3
+)
+
+// Deposits are never deleted, a refund is recorded as a deposit with a
+// negative amount and a reversal of a deposit which was entered by mistake
+// is recorded as a negative deposit with `ReversalOfId` pointing to it.
+
 type WorkOrderDeposit struct {
 	Id                 uint64      `json:"id"`
 	Uuid               string      `json:"uuid"`
@@ -21,7 +62,7 @@ type WorkOrderDeposit struct {
 	DepositMethod      int8        `json:"deposit_method"`
 	PaidTo             null.Int    `json:"paid_to"`
 	Currency           string      `json:"currency"`
-	Amount             float64     `json:"amount"`
+	Amount             Money       `json:"amount"`
 	PaidFor            int8        `json:"paid_for"`
 	CreatedTime        time.Time   `json:"created_time"`
 	CreatedById        null.Int    `json:"created_by_id"`
@@ -33,6 +74,7 @@ type WorkOrderDeposit struct {
 	LastModifiedFromIP null.String `json:"last_modified_from_ip"`
 	State              int8        `json:"state"`
 	OldId              uint64      `json:"old_id"`
+	ReversalOfId       null.Int    `json:"reversal_of_id"`
 }
 
 type WorkOrderDepositRepository interface {
@@ -42,4 +84,7 @@ type WorkOrderDepositRepository interface {
 	GetIdByOldId(ctx context.Context, tid uint64, oid uint64) (uint64, error)
 	CheckIfExistsById(ctx context.Context, id uint64) (bool, error)
 	InsertOrUpdateById(ctx context.Context, u *WorkOrderDeposit) error
+	ListByOrderId(ctx context.Context, orderId uint64) ([]*WorkOrderDeposit, error)
+	GetByReversalOfId(ctx context.Context, id uint64) (*WorkOrderDeposit, error)
+	InsertAndUpdateOrderBalance(ctx context.Context, u *WorkOrderDeposit, rev *WorkOrderInvoiceRevision) error
 }
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+)
+
+// TypeOf
+//---------------------
+// 1 = Labour
+// 2 = Materials
+// 3 = Other Costs
+
+const (
+	WorkOrderInvoiceLineItemLabourTypeOf     = 1
+	WorkOrderInvoiceLineItemMaterialsTypeOf  = 2
+	WorkOrderInvoiceLineItemOtherCostsTypeOf = 3
+)
+
+type WorkOrderInvoiceLineItem struct {
+	Id                 uint64      `json:"id"`
+	Uuid               string      `json:"uuid"`
+	TenantId           uint64      `json:"tenant_id"`
+	InvoiceId          uint64      `json:"invoice_id"`
+	SortNumber         int16       `json:"sort_number"`
+	TypeOf             int8        `json:"type_of"`
+	Quantity           float64     `json:"quantity"`
+	Description        string      `json:"description"`
+	UnitPrice          Money       `json:"unit_price"`
+	Amount             Money       `json:"amount"`
+	Notes              string      `json:"notes"`
+	CreatedTime        time.Time   `json:"created_time"`
+	CreatedById        null.Int    `json:"created_by_id"`
+	CreatedFromIP      null.String `json:"created_from_ip"`
+	LastModifiedTime   time.Time   `json:"last_modified_time"`
+	LastModifiedById   null.Int    `json:"last_modified_by_id"`
+	LastModifiedFromIP null.String `json:"last_modified_from_ip"`
+	OldId              uint64      `json:"old_id"`
+}
+
+type WorkOrderInvoiceLineItemRepository interface {
+	Insert(ctx context.Context, m *WorkOrderInvoiceLineItem) error
+	UpdateById(ctx context.Context, m *WorkOrderInvoiceLineItem) error
+	GetById(ctx context.Context, id uint64) (*WorkOrderInvoiceLineItem, error)
+	ListByInvoiceId(ctx context.Context, invoiceId uint64) ([]*WorkOrderInvoiceLineItem, error)
+	UpdateSortNumberById(ctx context.Context, id uint64, sortNumber int16) error
+	DeleteById(ctx context.Context, id uint64) error
+	InsertAndRecomputeInvoice(ctx context.Context, m *WorkOrderInvoiceLineItem, inv *WorkOrderInvoice, rev *WorkOrderInvoiceRevision) ([]*WorkOrderInvoiceLineItem, error)
+	UpdateByIdAndRecomputeInvoice(ctx context.Context, m *WorkOrderInvoiceLineItem, inv *WorkOrderInvoice, rev *WorkOrderInvoiceRevision) ([]*WorkOrderInvoiceLineItem, error)
+	UpdateSortNumbersAndRecomputeInvoice(ctx context.Context, ids []uint64, inv *WorkOrderInvoice, rev *WorkOrderInvoiceRevision) ([]*WorkOrderInvoiceLineItem, error)
+	DeleteByIdAndRecomputeInvoice(ctx context.Context, id uint64, inv *WorkOrderInvoice, rev *WorkOrderInvoiceRevision) ([]*WorkOrderInvoiceLineItem, error)
+	InsertOrUpdateByOld(ctx context.Context, m *WorkOrderInvoiceLineItem) error
+}
@@ -0,0 +1,250 @@
+package models
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+)
+
+// WorkOrderInvoiceRevision is an immutable copy of the invoice which is kept
+// every time the invoice gets changed so we can always show what was billed.
+type WorkOrderInvoiceRevision struct {
+	Id              uint64                    `json:"id"`
+	TenantId        uint64                    `json:"tenant_id"`
+	InvoiceId       uint64                    `json:"invoice_id"`
+	RevisionVersion int16                     `json:"revision_version"`
+	Snapshot        *WorkOrderInvoiceSnapshot `json:"snapshot"`
+	CreatedTime     time.Time                 `json:"created_time"`
+	CreatedById     null.Int                  `json:"created_by_id"`
+	CreatedByName   null.String               `json:"created_by_name"`
+	CreatedFromIP   null.String               `json:"created_from_ip"`
+}
+
+type WorkOrderInvoiceRevisionRepository interface {
+	// Function inserts the revision and increments the revision version of
+	// the invoice.
+	InsertAndIncrementInvoiceVersion(ctx context.Context, m *WorkOrderInvoiceRevision) error
+	ListByInvoiceId(ctx context.Context, invoiceId uint64) ([]*WorkOrderInvoiceRevision, error)
+	GetByInvoiceIdAndRevisionVersion(ctx context.Context, invoiceId uint64, version int16) (*WorkOrderInvoiceRevision, error)
+}
+
+// WorkOrderInvoiceSnapshot is everything which gets printed on the invoice.
+type WorkOrderInvoiceSnapshot struct {
+	InvoiceId           string                      `json:"invoice_id"`
+	InvoiceDate         time.Time                   `json:"invoice_date"`
+	AssociateName       string                      `json:"associate_name"`
+	AssociateTelephone  string                      `json:"associate_telephone"`
+	ClientName          string                      `json:"client_name"`
+	ClientTelephone     string                      `json:"client_telephone"`
+	ClientEmail         null.String                 `json:"client_email"`
+	ClientAddress       string                      `json:"client_address"`
+	InvoiceAssociateTax null.String                 `json:"invoice_associate_tax"`
+	TotalLabour         Money                       `json:"total_labour"`
+	TotalMaterials      Money                       `json:"total_materials"`
+	OtherCosts          Money                       `json:"other_costs"`
+	SubTotal            Money                       `json:"sub_total"`
+	Tax                 Money                       `json:"tax"`
+	TaxRules            AppliedTaxRules             `json:"tax_rules"`
+	Total               Money                       `json:"total"`
+	Deposit             Money                       `json:"deposit"`
+	AmountDue           Money                       `json:"amount_due"`
+	PaymentAmount       Money                       `json:"payment_amount"`
+	PaymentDate         null.Time                   `json:"payment_date"`
+	IsCash              bool                        `json:"is_cash"`
+	IsCheque            bool                        `json:"is_cheque"`
+	IsDebit             bool                        `json:"is_debit"`
+	IsCredit            bool                        `json:"is_credit"`
+	IsOther             bool                        `json:"is_other"`
+	ClientSignature     string                      `json:"client_signature"`
+	AssociateSignDate   time.Time                   `json:"associate_sign_date"`
+	AssociateSignature  string                      `json:"associate_signature"`
+	LineItems           []*WorkOrderInvoiceLineItem `json:"line_items"`
+}
+
+func NewWorkOrderInvoiceSnapshot(inv *WorkOrderInvoice, lineItems []*WorkOrderInvoiceLineItem) *WorkOrderInvoiceSnapshot {
+	if lineItems == nil {
+		lineItems = []*WorkOrderInvoiceLineItem{}
+	}
+	return &WorkOrderInvoiceSnapshot{
+		InvoiceId:           inv.InvoiceId,
+		InvoiceDate:         inv.InvoiceDate,
+		AssociateName:       inv.AssociateName,
+		AssociateTelephone:  inv.AssociateTelephone,
+		ClientName:          inv.ClientName,
+		ClientTelephone:     inv.ClientTelephone,
+		ClientEmail:         inv.ClientEmail,
+		ClientAddress:       inv.ClientAddress,
+		InvoiceAssociateTax: inv.InvoiceAssociateTax,
+		TotalLabour:         inv.TotalLabour,
+		TotalMaterials:      inv.TotalMaterials,
+		OtherCosts:          inv.OtherCosts,
+		SubTotal:            inv.SubTotal,
+		Tax:                 inv.Tax,
+		TaxRules:            inv.TaxRules,
+		Total:               inv.Total,
+		Deposit:             inv.Deposit,
+		AmountDue:           inv.AmountDue,
+		PaymentAmount:       inv.PaymentAmount,
+		PaymentDate:         inv.PaymentDate,
+		IsCash:              inv.IsCash,
+		IsCheque:            inv.IsCheque,
+		IsDebit:             inv.IsDebit,
+		IsCredit:            inv.IsCredit,
+		IsOther:             inv.IsOther,
+		ClientSignature:     inv.ClientSignature,
+		AssociateSignDate:   inv.AssociateSignDate,
+		AssociateSignature:  inv.AssociateSignature,
+		LineItems:           lineItems,
+	}
+}
+
+// Scan implements the `sql.Scanner` interface.
+func (s *WorkOrderInvoiceSnapshot) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	default:
+		return fmt.Errorf("cannot scan %T into invoice snapshot", value)
+	}
+}
+
+// Value implements the `driver.Valuer` interface.
+func (s *WorkOrderInvoiceSnapshot) Value() (driver.Value, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// WorkOrderInvoiceFieldChange is a field which has a different value in the
+// two revisions, the values are the JSON values of the field.
+type WorkOrderInvoiceFieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type WorkOrderInvoiceLineItemChange struct {
+	Uuid    string                         `json:"uuid"`
+	Changes []*WorkOrderInvoiceFieldChange `json:"changes"`
+}
+
+// WorkOrderInvoiceDiff is what changed on the invoice between two revisions,
+// the line items are matched by their `uuid`.
+type WorkOrderInvoiceDiff struct {
+	FromRevisionVersion int16                             `json:"from_revision_version"`
+	ToRevisionVersion   int16                             `json:"to_revision_version"`
+	Changes             []*WorkOrderInvoiceFieldChange    `json:"changes"`
+	AddedLineItems      []*WorkOrderInvoiceLineItem       `json:"added_line_items"`
+	RemovedLineItems    []*WorkOrderInvoiceLineItem       `json:"removed_line_items"`
+	ChangedLineItems    []*WorkOrderInvoiceLineItemChange `json:"changed_line_items"`
+}
+
+// The fields which change on every save and would only add noise to the diff.
+var workOrderInvoiceLineItemIgnoredFields = map[string]bool{
+	"last_modified_time":    true,
+	"last_modified_by_id":   true,
+	"last_modified_from_ip": true,
+}
+
+func DiffWorkOrderInvoiceSnapshots(fromVersion int16, from *WorkOrderInvoiceSnapshot, toVersion int16, to *WorkOrderInvoiceSnapshot) (*WorkOrderInvoiceDiff, error) {
+	res := &WorkOrderInvoiceDiff{
+		FromRevisionVersion: fromVersion,
+		ToRevisionVersion:   toVersion,
+		Changes:             []*WorkOrderInvoiceFieldChange{},
+		AddedLineItems:      []*WorkOrderInvoiceLineItem{},
+		RemovedLineItems:    []*WorkOrderInvoiceLineItem{},
+		ChangedLineItems:    []*WorkOrderInvoiceLineItemChange{},
+	}
+
+	// Compare the header of the invoice without the line items.
+	fromHeader, toHeader := *from, *to
+	fromHeader.LineItems, toHeader.LineItems = nil, nil
+	changes, err := diffJSONFields(&fromHeader, &toHeader, map[string]bool{"line_items": true})
+	if err != nil {
+		return nil, err
+	}
+	res.Changes = changes
+
+	fromLines := make(map[string]*WorkOrderInvoiceLineItem)
+	for _, li := range from.LineItems {
+		fromLines[li.Uuid] = li
+	}
+	toLines := make(map[string]bool)
+	for _, li := range to.LineItems {
+		toLines[li.Uuid] = true
+		old, ok := fromLines[li.Uuid]
+		if !ok {
+			res.AddedLineItems = append(res.AddedLineItems, li)
+			continue
+		}
+		changes, err := diffJSONFields(old, li, workOrderInvoiceLineItemIgnoredFields)
+		if err != nil {
+			return nil, err
+		}
+		if len(changes) > 0 {
+			res.ChangedLineItems = append(res.ChangedLineItems, &WorkOrderInvoiceLineItemChange{
+				Uuid:    li.Uuid,
+				Changes: changes,
+			})
+		}
+	}
+	for _, li := range from.LineItems {
+		if !toLines[li.Uuid] {
+			res.RemovedLineItems = append(res.RemovedLineItems, li)
+		}
+	}
+	return res, nil
+}
+
+// Function compares the JSON fields of the two values and returns the fields
+// which are different sorted by their name.
+func diffJSONFields(from interface{}, to interface{}, ignored map[string]bool) ([]*WorkOrderInvoiceFieldChange, error) {
+	fromFields, err := toJSONFields(from)
+	if err != nil {
+		return nil, err
+	}
+	toFields, err := toJSONFields(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []*WorkOrderInvoiceFieldChange{}
+	for field, fromValue := range fromFields {
+		if ignored[field] {
+			continue
+		}
+		if toValue := toFields[field]; !reflect.DeepEqual(fromValue, toValue) {
+			changes = append(changes, &WorkOrderInvoiceFieldChange{
+				Field: field,
+				From:  fromValue,
+				To:    toValue,
+			})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes, nil
+}
+
+func toJSONFields(v interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+)
+
+func TestWorkOrderInvoiceComputeTotals(t *testing.T) {
+	lines := []*WorkOrderInvoiceLineItem{
+		{TypeOf: WorkOrderInvoiceLineItemLabourTypeOf, Amount: 10000},
+		{TypeOf: WorkOrderInvoiceLineItemMaterialsTypeOf, Amount: 2550},
+		{TypeOf: WorkOrderInvoiceLineItemOtherCostsTypeOf, Amount: 1000},
+	}
+	rules := []*TenantTaxRule{{Id: 1, Name: "HST", Rate: NewPercentFromFloat(13)}}
+
+	tests := []struct {
+		name          string
+		associateTax  null.String
+		deposit       Money
+		wantSubTotal  Money
+		wantTax       Money
+		wantTotal     Money
+		wantAmountDue Money
+	}{
+		{"taxable", null.StringFrom("123456789"), 0, 13550, 1762, 15312, 15312},
+		{"not taxable", null.String{}, 0, 13550, 0, 13550, 13550},
+		{"blank tax number", null.StringFrom("  "), 0, 13550, 0, 13550, 13550},
+		{"deposit", null.StringFrom("123456789"), 5000, 13550, 1762, 15312, 10312},
+	}
+	for _, tt := range tests {
+		m := &WorkOrderInvoice{InvoiceAssociateTax: tt.associateTax, Deposit: tt.deposit}
+		m.ComputeTotals(lines, rules)
+		if m.TotalLabour != 10000 || m.TotalMaterials != 2550 || m.OtherCosts != 1000 {
+			t.Errorf("%s: labour, materials, other costs = %d, %d, %d", tt.name, m.TotalLabour, m.TotalMaterials, m.OtherCosts)
+		}
+		if m.SubTotal != tt.wantSubTotal || m.Tax != tt.wantTax || m.Total != tt.wantTotal || m.AmountDue != tt.wantAmountDue {
+			t.Errorf("%s: sub total, tax, total, amount due = %d, %d, %d, %d, want %d, %d, %d, %d",
+				tt.name, m.SubTotal, m.Tax, m.Total, m.AmountDue,
+				tt.wantSubTotal, tt.wantTax, tt.wantTotal, tt.wantAmountDue)
+		}
+		if wantRules := tt.wantTax != 0; (len(m.TaxRules) == 1) != wantRules {
+			t.Errorf("%s: %d tax rules kept with the invoice", tt.name, len(m.TaxRules))
+		}
+	}
+}
+
+func TestWorkOrderInvoiceTaxDate(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	invoiceDate := time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		invoiceDate time.Time
+		want        time.Time
+	}{
+		{"dated", invoiceDate, invoiceDate},
+		{"not dated", time.Time{}, now},
+	}
+	for _, tt := range tests {
+		m := &WorkOrderInvoice{InvoiceDate: tt.invoiceDate}
+		if got := m.TaxDate(now); !got.Equal(tt.want) {
+			t.Errorf("%s: TaxDate() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -12,13 +12,18 @@ import (
 // 1 = Active
 // 0 = Inactive
 
+const (
+	WorkOrderServiceFeeInactiveState = 0
+	WorkOrderServiceFeeActiveState   = 1
+)
+
 type WorkOrderServiceFee struct {
 	Id                 uint64      `json:"id"`
 	Uuid               string      `json:"uuid"`
 	TenantId           uint64      `json:"tenant_id"`
 	Title              string      `json:"title"`
 	Description        string      `json:"description"`
-	Percentage         float64     `json:"percentage"`
+	Percentage         Percent     `json:"percentage"`
 	CreatedTime        time.Time   `json:"created_time"`
 	CreatedById        null.Int    `json:"created_by_id,omitempty"`
 	CreatedByName      null.String `json:"created_by_name,omitempty"`
@@ -38,4 +43,36 @@ type WorkOrderServiceFeeRepository interface {
 	GetIdByOldId(ctx context.Context, tid uint64, oid uint64) (uint64, error)
 	CheckIfExistsById(ctx context.Context, id uint64) (bool, error)
 	InsertOrUpdateById(ctx context.Context, u *WorkOrderServiceFee) error
+	InsertWithRate(ctx context.Context, u *WorkOrderServiceFee, rate *WorkOrderServiceFeeRate) error
+	UpdateWithRate(ctx context.Context, u *WorkOrderServiceFee, rate *WorkOrderServiceFeeRate) error
+	ListRatesById(ctx context.Context, id uint64) ([]*WorkOrderServiceFeeRate, error)
+	GetPercentageAtById(ctx context.Context, id uint64, at time.Time) (Percent, error)
+	PreviewChangeById(ctx context.Context, id uint64, percentage Percent, effectiveFrom time.Time) (*WorkOrderServiceFeeChangePreview, error)
+}
+
+// WorkOrderServiceFeeRate is the percentage of the service fee starting at
+// the effective date, the percentage used for a work order is the one which
+// was effective when the work order was invoiced.
+type WorkOrderServiceFeeRate struct {
+	Id            uint64      `json:"id"`
+	TenantId      uint64      `json:"tenant_id"`
+	ServiceFeeId  uint64      `json:"service_fee_id"`
+	Percentage    Percent     `json:"percentage"`
+	EffectiveFrom time.Time   `json:"effective_from"`
+	CreatedTime   time.Time   `json:"created_time"`
+	CreatedById   null.Int    `json:"created_by_id,omitempty"`
+	CreatedByName null.String `json:"created_by_name,omitempty"`
+	CreatedFromIP string      `json:"created_from_ip"`
+}
+
+// WorkOrderServiceFeeChangePreview summarizes the open work orders which
+// would be charged the new percentage if the service fee was changed.
+type WorkOrderServiceFeeChangePreview struct {
+	ServiceFeeId              uint64    `json:"service_fee_id"`
+	Percentage                Percent   `json:"percentage"`
+	EffectiveFrom             time.Time `json:"effective_from"`
+	OrderCount                uint64    `json:"order_count"`
+	AssociateCount            uint64    `json:"associate_count"`
+	CurrentServiceFeeAmount   Money     `json:"current_service_fee_amount"`
+	ProjectedServiceFeeAmount Money     `json:"projected_service_fee_amount"`
 }
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+)
+
+// The associate score is the average job score of the latest surveys of the
+// associate, only the most recent submission of every work order counts.
+const AssociateScoreSurveyCount = 20
+
+// WorkOrderSurvey is a single submission of the customer satisfaction survey
+// of the work order, submitting the survey again keeps the previous ones as
+// the history. `AssociateScore` is the score of the associate right after
+// the survey was submitted.
+type WorkOrderSurvey struct {
+	Id                                uint64      `json:"id"`
+	Uuid                              string      `json:"uuid"`
+	TenantId                          uint64      `json:"tenant_id"`
+	OrderId                           uint64      `json:"order_id"`
+	AssociateId                       null.Int    `json:"associate_id"`
+	WasSurveyConducted                bool        `json:"was_survey_conducted"`
+	NoSurveyConductedReason           null.Int    `json:"no_survey_conducted_reason"`
+	NoSurveyConductedReasonOther      null.String `json:"no_survey_conducted_reason_other"`
+	WasJobSatisfactory                bool        `json:"was_job_satisfactory"`
+	WasJobFinishedOnTimeAndOnBudget   bool        `json:"was_job_finished_on_time_and_on_budget"`
+	WasAssociatePunctual              bool        `json:"was_associate_punctual"`
+	WasAssociateProfessional          bool        `json:"was_associate_professional"`
+	WouldCustomerReferOurOrganization bool        `json:"would_customer_refer_our_organization"`
+	Score                             int8        `json:"score"`
+	AssociateScore                    null.Float  `json:"associate_score"`
+	CreatedTime                       time.Time   `json:"created_time"`
+	CreatedById                       null.Int    `json:"created_by_id"`
+	CreatedByName                     null.String `json:"created_by_name"`
+	CreatedFromIP                     null.String `json:"created_from_ip"`
+}
+
+// ComputeScore returns the job score which is the number of questions the
+// customer answered yes, a survey which was not conducted scores zero.
+func (m *WorkOrderSurvey) ComputeScore() int8 {
+	if !m.WasSurveyConducted {
+		return 0
+	}
+	var score int8
+	for _, answer := range []bool{
+		m.WasJobSatisfactory,
+		m.WasJobFinishedOnTimeAndOnBudget,
+		m.WasAssociatePunctual,
+		m.WasAssociateProfessional,
+		m.WouldCustomerReferOurOrganization,
+	} {
+		if answer {
+			score++
+		}
+	}
+	return score
+}
+
+// ApplyTo copies the answers to the work order.
+func (m *WorkOrderSurvey) ApplyTo(order *WorkOrder) {
+	order.WasSurveyConducted = m.WasSurveyConducted
+	order.NoSurveyConductedReason = m.NoSurveyConductedReason
+	order.NoSurveyConductedReasonOther = m.NoSurveyConductedReasonOther
+	order.WasJobSatisfactory = m.WasJobSatisfactory
+	order.WasJobFinishedOnTimeAndOnBudget = m.WasJobFinishedOnTimeAndOnBudget
+	order.WasAssociatePunctual = m.WasAssociatePunctual
+	order.WasAssociateProfessional = m.WasAssociateProfessional
+	order.WouldCustomerReferOurOrganization = m.WouldCustomerReferOurOrganization
+	order.Score = m.Score
+}
+
+// AssociateSatisfaction is the summary of the surveys of the associate, the
+// counts are the number of surveys where the customer answered yes.
+type AssociateSatisfaction struct {
+	AssociateId                            uint64  `json:"associate_id"`
+	AssociateName                          string  `json:"associate_name"`
+	AssociateLexicalName                   string  `json:"associate_lexical_name"`
+	Score                                  float64 `json:"score"`
+	SurveyCount                            int64   `json:"survey_count"`
+	AverageScore                           float64 `json:"average_score"`
+	WasJobSatisfactoryCount                int64   `json:"was_job_satisfactory_count"`
+	WasJobFinishedOnTimeAndOnBudgetCount   int64   `json:"was_job_finished_on_time_and_on_budget_count"`
+	WasAssociatePunctualCount              int64   `json:"was_associate_punctual_count"`
+	WasAssociateProfessionalCount          int64   `json:"was_associate_professional_count"`
+	WouldCustomerReferOurOrganizationCount int64   `json:"would_customer_refer_our_organization_count"`
+}
+
+type WorkOrderSurveyRepository interface {
+	// Function saves the survey answers on the work order, inserts the survey
+	// and recomputes the score of the associate within a single transaction.
+	// The optional task is the survey task which gets closed by the survey.
+	Create(ctx context.Context, m *WorkOrderSurvey, order *WorkOrder, task *TaskItem) error
+	ListByOrderId(ctx context.Context, orderId uint64) ([]*WorkOrderSurvey, error)
+	ListByAssociateId(ctx context.Context, associateId uint64, from time.Time, to time.Time) ([]*WorkOrderSurvey, error)
+
+	// Function summarizes the latest conducted survey of every work order
+	// submitted within the dates, a zero date is not filtered on.
+	ListSatisfactionByTenantId(ctx context.Context, tenantId uint64, from time.Time, to time.Time) ([]*AssociateSatisfaction, error)
+}
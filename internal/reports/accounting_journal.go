@@ -0,0 +1,265 @@
+package reports
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+// AccountingJournal contains the records which get exported to the
+// accounting software of the tenant.
+type AccountingJournal struct {
+	Mapping            *models.TenantAccountMapping
+	Invoices           []*models.AccountingInvoice
+	Deposits           []*models.WorkOrderDeposit
+	ServiceFeePayments []*models.AccountingServiceFeePayment
+	Location           *time.Location
+}
+
+// Function returns the balanced journal entries of every record sorted by
+// date. Records which would not move any money are skipped and records which
+// were exported before only book the difference as an adjustment.
+func BuildAccountingJournalEntries(d *AccountingJournal) []*models.AccountingJournalEntry {
+	m := d.Mapping
+	var arr []*models.AccountingJournalEntry
+
+	// An invoice moves the total owed by the customer into our receivables
+	// and books the income and the tax collected.
+	for _, inv := range d.Invoices {
+		ref := inv.InvoiceId
+		if ref == "" {
+			ref = strconv.FormatUint(inv.OrderId, 10)
+		}
+		e := &models.AccountingJournalEntry{
+			SourceTypeOf:  models.AccountingInvoiceSourceTypeOf,
+			SourceId:      inv.Id,
+			SourceVersion: int64(inv.RevisionVersion),
+			Date:          inv.InvoiceDate,
+			Reference:     ref,
+			Name:          inv.ClientName,
+			Memo:          "Invoice for work order #" + strconv.FormatUint(inv.OrderId, 10),
+			Currency:      inv.Currency,
+		}
+		addAccountingCredit(e, m.LabourIncome, inv.TotalLabour)
+		addAccountingCredit(e, m.MaterialsIncome, inv.TotalMaterials)
+		addAccountingCredit(e, m.OtherIncome, inv.OtherCosts)
+		addAccountingCredit(e, m.SalesTaxPayable, inv.Tax)
+		addAccountingBalancingDebit(e, m.AccountsReceivable)
+		if len(inv.Exported) > 0 {
+			e.Date = inv.LastModifiedTime
+			e.Memo = "Revision " + strconv.Itoa(int(inv.RevisionVersion)) + " of the invoice for work order #" + strconv.FormatUint(inv.OrderId, 10)
+			netAccountingEntry(e, inv.Exported)
+		}
+		arr = appendAccountingEntry(arr, e)
+	}
+
+	// A deposit settles part of the receivable of the customer, the money is
+	// held either by us or by the associate who collected it. Refunds and
+	// reversals have a negative amount so their lines are simply inverted.
+	for _, dep := range d.Deposits {
+		account := m.UndepositedFunds
+		if dep.PaidTo.ValueOrZero() == models.WorkOrderDepositPaidToAssociate {
+			account = m.AssociateClearing
+		}
+		memo := "Deposit for work order #" + strconv.FormatUint(dep.OrderId, 10)
+		if dep.ReversalOfId.Valid {
+			memo = "Reversal of deposit #" + strconv.FormatInt(dep.ReversalOfId.Int64, 10) + " for work order #" + strconv.FormatUint(dep.OrderId, 10)
+		}
+		e := &models.AccountingJournalEntry{
+			SourceTypeOf: models.AccountingDepositSourceTypeOf,
+			SourceId:     dep.Id,
+			Date:         dep.PaidAt.ValueOrZero(),
+			Reference:    strconv.FormatUint(dep.OrderId, 10),
+			Memo:         memo,
+			Currency:     dep.Currency,
+		}
+		addAccountingCredit(e, m.AccountsReceivable, dep.Amount)
+		addAccountingBalancingDebit(e, account)
+		arr = appendAccountingEntry(arr, e)
+	}
+
+	// A service fee payment is the associate paying us our share of the job.
+	for _, p := range d.ServiceFeePayments {
+		e := &models.AccountingJournalEntry{
+			SourceTypeOf:  models.AccountingServiceFeePaymentSourceTypeOf,
+			SourceId:      p.OrderId,
+			SourceVersion: p.Version,
+			Date:          p.PaidAt,
+			Reference:     strconv.FormatUint(p.OrderId, 10),
+			Name:          p.AssociateName,
+			Memo:          "Service fee for work order #" + strconv.FormatUint(p.OrderId, 10),
+			Currency:      p.Currency,
+		}
+		addAccountingCredit(e, m.ServiceFeeIncome, p.Amount)
+		addAccountingBalancingDebit(e, m.UndepositedFunds)
+		if len(p.Exported) > 0 {
+			e.Date = p.LastModifiedTime
+			e.Memo = "Correction of the service fee for work order #" + strconv.FormatUint(p.OrderId, 10)
+			netAccountingEntry(e, p.Exported)
+		}
+		arr = appendAccountingEntry(arr, e)
+	}
+
+	// Keep the records of the same date in the order they were built.
+	sort.SliceStable(arr, func(i, j int) bool {
+		return arr[i].Date.Before(arr[j].Date)
+	})
+	return arr
+}
+
+func addAccountingCredit(e *models.AccountingJournalEntry, account string, amount models.Money) {
+	if amount != 0 {
+		e.Lines = append(e.Lines, &models.AccountingJournalLine{Account: account, Amount: -amount})
+	}
+}
+
+// Function adds the debit which balances the credits of the entry so the
+// entry always balances even if the stored totals were rounded differently.
+func addAccountingBalancingDebit(e *models.AccountingJournalEntry, account string) {
+	var total models.Money
+	for _, l := range e.Lines {
+		total += l.Amount
+	}
+	if total != 0 {
+		e.Lines = append([]*models.AccountingJournalLine{{Account: account, Amount: -total}}, e.Lines...)
+	}
+}
+
+// Function replaces the lines of the entry with the difference between them
+// and the lines which were already exported for the record, since both are
+// balanced the difference is balanced as well.
+func netAccountingEntry(e *models.AccountingJournalEntry, exported []*models.AccountingJournalEntry) {
+	var accounts []string
+	amounts := make(map[string]models.Money)
+	add := func(account string, amount models.Money) {
+		if _, ok := amounts[account]; !ok {
+			accounts = append(accounts, account)
+		}
+		amounts[account] += amount
+	}
+	for _, l := range e.Lines {
+		add(l.Account, l.Amount)
+	}
+	for _, x := range exported {
+		for _, l := range x.Lines {
+			add(l.Account, -l.Amount)
+		}
+	}
+
+	e.Lines = nil
+	for _, account := range accounts {
+		if amounts[account] != 0 {
+			e.Lines = append(e.Lines, &models.AccountingJournalLine{Account: account, Amount: amounts[account]})
+		}
+	}
+}
+
+func appendAccountingEntry(arr []*models.AccountingJournalEntry, e *models.AccountingJournalEntry) []*models.AccountingJournalEntry {
+	if len(e.Lines) == 0 {
+		return arr
+	}
+	e.Currency = models.CurrencyOrDefault(e.Currency)
+	return append(arr, e)
+}
+
+// Function writes the entries in the format into `w`.
+func WriteAccountingJournal(w io.Writer, format string, arr []*models.AccountingJournalEntry, loc *time.Location) error {
+	if loc == nil {
+		loc = time.UTC
+	}
+	switch format {
+	case models.AccountingIIFFormat:
+		return writeAccountingIIF(w, arr, loc)
+	case models.AccountingCSVFormat:
+		return writeAccountingCSV(w, arr, loc)
+	default:
+		return errors.New("unsupported accounting format: " + format)
+	}
+}
+
+// Function returns the MIME type and the file extension of the format.
+func AccountingJournalContentType(format string) (string, string) {
+	if format == models.AccountingIIFFormat {
+		return "application/x-iif", ".iif"
+	}
+	return "text/csv; charset=utf-8", ".csv"
+}
+
+// Function writes the entries as QuickBooks general journal transactions,
+// the first line of every transaction is the `TRNS` and the others are the
+// `SPL` lines. QuickBooks uses positive amounts for debits and negative
+// amounts for credits which is the same as our lines.
+func writeAccountingIIF(w io.Writer, arr []*models.AccountingJournalEntry, loc *time.Location) error {
+	bw := bufio.NewWriter(w)
+	header := "\tTRNSID\tTRNSTYPE\tDATE\tACCNT\tNAME\tAMOUNT\tDOCNUM\tMEMO\r\n"
+	bw.WriteString("!TRNS" + header)
+	bw.WriteString("!SPL" + strings.Replace(header, "TRNSID", "SPLID", 1))
+	bw.WriteString("!ENDTRNS\r\n")
+	for _, e := range arr {
+		date := e.Date.In(loc).Format("01/02/2006")
+		for i, l := range e.Lines {
+			kind := "SPL"
+			if i == 0 {
+				kind = "TRNS"
+			}
+			fields := []string{
+				kind, "", "GENERAL JOURNAL", date, l.Account, e.Name,
+				l.Amount.String(), e.Reference, e.Memo,
+			}
+			for j, f := range fields {
+				fields[j] = iifEscape(f)
+			}
+			bw.WriteString(strings.Join(fields, "\t") + "\r\n")
+		}
+		bw.WriteString("ENDTRNS\r\n")
+	}
+	return bw.Flush()
+}
+
+// Function removes the characters which would break the tab separated
+// layout of the IIF file.
+func iifEscape(s string) string {
+	return strings.NewReplacer("\t", " ", "\r", " ", "\n", " ", "\"", "'").Replace(s)
+}
+
+// Function writes the entries as a generic journal with one row per line and
+// separate debit and credit columns.
+func writeAccountingCSV(w io.Writer, arr []*models.AccountingJournalEntry, loc *time.Location) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"entry", "date", "reference", "account", "name", "memo", "debit",
+		"credit", "currency", "source_type_of", "source_id",
+	})
+	for i, e := range arr {
+		for _, l := range e.Lines {
+			var debit, credit string
+			if l.Amount >= 0 {
+				debit = l.Amount.String()
+			} else {
+				credit = (-l.Amount).String()
+			}
+			cw.Write([]string{
+				strconv.Itoa(i + 1),
+				e.Date.In(loc).Format("2006-01-02"),
+				e.Reference,
+				l.Account,
+				e.Name,
+				e.Memo,
+				debit,
+				credit,
+				e.Currency,
+				strconv.Itoa(int(e.SourceTypeOf)),
+				strconv.FormatUint(e.SourceId, 10),
+			})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
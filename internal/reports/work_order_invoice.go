@@ -0,0 +1,337 @@
+package reports
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/utils"
+)
+
+// WorkOrderInvoicePDF contains everything which gets printed on the invoice,
+// the `Template` and `Logo` are optional.
+type WorkOrderInvoicePDF struct {
+	Tenant    *models.Tenant
+	Template  *models.TenantInvoiceTemplate
+	Logo      image.Image
+	Invoice   *models.WorkOrderInvoice
+	LineItems []*models.WorkOrderInvoiceLineItem
+	Location  *time.Location
+	Currency  string
+}
+
+const (
+	invoiceMarginX      = 42.0
+	invoiceMarginBottom = 60.0
+	invoiceFontSize     = 9.0
+	invoiceLineHeight   = 12.0
+)
+
+// The right edge of the columns in the line items table.
+const (
+	invoiceColNumber      = invoiceMarginX + 20
+	invoiceColDescription = invoiceMarginX + 28
+	invoiceColQuantity    = 400.0
+	invoiceColUnitPrice   = 480.0
+	invoiceColAmount      = utils.PDFLetterWidth - invoiceMarginX
+)
+
+// Function will render the invoice as a PDF document into `w`.
+func RenderWorkOrderInvoicePDF(w io.Writer, d *WorkOrderInvoicePDF) error {
+	r := &invoiceRenderer{
+		doc:  utils.NewPDFDocument(),
+		data: d,
+	}
+	if r.data.Location == nil {
+		r.data.Location = time.UTC
+	}
+	if err := r.render(); err != nil {
+		return err
+	}
+	_, err := r.doc.WriteTo(w)
+	return err
+}
+
+type invoiceRenderer struct {
+	doc  *utils.PDFDocument
+	data *WorkOrderInvoicePDF
+	y    float64
+
+	// Set while rendering the line items so the table header gets repeated
+	// at the top of every new page.
+	inLineItems bool
+}
+
+func (r *invoiceRenderer) render() error {
+	r.doc.AddPage()
+	if err := r.renderHeader(); err != nil {
+		return err
+	}
+	r.renderParties()
+	r.renderLineItems()
+	r.renderTotals()
+	r.renderSignatures()
+	r.renderFooter()
+	return nil
+}
+
+func (r *invoiceRenderer) renderHeader() error {
+	t := r.data.Tenant
+	inv := r.data.Invoice
+	right := utils.PDFLetterWidth - invoiceMarginX
+
+	// Left side is the logo followed by the tenant and their address.
+	r.y = 42
+	if r.data.Logo != nil {
+		if err := r.doc.Image(r.data.Logo, invoiceMarginX, r.y, 160, 60); err != nil {
+			return err
+		}
+		r.y += 72
+	}
+	r.y += 14
+	r.doc.Text(invoiceMarginX, r.y, 14, true, t.Name)
+	for _, s := range tenantAddressLines(t) {
+		r.y += invoiceLineHeight
+		r.doc.Text(invoiceMarginX, r.y, invoiceFontSize, false, s)
+	}
+	if r.data.Template != nil && r.data.Template.TaxRegistrationNumber != "" {
+		r.y += invoiceLineHeight
+		r.doc.Text(invoiceMarginX, r.y, invoiceFontSize, false, "Tax Registration #: "+r.data.Template.TaxRegistrationNumber)
+	}
+
+	// Right side is the invoice details.
+	invoiceNumber := inv.InvoiceId
+	if invoiceNumber == "" {
+		invoiceNumber = strconv.FormatUint(inv.OrderId, 10)
+	}
+	y := 56.0
+	r.doc.TextRight(right, y, 22, true, "INVOICE")
+	for _, kv := range [][2]string{
+		{"Invoice #", invoiceNumber},
+		{"Invoice Date", r.formatDate(inv.InvoiceDate)},
+		{"Work Order #", strconv.FormatUint(inv.OrderId, 10)},
+	} {
+		y += invoiceLineHeight + 2
+		r.doc.TextRight(right-90, y, invoiceFontSize, true, kv[0]+":")
+		r.doc.TextRight(right, y, invoiceFontSize, false, kv[1])
+	}
+	if y > r.y {
+		r.y = y
+	}
+	r.y += 18
+	r.doc.Line(invoiceMarginX, r.y, right, r.y, 1)
+	return nil
+}
+
+func (r *invoiceRenderer) renderParties() {
+	inv := r.data.Invoice
+	half := utils.PDFLetterWidth / 2
+
+	r.y += 18
+	top := r.y
+	r.doc.Text(invoiceMarginX, r.y, 10, true, "BILL TO")
+	for _, s := range []string{inv.ClientName, inv.ClientAddress, inv.ClientTelephone, inv.ClientEmail.ValueOrZero()} {
+		if s == "" {
+			continue
+		}
+		r.y += invoiceLineHeight
+		r.doc.Text(invoiceMarginX, r.y, invoiceFontSize, false, s)
+	}
+
+	y := top
+	r.doc.Text(half, y, 10, true, "ASSOCIATE")
+	for _, s := range []string{inv.AssociateName, inv.AssociateTelephone} {
+		if s == "" {
+			continue
+		}
+		y += invoiceLineHeight
+		r.doc.Text(half, y, invoiceFontSize, false, s)
+	}
+	if tax := inv.InvoiceAssociateTax.ValueOrZero(); tax != "" {
+		y += invoiceLineHeight
+		r.doc.Text(half, y, invoiceFontSize, false, "Tax #: "+tax)
+	}
+	if y > r.y {
+		r.y = y
+	}
+	r.y += 24
+}
+
+func (r *invoiceRenderer) renderLineItemsHeader() {
+	r.doc.FillRect(invoiceMarginX, r.y-11, invoiceColAmount-invoiceMarginX, 16, 0.9)
+	r.doc.TextRight(invoiceColNumber, r.y, invoiceFontSize, true, "#")
+	r.doc.Text(invoiceColDescription, r.y, invoiceFontSize, true, "Description")
+	r.doc.TextRight(invoiceColQuantity, r.y, invoiceFontSize, true, "Qty")
+	r.doc.TextRight(invoiceColUnitPrice, r.y, invoiceFontSize, true, "Unit Price")
+	r.doc.TextRight(invoiceColAmount, r.y, invoiceFontSize, true, "Amount")
+	r.y += invoiceLineHeight + 6
+}
+
+func (r *invoiceRenderer) renderLineItems() {
+	r.inLineItems = true
+	defer func() { r.inLineItems = false }()
+
+	r.renderLineItemsHeader()
+	descriptionWidth := invoiceColQuantity - 40 - invoiceColDescription
+	for i, li := range r.data.LineItems {
+		lines := utils.PDFWrapText(li.Description, invoiceFontSize, false, descriptionWidth)
+		var notes []string
+		if li.Notes != "" {
+			notes = utils.PDFWrapText(li.Notes, invoiceFontSize-1.5, false, descriptionWidth)
+		}
+		r.ensureSpace(float64(len(lines)+len(notes)) * invoiceLineHeight)
+
+		r.doc.TextRight(invoiceColNumber, r.y, invoiceFontSize, false, strconv.Itoa(i+1))
+		r.doc.TextRight(invoiceColQuantity, r.y, invoiceFontSize, false, strconv.FormatFloat(li.Quantity, 'f', -1, 64))
+		r.doc.TextRight(invoiceColUnitPrice, r.y, invoiceFontSize, false, r.formatAmount(li.UnitPrice))
+		r.doc.TextRight(invoiceColAmount, r.y, invoiceFontSize, false, r.formatAmount(li.Amount))
+		for j, s := range lines {
+			if j > 0 {
+				r.y += invoiceLineHeight
+			}
+			r.doc.Text(invoiceColDescription, r.y, invoiceFontSize, false, s)
+		}
+		for _, s := range notes {
+			r.y += invoiceLineHeight - 1
+			r.doc.Text(invoiceColDescription+8, r.y, invoiceFontSize-1.5, false, s)
+		}
+		r.y += 6
+		r.doc.Line(invoiceMarginX, r.y, invoiceColAmount, r.y, 0.25)
+		r.y += invoiceLineHeight + 2
+	}
+}
+
+func (r *invoiceRenderer) renderTotals() {
+	inv := r.data.Invoice
+	rows := [][2]string{
+		{"Labour", r.formatAmount(inv.TotalLabour)},
+		{"Materials", r.formatAmount(inv.TotalMaterials)},
+		{"Other Costs", r.formatAmount(inv.OtherCosts)},
+		{"Sub-Total", r.formatAmount(inv.SubTotal)},
+	}
+
+	// Every tax rule which was applied gets its own line, older invoices
+	// without a snapshot only have the combined tax.
+	if len(inv.TaxRules) > 0 {
+		for _, rule := range inv.TaxRules {
+			rows = append(rows, [2]string{rule.Name + " " + strconv.FormatFloat(rule.Rate.Float64(), 'f', -1, 64) + "%", r.formatAmount(rule.Amount)})
+		}
+	} else {
+		rows = append(rows, [2]string{"Tax", r.formatAmount(inv.Tax)})
+	}
+	rows = append(rows,
+		[2]string{"Total", r.formatAmount(inv.Total)},
+		[2]string{"Deposit", r.formatAmount(-inv.Deposit)},
+	)
+	r.ensureSpace(float64(len(rows)+2) * (invoiceLineHeight + 2))
+
+	r.y += 6
+	for _, row := range rows {
+		r.doc.TextRight(invoiceColUnitPrice, r.y, invoiceFontSize, false, row[0]+":")
+		r.doc.TextRight(invoiceColAmount, r.y, invoiceFontSize, false, row[1])
+		r.y += invoiceLineHeight + 2
+	}
+	r.doc.FillRect(invoiceColUnitPrice-100, r.y-12, invoiceColAmount-invoiceColUnitPrice+100, 18, 0.9)
+	r.doc.TextRight(invoiceColUnitPrice, r.y, 11, true, "Amount Due:")
+	r.doc.TextRight(invoiceColAmount, r.y, 11, true, r.formatAmount(inv.AmountDue))
+	r.y += invoiceLineHeight * 2
+}
+
+func (r *invoiceRenderer) renderSignatures() {
+	inv := r.data.Invoice
+	half := utils.PDFLetterWidth / 2
+	r.ensureSpace(invoiceLineHeight * 6)
+
+	// Payment details are only printed once the invoice was paid.
+	if inv.PaymentDate.Valid {
+		var methods []string
+		for _, m := range []struct {
+			ok   bool
+			name string
+		}{
+			{inv.IsCash, "Cash"},
+			{inv.IsCheque, "Cheque"},
+			{inv.IsDebit, "Debit"},
+			{inv.IsCredit, "Credit"},
+			{inv.IsOther, "Other"},
+		} {
+			if m.ok {
+				methods = append(methods, m.name)
+			}
+		}
+		s := fmt.Sprintf("Paid %s on %s", r.formatAmount(inv.PaymentAmount), r.formatDate(inv.PaymentDate.Time))
+		if len(methods) > 0 {
+			s += " by " + strings.Join(methods, ", ")
+		}
+		r.doc.Text(invoiceMarginX, r.y, invoiceFontSize, false, s)
+		r.y += invoiceLineHeight * 2
+	}
+
+	r.y += invoiceLineHeight
+	r.doc.Text(invoiceMarginX, r.y, invoiceFontSize, false, inv.ClientSignature)
+	r.doc.Text(half, r.y, invoiceFontSize, false, inv.AssociateSignature)
+	r.y += 4
+	r.doc.Line(invoiceMarginX, r.y, half-30, r.y, 0.5)
+	r.doc.Line(half, r.y, invoiceColAmount, r.y, 0.5)
+	r.y += invoiceLineHeight
+	r.doc.Text(invoiceMarginX, r.y, invoiceFontSize-1, false, "Client Signature")
+	r.doc.Text(half, r.y, invoiceFontSize-1, false, "Associate Signature - "+r.formatDate(inv.AssociateSignDate))
+	r.y += invoiceLineHeight * 2
+}
+
+func (r *invoiceRenderer) renderFooter() {
+	if r.data.Template == nil || r.data.Template.FooterText == "" {
+		return
+	}
+	lines := utils.PDFWrapText(r.data.Template.FooterText, invoiceFontSize-1, false, invoiceColAmount-invoiceMarginX)
+	r.ensureSpace(float64(len(lines)) * invoiceLineHeight)
+	for _, s := range lines {
+		r.doc.Text(invoiceMarginX, r.y, invoiceFontSize-1, false, s)
+		r.y += invoiceLineHeight
+	}
+}
+
+// Function will start a new page if there is not enough room left for the
+// `height` on the current page.
+func (r *invoiceRenderer) ensureSpace(height float64) {
+	if r.y+height < r.doc.Height-invoiceMarginBottom {
+		return
+	}
+	r.doc.AddPage()
+	r.y = 56
+	if r.inLineItems {
+		r.renderLineItemsHeader()
+	}
+}
+
+func (r *invoiceRenderer) formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(r.data.Location).Format("2006-01-02")
+}
+
+func tenantAddressLines(t *models.Tenant) []string {
+	var lines []string
+	for _, s := range []string{
+		t.StreetAddress,
+		t.StreetAddressExtra,
+		strings.Trim(strings.Join([]string{t.AddressLocality, t.AddressRegion, t.PostalCode}, " "), " "),
+		t.AddressCountry,
+		t.Telephone,
+		t.Email,
+	} {
+		if strings.TrimSpace(s) != "" {
+			lines = append(lines, s)
+		}
+	}
+	return lines
+}
+
+func (r *invoiceRenderer) formatAmount(amount models.Money) string {
+	return amount.Format(r.data.Currency)
+}
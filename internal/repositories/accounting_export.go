@@ -0,0 +1,355 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+type AccountingExportRepo struct {
+	db dbtx
+}
+
+func NewAccountingExportRepo(db *sql.DB) *AccountingExportRepo {
+	return &AccountingExportRepo{
+		db: db,
+	}
+}
+
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *AccountingExportRepo) WithTx(tx *sql.Tx) *AccountingExportRepo {
+	return &AccountingExportRepo{
+		db: tx,
+	}
+}
+
+// The entries which were already exported for the record `source_id` of the
+// `$4` source type, oldest first.
+const accountingExportedEntriesSelect = `
+        SELECT
+            COALESCE(json_agg(e.entry ORDER BY e.id) FILTER (WHERE e.entry IS NOT NULL), '[]')
+        FROM
+            accounting_export_entries AS e
+        WHERE
+            e.tenant_id = $1 AND e.source_type_of = $4 AND e.source_id = `
+
+// Function returns the active invoices dated between `from` (inclusive) and
+// `to` (exclusive) which were never exported along with the invoices of any
+// date which were exported before but were revised since.
+func (r *AccountingExportRepo) ListUnexportedInvoices(ctx context.Context, tenantId uint64, from time.Time, to time.Time) ([]*models.AccountingInvoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT
+        i.id, i.order_id, i.invoice_id, i.invoice_date, i.client_name,
+		wo.currency, i.total_labour, i.total_materials, i.other_costs, i.tax,
+		i.revision_version, i.last_modified_time, (` + accountingExportedEntriesSelect + `i.id)
+    FROM
+        work_order_invoices AS i
+    INNER JOIN
+        work_orders AS wo ON wo.id = i.order_id
+    WHERE
+        i.tenant_id = $1 AND i.is_archived = FALSE
+		AND NOT EXISTS (
+            SELECT 1 FROM accounting_export_entries AS e
+            WHERE e.tenant_id = i.tenant_id AND e.source_type_of = $4 AND e.source_id = i.id
+			AND e.source_version = i.revision_version
+        )
+		AND (
+            (i.invoice_date >= $2 AND i.invoice_date < $3)
+            OR EXISTS (
+                SELECT 1 FROM accounting_export_entries AS e
+                WHERE e.tenant_id = i.tenant_id AND e.source_type_of = $4 AND e.source_id = i.id
+            )
+        )
+    ORDER BY
+        i.invoice_date, i.id`
+	rows, err := r.db.QueryContext(ctx, query, tenantId, from, to, models.AccountingInvoiceSourceTypeOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.AccountingInvoice
+	for rows.Next() {
+		m := new(models.AccountingInvoice)
+		err = rows.Scan(
+			&m.Id, &m.OrderId, &m.InvoiceId, &m.InvoiceDate, &m.ClientName,
+			&m.Currency, &m.TotalLabour, &m.TotalMaterials, &m.OtherCosts, &m.Tax,
+			&m.RevisionVersion, &m.LastModifiedTime, &m.Exported,
+		)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, m)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	return arr, err
+}
+
+// Function returns the active deposits paid between `from` (inclusive) and
+// `to` (exclusive) which were never exported, reversals are included since
+// they are deposits with a negative amount.
+func (r *AccountingExportRepo) ListUnexportedDeposits(ctx context.Context, tenantId uint64, from time.Time, to time.Time) ([]*models.WorkOrderDeposit, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT` + workOrderDepositColumns + `
+    FROM
+        work_order_deposits AS d
+    WHERE
+        tenant_id = $1 AND paid_at >= $2 AND paid_at < $3 AND state = 1
+		AND NOT EXISTS (
+            SELECT 1 FROM accounting_export_entries AS e
+            WHERE e.tenant_id = d.tenant_id AND e.source_type_of = $4 AND e.source_id = d.id
+        )
+    ORDER BY
+        paid_at, id`
+	rows, err := r.db.QueryContext(ctx, query, tenantId, from, to, models.AccountingDepositSourceTypeOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.WorkOrderDeposit
+	for rows.Next() {
+		m, err := scanWorkOrderDeposit(rows)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, m)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	return arr, err
+}
+
+// Function returns the service fees paid between `from` (inclusive) and `to`
+// (exclusive) which were never exported along with the service fee payments
+// of any date which were exported before but were changed since.
+func (r *AccountingExportRepo) ListUnexportedServiceFeePayments(ctx context.Context, tenantId uint64, from time.Time, to time.Time) ([]*models.AccountingServiceFeePayment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT
+        wo.id, COALESCE(wo.associate_name, ''),
+		COALESCE(wo.invoice_service_fee_payment_date, wo.last_modified_time),
+		wo.currency, wo.invoice_actual_service_fee_amount_paid,
+		wo.invoice_service_fee_payment_version, wo.last_modified_time,
+		(` + accountingExportedEntriesSelect + `wo.id)
+    FROM
+        work_orders AS wo
+    WHERE
+        wo.tenant_id = $1
+		AND NOT EXISTS (
+            SELECT 1 FROM accounting_export_entries AS e
+            WHERE e.tenant_id = wo.tenant_id AND e.source_type_of = $4 AND e.source_id = wo.id
+			AND e.source_version = wo.invoice_service_fee_payment_version
+        )
+		AND (
+            (
+                wo.invoice_service_fee_payment_date >= $2
+				AND wo.invoice_service_fee_payment_date < $3
+				AND wo.invoice_actual_service_fee_amount_paid <> 0
+            )
+            OR EXISTS (
+                SELECT 1 FROM accounting_export_entries AS e
+                WHERE e.tenant_id = wo.tenant_id AND e.source_type_of = $4 AND e.source_id = wo.id
+            )
+        )
+    ORDER BY
+        wo.invoice_service_fee_payment_date, wo.id`
+	rows, err := r.db.QueryContext(ctx, query, tenantId, from, to, models.AccountingServiceFeePaymentSourceTypeOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.AccountingServiceFeePayment
+	for rows.Next() {
+		m := new(models.AccountingServiceFeePayment)
+		err = rows.Scan(
+			&m.OrderId, &m.AssociateName, &m.PaidAt, &m.Currency, &m.Amount,
+			&m.Version, &m.LastModifiedTime, &m.Exported,
+		)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, m)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	return arr, err
+}
+
+// Function saves the export and marks the version of the source record of
+// every journal entry as exported in the same transaction, the entries are
+// kept so the file of the export can be downloaded again. If another export
+// already marked one of the records then nothing is saved and the unique
+// violation is returned.
+func (r *AccountingExportRepo) InsertWithEntries(ctx context.Context, m *models.AccountingExport, arr []*models.AccountingJournalEntry) error {
+	return runInTx(ctx, r.db, func(tx dbtx) error {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		query := `
+        INSERT INTO accounting_exports (
+            uuid, tenant_id, format, from_date, to_date, entry_count,
+			created_time, created_by_id, created_by_name, created_from_ip
+        ) VALUES (
+            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+        ) RETURNING id`
+		err := tx.QueryRowContext(
+			ctx, query,
+			m.Uuid, m.TenantId, m.Format, m.FromDate, m.ToDate, m.EntryCount,
+			m.CreatedTime, m.CreatedById, m.CreatedByName, m.CreatedFromIP,
+		).Scan(&m.Id)
+		if err != nil {
+			return err
+		}
+
+		stmt, err := tx.PrepareContext(ctx, `
+        INSERT INTO accounting_export_entries (
+            tenant_id, export_id, source_type_of, source_id, source_version,
+			entry
+        ) VALUES (
+            $1, $2, $3, $4, $5, $6
+        )`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, e := range arr {
+			b, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+			if _, err := stmt.ExecContext(ctx, m.TenantId, m.Id, e.SourceTypeOf, e.SourceId, e.SourceVersion, string(b)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+const accountingExportColumns = `
+        id, uuid, tenant_id, format, from_date, to_date, entry_count,
+		created_time, created_by_id, created_by_name, created_from_ip`
+
+func scanAccountingExport(row interface{ Scan(...interface{}) error }) (*models.AccountingExport, error) {
+	m := new(models.AccountingExport)
+	err := row.Scan(
+		&m.Id, &m.Uuid, &m.TenantId, &m.Format, &m.FromDate, &m.ToDate, &m.EntryCount,
+		&m.CreatedTime, &m.CreatedById, &m.CreatedByName, &m.CreatedFromIP,
+	)
+	return m, err
+}
+
+func (r *AccountingExportRepo) GetById(ctx context.Context, id uint64) (*models.AccountingExport, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT` + accountingExportColumns + `
+    FROM
+        accounting_exports
+    WHERE
+        id = $1`
+	m, err := scanAccountingExport(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		// CASE 1 OF 2: Cannot find record with that id.
+		if err == sql.ErrNoRows {
+			return nil, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Function returns the exports of the tenant, newest first.
+func (r *AccountingExportRepo) ListByTenantId(ctx context.Context, tenantId uint64, offset uint64, limit uint64) ([]*models.AccountingExport, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT` + accountingExportColumns + `
+    FROM
+        accounting_exports
+    WHERE
+        tenant_id = $1
+    ORDER BY
+        id DESC
+    LIMIT $2 OFFSET $3`
+	rows, err := r.db.QueryContext(ctx, query, tenantId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.AccountingExport
+	for rows.Next() {
+		m, err := scanAccountingExport(rows)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, m)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	return arr, err
+}
+
+func (r *AccountingExportRepo) CountByTenantId(ctx context.Context, tenantId uint64) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var count uint64
+	query := `
+    SELECT
+        COUNT(*)
+    FROM
+        accounting_exports
+    WHERE
+        tenant_id = $1`
+	err := r.db.QueryRowContext(ctx, query, tenantId).Scan(&count)
+	return count, err
+}
+
+// Function returns the journal entries of the export in the order they were
+// exported. Please note the exports which were made before the entries were
+// kept only return the entries which were kept, which are none.
+func (r *AccountingExportRepo) ListEntriesByExportId(ctx context.Context, exportId uint64) ([]*models.AccountingJournalEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT
+        COALESCE(json_agg(entry ORDER BY id) FILTER (WHERE entry IS NOT NULL), '[]')
+    FROM
+        accounting_export_entries
+    WHERE
+        export_id = $1`
+	var arr models.AccountingJournalEntries
+	if err := r.db.QueryRowContext(ctx, query, exportId).Scan(&arr); err != nil {
+		return nil, err
+	}
+	return arr, nil
+}
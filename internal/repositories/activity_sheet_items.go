@@ -3,13 +3,14 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"strconv"
 	"time"
 
 	"github.com/over55/workery-server/internal/models"
 )
 
 type ActivitySheetItemRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewActivitySheetItemRepo(db *sql.DB) *ActivitySheetItemRepo {
@@ -18,6 +19,14 @@ func NewActivitySheetItemRepo(db *sql.DB) *ActivitySheetItemRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *ActivitySheetItemRepo) WithTx(tx *sql.Tx) *ActivitySheetItemRepo {
+	return &ActivitySheetItemRepo{
+		db: tx,
+	}
+}
+
 func (r *ActivitySheetItemRepo) Insert(ctx context.Context, m *models.ActivitySheetItem) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -40,20 +49,13 @@ func (r *ActivitySheetItemRepo) Insert(ctx context.Context, m *models.ActivitySh
 		old_id
     ) VALUES (
         $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
-    )`
-	stmt, err := r.db.PrepareContext(ctx, query)
-	if err != nil {
-		return err
-	}
-	defer stmt.Close()
-
-	_, err = stmt.ExecContext(
-		ctx,
+    ) RETURNING id`
+	return r.db.QueryRowContext(
+		ctx, query,
 		m.Uuid, m.TenantId, m.Comment, m.CreatedTime, m.CreatedById, m.CreatedByName,
 		m.CreatedFromIP, m.AssociateId, m.AssociateName, m.AssociateLexicalName, m.OrderId, m.State, m.OngoingOrderId,
 		m.OldId,
-	)
-	return err
+	).Scan(&m.Id)
 }
 
 func (r *ActivitySheetItemRepo) UpdateById(ctx context.Context, m *models.ActivitySheetItem) error {
@@ -75,7 +77,7 @@ func (r *ActivitySheetItemRepo) UpdateById(ctx context.Context, m *models.Activi
 
 	_, err = stmt.ExecContext(
 		ctx,
-		m.Comment, m.AssociateId, m.OrderId, m.State, m.OngoingOrderId,
+		m.Comment, m.AssociateId, m.OrderId, m.State, m.OngoingOrderId, m.Id,
 	)
 	return err
 }
@@ -95,8 +97,8 @@ func (r *ActivitySheetItemRepo) GetById(ctx context.Context, id uint64) (*models
 		created_by_name,
 		created_from_ip,
 		associate_id,
-		associate_name,
-		associate_lexical_name,
+		COALESCE(associate_name, ''),
+		COALESCE(associate_lexical_name, ''),
 		order_id,
 		state,
 		ongoing_order_id
@@ -185,3 +187,125 @@ func (r *ActivitySheetItemRepo) InsertOrUpdateById(ctx context.Context, m *model
 	}
 	return r.UpdateById(ctx, m)
 }
+
+func (r *ActivitySheetItemRepo) queryFilterWhere(f *models.ActivitySheetItemFilter) (string, []interface{}) {
+	// Array will hold all the unique values we want to add into the query.
+	var filterValues []interface{}
+
+	// Start by setting the `tenant_id` placeholder and then append our value
+	// to the array.
+	filterValues = append(filterValues, f.TenantId)
+	query := ` WHERE tenant_id = $` + strconv.Itoa(len(filterValues))
+
+	//
+	// The following code will add our filters
+	//
+
+	if f.AssociateId.Valid {
+		filterValues = append(filterValues, f.AssociateId.Int64)
+		query += ` AND associate_id = $` + strconv.Itoa(len(filterValues))
+	}
+	if f.OrderId.Valid {
+		filterValues = append(filterValues, f.OrderId.Int64)
+		query += ` AND order_id = $` + strconv.Itoa(len(filterValues))
+	}
+	if f.OngoingOrderId.Valid {
+		filterValues = append(filterValues, f.OngoingOrderId.Int64)
+		query += ` AND ongoing_order_id = $` + strconv.Itoa(len(filterValues))
+	}
+	if len(f.States) > 0 {
+		query += ` AND (`
+		for i, v := range f.States {
+			filterValues = append(filterValues, v)
+			if i != 0 {
+				query += ` OR`
+			}
+			query += ` state = $` + strconv.Itoa(len(filterValues))
+		}
+		query += ` )`
+	}
+	return query, filterValues
+}
+
+func (r *ActivitySheetItemRepo) ListByFilter(ctx context.Context, f *models.ActivitySheetItemFilter) ([]*models.ActivitySheetItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	where, filterValues := r.queryFilterWhere(f)
+	query := `
+    SELECT
+        id, uuid, tenant_id, comment, created_time, created_by_id, created_by_name,
+		created_from_ip, associate_id, COALESCE(associate_name, ''),
+		COALESCE(associate_lexical_name, ''), order_id, state, ongoing_order_id, old_id
+    FROM
+        activity_sheet_items` + where
+
+	//
+	// The following code will add our pagination.
+	//
+
+	query += ` ORDER BY ` + f.SortField + ` ` + f.SortOrder + `, id ` + f.SortOrder
+	filterValues = append(filterValues, f.Limit)
+	query += ` LIMIT $` + strconv.Itoa(len(filterValues))
+	filterValues = append(filterValues, f.Offset)
+	query += ` OFFSET $` + strconv.Itoa(len(filterValues))
+
+	rows, err := r.db.QueryContext(ctx, query, filterValues...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.ActivitySheetItem
+	for rows.Next() {
+		m := new(models.ActivitySheetItem)
+		err := rows.Scan(
+			&m.Id, &m.Uuid, &m.TenantId, &m.Comment, &m.CreatedTime, &m.CreatedById, &m.CreatedByName,
+			&m.CreatedFromIP, &m.AssociateId, &m.AssociateName,
+			&m.AssociateLexicalName, &m.OrderId, &m.State, &m.OngoingOrderId, &m.OldId,
+		)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, m)
+	}
+	return arr, rows.Err()
+}
+
+func (r *ActivitySheetItemRepo) CountByFilter(ctx context.Context, f *models.ActivitySheetItemFilter) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var count uint64
+	where, filterValues := r.queryFilterWhere(f)
+	query := `SELECT COUNT(id) FROM activity_sheet_items` + where
+	err := r.db.QueryRowContext(ctx, query, filterValues...).Scan(&count)
+	return count, err
+}
+
+func (r *ActivitySheetItemRepo) GetAcceptanceRateByAssociateId(ctx context.Context, associateId uint64, since time.Time) (*models.AssociateAcceptanceRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	m := &models.AssociateAcceptanceRate{AssociateId: associateId}
+
+	query := `
+    SELECT
+        COUNT(*) FILTER (WHERE state = $1),
+		COUNT(*) FILTER (WHERE state = $2),
+		COUNT(*) FILTER (WHERE state = $3)
+    FROM
+        activity_sheet_items
+    WHERE
+        associate_id = $4 AND created_time >= $5`
+	err := r.db.QueryRowContext(
+		ctx, query,
+		models.ActivitySheetItemAcceptedState, models.ActivitySheetItemDeclinedState, models.ActivitySheetItemPendingState,
+		associateId, since,
+	).Scan(&m.AcceptedCount, &m.DeclinedCount, &m.PendingCount)
+	if err != nil {
+		return nil, err
+	}
+	m.ComputeRate()
+	return m, nil
+}
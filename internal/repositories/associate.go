@@ -3,13 +3,14 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/over55/workery-server/internal/models"
 )
 
 type AssociateRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewAssociateRepo(db *sql.DB) *AssociateRepo {
@@ -18,6 +19,14 @@ func NewAssociateRepo(db *sql.DB) *AssociateRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *AssociateRepo) WithTx(tx *sql.Tx) *AssociateRepo {
+	return &AssociateRepo{
+		db: tx,
+	}
+}
+
 func (r *AssociateRepo) Insert(ctx context.Context, m *models.Associate) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -30,7 +39,7 @@ func (r *AssociateRepo) Insert(ctx context.Context, m *models.Associate) error {
 		wsib_number, wsib_insurance_date, police_check, drivers_license_class,
 		how_hear_old, how_hear_id, how_hear_other, how_hear_text, state, deactivation_reason,
 		deactivation_reason_other, created_time, created_by_id, created_by_name, created_from_ip,
-		last_modified_time, last_modified_by_id, last_modified_by_name, last_modified_from_ip, Score,
+		last_modified_time, last_modified_by_id, last_modified_by_name, last_modified_from_ip, score,
 		old_id, service_fee_id, address_country, address_region, address_locality,
 		post_office_box_number, postal_code, street_address, street_address_extra,
 		full_address_without_postal_code, full_address_with_postal_code, full_address_url,
@@ -38,22 +47,19 @@ func (r *AssociateRepo) Insert(ctx context.Context, m *models.Associate) error {
 		join_date, nationality, gender, tax_id, elevation, latitude, longitude,
 		area_served, available_language, contact_type, email, fax_number,
 		telephone, telephone_type_of, telephone_extension, other_telephone,
-		other_telephone_extension, other_telephone_type_of, name, lexical_name
+		other_telephone_extension, other_telephone_type_of, name, lexical_name,
+		emergency_contact_name, emergency_contact_relationship, emergency_contact_telephone,
+		emergency_contact_alternative_telephone
     ) VALUES (
-        $1, $2, $3, $4, $5, $6, $7, $8, $9, $10 , $11, $12, $13, $14, $15, $16,
-		$17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30,
-		$31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44,
-		$45, $46, $47, $48, $49, $50, $51, $52, $53, $54, $55, $56, $57, $58,
-		$59, $60, $61, $62, $63, $64, $65, $66, $67, $68, $69, $70, $71
-    )`
-	stmt, err := r.db.PrepareContext(ctx, query)
-	if err != nil {
-		return err
-	}
-	defer stmt.Close()
-
-	_, err = stmt.ExecContext(
-		ctx,
+        $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14,
+		$15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28,
+		$29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42,
+		$43, $44, $45, $46, $47, $48, $49, $50, $51, $52, $53, $54, $55, $56,
+		$57, $58, $59, $60, $61, $62, $63, $64, $65, $66, $67, $68, $69, $70,
+		$71, $72, $73, $74, $75
+    ) RETURNING id`
+	return r.db.QueryRowContext(
+		ctx, query,
 		m.Uuid, m.TenantId, m.UserId, m.TypeOf, m.OrganizationName, m.OrganizationTypeOf,
 		m.Business, m.IndexedText, m.IsOkToEmail, m.IsOkToText, m.HourlySalaryDesired,
 		m.LimitSpecial, m.DuesDate, m.CommercialInsuranceExpiryDate,
@@ -69,8 +75,9 @@ func (r *AssociateRepo) Insert(ctx context.Context, m *models.Associate) error {
 		m.AreaServed, m.AvailableLanguage, m.ContactType, m.Email, m.FaxNumber,
 		m.Telephone, m.TelephoneTypeOf, m.TelephoneExtension, m.OtherTelephone,
 		m.OtherTelephoneExtension, m.OtherTelephoneTypeOf, m.Name, m.LexicalName,
-	)
-	return err
+		m.EmergencyContactName, m.EmergencyContactRelationship, m.EmergencyContactTelephone,
+		m.EmergencyContactAlternativeTelephone,
+	).Scan(&m.Id)
 }
 
 func (r *AssociateRepo) UpdateById(ctx context.Context, m *models.Associate) error {
@@ -81,9 +88,26 @@ func (r *AssociateRepo) UpdateById(ctx context.Context, m *models.Associate) err
     UPDATE
         associates
     SET
-        tenant_id = $1, user_id = $2
+        tenant_id = $1, user_id = $2, type_of = $3, organization_name = $4,
+		organization_type_of = $5, business = $6, indexed_text = $7, is_ok_to_email = $8,
+		is_ok_to_text = $9, hourly_salary_desired = $10, limit_special = $11, dues_date = $12,
+		commercial_insurance_expiry_date = $13, auto_insurance_expiry_date = $14, wsib_number = $15, wsib_insurance_date = $16,
+		police_check = $17, drivers_license_class = $18, how_hear_old = $19, how_hear_id = $20,
+		how_hear_other = $21, how_hear_text = $22, state = $23, deactivation_reason = $24,
+		deactivation_reason_other = $25, last_modified_time = $26, last_modified_by_id = $27, last_modified_by_name = $28,
+		last_modified_from_ip = $29, score = $30, service_fee_id = $31, address_country = $32,
+		address_region = $33, address_locality = $34, post_office_box_number = $35, postal_code = $36,
+		street_address = $37, street_address_extra = $38, full_address_without_postal_code = $39, full_address_with_postal_code = $40,
+		full_address_url = $41, given_name = $42, middle_name = $43, last_name = $44,
+		birthdate = $45, join_date = $46, nationality = $47, gender = $48,
+		tax_id = $49, elevation = $50, latitude = $51, longitude = $52,
+		area_served = $53, available_language = $54, contact_type = $55, email = $56,
+		fax_number = $57, telephone = $58, telephone_type_of = $59, telephone_extension = $60,
+		other_telephone = $61, other_telephone_extension = $62, other_telephone_type_of = $63, name = $64,
+		lexical_name = $65, emergency_contact_name = $66, emergency_contact_relationship = $67, emergency_contact_telephone = $68,
+		emergency_contact_alternative_telephone = $69
     WHERE
-        id = $3`
+        id = $70`
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return err
@@ -92,7 +116,18 @@ func (r *AssociateRepo) UpdateById(ctx context.Context, m *models.Associate) err
 
 	_, err = stmt.ExecContext(
 		ctx,
-		m.TenantId, m.UserId, m.Id,
+		m.TenantId, m.UserId, m.TypeOf, m.OrganizationName, m.OrganizationTypeOf, m.Business,
+		m.IndexedText, m.IsOkToEmail, m.IsOkToText, m.HourlySalaryDesired, m.LimitSpecial, m.DuesDate,
+		m.CommercialInsuranceExpiryDate, m.AutoInsuranceExpiryDate, m.WsibNumber, m.WsibInsuranceDate, m.PoliceCheck, m.DriversLicenseClass,
+		m.HowHearOld, m.HowHearId, m.HowHearOther, m.HowHearText, m.State, m.DeactivationReason,
+		m.DeactivationReasonOther, m.LastModifiedTime, m.LastModifiedById, m.LastModifiedByName, m.LastModifiedFromIP, m.Score,
+		m.ServiceFeeId, m.AddressCountry, m.AddressRegion, m.AddressLocality, m.PostOfficeBoxNumber, m.PostalCode,
+		m.StreetAddress, m.StreetAddressExtra, m.FullAddressWithoutPostalCode, m.FullAddressWithPostalCode, m.FullAddressUrl, m.GivenName,
+		m.MiddleName, m.LastName, m.Birthdate, m.JoinDate, m.Nationality, m.Gender,
+		m.TaxId, m.Elevation, m.Latitude, m.Longitude, m.AreaServed, m.AvailableLanguage,
+		m.ContactType, m.Email, m.FaxNumber, m.Telephone, m.TelephoneTypeOf, m.TelephoneExtension,
+		m.OtherTelephone, m.OtherTelephoneExtension, m.OtherTelephoneTypeOf, m.Name, m.LexicalName, m.EmergencyContactName,
+		m.EmergencyContactRelationship, m.EmergencyContactTelephone, m.EmergencyContactAlternativeTelephone, m.Id,
 	)
 	return err
 }
@@ -111,7 +146,7 @@ func (r *AssociateRepo) GetById(ctx context.Context, id uint64) (*models.Associa
 		wsib_number, wsib_insurance_date, police_check, drivers_license_class,
 		how_hear_old, how_hear_id, how_hear_other, how_hear_text, state, deactivation_reason,
 		deactivation_reason_other, created_time, created_by_id, created_by_name, created_from_ip,
-		last_modified_time, last_modified_by_id, created_by_name, last_modified_from_ip, Score,
+		last_modified_time, last_modified_by_id, last_modified_by_name, last_modified_from_ip, score,
 		old_id, service_fee_id, address_country, address_region, address_locality,
 		post_office_box_number, postal_code, street_address, street_address_extra,
 		full_address_without_postal_code, full_address_with_postal_code, full_address_url,
@@ -119,7 +154,9 @@ func (r *AssociateRepo) GetById(ctx context.Context, id uint64) (*models.Associa
 		join_date, nationality, gender, tax_id, elevation, latitude, longitude,
 		area_served, available_language, contact_type, email, fax_number,
 		telephone, telephone_type_of, telephone_extension, other_telephone,
-		other_telephone_extension, other_telephone_type_of, name, lexical_name
+		other_telephone_extension, other_telephone_type_of, name, lexical_name,
+		emergency_contact_name, emergency_contact_relationship, emergency_contact_telephone,
+		emergency_contact_alternative_telephone
 	FROM
         associates
     WHERE
@@ -132,7 +169,7 @@ func (r *AssociateRepo) GetById(ctx context.Context, id uint64) (*models.Associa
 		&m.AutoInsuranceExpiryDate, &m.WsibNumber, &m.WsibInsuranceDate, &m.PoliceCheck,
 		&m.DriversLicenseClass, &m.HowHearOld, &m.HowHearId, &m.HowHearOther, &m.HowHearText, &m.State,
 		&m.DeactivationReason, &m.DeactivationReasonOther, &m.CreatedTime, &m.CreatedById, &m.CreatedByName,
-		&m.CreatedFromIP, &m.LastModifiedTime, &m.LastModifiedById, &m.CreatedByName, &m.LastModifiedFromIP,
+		&m.CreatedFromIP, &m.LastModifiedTime, &m.LastModifiedById, &m.LastModifiedByName, &m.LastModifiedFromIP,
 		&m.Score, &m.OldId, &m.ServiceFeeId, &m.AddressCountry, &m.AddressRegion,
 		&m.AddressLocality, &m.PostOfficeBoxNumber, &m.PostalCode, &m.StreetAddress,
 		&m.StreetAddressExtra, &m.FullAddressWithoutPostalCode, &m.FullAddressWithPostalCode, &m.FullAddressUrl,
@@ -141,6 +178,8 @@ func (r *AssociateRepo) GetById(ctx context.Context, id uint64) (*models.Associa
 		&m.AreaServed, &m.AvailableLanguage, &m.ContactType, &m.Email, &m.FaxNumber,
 		&m.Telephone, &m.TelephoneTypeOf, &m.TelephoneExtension, &m.OtherTelephone,
 		&m.OtherTelephoneExtension, &m.OtherTelephoneTypeOf, &m.Name, &m.LexicalName,
+		&m.EmergencyContactName, &m.EmergencyContactRelationship, &m.EmergencyContactTelephone,
+		&m.EmergencyContactAlternativeTelephone,
 	)
 	if err != nil {
 		// CASE 1 OF 2: Cannot find record with that email.
@@ -153,6 +192,31 @@ func (r *AssociateRepo) GetById(ctx context.Context, id uint64) (*models.Associa
 	return m, nil
 }
 
+func (r *AssociateRepo) GetIdByUserId(ctx context.Context, userId uint64) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var id uint64
+
+	query := `
+    SELECT
+        id
+    FROM
+        associates
+    WHERE
+        user_id = $1`
+	err := r.db.QueryRowContext(ctx, query, userId).Scan(&id)
+	if err != nil {
+		// CASE 1 OF 2: Cannot find record with that user.
+		if err == sql.ErrNoRows {
+			return 0, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return 0, err
+		}
+	}
+	return id, nil
+}
+
 func (r *AssociateRepo) CheckIfExistsById(ctx context.Context, id uint64) (bool, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -221,3 +285,65 @@ func (r *AssociateRepo) InsertOrUpdateById(ctx context.Context, m *models.Associ
 	}
 	return r.UpdateById(ctx, m)
 }
+
+// Function returns true if a associate in the tenant already uses the email
+// or the telephone. The telephone is compared by digits only so formatting
+// differences like "(519) 555-1234" and "519-555-1234" still match.
+func (r *AssociateRepo) CheckIfExistsByEmailOrTelephone(ctx context.Context, tid uint64, email string, telephone string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var exists bool
+
+	query := `
+    SELECT
+        1
+    FROM
+        associates
+    WHERE
+        tenant_id = $1
+    AND (
+        ($2 <> '' AND LOWER(email) = LOWER($2))
+    OR
+        ($3 <> '' AND REGEXP_REPLACE(telephone, '[^0-9]', '', 'g') = $3)
+    )
+    LIMIT 1`
+	err := r.db.QueryRowContext(ctx, query, tid, email, telephone).Scan(&exists)
+	if err != nil {
+		// CASE 1 OF 2: Cannot find record with that email or telephone.
+		if err == sql.ErrNoRows {
+			return false, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return false, err
+		}
+	}
+	return exists, nil
+}
+
+func (r *AssociateRepo) InsertWithUser(ctx context.Context, user *models.User, m *models.Associate) error {
+	return runInTx(ctx, r.db, func(tx dbtx) error {
+		ur := &UserRepo{db: tx}
+		if err := ur.Insert(ctx, user); err != nil {
+			return err
+		}
+		u, err := ur.GetByEmail(ctx, user.Email)
+		if err != nil {
+			return err
+		}
+		if u == nil { // Defensive code
+			return errors.New("user account was not created")
+		}
+		user.Id = u.Id
+		m.UserId = u.Id
+		return (&AssociateRepo{db: tx}).Insert(ctx, m)
+	})
+}
+
+func (r *AssociateRepo) UpdateWithUserById(ctx context.Context, user *models.User, m *models.Associate) error {
+	return runInTx(ctx, r.db, func(tx dbtx) error {
+		if err := (&UserRepo{db: tx}).UpdateById(ctx, user); err != nil {
+			return err
+		}
+		return (&AssociateRepo{db: tx}).UpdateById(ctx, m)
+	})
+}
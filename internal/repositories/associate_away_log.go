@@ -6,11 +6,13 @@ import (
 	"strconv"
 	"time"
 
+	null "gopkg.in/guregu/null.v4"
+
 	"github.com/over55/workery-server/internal/models"
 )
 
 type AssociateAwayLogRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewAssociateAwayLogRepo(db *sql.DB) *AssociateAwayLogRepo {
@@ -19,6 +21,14 @@ func NewAssociateAwayLogRepo(db *sql.DB) *AssociateAwayLogRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *AssociateAwayLogRepo) WithTx(tx *sql.Tx) *AssociateAwayLogRepo {
+	return &AssociateAwayLogRepo{
+		db: tx,
+	}
+}
+
 func (r *AssociateAwayLogRepo) Insert(ctx context.Context, m *models.AssociateAwayLog) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -31,21 +41,14 @@ func (r *AssociateAwayLogRepo) Insert(ctx context.Context, m *models.AssociateAw
 		last_modified_by_id, last_modified_by_name, last_modified_from_ip, old_id
     ) VALUES (
         $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
-    )`
-	stmt, err := r.db.PrepareContext(ctx, query)
-	if err != nil {
-		return err
-	}
-	defer stmt.Close()
-
-	_, err = stmt.ExecContext(
-		ctx,
+    ) RETURNING id`
+	return r.db.QueryRowContext(
+		ctx, query,
 		m.Uuid, m.TenantId, m.AssociateId, m.AssociateName, m.AssociateLexicalName, m.Reason, m.ReasonOther,
 		m.UntilFurtherNotice, m.UntilDate, m.StartDate, m.State,
 		m.CreatedTime, m.CreatedById, m.CreatedByName, m.CreatedFromIP, m.LastModifiedTime,
 		m.LastModifiedById, m.LastModifiedByName, m.LastModifiedFromIP, m.OldId,
-	)
-	return err
+	).Scan(&m.Id)
 }
 
 func (r *AssociateAwayLogRepo) UpdateById(ctx context.Context, m *models.AssociateAwayLog) error {
@@ -56,9 +59,12 @@ func (r *AssociateAwayLogRepo) UpdateById(ctx context.Context, m *models.Associa
     UPDATE
         associate_away_logs
     SET
-        tenant_id = $1, associate_id = $2
+        tenant_id = $1, associate_id = $2, associate_name = $3, associate_lexical_name = $4,
+		reason = $5, reason_other = $6, until_further_notice = $7, until_date = $8,
+		start_date = $9, state = $10, last_modified_time = $11, last_modified_by_id = $12,
+		last_modified_by_name = $13, last_modified_from_ip = $14
     WHERE
-        id = $3`
+        id = $15`
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return err
@@ -67,7 +73,10 @@ func (r *AssociateAwayLogRepo) UpdateById(ctx context.Context, m *models.Associa
 
 	_, err = stmt.ExecContext(
 		ctx,
-		m.TenantId, m.AssociateId, m.Id,
+		m.TenantId, m.AssociateId, m.AssociateName, m.AssociateLexicalName,
+		m.Reason, m.ReasonOther, m.UntilFurtherNotice, m.UntilDate,
+		m.StartDate, m.State, m.LastModifiedTime, m.LastModifiedById,
+		m.LastModifiedByName, m.LastModifiedFromIP, m.Id,
 	)
 	return err
 }
@@ -80,13 +89,19 @@ func (r *AssociateAwayLogRepo) GetById(ctx context.Context, id uint64) (*models.
 
 	query := `
     SELECT
-        id, uuid, tenant_id, associate_id
+        id, uuid, tenant_id, associate_id, associate_name, associate_lexical_name, reason, reason_other,
+		until_further_notice, until_date, start_date, state,
+		created_time, created_by_id, created_by_name, created_from_ip, last_modified_time,
+		last_modified_by_id, last_modified_by_name, last_modified_from_ip, old_id
 	FROM
         associate_away_logs
     WHERE
         id = $1`
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&m.Id, &m.Uuid, &m.TenantId, &m.AssociateId,
+		&m.Id, &m.Uuid, &m.TenantId, &m.AssociateId, &m.AssociateName, &m.AssociateLexicalName, &m.Reason, &m.ReasonOther,
+		&m.UntilFurtherNotice, &m.UntilDate, &m.StartDate, &m.State,
+		&m.CreatedTime, &m.CreatedById, &m.CreatedByName, &m.CreatedFromIP, &m.LastModifiedTime,
+		&m.LastModifiedById, &m.LastModifiedByName, &m.LastModifiedFromIP, &m.OldId,
 	)
 	if err != nil {
 		// CASE 1 OF 2: Cannot find record with that email.
@@ -167,6 +182,119 @@ func (r *AssociateAwayLogRepo) InsertOrUpdateById(ctx context.Context, m *models
 	return r.UpdateById(ctx, m)
 }
 
+// Function returns true if the associate has an active away log which covers
+// the time, a log without a start date started right away.
+func (r *AssociateAwayLogRepo) CheckIfAwayByAssociateId(ctx context.Context, associateId uint64, at time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var exists bool
+
+	query := `
+    SELECT
+        1
+    FROM
+        associate_away_logs
+    WHERE
+        associate_id = $1
+		AND state = $2
+		AND (start_date IS NULL OR start_date <= $3)
+		AND (until_further_notice = TRUE OR until_date IS NULL OR until_date >= $3)
+    LIMIT 1`
+	err := r.db.QueryRowContext(ctx, query, associateId, models.AssociateAwayLogActiveState, at).Scan(&exists)
+	if err != nil {
+		// CASE 1 OF 2: The associate is not away.
+		if err == sql.ErrNoRows {
+			return false, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return false, err
+		}
+	}
+	return exists, nil
+}
+
+func (r *AssociateAwayLogRepo) CheckIfOverlapsByAssociateId(ctx context.Context, associateId uint64, excludeId uint64, startDate null.Time, untilDate null.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var exists bool
+
+	query := `
+    SELECT
+        1
+    FROM
+        associate_away_logs
+    WHERE
+        associate_id = $1
+		AND id <> $2
+		AND state = $3
+		AND (start_date IS NULL OR $5::TIMESTAMP IS NULL OR start_date <= $5)
+		AND (until_further_notice = TRUE OR until_date IS NULL OR $4::TIMESTAMP IS NULL OR until_date >= $4)
+    LIMIT 1`
+	err := r.db.QueryRowContext(ctx, query, associateId, excludeId, models.AssociateAwayLogActiveState, startDate, untilDate).Scan(&exists)
+	if err != nil {
+		// CASE 1 OF 2: No away log overlaps with the period.
+		if err == sql.ErrNoRows {
+			return false, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return false, err
+		}
+	}
+	return exists, nil
+}
+
+func (r *AssociateAwayLogRepo) SaveIfNotOverlapping(ctx context.Context, m *models.AssociateAwayLog) error {
+	return runInTx(ctx, r.db, func(tx dbtx) error {
+		// Lock the associate so the away logs of the associate are saved one
+		// after the other.
+		query := `
+        SELECT
+            id
+        FROM
+            associates
+        WHERE
+            id = $1
+        FOR UPDATE`
+		if _, err := tx.ExecContext(ctx, query, m.AssociateId); err != nil {
+			return err
+		}
+
+		txr := &AssociateAwayLogRepo{db: tx}
+		doesOverlap, err := txr.CheckIfOverlapsByAssociateId(ctx, m.AssociateId, m.Id, m.StartDate, m.UntilDate)
+		if err != nil {
+			return err
+		}
+		if doesOverlap {
+			return models.ErrAssociateAwayLogOverlaps
+		}
+		if m.Id == 0 {
+			return txr.Insert(ctx, m)
+		}
+		return txr.UpdateById(ctx, m)
+	})
+}
+
+func (r *AssociateAwayLogRepo) ExpireByUntilDate(ctx context.Context, now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    UPDATE
+        associate_away_logs
+    SET
+        state = $1, last_modified_time = $2
+    WHERE
+        state = $3
+		AND until_further_notice = FALSE
+		AND until_date IS NOT NULL
+		AND until_date < $2`
+	res, err := r.db.ExecContext(ctx, query, models.AssociateAwayLogInactiveState, now, models.AssociateAwayLogActiveState)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 func (s *AssociateAwayLogRepo) queryRowsWithFilter(ctx context.Context, query string, f *models.AssociateAwayLogFilter) (*sql.Rows, error) {
 	// Array will hold all the unique values we want to add into the query.
 	var filterValues []interface{}
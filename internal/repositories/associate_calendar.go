@@ -0,0 +1,213 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+type AssociateCalendarRepo struct {
+	db dbtx
+}
+
+func NewAssociateCalendarRepo(db *sql.DB) *AssociateCalendarRepo {
+	return &AssociateCalendarRepo{
+		db: db,
+	}
+}
+
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *AssociateCalendarRepo) WithTx(tx *sql.Tx) *AssociateCalendarRepo {
+	return &AssociateCalendarRepo{
+		db: tx,
+	}
+}
+
+func (r *AssociateCalendarRepo) ListEventsByAssociateId(ctx context.Context, associateId uint64, from time.Time, to time.Time) ([]*models.AssociateCalendarEvent, error) {
+	arr, err := r.listWorkOrderEvents(ctx, associateId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	awayArr, err := r.listAwayEvents(ctx, associateId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return append(arr, awayArr...), nil
+}
+
+func (r *AssociateCalendarRepo) listWorkOrderEvents(ctx context.Context, associateId uint64, from time.Time, to time.Time) ([]*models.AssociateCalendarEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// DEVELOPERS NOTE:
+	// The archived, declined and cancelled work orders are not shown.
+	query := `
+    SELECT
+        id, is_ongoing, ongoing_work_order_id, customer_name, description,
+		start_date, assignment_date, state
+    FROM
+        work_orders
+    WHERE
+        associate_id = $1
+		AND state NOT IN ($2, $3, $4)
+		AND start_date >= $5
+		AND start_date < $6
+    ORDER BY
+        start_date ASC, id ASC`
+	rows, err := r.db.QueryContext(
+		ctx, query, associateId,
+		models.WorkOrderArchivedState, models.WorkOrderDeclinedState, models.WorkOrderCancelledState,
+		from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.AssociateCalendarEvent
+	for rows.Next() {
+		var (
+			id           uint64
+			isOngoing    bool
+			customerName string
+		)
+		e := new(models.AssociateCalendarEvent)
+		err := rows.Scan(
+			&id, &isOngoing, &e.OngoingOrderId, &customerName, &e.Description,
+			&e.StartDate, &e.AssignmentDate, &e.State,
+		)
+		if err != nil {
+			return nil, err
+		}
+		e.Uid = "work-order-" + strconv.FormatUint(id, 10)
+		e.TypeOf = models.AssociateCalendarWorkOrderEventTypeOf
+		if isOngoing {
+			e.TypeOf = models.AssociateCalendarOngoingVisitEventTypeOf
+		}
+		e.Title = "Job #" + strconv.FormatUint(id, 10) + ": " + customerName
+		e.OrderId = null.IntFrom(int64(id))
+		arr = append(arr, e)
+	}
+	return arr, rows.Err()
+}
+
+func (r *AssociateCalendarRepo) listAwayEvents(ctx context.Context, associateId uint64, from time.Time, to time.Time) ([]*models.AssociateCalendarEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// DEVELOPERS NOTE:
+	// An away log without a start date started when it was created.
+	query := `
+    SELECT
+        id, reason, reason_other, COALESCE(start_date, created_time),
+		until_further_notice, until_date, state
+    FROM
+        associate_away_logs
+    WHERE
+        associate_id = $1
+		AND state = $2
+		AND COALESCE(start_date, created_time) < $4
+		AND (until_further_notice = TRUE OR until_date IS NULL OR until_date >= $3)
+    ORDER BY
+        4 ASC, id ASC`
+	rows, err := r.db.QueryContext(ctx, query, associateId, models.AssociateAwayLogActiveState, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.AssociateCalendarEvent
+	for rows.Next() {
+		var (
+			id                 uint64
+			reason             int8
+			reasonOther        null.String
+			untilFurtherNotice bool
+		)
+		e := new(models.AssociateCalendarEvent)
+		err := rows.Scan(
+			&id, &reason, &reasonOther, &e.StartDate,
+			&untilFurtherNotice, &e.EndDate, &e.State,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if untilFurtherNotice {
+			e.EndDate = null.Time{}
+		}
+		e.Uid = "away-log-" + strconv.FormatUint(id, 10)
+		e.TypeOf = models.AssociateCalendarAwayEventTypeOf
+		e.Title = "Away: " + models.AssociateAwayLogReasonTitles[reason]
+		e.Description = reasonOther.ValueOrZero()
+		e.AwayLogId = null.IntFrom(int64(id))
+		arr = append(arr, e)
+	}
+	return arr, rows.Err()
+}
+
+func (r *AssociateCalendarRepo) GetTokenByTokenHash(ctx context.Context, tokenHash string) (*models.AssociateCalendarToken, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	m := new(models.AssociateCalendarToken)
+
+	query := `
+    SELECT
+        id, tenant_id, associate_id, token_hash, created_time, created_by_id, created_by_name
+    FROM
+        associate_calendar_tokens
+    WHERE
+        token_hash = $1`
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&m.Id, &m.TenantId, &m.AssociateId, &m.TokenHash, &m.CreatedTime, &m.CreatedById, &m.CreatedByName,
+	)
+	if err != nil {
+		// CASE 1 OF 2: Cannot find record with that token.
+		if err == sql.ErrNoRows {
+			return nil, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (r *AssociateCalendarRepo) InsertOrUpdateTokenByAssociateId(ctx context.Context, m *models.AssociateCalendarToken) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    INSERT INTO associate_calendar_tokens (
+        tenant_id, associate_id, token_hash, created_time, created_by_id, created_by_name
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6
+    ) ON CONFLICT (associate_id) DO UPDATE SET
+        token_hash = EXCLUDED.token_hash,
+		created_time = EXCLUDED.created_time,
+		created_by_id = EXCLUDED.created_by_id,
+		created_by_name = EXCLUDED.created_by_name
+    RETURNING id`
+	return r.db.QueryRowContext(
+		ctx, query,
+		m.TenantId, m.AssociateId, m.TokenHash, m.CreatedTime, m.CreatedById, m.CreatedByName,
+	).Scan(&m.Id)
+}
+
+func (r *AssociateCalendarRepo) DeleteTokenByAssociateId(ctx context.Context, associateId uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    DELETE FROM
+        associate_calendar_tokens
+    WHERE
+        associate_id = $1`
+	_, err := r.db.ExecContext(ctx, query, associateId)
+	return err
+}
@@ -9,7 +9,7 @@ import (
 )
 
 type AssociateCommentRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewAssociateCommentRepo(db *sql.DB) *AssociateCommentRepo {
@@ -18,6 +18,14 @@ func NewAssociateCommentRepo(db *sql.DB) *AssociateCommentRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *AssociateCommentRepo) WithTx(tx *sql.Tx) *AssociateCommentRepo {
+	return &AssociateCommentRepo{
+		db: tx,
+	}
+}
+
 func (r *AssociateCommentRepo) Insert(ctx context.Context, m *models.AssociateComment) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+type AssociateComplianceRepo struct {
+	db dbtx
+}
+
+func NewAssociateComplianceRepo(db *sql.DB) *AssociateComplianceRepo {
+	return &AssociateComplianceRepo{
+		db: db,
+	}
+}
+
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *AssociateComplianceRepo) WithTx(tx *sql.Tx) *AssociateComplianceRepo {
+	return &AssociateComplianceRepo{
+		db: tx,
+	}
+}
+
+// The date of the associate which the insurance requirement expires on, keep
+// in sync with the `associate_date_type_of` of the `InsuranceRequirement`.
+const associateComplianceExpiryDate = `
+        CASE ir.associate_date_type_of
+		    WHEN 1 THEN a.commercial_insurance_expiry_date
+			WHEN 2 THEN a.auto_insurance_expiry_date
+			WHEN 3 THEN a.wsib_insurance_date
+			WHEN 4 THEN a.police_check
+			ELSE a.dues_date
+		END`
+
+const associateComplianceSelect = `
+    SELECT
+        a.id, COALESCE(a.name, ''), COALESCE(a.lexical_name, ''),
+		ir.id, ir.text, ir.associate_date_type_of,` + associateComplianceExpiryDate + `
+    FROM
+        associates AS a
+    INNER JOIN
+        associate_insurance_requirements AS air ON air.associate_id = a.id
+    INNER JOIN
+        insurance_requirements AS ir ON ir.id = air.insurance_requirement_id
+    WHERE
+        ir.state = 1 AND ir.associate_date_type_of <> 0`
+
+func (r *AssociateComplianceRepo) ListByTenantId(ctx context.Context, tenantId uint64) ([]*models.AssociateCompliance, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := associateComplianceSelect + `
+		AND a.tenant_id = $1 AND a.state = $2
+    ORDER BY
+        a.lexical_name ASC, a.id ASC, ir.text ASC`
+	return r.queryAssociateCompliances(ctx, query, tenantId, models.AssociateActiveState)
+}
+
+func (r *AssociateComplianceRepo) GetByAssociateId(ctx context.Context, associateId uint64) (*models.AssociateCompliance, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := associateComplianceSelect + `
+		AND a.id = $1
+    ORDER BY
+        ir.text ASC`
+	arr, err := r.queryAssociateCompliances(ctx, query, associateId)
+	if err != nil {
+		return nil, err
+	}
+	if len(arr) == 0 { // The associate does not have tracked requirements.
+		return &models.AssociateCompliance{
+			AssociateId: associateId,
+			Items:       []*models.AssociateComplianceItem{},
+		}, nil
+	}
+	return arr[0], nil
+}
+
+// Function groups the rows, which must be ordered by the associate, by the
+// associate.
+func (r *AssociateComplianceRepo) queryAssociateCompliances(ctx context.Context, query string, values ...interface{}) ([]*models.AssociateCompliance, error) {
+	rows, err := r.db.QueryContext(ctx, query, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.AssociateCompliance
+	var m *models.AssociateCompliance
+	for rows.Next() {
+		var (
+			associateId          uint64
+			associateName        string
+			associateLexicalName string
+		)
+		item := new(models.AssociateComplianceItem)
+		err := rows.Scan(
+			&associateId, &associateName, &associateLexicalName,
+			&item.InsuranceRequirementId, &item.InsuranceRequirementText, &item.AssociateDateTypeOf, &item.ExpiryDate,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if m == nil || m.AssociateId != associateId {
+			m = &models.AssociateCompliance{
+				AssociateId:          associateId,
+				AssociateName:        associateName,
+				AssociateLexicalName: associateLexicalName,
+			}
+			arr = append(arr, m)
+		}
+		m.Items = append(m.Items, item)
+	}
+	return arr, rows.Err()
+}
+
+func (r *AssociateComplianceRepo) ListLapsedForWorkOrder(ctx context.Context, associateId uint64, orderId uint64, now time.Time) ([]*models.AssociateComplianceItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT DISTINCT
+        ir.id, ir.text, ir.associate_date_type_of,` + associateComplianceExpiryDate + `
+    FROM
+        work_order_skill_sets AS woss
+    INNER JOIN
+        skill_set_insurance_requirements AS ssir ON ssir.skill_set_id = woss.skill_set_id
+    INNER JOIN
+        insurance_requirements AS ir ON ir.id = ssir.insurance_requirement_id
+    INNER JOIN
+        associates AS a ON a.id = $1
+    WHERE
+        woss.order_id = $2
+		AND ir.state = 1
+		AND ir.associate_date_type_of <> 0
+		AND ` + associateComplianceExpiryDate + ` <= $3
+    ORDER BY
+        ir.text ASC`
+	rows, err := r.db.QueryContext(ctx, query, associateId, orderId, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.AssociateComplianceItem
+	for rows.Next() {
+		m := new(models.AssociateComplianceItem)
+		err := rows.Scan(
+			&m.InsuranceRequirementId, &m.InsuranceRequirementText, &m.AssociateDateTypeOf, &m.ExpiryDate,
+		)
+		if err != nil {
+			return nil, err
+		}
+		m.Status = models.AssociateComplianceLapsedStatus
+		arr = append(arr, m)
+	}
+	return arr, rows.Err()
+}
@@ -9,7 +9,7 @@ import (
 )
 
 type AssociateInsuranceRequirementRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewAssociateInsuranceRequirementRepo(db *sql.DB) *AssociateInsuranceRequirementRepo {
@@ -18,6 +18,14 @@ func NewAssociateInsuranceRequirementRepo(db *sql.DB) *AssociateInsuranceRequire
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *AssociateInsuranceRequirementRepo) WithTx(tx *sql.Tx) *AssociateInsuranceRequirementRepo {
+	return &AssociateInsuranceRequirementRepo{
+		db: tx,
+	}
+}
+
 func (r *AssociateInsuranceRequirementRepo) Insert(ctx context.Context, m *models.AssociateInsuranceRequirement) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -160,3 +168,79 @@ func (r *AssociateInsuranceRequirementRepo) InsertOrUpdateById(ctx context.Conte
 	}
 	return r.UpdateById(ctx, m)
 }
+
+func (r *AssociateInsuranceRequirementRepo) ListByAssociateId(ctx context.Context, associateId uint64) ([]*models.AssociateInsuranceRequirement, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT
+        x.id, x.uuid, x.tenant_id, x.associate_id, x.insurance_requirement_id, x.old_id, ir.text
+    FROM
+        associate_insurance_requirements AS x
+    INNER JOIN
+        insurance_requirements AS ir ON ir.id = x.insurance_requirement_id
+    WHERE
+        x.associate_id = $1
+    ORDER BY
+        ir.text ASC`
+	rows, err := r.db.QueryContext(ctx, query, associateId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.AssociateInsuranceRequirement
+	for rows.Next() {
+		m := new(models.AssociateInsuranceRequirement)
+		err := rows.Scan(
+			&m.Id, &m.Uuid, &m.TenantId, &m.AssociateId, &m.InsuranceRequirementId, &m.OldId, &m.Text,
+		)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, m)
+	}
+	return arr, rows.Err()
+}
+
+func (r *AssociateInsuranceRequirementRepo) GetByAssociateIdAndInsuranceRequirementId(ctx context.Context, associateId uint64, insuranceRequirementId uint64) (*models.AssociateInsuranceRequirement, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	m := new(models.AssociateInsuranceRequirement)
+
+	query := `
+    SELECT
+        id, uuid, tenant_id, associate_id, insurance_requirement_id, old_id
+    FROM
+        associate_insurance_requirements
+    WHERE
+        associate_id = $1 AND insurance_requirement_id = $2
+    LIMIT 1`
+	err := r.db.QueryRowContext(ctx, query, associateId, insuranceRequirementId).Scan(
+		&m.Id, &m.Uuid, &m.TenantId, &m.AssociateId, &m.InsuranceRequirementId, &m.OldId,
+	)
+	if err != nil {
+		// CASE 1 OF 2: Cannot find record.
+		if err == sql.ErrNoRows {
+			return nil, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (r *AssociateInsuranceRequirementRepo) DeleteById(ctx context.Context, id uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    DELETE FROM
+        associate_insurance_requirements
+    WHERE
+        id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
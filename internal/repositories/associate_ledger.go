@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+type AssociateLedgerRepo struct {
+	db dbtx
+}
+
+func NewAssociateLedgerRepo(db *sql.DB) *AssociateLedgerRepo {
+	return &AssociateLedgerRepo{
+		db: db,
+	}
+}
+
+// Function returns the ledger entries of the associate between the `from`
+// (inclusive) and `to` (exclusive) times, a zero time leaves that side of the
+// range open. The `Balance` of the entries is left for the caller to compute.
+func (r *AssociateLedgerRepo) ListByAssociateId(ctx context.Context, associateId uint64, from time.Time, to time.Time) ([]*models.AssociateLedgerEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT
+        tenant_id, associate_id, order_id, type_of, entry_date, currency, amount
+    FROM
+        associate_service_fee_ledger
+    WHERE
+        associate_id = $1`
+	args := []interface{}{associateId}
+	if !from.IsZero() {
+		args = append(args, from)
+		query += ` AND entry_date >= $` + strconv.Itoa(len(args))
+	}
+	if !to.IsZero() {
+		args = append(args, to)
+		query += ` AND entry_date < $` + strconv.Itoa(len(args))
+	}
+	query += `
+    ORDER BY
+        entry_date, order_id, type_of`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.AssociateLedgerEntry
+	for rows.Next() {
+		m := new(models.AssociateLedgerEntry)
+		err = rows.Scan(
+			&m.TenantId, &m.AssociateId, &m.OrderId, &m.TypeOf, &m.EntryDate, &m.Currency, &m.Amount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, m)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	return arr, err
+}
+
+// Function returns what the associate owed us right before the time.
+func (r *AssociateLedgerRepo) GetBalanceByAssociateId(ctx context.Context, associateId uint64, before time.Time) (models.Money, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var balance models.Money
+
+	query := `
+    SELECT
+        COALESCE(SUM(amount), 0)
+    FROM
+        associate_service_fee_ledger
+    WHERE
+        associate_id = $1 AND entry_date < $2`
+	err := r.db.QueryRowContext(ctx, query, associateId, before).Scan(&balance)
+	return balance, err
+}
+
+// Function returns the balance of every associate of the tenant who owes us
+// or who we owe, sorted by the largest balance first.
+func (r *AssociateLedgerRepo) ListBalancesByTenantId(ctx context.Context, tenantId uint64) ([]*models.AssociateBalance, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT
+        l.associate_id, COALESCE(a.name, ''), COALESCE(a.lexical_name, ''), SUM(l.amount) AS balance
+    FROM
+        associate_service_fee_ledger AS l
+    INNER JOIN
+        associates AS a ON a.id = l.associate_id
+    WHERE
+        l.tenant_id = $1
+    GROUP BY
+        l.associate_id, a.name, a.lexical_name
+    HAVING
+        SUM(l.amount) <> 0
+    ORDER BY
+        balance DESC, a.lexical_name`
+	rows, err := r.db.QueryContext(ctx, query, tenantId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.AssociateBalance
+	for rows.Next() {
+		m := new(models.AssociateBalance)
+		err = rows.Scan(&m.AssociateId, &m.AssociateName, &m.AssociateLexicalName, &m.Balance)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, m)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	return arr, err
+}
@@ -9,7 +9,7 @@ import (
 )
 
 type AssociateSkillSetRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewAssociateSkillSetRepo(db *sql.DB) *AssociateSkillSetRepo {
@@ -18,6 +18,14 @@ func NewAssociateSkillSetRepo(db *sql.DB) *AssociateSkillSetRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *AssociateSkillSetRepo) WithTx(tx *sql.Tx) *AssociateSkillSetRepo {
+	return &AssociateSkillSetRepo{
+		db: tx,
+	}
+}
+
 func (r *AssociateSkillSetRepo) Insert(ctx context.Context, m *models.AssociateSkillSet) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -159,3 +167,79 @@ func (r *AssociateSkillSetRepo) InsertOrUpdateById(ctx context.Context, m *model
 	}
 	return r.UpdateById(ctx, m)
 }
+
+func (r *AssociateSkillSetRepo) ListByAssociateId(ctx context.Context, associateId uint64) ([]*models.AssociateSkillSet, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT
+        x.id, x.uuid, x.tenant_id, x.associate_id, x.skill_set_id, x.old_id, ss.category, ss.sub_category
+    FROM
+        associate_skill_sets AS x
+    INNER JOIN
+        skill_sets AS ss ON ss.id = x.skill_set_id
+    WHERE
+        x.associate_id = $1
+    ORDER BY
+        ss.category ASC, ss.sub_category ASC`
+	rows, err := r.db.QueryContext(ctx, query, associateId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.AssociateSkillSet
+	for rows.Next() {
+		m := new(models.AssociateSkillSet)
+		err := rows.Scan(
+			&m.Id, &m.Uuid, &m.TenantId, &m.AssociateId, &m.SkillSetId, &m.OldId, &m.Category, &m.SubCategory,
+		)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, m)
+	}
+	return arr, rows.Err()
+}
+
+func (r *AssociateSkillSetRepo) GetByAssociateIdAndSkillSetId(ctx context.Context, associateId uint64, skillSetId uint64) (*models.AssociateSkillSet, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	m := new(models.AssociateSkillSet)
+
+	query := `
+    SELECT
+        id, uuid, tenant_id, associate_id, skill_set_id, old_id
+    FROM
+        associate_skill_sets
+    WHERE
+        associate_id = $1 AND skill_set_id = $2
+    LIMIT 1`
+	err := r.db.QueryRowContext(ctx, query, associateId, skillSetId).Scan(
+		&m.Id, &m.Uuid, &m.TenantId, &m.AssociateId, &m.SkillSetId, &m.OldId,
+	)
+	if err != nil {
+		// CASE 1 OF 2: Cannot find record.
+		if err == sql.ErrNoRows {
+			return nil, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (r *AssociateSkillSetRepo) DeleteById(ctx context.Context, id uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    DELETE FROM
+        associate_skill_sets
+    WHERE
+        id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
@@ -9,7 +9,7 @@ import (
 )
 
 type AssociateTagRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewAssociateTagRepo(db *sql.DB) *AssociateTagRepo {
@@ -18,6 +18,14 @@ func NewAssociateTagRepo(db *sql.DB) *AssociateTagRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *AssociateTagRepo) WithTx(tx *sql.Tx) *AssociateTagRepo {
+	return &AssociateTagRepo{
+		db: tx,
+	}
+}
+
 func (r *AssociateTagRepo) Insert(ctx context.Context, m *models.AssociateTag) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -159,3 +167,79 @@ func (r *AssociateTagRepo) InsertOrUpdateById(ctx context.Context, m *models.Ass
 	}
 	return r.UpdateById(ctx, m)
 }
+
+func (r *AssociateTagRepo) ListByAssociateId(ctx context.Context, associateId uint64) ([]*models.AssociateTag, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT
+        x.id, x.uuid, x.tenant_id, x.associate_id, x.tag_id, x.old_id, t.text
+    FROM
+        associate_tags AS x
+    INNER JOIN
+        tags AS t ON t.id = x.tag_id
+    WHERE
+        x.associate_id = $1
+    ORDER BY
+        t.text ASC`
+	rows, err := r.db.QueryContext(ctx, query, associateId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.AssociateTag
+	for rows.Next() {
+		m := new(models.AssociateTag)
+		err := rows.Scan(
+			&m.Id, &m.Uuid, &m.TenantId, &m.AssociateId, &m.TagId, &m.OldId, &m.Text,
+		)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, m)
+	}
+	return arr, rows.Err()
+}
+
+func (r *AssociateTagRepo) GetByAssociateIdAndTagId(ctx context.Context, associateId uint64, tagId uint64) (*models.AssociateTag, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	m := new(models.AssociateTag)
+
+	query := `
+    SELECT
+        id, uuid, tenant_id, associate_id, tag_id, old_id
+    FROM
+        associate_tags
+    WHERE
+        associate_id = $1 AND tag_id = $2
+    LIMIT 1`
+	err := r.db.QueryRowContext(ctx, query, associateId, tagId).Scan(
+		&m.Id, &m.Uuid, &m.TenantId, &m.AssociateId, &m.TagId, &m.OldId,
+	)
+	if err != nil {
+		// CASE 1 OF 2: Cannot find record.
+		if err == sql.ErrNoRows {
+			return nil, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (r *AssociateTagRepo) DeleteById(ctx context.Context, id uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    DELETE FROM
+        associate_tags
+    WHERE
+        id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
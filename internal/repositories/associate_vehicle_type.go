@@ -9,7 +9,7 @@ import (
 )
 
 type AssociateVehicleTypeRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewAssociateVehicleTypeRepo(db *sql.DB) *AssociateVehicleTypeRepo {
@@ -18,6 +18,14 @@ func NewAssociateVehicleTypeRepo(db *sql.DB) *AssociateVehicleTypeRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *AssociateVehicleTypeRepo) WithTx(tx *sql.Tx) *AssociateVehicleTypeRepo {
+	return &AssociateVehicleTypeRepo{
+		db: tx,
+	}
+}
+
 func (r *AssociateVehicleTypeRepo) Insert(ctx context.Context, m *models.AssociateVehicleType) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -159,3 +167,79 @@ func (r *AssociateVehicleTypeRepo) InsertOrUpdateById(ctx context.Context, m *mo
 	}
 	return r.UpdateById(ctx, m)
 }
+
+func (r *AssociateVehicleTypeRepo) ListByAssociateId(ctx context.Context, associateId uint64) ([]*models.AssociateVehicleType, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT
+        x.id, x.uuid, x.tenant_id, x.associate_id, x.vehicle_type_id, x.old_id, vt.text
+    FROM
+        associate_vehicle_types AS x
+    INNER JOIN
+        vehicle_types AS vt ON vt.id = x.vehicle_type_id
+    WHERE
+        x.associate_id = $1
+    ORDER BY
+        vt.text ASC`
+	rows, err := r.db.QueryContext(ctx, query, associateId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.AssociateVehicleType
+	for rows.Next() {
+		m := new(models.AssociateVehicleType)
+		err := rows.Scan(
+			&m.Id, &m.Uuid, &m.TenantId, &m.AssociateId, &m.VehicleTypeId, &m.OldId, &m.Text,
+		)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, m)
+	}
+	return arr, rows.Err()
+}
+
+func (r *AssociateVehicleTypeRepo) GetByAssociateIdAndVehicleTypeId(ctx context.Context, associateId uint64, vehicleTypeId uint64) (*models.AssociateVehicleType, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	m := new(models.AssociateVehicleType)
+
+	query := `
+    SELECT
+        id, uuid, tenant_id, associate_id, vehicle_type_id, old_id
+    FROM
+        associate_vehicle_types
+    WHERE
+        associate_id = $1 AND vehicle_type_id = $2
+    LIMIT 1`
+	err := r.db.QueryRowContext(ctx, query, associateId, vehicleTypeId).Scan(
+		&m.Id, &m.Uuid, &m.TenantId, &m.AssociateId, &m.VehicleTypeId, &m.OldId,
+	)
+	if err != nil {
+		// CASE 1 OF 2: Cannot find record.
+		if err == sql.ErrNoRows {
+			return nil, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (r *AssociateVehicleTypeRepo) DeleteById(ctx context.Context, id uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    DELETE FROM
+        associate_vehicle_types
+    WHERE
+        id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
@@ -10,7 +10,7 @@ import (
 )
 
 type BulletinBoardItemRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewBulletinBoardItemRepo(db *sql.DB) *BulletinBoardItemRepo {
@@ -19,6 +19,14 @@ func NewBulletinBoardItemRepo(db *sql.DB) *BulletinBoardItemRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *BulletinBoardItemRepo) WithTx(tx *sql.Tx) *BulletinBoardItemRepo {
+	return &BulletinBoardItemRepo{
+		db: tx,
+	}
+}
+
 func (r *BulletinBoardItemRepo) Insert(ctx context.Context, m *models.BulletinBoardItem) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
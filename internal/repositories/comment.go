@@ -9,7 +9,7 @@ import (
 )
 
 type CommentRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewCommentRepo(db *sql.DB) *CommentRepo {
@@ -18,6 +18,14 @@ func NewCommentRepo(db *sql.DB) *CommentRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *CommentRepo) WithTx(tx *sql.Tx) *CommentRepo {
+	return &CommentRepo{
+		db: tx,
+	}
+}
+
 func (r *CommentRepo) Insert(ctx context.Context, m *models.Comment) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
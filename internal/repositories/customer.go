@@ -9,7 +9,7 @@ import (
 )
 
 type CustomerRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewCustomerRepo(db *sql.DB) *CustomerRepo {
@@ -18,6 +18,14 @@ func NewCustomerRepo(db *sql.DB) *CustomerRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *CustomerRepo) WithTx(tx *sql.Tx) *CustomerRepo {
+	return &CustomerRepo{
+		db: tx,
+	}
+}
+
 func (r *CustomerRepo) Insert(ctx context.Context, m *models.Customer) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -212,3 +220,37 @@ func (r *CustomerRepo) InsertOrUpdateById(ctx context.Context, m *models.Custome
 	}
 	return r.UpdateById(ctx, m)
 }
+
+// Function returns true if a customer in the tenant already uses the email
+// or the telephone. The telephone is compared by digits only so formatting
+// differences like "(519) 555-1234" and "519-555-1234" still match.
+func (r *CustomerRepo) CheckIfExistsByEmailOrTelephone(ctx context.Context, tid uint64, email string, telephone string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var exists bool
+
+	query := `
+    SELECT
+        1
+    FROM
+        customers
+    WHERE
+        tenant_id = $1
+    AND (
+        ($2 <> '' AND LOWER(email) = LOWER($2))
+    OR
+        ($3 <> '' AND REGEXP_REPLACE(telephone, '[^0-9]', '', 'g') = $3)
+    )
+    LIMIT 1`
+	err := r.db.QueryRowContext(ctx, query, tid, email, telephone).Scan(&exists)
+	if err != nil {
+		// CASE 1 OF 2: Cannot find record with that email or telephone.
+		if err == sql.ErrNoRows {
+			return false, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return false, err
+		}
+	}
+	return exists, nil
+}
@@ -9,7 +9,7 @@ import (
 )
 
 type CustomerCommentRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewCustomerCommentRepo(db *sql.DB) *CustomerCommentRepo {
@@ -18,6 +18,14 @@ func NewCustomerCommentRepo(db *sql.DB) *CustomerCommentRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *CustomerCommentRepo) WithTx(tx *sql.Tx) *CustomerCommentRepo {
+	return &CustomerCommentRepo{
+		db: tx,
+	}
+}
+
 func (r *CustomerCommentRepo) Insert(ctx context.Context, m *models.CustomerComment) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -11,7 +11,7 @@ import (
 )
 
 type CustomerTagRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewCustomerTagRepo(db *sql.DB) *CustomerTagRepo {
@@ -20,6 +20,14 @@ func NewCustomerTagRepo(db *sql.DB) *CustomerTagRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *CustomerTagRepo) WithTx(tx *sql.Tx) *CustomerTagRepo {
+	return &CustomerTagRepo{
+		db: tx,
+	}
+}
+
 func (r *CustomerTagRepo) Insert(ctx context.Context, m *models.CustomerTag) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+)
+
+// dbtx is the subset of methods which both `*sql.DB` and `*sql.Tx` share so
+// our repositories can run either directly against the database or inside
+// of a transaction.
+type dbtx interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Function runs the function inside of a new transaction which gets
+// committed if no error was returned, if the repository is already inside
+// of a transaction then the function simply joins it.
+func runInTx(ctx context.Context, db dbtx, fn func(tx dbtx) error) error {
+	sqlDB, ok := db.(*sql.DB)
+	if !ok {
+		return fn(db)
+	}
+	tx, err := sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+type ETLCheckpointRepo struct {
+	db dbtx
+}
+
+func NewETLCheckpointRepo(db *sql.DB) *ETLCheckpointRepo {
+	return &ETLCheckpointRepo{
+		db: db,
+	}
+}
+
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *ETLCheckpointRepo) WithTx(tx *sql.Tx) *ETLCheckpointRepo {
+	return &ETLCheckpointRepo{
+		db: tx,
+	}
+}
+
+func (r *ETLCheckpointRepo) GetBySchemaNameAndStep(ctx context.Context, schemaName string, step string) (*models.ETLCheckpoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	m := new(models.ETLCheckpoint)
+
+	query := `
+    SELECT
+        id, schema_name, step, last_old_id, imported_rows, failed_rows,
+		failed_old_ids, is_completed, created_time, last_modified_time
+    FROM
+        etl_checkpoints
+    WHERE
+        schema_name = $1
+	AND
+	    step = $2`
+	var failedOldIds []int64
+	err := r.db.QueryRowContext(ctx, query, schemaName, step).Scan(
+		&m.Id, &m.SchemaName, &m.Step, &m.LastOldId, &m.ImportedRows, &m.FailedRows,
+		pq.Array(&failedOldIds), &m.IsCompleted, &m.CreatedTime, &m.LastModifiedTime,
+	)
+	if err != nil {
+		// CASE 1 OF 2: Cannot find record with that schema and step.
+		if err == sql.ErrNoRows {
+			return nil, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return nil, err
+		}
+	}
+	for _, oldId := range failedOldIds {
+		m.FailedOldIds = append(m.FailedOldIds, uint64(oldId))
+	}
+	return m, nil
+}
+
+func (r *ETLCheckpointRepo) InsertOrUpdateBySchemaNameAndStep(ctx context.Context, m *models.ETLCheckpoint) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    INSERT INTO etl_checkpoints (
+        schema_name, step, last_old_id, imported_rows, failed_rows,
+		failed_old_ids, is_completed, created_time, last_modified_time
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6, $7, $8, $9
+    ) ON CONFLICT (schema_name, step) DO UPDATE SET
+        last_old_id = EXCLUDED.last_old_id,
+		imported_rows = EXCLUDED.imported_rows,
+		failed_rows = EXCLUDED.failed_rows,
+		failed_old_ids = EXCLUDED.failed_old_ids,
+		is_completed = EXCLUDED.is_completed,
+		last_modified_time = EXCLUDED.last_modified_time`
+	failedOldIds := make([]int64, len(m.FailedOldIds))
+	for i, oldId := range m.FailedOldIds {
+		failedOldIds[i] = int64(oldId)
+	}
+	_, err := r.db.ExecContext(
+		ctx, query,
+		m.SchemaName, m.Step, m.LastOldId, m.ImportedRows, m.FailedRows,
+		pq.Array(failedOldIds), m.IsCompleted, m.CreatedTime, m.LastModifiedTime,
+	)
+	return err
+}
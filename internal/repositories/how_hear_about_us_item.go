@@ -9,7 +9,7 @@ import (
 )
 
 type HowHearAboutUsItemRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewHowHearAboutUsItemRepo(db *sql.DB) *HowHearAboutUsItemRepo {
@@ -18,6 +18,14 @@ func NewHowHearAboutUsItemRepo(db *sql.DB) *HowHearAboutUsItemRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *HowHearAboutUsItemRepo) WithTx(tx *sql.Tx) *HowHearAboutUsItemRepo {
+	return &HowHearAboutUsItemRepo{
+		db: tx,
+	}
+}
+
 func (r *HowHearAboutUsItemRepo) Insert(ctx context.Context, m *models.HowHearAboutUsItem) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -9,7 +9,7 @@ import (
 )
 
 type InsuranceRequirementRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewInsuranceRequirementRepo(db *sql.DB) *InsuranceRequirementRepo {
@@ -18,15 +18,23 @@ func NewInsuranceRequirementRepo(db *sql.DB) *InsuranceRequirementRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *InsuranceRequirementRepo) WithTx(tx *sql.Tx) *InsuranceRequirementRepo {
+	return &InsuranceRequirementRepo{
+		db: tx,
+	}
+}
+
 func (r *InsuranceRequirementRepo) Insert(ctx context.Context, m *models.InsuranceRequirement) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	query := `
     INSERT INTO insurance_requirements (
-        uuid, tenant_id, text, description, state, old_id
+        uuid, tenant_id, text, description, state, old_id, associate_date_type_of
     ) VALUES (
-        $1, $2, $3, $4, $5, $6
+        $1, $2, $3, $4, $5, $6, $7
     )`
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
@@ -36,7 +44,7 @@ func (r *InsuranceRequirementRepo) Insert(ctx context.Context, m *models.Insuran
 
 	_, err = stmt.ExecContext(
 		ctx,
-		m.Uuid, m.TenantId, m.Text, m.Description, m.State, m.OldId,
+		m.Uuid, m.TenantId, m.Text, m.Description, m.State, m.OldId, m.AssociateDateTypeOf,
 	)
 	return err
 }
@@ -49,9 +57,9 @@ func (r *InsuranceRequirementRepo) UpdateById(ctx context.Context, m *models.Ins
     UPDATE
         insurance_requirements
     SET
-        tenant_id = $1, text = $2, description = $3, state = $4
+        tenant_id = $1, text = $2, description = $3, state = $4, associate_date_type_of = $5
     WHERE
-        id = $5`
+        id = $6`
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return err
@@ -60,7 +68,7 @@ func (r *InsuranceRequirementRepo) UpdateById(ctx context.Context, m *models.Ins
 
 	_, err = stmt.ExecContext(
 		ctx,
-		m.TenantId, m.Text, m.Description, m.State, m.Id,
+		m.TenantId, m.Text, m.Description, m.State, m.AssociateDateTypeOf, m.Id,
 	)
 	return err
 }
@@ -73,13 +81,13 @@ func (r *InsuranceRequirementRepo) GetById(ctx context.Context, id uint64) (*mod
 
 	query := `
     SELECT
-        id, uuid, tenant_id, text, description, state
+        id, uuid, tenant_id, text, description, state, associate_date_type_of
 	FROM
         insurance_requirements
     WHERE
         id = $1`
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&m.Id, &m.Uuid, &m.TenantId, &m.Text, &m.Description, &m.State,
+		&m.Id, &m.Uuid, &m.TenantId, &m.Text, &m.Description, &m.State, &m.AssociateDateTypeOf,
 	)
 	if err != nil {
 		// CASE 1 OF 2: Cannot find record with that email.
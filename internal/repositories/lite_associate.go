@@ -11,7 +11,7 @@ import (
 )
 
 type LiteAssociateRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewLiteAssociateRepo(db *sql.DB) *LiteAssociateRepo {
@@ -20,6 +20,14 @@ func NewLiteAssociateRepo(db *sql.DB) *LiteAssociateRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *LiteAssociateRepo) WithTx(tx *sql.Tx) *LiteAssociateRepo {
+	return &LiteAssociateRepo{
+		db: tx,
+	}
+}
+
 func (s *LiteAssociateRepo) queryRowsWithFilter(ctx context.Context, query string, f *models.LiteAssociateFilter) (*sql.Rows, error) {
 	// Array will hold all the unique values we want to add into the query.
 	var filterValues []interface{}
@@ -57,11 +65,16 @@ func (s *LiteAssociateRepo) queryRowsWithFilter(ctx context.Context, query strin
 	// The following code will add our pagination.
 	//
 
+	// DEVELOPERS NOTE:
+	// A zero `Limit` means we want every matching row, this is used when
+	// exporting the list as a spreadsheet.
 	query += ` ORDER BY ` + f.SortField + ` ` + f.SortOrder
-	filterValues = append(filterValues, f.Limit)
-	query += ` LIMIT $` + strconv.Itoa(len(filterValues))
-	filterValues = append(filterValues, f.Offset)
-	query += ` OFFSET $` + strconv.Itoa(len(filterValues))
+	if f.Limit > 0 {
+		filterValues = append(filterValues, f.Limit)
+		query += ` LIMIT $` + strconv.Itoa(len(filterValues))
+		filterValues = append(filterValues, f.Offset)
+		query += ` OFFSET $` + strconv.Itoa(len(filterValues))
+	}
 
 	//
 	// Execute our custom built SQL query to the database.
@@ -171,3 +184,56 @@ func (s *LiteAssociateRepo) CountByFilter(ctx context.Context, f *models.LiteAss
 	// log.Println("QUERY:", query)
 	return count, err
 }
+
+func (s *LiteAssociateRepo) StreamByFilter(ctx context.Context, filter *models.LiteAssociateFilter, fn func(m *models.LiteAssociate) error) error {
+	// DEVELOPERS NOTE:
+	// Streaming is used by the export functionality which can iterate over
+	// the entire table so we allow for a longer timeout.
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	querySelect := `
+    SELECT
+        id,
+		tenant_id,
+		state,
+		given_name,
+		last_name,
+		telephone,
+		telephone_type_of,
+		telephone_extension,
+		email,
+		join_date
+    FROM
+        associates
+    `
+
+	rows, err := s.queryRowsWithFilter(ctx, querySelect, filter)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		m := new(models.LiteAssociate)
+		err := rows.Scan(
+			&m.Id,
+			&m.TenantId,
+			&m.State,
+			&m.GivenName,
+			&m.LastName,
+			&m.Telephone,
+			&m.TelephoneTypeOf,
+			&m.TelephoneExtension,
+			&m.Email,
+			&m.JoinDate,
+		)
+		if err != nil {
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -11,7 +11,7 @@ import (
 )
 
 type LiteAssociateAwayLogRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewLiteAssociateAwayLogRepo(db *sql.DB) *LiteAssociateAwayLogRepo {
@@ -20,6 +20,14 @@ func NewLiteAssociateAwayLogRepo(db *sql.DB) *LiteAssociateAwayLogRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *LiteAssociateAwayLogRepo) WithTx(tx *sql.Tx) *LiteAssociateAwayLogRepo {
+	return &LiteAssociateAwayLogRepo{
+		db: tx,
+	}
+}
+
 func (s *LiteAssociateAwayLogRepo) queryRowsWithFilter(ctx context.Context, query string, f *models.LiteAssociateAwayLogFilter) (*sql.Rows, error) {
 	// Array will hold all the unique values we want to add into the query.
 	var filterValues []interface{}
@@ -11,7 +11,7 @@ import (
 )
 
 type LiteBulletinBoardItemRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewLiteBulletinBoardItemRepo(db *sql.DB) *LiteBulletinBoardItemRepo {
@@ -20,6 +20,14 @@ func NewLiteBulletinBoardItemRepo(db *sql.DB) *LiteBulletinBoardItemRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *LiteBulletinBoardItemRepo) WithTx(tx *sql.Tx) *LiteBulletinBoardItemRepo {
+	return &LiteBulletinBoardItemRepo{
+		db: tx,
+	}
+}
+
 func (s *LiteBulletinBoardItemRepo) queryRowsWithFilter(ctx context.Context, query string, f *models.LiteBulletinBoardItemFilter) (*sql.Rows, error) {
 	// Array will hold all the unique values we want to add into the query.
 	var filterValues []interface{}
@@ -11,7 +11,7 @@ import (
 )
 
 type LiteCustomerRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewLiteCustomerRepo(db *sql.DB) *LiteCustomerRepo {
@@ -20,6 +20,14 @@ func NewLiteCustomerRepo(db *sql.DB) *LiteCustomerRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *LiteCustomerRepo) WithTx(tx *sql.Tx) *LiteCustomerRepo {
+	return &LiteCustomerRepo{
+		db: tx,
+	}
+}
+
 func (s *LiteCustomerRepo) queryRowsWithFilter(ctx context.Context, query string, f *models.LiteCustomerFilter) (*sql.Rows, error) {
 	// Array will hold all the unique values we want to add into the query.
 	var filterValues []interface{}
@@ -57,11 +65,16 @@ func (s *LiteCustomerRepo) queryRowsWithFilter(ctx context.Context, query string
 	// The following code will add our pagination.
 	//
 
+	// DEVELOPERS NOTE:
+	// A zero `Limit` means we want every matching row, this is used when
+	// exporting the list as a spreadsheet.
 	query += ` ORDER BY ` + f.SortField + ` ` + f.SortOrder
-	filterValues = append(filterValues, f.Limit)
-	query += ` LIMIT $` + strconv.Itoa(len(filterValues))
-	filterValues = append(filterValues, f.Offset)
-	query += ` OFFSET $` + strconv.Itoa(len(filterValues))
+	if f.Limit > 0 {
+		filterValues = append(filterValues, f.Limit)
+		query += ` LIMIT $` + strconv.Itoa(len(filterValues))
+		filterValues = append(filterValues, f.Offset)
+		query += ` OFFSET $` + strconv.Itoa(len(filterValues))
+	}
 
 	//
 	// Execute our custom built SQL query to the database.
@@ -181,3 +194,54 @@ func (s *LiteCustomerRepo) CountByFilter(ctx context.Context, f *models.LiteCust
 	// Return our values.
 	return count, err
 }
+
+func (s *LiteCustomerRepo) StreamByFilter(ctx context.Context, filter *models.LiteCustomerFilter, fn func(m *models.LiteCustomer) error) error {
+	// DEVELOPERS NOTE:
+	// Streaming is used by the export functionality which can iterate over
+	// the entire table so we allow for a longer timeout.
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	querySelect := `
+    SELECT
+        id,
+		tenant_id,
+		state,
+		given_name,
+		last_name,
+		telephone,
+		email,
+		join_date,
+		type_of
+    FROM
+        customers
+    `
+
+	rows, err := s.queryRowsWithFilter(ctx, querySelect, filter)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		m := new(models.LiteCustomer)
+		err := rows.Scan(
+			&m.Id,
+			&m.TenantId,
+			&m.State,
+			&m.GivenName,
+			&m.LastName,
+			&m.Telephone,
+			&m.Email,
+			&m.JoinDate,
+			&m.TypeOf,
+		)
+		if err != nil {
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
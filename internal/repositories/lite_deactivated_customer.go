@@ -11,7 +11,7 @@ import (
 )
 
 type LiteDeactivatedCustomerRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewLiteDeactivatedCustomerRepo(db *sql.DB) *LiteDeactivatedCustomerRepo {
@@ -20,6 +20,14 @@ func NewLiteDeactivatedCustomerRepo(db *sql.DB) *LiteDeactivatedCustomerRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *LiteDeactivatedCustomerRepo) WithTx(tx *sql.Tx) *LiteDeactivatedCustomerRepo {
+	return &LiteDeactivatedCustomerRepo{
+		db: tx,
+	}
+}
+
 func (s *LiteDeactivatedCustomerRepo) queryRowsWithFilter(ctx context.Context, query string, f *models.LiteDeactivatedCustomerFilter) (*sql.Rows, error) {
 	// Array will hold all the unique values we want to add into the query.
 	var filterValues []interface{}
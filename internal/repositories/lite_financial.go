@@ -11,7 +11,7 @@ import (
 )
 
 type LiteFinancialRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewLiteFinancialRepo(db *sql.DB) *LiteFinancialRepo {
@@ -20,7 +20,18 @@ func NewLiteFinancialRepo(db *sql.DB) *LiteFinancialRepo {
 	}
 }
 
-func (s *LiteFinancialRepo) queryRowsWithFilter(ctx context.Context, query string, f *models.LiteFinancialFilter) (*sql.Rows, error) {
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *LiteFinancialRepo) WithTx(tx *sql.Tx) *LiteFinancialRepo {
+	return &LiteFinancialRepo{
+		db: tx,
+	}
+}
+
+// Function returns the `WHERE` clause of the filter along with the values
+// of its placeholders, it is shared by the listing, counting and totals so
+// they always agree with each other.
+func (s *LiteFinancialRepo) whereWithFilter(f *models.LiteFinancialFilter) (string, []interface{}) {
 	// Array will hold all the unique values we want to add into the query.
 	var filterValues []interface{}
 
@@ -28,7 +39,7 @@ func (s *LiteFinancialRepo) queryRowsWithFilter(ctx context.Context, query strin
 	// by setting the `tenant_id` placeholder and then append our value to
 	// the array.
 	filterValues = append(filterValues, f.TenantId)
-	query += ` WHERE tenant_id = $` + strconv.Itoa(len(filterValues))
+	query := ` WHERE tenant_id = $` + strconv.Itoa(len(filterValues))
 
 	//
 	// The following code will add our OPTIONAL filters
@@ -40,7 +51,7 @@ func (s *LiteFinancialRepo) queryRowsWithFilter(ctx context.Context, query strin
 	}
 
 	if !f.AssociateName.IsZero() {
-		filterValues = append(filterValues, f.AssociateLexicalName)
+		filterValues = append(filterValues, f.AssociateName)
 		query += ` AND associate_name = $` + strconv.Itoa(len(filterValues))
 	}
 
@@ -50,7 +61,7 @@ func (s *LiteFinancialRepo) queryRowsWithFilter(ctx context.Context, query strin
 	}
 
 	if !f.CustomerName.IsZero() {
-		filterValues = append(filterValues, f.CustomerLexicalName)
+		filterValues = append(filterValues, f.CustomerName)
 		query += ` AND customer_name = $` + strconv.Itoa(len(filterValues))
 	}
 
@@ -59,6 +70,48 @@ func (s *LiteFinancialRepo) queryRowsWithFilter(ctx context.Context, query strin
 		query += ` AND customer_lexical_name = $` + strconv.Itoa(len(filterValues))
 	}
 
+	if f.AssociateId.Valid {
+		filterValues = append(filterValues, f.AssociateId)
+		query += ` AND associate_id = $` + strconv.Itoa(len(filterValues))
+	}
+
+	if f.InvoicePaidTo.Valid {
+		filterValues = append(filterValues, f.InvoicePaidTo)
+		query += ` AND invoice_paid_to = $` + strconv.Itoa(len(filterValues))
+	}
+
+	if f.InvoiceDateGTE.Valid {
+		filterValues = append(filterValues, f.InvoiceDateGTE)
+		query += ` AND invoice_date >= $` + strconv.Itoa(len(filterValues))
+	}
+
+	if f.InvoiceDateLT.Valid {
+		filterValues = append(filterValues, f.InvoiceDateLT)
+		query += ` AND invoice_date < $` + strconv.Itoa(len(filterValues))
+	}
+
+	if f.ServiceFeePaymentDateIsNull.Valid {
+		if f.ServiceFeePaymentDateIsNull.Bool {
+			query += ` AND invoice_service_fee_payment_date IS NULL`
+		} else {
+			query += ` AND invoice_service_fee_payment_date IS NOT NULL`
+		}
+	}
+
+	if f.ServiceFeePaymentDateGTE.Valid {
+		filterValues = append(filterValues, f.ServiceFeePaymentDateGTE)
+		query += ` AND invoice_service_fee_payment_date >= $` + strconv.Itoa(len(filterValues))
+	}
+
+	if f.ServiceFeePaymentDateLT.Valid {
+		filterValues = append(filterValues, f.ServiceFeePaymentDateLT)
+		query += ` AND invoice_service_fee_payment_date < $` + strconv.Itoa(len(filterValues))
+	}
+
+	if f.HasBalanceOwing {
+		query += ` AND invoice_balance_owing_amount > 0`
+	}
+
 	if !f.Search.IsZero() {
 		log.Fatal("TODO: PLEASE IMPLEMENT")
 		// filterValues = append(filterValues, f.Search)
@@ -78,15 +131,27 @@ func (s *LiteFinancialRepo) queryRowsWithFilter(ctx context.Context, query strin
 		query += ` )`
 	}
 
+	return query, filterValues
+}
+
+func (s *LiteFinancialRepo) queryRowsWithFilter(ctx context.Context, query string, f *models.LiteFinancialFilter) (*sql.Rows, error) {
+	where, filterValues := s.whereWithFilter(f)
+	query += where
+
 	//
 	// The following code will add our pagination.
 	//
 
+	// DEVELOPERS NOTE:
+	// A zero `Limit` means we want every matching row, this is used when
+	// exporting the list as a spreadsheet.
 	query += ` ORDER BY ` + f.SortField + ` ` + f.SortOrder
-	filterValues = append(filterValues, f.Limit)
-	query += ` LIMIT $` + strconv.Itoa(len(filterValues))
-	filterValues = append(filterValues, f.Offset)
-	query += ` OFFSET $` + strconv.Itoa(len(filterValues))
+	if f.Limit > 0 {
+		filterValues = append(filterValues, f.Limit)
+		query += ` LIMIT $` + strconv.Itoa(len(filterValues))
+		filterValues = append(filterValues, f.Offset)
+		query += ` OFFSET $` + strconv.Itoa(len(filterValues))
+	}
 
 	//
 	// Execute our custom built SQL query to the database.
@@ -100,11 +165,7 @@ func (s *LiteFinancialRepo) queryRowsWithFilter(ctx context.Context, query strin
 	return s.db.QueryContext(ctx, query, filterValues...)
 }
 
-func (s *LiteFinancialRepo) ListByFilter(ctx context.Context, filter *models.LiteFinancialFilter) ([]*models.LiteFinancial, error) {
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
-	querySelect := `
+const liteFinancialSelect = `
     SELECT
         id,
 		tenant_id,
@@ -114,12 +175,44 @@ func (s *LiteFinancialRepo) ListByFilter(ctx context.Context, filter *models.Lit
 		associate_id,
 		associate_name,
 		invoice_service_fee_payment_date,
-		type_of
+		type_of,
+		currency,
+		invoice_date,
+		invoice_paid_to,
+		invoice_service_fee_amount,
+		invoice_actual_service_fee_amount_paid,
+		invoice_balance_owing_amount
     FROM
         work_orders
     `
 
-	rows, err := s.queryRowsWithFilter(ctx, querySelect, filter)
+func scanLiteFinancial(rows *sql.Rows) (*models.LiteFinancial, error) {
+	m := new(models.LiteFinancial)
+	err := rows.Scan(
+		&m.Id,
+		&m.TenantId,
+		&m.State,
+		&m.CustomerId,
+		&m.CustomerName,
+		&m.AssociateId,
+		&m.AssociateName,
+		&m.InvoiceServiceFeePaymentDate,
+		&m.TypeOf,
+		&m.Currency,
+		&m.InvoiceDate,
+		&m.InvoicePaidTo,
+		&m.InvoiceServiceFeeAmount,
+		&m.InvoiceActualServiceFeeAmountPaid,
+		&m.InvoiceBalanceOwingAmount,
+	)
+	return m, err
+}
+
+func (s *LiteFinancialRepo) ListByFilter(ctx context.Context, filter *models.LiteFinancialFilter) ([]*models.LiteFinancial, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := s.queryRowsWithFilter(ctx, liteFinancialSelect, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -127,18 +220,7 @@ func (s *LiteFinancialRepo) ListByFilter(ctx context.Context, filter *models.Lit
 	var arr []*models.LiteFinancial
 	defer rows.Close()
 	for rows.Next() {
-		m := new(models.LiteFinancial)
-		err := rows.Scan(
-			&m.Id,
-			&m.TenantId,
-			&m.State,
-			&m.CustomerId,
-			&m.CustomerName,
-			&m.AssociateId,
-			&m.AssociateName,
-			&m.InvoiceServiceFeePaymentDate,
-			&m.TypeOf,
-		)
+		m, err := scanLiteFinancial(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -158,53 +240,66 @@ func (s *LiteFinancialRepo) CountByFilter(ctx context.Context, f *models.LiteFin
 	// The result we are looking for.
 	var count uint64
 
-	// Array will hold all the unique values we want to add into the query.
-	var filterValues []interface{}
-
-	// The SQL query statement we will be calling in the database, start
-	// by setting the `tenant_id` placeholder and then append our value to
-	// the array.
-	filterValues = append(filterValues, f.TenantId)
+	where, filterValues := s.whereWithFilter(f)
 	query := `
 	SELECT COUNT(id) FROM
-	    work_orders
-	WHERE
-		tenant_id = $` + strconv.Itoa(len(filterValues))
+	    work_orders` + where
 
 	//
-	// The following code will add our filters
+	// Execute our custom built SQL query to the database.
 	//
 
-	if !f.LastModifiedById.IsZero() {
-		filterValues = append(filterValues, f.LastModifiedById)
-		query += ` AND last_modified_by_id = $` + strconv.Itoa(len(filterValues))
-	}
+	err := s.db.QueryRowContext(ctx, query, filterValues...).Scan(&count)
 
-	if !f.Search.IsZero() {
-		log.Fatal("TODO: PLEASE IMPLEMENT")
-		// filterValues = append(filterValues, f.Search)
-		// query += `AND state = $` + strconv.Itoa(len(filterValues))
-	}
+	// Return our values.
+	return count, err
+}
 
-	if len(f.States) > 0 {
-		query += ` AND (`
-		for i, v := range f.States {
-			s := strconv.Itoa(int(v))
-			filterValues = append(filterValues, s)
-			if i != 0 {
-				query += ` OR`
-			}
-			query += ` state = $` + strconv.Itoa(len(filterValues))
-		}
-		query += ` )`
+func (s *LiteFinancialRepo) SumByFilter(ctx context.Context, f *models.LiteFinancialFilter) (*models.LiteFinancialTotals, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	m := new(models.LiteFinancialTotals)
+
+	where, filterValues := s.whereWithFilter(f)
+	query := `
+	SELECT
+	    COALESCE(SUM(invoice_service_fee_amount), 0),
+	    COALESCE(SUM(invoice_actual_service_fee_amount_paid), 0),
+	    COALESCE(SUM(invoice_balance_owing_amount), 0)
+	FROM
+	    work_orders` + where
+
+	err := s.db.QueryRowContext(ctx, query, filterValues...).Scan(
+		&m.ServiceFeeAmount, &m.ServiceFeeAmountPaid, &m.BalanceOwingAmount,
+	)
+	if err != nil {
+		return nil, err
 	}
+	return m, nil
+}
 
-	//
-	// Execute our custom built SQL query to the database.
-	//
+func (s *LiteFinancialRepo) StreamByFilter(ctx context.Context, filter *models.LiteFinancialFilter, fn func(m *models.LiteFinancial) error) error {
+	// DEVELOPERS NOTE:
+	// Streaming is used by the export functionality which can iterate over
+	// the entire table so we allow for a longer timeout.
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
 
-	err := s.db.QueryRowContext(ctx, query, filterValues...).Scan(&count)
+	rows, err := s.queryRowsWithFilter(ctx, liteFinancialSelect, filter)
+	if err != nil {
+		return err
+	}
 
-	// Return our values.
-	return count, err
+	defer rows.Close()
+	for rows.Next() {
+		m, err := scanLiteFinancial(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
 }
@@ -11,7 +11,7 @@ import (
 )
 
 type LiteHowHearAboutUsItemRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewLiteHowHearAboutUsItemRepo(db *sql.DB) *LiteHowHearAboutUsItemRepo {
@@ -20,6 +20,14 @@ func NewLiteHowHearAboutUsItemRepo(db *sql.DB) *LiteHowHearAboutUsItemRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *LiteHowHearAboutUsItemRepo) WithTx(tx *sql.Tx) *LiteHowHearAboutUsItemRepo {
+	return &LiteHowHearAboutUsItemRepo{
+		db: tx,
+	}
+}
+
 func (s *LiteHowHearAboutUsItemRepo) queryRowsWithFilter(ctx context.Context, query string, f *models.LiteHowHearAboutUsItemFilter) (*sql.Rows, error) {
 	// Array will hold all the unique values we want to add into the query.
 	var filterValues []interface{}
@@ -11,7 +11,7 @@ import (
 )
 
 type LiteInsuranceRequirementRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewLiteInsuranceRequirementRepo(db *sql.DB) *LiteInsuranceRequirementRepo {
@@ -20,6 +20,14 @@ func NewLiteInsuranceRequirementRepo(db *sql.DB) *LiteInsuranceRequirementRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *LiteInsuranceRequirementRepo) WithTx(tx *sql.Tx) *LiteInsuranceRequirementRepo {
+	return &LiteInsuranceRequirementRepo{
+		db: tx,
+	}
+}
+
 func (s *LiteInsuranceRequirementRepo) queryRowsWithFilter(ctx context.Context, query string, f *models.LiteInsuranceRequirementFilter) (*sql.Rows, error) {
 	// Array will hold all the unique values we want to add into the query.
 	var filterValues []interface{}
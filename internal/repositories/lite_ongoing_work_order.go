@@ -11,7 +11,7 @@ import (
 )
 
 type LiteOngoingWorkOrderRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewLiteOngoingWorkOrderRepo(db *sql.DB) *LiteOngoingWorkOrderRepo {
@@ -20,6 +20,14 @@ func NewLiteOngoingWorkOrderRepo(db *sql.DB) *LiteOngoingWorkOrderRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *LiteOngoingWorkOrderRepo) WithTx(tx *sql.Tx) *LiteOngoingWorkOrderRepo {
+	return &LiteOngoingWorkOrderRepo{
+		db: tx,
+	}
+}
+
 func (s *LiteOngoingWorkOrderRepo) queryRowsWithFilter(ctx context.Context, query string, f *models.LiteOngoingWorkOrderFilter) (*sql.Rows, error) {
 	// Array will hold all the unique values we want to add into the query.
 	var filterValues []interface{}
@@ -11,7 +11,7 @@ import (
 )
 
 type LitePartnerRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewLitePartnerRepo(db *sql.DB) *LitePartnerRepo {
@@ -20,6 +20,14 @@ func NewLitePartnerRepo(db *sql.DB) *LitePartnerRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *LitePartnerRepo) WithTx(tx *sql.Tx) *LitePartnerRepo {
+	return &LitePartnerRepo{
+		db: tx,
+	}
+}
+
 func (s *LitePartnerRepo) queryRowsWithFilter(ctx context.Context, query string, f *models.LitePartnerFilter) (*sql.Rows, error) {
 	// Array will hold all the unique values we want to add into the query.
 	var filterValues []interface{}
@@ -11,7 +11,7 @@ import (
 )
 
 type LiteSkillSetRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewLiteSkillSetRepo(db *sql.DB) *LiteSkillSetRepo {
@@ -20,6 +20,14 @@ func NewLiteSkillSetRepo(db *sql.DB) *LiteSkillSetRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *LiteSkillSetRepo) WithTx(tx *sql.Tx) *LiteSkillSetRepo {
+	return &LiteSkillSetRepo{
+		db: tx,
+	}
+}
+
 func (s *LiteSkillSetRepo) queryRowsWithFilter(ctx context.Context, query string, f *models.LiteSkillSetFilter) (*sql.Rows, error) {
 	// Array will hold all the unique values we want to add into the query.
 	var filterValues []interface{}
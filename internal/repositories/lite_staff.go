@@ -11,7 +11,7 @@ import (
 )
 
 type LiteStaffRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewLiteStaffRepo(db *sql.DB) *LiteStaffRepo {
@@ -20,6 +20,14 @@ func NewLiteStaffRepo(db *sql.DB) *LiteStaffRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *LiteStaffRepo) WithTx(tx *sql.Tx) *LiteStaffRepo {
+	return &LiteStaffRepo{
+		db: tx,
+	}
+}
+
 func (s *LiteStaffRepo) queryRowsWithFilter(ctx context.Context, query string, f *models.LiteStaffFilter) (*sql.Rows, error) {
 	// Array will hold all the unique values we want to add into the query.
 	var filterValues []interface{}
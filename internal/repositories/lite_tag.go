@@ -11,7 +11,7 @@ import (
 )
 
 type LiteTagRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewLiteTagRepo(db *sql.DB) *LiteTagRepo {
@@ -20,6 +20,14 @@ func NewLiteTagRepo(db *sql.DB) *LiteTagRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *LiteTagRepo) WithTx(tx *sql.Tx) *LiteTagRepo {
+	return &LiteTagRepo{
+		db: tx,
+	}
+}
+
 func (s *LiteTagRepo) queryRowsWithFilter(ctx context.Context, query string, f *models.LiteTagFilter) (*sql.Rows, error) {
 	// Array will hold all the unique values we want to add into the query.
 	var filterValues []interface{}
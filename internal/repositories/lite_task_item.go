@@ -11,7 +11,7 @@ import (
 )
 
 type LiteTaskItemRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewLiteTaskItemRepo(db *sql.DB) *LiteTaskItemRepo {
@@ -20,6 +20,14 @@ func NewLiteTaskItemRepo(db *sql.DB) *LiteTaskItemRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *LiteTaskItemRepo) WithTx(tx *sql.Tx) *LiteTaskItemRepo {
+	return &LiteTaskItemRepo{
+		db: tx,
+	}
+}
+
 func (s *LiteTaskItemRepo) queryRowsWithFilter(ctx context.Context, query string, f *models.LiteTaskItemFilter) (*sql.Rows, error) {
 	// Array will hold all the unique values we want to add into the query.
 	var filterValues []interface{}
@@ -39,6 +47,21 @@ func (s *LiteTaskItemRepo) queryRowsWithFilter(ctx context.Context, query string
 		query += ` AND is_closed = $` + strconv.Itoa(len(filterValues))
 	}
 
+	if !f.IsOverdue.IsZero() {
+		filterValues = append(filterValues, f.IsOverdue.ValueOrZero())
+		query += ` AND is_overdue = $` + strconv.Itoa(len(filterValues))
+	}
+
+	if !f.EscalatedToId.IsZero() {
+		filterValues = append(filterValues, f.EscalatedToId.ValueOrZero())
+		query += ` AND escalated_to_id = $` + strconv.Itoa(len(filterValues))
+	}
+
+	if !f.AssociateId.IsZero() {
+		filterValues = append(filterValues, f.AssociateId.ValueOrZero())
+		query += ` AND associate_id = $` + strconv.Itoa(len(filterValues))
+	}
+
 	if !f.Search.IsZero() {
 		log.Fatal("TODO: PLEASE IMPLEMENT")
 		// filterValues = append(filterValues, f.Search)
@@ -94,7 +117,9 @@ func (s *LiteTaskItemRepo) ListByFilter(ctx context.Context, filter *models.Lite
 		associate_id,
 		associate_name,
 		associate_lexical_name,
-		order_type_of
+		order_type_of,
+		is_overdue,
+		escalated_to_id
     FROM
         task_items
     `
@@ -121,6 +146,8 @@ func (s *LiteTaskItemRepo) ListByFilter(ctx context.Context, filter *models.Lite
 			&m.AssociateName,
 			&m.AssociateLexicalName,
 			&m.OrderTypeOf,
+			&m.IsOverdue,
+			&m.EscalatedToId,
 		)
 		if err != nil {
 			return nil, err
@@ -163,6 +190,21 @@ func (s *LiteTaskItemRepo) CountByFilter(ctx context.Context, f *models.LiteTask
 		query += ` AND is_closed = $` + strconv.Itoa(len(filterValues))
 	}
 
+	if !f.IsOverdue.IsZero() {
+		filterValues = append(filterValues, f.IsOverdue.ValueOrZero())
+		query += ` AND is_overdue = $` + strconv.Itoa(len(filterValues))
+	}
+
+	if !f.EscalatedToId.IsZero() {
+		filterValues = append(filterValues, f.EscalatedToId.ValueOrZero())
+		query += ` AND escalated_to_id = $` + strconv.Itoa(len(filterValues))
+	}
+
+	if !f.AssociateId.IsZero() {
+		filterValues = append(filterValues, f.AssociateId.ValueOrZero())
+		query += ` AND associate_id = $` + strconv.Itoa(len(filterValues))
+	}
+
 	if !f.Search.IsZero() {
 		log.Fatal("TODO: PLEASE IMPLEMENT")
 		// filterValues = append(filterValues, f.Search)
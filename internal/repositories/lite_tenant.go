@@ -11,7 +11,7 @@ import (
 )
 
 type LiteTenantRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewLiteTenantRepo(db *sql.DB) *LiteTenantRepo {
@@ -20,6 +20,14 @@ func NewLiteTenantRepo(db *sql.DB) *LiteTenantRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *LiteTenantRepo) WithTx(tx *sql.Tx) *LiteTenantRepo {
+	return &LiteTenantRepo{
+		db: tx,
+	}
+}
+
 func (s *LiteTenantRepo) ListAllIds(ctx context.Context) ([]uint64, error) {
 	query := `SELECT id FROM tenants ORDER BY (id) ASC`
 
@@ -11,7 +11,7 @@ import (
 )
 
 type LiteVehicleTypeRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewLiteVehicleTypeRepo(db *sql.DB) *LiteVehicleTypeRepo {
@@ -20,6 +20,14 @@ func NewLiteVehicleTypeRepo(db *sql.DB) *LiteVehicleTypeRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *LiteVehicleTypeRepo) WithTx(tx *sql.Tx) *LiteVehicleTypeRepo {
+	return &LiteVehicleTypeRepo{
+		db: tx,
+	}
+}
+
 func (s *LiteVehicleTypeRepo) queryRowsWithFilter(ctx context.Context, query string, f *models.LiteVehicleTypeFilter) (*sql.Rows, error) {
 	// Array will hold all the unique values we want to add into the query.
 	var filterValues []interface{}
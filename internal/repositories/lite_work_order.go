@@ -11,7 +11,7 @@ import (
 )
 
 type LiteWorkOrderRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewLiteWorkOrderRepo(db *sql.DB) *LiteWorkOrderRepo {
@@ -20,6 +20,14 @@ func NewLiteWorkOrderRepo(db *sql.DB) *LiteWorkOrderRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *LiteWorkOrderRepo) WithTx(tx *sql.Tx) *LiteWorkOrderRepo {
+	return &LiteWorkOrderRepo{
+		db: tx,
+	}
+}
+
 func (s *LiteWorkOrderRepo) queryRowsWithFilter(ctx context.Context, query string, f *models.LiteWorkOrderFilter) (*sql.Rows, error) {
 	// Array will hold all the unique values we want to add into the query.
 	var filterValues []interface{}
@@ -39,6 +47,11 @@ func (s *LiteWorkOrderRepo) queryRowsWithFilter(ctx context.Context, query strin
 		query += ` AND last_modified_by_id = $` + strconv.Itoa(len(filterValues))
 	}
 
+	if !f.AssociateId.IsZero() {
+		filterValues = append(filterValues, f.AssociateId)
+		query += ` AND associate_id = $` + strconv.Itoa(len(filterValues))
+	}
+
 	if !f.AssociateName.IsZero() {
 		filterValues = append(filterValues, f.AssociateLexicalName)
 		query += ` AND associate_name = $` + strconv.Itoa(len(filterValues))
@@ -82,11 +95,16 @@ func (s *LiteWorkOrderRepo) queryRowsWithFilter(ctx context.Context, query strin
 	// The following code will add our pagination.
 	//
 
+	// DEVELOPERS NOTE:
+	// A zero `Limit` means we want every matching row, this is used when
+	// exporting the list as a spreadsheet.
 	query += ` ORDER BY ` + f.SortField + ` ` + f.SortOrder
-	filterValues = append(filterValues, f.Limit)
-	query += ` LIMIT $` + strconv.Itoa(len(filterValues))
-	filterValues = append(filterValues, f.Offset)
-	query += ` OFFSET $` + strconv.Itoa(len(filterValues))
+	if f.Limit > 0 {
+		filterValues = append(filterValues, f.Limit)
+		query += ` LIMIT $` + strconv.Itoa(len(filterValues))
+		filterValues = append(filterValues, f.Offset)
+		query += ` OFFSET $` + strconv.Itoa(len(filterValues))
+	}
 
 	//
 	// Execute our custom built SQL query to the database.
@@ -184,6 +202,11 @@ func (s *LiteWorkOrderRepo) CountByFilter(ctx context.Context, f *models.LiteWor
 		query += ` AND last_modified_by_id = $` + strconv.Itoa(len(filterValues))
 	}
 
+	if !f.AssociateId.IsZero() {
+		filterValues = append(filterValues, f.AssociateId)
+		query += ` AND associate_id = $` + strconv.Itoa(len(filterValues))
+	}
+
 	if !f.Search.IsZero() {
 		log.Fatal("TODO: PLEASE IMPLEMENT")
 		// filterValues = append(filterValues, f.Search)
@@ -212,3 +235,58 @@ func (s *LiteWorkOrderRepo) CountByFilter(ctx context.Context, f *models.LiteWor
 	// Return our values.
 	return count, err
 }
+
+func (s *LiteWorkOrderRepo) StreamByFilter(ctx context.Context, filter *models.LiteWorkOrderFilter, fn func(m *models.LiteWorkOrder) error) error {
+	// DEVELOPERS NOTE:
+	// Streaming is used by the export functionality which can iterate over
+	// the entire table so we allow for a longer timeout.
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	querySelect := `
+    SELECT
+        id,
+		tenant_id,
+		state,
+		customer_id,
+		customer_name,
+		associate_id,
+		associate_name,
+		assignment_date,
+		start_date,
+		type_of,
+		is_ongoing
+    FROM
+        work_orders
+    `
+
+	rows, err := s.queryRowsWithFilter(ctx, querySelect, filter)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		m := new(models.LiteWorkOrder)
+		err := rows.Scan(
+			&m.Id,
+			&m.TenantId,
+			&m.State,
+			&m.CustomerId,
+			&m.CustomerName,
+			&m.AssociateId,
+			&m.AssociateName,
+			&m.AssignmentDate,
+			&m.StartDate,
+			&m.TypeOf,
+			&m.IsOngoing,
+		)
+		if err != nil {
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -11,7 +11,7 @@ import (
 )
 
 type LiteWorkOrderServiceFeeRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewLiteWorkOrderServiceFeeRepo(db *sql.DB) *LiteWorkOrderServiceFeeRepo {
@@ -20,6 +20,14 @@ func NewLiteWorkOrderServiceFeeRepo(db *sql.DB) *LiteWorkOrderServiceFeeRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *LiteWorkOrderServiceFeeRepo) WithTx(tx *sql.Tx) *LiteWorkOrderServiceFeeRepo {
+	return &LiteWorkOrderServiceFeeRepo{
+		db: tx,
+	}
+}
+
 func (s *LiteWorkOrderServiceFeeRepo) queryRowsWithFilter(ctx context.Context, query string, f *models.LiteWorkOrderServiceFeeFilter) (*sql.Rows, error) {
 	// Array will hold all the unique values we want to add into the query.
 	var filterValues []interface{}
@@ -83,7 +91,7 @@ func (s *LiteWorkOrderServiceFeeRepo) ListByFilter(ctx context.Context, filter *
         id,
 		tenant_id,
 		title,
-		percentage,
+		` + workOrderServiceFeeEffectivePercentage + `,
 		description,
 		state
     FROM
@@ -5,11 +5,13 @@ import (
 	"database/sql"
 	"time"
 
+	null "gopkg.in/guregu/null.v4"
+
 	"github.com/over55/workery-server/internal/models"
 )
 
 type OngoingWorkOrderRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewOngoingWorkOrderRepo(db *sql.DB) *OngoingWorkOrderRepo {
@@ -18,6 +20,14 @@ func NewOngoingWorkOrderRepo(db *sql.DB) *OngoingWorkOrderRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *OngoingWorkOrderRepo) WithTx(tx *sql.Tx) *OngoingWorkOrderRepo {
+	return &OngoingWorkOrderRepo{
+		db: tx,
+	}
+}
+
 func (r *OngoingWorkOrderRepo) Insert(ctx context.Context, m *models.OngoingWorkOrder) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -40,18 +50,20 @@ func (r *OngoingWorkOrderRepo) Insert(ctx context.Context, m *models.OngoingWork
 		last_modified_time,
 		last_modified_by_id,
 		last_modified_by_name,
-		last_modified_from_ip, old_id
+		last_modified_from_ip, old_id,
+		description,
+		type_of,
+		is_home_support_service,
+		recurrence_rule,
+		recurrence_start_date,
+		recurrence_end_date,
+		generated_until
     ) VALUES (
-        $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18
-    )`
-	stmt, err := r.db.PrepareContext(ctx, query)
-	if err != nil {
-		return err
-	}
-	defer stmt.Close()
-
-	_, err = stmt.ExecContext(
-		ctx,
+        $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18,
+		$19, $20, $21, $22, $23, $24, $25
+    ) RETURNING id`
+	return r.db.QueryRowContext(
+		ctx, query,
 		m.Uuid,
 		m.TenantId,
 		m.CustomerId,
@@ -70,8 +82,14 @@ func (r *OngoingWorkOrderRepo) Insert(ctx context.Context, m *models.OngoingWork
 		m.LastModifiedByName,
 		m.LastModifiedFromIP,
 		m.OldId,
-	)
-	return err
+		m.Description,
+		m.TypeOf,
+		m.IsHomeSupportService,
+		m.RecurrenceRule,
+		m.RecurrenceStartDate,
+		m.RecurrenceEndDate,
+		m.GeneratedUntil,
+	).Scan(&m.Id)
 }
 
 func (r *OngoingWorkOrderRepo) UpdateById(ctx context.Context, m *models.OngoingWorkOrder) error {
@@ -89,9 +107,20 @@ func (r *OngoingWorkOrderRepo) UpdateById(ctx context.Context, m *models.Ongoing
 		last_modified_time = $5,
 		last_modified_by_id = $6,
 		last_modified_by_name = $7,
-		last_modified_from_ip = $8
+		last_modified_from_ip = $8,
+		customer_name = $9,
+		customer_lexical_name = $10,
+		associate_name = $11,
+		associate_lexical_name = $12,
+		description = $13,
+		type_of = $14,
+		is_home_support_service = $15,
+		recurrence_rule = $16,
+		recurrence_start_date = $17,
+		recurrence_end_date = $18,
+		generated_until = $19
     WHERE
-        id = $9`
+        id = $20`
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return err
@@ -108,6 +137,17 @@ func (r *OngoingWorkOrderRepo) UpdateById(ctx context.Context, m *models.Ongoing
 		m.LastModifiedById,
 		m.LastModifiedByName,
 		m.LastModifiedFromIP,
+		m.CustomerName,
+		m.CustomerLexicalName,
+		m.AssociateName,
+		m.AssociateLexicalName,
+		m.Description,
+		m.TypeOf,
+		m.IsHomeSupportService,
+		m.RecurrenceRule,
+		m.RecurrenceStartDate,
+		m.RecurrenceEndDate,
+		m.GeneratedUntil,
 		m.Id,
 	)
 	return err
@@ -138,7 +178,14 @@ func (r *OngoingWorkOrderRepo) GetById(ctx context.Context, id uint64) (*models.
 		last_modified_time,
 		last_modified_by_id,
 		last_modified_by_name,
-		last_modified_from_ip
+		last_modified_from_ip,
+		description,
+		type_of,
+		is_home_support_service,
+		recurrence_rule,
+		recurrence_start_date,
+		recurrence_end_date,
+		generated_until
 	FROM
         ongoing_work_orders
     WHERE
@@ -162,6 +209,13 @@ func (r *OngoingWorkOrderRepo) GetById(ctx context.Context, id uint64) (*models.
 		&m.LastModifiedById,
 		&m.LastModifiedByName,
 		&m.LastModifiedFromIP,
+		&m.Description,
+		&m.TypeOf,
+		&m.IsHomeSupportService,
+		&m.RecurrenceRule,
+		&m.RecurrenceStartDate,
+		&m.RecurrenceEndDate,
+		&m.GeneratedUntil,
 	)
 	if err != nil {
 		// CASE 1 OF 2: Cannot find record with that email.
@@ -242,3 +296,104 @@ func (r *OngoingWorkOrderRepo) InsertOrUpdateById(ctx context.Context, m *models
 	}
 	return r.UpdateById(ctx, m)
 }
+
+func (r *OngoingWorkOrderRepo) ListIdsDueForGeneration(ctx context.Context, until time.Time, limit uint64) ([]uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT
+        id
+    FROM
+        ongoing_work_orders
+    WHERE
+        state = $1
+		AND recurrence_rule <> ''
+		AND recurrence_start_date IS NOT NULL
+		AND (generated_until IS NULL OR generated_until < $2)
+		AND (recurrence_end_date IS NULL OR generated_until IS NULL OR generated_until < recurrence_end_date)
+    ORDER BY
+        id ASC
+    LIMIT $3`
+	rows, err := r.db.QueryContext(ctx, query, models.OngoingWorkOrderActiveState, until, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []uint64
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		arr = append(arr, id)
+	}
+	return arr, rows.Err()
+}
+
+func (r *OngoingWorkOrderRepo) ListIdsByAssociateId(ctx context.Context, associateId uint64) ([]uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT
+        id
+    FROM
+        ongoing_work_orders
+    WHERE
+        associate_id = $1
+		AND state = $2
+		AND recurrence_rule <> ''
+		AND recurrence_start_date IS NOT NULL
+    ORDER BY
+        id ASC`
+	rows, err := r.db.QueryContext(ctx, query, associateId, models.OngoingWorkOrderActiveState)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []uint64
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		arr = append(arr, id)
+	}
+	return arr, rows.Err()
+}
+
+func (r *OngoingWorkOrderRepo) InsertOccurrences(ctx context.Context, m *models.OngoingWorkOrder, arr []*models.OngoingWorkOrderOccurrence, generatedUntil time.Time) error {
+	return runInTx(ctx, r.db, func(tx dbtx) error {
+		wor := &WorkOrderRepo{db: tx}
+		tr := &TaskItemRepo{db: tx}
+		for _, o := range arr {
+			if err := wor.Insert(ctx, o.Order); err != nil {
+				return err
+			}
+			o.Task.OrderId = o.Order.Id
+			if err := tr.InsertForWorkOrder(ctx, o.Task); err != nil {
+				return err
+			}
+			o.Order.LatestPendingTaskId = null.IntFrom(int64(o.Task.Id))
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		query := `
+        UPDATE
+            ongoing_work_orders
+        SET
+            generated_until = $1
+        WHERE
+            id = $2`
+		if _, err := tx.ExecContext(ctx, query, generatedUntil, m.Id); err != nil {
+			return err
+		}
+		m.GeneratedUntil = null.TimeFrom(generatedUntil)
+		return nil
+	})
+}
@@ -9,7 +9,7 @@ import (
 )
 
 type PartnerRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewPartnerRepo(db *sql.DB) *PartnerRepo {
@@ -18,6 +18,14 @@ func NewPartnerRepo(db *sql.DB) *PartnerRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *PartnerRepo) WithTx(tx *sql.Tx) *PartnerRepo {
+	return &PartnerRepo{
+		db: tx,
+	}
+}
+
 func (r *PartnerRepo) Insert(ctx context.Context, m *models.Partner) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
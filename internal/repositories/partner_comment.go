@@ -9,7 +9,7 @@ import (
 )
 
 type PartnerCommentRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewPartnerCommentRepo(db *sql.DB) *PartnerCommentRepo {
@@ -18,6 +18,14 @@ func NewPartnerCommentRepo(db *sql.DB) *PartnerCommentRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *PartnerCommentRepo) WithTx(tx *sql.Tx) *PartnerCommentRepo {
+	return &PartnerCommentRepo{
+		db: tx,
+	}
+}
+
 func (r *PartnerCommentRepo) Insert(ctx context.Context, m *models.PartnerComment) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -9,7 +9,7 @@ import (
 )
 
 type PrivateFileRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewPrivateFileRepo(db *sql.DB) *PrivateFileRepo {
@@ -18,6 +18,14 @@ func NewPrivateFileRepo(db *sql.DB) *PrivateFileRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *PrivateFileRepo) WithTx(tx *sql.Tx) *PrivateFileRepo {
+	return &PrivateFileRepo{
+		db: tx,
+	}
+}
+
 func (r *PrivateFileRepo) Insert(ctx context.Context, m *models.PrivateFile) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -9,7 +9,7 @@ import (
 )
 
 type SkillSetRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewSkillSetRepo(db *sql.DB) *SkillSetRepo {
@@ -18,6 +18,14 @@ func NewSkillSetRepo(db *sql.DB) *SkillSetRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *SkillSetRepo) WithTx(tx *sql.Tx) *SkillSetRepo {
+	return &SkillSetRepo{
+		db: tx,
+	}
+}
+
 func (r *SkillSetRepo) Insert(ctx context.Context, m *models.SkillSet) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
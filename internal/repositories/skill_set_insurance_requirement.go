@@ -9,7 +9,7 @@ import (
 )
 
 type SkillSetInsuranceRequirementRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewSkillSetInsuranceRequirementRepo(db *sql.DB) *SkillSetInsuranceRequirementRepo {
@@ -18,6 +18,14 @@ func NewSkillSetInsuranceRequirementRepo(db *sql.DB) *SkillSetInsuranceRequireme
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *SkillSetInsuranceRequirementRepo) WithTx(tx *sql.Tx) *SkillSetInsuranceRequirementRepo {
+	return &SkillSetInsuranceRequirementRepo{
+		db: tx,
+	}
+}
+
 func (r *SkillSetInsuranceRequirementRepo) Insert(ctx context.Context, m *models.SkillSetInsuranceRequirement) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -9,7 +9,7 @@ import (
 )
 
 type StaffRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewStaffRepo(db *sql.DB) *StaffRepo {
@@ -18,6 +18,14 @@ func NewStaffRepo(db *sql.DB) *StaffRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *StaffRepo) WithTx(tx *sql.Tx) *StaffRepo {
+	return &StaffRepo{
+		db: tx,
+	}
+}
+
 func (r *StaffRepo) Insert(ctx context.Context, m *models.Staff) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
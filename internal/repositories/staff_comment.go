@@ -9,7 +9,7 @@ import (
 )
 
 type StaffCommentRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewStaffCommentRepo(db *sql.DB) *StaffCommentRepo {
@@ -18,6 +18,14 @@ func NewStaffCommentRepo(db *sql.DB) *StaffCommentRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *StaffCommentRepo) WithTx(tx *sql.Tx) *StaffCommentRepo {
+	return &StaffCommentRepo{
+		db: tx,
+	}
+}
+
 func (r *StaffCommentRepo) Insert(ctx context.Context, m *models.StaffComment) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
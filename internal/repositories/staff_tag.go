@@ -9,7 +9,7 @@ import (
 )
 
 type StaffTagRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewStaffTagRepo(db *sql.DB) *StaffTagRepo {
@@ -18,6 +18,14 @@ func NewStaffTagRepo(db *sql.DB) *StaffTagRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *StaffTagRepo) WithTx(tx *sql.Tx) *StaffTagRepo {
+	return &StaffTagRepo{
+		db: tx,
+	}
+}
+
 func (r *StaffTagRepo) Insert(ctx context.Context, m *models.StaffTag) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -9,7 +9,7 @@ import (
 )
 
 type TagRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewTagRepo(db *sql.DB) *TagRepo {
@@ -18,6 +18,14 @@ func NewTagRepo(db *sql.DB) *TagRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *TagRepo) WithTx(tx *sql.Tx) *TagRepo {
+	return &TagRepo{
+		db: tx,
+	}
+}
+
 func (r *TagRepo) Insert(ctx context.Context, m *models.Tag) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
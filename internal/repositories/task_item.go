@@ -5,11 +5,13 @@ import (
 	"database/sql"
 	"time"
 
+	null "gopkg.in/guregu/null.v4"
+
 	"github.com/over55/workery-server/internal/models"
 )
 
 type TaskItemRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewTaskItemRepo(db *sql.DB) *TaskItemRepo {
@@ -18,6 +20,14 @@ func NewTaskItemRepo(db *sql.DB) *TaskItemRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *TaskItemRepo) WithTx(tx *sql.Tx) *TaskItemRepo {
+	return &TaskItemRepo{
+		db: tx,
+	}
+}
+
 func (r *TaskItemRepo) Insert(ctx context.Context, m *models.TaskItem) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -33,23 +43,16 @@ func (r *TaskItemRepo) Insert(ctx context.Context, m *models.TaskItem) error {
     ) VALUES (
         $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16,
 		$17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29
-    )`
-	stmt, err := r.db.PrepareContext(ctx, query)
-	if err != nil {
-		return err
-	}
-	defer stmt.Close()
-
-	_, err = stmt.ExecContext(
-		ctx,
+    ) RETURNING id`
+	return r.db.QueryRowContext(
+		ctx, query,
 		m.Uuid, m.TenantId, m.TypeOf, m.Title, m.Description, m.DueDate, m.IsClosed,
 		m.WasPostponed, m.ClosingReason, m.ClosingReasonOther, m.CreatedTime,
 		m.CreatedFromIP, m.CreatedById, m.CreatedByName, m.LastModifiedTime, m.LastModifiedFromIP,
 		m.LastModifiedById, m.LastModifiedByName, m.OrderId, m.OrderTypeOf, m.OngoingOrderId,
 		m.State, m.CustomerId, m.CustomerName, m.CustomerLexicalName,
 		m.AssociateId, m.AssociateName, m.AssociateLexicalName, m.OldId,
-	)
-	return err
+	).Scan(&m.Id)
 }
 
 func (r *TaskItemRepo) UpdateById(ctx context.Context, m *models.TaskItem) error {
@@ -60,9 +63,14 @@ func (r *TaskItemRepo) UpdateById(ctx context.Context, m *models.TaskItem) error
     UPDATE
         task_items
     SET
-        tenant_id = $1, text = $2, description = $3, state = $4
+        type_of = $1, title = $2, description = $3, due_date = $4, is_closed = $5,
+		was_postponed = $6, closing_reason = $7, closing_reason_other = $8,
+		last_modified_time = $9, last_modified_from_ip = $10, last_modified_by_id = $11,
+		last_modified_by_name = $12, order_type_of = $13, ongoing_order_id = $14, state = $15,
+		customer_id = $16, customer_name = $17, customer_lexical_name = $18,
+		associate_id = $19, associate_name = $20, associate_lexical_name = $21
     WHERE
-        id = $5`
+        id = $22`
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return err
@@ -71,7 +79,12 @@ func (r *TaskItemRepo) UpdateById(ctx context.Context, m *models.TaskItem) error
 
 	_, err = stmt.ExecContext(
 		ctx,
-		m.TenantId, m.Description, m.State, m.Id,
+		m.TypeOf, m.Title, m.Description, m.DueDate, m.IsClosed,
+		m.WasPostponed, m.ClosingReason, m.ClosingReasonOther,
+		m.LastModifiedTime, m.LastModifiedFromIP, m.LastModifiedById,
+		m.LastModifiedByName, m.OrderTypeOf, m.OngoingOrderId, m.State,
+		m.CustomerId, m.CustomerName, m.CustomerLexicalName,
+		m.AssociateId, m.AssociateName, m.AssociateLexicalName, m.Id,
 	)
 	return err
 }
@@ -84,16 +97,30 @@ func (r *TaskItemRepo) GetById(ctx context.Context, id uint64) (*models.TaskItem
 
 	query := `
     SELECT
-        id, uuid, tenant_id, text, description, state
+        id, uuid, tenant_id, type_of, title, description, due_date, is_closed,
+		was_postponed, closing_reason, closing_reason_other, order_id,
+		ongoing_order_id, created_time, created_from_ip, created_by_id,
+		created_by_name, last_modified_time, last_modified_from_ip,
+		last_modified_by_id, last_modified_by_name, state, old_id, customer_id,
+		customer_name, customer_lexical_name, associate_id, associate_name,
+		associate_lexical_name, order_type_of, is_overdue, escalated_time,
+		escalated_to_id
 	FROM
         task_items
     WHERE
         id = $1`
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&m.Id, &m.Uuid, &m.TenantId, &m.Description, &m.State,
+		&m.Id, &m.Uuid, &m.TenantId, &m.TypeOf, &m.Title, &m.Description, &m.DueDate, &m.IsClosed,
+		&m.WasPostponed, &m.ClosingReason, &m.ClosingReasonOther, &m.OrderId,
+		&m.OngoingOrderId, &m.CreatedTime, &m.CreatedFromIP, &m.CreatedById,
+		&m.CreatedByName, &m.LastModifiedTime, &m.LastModifiedFromIP,
+		&m.LastModifiedById, &m.LastModifiedByName, &m.State, &m.OldId, &m.CustomerId,
+		&m.CustomerName, &m.CustomerLexicalName, &m.AssociateId, &m.AssociateName,
+		&m.AssociateLexicalName, &m.OrderTypeOf, &m.IsOverdue, &m.EscalatedTime,
+		&m.EscalatedToId,
 	)
 	if err != nil {
-		// CASE 1 OF 2: Cannot find record with that email.
+		// CASE 1 OF 2: Cannot find record with that id.
 		if err == sql.ErrNoRows {
 			return nil, nil
 		} else { // CASE 2 OF 2: All other errors.
@@ -169,3 +196,166 @@ func (r *TaskItemRepo) InsertOrUpdateById(ctx context.Context, m *models.TaskIte
 	}
 	return r.UpdateById(ctx, m)
 }
+
+func (r *TaskItemRepo) CloseAndCreateNext(ctx context.Context, m *models.TaskItem, order *models.WorkOrder, next *models.TaskItem, change int8) error {
+	return runInTx(ctx, r.db, func(tx dbtx) error {
+		tr := &TaskItemRepo{db: tx}
+		wor := &WorkOrderRepo{db: tx}
+
+		if err := tr.closeById(ctx, m); err != nil {
+			return err
+		}
+		order.LatestPendingTaskId = null.Int{}
+		if next != nil {
+			if err := tr.Insert(ctx, next); err != nil {
+				return err
+			}
+			order.LatestPendingTaskId = null.IntFrom(int64(next.Id))
+		}
+		if err := wor.updateForTaskById(ctx, order, change); err != nil {
+			return err
+		}
+
+		// The financials of the work order could have changed so make sure the
+		// amount due and balance owing still agree with the deposits.
+		if err := wor.UpdateDepositAmountsById(ctx, order.Id); err != nil {
+			return err
+		}
+		saved, err := wor.GetById(ctx, order.Id)
+		if err != nil {
+			return err
+		}
+		if saved != nil {
+			*order = *saved
+		}
+		return nil
+	})
+}
+
+// Function closes the task if it is still open, otherwise someone else got to
+// the task first and `models.ErrTaskItemClosed` is returned.
+func (r *TaskItemRepo) closeById(ctx context.Context, m *models.TaskItem) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    UPDATE
+        task_items
+    SET
+        is_closed = TRUE, was_postponed = $1, closing_reason = $2,
+		closing_reason_other = $3, last_modified_time = $4,
+		last_modified_from_ip = $5, last_modified_by_id = $6,
+		last_modified_by_name = $7
+    WHERE
+        id = $8 AND is_closed = FALSE`
+	res, err := r.db.ExecContext(
+		ctx, query,
+		m.WasPostponed, m.ClosingReason, m.ClosingReasonOther,
+		m.LastModifiedTime, m.LastModifiedFromIP, m.LastModifiedById,
+		m.LastModifiedByName, m.Id,
+	)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n != 1 {
+		return models.ErrTaskItemClosed
+	}
+	m.IsClosed = true
+	return nil
+}
+
+func (r *TaskItemRepo) CloseAndAssignAssociate(ctx context.Context, m *models.TaskItem, order *models.WorkOrder, next *models.TaskItem, item *models.ActivitySheetItem) error {
+	return runInTx(ctx, r.db, func(tx dbtx) error {
+		asir := &ActivitySheetItemRepo{db: tx}
+		if item.Id != 0 { // The associate was offered the job beforehand.
+			if err := asir.UpdateById(ctx, item); err != nil {
+				return err
+			}
+		} else if err := asir.Insert(ctx, item); err != nil {
+			return err
+		}
+		return (&TaskItemRepo{db: tx}).CloseAndCreateNext(ctx, m, order, next, models.TaskWorkOrderAssociateChange)
+	})
+}
+
+func (r *TaskItemRepo) InsertForWorkOrder(ctx context.Context, m *models.TaskItem) error {
+	return runInTx(ctx, r.db, func(tx dbtx) error {
+		tr := &TaskItemRepo{db: tx}
+		if err := tr.Insert(ctx, m); err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		query := `
+        UPDATE
+            work_orders
+        SET
+            latest_pending_task_id = $1
+        WHERE
+            id = $2`
+		_, err := tx.ExecContext(ctx, query, m.Id, m.OrderId)
+		return err
+	})
+}
+
+func (r *TaskItemRepo) UpdateOverdue(ctx context.Context, now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    UPDATE
+        task_items
+    SET
+        is_overdue = TRUE
+    WHERE
+        is_closed = FALSE AND is_overdue = FALSE AND due_date < $1`
+	res, err := r.db.ExecContext(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *TaskItemRepo) EscalateOverdue(ctx context.Context, now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// The task is escalated to the manager in the settings of the tenant,
+	// otherwise to the first active management (2) or executive (1) user.
+	query := `
+    UPDATE
+        task_items AS t
+    SET
+        escalated_time = $1,
+		escalated_to_id = COALESCE(s.escalate_to_id, (
+		    SELECT
+			    u.id
+			FROM
+			    users AS u
+			WHERE
+			    u.tenant_id = t.tenant_id AND u.state = 1 AND u.role_id IN (1, 2)
+			ORDER BY
+			    u.role_id DESC, u.id ASC
+			LIMIT 1
+		))
+    FROM
+        tenants AS tn
+    LEFT JOIN
+        tenant_task_settings AS s ON s.tenant_id = tn.id
+    WHERE
+        tn.id = t.tenant_id
+		AND t.is_closed = FALSE
+		AND t.escalated_time IS NULL
+		AND t.due_date < $1 - make_interval(days => COALESCE(s.escalate_after_days, $2)::INT)`
+	res, err := r.db.ExecContext(ctx, query, now, models.TenantTaskSettingDefaultEscalateAfterDays)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
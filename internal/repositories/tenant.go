@@ -10,7 +10,7 @@ import (
 )
 
 type TenantRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewTenantRepo(db *sql.DB) *TenantRepo {
@@ -19,6 +19,14 @@ func NewTenantRepo(db *sql.DB) *TenantRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *TenantRepo) WithTx(tx *sql.Tx) *TenantRepo {
+	return &TenantRepo{
+		db: tx,
+	}
+}
+
 func (r *TenantRepo) Insert(ctx context.Context, m *models.Tenant) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+type TenantAccountMappingRepo struct {
+	db dbtx
+}
+
+func NewTenantAccountMappingRepo(db *sql.DB) *TenantAccountMappingRepo {
+	return &TenantAccountMappingRepo{
+		db: db,
+	}
+}
+
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *TenantAccountMappingRepo) WithTx(tx *sql.Tx) *TenantAccountMappingRepo {
+	return &TenantAccountMappingRepo{
+		db: tx,
+	}
+}
+
+func (r *TenantAccountMappingRepo) GetByTenantId(ctx context.Context, tenantId uint64) (*models.TenantAccountMapping, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	m := new(models.TenantAccountMapping)
+
+	query := `
+    SELECT
+        id, tenant_id, accounts_receivable, labour_income, materials_income,
+		other_income, sales_tax_payable, undeposited_funds, associate_clearing,
+		service_fee_income, created_time, last_modified_time
+    FROM
+        tenant_account_mappings
+    WHERE
+        tenant_id = $1`
+	err := r.db.QueryRowContext(ctx, query, tenantId).Scan(
+		&m.Id, &m.TenantId, &m.AccountsReceivable, &m.LabourIncome, &m.MaterialsIncome,
+		&m.OtherIncome, &m.SalesTaxPayable, &m.UndepositedFunds, &m.AssociateClearing,
+		&m.ServiceFeeIncome, &m.CreatedTime, &m.LastModifiedTime,
+	)
+	if err != nil {
+		// CASE 1 OF 2: Cannot find record with that tenant.
+		if err == sql.ErrNoRows {
+			return nil, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (r *TenantAccountMappingRepo) InsertOrUpdateByTenantId(ctx context.Context, m *models.TenantAccountMapping) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    INSERT INTO tenant_account_mappings (
+        tenant_id, accounts_receivable, labour_income, materials_income,
+		other_income, sales_tax_payable, undeposited_funds, associate_clearing,
+		service_fee_income, created_time, last_modified_time
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+    ) ON CONFLICT (tenant_id) DO UPDATE SET
+        accounts_receivable = EXCLUDED.accounts_receivable,
+		labour_income = EXCLUDED.labour_income,
+		materials_income = EXCLUDED.materials_income,
+		other_income = EXCLUDED.other_income,
+		sales_tax_payable = EXCLUDED.sales_tax_payable,
+		undeposited_funds = EXCLUDED.undeposited_funds,
+		associate_clearing = EXCLUDED.associate_clearing,
+		service_fee_income = EXCLUDED.service_fee_income,
+		last_modified_time = EXCLUDED.last_modified_time`
+	_, err := r.db.ExecContext(
+		ctx, query,
+		m.TenantId, m.AccountsReceivable, m.LabourIncome, m.MaterialsIncome,
+		m.OtherIncome, m.SalesTaxPayable, m.UndepositedFunds, m.AssociateClearing,
+		m.ServiceFeeIncome, m.CreatedTime, m.LastModifiedTime,
+	)
+	return err
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+type TenantInvoiceTemplateRepo struct {
+	db dbtx
+}
+
+func NewTenantInvoiceTemplateRepo(db *sql.DB) *TenantInvoiceTemplateRepo {
+	return &TenantInvoiceTemplateRepo{
+		db: db,
+	}
+}
+
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *TenantInvoiceTemplateRepo) WithTx(tx *sql.Tx) *TenantInvoiceTemplateRepo {
+	return &TenantInvoiceTemplateRepo{
+		db: tx,
+	}
+}
+
+func (r *TenantInvoiceTemplateRepo) GetByTenantId(ctx context.Context, tenantId uint64) (*models.TenantInvoiceTemplate, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	m := new(models.TenantInvoiceTemplate)
+
+	query := `
+    SELECT
+        id, tenant_id, logo_s3_key, tax_registration_number, footer_text,
+		created_time, last_modified_time
+    FROM
+        tenant_invoice_templates
+    WHERE
+        tenant_id = $1`
+	err := r.db.QueryRowContext(ctx, query, tenantId).Scan(
+		&m.Id, &m.TenantId, &m.LogoS3Key, &m.TaxRegistrationNumber, &m.FooterText,
+		&m.CreatedTime, &m.LastModifiedTime,
+	)
+	if err != nil {
+		// CASE 1 OF 2: Cannot find record with that tenant.
+		if err == sql.ErrNoRows {
+			return nil, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (r *TenantInvoiceTemplateRepo) InsertOrUpdateByTenantId(ctx context.Context, m *models.TenantInvoiceTemplate) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    INSERT INTO tenant_invoice_templates (
+        tenant_id, logo_s3_key, tax_registration_number, footer_text,
+		created_time, last_modified_time
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6
+    ) ON CONFLICT (tenant_id) DO UPDATE SET
+        logo_s3_key = EXCLUDED.logo_s3_key,
+		tax_registration_number = EXCLUDED.tax_registration_number,
+		footer_text = EXCLUDED.footer_text,
+		last_modified_time = EXCLUDED.last_modified_time`
+	_, err := r.db.ExecContext(
+		ctx, query,
+		m.TenantId, m.LogoS3Key, m.TaxRegistrationNumber, m.FooterText,
+		m.CreatedTime, m.LastModifiedTime,
+	)
+	return err
+}
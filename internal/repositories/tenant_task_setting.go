@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+type TenantTaskSettingRepo struct {
+	db dbtx
+}
+
+func NewTenantTaskSettingRepo(db *sql.DB) *TenantTaskSettingRepo {
+	return &TenantTaskSettingRepo{
+		db: db,
+	}
+}
+
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *TenantTaskSettingRepo) WithTx(tx *sql.Tx) *TenantTaskSettingRepo {
+	return &TenantTaskSettingRepo{
+		db: tx,
+	}
+}
+
+func (r *TenantTaskSettingRepo) GetByTenantId(ctx context.Context, tenantId uint64) (*models.TenantTaskSetting, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	m := new(models.TenantTaskSetting)
+
+	query := `
+    SELECT
+        id, tenant_id, follow_up_after_days, escalate_after_days, escalate_to_id,
+		created_time, last_modified_time
+    FROM
+        tenant_task_settings
+    WHERE
+        tenant_id = $1`
+	err := r.db.QueryRowContext(ctx, query, tenantId).Scan(
+		&m.Id, &m.TenantId, &m.FollowUpAfterDays, &m.EscalateAfterDays, &m.EscalateToId,
+		&m.CreatedTime, &m.LastModifiedTime,
+	)
+	if err != nil {
+		// CASE 1 OF 2: Cannot find record with that tenant.
+		if err == sql.ErrNoRows {
+			return nil, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (r *TenantTaskSettingRepo) InsertOrUpdateByTenantId(ctx context.Context, m *models.TenantTaskSetting) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    INSERT INTO tenant_task_settings (
+        tenant_id, follow_up_after_days, escalate_after_days, escalate_to_id,
+		created_time, last_modified_time
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6
+    ) ON CONFLICT (tenant_id) DO UPDATE SET
+        follow_up_after_days = EXCLUDED.follow_up_after_days,
+		escalate_after_days = EXCLUDED.escalate_after_days,
+		escalate_to_id = EXCLUDED.escalate_to_id,
+		last_modified_time = EXCLUDED.last_modified_time`
+	_, err := r.db.ExecContext(
+		ctx, query,
+		m.TenantId, m.FollowUpAfterDays, m.EscalateAfterDays, m.EscalateToId,
+		m.CreatedTime, m.LastModifiedTime,
+	)
+	return err
+}
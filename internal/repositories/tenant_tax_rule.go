@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+type TenantTaxRuleRepo struct {
+	db dbtx
+}
+
+func NewTenantTaxRuleRepo(db *sql.DB) *TenantTaxRuleRepo {
+	return &TenantTaxRuleRepo{
+		db: db,
+	}
+}
+
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *TenantTaxRuleRepo) WithTx(tx *sql.Tx) *TenantTaxRuleRepo {
+	return &TenantTaxRuleRepo{
+		db: tx,
+	}
+}
+
+func (r *TenantTaxRuleRepo) Insert(ctx context.Context, m *models.TenantTaxRule) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    INSERT INTO tenant_tax_rules (
+        uuid, tenant_id, name, rate, type_of, effective_from, effective_to,
+		created_time, created_by_id, last_modified_time, last_modified_by_id
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+    ) RETURNING id`
+	return r.db.QueryRowContext(
+		ctx, query,
+		m.Uuid, m.TenantId, m.Name, m.Rate, m.TypeOf, m.EffectiveFrom, m.EffectiveTo,
+		m.CreatedTime, m.CreatedById, m.LastModifiedTime, m.LastModifiedById,
+	).Scan(&m.Id)
+}
+
+func (r *TenantTaxRuleRepo) UpdateById(ctx context.Context, m *models.TenantTaxRule) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    UPDATE
+        tenant_tax_rules
+    SET
+        name = $1, rate = $2, type_of = $3, effective_from = $4, effective_to = $5,
+		last_modified_time = $6, last_modified_by_id = $7
+    WHERE
+        id = $8`
+	_, err := r.db.ExecContext(
+		ctx, query,
+		m.Name, m.Rate, m.TypeOf, m.EffectiveFrom, m.EffectiveTo,
+		m.LastModifiedTime, m.LastModifiedById, m.Id,
+	)
+	return err
+}
+
+const tenantTaxRuleColumns = `
+        id, uuid, tenant_id, name, rate, type_of, effective_from, effective_to,
+		created_time, created_by_id, last_modified_time, last_modified_by_id`
+
+func scanTenantTaxRule(row interface{ Scan(...interface{}) error }) (*models.TenantTaxRule, error) {
+	m := new(models.TenantTaxRule)
+	err := row.Scan(
+		&m.Id, &m.Uuid, &m.TenantId, &m.Name, &m.Rate, &m.TypeOf, &m.EffectiveFrom, &m.EffectiveTo,
+		&m.CreatedTime, &m.CreatedById, &m.LastModifiedTime, &m.LastModifiedById,
+	)
+	return m, err
+}
+
+func (r *TenantTaxRuleRepo) GetById(ctx context.Context, id uint64) (*models.TenantTaxRule, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT` + tenantTaxRuleColumns + `
+    FROM
+        tenant_tax_rules
+    WHERE
+        id = $1`
+	m, err := scanTenantTaxRule(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		// CASE 1 OF 2: Cannot find record with that id.
+		if err == sql.ErrNoRows {
+			return nil, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (r *TenantTaxRuleRepo) DeleteById(ctx context.Context, id uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `DELETE FROM tenant_tax_rules WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *TenantTaxRuleRepo) ListByTenantId(ctx context.Context, tenantId uint64) ([]*models.TenantTaxRule, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT` + tenantTaxRuleColumns + `
+    FROM
+        tenant_tax_rules
+    WHERE
+        tenant_id = $1
+    ORDER BY
+        effective_from DESC, name, id`
+	return r.queryTenantTaxRules(ctx, query, tenantId)
+}
+
+// Function returns the rules of the tenant which apply to the type of work
+// order at the time.
+func (r *TenantTaxRuleRepo) ListEffectiveByTenantId(ctx context.Context, tenantId uint64, typeOf int8, at time.Time) ([]*models.TenantTaxRule, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT` + tenantTaxRuleColumns + `
+    FROM
+        tenant_tax_rules
+    WHERE
+        tenant_id = $1 AND (type_of = $2 OR type_of = $3)
+		AND effective_from <= $4 AND (effective_to IS NULL OR effective_to > $4)
+    ORDER BY
+        name, id`
+	return r.queryTenantTaxRules(ctx, query, tenantId, models.TenantTaxRuleAnyTypeOf, typeOf, at)
+}
+
+func (r *TenantTaxRuleRepo) queryTenantTaxRules(ctx context.Context, query string, args ...interface{}) ([]*models.TenantTaxRule, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.TenantTaxRule
+	for rows.Next() {
+		m, err := scanTenantTaxRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, m)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	return arr, err
+}
@@ -9,7 +9,7 @@ import (
 )
 
 type UserRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewUserRepo(db *sql.DB) *UserRepo {
@@ -18,6 +18,14 @@ func NewUserRepo(db *sql.DB) *UserRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *UserRepo) WithTx(tx *sql.Tx) *UserRepo {
+	return &UserRepo{
+		db: tx,
+	}
+}
+
 func (r *UserRepo) Insert(ctx context.Context, m *models.User) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -9,7 +9,7 @@ import (
 )
 
 type VehicleTypeRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewVehicleTypeRepo(db *sql.DB) *VehicleTypeRepo {
@@ -18,6 +18,14 @@ func NewVehicleTypeRepo(db *sql.DB) *VehicleTypeRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *VehicleTypeRepo) WithTx(tx *sql.Tx) *VehicleTypeRepo {
+	return &VehicleTypeRepo{
+		db: tx,
+	}
+}
+
 func (r *VehicleTypeRepo) Insert(ctx context.Context, m *models.VehicleType) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
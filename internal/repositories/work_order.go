@@ -9,7 +9,7 @@ import (
 )
 
 type WorkOrderRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewWorkOrderRepo(db *sql.DB) *WorkOrderRepo {
@@ -18,6 +18,14 @@ func NewWorkOrderRepo(db *sql.DB) *WorkOrderRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *WorkOrderRepo) WithTx(tx *sql.Tx) *WorkOrderRepo {
+	return &WorkOrderRepo{
+		db: tx,
+	}
+}
+
 func (r *WorkOrderRepo) Insert(ctx context.Context, m *models.WorkOrder) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -47,18 +55,12 @@ func (r *WorkOrderRepo) Insert(ctx context.Context, m *models.WorkOrder) error {
 		$33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47,
 		$48, $49, $50, $51, $52, $53, $54, $55, $56, $57, $58, $59, $60, $61, $62,
 		$63, $64, $65, $66
-    )`
-	stmt, err := r.db.PrepareContext(ctx, query)
-	if err != nil {
-		return err
-	}
-	defer stmt.Close()
-
-	_, err = stmt.ExecContext(
-		ctx,
+    ) RETURNING id`
+	return r.db.QueryRowContext(
+		ctx, query,
 		m.Uuid, m.TenantId, m.CustomerId, m.AssociateId, m.Description,
 		m.AssignmentDate, m.IsOngoing, m.IsHomeSupportService, m.StartDate, m.CompletionDate, m.Hours,
-		m.IndexedText, m.ClosingReason, m.ClosingReasonOther, m.State, m.Currency,
+		m.IndexedText, m.ClosingReason, m.ClosingReasonOther, m.State, models.CurrencyOrDefault(m.Currency),
 		m.WasJobSatisfactory, m.WasJobFinishedOnTimeAndOnBudget, m.WasAssociatePunctual,
 		m.WasAssociateProfessional, m.WouldCustomerReferOurOrganization, m.Score,
 		m.InvoiceDate, m.InvoiceQuoteAmount, m.InvoiceLabourAmount, m.InvoiceMaterialAmount,
@@ -73,8 +75,7 @@ func (r *WorkOrderRepo) Insert(ctx context.Context, m *models.WorkOrder) error {
 		m.InvoiceOtherCostsAmount, m.InvoiceQuotedOtherCostsAmount, m.InvoicePaidTo,
 		m.InvoiceAmountDue, m.InvoiceSubTotalAmount, m.ClosingReasonComment, m.TypeOf,
 		m.CustomerName, m.CustomerLexicalName, m.AssociateName, m.AssociateLexicalName,
-	)
-	return err
+	).Scan(&m.Id)
 }
 
 func (r *WorkOrderRepo) UpdateById(ctx context.Context, m *models.WorkOrder) error {
@@ -85,10 +86,39 @@ func (r *WorkOrderRepo) UpdateById(ctx context.Context, m *models.WorkOrder) err
     UPDATE
         work_orders
     SET
-        tenant_id = $1, customer_id = $2, associate_id = $3, state = $4,
-		last_modified_time = $5, last_modified_by_id = $6, last_modified_from_ip = $7
+        tenant_id = $1, customer_id = $2, associate_id = $3, description = $4,
+		assignment_date = $5, is_ongoing = $6, is_home_support_service = $7,
+		start_date = $8, completion_date = $9, hours = $10, indexed_text = $11,
+		closing_reason = $12, closing_reason_other = $13, state = $14,
+		currency = $15, was_job_satisfactory = $16,
+		was_job_finished_on_time_and_on_budget = $17, was_associate_punctual = $18,
+		was_associate_professional = $19,
+		would_customer_refer_our_organization = $20, score = $21, invoice_date = $22,
+		invoice_quote_amount = $23, invoice_labour_amount = $24,
+		invoice_material_amount = $25, invoice_tax_amount = $26,
+		invoice_total_amount = $27, invoice_service_fee_amount = $28,
+		invoice_service_fee_payment_date = $29, last_modified_time = $30,
+		last_modified_by_id = $31, last_modified_by_name = $32,
+		last_modified_from_ip = $33, invoice_service_fee_id = $34,
+		latest_pending_task_id = $35, ongoing_work_order_id = $36,
+		was_survey_conducted = $37, was_there_financials_inputted = $38,
+		invoice_actual_service_fee_amount_paid = $39,
+		invoice_balance_owing_amount = $40, invoice_quoted_labour_amount = $41,
+		invoice_quoted_material_amount = $42, invoice_total_quote_amount = $43,
+		visits = $44, invoice_ids = $45, no_survey_conducted_reason = $46,
+		no_survey_conducted_reason_other = $47, cloned_from_id = $48,
+		invoice_deposit_amount = $49, invoice_other_costs_amount = $50,
+		invoice_quoted_other_costs_amount = $51, invoice_paid_to = $52,
+		invoice_amount_due = $53, invoice_sub_total_amount = $54,
+		closing_reason_comment = $55, type_of = $56, customer_name = $57,
+		customer_lexical_name = $58, associate_name = $59,
+		associate_lexical_name = $60,
+		invoice_service_fee_payment_version = invoice_service_fee_payment_version + CASE
+            WHEN invoice_actual_service_fee_amount_paid IS DISTINCT FROM $39
+			OR invoice_service_fee_payment_date IS DISTINCT FROM $29 THEN 1 ELSE 0
+        END
     WHERE
-        id = $8`
+        id = $61`
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return err
@@ -97,8 +127,229 @@ func (r *WorkOrderRepo) UpdateById(ctx context.Context, m *models.WorkOrder) err
 
 	_, err = stmt.ExecContext(
 		ctx,
-		m.TenantId, m.CustomerId, m.AssociateId, m.State, m.LastModifiedTime,
-		m.LastModifiedById, m.LastModifiedFromIP, m.Id,
+		m.TenantId, m.CustomerId, m.AssociateId, m.Description, m.AssignmentDate,
+		m.IsOngoing, m.IsHomeSupportService, m.StartDate, m.CompletionDate, m.Hours,
+		m.IndexedText, m.ClosingReason, m.ClosingReasonOther, m.State, models.CurrencyOrDefault(m.Currency),
+		m.WasJobSatisfactory, m.WasJobFinishedOnTimeAndOnBudget,
+		m.WasAssociatePunctual, m.WasAssociateProfessional,
+		m.WouldCustomerReferOurOrganization, m.Score, m.InvoiceDate,
+		m.InvoiceQuoteAmount, m.InvoiceLabourAmount, m.InvoiceMaterialAmount,
+		m.InvoiceTaxAmount, m.InvoiceTotalAmount, m.InvoiceServiceFeeAmount,
+		m.InvoiceServiceFeePaymentDate, m.LastModifiedTime, m.LastModifiedById,
+		m.LastModifiedByName, m.LastModifiedFromIP, m.InvoiceServiceFeeId,
+		m.LatestPendingTaskId, m.OngoingWorkOrderId, m.WasSurveyConducted,
+		m.WasThereFinancialsInputted, m.InvoiceActualServiceFeeAmountPaid,
+		m.InvoiceBalanceOwingAmount, m.InvoiceQuotedLabourAmount,
+		m.InvoiceQuotedMaterialAmount, m.InvoiceTotalQuoteAmount, m.Visits,
+		m.InvoiceIds, m.NoSurveyConductedReason, m.NoSurveyConductedReasonOther,
+		m.ClonedFromId, m.InvoiceDepositAmount, m.InvoiceOtherCostsAmount,
+		m.InvoiceQuotedOtherCostsAmount, m.InvoicePaidTo, m.InvoiceAmountDue,
+		m.InvoiceSubTotalAmount, m.ClosingReasonComment, m.TypeOf, m.CustomerName,
+		m.CustomerLexicalName, m.AssociateName, m.AssociateLexicalName, m.Id,
+	)
+	return err
+}
+
+// Function saves the `change` part of the work order made by a task
+// operation along with the latest pending task, the other columns are left
+// alone so they are never overwritten from an older read of the work order.
+func (r *WorkOrderRepo) updateForTaskById(ctx context.Context, m *models.WorkOrder, change int8) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    UPDATE
+        work_orders
+    SET
+        latest_pending_task_id = $2, last_modified_time = $3,
+		last_modified_by_id = $4, last_modified_by_name = $5,
+		last_modified_from_ip = $6`
+	args := []interface{}{
+		m.Id, m.LatestPendingTaskId, m.LastModifiedTime, m.LastModifiedById,
+		m.LastModifiedByName, m.LastModifiedFromIP,
+	}
+	switch change {
+	case models.TaskWorkOrderAssociateChange:
+		// The service fee of the associate is only a default.
+		query += `,
+		state = $7, associate_id = $8, associate_name = $9,
+		associate_lexical_name = $10, assignment_date = $11,
+		invoice_service_fee_id = COALESCE(invoice_service_fee_id, $12)`
+		args = append(
+			args,
+			m.State, m.AssociateId, m.AssociateName, m.AssociateLexicalName,
+			m.AssignmentDate, m.InvoiceServiceFeeId,
+		)
+	case models.TaskWorkOrderClosingChange:
+		query += `,
+		state = $7, closing_reason = $8, closing_reason_other = $9,
+		closing_reason_comment = $10`
+		args = append(args, m.State, m.ClosingReason, m.ClosingReasonOther, m.ClosingReasonComment)
+	case models.TaskWorkOrderCompletionChange:
+		query += `,
+		state = $7, completion_date = $8, invoice_date = $9, invoice_ids = $10,
+		invoice_quote_amount = $11, invoice_labour_amount = $12,
+		invoice_material_amount = $13, invoice_other_costs_amount = $14,
+		invoice_sub_total_amount = $15, invoice_tax_amount = $16,
+		invoice_total_amount = $17, invoice_paid_to = $18,
+		invoice_service_fee_id = $19, invoice_service_fee_amount = $20,
+		visits = $21, was_there_financials_inputted = $22,
+		invoice_tax_rules = $23`
+		args = append(
+			args,
+			m.State, m.CompletionDate, m.InvoiceDate, m.InvoiceIds,
+			m.InvoiceQuoteAmount, m.InvoiceLabourAmount, m.InvoiceMaterialAmount,
+			m.InvoiceOtherCostsAmount, m.InvoiceSubTotalAmount, m.InvoiceTaxAmount,
+			m.InvoiceTotalAmount, m.InvoicePaidTo, m.InvoiceServiceFeeId,
+			m.InvoiceServiceFeeAmount, m.Visits, m.WasThereFinancialsInputted,
+			m.InvoiceTaxRules,
+		)
+	}
+	query += `
+    WHERE
+        id = $1`
+	_, err := r.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+// Function saves the survey answers and the score of the work order along
+// with the latest pending task, the other columns are left alone so they are
+// never overwritten from an older read of the work order.
+func (r *WorkOrderRepo) updateSurveyById(ctx context.Context, m *models.WorkOrder) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    UPDATE
+        work_orders
+    SET
+        was_survey_conducted = $2, no_survey_conducted_reason = $3,
+		no_survey_conducted_reason_other = $4, was_job_satisfactory = $5,
+		was_job_finished_on_time_and_on_budget = $6,
+		was_associate_punctual = $7, was_associate_professional = $8,
+		would_customer_refer_our_organization = $9, score = $10,
+		latest_pending_task_id = $11, last_modified_time = $12,
+		last_modified_by_id = $13, last_modified_by_name = $14,
+		last_modified_from_ip = $15
+    WHERE
+        id = $1`
+	_, err := r.db.ExecContext(
+		ctx, query,
+		m.Id, m.WasSurveyConducted, m.NoSurveyConductedReason,
+		m.NoSurveyConductedReasonOther, m.WasJobSatisfactory,
+		m.WasJobFinishedOnTimeAndOnBudget, m.WasAssociatePunctual,
+		m.WasAssociateProfessional, m.WouldCustomerReferOurOrganization,
+		m.Score, m.LatestPendingTaskId, m.LastModifiedTime,
+		m.LastModifiedById, m.LastModifiedByName, m.LastModifiedFromIP,
+	)
+	return err
+}
+
+// Function saves the hours, the visits and the completion date the
+// associate entered, the work order must still be assigned to the associate
+// and in progress or ongoing.
+func (r *WorkOrderRepo) UpdateAssociateCompletionById(ctx context.Context, m *models.WorkOrder) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    UPDATE
+        work_orders
+    SET
+        hours = $1, visits = $2, completion_date = $3,
+		last_modified_time = $4, last_modified_by_id = $5,
+		last_modified_by_name = $6, last_modified_from_ip = $7
+    WHERE
+        id = $8 AND associate_id = $9 AND state IN ($10, $11)`
+	res, err := r.db.ExecContext(
+		ctx, query,
+		m.Hours, m.Visits, m.CompletionDate, m.LastModifiedTime,
+		m.LastModifiedById, m.LastModifiedByName, m.LastModifiedFromIP,
+		m.Id, m.AssociateId, models.WorkOrderInProgressState,
+		models.WorkOrderOngoingState,
+	)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n != 1 {
+		return models.ErrWorkOrderNotInProgress
+	}
+	return nil
+}
+
+// Function recomputes the deposit amount, the amount due and the balance
+// owing of the work order from its active deposits. The balance owing is the
+// service fee the associate still owes us so deposits which were paid to the
+// organization are deducted from it.
+func (r *WorkOrderRepo) UpdateDepositAmountsById(ctx context.Context, id uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    UPDATE
+        work_orders
+    SET
+        invoice_deposit_amount = d.total,
+		invoice_amount_due = work_orders.invoice_total_amount - d.total,
+		invoice_balance_owing_amount = work_orders.invoice_service_fee_amount - work_orders.invoice_actual_service_fee_amount_paid - d.paid_to_organization
+    FROM (
+        SELECT
+            COALESCE(SUM(amount), 0) AS total,
+            COALESCE(SUM(amount) FILTER (WHERE paid_to = $2), 0) AS paid_to_organization
+        FROM
+            work_order_deposits
+        WHERE
+            order_id = $1 AND state = $3
+    ) AS d
+    WHERE
+        work_orders.id = $1`
+	_, err := r.db.ExecContext(ctx, query, id, models.WorkOrderDepositPaidToOrganization, models.WorkOrderDepositActiveState)
+	return err
+}
+
+// Function saves the service fee payment of every work order and recomputes
+// their balance owing, either all of the work orders get updated or none.
+func (r *WorkOrderRepo) UpdateServiceFeePayments(ctx context.Context, arr []*models.WorkOrder) error {
+	return runInTx(ctx, r.db, func(tx dbtx) error {
+		txr := &WorkOrderRepo{db: tx}
+		for _, m := range arr {
+			if err := txr.updateServiceFeePaymentById(ctx, m); err != nil {
+				return err
+			}
+			if err := txr.UpdateDepositAmountsById(ctx, m.Id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *WorkOrderRepo) updateServiceFeePaymentById(ctx context.Context, m *models.WorkOrder) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    UPDATE
+        work_orders
+    SET
+        invoice_actual_service_fee_amount_paid = $1, invoice_service_fee_payment_date = $2,
+		last_modified_time = $3, last_modified_by_id = $4, last_modified_by_name = $5,
+		last_modified_from_ip = $6,
+		invoice_service_fee_payment_version = invoice_service_fee_payment_version + CASE
+            WHEN invoice_actual_service_fee_amount_paid IS DISTINCT FROM $1
+			OR invoice_service_fee_payment_date IS DISTINCT FROM $2 THEN 1 ELSE 0
+        END
+    WHERE
+        id = $7`
+	_, err := r.db.ExecContext(
+		ctx, query,
+		m.InvoiceActualServiceFeeAmountPaid, m.InvoiceServiceFeePaymentDate,
+		m.LastModifiedTime, m.LastModifiedById, m.LastModifiedByName,
+		m.LastModifiedFromIP, m.Id,
 	)
 	return err
 }
@@ -127,7 +378,8 @@ func (r *WorkOrderRepo) GetById(ctx context.Context, id uint64) (*models.WorkOrd
 		no_survey_conducted_reason_other, cloned_from_id, invoice_deposit_amount,
 		invoice_other_costs_amount, invoice_quoted_other_costs_amount, invoice_paid_to,
 		invoice_amount_due, invoice_sub_total_amount, closing_reason_comment, type_of,
-		customer_name, customer_lexical_name, associate_name, associate_lexical_name
+		customer_name, customer_lexical_name, associate_name, associate_lexical_name,
+		invoice_tax_rules
 	FROM
         work_orders
     WHERE
@@ -150,6 +402,7 @@ func (r *WorkOrderRepo) GetById(ctx context.Context, id uint64) (*models.WorkOrd
 		&m.InvoiceOtherCostsAmount, &m.InvoiceQuotedOtherCostsAmount, &m.InvoicePaidTo,
 		&m.InvoiceAmountDue, &m.InvoiceSubTotalAmount, &m.ClosingReasonComment, &m.TypeOf,
 		&m.CustomerName, &m.CustomerLexicalName, &m.AssociateName, &m.AssociateLexicalName,
+		&m.InvoiceTaxRules,
 	)
 	if err != nil {
 		// CASE 1 OF 2: Cannot find record with that email.
@@ -230,3 +483,45 @@ func (r *WorkOrderRepo) InsertOrUpdateById(ctx context.Context, m *models.WorkOr
 	}
 	return r.UpdateById(ctx, m)
 }
+
+func (r *WorkOrderRepo) ListIdsDueForFollowUp(ctx context.Context, now time.Time, limit uint64) ([]uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT
+        w.id
+    FROM
+        work_orders AS w
+    LEFT JOIN
+        tenant_task_settings AS s ON s.tenant_id = w.tenant_id
+    WHERE
+        w.state IN ($1, $2)
+		AND w.associate_id IS NOT NULL
+		AND w.assignment_date <= $3 - make_interval(days => COALESCE(s.follow_up_after_days, $4)::INT)
+		AND NOT EXISTS (
+		    SELECT 1 FROM task_items AS t WHERE t.order_id = w.id AND t.is_closed = FALSE
+		)
+    ORDER BY
+        w.id ASC
+    LIMIT $5`
+	rows, err := r.db.QueryContext(
+		ctx, query,
+		models.WorkOrderOngoingState, models.WorkOrderInProgressState, now,
+		models.TenantTaskSettingDefaultFollowUpAfterDays, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []uint64
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		arr = append(arr, id)
+	}
+	return arr, rows.Err()
+}
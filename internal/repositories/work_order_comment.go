@@ -11,7 +11,7 @@ import (
 )
 
 type WorkOrderCommentRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewWorkOrderCommentRepo(db *sql.DB) *WorkOrderCommentRepo {
@@ -20,6 +20,14 @@ func NewWorkOrderCommentRepo(db *sql.DB) *WorkOrderCommentRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *WorkOrderCommentRepo) WithTx(tx *sql.Tx) *WorkOrderCommentRepo {
+	return &WorkOrderCommentRepo{
+		db: tx,
+	}
+}
+
 func (r *WorkOrderCommentRepo) Insert(ctx context.Context, m *models.WorkOrderComment) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -9,7 +9,7 @@ import (
 )
 
 type WorkOrderDepositRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewWorkOrderDepositRepo(db *sql.DB) *WorkOrderDepositRepo {
@@ -18,6 +18,14 @@ func NewWorkOrderDepositRepo(db *sql.DB) *WorkOrderDepositRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *WorkOrderDepositRepo) WithTx(tx *sql.Tx) *WorkOrderDepositRepo {
+	return &WorkOrderDepositRepo{
+		db: tx,
+	}
+}
+
 func (r *WorkOrderDepositRepo) Insert(ctx context.Context, m *models.WorkOrderDeposit) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -27,25 +35,24 @@ func (r *WorkOrderDepositRepo) Insert(ctx context.Context, m *models.WorkOrderDe
         uuid, tenant_id, paid_at, deposit_method, paid_to, currency,
 		amount, paid_for, created_time, last_modified_time, created_by_id,
 		created_by_name, last_modified_by_id, last_modified_by_name, order_id, created_from_ip,
-		last_modified_from_ip, state, old_id
+		last_modified_from_ip, state, old_id, reversal_of_id
     ) VALUES (
         $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16,
-		$17, $18, $19
-    )`
+		$17, $18, $19, $20
+    ) RETURNING id`
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.ExecContext(
+	return stmt.QueryRowContext(
 		ctx,
-		m.Uuid, m.TenantId, m.PaidAt, m.DepositMethod, m.PaidTo, m.Currency,
+		m.Uuid, m.TenantId, m.PaidAt, m.DepositMethod, m.PaidTo, models.CurrencyOrDefault(m.Currency),
 		m.Amount, m.PaidFor, m.CreatedTime, m.LastModifiedTime, m.CreatedById,
 		m.CreatedByName, m.LastModifiedById, m.LastModifiedByName, m.OrderId, m.CreatedFromIP,
-		m.LastModifiedFromIP, m.State, m.OldId,
-	)
-	return err
+		m.LastModifiedFromIP, m.State, m.OldId, m.ReversalOfId,
+	).Scan(&m.Id)
 }
 
 func (r *WorkOrderDepositRepo) UpdateById(ctx context.Context, m *models.WorkOrderDeposit) error {
@@ -56,9 +63,12 @@ func (r *WorkOrderDepositRepo) UpdateById(ctx context.Context, m *models.WorkOrd
     UPDATE
         work_order_deposits
     SET
-        tenant_id = $1, text = $2, description = $3, state = $4
+        tenant_id = $1, paid_at = $2, deposit_method = $3, paid_to = $4,
+		currency = $5, amount = $6, paid_for = $7, last_modified_time = $8,
+		last_modified_by_id = $9, last_modified_by_name = $10,
+		last_modified_from_ip = $11, state = $12
     WHERE
-        id = $5`
+        id = $13`
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return err
@@ -67,33 +77,46 @@ func (r *WorkOrderDepositRepo) UpdateById(ctx context.Context, m *models.WorkOrd
 
 	_, err = stmt.ExecContext(
 		ctx,
-		m.TenantId,
-		// m.Text, m.Description,
-		m.State, m.Id,
+		m.TenantId, m.PaidAt, m.DepositMethod, m.PaidTo,
+		models.CurrencyOrDefault(m.Currency), m.Amount, m.PaidFor, m.LastModifiedTime,
+		m.LastModifiedById, m.LastModifiedByName,
+		m.LastModifiedFromIP, m.State, m.Id,
 	)
 	return err
 }
 
+const workOrderDepositColumns = `
+        id, uuid, tenant_id, order_id, paid_at, deposit_method, paid_to,
+		currency, amount, paid_for, created_time, created_by_id,
+		created_by_name, created_from_ip, last_modified_time,
+		last_modified_by_id, last_modified_by_name, last_modified_from_ip,
+		state, old_id, reversal_of_id`
+
+func scanWorkOrderDeposit(row interface{ Scan(...interface{}) error }) (*models.WorkOrderDeposit, error) {
+	m := new(models.WorkOrderDeposit)
+	err := row.Scan(
+		&m.Id, &m.Uuid, &m.TenantId, &m.OrderId, &m.PaidAt, &m.DepositMethod, &m.PaidTo,
+		&m.Currency, &m.Amount, &m.PaidFor, &m.CreatedTime, &m.CreatedById,
+		&m.CreatedByName, &m.CreatedFromIP, &m.LastModifiedTime,
+		&m.LastModifiedById, &m.LastModifiedByName, &m.LastModifiedFromIP,
+		&m.State, &m.OldId, &m.ReversalOfId,
+	)
+	return m, err
+}
+
 func (r *WorkOrderDepositRepo) GetById(ctx context.Context, id uint64) (*models.WorkOrderDeposit, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	m := new(models.WorkOrderDeposit)
-
 	query := `
-    SELECT
-        id, uuid, tenant_id, text, description, state
+    SELECT` + workOrderDepositColumns + `
 	FROM
         work_order_deposits
     WHERE
         id = $1`
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&m.Id, &m.Uuid, &m.TenantId,
-		// &m.Text, &m.Description,
-		&m.State,
-	)
+	m, err := scanWorkOrderDeposit(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
-		// CASE 1 OF 2: Cannot find record with that email.
+		// CASE 1 OF 2: Cannot find record with that id.
 		if err == sql.ErrNoRows {
 			return nil, nil
 		} else { // CASE 2 OF 2: All other errors.
@@ -103,6 +126,110 @@ func (r *WorkOrderDepositRepo) GetById(ctx context.Context, id uint64) (*models.
 	return m, nil
 }
 
+func (r *WorkOrderDepositRepo) GetByReversalOfId(ctx context.Context, id uint64) (*models.WorkOrderDeposit, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT` + workOrderDepositColumns + `
+	FROM
+        work_order_deposits
+    WHERE
+        reversal_of_id = $1`
+	m, err := scanWorkOrderDeposit(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		// CASE 1 OF 2: Cannot find record with that id.
+		if err == sql.ErrNoRows {
+			return nil, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (r *WorkOrderDepositRepo) ListByOrderId(ctx context.Context, orderId uint64) ([]*models.WorkOrderDeposit, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT` + workOrderDepositColumns + `
+	FROM
+        work_order_deposits
+    WHERE
+        order_id = $1
+    ORDER BY
+        paid_at, id`
+	rows, err := r.db.QueryContext(ctx, query, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.WorkOrderDeposit
+	for rows.Next() {
+		m, err := scanWorkOrderDeposit(rows)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, m)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	return arr, err
+}
+
+// Function inserts the deposit and recomputes the deposit amount, amount
+// due and balance owing of its work order and the deposit and amount due of
+// the invoice of the work order in the same transaction. The work order row
+// is locked first so deposits posted at the same time are applied one after
+// the other. If the work order has an invoice the change goes through
+// `editWorkOrderInvoice` so the invoice is kept as the `rev` revision, if the
+// invoice is locked `models.ErrWorkOrderInvoiceLocked` is returned.
+func (r *WorkOrderDepositRepo) InsertAndUpdateOrderBalance(ctx context.Context, m *models.WorkOrderDeposit, rev *models.WorkOrderInvoiceRevision) error {
+	return runInTx(ctx, r.db, func(tx dbtx) error {
+		query := `
+        SELECT
+            id
+        FROM
+            work_orders
+        WHERE
+            id = $1
+        FOR UPDATE`
+		if _, err := tx.ExecContext(ctx, query, m.OrderId); err != nil {
+			return err
+		}
+
+		deposit := func(tx dbtx) error {
+			if err := (&WorkOrderDepositRepo{db: tx}).Insert(ctx, m); err != nil {
+				return err
+			}
+			if err := (&WorkOrderRepo{db: tx}).UpdateDepositAmountsById(ctx, m.OrderId); err != nil {
+				return err
+			}
+			return (&WorkOrderInvoiceRepo{db: tx}).UpdateDepositAmountsByOrderId(ctx, m.OrderId)
+		}
+
+		inv, err := (&WorkOrderInvoiceRepo{db: tx}).GetByOrderId(ctx, m.OrderId)
+		if err != nil {
+			return err
+		}
+		if inv == nil {
+			return deposit(tx)
+		}
+		if inv.IsLocked() {
+			return models.ErrWorkOrderInvoiceLocked
+		}
+		inv.LastModifiedTime = m.LastModifiedTime
+		inv.LastModifiedById = uint64(m.LastModifiedById.ValueOrZero())
+		inv.LastModifiedByName = m.LastModifiedByName
+		_, err = editWorkOrderInvoice(ctx, tx, inv, rev, deposit)
+		return err
+	})
+}
+
 func (r *WorkOrderDepositRepo) GetIdByOldId(ctx context.Context, tenantId uint64, oldId uint64) (uint64, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -9,7 +9,7 @@ import (
 )
 
 type WorkOrderInvoiceRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewWorkOrderInvoiceRepo(db *sql.DB) *WorkOrderInvoiceRepo {
@@ -18,6 +18,14 @@ func NewWorkOrderInvoiceRepo(db *sql.DB) *WorkOrderInvoiceRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *WorkOrderInvoiceRepo) WithTx(tx *sql.Tx) *WorkOrderInvoiceRepo {
+	return &WorkOrderInvoiceRepo{
+		db: tx,
+	}
+}
+
 func (r *WorkOrderInvoiceRepo) Insert(ctx context.Context, m *models.WorkOrderInvoice) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -49,7 +57,7 @@ func (r *WorkOrderInvoiceRepo) Insert(ctx context.Context, m *models.WorkOrderIn
 		client_signature, associate_sign_date, associate_signature, created_time,
 		last_modified_time, created_by_id, last_modified_by_id, client_address,
 		revision_version, deposit, amount_due, sub_total, state, created_by_name,
-		last_modified_by_name
+		last_modified_by_name, tax_rules
     ) VALUES (
         $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16,
 		$17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31,
@@ -58,7 +66,7 @@ func (r *WorkOrderInvoiceRepo) Insert(ctx context.Context, m *models.WorkOrderIn
 		$60, $61, $62, $63, $64, $65, $66, $67, $68, $69, $70, $71, $72, $73,
 		$74, $75, $76, $77, $78, $79, $80, $81, $82, $83, $84, $85, $86, $87,
 		$88, $89, $90, $91, $92, $93, $94, $95, $96, $97, $98, $99, $100, $101,
-		$102, $103, $104
+		$102, $103, $104, $105
     )`
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
@@ -93,7 +101,7 @@ func (r *WorkOrderInvoiceRepo) Insert(ctx context.Context, m *models.WorkOrderIn
 		m.ClientSignature, m.AssociateSignDate, m.AssociateSignature,
 		m.CreatedTime, m.LastModifiedTime, m.CreatedById, m.LastModifiedById,
 		m.ClientAddress, m.RevisionVersion, m.Deposit, m.AmountDue, m.SubTotal,
-		m.State, m.CreatedByName, m.LastModifiedByName,
+		m.State, m.CreatedByName, m.LastModifiedByName, m.TaxRules,
 	)
 	return err
 }
@@ -122,6 +130,85 @@ func (r *WorkOrderInvoiceRepo) UpdateById(ctx context.Context, m *models.WorkOrd
 	return err
 }
 
+func (r *WorkOrderInvoiceRepo) UpdateTotalsById(ctx context.Context, m *models.WorkOrderInvoice) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    UPDATE
+        work_order_invoices
+    SET
+        total_labour = $1, total_materials = $2, other_costs = $3,
+		sub_total = $4, tax = $5, total = $6, amount_due = $7,
+		last_modified_time = $8, last_modified_by_id = $9,
+		last_modified_by_name = $10, tax_rules = $11
+    WHERE
+        id = $12`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(
+		ctx,
+		m.TotalLabour, m.TotalMaterials, m.OtherCosts,
+		m.SubTotal, m.Tax, m.Total, m.AmountDue,
+		m.LastModifiedTime, m.LastModifiedById,
+		m.LastModifiedByName, m.TaxRules, m.Id,
+	)
+	return err
+}
+
+// Function recomputes the deposit and amount due of the invoice of the work
+// order from the active deposits of the work order, nothing happens if the
+// work order does not have an invoice yet.
+func (r *WorkOrderInvoiceRepo) UpdateDepositAmountsByOrderId(ctx context.Context, orderId uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    UPDATE
+        work_order_invoices
+    SET
+        deposit = d.total,
+		amount_due = work_order_invoices.total - d.total
+    FROM (
+        SELECT
+            COALESCE(SUM(amount), 0) AS total
+        FROM
+            work_order_deposits
+        WHERE
+            order_id = $1 AND state = $2
+    ) AS d
+    WHERE
+        work_order_invoices.order_id = $1`
+	_, err := r.db.ExecContext(ctx, query, orderId, models.WorkOrderDepositActiveState)
+	return err
+}
+
+// Function reopens the paid invoice so it can be changed or, if the reopened
+// time is not set, locks it again.
+func (r *WorkOrderInvoiceRepo) UpdateReopenedById(ctx context.Context, m *models.WorkOrderInvoice) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    UPDATE
+        work_order_invoices
+    SET
+        reopened_time = $1, reopened_by_id = $2, last_modified_time = $3,
+		last_modified_by_id = $4, last_modified_by_name = $5
+    WHERE
+        id = $6`
+	_, err := r.db.ExecContext(
+		ctx, query,
+		m.ReopenedTime, m.ReopenedById, m.LastModifiedTime,
+		m.LastModifiedById, m.LastModifiedByName, m.Id,
+	)
+	return err
+}
+
 func (r *WorkOrderInvoiceRepo) GetById(ctx context.Context, id uint64) (*models.WorkOrderInvoice, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -130,16 +217,79 @@ func (r *WorkOrderInvoiceRepo) GetById(ctx context.Context, id uint64) (*models.
 
 	query := `
     SELECT
-        id, uuid, tenant_id
+        id, uuid, tenant_id, order_id, invoice_id, invoice_date,
+		associate_name, associate_telephone, client_name, client_telephone,
+		client_email, client_address, invoice_quote_days, invoice_associate_tax,
+		invoice_quote_date, invoice_customers_approval, total_labour,
+		total_materials, other_costs, sub_total, tax, total, deposit, amount_due,
+		payment_amount, payment_date, is_cash, is_cheque, is_debit, is_credit,
+		is_other, client_signature, associate_sign_date, associate_signature,
+		revision_version, created_time, created_by_id, created_by_name,
+		last_modified_time, last_modified_by_id, last_modified_by_name, state,
+		old_id, tax_rules, reopened_time, reopened_by_id
 	FROM
         work_order_invoices
     WHERE
         id = $1`
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&m.Id, &m.Uuid, &m.TenantId,
+		&m.Id, &m.Uuid, &m.TenantId, &m.OrderId, &m.InvoiceId, &m.InvoiceDate,
+		&m.AssociateName, &m.AssociateTelephone, &m.ClientName, &m.ClientTelephone,
+		&m.ClientEmail, &m.ClientAddress, &m.InvoiceQuoteDays, &m.InvoiceAssociateTax,
+		&m.InvoiceQuoteDate, &m.InvoiceCustomersApproval, &m.TotalLabour,
+		&m.TotalMaterials, &m.OtherCosts, &m.SubTotal, &m.Tax, &m.Total, &m.Deposit, &m.AmountDue,
+		&m.PaymentAmount, &m.PaymentDate, &m.IsCash, &m.IsCheque, &m.IsDebit, &m.IsCredit,
+		&m.IsOther, &m.ClientSignature, &m.AssociateSignDate, &m.AssociateSignature,
+		&m.RevisionVersion, &m.CreatedTime, &m.CreatedById, &m.CreatedByName,
+		&m.LastModifiedTime, &m.LastModifiedById, &m.LastModifiedByName, &m.State,
+		&m.OldId, &m.TaxRules, &m.ReopenedTime, &m.ReopenedById,
 	)
 	if err != nil {
-		// CASE 1 OF 2: Cannot find record with that email.
+		// CASE 1 OF 2: Cannot find record with that id.
+		if err == sql.ErrNoRows {
+			return nil, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (r *WorkOrderInvoiceRepo) GetByOrderId(ctx context.Context, orderId uint64) (*models.WorkOrderInvoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	m := new(models.WorkOrderInvoice)
+
+	query := `
+    SELECT
+        id, uuid, tenant_id, order_id, invoice_id, invoice_date,
+		associate_name, associate_telephone, client_name, client_telephone,
+		client_email, client_address, invoice_quote_days, invoice_associate_tax,
+		invoice_quote_date, invoice_customers_approval, total_labour,
+		total_materials, other_costs, sub_total, tax, total, deposit, amount_due,
+		payment_amount, payment_date, is_cash, is_cheque, is_debit, is_credit,
+		is_other, client_signature, associate_sign_date, associate_signature,
+		revision_version, created_time, created_by_id, created_by_name,
+		last_modified_time, last_modified_by_id, last_modified_by_name, state,
+		old_id, tax_rules, reopened_time, reopened_by_id
+	FROM
+        work_order_invoices
+    WHERE
+        order_id = $1`
+	err := r.db.QueryRowContext(ctx, query, orderId).Scan(
+		&m.Id, &m.Uuid, &m.TenantId, &m.OrderId, &m.InvoiceId, &m.InvoiceDate,
+		&m.AssociateName, &m.AssociateTelephone, &m.ClientName, &m.ClientTelephone,
+		&m.ClientEmail, &m.ClientAddress, &m.InvoiceQuoteDays, &m.InvoiceAssociateTax,
+		&m.InvoiceQuoteDate, &m.InvoiceCustomersApproval, &m.TotalLabour,
+		&m.TotalMaterials, &m.OtherCosts, &m.SubTotal, &m.Tax, &m.Total, &m.Deposit, &m.AmountDue,
+		&m.PaymentAmount, &m.PaymentDate, &m.IsCash, &m.IsCheque, &m.IsDebit, &m.IsCredit,
+		&m.IsOther, &m.ClientSignature, &m.AssociateSignDate, &m.AssociateSignature,
+		&m.RevisionVersion, &m.CreatedTime, &m.CreatedById, &m.CreatedByName,
+		&m.LastModifiedTime, &m.LastModifiedById, &m.LastModifiedByName, &m.State,
+		&m.OldId, &m.TaxRules, &m.ReopenedTime, &m.ReopenedById,
+	)
+	if err != nil {
+		// CASE 1 OF 2: Cannot find record with that order id.
 		if err == sql.ErrNoRows {
 			return nil, nil
 		} else { // CASE 2 OF 2: All other errors.
@@ -216,3 +366,147 @@ func (r *WorkOrderInvoiceRepo) InsertOrUpdateById(ctx context.Context, m *models
 	}
 	return r.UpdateById(ctx, m)
 }
+
+// Function keeps the current version of the invoice as the `rev` revision,
+// runs the change to the invoice and recomputes the totals of the invoice in
+// the same transaction. The work order and the invoice rows are locked and
+// the invoice is read again first so concurrent changes to the same invoice
+// are applied one after the other, only the last modified fields of `inv` are
+// kept. If the invoice got locked in the meantime
+// `models.ErrWorkOrderInvoiceLocked` is returned. The line items of the
+// invoice after the change are returned.
+func editWorkOrderInvoice(ctx context.Context, db dbtx, inv *models.WorkOrderInvoice, rev *models.WorkOrderInvoiceRevision, edit func(tx dbtx) error) ([]*models.WorkOrderInvoiceLineItem, error) {
+	var arr []*models.WorkOrderInvoiceLineItem
+	err := runInTx(ctx, db, func(tx dbtx) error {
+		// The work order is locked before the invoice, the same order the
+		// deposits use, since the totals are copied to the work order.
+		query := `
+        SELECT
+            id
+        FROM
+            work_orders
+        WHERE
+            id = $1
+        FOR UPDATE`
+		if _, err := tx.ExecContext(ctx, query, inv.OrderId); err != nil {
+			return err
+		}
+		query = `
+        SELECT
+            id
+        FROM
+            work_order_invoices
+        WHERE
+            id = $1
+        FOR UPDATE`
+		if _, err := tx.ExecContext(ctx, query, inv.Id); err != nil {
+			return err
+		}
+		cur, err := (&WorkOrderInvoiceRepo{db: tx}).GetById(ctx, inv.Id)
+		if err != nil {
+			return err
+		}
+		if cur == nil {
+			return sql.ErrNoRows
+		}
+		if cur.IsLocked() {
+			return models.ErrWorkOrderInvoiceLocked
+		}
+		cur.LastModifiedTime = inv.LastModifiedTime
+		cur.LastModifiedById = inv.LastModifiedById
+		cur.LastModifiedByName = inv.LastModifiedByName
+		*inv = *cur
+
+		lines, err := (&WorkOrderInvoiceLineItemRepo{db: tx}).ListByInvoiceId(ctx, inv.Id)
+		if err != nil {
+			return err
+		}
+		rev.TenantId = inv.TenantId
+		rev.InvoiceId = inv.Id
+		rev.RevisionVersion = inv.RevisionVersion
+		rev.Snapshot = models.NewWorkOrderInvoiceSnapshot(inv, lines)
+		if err := (&WorkOrderInvoiceRevisionRepo{db: tx}).InsertAndIncrementInvoiceVersion(ctx, rev); err != nil {
+			return err
+		}
+		inv.RevisionVersion++
+
+		if err := edit(tx); err != nil {
+			return err
+		}
+
+		// The change may have posted a deposit so the amount due is computed
+		// from the deposit as it is now.
+		query = `
+        SELECT
+            deposit
+        FROM
+            work_order_invoices
+        WHERE
+            id = $1`
+		if err := tx.QueryRowContext(ctx, query, inv.Id).Scan(&inv.Deposit); err != nil {
+			return err
+		}
+
+		arr, err = recomputeWorkOrderInvoiceTotals(ctx, tx, inv)
+		return err
+	})
+	return arr, err
+}
+
+// Function recomputes the totals of the invoice from its line items and the
+// tax rules of the tenant which are effective on the tax date of the invoice,
+// the totals are copied to the work order of the invoice.
+func recomputeWorkOrderInvoiceTotals(ctx context.Context, tx dbtx, inv *models.WorkOrderInvoice) ([]*models.WorkOrderInvoiceLineItem, error) {
+	arr, err := (&WorkOrderInvoiceLineItemRepo{db: tx}).ListByInvoiceId(ctx, inv.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []*models.TenantTaxRule
+	if inv.IsTaxable() {
+		var typeOf int8
+		query := `
+        SELECT
+            type_of
+        FROM
+            work_orders
+        WHERE
+            id = $1`
+		if err := tx.QueryRowContext(ctx, query, inv.OrderId).Scan(&typeOf); err != nil {
+			return nil, err
+		}
+		rules, err = (&TenantTaxRuleRepo{db: tx}).ListEffectiveByTenantId(ctx, inv.TenantId, typeOf, inv.TaxDate(time.Now()))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	inv.ComputeTotals(arr, rules)
+	if err := (&WorkOrderInvoiceRepo{db: tx}).UpdateTotalsById(ctx, inv); err != nil {
+		return nil, err
+	}
+
+	// The work order keeps a copy of the totals which its amount due and
+	// balance owing are computed from.
+	query := `
+    UPDATE
+        work_orders
+    SET
+        invoice_labour_amount = $1, invoice_material_amount = $2,
+		invoice_other_costs_amount = $3, invoice_sub_total_amount = $4,
+		invoice_tax_amount = $5, invoice_total_amount = $6
+    WHERE
+        id = $7`
+	_, err = tx.ExecContext(
+		ctx, query,
+		inv.TotalLabour, inv.TotalMaterials, inv.OtherCosts, inv.SubTotal,
+		inv.Tax, inv.Total, inv.OrderId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := (&WorkOrderRepo{db: tx}).UpdateDepositAmountsById(ctx, inv.OrderId); err != nil {
+		return nil, err
+	}
+	return arr, nil
+}
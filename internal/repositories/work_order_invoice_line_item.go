@@ -0,0 +1,272 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+type WorkOrderInvoiceLineItemRepo struct {
+	db dbtx
+}
+
+func NewWorkOrderInvoiceLineItemRepo(db *sql.DB) *WorkOrderInvoiceLineItemRepo {
+	return &WorkOrderInvoiceLineItemRepo{
+		db: db,
+	}
+}
+
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *WorkOrderInvoiceLineItemRepo) WithTx(tx *sql.Tx) *WorkOrderInvoiceLineItemRepo {
+	return &WorkOrderInvoiceLineItemRepo{
+		db: tx,
+	}
+}
+
+func (r *WorkOrderInvoiceLineItemRepo) Insert(ctx context.Context, m *models.WorkOrderInvoiceLineItem) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    INSERT INTO work_order_invoice_line_items (
+        uuid, tenant_id, invoice_id, sort_number, type_of, quantity,
+		description, unit_price, amount, notes, created_time, created_by_id,
+		created_from_ip, last_modified_time, last_modified_by_id,
+		last_modified_from_ip, old_id
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16,
+		$17
+    ) RETURNING id`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	return stmt.QueryRowContext(
+		ctx,
+		m.Uuid, m.TenantId, m.InvoiceId, m.SortNumber, m.TypeOf, m.Quantity,
+		m.Description, m.UnitPrice, m.Amount, m.Notes, m.CreatedTime, m.CreatedById,
+		m.CreatedFromIP, m.LastModifiedTime, m.LastModifiedById,
+		m.LastModifiedFromIP, m.OldId,
+	).Scan(&m.Id)
+}
+
+func (r *WorkOrderInvoiceLineItemRepo) UpdateById(ctx context.Context, m *models.WorkOrderInvoiceLineItem) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    UPDATE
+        work_order_invoice_line_items
+    SET
+        sort_number = $1, type_of = $2, quantity = $3, description = $4,
+		unit_price = $5, amount = $6, notes = $7, last_modified_time = $8,
+		last_modified_by_id = $9, last_modified_from_ip = $10
+    WHERE
+        id = $11`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(
+		ctx,
+		m.SortNumber, m.TypeOf, m.Quantity, m.Description,
+		m.UnitPrice, m.Amount, m.Notes, m.LastModifiedTime,
+		m.LastModifiedById, m.LastModifiedFromIP, m.Id,
+	)
+	return err
+}
+
+func (r *WorkOrderInvoiceLineItemRepo) GetById(ctx context.Context, id uint64) (*models.WorkOrderInvoiceLineItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	m := new(models.WorkOrderInvoiceLineItem)
+
+	query := `
+    SELECT
+        id, uuid, tenant_id, invoice_id, sort_number, type_of, quantity,
+		description, unit_price, amount, notes, created_time, created_by_id,
+		created_from_ip, last_modified_time, last_modified_by_id,
+		last_modified_from_ip, old_id
+	FROM
+        work_order_invoice_line_items
+    WHERE
+        id = $1`
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&m.Id, &m.Uuid, &m.TenantId, &m.InvoiceId, &m.SortNumber, &m.TypeOf, &m.Quantity,
+		&m.Description, &m.UnitPrice, &m.Amount, &m.Notes, &m.CreatedTime, &m.CreatedById,
+		&m.CreatedFromIP, &m.LastModifiedTime, &m.LastModifiedById,
+		&m.LastModifiedFromIP, &m.OldId,
+	)
+	if err != nil {
+		// CASE 1 OF 2: Cannot find record with that id.
+		if err == sql.ErrNoRows {
+			return nil, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (r *WorkOrderInvoiceLineItemRepo) ListByInvoiceId(ctx context.Context, invoiceId uint64) ([]*models.WorkOrderInvoiceLineItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT
+        id, uuid, tenant_id, invoice_id, sort_number, type_of, quantity,
+		description, unit_price, amount, notes, created_time, created_by_id,
+		created_from_ip, last_modified_time, last_modified_by_id,
+		last_modified_from_ip, old_id
+	FROM
+        work_order_invoice_line_items
+    WHERE
+        invoice_id = $1
+    ORDER BY
+        sort_number ASC, id ASC`
+	rows, err := r.db.QueryContext(ctx, query, invoiceId)
+	if err != nil {
+		return nil, err
+	}
+
+	var arr []*models.WorkOrderInvoiceLineItem
+	defer rows.Close()
+	for rows.Next() {
+		m := new(models.WorkOrderInvoiceLineItem)
+		err := rows.Scan(
+			&m.Id, &m.Uuid, &m.TenantId, &m.InvoiceId, &m.SortNumber, &m.TypeOf, &m.Quantity,
+			&m.Description, &m.UnitPrice, &m.Amount, &m.Notes, &m.CreatedTime, &m.CreatedById,
+			&m.CreatedFromIP, &m.LastModifiedTime, &m.LastModifiedById,
+			&m.LastModifiedFromIP, &m.OldId,
+		)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, m)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	return arr, err
+}
+
+func (r *WorkOrderInvoiceLineItemRepo) UpdateSortNumberById(ctx context.Context, id uint64, sortNumber int16) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    UPDATE
+        work_order_invoice_line_items
+    SET
+        sort_number = $1
+    WHERE
+        id = $2`
+	_, err := r.db.ExecContext(ctx, query, sortNumber, id)
+	return err
+}
+
+func (r *WorkOrderInvoiceLineItemRepo) DeleteById(ctx context.Context, id uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    DELETE FROM
+        work_order_invoice_line_items
+    WHERE
+        id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// Function keeps the current version of the invoice as the `rev` revision,
+// appends the line item to the end of the invoice and recomputes the totals
+// of the invoice in the same transaction.
+func (r *WorkOrderInvoiceLineItemRepo) InsertAndRecomputeInvoice(ctx context.Context, m *models.WorkOrderInvoiceLineItem, inv *models.WorkOrderInvoice, rev *models.WorkOrderInvoiceRevision) ([]*models.WorkOrderInvoiceLineItem, error) {
+	return editWorkOrderInvoice(ctx, r.db, inv, rev, func(tx dbtx) error {
+		query := `
+        SELECT
+            COALESCE(MAX(sort_number), 0) + 1
+        FROM
+            work_order_invoice_line_items
+        WHERE
+            invoice_id = $1`
+		if err := tx.QueryRowContext(ctx, query, inv.Id).Scan(&m.SortNumber); err != nil {
+			return err
+		}
+		return (&WorkOrderInvoiceLineItemRepo{db: tx}).Insert(ctx, m)
+	})
+}
+
+// Function keeps the current version of the invoice as the `rev` revision,
+// updates the line item and recomputes the totals of the invoice in the same
+// transaction.
+func (r *WorkOrderInvoiceLineItemRepo) UpdateByIdAndRecomputeInvoice(ctx context.Context, m *models.WorkOrderInvoiceLineItem, inv *models.WorkOrderInvoice, rev *models.WorkOrderInvoiceRevision) ([]*models.WorkOrderInvoiceLineItem, error) {
+	return editWorkOrderInvoice(ctx, r.db, inv, rev, func(tx dbtx) error {
+		return (&WorkOrderInvoiceLineItemRepo{db: tx}).UpdateById(ctx, m)
+	})
+}
+
+// Function keeps the current version of the invoice as the `rev` revision,
+// saves the new order of the line items, where `ids` are the line items in
+// their new order, and recomputes the totals of the invoice in the same
+// transaction.
+func (r *WorkOrderInvoiceLineItemRepo) UpdateSortNumbersAndRecomputeInvoice(ctx context.Context, ids []uint64, inv *models.WorkOrderInvoice, rev *models.WorkOrderInvoiceRevision) ([]*models.WorkOrderInvoiceLineItem, error) {
+	return editWorkOrderInvoice(ctx, r.db, inv, rev, func(tx dbtx) error {
+		lir := &WorkOrderInvoiceLineItemRepo{db: tx}
+		for i, id := range ids {
+			if err := lir.UpdateSortNumberById(ctx, id, int16(i+1)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Function keeps the current version of the invoice as the `rev` revision,
+// deletes the line item and recomputes the totals of the invoice in the same
+// transaction.
+func (r *WorkOrderInvoiceLineItemRepo) DeleteByIdAndRecomputeInvoice(ctx context.Context, id uint64, inv *models.WorkOrderInvoice, rev *models.WorkOrderInvoiceRevision) ([]*models.WorkOrderInvoiceLineItem, error) {
+	return editWorkOrderInvoice(ctx, r.db, inv, rev, func(tx dbtx) error {
+		return (&WorkOrderInvoiceLineItemRepo{db: tx}).DeleteById(ctx, id)
+	})
+}
+
+// Function inserts the imported line item or updates it if it was already
+// imported, the line items are identified by the invoice and their old id.
+func (r *WorkOrderInvoiceLineItemRepo) InsertOrUpdateByOld(ctx context.Context, m *models.WorkOrderInvoiceLineItem) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    INSERT INTO work_order_invoice_line_items (
+        uuid, tenant_id, invoice_id, sort_number, type_of, quantity,
+		description, unit_price, amount, notes, created_time, created_by_id,
+		created_from_ip, last_modified_time, last_modified_by_id,
+		last_modified_from_ip, old_id
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16,
+		$17
+    ) ON CONFLICT (invoice_id, old_id) WHERE old_id <> 0 DO UPDATE SET
+        sort_number = EXCLUDED.sort_number, type_of = EXCLUDED.type_of,
+		quantity = EXCLUDED.quantity, description = EXCLUDED.description,
+		unit_price = EXCLUDED.unit_price, amount = EXCLUDED.amount,
+		notes = EXCLUDED.notes, last_modified_time = EXCLUDED.last_modified_time,
+		last_modified_by_id = EXCLUDED.last_modified_by_id
+    RETURNING id`
+	return r.db.QueryRowContext(
+		ctx, query,
+		m.Uuid, m.TenantId, m.InvoiceId, m.SortNumber, m.TypeOf, m.Quantity,
+		m.Description, m.UnitPrice, m.Amount, m.Notes, m.CreatedTime, m.CreatedById,
+		m.CreatedFromIP, m.LastModifiedTime, m.LastModifiedById,
+		m.LastModifiedFromIP, m.OldId,
+	).Scan(&m.Id)
+}
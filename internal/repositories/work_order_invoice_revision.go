@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+type WorkOrderInvoiceRevisionRepo struct {
+	db dbtx
+}
+
+func NewWorkOrderInvoiceRevisionRepo(db *sql.DB) *WorkOrderInvoiceRevisionRepo {
+	return &WorkOrderInvoiceRevisionRepo{
+		db: db,
+	}
+}
+
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *WorkOrderInvoiceRevisionRepo) WithTx(tx *sql.Tx) *WorkOrderInvoiceRevisionRepo {
+	return &WorkOrderInvoiceRevisionRepo{
+		db: tx,
+	}
+}
+
+func (r *WorkOrderInvoiceRevisionRepo) InsertAndIncrementInvoiceVersion(ctx context.Context, m *models.WorkOrderInvoiceRevision) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return runInTx(ctx, r.db, func(tx dbtx) error {
+		query := `
+        INSERT INTO work_order_invoice_revisions (
+            tenant_id, invoice_id, revision_version, snapshot, created_time,
+		    created_by_id, created_by_name, created_from_ip
+        ) VALUES (
+            $1, $2, $3, $4, $5, $6, $7, $8
+        ) RETURNING id`
+		err := tx.QueryRowContext(
+			ctx, query,
+			m.TenantId, m.InvoiceId, m.RevisionVersion, m.Snapshot, m.CreatedTime,
+			m.CreatedById, m.CreatedByName, m.CreatedFromIP,
+		).Scan(&m.Id)
+		if err != nil {
+			return err
+		}
+
+		query = `
+        UPDATE
+            work_order_invoices
+        SET
+            revision_version = $1
+        WHERE
+            id = $2`
+		_, err = tx.ExecContext(ctx, query, m.RevisionVersion+1, m.InvoiceId)
+		return err
+	})
+}
+
+const workOrderInvoiceRevisionColumns = `
+        id, tenant_id, invoice_id, revision_version, snapshot, created_time,
+		created_by_id, created_by_name, created_from_ip`
+
+func scanWorkOrderInvoiceRevision(row interface{ Scan(...interface{}) error }) (*models.WorkOrderInvoiceRevision, error) {
+	m := &models.WorkOrderInvoiceRevision{
+		Snapshot: new(models.WorkOrderInvoiceSnapshot),
+	}
+	err := row.Scan(
+		&m.Id, &m.TenantId, &m.InvoiceId, &m.RevisionVersion, m.Snapshot, &m.CreatedTime,
+		&m.CreatedById, &m.CreatedByName, &m.CreatedFromIP,
+	)
+	return m, err
+}
+
+func (r *WorkOrderInvoiceRevisionRepo) ListByInvoiceId(ctx context.Context, invoiceId uint64) ([]*models.WorkOrderInvoiceRevision, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT` + workOrderInvoiceRevisionColumns + `
+    FROM
+        work_order_invoice_revisions
+    WHERE
+        invoice_id = $1
+    ORDER BY
+        revision_version`
+	rows, err := r.db.QueryContext(ctx, query, invoiceId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.WorkOrderInvoiceRevision
+	for rows.Next() {
+		m, err := scanWorkOrderInvoiceRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, m)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	return arr, err
+}
+
+func (r *WorkOrderInvoiceRevisionRepo) GetByInvoiceIdAndRevisionVersion(ctx context.Context, invoiceId uint64, version int16) (*models.WorkOrderInvoiceRevision, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT` + workOrderInvoiceRevisionColumns + `
+    FROM
+        work_order_invoice_revisions
+    WHERE
+        invoice_id = $1 AND revision_version = $2`
+	m, err := scanWorkOrderInvoiceRevision(r.db.QueryRowContext(ctx, query, invoiceId, version))
+	if err != nil {
+		// CASE 1 OF 2: Cannot find record with that version.
+		if err == sql.ErrNoRows {
+			return nil, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return nil, err
+		}
+	}
+	return m, nil
+}
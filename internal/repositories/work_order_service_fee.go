@@ -5,11 +5,13 @@ import (
 	"database/sql"
 	"time"
 
+	null "gopkg.in/guregu/null.v4"
+
 	"github.com/over55/workery-server/internal/models"
 )
 
 type WorkOrderServiceFeeRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewWorkOrderServiceFeeRepo(db *sql.DB) *WorkOrderServiceFeeRepo {
@@ -18,6 +20,14 @@ func NewWorkOrderServiceFeeRepo(db *sql.DB) *WorkOrderServiceFeeRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *WorkOrderServiceFeeRepo) WithTx(tx *sql.Tx) *WorkOrderServiceFeeRepo {
+	return &WorkOrderServiceFeeRepo{
+		db: tx,
+	}
+}
+
 func (r *WorkOrderServiceFeeRepo) Insert(ctx context.Context, m *models.WorkOrderServiceFee) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -29,23 +39,30 @@ func (r *WorkOrderServiceFeeRepo) Insert(ctx context.Context, m *models.WorkOrde
 		last_modified_from_ip, state, old_id
     ) VALUES (
         $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
-    )`
-	stmt, err := r.db.PrepareContext(ctx, query)
-	if err != nil {
-		return err
-	}
-	defer stmt.Close()
-
-	_, err = stmt.ExecContext(
-		ctx,
+    ) RETURNING id`
+	return r.db.QueryRowContext(
+		ctx, query,
 		m.Uuid, m.TenantId, m.Title, m.Description, m.Percentage,
 		m.CreatedTime, m.CreatedById, m.CreatedByName, m.CreatedFromIP,
 		m.LastModifiedTime, m.LastModifiedById, m.LastModifiedByName, m.LastModifiedFromIP,
 		m.State, m.OldId,
-	)
-	return err
+	).Scan(&m.Id)
 }
 
+// The percentage of the service fee which is in effect right now, future
+// dated rates only take effect once their date is reached.
+const workOrderServiceFeeEffectivePercentage = `COALESCE((
+        SELECT
+            r.percentage
+        FROM
+            work_order_service_fee_rates r
+        WHERE
+            r.service_fee_id = work_order_service_fees.id AND r.effective_from <= (now() AT TIME ZONE 'utc')
+        ORDER BY
+            r.effective_from DESC
+        LIMIT 1
+    ), work_order_service_fees.percentage)`
+
 func (r *WorkOrderServiceFeeRepo) UpdateById(ctx context.Context, m *models.WorkOrderServiceFee) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -57,9 +74,9 @@ func (r *WorkOrderServiceFeeRepo) UpdateById(ctx context.Context, m *models.Work
         tenant_id = $1, title = $2, description = $3, state = $4,
 		percentage = $5, created_time = $6, created_by_id = $7,
 		created_from_ip = $8, last_modified_time = $9, last_modified_by_id = $10,
-		last_modified_from_ip = $11,
+		last_modified_from_ip = $11, last_modified_by_name = $12
     WHERE
-        id = $12`
+        id = $13`
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return err
@@ -69,7 +86,7 @@ func (r *WorkOrderServiceFeeRepo) UpdateById(ctx context.Context, m *models.Work
 	_, err = stmt.ExecContext(
 		ctx,
 		m.TenantId, m.Title, m.Description, m.State, m.Percentage, m.CreatedTime, m.CreatedById, m.CreatedFromIP,
-		m.LastModifiedTime, m.LastModifiedById, m.LastModifiedFromIP, m.Id,
+		m.LastModifiedTime, m.LastModifiedById, m.LastModifiedFromIP, m.LastModifiedByName, m.Id,
 	)
 	return err
 }
@@ -82,17 +99,18 @@ func (r *WorkOrderServiceFeeRepo) GetById(ctx context.Context, id uint64) (*mode
 
 	query := `
     SELECT
-        id, uuid, tenant_id, title, description, percentage, created_time,
-		created_by_id, created_from_ip, last_modified_time, last_modified_by_id,
-		last_modified_from_ip, state
+        id, uuid, tenant_id, title, description, ` + workOrderServiceFeeEffectivePercentage + `, created_time,
+		created_by_id, created_by_name, created_from_ip, last_modified_time, last_modified_by_id,
+		last_modified_by_name, last_modified_from_ip, state, old_id
 	FROM
         work_order_service_fees
     WHERE
         id = $1`
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&m.Id, &m.Uuid, &m.TenantId, &m.Title, &m.Description, &m.State,
-		&m.Percentage, &m.CreatedTime, &m.CreatedById, &m.CreatedFromIP,
-		&m.LastModifiedTime, &m.LastModifiedById, &m.LastModifiedFromIP,
+		&m.Id, &m.Uuid, &m.TenantId, &m.Title, &m.Description, &m.Percentage,
+		&m.CreatedTime, &m.CreatedById, &m.CreatedByName, &m.CreatedFromIP,
+		&m.LastModifiedTime, &m.LastModifiedById, &m.LastModifiedByName,
+		&m.LastModifiedFromIP, &m.State, &m.OldId,
 	)
 	if err != nil {
 		// CASE 1 OF 2: Cannot find record with that email.
@@ -173,3 +191,189 @@ func (r *WorkOrderServiceFeeRepo) InsertOrUpdateById(ctx context.Context, m *mod
 	}
 	return r.UpdateById(ctx, m)
 }
+
+func (r *WorkOrderServiceFeeRepo) insertRate(ctx context.Context, m *models.WorkOrderServiceFeeRate) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    INSERT INTO work_order_service_fee_rates (
+        tenant_id, service_fee_id, percentage, effective_from, created_time,
+		created_by_id, created_by_name, created_from_ip
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6, $7, $8
+    )
+	ON CONFLICT (service_fee_id, effective_from)
+	DO UPDATE SET
+	    percentage = EXCLUDED.percentage
+	RETURNING id`
+	return r.db.QueryRowContext(
+		ctx, query,
+		m.TenantId, m.ServiceFeeId, m.Percentage, m.EffectiveFrom, m.CreatedTime,
+		m.CreatedById, m.CreatedByName, m.CreatedFromIP,
+	).Scan(&m.Id)
+}
+
+// Function inserts the service fee along with the rate it starts with.
+func (r *WorkOrderServiceFeeRepo) InsertWithRate(ctx context.Context, m *models.WorkOrderServiceFee, rate *models.WorkOrderServiceFeeRate) error {
+	return runInTx(ctx, r.db, func(tx dbtx) error {
+		txr := &WorkOrderServiceFeeRepo{db: tx}
+		if err := txr.Insert(ctx, m); err != nil {
+			return err
+		}
+		rate.ServiceFeeId = m.Id
+		return txr.insertRate(ctx, rate)
+	})
+}
+
+// Function updates the service fee and, if the rate is set, schedules the new
+// percentage starting at the effective date of the rate.
+func (r *WorkOrderServiceFeeRepo) UpdateWithRate(ctx context.Context, m *models.WorkOrderServiceFee, rate *models.WorkOrderServiceFeeRate) error {
+	return runInTx(ctx, r.db, func(tx dbtx) error {
+		txr := &WorkOrderServiceFeeRepo{db: tx}
+		if err := txr.UpdateById(ctx, m); err != nil {
+			return err
+		}
+		if rate == nil {
+			return nil
+		}
+		rate.ServiceFeeId = m.Id
+		return txr.insertRate(ctx, rate)
+	})
+}
+
+func (r *WorkOrderServiceFeeRepo) ListRatesById(ctx context.Context, id uint64) ([]*models.WorkOrderServiceFeeRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT
+        id, tenant_id, service_fee_id, percentage, effective_from, created_time,
+		created_by_id, created_by_name, created_from_ip
+    FROM
+        work_order_service_fee_rates
+    WHERE
+        service_fee_id = $1
+    ORDER BY
+        effective_from DESC`
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.WorkOrderServiceFeeRate
+	for rows.Next() {
+		m := new(models.WorkOrderServiceFeeRate)
+		err := rows.Scan(
+			&m.Id, &m.TenantId, &m.ServiceFeeId, &m.Percentage, &m.EffectiveFrom, &m.CreatedTime,
+			&m.CreatedById, &m.CreatedByName, &m.CreatedFromIP,
+		)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, m)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	return arr, err
+}
+
+// Function returns the percentage of the service fee which was in effect at
+// the time, this is the percentage which must be used to compute the service
+// fee of a work order invoiced at that time.
+func (r *WorkOrderServiceFeeRepo) GetPercentageAtById(ctx context.Context, id uint64, at time.Time) (models.Percent, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var percentage models.Percent
+
+	query := `
+    SELECT
+        COALESCE((
+            SELECT
+                r.percentage
+            FROM
+                work_order_service_fee_rates r
+            WHERE
+                r.service_fee_id = f.id AND r.effective_from <= $2
+            ORDER BY
+                r.effective_from DESC
+            LIMIT 1
+        ), f.percentage)
+    FROM
+        work_order_service_fees f
+    WHERE
+        f.id = $1`
+	err := r.db.QueryRowContext(ctx, query, id, at).Scan(&percentage)
+	if err != nil {
+		// CASE 1 OF 2: Cannot find record with that id.
+		if err == sql.ErrNoRows {
+			return 0, nil
+		} else { // CASE 2 OF 2: All other errors.
+			return 0, err
+		}
+	}
+	return percentage, nil
+}
+
+// Function returns how the open work orders using the service fee would be
+// affected if the percentage changed at the effective date. Open work orders
+// are the ones which were not completed yet along with the completed ones
+// which were not invoiced before the effective date.
+func (r *WorkOrderServiceFeeRepo) PreviewChangeById(ctx context.Context, id uint64, percentage models.Percent, effectiveFrom time.Time) (*models.WorkOrderServiceFeeChangePreview, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT
+        associate_id, invoice_labour_amount, invoice_service_fee_amount
+    FROM
+        work_orders
+    WHERE
+        invoice_service_fee_id = $1
+		AND (
+		    state IN ($2, $3, $4, $5)
+			OR (state = $6 AND (invoice_date IS NULL OR invoice_date >= $7))
+		)`
+	rows, err := r.db.QueryContext(
+		ctx, query, id,
+		models.WorkOrderNewState, models.WorkOrderPendingState,
+		models.WorkOrderOngoingState, models.WorkOrderInProgressState,
+		models.WorkOrderCompletedButUnpaidState, effectiveFrom,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := &models.WorkOrderServiceFeeChangePreview{
+		ServiceFeeId:  id,
+		Percentage:    percentage,
+		EffectiveFrom: effectiveFrom,
+	}
+	associates := make(map[int64]bool)
+	for rows.Next() {
+		var (
+			associateId null.Int
+			labour      models.Money
+			fee         models.Money
+		)
+		if err := rows.Scan(&associateId, &labour, &fee); err != nil {
+			return nil, err
+		}
+		res.OrderCount++
+		if associateId.Valid {
+			associates[associateId.Int64] = true
+		}
+		res.CurrentServiceFeeAmount += fee
+		res.ProjectedServiceFeeAmount += models.ComputeServiceFee(labour, percentage)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	res.AssociateCount = uint64(len(associates))
+	return res, nil
+}
@@ -9,7 +9,7 @@ import (
 )
 
 type WorkOrderSkillSetRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewWorkOrderSkillSetRepo(db *sql.DB) *WorkOrderSkillSetRepo {
@@ -18,6 +18,14 @@ func NewWorkOrderSkillSetRepo(db *sql.DB) *WorkOrderSkillSetRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *WorkOrderSkillSetRepo) WithTx(tx *sql.Tx) *WorkOrderSkillSetRepo {
+	return &WorkOrderSkillSetRepo{
+		db: tx,
+	}
+}
+
 func (r *WorkOrderSkillSetRepo) Insert(ctx context.Context, m *models.WorkOrderSkillSet) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
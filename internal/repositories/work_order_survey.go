@@ -0,0 +1,289 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/models"
+)
+
+type WorkOrderSurveyRepo struct {
+	db dbtx
+}
+
+func NewWorkOrderSurveyRepo(db *sql.DB) *WorkOrderSurveyRepo {
+	return &WorkOrderSurveyRepo{
+		db: db,
+	}
+}
+
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *WorkOrderSurveyRepo) WithTx(tx *sql.Tx) *WorkOrderSurveyRepo {
+	return &WorkOrderSurveyRepo{
+		db: tx,
+	}
+}
+
+const workOrderSurveyColumns = `
+        id, uuid, tenant_id, order_id, associate_id, was_survey_conducted,
+		no_survey_conducted_reason, no_survey_conducted_reason_other,
+		was_job_satisfactory, was_job_finished_on_time_and_on_budget,
+		was_associate_punctual, was_associate_professional,
+		would_customer_refer_our_organization, score, associate_score,
+		created_time, created_by_id, created_by_name, created_from_ip`
+
+func (r *WorkOrderSurveyRepo) Create(ctx context.Context, m *models.WorkOrderSurvey, order *models.WorkOrder, task *models.TaskItem) error {
+	return runInTx(ctx, r.db, func(tx dbtx) error {
+		sr := &WorkOrderSurveyRepo{db: tx}
+		wor := &WorkOrderRepo{db: tx}
+
+		if task != nil {
+			tr := &TaskItemRepo{db: tx}
+			if err := tr.closeById(ctx, task); err != nil {
+				return err
+			}
+			order.LatestPendingTaskId = null.Int{}
+		}
+		if err := wor.updateSurveyById(ctx, order); err != nil {
+			return err
+		}
+		if err := sr.insert(ctx, m); err != nil {
+			return err
+		}
+		if !m.AssociateId.Valid {
+			return nil
+		}
+		score, err := sr.updateAssociateScore(ctx, uint64(m.AssociateId.Int64))
+		if err != nil {
+			return err
+		}
+		m.AssociateScore = null.FloatFrom(score)
+		return sr.updateAssociateScoreById(ctx, m.Id, score)
+	})
+}
+
+func (r *WorkOrderSurveyRepo) insert(ctx context.Context, m *models.WorkOrderSurvey) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    INSERT INTO work_order_surveys (
+        uuid, tenant_id, order_id, associate_id, was_survey_conducted,
+		no_survey_conducted_reason, no_survey_conducted_reason_other,
+		was_job_satisfactory, was_job_finished_on_time_and_on_budget,
+		was_associate_punctual, was_associate_professional,
+		would_customer_refer_our_organization, score, created_time,
+		created_by_id, created_by_name, created_from_ip
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
+    ) RETURNING id`
+	return r.db.QueryRowContext(
+		ctx, query,
+		m.Uuid, m.TenantId, m.OrderId, m.AssociateId, m.WasSurveyConducted,
+		m.NoSurveyConductedReason, m.NoSurveyConductedReasonOther,
+		m.WasJobSatisfactory, m.WasJobFinishedOnTimeAndOnBudget,
+		m.WasAssociatePunctual, m.WasAssociateProfessional,
+		m.WouldCustomerReferOurOrganization, m.Score, m.CreatedTime,
+		m.CreatedById, m.CreatedByName, m.CreatedFromIP,
+	).Scan(&m.Id)
+}
+
+// Function recomputes the score of the associate as the average job score
+// of the latest conducted surveys, only the most recent submission of every
+// work order counts.
+func (r *WorkOrderSurveyRepo) updateAssociateScore(ctx context.Context, associateId uint64) (float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var score float64
+	query := `
+    UPDATE
+        associates
+    SET
+        score = (
+		    SELECT
+			    COALESCE(AVG(recent.score), 0)
+			FROM (
+			    SELECT
+				    latest.score
+				FROM (
+				    SELECT DISTINCT ON (order_id)
+					    score, was_survey_conducted, created_time
+					FROM
+					    work_order_surveys
+					WHERE
+					    associate_id = $1
+					ORDER BY
+					    order_id, created_time DESC, id DESC
+				) AS latest
+				WHERE
+				    latest.was_survey_conducted = TRUE
+				ORDER BY
+				    latest.created_time DESC
+				LIMIT $2
+			) AS recent
+		)
+    WHERE
+        id = $1
+    RETURNING
+        score`
+	err := r.db.QueryRowContext(ctx, query, associateId, models.AssociateScoreSurveyCount).Scan(&score)
+	return score, err
+}
+
+func (r *WorkOrderSurveyRepo) updateAssociateScoreById(ctx context.Context, id uint64, score float64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    UPDATE
+        work_order_surveys
+    SET
+        associate_score = $1
+    WHERE
+        id = $2`
+	_, err := r.db.ExecContext(ctx, query, score, id)
+	return err
+}
+
+func (r *WorkOrderSurveyRepo) ListByOrderId(ctx context.Context, orderId uint64) ([]*models.WorkOrderSurvey, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+    SELECT` + workOrderSurveyColumns + `
+    FROM
+        work_order_surveys
+    WHERE
+        order_id = $1
+    ORDER BY
+        created_time DESC, id DESC`
+	return r.queryWorkOrderSurveys(ctx, query, orderId)
+}
+
+func (r *WorkOrderSurveyRepo) ListByAssociateId(ctx context.Context, associateId uint64, from time.Time, to time.Time) ([]*models.WorkOrderSurvey, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	values := []interface{}{associateId}
+	query := `
+    SELECT` + workOrderSurveyColumns + `
+    FROM
+        work_order_surveys
+    WHERE
+        associate_id = $1`
+	if !from.IsZero() {
+		values = append(values, from)
+		query += ` AND created_time >= $` + strconv.Itoa(len(values))
+	}
+	if !to.IsZero() {
+		values = append(values, to)
+		query += ` AND created_time < $` + strconv.Itoa(len(values))
+	}
+	query += ` ORDER BY created_time DESC, id DESC`
+	return r.queryWorkOrderSurveys(ctx, query, values...)
+}
+
+func (r *WorkOrderSurveyRepo) queryWorkOrderSurveys(ctx context.Context, query string, values ...interface{}) ([]*models.WorkOrderSurvey, error) {
+	rows, err := r.db.QueryContext(ctx, query, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.WorkOrderSurvey
+	for rows.Next() {
+		m := new(models.WorkOrderSurvey)
+		err := rows.Scan(
+			&m.Id, &m.Uuid, &m.TenantId, &m.OrderId, &m.AssociateId, &m.WasSurveyConducted,
+			&m.NoSurveyConductedReason, &m.NoSurveyConductedReasonOther,
+			&m.WasJobSatisfactory, &m.WasJobFinishedOnTimeAndOnBudget,
+			&m.WasAssociatePunctual, &m.WasAssociateProfessional,
+			&m.WouldCustomerReferOurOrganization, &m.Score, &m.AssociateScore,
+			&m.CreatedTime, &m.CreatedById, &m.CreatedByName, &m.CreatedFromIP,
+		)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, m)
+	}
+	return arr, rows.Err()
+}
+
+func (r *WorkOrderSurveyRepo) ListSatisfactionByTenantId(ctx context.Context, tenantId uint64, from time.Time, to time.Time) ([]*models.AssociateSatisfaction, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	values := []interface{}{tenantId}
+	filter := ``
+	if !from.IsZero() {
+		values = append(values, from)
+		filter += ` AND created_time >= $` + strconv.Itoa(len(values))
+	}
+	if !to.IsZero() {
+		values = append(values, to)
+		filter += ` AND created_time < $` + strconv.Itoa(len(values))
+	}
+
+	query := `
+    SELECT
+        a.id, COALESCE(a.name, ''), COALESCE(a.lexical_name, ''), a.score,
+		COUNT(s.order_id),
+		COALESCE(AVG(s.score), 0),
+		COUNT(*) FILTER (WHERE s.was_job_satisfactory),
+		COUNT(*) FILTER (WHERE s.was_job_finished_on_time_and_on_budget),
+		COUNT(*) FILTER (WHERE s.was_associate_punctual),
+		COUNT(*) FILTER (WHERE s.was_associate_professional),
+		COUNT(*) FILTER (WHERE s.would_customer_refer_our_organization)
+    FROM (
+        SELECT DISTINCT ON (order_id)
+            order_id, associate_id, was_survey_conducted, score,
+			was_job_satisfactory, was_job_finished_on_time_and_on_budget,
+			was_associate_punctual, was_associate_professional,
+			would_customer_refer_our_organization
+        FROM
+            work_order_surveys
+        WHERE
+            tenant_id = $1 AND associate_id IS NOT NULL` + filter + `
+        ORDER BY
+            order_id, created_time DESC, id DESC
+    ) AS s
+    INNER JOIN
+        associates AS a ON a.id = s.associate_id
+    WHERE
+        s.was_survey_conducted = TRUE
+    GROUP BY
+        a.id, a.name, a.lexical_name, a.score
+    ORDER BY
+        a.lexical_name ASC`
+	rows, err := r.db.QueryContext(ctx, query, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arr []*models.AssociateSatisfaction
+	for rows.Next() {
+		m := new(models.AssociateSatisfaction)
+		err := rows.Scan(
+			&m.AssociateId, &m.AssociateName, &m.AssociateLexicalName, &m.Score,
+			&m.SurveyCount,
+			&m.AverageScore,
+			&m.WasJobSatisfactoryCount,
+			&m.WasJobFinishedOnTimeAndOnBudgetCount,
+			&m.WasAssociatePunctualCount,
+			&m.WasAssociateProfessionalCount,
+			&m.WouldCustomerReferOurOrganizationCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, m)
+	}
+	return arr, rows.Err()
+}
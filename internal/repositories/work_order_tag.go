@@ -9,7 +9,7 @@ import (
 )
 
 type WorkOrderTagRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewWorkOrderTagRepo(db *sql.DB) *WorkOrderTagRepo {
@@ -18,6 +18,14 @@ func NewWorkOrderTagRepo(db *sql.DB) *WorkOrderTagRepo {
 	}
 }
 
+// Function returns a copy of the repository which will run all of its
+// queries inside the transaction.
+func (r *WorkOrderTagRepo) WithTx(tx *sql.Tx) *WorkOrderTagRepo {
+	return &WorkOrderTagRepo{
+		db: tx,
+	}
+}
+
 func (r *WorkOrderTagRepo) Insert(ctx context.Context, m *models.WorkOrderTag) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
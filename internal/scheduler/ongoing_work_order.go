@@ -0,0 +1,165 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/utils"
+)
+
+// createOngoingWorkOrderJobs creates the jobs of the active ongoing work
+// orders which fall within the next `OngoingWorkOrderGenerateAheadDays` days.
+// Occurrences when the associate is away are skipped and jobs are never
+// created for past dates.
+func (s *Scheduler) createOngoingWorkOrderJobs(ctx context.Context, now time.Time) (int, error) {
+	horizon := now.AddDate(0, 0, models.OngoingWorkOrderGenerateAheadDays)
+
+	var count int
+	for {
+		ids, err := s.ongoingWorkOrderRepo.ListIdsDueForGeneration(ctx, horizon, generationBatchSize)
+		if err != nil {
+			return count, err
+		}
+		for _, id := range ids {
+			m, err := s.ongoingWorkOrderRepo.GetById(ctx, id)
+			if err != nil {
+				return count, err
+			}
+			if m == nil { // Defensive code
+				continue
+			}
+			n, err := s.createOngoingWorkOrderJobsFor(ctx, m, now, horizon)
+			if err != nil {
+				return count, err
+			}
+			count += n
+		}
+
+		// Every ongoing work order in the batch is now generated up to the
+		// horizon so the next query returns the following ones.
+		if len(ids) < generationBatchSize {
+			return count, nil
+		}
+	}
+}
+
+func (s *Scheduler) createOngoingWorkOrderJobsFor(ctx context.Context, m *models.OngoingWorkOrder, now time.Time, horizon time.Time) (int, error) {
+	until := horizon
+	if m.RecurrenceEndDate.Valid && m.RecurrenceEndDate.Time.Before(until) {
+		until = m.RecurrenceEndDate.Time
+	}
+
+	rule, err := models.ParseRecurrenceRule(m.RecurrenceRule)
+	if err != nil {
+		// The rule is validated when saved so this only happens with rules
+		// imported from elsewhere, mark it as generated so it is not picked
+		// up again on every run.
+		log.Println("WARNING: scheduler|ParseRecurrenceRule|id:", m.Id, "|err:", err.Error())
+		return 0, s.ongoingWorkOrderRepo.InsertOccurrences(ctx, m, nil, until)
+	}
+
+	tenant, err := s.tenantRepo.GetById(ctx, m.TenantId)
+	if err != nil {
+		return 0, err
+	}
+	loc := time.UTC
+	if tenant != nil {
+		if l, err := utils.GetTimezoneLocation(tenant.Timezone); err == nil {
+			loc = l
+		}
+	}
+
+	// Occurrences are created after the last generated one and never before
+	// the start of today.
+	start := m.RecurrenceStartDate.Time.In(loc)
+	after := start.Add(-time.Nanosecond)
+	if m.GeneratedUntil.Valid && m.GeneratedUntil.Time.After(after) {
+		after = m.GeneratedUntil.Time
+	}
+	y, mo, d := now.In(loc).Date()
+	if today := time.Date(y, mo, d, 0, 0, 0, 0, loc); after.Before(today) {
+		after = today.Add(-time.Nanosecond)
+	}
+
+	var arr []*models.OngoingWorkOrderOccurrence
+	for _, t := range rule.Occurrences(start, after, until) {
+		if m.AssociateId.Valid {
+			isAway, err := s.associateAwayLogRepo.CheckIfAwayByAssociateId(ctx, uint64(m.AssociateId.Int64), t)
+			if err != nil {
+				return 0, err
+			}
+			if isAway {
+				continue
+			}
+		}
+		arr = append(arr, newOngoingWorkOrderOccurrence(m, t, now))
+	}
+
+	if err := s.ongoingWorkOrderRepo.InsertOccurrences(ctx, m, arr, until); err != nil {
+		return 0, err
+	}
+	return len(arr), nil
+}
+
+// newOngoingWorkOrderOccurrence returns the job of the ongoing work order
+// starting at `t` along with its first task, the job needs an associate to be
+// assigned first when the ongoing work order does not have one.
+func newOngoingWorkOrderOccurrence(m *models.OngoingWorkOrder, t time.Time, now time.Time) *models.OngoingWorkOrderOccurrence {
+	order := &models.WorkOrder{
+		Uuid:                 uuid.NewString(),
+		TenantId:             m.TenantId,
+		CustomerId:           m.CustomerId,
+		CustomerName:         m.CustomerName.ValueOrZero(),
+		CustomerLexicalName:  m.CustomerLexicalName.ValueOrZero(),
+		AssociateId:          m.AssociateId,
+		AssociateName:        m.AssociateName,
+		AssociateLexicalName: m.AssociateLexicalName,
+		Description:          m.Description,
+		IsOngoing:            true,
+		IsHomeSupportService: m.IsHomeSupportService,
+		StartDate:            t,
+		TypeOf:               m.TypeOf,
+		IndexedText:          m.Description,
+		State:                models.WorkOrderNewState,
+		Currency:             models.DefaultCurrency,
+		CreatedTime:          now,
+		LastModifiedTime:     now,
+		OngoingWorkOrderId:   null.IntFrom(int64(m.Id)),
+	}
+
+	var typeOf int8 = models.TaskAssignAssociateTypeOf
+	if m.AssociateId.Valid {
+		order.State = models.WorkOrderOngoingState
+		order.AssignmentDate = null.TimeFrom(now)
+		typeOf = models.TaskUpdateOngoingJobTypeOf
+	}
+
+	task := &models.TaskItem{
+		Uuid:                 uuid.NewString(),
+		TenantId:             m.TenantId,
+		TypeOf:               typeOf,
+		Title:                models.TaskItemTypeOfTitles[typeOf],
+		Description:          m.Description,
+		DueDate:              t,
+		OngoingOrderId:       order.OngoingWorkOrderId,
+		CreatedTime:          now,
+		LastModifiedTime:     now,
+		State:                models.TaskActiveState,
+		CustomerId:           null.IntFrom(int64(m.CustomerId)),
+		CustomerName:         m.CustomerName,
+		CustomerLexicalName:  m.CustomerLexicalName,
+		AssociateId:          m.AssociateId,
+		AssociateName:        m.AssociateName,
+		AssociateLexicalName: m.AssociateLexicalName,
+		OrderTypeOf:          m.TypeOf,
+	}
+	return &models.OngoingWorkOrderOccurrence{
+		Order: order,
+		Task:  task,
+	}
+}
@@ -0,0 +1,228 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/models"
+	"github.com/over55/workery-server/internal/repositories"
+)
+
+const (
+	DefaultInterval = 5 * time.Minute
+
+	// The key of the Postgres advisory lock which is held by the replica
+	// running the jobs, every other replica skips the jobs until it is
+	// released.
+	leaderLockKey int64 = 420042
+
+	// The number of work orders which get a follow up task per query.
+	followUpBatchSize = 100
+
+	// The number of ongoing work orders which get their jobs created per
+	// query.
+	generationBatchSize = 100
+)
+
+// Scheduler periodically expires the away logs of the associates, creates
+// the upcoming jobs of the ongoing work orders and the follow up tasks of the
+// assigned work orders, flags the overdue tasks and escalates the tasks which
+// are overdue for too long. It can run inside the `serve` command or by itself with the
+// `worker` command, when there are multiple replicas only the one holding
+// the advisory lock runs the jobs.
+type Scheduler struct {
+	db                   *sql.DB
+	interval             time.Duration
+	associateAwayLogRepo models.AssociateAwayLogRepository
+	ongoingWorkOrderRepo models.OngoingWorkOrderRepository
+	taskItemRepo         models.TaskItemRepository
+	tenantRepo           models.TenantRepository
+	workOrderRepo        models.WorkOrderRepository
+
+	// The dedicated connection holding the advisory lock while this replica
+	// is the leader, the lock is released if the connection is lost.
+	leaderConn *sql.Conn
+}
+
+func New(db *sql.DB, interval time.Duration) *Scheduler {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Scheduler{
+		db:                   db,
+		interval:             interval,
+		associateAwayLogRepo: repositories.NewAssociateAwayLogRepo(db),
+		ongoingWorkOrderRepo: repositories.NewOngoingWorkOrderRepo(db),
+		taskItemRepo:         repositories.NewTaskItemRepo(db),
+		tenantRepo:           repositories.NewTenantRepo(db),
+		workOrderRepo:        repositories.NewWorkOrderRepo(db),
+	}
+}
+
+// Run executes the jobs on every interval until the context is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	defer s.releaseLeadership()
+
+	for {
+		s.tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) tick(ctx context.Context) {
+	isLeader, err := s.acquireLeadership(ctx)
+	if err != nil {
+		log.Println("WARNING: scheduler|acquireLeadership|err:", err.Error())
+		return
+	}
+	if !isLeader {
+		return
+	}
+	if err := s.RunOnce(ctx); err != nil {
+		log.Println("WARNING: scheduler|RunOnce|err:", err.Error())
+	}
+}
+
+// RunOnce executes every job a single time, it does not check for the
+// leadership so the caller must make sure no other replica is running it.
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	now := time.Now()
+
+	expired, err := s.associateAwayLogRepo.ExpireByUntilDate(ctx, now)
+	if err != nil {
+		return err
+	}
+	generated, err := s.createOngoingWorkOrderJobs(ctx, now)
+	if err != nil {
+		return err
+	}
+	created, err := s.createFollowUpTasks(ctx, now)
+	if err != nil {
+		return err
+	}
+	flagged, err := s.taskItemRepo.UpdateOverdue(ctx, now)
+	if err != nil {
+		return err
+	}
+	escalated, err := s.taskItemRepo.EscalateOverdue(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	if expired > 0 || generated > 0 || created > 0 || flagged > 0 || escalated > 0 {
+		log.Printf("Scheduler expired %d away logs, created %d ongoing jobs and %d follow up tasks, flagged %d overdue tasks and escalated %d tasks\n", expired, generated, created, flagged, escalated)
+	}
+	return nil
+}
+
+func (s *Scheduler) createFollowUpTasks(ctx context.Context, now time.Time) (int, error) {
+	var count int
+	for {
+		ids, err := s.workOrderRepo.ListIdsDueForFollowUp(ctx, now, followUpBatchSize)
+		if err != nil {
+			return count, err
+		}
+		for _, id := range ids {
+			order, err := s.workOrderRepo.GetById(ctx, id)
+			if err != nil {
+				return count, err
+			}
+			if order == nil { // Defensive code
+				continue
+			}
+			if err := s.taskItemRepo.InsertForWorkOrder(ctx, newFollowUpTaskItem(order, now)); err != nil {
+				return count, err
+			}
+			count++
+		}
+
+		// Every work order in the batch now has an open task so the next
+		// query returns the following work orders.
+		if len(ids) < followUpBatchSize {
+			return count, nil
+		}
+	}
+}
+
+func newFollowUpTaskItem(order *models.WorkOrder, now time.Time) *models.TaskItem {
+	var typeOf int8 = models.TaskFollowUpIsJobCompleteTypeOf
+	if order.State == models.WorkOrderOngoingState {
+		typeOf = models.TaskUpdateOngoingJobTypeOf
+	}
+	return &models.TaskItem{
+		Uuid:                 uuid.NewString(),
+		TenantId:             order.TenantId,
+		TypeOf:               typeOf,
+		Title:                models.TaskItemTypeOfTitles[typeOf],
+		Description:          order.Description,
+		DueDate:              now,
+		OrderId:              order.Id,
+		OngoingOrderId:       order.OngoingWorkOrderId,
+		CreatedTime:          now,
+		LastModifiedTime:     now,
+		State:                models.TaskActiveState,
+		CustomerId:           null.IntFrom(int64(order.CustomerId)),
+		CustomerName:         null.StringFrom(order.CustomerName),
+		CustomerLexicalName:  null.StringFrom(order.CustomerLexicalName),
+		AssociateId:          order.AssociateId,
+		AssociateName:        order.AssociateName,
+		AssociateLexicalName: order.AssociateLexicalName,
+		OrderTypeOf:          order.TypeOf,
+	}
+}
+
+// acquireLeadership returns true if this replica holds the advisory lock,
+// the lock is attempted on every tick so another replica takes over when
+// the leader goes away.
+func (s *Scheduler) acquireLeadership(ctx context.Context) (bool, error) {
+	if s.leaderConn != nil {
+		if err := s.leaderConn.PingContext(ctx); err == nil {
+			return true, nil
+		}
+		// The lock belonged to the session which is gone.
+		s.leaderConn.Close()
+		s.leaderConn = nil
+	}
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	var isLocked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", leaderLockKey).Scan(&isLocked); err != nil {
+		conn.Close()
+		return false, err
+	}
+	if !isLocked {
+		conn.Close()
+		return false, nil
+	}
+	s.leaderConn = conn
+	return true, nil
+}
+
+func (s *Scheduler) releaseLeadership() {
+	if s.leaderConn == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := s.leaderConn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", leaderLockKey); err != nil {
+		log.Println("WARNING: scheduler|releaseLeadership|err:", err.Error())
+	}
+	s.leaderConn.Close()
+	s.leaderConn = nil
+}
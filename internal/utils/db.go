@@ -27,3 +27,28 @@ func ConnectDB(databaseHost, databasePort, databaseUser, databasePassword, datab
 	}
 	return dbInstance, nil
 }
+
+// Function connects to the database with the session `TimeZone` set, this
+// controls the offset which the database uses when returning `timestamptz`
+// values to us.
+func ConnectDBWithTimezone(databaseHost, databasePort, databaseUser, databasePassword, databaseName, databaseSchemaName, timezone string) (*sql.DB, error) {
+	psqlInfo := fmt.Sprintf("host=%s port=%s user=%s "+"password=%s dbname=%s sslmode=disable search_path=%s timezone=%s",
+		databaseHost,
+		databasePort,
+		databaseUser,
+		databasePassword,
+		databaseName,
+		databaseSchemaName,
+		timezone,
+	)
+
+	dbInstance, err := sql.Open("postgres", psqlInfo)
+	if err != nil {
+		return nil, err
+	}
+	err = dbInstance.Ping()
+	if err != nil {
+		return nil, err
+	}
+	return dbInstance, nil
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+// ICalendarEvent is a single `VEVENT` of the calendar. All day events use the
+// date of `Start` and `End` in their own location and `End` is exclusive.
+type ICalendarEvent struct {
+	Uid         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+}
+
+// ICalendar is a minimal iCalendar (RFC 5545) writer which supports the
+// events needed by the calendar feeds, it exists so we do not depend on a
+// third-party library.
+type ICalendar struct {
+	Name   string
+	events []*ICalendarEvent
+}
+
+func NewICalendar(name string) *ICalendar {
+	return &ICalendar{
+		Name: name,
+	}
+}
+
+func (c *ICalendar) AddEvent(e *ICalendarEvent) {
+	c.events = append(c.events, e)
+}
+
+// Function returns the calendar as a `text/calendar` document.
+func (c *ICalendar) Bytes(now time.Time) []byte {
+	var b bytes.Buffer
+	writeICalendarLine(&b, "BEGIN:VCALENDAR")
+	writeICalendarLine(&b, "VERSION:2.0")
+	writeICalendarLine(&b, "PRODID:-//Workery//Workery Server//EN")
+	writeICalendarLine(&b, "CALSCALE:GREGORIAN")
+	writeICalendarLine(&b, "METHOD:PUBLISH")
+	writeICalendarLine(&b, "X-WR-CALNAME:"+escapeICalendarText(c.Name))
+
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, e := range c.events {
+		writeICalendarLine(&b, "BEGIN:VEVENT")
+		writeICalendarLine(&b, "UID:"+escapeICalendarText(e.Uid))
+		writeICalendarLine(&b, "DTSTAMP:"+stamp)
+		if e.AllDay {
+			writeICalendarLine(&b, "DTSTART;VALUE=DATE:"+e.Start.Format("20060102"))
+			writeICalendarLine(&b, "DTEND;VALUE=DATE:"+e.End.Format("20060102"))
+		} else {
+			writeICalendarLine(&b, "DTSTART:"+e.Start.UTC().Format("20060102T150405Z"))
+			writeICalendarLine(&b, "DTEND:"+e.End.UTC().Format("20060102T150405Z"))
+		}
+		writeICalendarLine(&b, "SUMMARY:"+escapeICalendarText(e.Summary))
+		if e.Description != "" {
+			writeICalendarLine(&b, "DESCRIPTION:"+escapeICalendarText(e.Description))
+		}
+		writeICalendarLine(&b, "END:VEVENT")
+	}
+	writeICalendarLine(&b, "END:VCALENDAR")
+	return b.Bytes()
+}
+
+var iCalendarTextReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`;`, `\;`,
+	`,`, `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", ``,
+)
+
+func escapeICalendarText(s string) string {
+	return iCalendarTextReplacer.Replace(s)
+}
+
+// Function writes the content line folded to 75 octets without splitting
+// a UTF-8 character, as required by the specification.
+func writeICalendarLine(b *bytes.Buffer, line string) {
+	limit := 75
+	for len(line) > limit {
+		i := limit
+		for i > 0 && line[i]&0xC0 == 0x80 { // Continuation byte.
+			i--
+		}
+		b.WriteString(line[:i])
+		b.WriteString("\r\n ")
+		line = line[i:]
+		limit = 74 // The leading space counts.
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
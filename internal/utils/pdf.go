@@ -0,0 +1,231 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"strings"
+)
+
+// The size of a "US Letter" page in points.
+const (
+	PDFLetterWidth  = 612.0
+	PDFLetterHeight = 792.0
+)
+
+// PDFDocument is a minimal PDF writer which supports the standard Helvetica
+// fonts, lines, rectangles and images. It exists so we can render documents
+// like invoices without depending on a third-party library. All coordinates
+// are in points with the origin at the *top-left* corner of the page.
+type PDFDocument struct {
+	Width  float64
+	Height float64
+	pages  []*bytes.Buffer
+	images [][]byte
+	page   *bytes.Buffer
+}
+
+func NewPDFDocument() *PDFDocument {
+	return &PDFDocument{
+		Width:  PDFLetterWidth,
+		Height: PDFLetterHeight,
+	}
+}
+
+// Function will start a new page, every drawing function afterwards will
+// draw on this page.
+func (d *PDFDocument) AddPage() {
+	d.page = new(bytes.Buffer)
+	d.pages = append(d.pages, d.page)
+}
+
+// Function will draw the text with the baseline at `y`.
+func (d *PDFDocument) Text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.page, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, d.Height-y, pdfEscapeText(s))
+}
+
+// Function will draw the text so it ends at `x`.
+func (d *PDFDocument) TextRight(x, y, size float64, bold bool, s string) {
+	d.Text(x-PDFTextWidth(s, size, bold), y, size, bold, s)
+}
+
+func (d *PDFDocument) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.page, "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, d.Height-y1, x2, d.Height-y2)
+}
+
+// Function will fill the rectangle with the `gray` level where 0 is black
+// and 1 is white.
+func (d *PDFDocument) FillRect(x, y, w, h, gray float64) {
+	fmt.Fprintf(d.page, "q %.2f g %.2f %.2f %.2f %.2f re f Q\n", gray, x, d.Height-y-h, w, h)
+}
+
+// Function will draw the image scaled to fit inside the box while keeping
+// its aspect ratio. The image is flattened onto a white background and
+// embedded as a JPEG.
+func (d *PDFDocument) Image(img image.Image, x, y, w, h float64) error {
+	b := img.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 {
+		return nil
+	}
+	flat := image.NewRGBA(b)
+	draw.Draw(flat, b, &image.Uniform{color.White}, image.Point{}, draw.Src)
+	draw.Draw(flat, b, img, b.Min, draw.Over)
+
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, flat, &jpeg.Options{Quality: 90}); err != nil {
+		return err
+	}
+	header := fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>\nstream\n", b.Dx(), b.Dy(), buf.Len())
+	obj := append([]byte(header), buf.Bytes()...)
+	obj = append(obj, "\nendstream"...)
+	d.images = append(d.images, obj)
+
+	scale := w / float64(b.Dx())
+	if s := h / float64(b.Dy()); s < scale {
+		scale = s
+	}
+	iw, ih := float64(b.Dx())*scale, float64(b.Dy())*scale
+	fmt.Fprintf(d.page, "q %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n", iw, ih, x, d.Height-y-ih, len(d.images))
+	return nil
+}
+
+// Function will write the document to `w`.
+func (d *PDFDocument) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	// Object numbers: 1 catalog, 2 pages, 3 & 4 fonts, then the images
+	// followed by a page and content stream object for every page.
+	var objs []string
+	objs = append(objs, "<< /Type /Catalog /Pages 2 0 R >>")
+	firstPage := 5 + len(d.images)
+	var kids []string
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", firstPage+i*2))
+	}
+	objs = append(objs, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	objs = append(objs, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	objs = append(objs, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	var xobjects []string
+	for i, img := range d.images {
+		objs = append(objs, string(img))
+		xobjects = append(xobjects, fmt.Sprintf("/Im%d %d 0 R", i+1, 5+i))
+	}
+	resources := "<< /Font << /F1 3 0 R /F2 4 0 R >> /XObject << " + strings.Join(xobjects, " ") + " >> >>"
+	for i, p := range d.pages {
+		objs = append(objs, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources %s /Contents %d 0 R >>", d.Width, d.Height, resources, firstPage+i*2+1))
+		objs = append(objs, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", p.Len(), p.String()))
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objs))
+	for i, obj := range objs {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(buf, "xref\n0 %d\n0000000000 65535 f \n", len(objs)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objs)+1, xref)
+	return buf.WriteTo(w)
+}
+
+// Function returns the width in points of the text in Helvetica.
+func PDFTextWidth(s string, size float64, bold bool) float64 {
+	widths := pdfHelveticaWidths
+	if bold {
+		widths = pdfHelveticaBoldWidths
+	}
+	var total int
+	for _, r := range s {
+		if r >= 32 && r <= 126 {
+			total += widths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Function will split the text into lines which fit inside `maxWidth`.
+func PDFWrapText(s string, size float64, bold bool, maxWidth float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if line != "" && PDFTextWidth(candidate, size, bold) > maxWidth {
+				lines = append(lines, line)
+				candidate = word
+			}
+			line = candidate
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Function will escape the text and convert it to the "WinAnsiEncoding" of
+// our standard fonts, characters which cannot be encoded become "?".
+func pdfEscapeText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r <= 126:
+			b.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(&b, "\\%03o", r)
+		case r == '–':
+			b.WriteString("\\226")
+		case r == '—':
+			b.WriteString("\\227")
+		case r == '‘' || r == '’':
+			b.WriteByte('\'')
+		case r == '“' || r == '”':
+			b.WriteByte('"')
+		case r == '€':
+			b.WriteString("\\200")
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// The widths of the printable ASCII characters from the Adobe font metrics
+// of the standard Helvetica fonts.
+var pdfHelveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var pdfHelveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	SpreadsheetCSVFormat  = "csv"
+	SpreadsheetXLSXFormat = "xlsx"
+)
+
+// SpreadsheetWriter is used to stream tabular data row by row into a file
+// format which our staff can open with their spreadsheet software. Rows are
+// written to the underlying `io.Writer` as they arrive so memory usage stays
+// flat regardless of how many rows are exported.
+type SpreadsheetWriter interface {
+	WriteRow(cells []string) error
+	Close() error
+}
+
+// Function returns the spreadsheet writer for the format or an error if the
+// format is not supported.
+func NewSpreadsheetWriter(w io.Writer, format string) (SpreadsheetWriter, error) {
+	switch format {
+	case SpreadsheetCSVFormat:
+		return NewCSVSpreadsheetWriter(w), nil
+	case SpreadsheetXLSXFormat:
+		return NewXLSXSpreadsheetWriter(w)
+	default:
+		return nil, errors.New("unsupported spreadsheet format: " + format)
+	}
+}
+
+// Function returns the MIME type to use in the HTTP response for the format.
+func SpreadsheetContentType(format string) string {
+	if format == SpreadsheetXLSXFormat {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Function returns the cell with a leading `'` if the spreadsheet software
+// would otherwise run it as a formula, ex: a customer named "=HYPERLINK(...)".
+// Numbers, including negative amounts, are left alone.
+func EscapeSpreadsheetCell(cell string) string {
+	if cell == "" || !strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return cell
+	}
+	if _, err := strconv.ParseFloat(cell, 64); err == nil {
+		return cell
+	}
+	return "'" + cell
+}
+
+// Function returns a copy of the row with every cell escaped.
+func escapeSpreadsheetRow(cells []string) []string {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = EscapeSpreadsheetCell(cell)
+	}
+	return escaped
+}
+
+type csvSpreadsheetWriter struct {
+	w *csv.Writer
+}
+
+func NewCSVSpreadsheetWriter(w io.Writer) SpreadsheetWriter {
+	return &csvSpreadsheetWriter{
+		w: csv.NewWriter(w),
+	}
+}
+
+func (s *csvSpreadsheetWriter) WriteRow(cells []string) error {
+	if err := s.w.Write(escapeSpreadsheetRow(cells)); err != nil {
+		return err
+	}
+	s.w.Flush()
+	return s.w.Error()
+}
+
+func (s *csvSpreadsheetWriter) Close() error {
+	s.w.Flush()
+	return s.w.Error()
+}
+
+// DEVELOPERS NOTE:
+// The following `xlsx` writer produces the smallest valid "Office Open XML"
+// workbook with a single sheet. We use inline strings instead of a shared
+// strings table so we never have to hold the cell values in memory.
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+
+const xlsxSheetHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+
+const xlsxSheetFooter = `</sheetData></worksheet>`
+
+type xlsxSpreadsheetWriter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	row   int
+}
+
+func NewXLSXSpreadsheetWriter(w io.Writer) (SpreadsheetWriter, error) {
+	zw := zip.NewWriter(w)
+
+	// Write all the static parts of the workbook first because the zip format
+	// only allows one open file entry at a time; the sheet must come last.
+	parts := []struct {
+		name string
+		body string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(sheet, xlsxSheetHeader); err != nil {
+		return nil, err
+	}
+
+	return &xlsxSpreadsheetWriter{
+		zw:    zw,
+		sheet: sheet,
+	}, nil
+}
+
+func (s *xlsxSpreadsheetWriter) WriteRow(cells []string) error {
+	s.row++
+	rowStr := strconv.Itoa(s.row)
+
+	var b strings.Builder
+	b.WriteString(`<row r="` + rowStr + `">`)
+	for i, cell := range cells {
+		b.WriteString(`<c r="` + xlsxColumnName(i) + rowStr + `" t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(&b, []byte(EscapeSpreadsheetCell(cell))); err != nil {
+			return err
+		}
+		b.WriteString(`</t></is></c>`)
+	}
+	b.WriteString(`</row>`)
+
+	_, err := io.WriteString(s.sheet, b.String())
+	return err
+}
+
+func (s *xlsxSpreadsheetWriter) Close() error {
+	if _, err := io.WriteString(s.sheet, xlsxSheetFooter); err != nil {
+		return err
+	}
+	return s.zw.Close()
+}
+
+// Function converts the zero-based column index into the spreadsheet column
+// letters, for example: 0 -> "A", 25 -> "Z", 26 -> "AA".
+func xlsxColumnName(i int) string {
+	name := ""
+	for i >= 0 {
+		name = string(rune('A'+(i%26))) + name
+		i = i/26 - 1
+	}
+	return name
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEscapeSpreadsheetCell(t *testing.T) {
+	tests := []struct {
+		cell string
+		want string
+	}{
+		{"", ""},
+		{"Frank Herbert", "Frank Herbert"},
+		{"=HYPERLINK(\"http://example.com\")", "'=HYPERLINK(\"http://example.com\")"},
+		{"+1+2", "'+1+2"},
+		{"-1+2", "'-1+2"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tcmd", "'\tcmd"},
+		{"\rcmd", "'\rcmd"},
+		{"-12.50", "-12.50"},
+		{"+5", "+5"},
+		{"a=b", "a=b"},
+	}
+	for _, tt := range tests {
+		if got := EscapeSpreadsheetCell(tt.cell); got != tt.want {
+			t.Errorf("EscapeSpreadsheetCell(%q) = %q, want %q", tt.cell, got, tt.want)
+		}
+	}
+}
+
+func TestCSVSpreadsheetWriterEscapesCells(t *testing.T) {
+	var buf bytes.Buffer
+	sw := NewCSVSpreadsheetWriter(&buf)
+	if err := sw.WriteRow([]string{"=1+1", "-3.25", "notes"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "'=1+1,-3.25,notes\n"; got != want {
+		t.Errorf("row = %q, want %q", got, want)
+	}
+}
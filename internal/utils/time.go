@@ -1,57 +1,64 @@
 package utils
 
 import (
+	"fmt"
 	"strings"
 	"time"
 )
 
-// Function will convert the timestamp string that `PgAdmin` exports (when you
-// export as CSV format) into a Golang `time` data-format.
-// TODO: Implement matching of timezones!
-func ConvertPGAdminTimeStringToTime(dateTimeString string) (time.Time, error) {
-	loc, err := time.LoadLocation("America/Toronto")
-	if err != nil {
-		return time.Now(), err
-	}
+// The default timezone of our tenants if none was set.
+const DefaultTimezone = "America/Toronto"
 
-	// Split our string so it will look like this:
-	// INPUT: "2018-06-17 21:23:59.241031-04"
-	// OUTPUT: ["2018-06-17", "21:23:59.241031-04"]
-	dateTimeStringSplits := strings.Split(dateTimeString, " ")
+// The layouts of the PostgreSQL `timestamptz` text output (which is what
+// `PgAdmin` exports when you export as CSV format), please note the
+// fractional seconds are optional when parsing [0].
+var pgTimestampWithOffsetLayouts = []string{
+	"2006-01-02 15:04:05.999999999Z07:00:00",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02T15:04:05.999999999Z07:00",
+}
 
-	//
-	// Convert the "date" format.
-	//
+var pgTimestampWithoutOffsetLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
 
-	datePart, err := time.Parse("2006-01-02", dateTimeStringSplits[0])
-	if err != nil {
-		return time.Now(), err
+// Function will convert the timestamp string that PostgreSQL outputs, ex:
+// "2018-06-17 21:23:59.241031-04" or "2018-12-01 09:00:00-05", into a Golang
+// `time` data-format. If the string has an offset then it is honoured,
+// otherwise the string is treated as a wall clock time in the `loc` timezone.
+func ConvertPGAdminTimeStringToTime(dateTimeString string, loc *time.Location) (time.Time, error) {
+	value := strings.TrimSpace(dateTimeString)
+	if loc == nil {
+		loc = time.UTC
 	}
 
-	//
-	// Convert the "time" format.
-	//
-
-	// Split out our timezone format.
-	// Ex: America/Toronto is "-04".
-	// INPUT: "21:23:59.241031-04"
-	// OUTPUT: ["21:23:59.241031", "-04"]
-	timeSplits := strings.Split(dateTimeStringSplits[1], "-04")
-
-	// The following code will take our "21:23:59.241031" string portion
-	// and convert it into our Golang format. Please note you'll need to
-	// read article [0] as to why I chose "15:04:05.000000" format.
-	timePart, err := time.Parse("15:04:05.000000", timeSplits[0])
-	if err != nil {
-		return time.Now(), err
+	for _, layout := range pgTimestampWithOffsetLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
 	}
+	for _, layout := range pgTimestampWithoutOffsetLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported timestamp format: %s", dateTimeString)
+}
 
-	// Note; You'll need to see article [1] to see where I got these field.s
-	dateTime := time.Date(datePart.Year(), datePart.Month(), datePart.Day(), timePart.Hour(), timePart.Minute(), timePart.Second(), timePart.Nanosecond(), loc)
-
-	return dateTime, nil
+// Function returns the location of the timezone name, ex: "America/Toronto",
+// and falls back to our default timezone if the name is empty.
+func GetTimezoneLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		timezone = DefaultTimezone
+	}
+	if strings.EqualFold(timezone, "utc") {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(timezone)
 }
 
 // SPECIAL THANKS:
-// [0] https://www.golangprograms.com/get-current-date-and-time-in-various-format-in-golang.html
-// [1] https://golang.org/pkg/time/
+// [0] https://golang.org/pkg/time/
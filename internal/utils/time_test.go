@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConvertPGAdminTimeStringToTime(t *testing.T) {
+	toronto, err := time.LoadLocation("America/Toronto")
+	if err != nil {
+		t.Skip("timezone database is not available:", err)
+	}
+	utc := func(year int, month time.Month, day, hour, min, sec, nsec int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, nsec, time.UTC)
+	}
+
+	tests := []struct {
+		name    string
+		s       string
+		loc     *time.Location
+		want    time.Time
+		wantErr bool
+	}{
+		{"offset -04 with fractional seconds", "2018-06-17 21:23:59.241031-04", toronto, utc(2018, 6, 18, 1, 23, 59, 241031000), false},
+		{"offset -05", "2018-12-01 09:00:00-05", toronto, utc(2018, 12, 1, 14, 0, 0, 0), false},
+		{"offset +05:30", "2018-12-01 09:00:00+05:30", toronto, utc(2018, 12, 1, 3, 30, 0, 0), false},
+		{"offset with seconds", "1895-01-01 00:00:00-05:17:32", toronto, utc(1895, 1, 1, 5, 17, 32, 0), false},
+		{"ISO 8601", "2018-12-01T09:00:00.5-05:00", toronto, utc(2018, 12, 1, 14, 0, 0, 500000000), false},
+		{"offset is honoured over the location", "2018-12-01 09:00:00+00", toronto, utc(2018, 12, 1, 9, 0, 0, 0), false},
+		{"without offset before DST", "2021-03-14 01:30:00", toronto, utc(2021, 3, 14, 6, 30, 0, 0), false},
+		{"without offset after DST", "2021-03-14 03:30:00", toronto, utc(2021, 3, 14, 7, 30, 0, 0), false},
+		{"without offset after DST ends", "2021-11-07 12:00:00.25", toronto, utc(2021, 11, 7, 17, 0, 0, 250000000), false},
+		{"date only", "2021-07-01", toronto, utc(2021, 7, 1, 4, 0, 0, 0), false},
+		{"without offset or location", " 2021-07-01 12:00:00 ", nil, utc(2021, 7, 1, 12, 0, 0, 0), false},
+		{"empty", "", toronto, time.Time{}, true},
+		{"not a timestamp", "yesterday", toronto, time.Time{}, true},
+		{"invalid month", "2018-13-01 00:00:00", toronto, time.Time{}, true},
+		{"timezone abbreviation", "2018-12-01 09:00:00 EST", toronto, time.Time{}, true},
+		{"invalid offset", "2018-12-01 09:00:00-5", toronto, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ConvertPGAdminTimeStringToTime(tt.s, tt.loc)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: ConvertPGAdminTimeStringToTime(%q) = %v, want %v", tt.name, tt.s, got.UTC(), tt.want)
+		}
+	}
+}
+
+func TestGetTimezoneLocation(t *testing.T) {
+	if _, err := time.LoadLocation(DefaultTimezone); err != nil {
+		t.Skip("timezone database is not available:", err)
+	}
+
+	tests := []struct {
+		timezone string
+		want     string
+		wantErr  bool
+	}{
+		{"", DefaultTimezone, false},
+		{"America/Vancouver", "America/Vancouver", false},
+		{"utc", "UTC", false},
+		{"UTC", "UTC", false},
+		{"Not/A_Zone", "", true},
+	}
+	for _, tt := range tests {
+		loc, err := GetTimezoneLocation(tt.timezone)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("GetTimezoneLocation(%q) error = %v, want error %v", tt.timezone, err, tt.wantErr)
+			continue
+		}
+		if err == nil && loc.String() != tt.want {
+			t.Errorf("GetTimezoneLocation(%q) = %s, want %s", tt.timezone, loc, tt.want)
+		}
+	}
+}
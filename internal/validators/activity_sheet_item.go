@@ -0,0 +1,31 @@
+package validators
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+)
+
+func ValidateActivitySheetItemSaveFromRequest(dirtyData *idos.ActivitySheetItemIDO) (bool, string) {
+	e := make(map[string]string)
+	if dirtyData.AssociateId == 0 {
+		e["associate_id"] = "missing value"
+	}
+	if dirtyData.State < models.ActivitySheetItemDeclinedState || dirtyData.State > models.ActivitySheetItemAcceptedState {
+		e["state"] = "invalid value"
+	}
+	if utf8.RuneCountInString(dirtyData.Comment) > 2055 {
+		e["comment"] = "character count over 2055"
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
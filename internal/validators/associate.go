@@ -0,0 +1,169 @@
+package validators
+
+import (
+	"encoding/json"
+	"net/mail"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+)
+
+func ValidateAssociateSaveFromRequest(dirtyData *idos.AssociateIDO, isCreate bool) (bool, string) {
+	e := make(map[string]string)
+
+	validateAssociateContact(&dirtyData.AssociateContactIDO, e)
+	validateAssociateAddress(&dirtyData.AssociateAddressIDO, e)
+	validateAssociateMetrics(&dirtyData.AssociateMetricsIDO, e)
+	if isCreate && (dirtyData.Password != "" || dirtyData.PasswordRepeated != "") {
+		validateAssociatePassword(dirtyData.Password, dirtyData.PasswordRepeated, e)
+	}
+	return associateValidationResult(e)
+}
+
+func ValidateAssociateContactFromRequest(dirtyData *idos.AssociateContactIDO) (bool, string) {
+	e := make(map[string]string)
+	validateAssociateContact(dirtyData, e)
+	return associateValidationResult(e)
+}
+
+func ValidateAssociateAddressFromRequest(dirtyData *idos.AssociateAddressIDO) (bool, string) {
+	e := make(map[string]string)
+	validateAssociateAddress(dirtyData, e)
+	return associateValidationResult(e)
+}
+
+func ValidateAssociateMetricsFromRequest(dirtyData *idos.AssociateMetricsIDO) (bool, string) {
+	e := make(map[string]string)
+	validateAssociateMetrics(dirtyData, e)
+	return associateValidationResult(e)
+}
+
+func ValidateAssociateAccountFromRequest(dirtyData *idos.AssociateAccountIDO) (bool, string) {
+	e := make(map[string]string)
+	validateAssociatePassword(dirtyData.Password, dirtyData.PasswordRepeated, e)
+	return associateValidationResult(e)
+}
+
+func ValidateAssociateUpgradeFromRequest(dirtyData *idos.AssociateUpgradeIDO) (bool, string) {
+	e := make(map[string]string)
+
+	if strings.TrimSpace(dirtyData.OrganizationName) == "" {
+		e["organization_name"] = "missing value"
+	} else if utf8.RuneCountInString(dirtyData.OrganizationName) > 255 {
+		e["organization_name"] = "character count over 255"
+	}
+	if dirtyData.OrganizationTypeOf < 1 || dirtyData.OrganizationTypeOf > 4 {
+		e["organization_type_of"] = "invalid value"
+	}
+	return associateValidationResult(e)
+}
+
+func ValidateAssociateArchiveFromRequest(dirtyData *idos.AssociateArchiveIDO) (bool, string) {
+	e := make(map[string]string)
+
+	if dirtyData.DeactivationReason < models.AssociateOtherDeactivationReason || dirtyData.DeactivationReason > models.AssociateDoNotConstactDeactivationReason {
+		e["deactivation_reason"] = "invalid value"
+	} else if dirtyData.DeactivationReason == models.AssociateOtherDeactivationReason && strings.TrimSpace(dirtyData.DeactivationReasonOther) == "" {
+		e["deactivation_reason_other"] = "missing value"
+	}
+	if utf8.RuneCountInString(dirtyData.DeactivationReasonOther) > 2055 {
+		e["deactivation_reason_other"] = "character count over 2055"
+	}
+	return associateValidationResult(e)
+}
+
+func validateAssociateContact(d *idos.AssociateContactIDO, e map[string]string) {
+	if d.TypeOf != models.AssociateResidentialTypeOf && d.TypeOf != models.AssociateCommercialTypeOf {
+		e["type_of"] = "invalid value"
+	}
+	if d.TypeOf == models.AssociateCommercialTypeOf {
+		if strings.TrimSpace(d.OrganizationName) == "" {
+			e["organization_name"] = "missing value"
+		}
+		if d.OrganizationTypeOf < 1 || d.OrganizationTypeOf > 4 {
+			e["organization_type_of"] = "invalid value"
+		}
+	}
+	checkAssociateText(e, "given_name", d.GivenName, 63, true)
+	checkAssociateText(e, "middle_name", d.MiddleName, 63, false)
+	checkAssociateText(e, "last_name", d.LastName, 63, true)
+	checkAssociateText(e, "organization_name", d.OrganizationName, 255, false)
+	checkAssociateText(e, "email", d.Email, 255, true)
+	if _, ok := e["email"]; !ok {
+		if _, err := mail.ParseAddress(d.Email); err != nil {
+			e["email"] = "invalid email"
+		}
+	}
+	checkAssociateText(e, "telephone", d.Telephone, 127, true)
+	checkAssociateText(e, "telephone_extension", d.TelephoneExtension, 31, false)
+	checkAssociateText(e, "other_telephone", d.OtherTelephone, 127, false)
+	checkAssociateText(e, "other_telephone_extension", d.OtherTelephoneExtension, 31, false)
+}
+
+func validateAssociateAddress(d *idos.AssociateAddressIDO, e map[string]string) {
+	checkAssociateText(e, "address_country", d.AddressCountry, 127, true)
+	checkAssociateText(e, "address_region", d.AddressRegion, 127, true)
+	checkAssociateText(e, "address_locality", d.AddressLocality, 127, true)
+	checkAssociateText(e, "post_office_box_number", d.PostOfficeBoxNumber, 255, false)
+	checkAssociateText(e, "postal_code", d.PostalCode, 127, false)
+	checkAssociateText(e, "street_address", d.StreetAddress, 127, true)
+	checkAssociateText(e, "street_address_extra", d.StreetAddressExtra, 127, false)
+}
+
+func validateAssociateMetrics(d *idos.AssociateMetricsIDO, e map[string]string) {
+	if d.HowHearId == 0 {
+		e["how_hear_id"] = "missing value"
+	}
+	if d.ServiceFeeId == 0 {
+		e["service_fee_id"] = "missing value"
+	}
+	if d.HourlySalaryDesired < 0 {
+		e["hourly_salary_desired"] = "must not be negative"
+	}
+	checkAssociateText(e, "gender", d.Gender, 31, false)
+	checkAssociateText(e, "nationality", d.Nationality, 63, false)
+	checkAssociateText(e, "tax_id", d.TaxId, 127, false)
+	checkAssociateText(e, "how_hear_other", d.HowHearOther, 2055, false)
+	checkAssociateText(e, "business", d.Business, 63, false)
+	checkAssociateText(e, "limit_special", d.LimitSpecial, 255, false)
+	checkAssociateText(e, "wsib_number", d.WsibNumber, 127, false)
+	checkAssociateText(e, "drivers_license_class", d.DriversLicenseClass, 31, false)
+	checkAssociateText(e, "emergency_contact_name", d.EmergencyContactName, 127, false)
+	checkAssociateText(e, "emergency_contact_relationship", d.EmergencyContactRelationship, 127, false)
+	checkAssociateText(e, "emergency_contact_telephone", d.EmergencyContactTelephone, 127, false)
+	checkAssociateText(e, "emergency_contact_alternative_telephone", d.EmergencyContactAlternativeTelephone, 127, false)
+}
+
+func validateAssociatePassword(password string, passwordRepeated string, e map[string]string) {
+	if password == "" {
+		e["password"] = "missing value"
+	} else if utf8.RuneCountInString(password) < 8 {
+		e["password"] = "character count under 8"
+	} else if password != passwordRepeated {
+		e["password_repeated"] = "does not match the password"
+	}
+}
+
+// Function checks the text fits in the database column and, if required, was
+// provided.
+func checkAssociateText(e map[string]string, field string, value string, max int, isRequired bool) {
+	if isRequired && strings.TrimSpace(value) == "" {
+		e[field] = "missing value"
+	} else if utf8.RuneCountInString(value) > max {
+		e[field] = "character count over " + strconv.Itoa(max)
+	}
+}
+
+func associateValidationResult(e map[string]string) (bool, string) {
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
@@ -0,0 +1,41 @@
+package validators
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+)
+
+func ValidateAssociateAwayLogSaveFromRequest(dirtyData *idos.AssociateAwayLogIDO) (bool, string) {
+	e := make(map[string]string)
+
+	if dirtyData.AssociateId == 0 {
+		e["associate_id"] = "missing value"
+	}
+	if dirtyData.Reason < models.AssociateAwayLogOtherReason || dirtyData.Reason > models.AssociateAwayLogPoliceCheckExpiredReason {
+		e["reason"] = "invalid value"
+	} else if dirtyData.Reason == models.AssociateAwayLogOtherReason && strings.TrimSpace(dirtyData.ReasonOther.ValueOrZero()) == "" {
+		e["reason_other"] = "missing value"
+	}
+	if dirtyData.StartDate.IsZero() {
+		e["start_date"] = "missing value"
+	}
+	if !dirtyData.UntilFurtherNotice {
+		if dirtyData.UntilDate.IsZero() {
+			e["until_date"] = "missing value"
+		} else if !dirtyData.StartDate.IsZero() && dirtyData.UntilDate.Time.Before(dirtyData.StartDate.Time) {
+			e["until_date"] = "must not be before the start date"
+		}
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
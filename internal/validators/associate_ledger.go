@@ -0,0 +1,54 @@
+package validators
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+)
+
+// Function validates the service fee payment against the work orders it
+// settles, the work orders must be in the same order as the ids.
+func ValidateAssociateServiceFeePaymentFromRequest(dirtyData *idos.AssociateServiceFeePaymentIDO, a *models.Associate, arr []*models.WorkOrder) (bool, string) {
+	e := make(map[string]string)
+
+	if dirtyData.PaidAt.IsZero() {
+		e["paid_at"] = "missing value"
+	}
+	if len(dirtyData.OrderIds) == 0 {
+		e["order_ids"] = "missing value"
+	}
+	seen := make(map[uint64]bool)
+	for i, id := range dirtyData.OrderIds {
+		idStr := strconv.FormatUint(id, 10)
+		if seen[id] {
+			e["order_ids"] = "duplicate work order id " + idStr
+			break
+		}
+		seen[id] = true
+
+		m := arr[i]
+		if m == nil || m.TenantId != a.TenantId || m.AssociateId.ValueOrZero() != int64(a.Id) {
+			e["order_ids"] = "work order " + idStr + " does not belong to the associate"
+			break
+		}
+		if m.InvoiceServiceFeeAmount <= 0 {
+			e["order_ids"] = "work order " + idStr + " has no service fee"
+			break
+		}
+		if m.InvoiceActualServiceFeeAmountPaid >= m.InvoiceServiceFeeAmount {
+			e["order_ids"] = "work order " + idStr + " was already paid"
+			break
+		}
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
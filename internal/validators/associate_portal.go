@@ -0,0 +1,49 @@
+package validators
+
+import (
+	"encoding/json"
+	"time"
+	"unicode/utf8"
+
+	"github.com/over55/workery-server/internal/idos"
+)
+
+func ValidateAssociateOfferResponseFromRequest(dirtyData *idos.AssociateOfferResponseIDO) (bool, string) {
+	e := make(map[string]string)
+	if utf8.RuneCountInString(dirtyData.Comment) > 2055 {
+		e["comment"] = "character count over 2055"
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
+
+func ValidateAssociateWorkOrderCompletionFromRequest(dirtyData *idos.AssociateWorkOrderCompletionIDO) (bool, string) {
+	e := make(map[string]string)
+	if dirtyData.Hours <= 0 {
+		e["hours"] = "must be greater than zero"
+	} else if dirtyData.Hours > 10000 {
+		e["hours"] = "must not be over 10000"
+	}
+	if dirtyData.Visits < 1 {
+		e["visits"] = "must be at least one"
+	}
+	if dirtyData.CompletionDate.Valid && dirtyData.CompletionDate.Time.After(time.Now()) {
+		e["completion_date"] = "must not be in the future"
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
@@ -0,0 +1,35 @@
+package validators
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+)
+
+func ValidateInsuranceRequirementSaveFromRequest(dirtyData *idos.InsuranceRequirementIDO) (bool, string) {
+	e := make(map[string]string)
+
+	if strings.TrimSpace(dirtyData.Text) == "" {
+		e["text"] = "missing value"
+	} else if utf8.RuneCountInString(dirtyData.Text) > 31 {
+		e["text"] = "character count over 31"
+	}
+	if dirtyData.State != models.InsuranceRequirementActiveState && dirtyData.State != models.InsuranceRequirementInactiveState {
+		e["state"] = "invalid value"
+	}
+	if dirtyData.AssociateDateTypeOf < models.InsuranceRequirementNotTrackedAssociateDateTypeOf || dirtyData.AssociateDateTypeOf > models.InsuranceRequirementDuesAssociateDateTypeOf {
+		e["associate_date_type_of"] = "invalid value"
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
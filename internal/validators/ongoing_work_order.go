@@ -0,0 +1,52 @@
+package validators
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+)
+
+func ValidateOngoingWorkOrderSaveFromRequest(dirtyData *idos.OngoingWorkOrderIDO) (bool, string) {
+	e := make(map[string]string)
+
+	if dirtyData.CustomerId == 0 {
+		e["customer_id"] = "missing value"
+	}
+	if strings.TrimSpace(dirtyData.Description) == "" {
+		e["description"] = "missing value"
+	}
+	if dirtyData.TypeOf < models.WorkOrderResidentialTypeOf || dirtyData.TypeOf > models.WorkOrderUnassignedTypeOf {
+		e["type_of"] = "invalid value"
+	}
+	if dirtyData.RecurrenceRule == "" {
+		e["recurrence_rule"] = "missing value"
+	} else if _, err := models.ParseRecurrenceRule(dirtyData.RecurrenceRule); err != nil {
+		e["recurrence_rule"] = err.Error()
+	}
+	startDate, err := time.Parse("2006-01-02", dirtyData.RecurrenceStartDate)
+	if dirtyData.RecurrenceStartDate == "" {
+		e["recurrence_start_date"] = "missing value"
+	} else if err != nil {
+		e["recurrence_start_date"] = "invalid date"
+	}
+	if dirtyData.RecurrenceEndDate != "" {
+		endDate, err := time.Parse("2006-01-02", dirtyData.RecurrenceEndDate)
+		if err != nil {
+			e["recurrence_end_date"] = "invalid date"
+		} else if !startDate.IsZero() && endDate.Before(startDate) {
+			e["recurrence_end_date"] = "must not be before the start date"
+		}
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
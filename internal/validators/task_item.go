@@ -0,0 +1,144 @@
+package validators
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+)
+
+func ValidateTaskItemAssignAssociateFromRequest(dirtyData *idos.TaskItemAssignAssociateIDO) (bool, string) {
+	e := make(map[string]string)
+	if dirtyData.AssociateId == 0 {
+		e["associate_id"] = "missing value"
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
+
+func ValidateTaskItemDeclineAssociateFromRequest(dirtyData *idos.TaskItemDeclineAssociateIDO) (bool, string) {
+	e := make(map[string]string)
+	if dirtyData.AssociateId == 0 {
+		e["associate_id"] = "missing value"
+	}
+	if utf8.RuneCountInString(dirtyData.Comment) > 2055 {
+		e["comment"] = "character count over 2055"
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
+
+func ValidateTaskItemFollowUpFromRequest(dirtyData *idos.TaskItemFollowUpIDO) (bool, string) {
+	e := make(map[string]string)
+	if dirtyData.HasAgreedToMeet && !dirtyData.MeetingDate.Valid {
+		e["meeting_date"] = "missing value"
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
+
+func ValidateTaskItemFollowUpPendingFromRequest(dirtyData *idos.TaskItemFollowUpPendingIDO) (bool, string) {
+	e := make(map[string]string)
+	if !dirtyData.DueDate.Valid {
+		e["due_date"] = "missing value"
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
+
+func ValidateTaskItemCloseFromRequest(dirtyData *idos.TaskItemCloseIDO) (bool, string) {
+	e := make(map[string]string)
+	if dirtyData.ClosingReason == 0 {
+		e["closing_reason"] = "missing value"
+	}
+	if len(dirtyData.ClosingReasonOther) > 1024 {
+		e["closing_reason_other"] = "character count over 1024"
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
+
+func ValidateTaskItemOrderCompletionFromRequest(dirtyData *idos.TaskItemOrderCompletionIDO) (bool, string) {
+	e := make(map[string]string)
+	if !dirtyData.WasCompleted {
+		if dirtyData.ClosingReason == 0 {
+			e["closing_reason"] = "missing value"
+		}
+	} else {
+		if !dirtyData.CompletionDate.Valid {
+			e["completion_date"] = "missing value"
+		}
+		if !dirtyData.InvoiceDate.Valid {
+			e["invoice_date"] = "missing value"
+		}
+		if strings.TrimSpace(dirtyData.InvoiceIds) == "" {
+			e["invoice_ids"] = "missing value"
+		}
+		amounts := map[string]models.Money{
+			"invoice_quote_amount":       dirtyData.InvoiceQuoteAmount,
+			"invoice_labour_amount":      dirtyData.InvoiceLabourAmount,
+			"invoice_material_amount":    dirtyData.InvoiceMaterialAmount,
+			"invoice_other_costs_amount": dirtyData.InvoiceOtherCostsAmount,
+		}
+		for key, value := range amounts {
+			if value < 0 {
+				e[key] = "cannot be negative"
+			}
+		}
+		if dirtyData.InvoicePaidTo != models.WorkOrderDepositPaidToAssociate && dirtyData.InvoicePaidTo != models.WorkOrderDepositPaidToOrganization {
+			e["invoice_paid_to"] = "invalid value"
+		}
+		if dirtyData.InvoiceServiceFeeId == 0 {
+			e["invoice_service_fee_id"] = "missing value"
+		}
+		if dirtyData.Visits < 0 {
+			e["visits"] = "cannot be negative"
+		}
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
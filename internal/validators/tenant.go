@@ -2,6 +2,7 @@ package validators
 
 import (
 	"encoding/json"
+	"strings"
 	"unicode/utf8"
 
 	"github.com/over55/workery-server/internal/idos"
@@ -37,3 +38,56 @@ func ValidateTenantSaveFromRequest(dirtyData *idos.TenantIDO) (bool, string) {
 	}
 	return true, ""
 }
+
+func ValidateTenantInvoiceTemplateSaveFromRequest(dirtyData *idos.TenantInvoiceTemplateIDO) (bool, string) {
+	e := make(map[string]string)
+
+	if utf8.RuneCountInString(dirtyData.LogoS3Key) > 1027 {
+		e["logo_s3_key"] = "character count over 1027"
+	}
+	if utf8.RuneCountInString(dirtyData.TaxRegistrationNumber) > 127 {
+		e["tax_registration_number"] = "character count over 127"
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
+
+func ValidateTenantAccountMappingSaveFromRequest(dirtyData *idos.TenantAccountMappingIDO) (bool, string) {
+	e := make(map[string]string)
+
+	accounts := map[string]string{
+		"accounts_receivable": dirtyData.AccountsReceivable,
+		"labour_income":       dirtyData.LabourIncome,
+		"materials_income":    dirtyData.MaterialsIncome,
+		"other_income":        dirtyData.OtherIncome,
+		"sales_tax_payable":   dirtyData.SalesTaxPayable,
+		"undeposited_funds":   dirtyData.UndepositedFunds,
+		"associate_clearing":  dirtyData.AssociateClearing,
+		"service_fee_income":  dirtyData.ServiceFeeIncome,
+	}
+	for key, value := range accounts {
+		if strings.TrimSpace(value) == "" {
+			e[key] = "missing value"
+		} else if utf8.RuneCountInString(value) > 127 {
+			e[key] = "character count over 127"
+		} else if strings.ContainsAny(value, "\t\r\n") {
+			e[key] = "invalid characters"
+		}
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
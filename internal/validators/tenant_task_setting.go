@@ -0,0 +1,27 @@
+package validators
+
+import (
+	"encoding/json"
+
+	"github.com/over55/workery-server/internal/idos"
+)
+
+func ValidateTenantTaskSettingSaveFromRequest(dirtyData *idos.TenantTaskSettingIDO) (bool, string) {
+	e := make(map[string]string)
+
+	if dirtyData.FollowUpAfterDays < 1 || dirtyData.FollowUpAfterDays > 365 {
+		e["follow_up_after_days"] = "must be between 1 and 365"
+	}
+	if dirtyData.EscalateAfterDays < 1 || dirtyData.EscalateAfterDays > 365 {
+		e["escalate_after_days"] = "must be between 1 and 365"
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
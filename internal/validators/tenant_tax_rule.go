@@ -0,0 +1,56 @@
+package validators
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+)
+
+// Function validates the tax rule which is saved as the rule `id`, which is
+// zero for a new rule, against the other rules `arr` of the tenant.
+func ValidateTenantTaxRuleSaveFromRequest(dirtyData *idos.TenantTaxRuleIDO, id uint64, arr []*models.TenantTaxRule) (bool, string) {
+	e := make(map[string]string)
+
+	if strings.TrimSpace(dirtyData.Name) == "" {
+		e["name"] = "missing value"
+	} else if utf8.RuneCountInString(dirtyData.Name) > 63 {
+		e["name"] = "character count over 63"
+	}
+	if dirtyData.Rate < 0 || dirtyData.Rate > models.NewPercentFromFloat(100) {
+		e["rate"] = "must be between 0 and 100"
+	}
+	if dirtyData.TypeOf < models.TenantTaxRuleAnyTypeOf || dirtyData.TypeOf > models.WorkOrderUnassignedTypeOf {
+		e["type_of"] = "invalid value"
+	}
+	if dirtyData.EffectiveFrom.IsZero() {
+		e["effective_from"] = "missing value"
+	} else if dirtyData.EffectiveTo.Valid && !dirtyData.EffectiveTo.Time.After(dirtyData.EffectiveFrom.Time) {
+		e["effective_to"] = "must be after the effective from date"
+	}
+	if len(e) == 0 {
+		m := &models.TenantTaxRule{
+			Name:          dirtyData.Name,
+			TypeOf:        dirtyData.TypeOf,
+			EffectiveFrom: dirtyData.EffectiveFrom.Time,
+			EffectiveTo:   dirtyData.EffectiveTo,
+		}
+		for _, other := range arr {
+			if other.Id != id && m.Overlaps(other) {
+				e["effective_from"] = "overlaps with another " + other.Name + " tax rule for the same type of work order"
+				break
+			}
+		}
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
@@ -0,0 +1,57 @@
+package validators
+
+import (
+	"testing"
+	"time"
+
+	null "gopkg.in/guregu/null.v4"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+)
+
+func TestValidateTenantTaxRuleSaveFromRequest(t *testing.T) {
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	jul := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	arr := []*models.TenantTaxRule{
+		{Id: 1, Name: "HST", TypeOf: models.TenantTaxRuleAnyTypeOf, EffectiveFrom: jan, EffectiveTo: null.TimeFrom(jul)},
+	}
+
+	tests := []struct {
+		name string
+		data *idos.TenantTaxRuleIDO
+		id   uint64
+		want bool
+	}{
+		{
+			"overlapping rule",
+			&idos.TenantTaxRuleIDO{Name: "HST", Rate: models.NewPercentFromFloat(13), TypeOf: models.WorkOrderResidentialTypeOf, EffectiveFrom: null.TimeFrom(jan.AddDate(0, 3, 0))},
+			0, false,
+		},
+		{
+			"rule which follows",
+			&idos.TenantTaxRuleIDO{Name: "HST", Rate: models.NewPercentFromFloat(15), TypeOf: models.WorkOrderResidentialTypeOf, EffectiveFrom: null.TimeFrom(jul)},
+			0, true,
+		},
+		{
+			"other tax",
+			&idos.TenantTaxRuleIDO{Name: "GST", Rate: models.NewPercentFromFloat(5), TypeOf: models.WorkOrderResidentialTypeOf, EffectiveFrom: null.TimeFrom(jan)},
+			0, true,
+		},
+		{
+			"updating the same rule",
+			&idos.TenantTaxRuleIDO{Name: "HST", Rate: models.NewPercentFromFloat(13), TypeOf: models.TenantTaxRuleAnyTypeOf, EffectiveFrom: null.TimeFrom(jan)},
+			1, true,
+		},
+		{
+			"effective to before effective from",
+			&idos.TenantTaxRuleIDO{Name: "GST", Rate: models.NewPercentFromFloat(5), EffectiveFrom: null.TimeFrom(jul), EffectiveTo: null.TimeFrom(jan)},
+			0, false,
+		},
+	}
+	for _, tt := range tests {
+		if got, errStr := ValidateTenantTaxRuleSaveFromRequest(tt.data, tt.id, arr); got != tt.want {
+			t.Errorf("%s: valid = %v (%s), want %v", tt.name, got, errStr, tt.want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package validators
+
+import (
+	"encoding/json"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+)
+
+func ValidateWorkOrderDepositCreateFromRequest(dirtyData *idos.WorkOrderDepositIDO) (bool, string) {
+	e := make(map[string]string)
+
+	if dirtyData.PaidAt.IsZero() {
+		e["paid_at"] = "missing value"
+	}
+	switch dirtyData.DepositMethod {
+	case 0:
+		e["deposit_method"] = "missing value"
+	case models.WorkOrderDepositDebitMethod, models.WorkOrderDepositCreditMethod, models.WorkOrderDepositChequeMethod, models.WorkOrderDepositCashMethod, models.WorkOrderDepositOtherMethod:
+	default:
+		e["deposit_method"] = "invalid value"
+	}
+	switch dirtyData.PaidTo {
+	case 0:
+		e["paid_to"] = "missing value"
+	case models.WorkOrderDepositPaidToAssociate, models.WorkOrderDepositPaidToOrganization:
+	default:
+		e["paid_to"] = "invalid value"
+	}
+	switch dirtyData.PaidFor {
+	case 0:
+		e["paid_for"] = "missing value"
+	case models.WorkOrderDepositPaidForLabour, models.WorkOrderDepositPaidForMaterials, models.WorkOrderDepositPaidForOtherCosts:
+	default:
+		e["paid_for"] = "invalid value"
+	}
+	// Refunds are entered as a negative amount.
+	if dirtyData.Amount == 0 {
+		e["amount"] = "missing value"
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
@@ -0,0 +1,74 @@
+package validators
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+)
+
+func ValidateWorkOrderInvoiceLineItemSaveFromRequest(dirtyData *idos.WorkOrderInvoiceLineItemIDO) (bool, string) {
+	e := make(map[string]string)
+
+	if dirtyData.TypeOf == 0 {
+		e["type_of"] = "missing value"
+	} else {
+		if dirtyData.TypeOf != models.WorkOrderInvoiceLineItemLabourTypeOf && dirtyData.TypeOf != models.WorkOrderInvoiceLineItemMaterialsTypeOf && dirtyData.TypeOf != models.WorkOrderInvoiceLineItemOtherCostsTypeOf {
+			e["type_of"] = "invalid value"
+		}
+	}
+	if dirtyData.Description == "" {
+		e["description"] = "missing value"
+	} else {
+		if utf8.RuneCountInString(dirtyData.Description) > 255 {
+			e["description"] = "character count over 255"
+		}
+	}
+	if dirtyData.Quantity == 0 {
+		e["quantity"] = "missing value"
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
+
+func ValidateWorkOrderInvoiceLineItemReorderFromRequest(dirtyData *idos.WorkOrderInvoiceLineItemReorderIDO, arr []*models.WorkOrderInvoiceLineItem) (bool, string) {
+	e := make(map[string]string)
+
+	// The new order must list every line of the invoice exactly once.
+	existing := make(map[uint64]bool)
+	for _, li := range arr {
+		existing[li.Id] = true
+	}
+	seen := make(map[uint64]bool)
+	for _, id := range dirtyData.Ids {
+		if existing[id] == false {
+			e["ids"] = "unknown line item id"
+			break
+		}
+		if seen[id] {
+			e["ids"] = "duplicate line item id"
+			break
+		}
+		seen[id] = true
+	}
+	if _, ok := e["ids"]; !ok && len(seen) != len(existing) {
+		e["ids"] = "every line item must be included"
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
@@ -0,0 +1,49 @@
+package validators
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/over55/workery-server/internal/idos"
+	"github.com/over55/workery-server/internal/models"
+)
+
+func ValidateWorkOrderServiceFeeSaveFromRequest(dirtyData *idos.WorkOrderServiceFeeIDO) (bool, string) {
+	e := make(map[string]string)
+
+	if strings.TrimSpace(dirtyData.Title) == "" {
+		e["title"] = "missing value"
+	} else if utf8.RuneCountInString(dirtyData.Title) > 63 {
+		e["title"] = "character count over 63"
+	}
+	if dirtyData.Percentage < 0 || dirtyData.Percentage > models.NewPercentFromFloat(100) {
+		e["percentage"] = "must be between 0 and 100"
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
+
+func ValidateWorkOrderServiceFeeChangePreviewFromRequest(dirtyData *idos.WorkOrderServiceFeeChangePreviewIDO) (bool, string) {
+	e := make(map[string]string)
+
+	if dirtyData.Percentage < 0 || dirtyData.Percentage > models.NewPercentFromFloat(100) {
+		e["percentage"] = "must be between 0 and 100"
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
@@ -0,0 +1,28 @@
+package validators
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+
+	"github.com/over55/workery-server/internal/idos"
+)
+
+func ValidateWorkOrderSurveySaveFromRequest(dirtyData *idos.WorkOrderSurveyIDO) (bool, string) {
+	e := make(map[string]string)
+
+	if !dirtyData.WasSurveyConducted && !dirtyData.NoSurveyConductedReason.Valid {
+		e["no_survey_conducted_reason"] = "missing value"
+	}
+	if utf8.RuneCountInString(dirtyData.NoSurveyConductedReasonOther) > 1024 {
+		e["no_survey_conducted_reason_other"] = "character count over 1024"
+	}
+
+	if len(e) != 0 {
+		b, err := json.Marshal(e)
+		if err != nil { // Defensive code
+			return false, err.Error()
+		}
+		return false, string(b)
+	}
+	return true, ""
+}
@@ -0,0 +1 @@
+DROP TABLE etl_checkpoints CASCADE;
@@ -0,0 +1,13 @@
+CREATE TABLE etl_checkpoints (
+    id BIGSERIAL PRIMARY KEY,
+    schema_name VARCHAR (63) NOT NULL,
+    step VARCHAR (63) NOT NULL,
+    last_old_id BIGINT NOT NULL DEFAULT 0,
+    imported_rows BIGINT NOT NULL DEFAULT 0,
+    failed_rows BIGINT NOT NULL DEFAULT 0,
+    is_completed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_time TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    last_modified_time TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc')
+);
+CREATE UNIQUE INDEX idx_etl_checkpoint_schema_name_step
+ON etl_checkpoints (schema_name, step);
@@ -0,0 +1 @@
+DROP TABLE work_order_invoice_line_items CASCADE;
@@ -0,0 +1,64 @@
+CREATE TABLE work_order_invoice_line_items (
+    id BIGSERIAL PRIMARY KEY,
+    uuid VARCHAR (36) UNIQUE NOT NULL,
+    tenant_id BIGINT NOT NULL,
+    invoice_id BIGINT NOT NULL,
+    sort_number SMALLINT NOT NULL DEFAULT 0,
+    type_of SMALLINT NOT NULL DEFAULT 1,
+    quantity FLOAT NOT NULL DEFAULT 0,
+    description VARCHAR (255) NOT NULL DEFAULT '',
+    unit_price FLOAT NOT NULL DEFAULT 0,
+    amount FLOAT NOT NULL DEFAULT 0,
+    notes TEXT NOT NULL DEFAULT '',
+    created_time TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    created_by_id BIGINT NULL,
+    created_from_ip VARCHAR (50) NULL,
+    last_modified_time TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    last_modified_by_id BIGINT NULL,
+    last_modified_from_ip VARCHAR (50) NULL,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+    FOREIGN KEY (invoice_id) REFERENCES work_order_invoices(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by_id) REFERENCES users(id),
+    FOREIGN KEY (last_modified_by_id) REFERENCES users(id)
+);
+CREATE INDEX idx_work_order_invoice_line_item_tenant_id
+ON work_order_invoice_line_items (tenant_id);
+CREATE INDEX idx_work_order_invoice_line_item_invoice_id
+ON work_order_invoice_line_items (invoice_id, sort_number);
+
+-- Explode the fifteen `line_NN_*` column groups of every invoice into rows,
+-- skipping the empty lines. The old invoices did not track what the line was
+-- for so we import them as labour (type 1).
+INSERT INTO work_order_invoice_line_items (
+    uuid, tenant_id, invoice_id, sort_number, type_of, quantity, description,
+    unit_price, amount, notes, created_time, created_by_id, last_modified_time,
+    last_modified_by_id
+)
+SELECT
+    md5(random()::text || clock_timestamp()::text)::uuid::text,
+    i.tenant_id, i.id, l.sort_number, 1, COALESCE(l.qty, 0),
+    COALESCE(l.description, ''), COALESCE(l.price, 0), COALESCE(l.amount, 0),
+    l.notes, i.created_time, i.created_by_id, i.last_modified_time,
+    i.last_modified_by_id
+FROM
+    work_order_invoices i
+CROSS JOIN LATERAL (
+    VALUES
+        (1, i.line_01_qty, i.line_01_desc, i.line_01_price, i.line_01_amount, COALESCE(i.line_01_notes, '')),
+        (2, i.line_02_qty, i.line_02_desc, i.line_02_price, i.line_02_amount, COALESCE(i.line_02_notes, '')),
+        (3, i.line_03_qty, i.line_03_desc, i.line_03_price, i.line_03_amount, ''),
+        (4, i.line_04_qty, i.line_04_desc, i.line_04_price, i.line_04_amount, ''),
+        (5, i.line_05_qty, i.line_05_desc, i.line_05_price, i.line_05_amount, ''),
+        (6, i.line_06_qty, i.line_06_desc, i.line_06_price, i.line_06_amount, ''),
+        (7, i.line_07_qty, i.line_07_desc, i.line_07_price, i.line_07_amount, ''),
+        (8, i.line_08_qty, i.line_08_desc, i.line_08_price, i.line_08_amount, ''),
+        (9, i.line_09_qty, i.line_09_desc, i.line_09_price, i.line_09_amount, ''),
+        (10, i.line_10_qty, i.line_10_desc, i.line_10_price, i.line_10_amount, ''),
+        (11, i.line_11_qty, i.line_11_desc, i.line_11_price, i.line_11_amount, ''),
+        (12, i.line_12_qty, i.line_12_desc, i.line_12_price, i.line_12_amount, ''),
+        (13, i.line_13_qty, i.line_13_desc, i.line_13_price, i.line_13_amount, ''),
+        (14, i.line_14_qty, i.line_14_desc, i.line_14_price, i.line_14_amount, ''),
+        (15, i.line_15_qty, i.line_15_desc, i.line_15_price, i.line_15_amount, '')
+) AS l (sort_number, qty, description, price, amount, notes)
+WHERE
+    COALESCE(l.description, '') <> '' OR COALESCE(l.amount, 0) <> 0;
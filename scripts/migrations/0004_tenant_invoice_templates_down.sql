@@ -0,0 +1 @@
+DROP TABLE tenant_invoice_templates CASCADE;
@@ -0,0 +1,12 @@
+CREATE TABLE tenant_invoice_templates (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    logo_s3_key VARCHAR (1027) NOT NULL DEFAULT '',
+    tax_registration_number VARCHAR (127) NOT NULL DEFAULT '',
+    footer_text TEXT NOT NULL DEFAULT '',
+    created_time TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    last_modified_time TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id)
+);
+CREATE UNIQUE INDEX idx_tenant_invoice_template_tenant_id
+ON tenant_invoice_templates (tenant_id);
@@ -0,0 +1,38 @@
+ALTER TABLE work_orders
+    ALTER COLUMN invoice_quote_amount TYPE FLOAT USING invoice_quote_amount::float,
+    ALTER COLUMN invoice_labour_amount TYPE FLOAT USING invoice_labour_amount::float,
+    ALTER COLUMN invoice_material_amount TYPE FLOAT USING invoice_material_amount::float,
+    ALTER COLUMN invoice_other_costs_amount TYPE FLOAT USING invoice_other_costs_amount::float,
+    ALTER COLUMN invoice_quoted_material_amount TYPE FLOAT USING invoice_quoted_material_amount::float,
+    ALTER COLUMN invoice_quoted_labour_amount TYPE FLOAT USING invoice_quoted_labour_amount::float,
+    ALTER COLUMN invoice_quoted_other_costs_amount TYPE FLOAT USING invoice_quoted_other_costs_amount::float,
+    ALTER COLUMN invoice_total_quote_amount TYPE FLOAT USING invoice_total_quote_amount::float,
+    ALTER COLUMN invoice_sub_total_amount TYPE FLOAT USING invoice_sub_total_amount::float,
+    ALTER COLUMN invoice_tax_amount TYPE FLOAT USING invoice_tax_amount::float,
+    ALTER COLUMN invoice_total_amount TYPE FLOAT USING invoice_total_amount::float,
+    ALTER COLUMN invoice_deposit_amount TYPE FLOAT USING invoice_deposit_amount::float,
+    ALTER COLUMN invoice_amount_due TYPE FLOAT USING invoice_amount_due::float,
+    ALTER COLUMN invoice_service_fee_amount TYPE FLOAT USING invoice_service_fee_amount::float,
+    ALTER COLUMN invoice_actual_service_fee_amount_paid TYPE FLOAT USING invoice_actual_service_fee_amount_paid::float,
+    ALTER COLUMN invoice_balance_owing_amount TYPE FLOAT USING invoice_balance_owing_amount::float;
+
+ALTER TABLE work_order_invoices
+    ALTER COLUMN total_labour TYPE FLOAT USING total_labour::float,
+    ALTER COLUMN total_materials TYPE FLOAT USING total_materials::float,
+    ALTER COLUMN other_costs TYPE FLOAT USING other_costs::float,
+    ALTER COLUMN sub_total TYPE FLOAT USING sub_total::float,
+    ALTER COLUMN tax TYPE FLOAT USING tax::float,
+    ALTER COLUMN total TYPE FLOAT USING total::float,
+    ALTER COLUMN deposit TYPE FLOAT USING deposit::float,
+    ALTER COLUMN amount_due TYPE FLOAT USING amount_due::float,
+    ALTER COLUMN payment_amount TYPE FLOAT USING payment_amount::float;
+
+ALTER TABLE work_order_invoice_line_items
+    ALTER COLUMN unit_price TYPE FLOAT USING unit_price::float,
+    ALTER COLUMN amount TYPE FLOAT USING amount::float;
+
+ALTER TABLE work_order_deposits
+    ALTER COLUMN amount TYPE FLOAT USING amount::float;
+
+ALTER TABLE work_order_service_fees
+    ALTER COLUMN percentage TYPE FLOAT USING percentage::float;
\ No newline at end of file
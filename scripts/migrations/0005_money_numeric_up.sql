@@ -0,0 +1,41 @@
+-- Store the amounts as exact decimals instead of floats, the existing values
+-- are rounded to the nearest cent (see `models.Money`).
+
+ALTER TABLE work_orders
+    ALTER COLUMN invoice_quote_amount TYPE NUMERIC (12,2) USING round(invoice_quote_amount::numeric, 2),
+    ALTER COLUMN invoice_labour_amount TYPE NUMERIC (12,2) USING round(invoice_labour_amount::numeric, 2),
+    ALTER COLUMN invoice_material_amount TYPE NUMERIC (12,2) USING round(invoice_material_amount::numeric, 2),
+    ALTER COLUMN invoice_other_costs_amount TYPE NUMERIC (12,2) USING round(invoice_other_costs_amount::numeric, 2),
+    ALTER COLUMN invoice_quoted_material_amount TYPE NUMERIC (12,2) USING round(invoice_quoted_material_amount::numeric, 2),
+    ALTER COLUMN invoice_quoted_labour_amount TYPE NUMERIC (12,2) USING round(invoice_quoted_labour_amount::numeric, 2),
+    ALTER COLUMN invoice_quoted_other_costs_amount TYPE NUMERIC (12,2) USING round(invoice_quoted_other_costs_amount::numeric, 2),
+    ALTER COLUMN invoice_total_quote_amount TYPE NUMERIC (12,2) USING round(invoice_total_quote_amount::numeric, 2),
+    ALTER COLUMN invoice_sub_total_amount TYPE NUMERIC (12,2) USING round(invoice_sub_total_amount::numeric, 2),
+    ALTER COLUMN invoice_tax_amount TYPE NUMERIC (12,2) USING round(invoice_tax_amount::numeric, 2),
+    ALTER COLUMN invoice_total_amount TYPE NUMERIC (12,2) USING round(invoice_total_amount::numeric, 2),
+    ALTER COLUMN invoice_deposit_amount TYPE NUMERIC (12,2) USING round(invoice_deposit_amount::numeric, 2),
+    ALTER COLUMN invoice_amount_due TYPE NUMERIC (12,2) USING round(invoice_amount_due::numeric, 2),
+    ALTER COLUMN invoice_service_fee_amount TYPE NUMERIC (12,2) USING round(invoice_service_fee_amount::numeric, 2),
+    ALTER COLUMN invoice_actual_service_fee_amount_paid TYPE NUMERIC (12,2) USING round(invoice_actual_service_fee_amount_paid::numeric, 2),
+    ALTER COLUMN invoice_balance_owing_amount TYPE NUMERIC (12,2) USING round(invoice_balance_owing_amount::numeric, 2);
+
+ALTER TABLE work_order_invoices
+    ALTER COLUMN total_labour TYPE NUMERIC (12,2) USING round(total_labour::numeric, 2),
+    ALTER COLUMN total_materials TYPE NUMERIC (12,2) USING round(total_materials::numeric, 2),
+    ALTER COLUMN other_costs TYPE NUMERIC (12,2) USING round(other_costs::numeric, 2),
+    ALTER COLUMN sub_total TYPE NUMERIC (12,2) USING round(sub_total::numeric, 2),
+    ALTER COLUMN tax TYPE NUMERIC (12,2) USING round(tax::numeric, 2),
+    ALTER COLUMN total TYPE NUMERIC (12,2) USING round(total::numeric, 2),
+    ALTER COLUMN deposit TYPE NUMERIC (12,2) USING round(deposit::numeric, 2),
+    ALTER COLUMN amount_due TYPE NUMERIC (12,2) USING round(amount_due::numeric, 2),
+    ALTER COLUMN payment_amount TYPE NUMERIC (12,2) USING round(payment_amount::numeric, 2);
+
+ALTER TABLE work_order_invoice_line_items
+    ALTER COLUMN unit_price TYPE NUMERIC (12,2) USING round(unit_price::numeric, 2),
+    ALTER COLUMN amount TYPE NUMERIC (12,2) USING round(amount::numeric, 2);
+
+ALTER TABLE work_order_deposits
+    ALTER COLUMN amount TYPE NUMERIC (12,2) USING round(amount::numeric, 2);
+
+ALTER TABLE work_order_service_fees
+    ALTER COLUMN percentage TYPE NUMERIC (9,4) USING round(percentage::numeric, 4);
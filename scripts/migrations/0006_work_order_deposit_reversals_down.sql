@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_work_order_deposit_reversal_of_id;
+ALTER TABLE work_order_deposits DROP COLUMN IF EXISTS reversal_of_id;
@@ -0,0 +1,4 @@
+ALTER TABLE work_order_deposits ADD COLUMN reversal_of_id BIGINT NULL;
+ALTER TABLE work_order_deposits ADD FOREIGN KEY (reversal_of_id) REFERENCES work_order_deposits(id);
+CREATE UNIQUE INDEX idx_work_order_deposit_reversal_of_id
+ON work_order_deposits (reversal_of_id);
@@ -0,0 +1 @@
+DROP VIEW IF EXISTS associate_service_fee_ledger;
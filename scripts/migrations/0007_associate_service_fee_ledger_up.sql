@@ -0,0 +1,48 @@
+-- Every amount which changes what an associate owes us in service fees, the
+-- balance of an associate is the sum of their entries.
+--
+-- type_of
+-- 1 = Service fee billed for the work order
+-- 2 = Service fee payment received from the associate
+-- 3 = Deposit paid to the organization on the work order
+CREATE VIEW associate_service_fee_ledger AS
+    SELECT
+        tenant_id,
+        associate_id,
+        id AS order_id,
+        1 AS type_of,
+        COALESCE(completion_date, invoice_date, start_date) AS entry_date,
+        currency,
+        invoice_service_fee_amount AS amount
+    FROM
+        work_orders
+    WHERE
+        associate_id IS NOT NULL AND invoice_service_fee_amount <> 0
+    UNION ALL
+    SELECT
+        tenant_id,
+        associate_id,
+        id AS order_id,
+        2 AS type_of,
+        COALESCE(invoice_service_fee_payment_date, last_modified_time) AS entry_date,
+        currency,
+        -invoice_actual_service_fee_amount_paid AS amount
+    FROM
+        work_orders
+    WHERE
+        associate_id IS NOT NULL AND invoice_actual_service_fee_amount_paid <> 0
+    UNION ALL
+    SELECT
+        d.tenant_id,
+        wo.associate_id,
+        d.order_id,
+        3 AS type_of,
+        d.paid_at AS entry_date,
+        d.currency,
+        -d.amount AS amount
+    FROM
+        work_order_deposits AS d
+    INNER JOIN
+        work_orders AS wo ON wo.id = d.order_id
+    WHERE
+        wo.associate_id IS NOT NULL AND d.paid_to = 2 AND d.state = 1;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS accounting_export_entries;
+DROP TABLE IF EXISTS accounting_exports;
+DROP TABLE IF EXISTS tenant_account_mappings;
@@ -0,0 +1,54 @@
+CREATE TABLE tenant_account_mappings (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    accounts_receivable VARCHAR (127) NOT NULL DEFAULT 'Accounts Receivable',
+    labour_income VARCHAR (127) NOT NULL DEFAULT 'Labour Income',
+    materials_income VARCHAR (127) NOT NULL DEFAULT 'Materials Income',
+    other_income VARCHAR (127) NOT NULL DEFAULT 'Other Income',
+    sales_tax_payable VARCHAR (127) NOT NULL DEFAULT 'Sales Tax Payable',
+    undeposited_funds VARCHAR (127) NOT NULL DEFAULT 'Undeposited Funds',
+    associate_clearing VARCHAR (127) NOT NULL DEFAULT 'Associate Clearing',
+    service_fee_income VARCHAR (127) NOT NULL DEFAULT 'Service Fee Income',
+    created_time TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    last_modified_time TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id)
+);
+CREATE UNIQUE INDEX idx_tenant_account_mapping_tenant_id
+ON tenant_account_mappings (tenant_id);
+
+CREATE TABLE accounting_exports (
+    id BIGSERIAL PRIMARY KEY,
+    uuid VARCHAR (36) UNIQUE NOT NULL,
+    tenant_id BIGINT NOT NULL,
+    format VARCHAR (15) NOT NULL DEFAULT '',
+    from_date TIMESTAMP NOT NULL,
+    to_date TIMESTAMP NOT NULL,
+    entry_count BIGINT NOT NULL DEFAULT 0,
+    created_time TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    created_by_id BIGINT NULL,
+    created_by_name VARCHAR (511) NULL,
+    created_from_ip VARCHAR (50) NULL,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+    FOREIGN KEY (created_by_id) REFERENCES users(id)
+);
+CREATE INDEX idx_accounting_export_tenant_id
+ON accounting_exports (tenant_id);
+
+-- Every record which was exported, the unique index prevents the same record
+-- from being exported twice.
+--
+-- source_type_of
+-- 1 = Work order invoice
+-- 2 = Work order deposit
+-- 3 = Service fee payment of a work order
+CREATE TABLE accounting_export_entries (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    export_id BIGINT NOT NULL,
+    source_type_of SMALLINT NOT NULL,
+    source_id BIGINT NOT NULL,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+    FOREIGN KEY (export_id) REFERENCES accounting_exports(id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX idx_accounting_export_entry_source
+ON accounting_export_entries (tenant_id, source_type_of, source_id);
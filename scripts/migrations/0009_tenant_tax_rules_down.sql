@@ -0,0 +1,2 @@
+ALTER TABLE work_order_invoices DROP COLUMN IF EXISTS tax_rules;
+DROP TABLE IF EXISTS tenant_tax_rules;
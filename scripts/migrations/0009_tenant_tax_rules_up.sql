@@ -0,0 +1,34 @@
+-- type_of
+-- 0 = Applies to every type of work order
+-- 1 = Residential
+-- 2 = Commercial
+-- 3 = Unassigned
+CREATE TABLE tenant_tax_rules (
+    id BIGSERIAL PRIMARY KEY,
+    uuid VARCHAR (36) UNIQUE NOT NULL,
+    tenant_id BIGINT NOT NULL,
+    name VARCHAR (63) NOT NULL DEFAULT '',
+    rate NUMERIC (9,4) NOT NULL DEFAULT 0,
+    type_of SMALLINT NOT NULL DEFAULT 0,
+    effective_from TIMESTAMP NOT NULL,
+    effective_to TIMESTAMP NULL,
+    created_time TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    created_by_id BIGINT NULL,
+    last_modified_time TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    last_modified_by_id BIGINT NULL,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+    FOREIGN KEY (created_by_id) REFERENCES users(id),
+    FOREIGN KEY (last_modified_by_id) REFERENCES users(id)
+);
+CREATE INDEX idx_tenant_tax_rule_tenant_id
+ON tenant_tax_rules (tenant_id);
+
+-- Every tenant used to be charged the Ontario HST so it becomes their rule.
+INSERT INTO tenant_tax_rules (uuid, tenant_id, name, rate, type_of, effective_from)
+SELECT
+    md5(random()::text || id::text)::uuid::text, id, 'HST', 13, 0, '2000-01-01'
+FROM
+    tenants;
+
+-- The tax rules which were applied when the invoice totals were computed.
+ALTER TABLE work_order_invoices ADD COLUMN tax_rules JSONB NOT NULL DEFAULT '[]';
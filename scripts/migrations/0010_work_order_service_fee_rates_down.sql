@@ -0,0 +1 @@
+DROP TABLE work_order_service_fee_rates;
@@ -0,0 +1,25 @@
+-- The percentage of a service fee over time, the rate of a work order is the
+-- one which was effective when the work order was invoiced so changing a fee
+-- never alters historical jobs.
+CREATE TABLE work_order_service_fee_rates (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    service_fee_id BIGINT NOT NULL,
+    percentage NUMERIC (9,4) NOT NULL DEFAULT 0,
+    effective_from TIMESTAMP NOT NULL,
+    created_time TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    created_by_id BIGINT NULL,
+    created_by_name VARCHAR (511) NULL,
+    created_from_ip VARCHAR (50) NOT NULL DEFAULT '',
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+    FOREIGN KEY (service_fee_id) REFERENCES work_order_service_fees(id)
+);
+CREATE UNIQUE INDEX idx_work_order_service_fee_rate_service_fee_id_effective_from
+ON work_order_service_fee_rates (service_fee_id, effective_from);
+
+-- The existing percentages have always been in effect.
+INSERT INTO work_order_service_fee_rates (tenant_id, service_fee_id, percentage, effective_from, created_time)
+SELECT
+    tenant_id, id, percentage, '2000-01-01', created_time
+FROM
+    work_order_service_fees;
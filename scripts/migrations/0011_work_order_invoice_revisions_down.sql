@@ -0,0 +1,10 @@
+DROP TABLE work_order_invoice_revisions;
+
+ALTER TABLE work_order_invoices
+    DROP COLUMN reopened_by_id,
+    DROP COLUMN reopened_time;
+
+UPDATE work_order_invoices SET payment_date = created_time WHERE payment_date IS NULL;
+ALTER TABLE work_order_invoices
+    ALTER COLUMN payment_date SET DEFAULT (now() AT TIME ZONE 'utc'),
+    ALTER COLUMN payment_date SET NOT NULL;
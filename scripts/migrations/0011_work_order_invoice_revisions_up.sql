@@ -0,0 +1,30 @@
+-- The payment date used to default to the creation time of the invoice so it
+-- could not tell whether the invoice was paid, only paid invoices keep it.
+ALTER TABLE work_order_invoices
+    ALTER COLUMN payment_date DROP NOT NULL,
+    ALTER COLUMN payment_date DROP DEFAULT;
+UPDATE work_order_invoices SET payment_date = NULL WHERE payment_amount = 0;
+
+-- Paid invoices are locked unless a manager reopened them.
+ALTER TABLE work_order_invoices
+    ADD COLUMN reopened_time TIMESTAMP NULL,
+    ADD COLUMN reopened_by_id BIGINT NULL REFERENCES users(id);
+
+-- Every version of the invoice before it was changed, the snapshot holds the
+-- header, totals, signatures and line items of the invoice as JSON.
+CREATE TABLE work_order_invoice_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    invoice_id BIGINT NOT NULL,
+    revision_version SMALLINT NOT NULL,
+    snapshot JSONB NOT NULL,
+    created_time TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    created_by_id BIGINT NULL,
+    created_by_name VARCHAR (511) NULL,
+    created_from_ip VARCHAR (50) NULL,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+    FOREIGN KEY (invoice_id) REFERENCES work_order_invoices(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by_id) REFERENCES users(id)
+);
+CREATE UNIQUE INDEX idx_work_order_invoice_revision_invoice_id_revision_version
+ON work_order_invoice_revisions (invoice_id, revision_version);
@@ -0,0 +1,7 @@
+DROP INDEX idx_task_item_open_due_date;
+ALTER TABLE task_items
+    DROP COLUMN escalated_to_id,
+    DROP COLUMN escalated_time,
+    DROP COLUMN is_overdue;
+
+DROP TABLE tenant_task_settings;
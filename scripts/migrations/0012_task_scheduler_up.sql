@@ -0,0 +1,22 @@
+-- The scheduler settings of each tenant, tenants without settings use the
+-- defaults of the columns.
+CREATE TABLE tenant_task_settings (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    follow_up_after_days SMALLINT NOT NULL DEFAULT 7,
+    escalate_after_days SMALLINT NOT NULL DEFAULT 3,
+    escalate_to_id BIGINT NULL,
+    created_time TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    last_modified_time TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+    FOREIGN KEY (escalate_to_id) REFERENCES users(id)
+);
+CREATE UNIQUE INDEX idx_tenant_task_setting_tenant_id
+ON tenant_task_settings (tenant_id);
+
+ALTER TABLE task_items
+    ADD COLUMN is_overdue BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN escalated_time TIMESTAMP NULL,
+    ADD COLUMN escalated_to_id BIGINT NULL REFERENCES users(id);
+CREATE INDEX idx_task_item_open_due_date
+ON task_items (due_date) WHERE is_closed = FALSE;
@@ -0,0 +1,77 @@
+CREATE TABLE work_order_surveys (
+    id BIGSERIAL PRIMARY KEY,
+    uuid VARCHAR (36) UNIQUE NOT NULL,
+    tenant_id BIGINT NOT NULL,
+    order_id BIGINT NOT NULL,
+    associate_id BIGINT NULL,
+    was_survey_conducted BOOLEAN NOT NULL DEFAULT FALSE,
+    no_survey_conducted_reason SMALLINT NULL,
+    no_survey_conducted_reason_other VARCHAR (1024) NULL,
+    was_job_satisfactory BOOLEAN NOT NULL DEFAULT FALSE,
+    was_job_finished_on_time_and_on_budget BOOLEAN NOT NULL DEFAULT FALSE,
+    was_associate_punctual BOOLEAN NOT NULL DEFAULT FALSE,
+    was_associate_professional BOOLEAN NOT NULL DEFAULT FALSE,
+    would_customer_refer_our_organization BOOLEAN NOT NULL DEFAULT FALSE,
+    score SMALLINT NOT NULL DEFAULT 0,
+    associate_score FLOAT NULL,
+    created_time TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    created_by_id BIGINT NULL,
+    created_by_name VARCHAR (511) NULL,
+    created_from_ip VARCHAR (50) NULL,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+    FOREIGN KEY (order_id) REFERENCES work_orders(id),
+    FOREIGN KEY (associate_id) REFERENCES associates(id),
+    FOREIGN KEY (created_by_id) REFERENCES users(id)
+);
+CREATE INDEX idx_work_order_survey_order_id
+ON work_order_surveys (order_id);
+CREATE INDEX idx_work_order_survey_associate_id
+ON work_order_surveys (associate_id);
+
+-- The surveys which were conducted before are the start of the history.
+INSERT INTO work_order_surveys (
+    uuid, tenant_id, order_id, associate_id, was_survey_conducted,
+    no_survey_conducted_reason, no_survey_conducted_reason_other,
+    was_job_satisfactory, was_job_finished_on_time_and_on_budget,
+    was_associate_punctual, was_associate_professional,
+    would_customer_refer_our_organization, score, created_time
+)
+SELECT
+    md5(random()::text || id::text)::uuid::text, tenant_id, id, associate_id, was_survey_conducted,
+    no_survey_conducted_reason, no_survey_conducted_reason_other,
+    was_job_satisfactory, was_job_finished_on_time_and_on_budget,
+    was_associate_punctual, was_associate_professional,
+    would_customer_refer_our_organization, score, COALESCE(completion_date, last_modified_time)
+FROM
+    work_orders
+WHERE
+    was_survey_conducted = TRUE;
+
+UPDATE work_order_surveys AS s
+SET associate_score = r.associate_score
+FROM (
+    SELECT
+        id,
+        AVG(score) OVER (PARTITION BY associate_id ORDER BY created_time, id ROWS BETWEEN 19 PRECEDING AND CURRENT ROW) AS associate_score
+    FROM
+        work_order_surveys
+    WHERE
+        associate_id IS NOT NULL
+) AS r
+WHERE
+    s.id = r.id;
+
+UPDATE associates AS a
+SET score = latest.associate_score
+FROM (
+    SELECT DISTINCT ON (associate_id)
+        associate_id, associate_score
+    FROM
+        work_order_surveys
+    WHERE
+        associate_id IS NOT NULL
+    ORDER BY
+        associate_id, created_time DESC, id DESC
+) AS latest
+WHERE
+    a.id = latest.associate_id;
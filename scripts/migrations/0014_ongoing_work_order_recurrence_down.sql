@@ -0,0 +1,10 @@
+DROP INDEX idx_work_order_ongoing_work_order_id;
+DROP INDEX idx_ongoing_work_order_tenant_id;
+ALTER TABLE ongoing_work_orders
+    DROP COLUMN generated_until,
+    DROP COLUMN recurrence_end_date,
+    DROP COLUMN recurrence_start_date,
+    DROP COLUMN recurrence_rule,
+    DROP COLUMN is_home_support_service,
+    DROP COLUMN type_of,
+    DROP COLUMN description;
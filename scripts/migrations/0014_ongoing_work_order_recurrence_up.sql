@@ -0,0 +1,15 @@
+-- recurrence_rule
+-- A subset of the iCalendar RRULE (see `models.RecurrenceRule`), an empty
+-- rule means the jobs of the ongoing work order are created by hand.
+ALTER TABLE ongoing_work_orders
+    ADD COLUMN description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN type_of SMALLINT NOT NULL DEFAULT 1,
+    ADD COLUMN is_home_support_service BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN recurrence_rule VARCHAR (255) NOT NULL DEFAULT '',
+    ADD COLUMN recurrence_start_date TIMESTAMP NULL,
+    ADD COLUMN recurrence_end_date TIMESTAMP NULL,
+    ADD COLUMN generated_until TIMESTAMP NULL;
+CREATE INDEX idx_ongoing_work_order_tenant_id
+ON ongoing_work_orders (tenant_id);
+CREATE INDEX idx_work_order_ongoing_work_order_id
+ON work_orders (ongoing_work_order_id);
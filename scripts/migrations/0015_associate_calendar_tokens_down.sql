@@ -0,0 +1 @@
+DROP TABLE associate_calendar_tokens;
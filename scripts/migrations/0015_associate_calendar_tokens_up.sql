@@ -0,0 +1,19 @@
+-- The token of the calendar feed of each associate, only the SHA-256 hash of
+-- the token is stored so the feed URL cannot be recovered from the database.
+-- Rotating the token revokes the previous feed URL.
+CREATE TABLE associate_calendar_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    associate_id BIGINT NOT NULL,
+    token_hash VARCHAR (64) NOT NULL,
+    created_time TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    created_by_id BIGINT NULL,
+    created_by_name VARCHAR (511) NULL,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+    FOREIGN KEY (associate_id) REFERENCES associates(id),
+    FOREIGN KEY (created_by_id) REFERENCES users(id)
+);
+CREATE UNIQUE INDEX idx_associate_calendar_token_associate_id
+ON associate_calendar_tokens (associate_id);
+CREATE UNIQUE INDEX idx_associate_calendar_token_token_hash
+ON associate_calendar_tokens (token_hash);
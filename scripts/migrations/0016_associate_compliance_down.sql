@@ -0,0 +1,3 @@
+DROP INDEX idx_associate_insurance_requirement_associate_id;
+ALTER TABLE insurance_requirements
+    DROP COLUMN associate_date_type_of;
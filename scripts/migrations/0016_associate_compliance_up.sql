@@ -0,0 +1,13 @@
+-- associate_date_type_of
+-- The date of the associate which the insurance requirement expires on, see
+-- `models.InsuranceRequirement`. The existing requirements are matched by
+-- their text and the others are not tracked until an administrator sets it.
+ALTER TABLE insurance_requirements
+    ADD COLUMN associate_date_type_of SMALLINT NOT NULL DEFAULT 0;
+UPDATE insurance_requirements SET associate_date_type_of = 1 WHERE text ILIKE '%commercial%';
+UPDATE insurance_requirements SET associate_date_type_of = 2 WHERE associate_date_type_of = 0 AND text ILIKE '%auto%';
+UPDATE insurance_requirements SET associate_date_type_of = 3 WHERE associate_date_type_of = 0 AND text ILIKE '%wsib%';
+UPDATE insurance_requirements SET associate_date_type_of = 4 WHERE associate_date_type_of = 0 AND text ILIKE '%police%';
+
+CREATE INDEX idx_associate_insurance_requirement_associate_id
+ON associate_insurance_requirements (associate_id);
@@ -0,0 +1,2 @@
+ALTER TABLE etl_checkpoints
+    DROP COLUMN failed_old_ids;
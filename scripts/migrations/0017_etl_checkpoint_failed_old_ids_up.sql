@@ -0,0 +1,5 @@
+-- failed_old_ids
+-- The rows of the step which failed to import, they are retried when the
+-- step is resumed even though they are before the `last_old_id`.
+ALTER TABLE etl_checkpoints
+    ADD COLUMN failed_old_ids BIGINT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+DELETE FROM etl_checkpoints
+WHERE step = 'fix_timestamps' AND is_completed = FALSE;
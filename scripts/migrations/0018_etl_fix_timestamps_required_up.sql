@@ -0,0 +1,20 @@
+-- The tenants which were imported before the ETL converted the old times to
+-- UTC need to be corrected by `etl_fix_timestamps` before anything else is
+-- imported, so we add the checkpoint of that step as not completed. Please
+-- note the tenants which were already corrected keep their checkpoint.
+INSERT INTO etl_checkpoints (schema_name, step, is_completed)
+SELECT
+    t.schema_name, 'fix_timestamps', FALSE
+FROM
+    tenants AS t
+WHERE
+    t.schema_name IS NOT NULL
+AND
+    (
+        EXISTS (SELECT 1 FROM users AS u WHERE u.tenant_id = t.id AND u.old_id <> 0)
+    OR
+        EXISTS (SELECT 1 FROM customers AS c WHERE c.tenant_id = t.id AND c.old_id <> 0)
+    OR
+        EXISTS (SELECT 1 FROM work_orders AS wo WHERE wo.tenant_id = t.id AND wo.old_id <> 0)
+    )
+ON CONFLICT (schema_name, step) DO NOTHING;
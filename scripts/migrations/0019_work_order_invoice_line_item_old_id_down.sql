@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_work_order_invoice_line_item_old_id;
+ALTER TABLE work_order_invoice_line_items
+    DROP COLUMN old_id;
@@ -0,0 +1,20 @@
+-- old_id
+-- The old invoices had no ids for their lines so the old id of the imported
+-- line items is the number of the line on the old invoice, 1 to 15, which
+-- lets the ETL import the line items of an invoice more than once.
+ALTER TABLE work_order_invoice_line_items
+    ADD COLUMN old_id BIGINT NOT NULL DEFAULT 0;
+
+-- The line items which were imported or exploded by the `0003` migration
+-- still have the time of their invoice.
+UPDATE
+    work_order_invoice_line_items AS l
+SET
+    old_id = l.sort_number
+FROM
+    work_order_invoices AS i
+WHERE
+    i.id = l.invoice_id AND i.old_id <> 0 AND l.created_time = i.created_time;
+
+CREATE UNIQUE INDEX idx_work_order_invoice_line_item_old_id
+ON work_order_invoice_line_items (invoice_id, old_id) WHERE old_id <> 0;
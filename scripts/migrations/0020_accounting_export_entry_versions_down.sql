@@ -0,0 +1,18 @@
+ALTER TABLE work_orders
+    DROP COLUMN invoice_service_fee_payment_version;
+
+DROP INDEX IF EXISTS idx_accounting_export_entry_export_id;
+DROP INDEX idx_accounting_export_entry_source;
+DELETE FROM accounting_export_entries
+WHERE source_version <> (
+    SELECT MAX(o.source_version) FROM accounting_export_entries AS o
+    WHERE o.tenant_id = accounting_export_entries.tenant_id
+    AND o.source_type_of = accounting_export_entries.source_type_of
+    AND o.source_id = accounting_export_entries.source_id
+);
+CREATE UNIQUE INDEX idx_accounting_export_entry_source
+ON accounting_export_entries (tenant_id, source_type_of, source_id);
+
+ALTER TABLE accounting_export_entries
+    DROP COLUMN entry,
+    DROP COLUMN source_version;
@@ -0,0 +1,33 @@
+-- source_version
+-- The version of the record which was exported, the revision version of the
+-- invoices and the payment version of the service fee payments, a record is
+-- exported again as an adjustment once its version changes. The entries which
+-- were exported before are assumed to be of the current version.
+--
+-- entry
+-- The journal entry which was exported as JSON so the file of the export can
+-- be downloaded again and adjustments only book the difference.
+ALTER TABLE accounting_export_entries
+    ADD COLUMN source_version BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN entry JSONB NULL;
+
+UPDATE
+    accounting_export_entries AS e
+SET
+    source_version = i.revision_version
+FROM
+    work_order_invoices AS i
+WHERE
+    e.source_type_of = 1 AND e.source_id = i.id;
+
+DROP INDEX idx_accounting_export_entry_source;
+CREATE UNIQUE INDEX idx_accounting_export_entry_source
+ON accounting_export_entries (tenant_id, source_type_of, source_id, source_version);
+CREATE INDEX idx_accounting_export_entry_export_id
+ON accounting_export_entries (export_id);
+
+-- invoice_service_fee_payment_version
+-- Incremented every time the service fee payment amount or date of the work
+-- order changes.
+ALTER TABLE work_orders
+    ADD COLUMN invoice_service_fee_payment_version BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE work_orders
+    DROP COLUMN invoice_tax_rules;
@@ -0,0 +1,5 @@
+-- invoice_tax_rules
+-- The tax rules which were applied when the invoice tax of the work order was
+-- computed on completion.
+ALTER TABLE work_orders
+    ADD COLUMN invoice_tax_rules JSONB NOT NULL DEFAULT '[]';